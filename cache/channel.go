@@ -7,7 +7,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/go-redis/redis"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/slack-go/slack"
 )
@@ -15,24 +15,27 @@ import (
 type channelGetter interface {
 	GetByID(ctx context.Context, id string) (slack.Channel, bool, error)
 	GetByName(ctx context.Context, name string) (slack.Channel, bool, error)
+	All(ctx context.Context) ([]slack.Channel, error)
 }
 
 type channelPutter interface {
 	Hash(ctx context.Context, id string) (string, bool, error)
 	TTL(ctx context.Context, id string) (time.Duration, bool, error)
 	Put(ctx context.Context, id, name, data, hash string) error
+	GetByID(ctx context.Context, id string) (slack.Channel, bool, error)
+	Delete(ctx context.Context, id, name string) error
 }
 
 // ChannelFiller is channel cache filler.
 type ChannelFiller struct {
-	s     *slack.Client
+	s     channelAPI
 	store channelPutter
 	l     zerolog.Logger
 }
 
 // NewChannelFiller generates a new cache populator.
 func NewChannelFiller(sc *slack.Client, rc *redis.Client, logger zerolog.Logger) (*ChannelFiller, error) {
-	res := rc.Set(redisByIDPrefix+"populator_test_id_should_be_auto_removed", "foobar", time.Second)
+	res := rc.Set(context.Background(), redisByIDPrefix+"populator_test_id_should_be_auto_removed", "foobar", time.Second)
 	if err := res.Err(); err != nil {
 		return nil, fmt.Errorf("failed to set test key: %w", err)
 	}
@@ -44,21 +47,17 @@ func NewChannelFiller(sc *slack.Client, rc *redis.Client, logger zerolog.Logger)
 	}, nil
 }
 
-var channelHasher = sha256.New()
-
+// hashit is safe for concurrent use, as Update may be called for several
+// channels at once.
 func hashit(j []byte) string {
-	defer channelHasher.Reset()
-	if _, err := channelHasher.Write(j); err != nil {
-		panic(err.Error())
-	}
-	return fmt.Sprintf("%x", channelHasher.Sum(nil))
+	return fmt.Sprintf("%x", sha256.Sum256(j))
 }
 
 // Fill loads the cache.
 func (c *ChannelFiller) Fill(ctx context.Context) error {
-	chans, err := c.s.GetChannelsContext(ctx, true)
+	chans, err := listChannels(ctx, c.s)
 	if err != nil {
-		return fmt.Errorf("failed to get channel info: %w", err)
+		return err
 	}
 
 	for _, ch := range chans {
@@ -107,6 +106,48 @@ func (c *ChannelFiller) Fill(ctx context.Context) error {
 	return nil
 }
 
+// Update refreshes a single channel in the cache, after it was created,
+// renamed, archived, or unarchived. Archived channels are removed, like they're
+// left out by Fill, and a renamed channel stops being found by its old name.
+func (c *ChannelFiller) Update(ctx context.Context, id string) error {
+	ch, err := c.s.GetConversationInfoContext(ctx, id, false)
+	if err != nil {
+		return fmt.Errorf("failed to get channel info: %w", err)
+	}
+
+	old, notFound, err := c.store.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !notFound && (ch.IsArchived || old.Name != ch.Name) {
+		if err := c.store.Delete(ctx, id, old.Name); err != nil {
+			return err
+		}
+	}
+
+	if ch.IsArchived {
+		c.l.Debug().
+			Str("channel_id", id).
+			Msg("removed archived channel")
+
+		return nil
+	}
+
+	j, _ := json.Marshal(ch)
+
+	if err := c.store.Put(ctx, ch.ID, ch.Name, string(j), hashit(j)); err != nil {
+		return err
+	}
+
+	c.l.Debug().
+		Str("channel_id", id).
+		Str("channel_name", ch.Name).
+		Msg("updated channel")
+
+	return nil
+}
+
 // Channel represents a Redis-backed channel cache.
 type Channel struct {
 	store channelGetter
@@ -134,3 +175,8 @@ func (c *Channel) Lookup(name string) (slack.Channel, bool, error) {
 
 	return c.store.GetByName(ctx, name)
 }
+
+// All returns every channel in the cache, in no particular order.
+func (c *Channel) All(ctx context.Context) ([]slack.Channel, error) {
+	return c.store.All(ctx)
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// conversationLister is the part of *slack.Client used to list channels.
+type conversationLister interface {
+	GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error)
+}
+
+// channelAPI is the part of *slack.Client used to fill the channel cache.
+type channelAPI interface {
+	conversationLister
+	GetConversationInfoContext(ctx context.Context, channelID string, includeLocale bool) (*slack.Channel, error)
+}
+
+// conversationsPageSize is how many channels are asked for in each page. Slack
+// may return fewer, even when there are more.
+const conversationsPageSize = 1000
+
+// listChannels returns all unarchived public channels, and the private channels
+// the bot is in, following the cursor through every page. If rate limited, it
+// waits for as long as Slack asked before asking for the page again.
+func listChannels(ctx context.Context, l conversationLister) ([]slack.Channel, error) {
+	params := &slack.GetConversationsParameters{
+		ExcludeArchived: "true",
+		Limit:           conversationsPageSize,
+		Types:           []string{"public_channel", "private_channel"},
+	}
+
+	var chans []slack.Channel
+
+	for {
+		page, next, err := l.GetConversationsContext(ctx, params)
+
+		var rle *slack.RateLimitedError
+
+		if errors.As(err, &rle) {
+			select {
+			case <-time.After(rle.RetryAfter):
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list channels: %w", err)
+		}
+
+		chans = append(chans, page...)
+
+		if len(next) == 0 {
+			return chans, nil
+		}
+
+		params.Cursor = next
+	}
+}
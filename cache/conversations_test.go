@@ -0,0 +1,275 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// fakeConversations serves channels a page at a time, like conversations.list.
+type fakeConversations struct {
+	pages [][]slack.Channel
+
+	// rateLimit is how many requests are rate limited before the first
+	// page is served.
+	rateLimit int
+
+	// failAt is the page to fail on, if positive.
+	failAt int
+
+	params []slack.GetConversationsParameters
+}
+
+func (f *fakeConversations) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	f.params = append(f.params, *params)
+
+	if f.rateLimit > 0 {
+		f.rateLimit--
+		return nil, "", &slack.RateLimitedError{RetryAfter: time.Millisecond}
+	}
+
+	i := 0
+	if len(params.Cursor) > 0 {
+		if _, err := fmt.Sscanf(params.Cursor, "page%d", &i); err != nil {
+			return nil, "", fmt.Errorf("bad cursor %q", params.Cursor)
+		}
+	}
+
+	if f.failAt > 0 && i == f.failAt {
+		return nil, "", errors.New("internal_error")
+	}
+
+	var next string
+	if i+1 < len(f.pages) {
+		next = fmt.Sprintf("page%d", i+1)
+	}
+
+	return f.pages[i], next, nil
+}
+
+func (f *fakeConversations) GetConversationInfoContext(ctx context.Context, channelID string, includeLocale bool) (*slack.Channel, error) {
+	for _, p := range f.pages {
+		for _, ch := range p {
+			if ch.ID == channelID {
+				return &ch, nil
+			}
+		}
+	}
+
+	return nil, errors.New("channel_not_found")
+}
+
+func testChannel(id, name string) slack.Channel {
+	var ch slack.Channel
+
+	ch.ID = id
+	ch.Name = name
+
+	return ch
+}
+
+func testPages() [][]slack.Channel {
+	return [][]slack.Channel{
+		{testChannel("C1", "general"), testChannel("C2", "golang-newbies")},
+		{testChannel("G3", "admins")},
+		{},
+		{testChannel("C4", "remotemeetup")},
+	}
+}
+
+func channelIDs(chans []slack.Channel) []string {
+	ids := make([]string, len(chans))
+	for i, ch := range chans {
+		ids[i] = ch.ID
+	}
+
+	return ids
+}
+
+func Test_listChannels(t *testing.T) {
+	tests := []struct {
+		name      string
+		rateLimit int
+		failAt    int
+		want      []string
+		wantCalls int
+		err       string
+	}{
+		{
+			name:      "all_pages",
+			want:      []string{"C1", "C2", "G3", "C4"},
+			wantCalls: 4,
+		},
+		{
+			name:      "rate_limited",
+			rateLimit: 2,
+			want:      []string{"C1", "C2", "G3", "C4"},
+			wantCalls: 6,
+		},
+		{
+			name:      "failed_page",
+			failAt:    2,
+			wantCalls: 3,
+			err:       "failed to list channels: internal_error",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeConversations{pages: testPages(), rateLimit: tt.rateLimit, failAt: tt.failAt}
+
+			got, err := listChannels(context.Background(), f)
+
+			if len(f.params) != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", len(f.params), tt.wantCalls)
+			}
+
+			if len(tt.err) > 0 {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, channelIDs(got)); diff != "" {
+				t.Fatalf("listChannels() mismatch (-want +got):\n%s", diff)
+			}
+
+			for _, p := range f.params {
+				if p.ExcludeArchived != "true" || p.Limit != conversationsPageSize {
+					t.Fatalf("unexpected params: %+v", p)
+				}
+
+				if diff := cmp.Diff([]string{"public_channel", "private_channel"}, p.Types); diff != "" {
+					t.Fatalf("Types mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+// fakeStore records what's put, for ChannelFiller.
+type fakeStore struct {
+	channels map[string]slack.Channel
+	names    map[string]string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{channels: make(map[string]slack.Channel), names: make(map[string]string)}
+}
+
+func (s *fakeStore) Hash(ctx context.Context, id string) (string, bool, error) {
+	return "", true, nil
+}
+
+func (s *fakeStore) TTL(ctx context.Context, id string) (time.Duration, bool, error) {
+	return 0, true, nil
+}
+
+func (s *fakeStore) Put(ctx context.Context, id, name, data, hash string) error {
+	s.channels[id] = testChannel(id, name)
+	s.names[name] = id
+
+	return nil
+}
+
+func (s *fakeStore) GetByID(ctx context.Context, id string) (slack.Channel, bool, error) {
+	ch, ok := s.channels[id]
+	return ch, !ok, nil
+}
+
+func (s *fakeStore) Delete(ctx context.Context, id, name string) error {
+	delete(s.channels, id)
+
+	if s.names[name] == id {
+		delete(s.names, name)
+	}
+
+	return nil
+}
+
+func TestChannelFiller_Fill(t *testing.T) {
+	fs := newFakeStore()
+
+	c := &ChannelFiller{
+		s:     &fakeConversations{pages: testPages()},
+		store: fs,
+		l:     zerolog.Nop(),
+	}
+
+	if err := c.Fill(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"general":        "C1",
+		"golang-newbies": "C2",
+		"admins":         "G3",
+		"remotemeetup":   "C4",
+	}
+
+	if diff := cmp.Diff(want, fs.names); diff != "" {
+		t.Fatalf("names mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestInMemChannel_update(t *testing.T) {
+	c := &InMemChannel{
+		sc: &fakeConversations{pages: testPages()},
+		l:  zerolog.Nop(),
+		mu: &sync.RWMutex{},
+	}
+
+	if err := c.update(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the last page must have been fetched
+	ch, notFound, err := c.Lookup("remotemeetup")
+	if err != nil || notFound || ch.ID != "C4" {
+		t.Fatalf("Lookup() = %q, %t, %v, want C4", ch.ID, notFound, err)
+	}
+}
+
+func TestChannelFiller_Update(t *testing.T) {
+	fs := newFakeStore()
+	_ = fs.Put(context.Background(), "C2", "newbies", "", "")
+
+	pages := testPages()
+	pages[1] = append(pages[1], testChannel("C5", "archived"))
+	pages[1][1].IsArchived = true
+
+	_ = fs.Put(context.Background(), "C5", "archived", "", "")
+
+	c := &ChannelFiller{
+		s:     &fakeConversations{pages: pages},
+		store: fs,
+		l:     zerolog.Nop(),
+	}
+
+	for _, id := range []string{"C2", "C5"} {
+		if err := c.Update(context.Background(), id); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// renamed, and the archived channel removed
+	want := map[string]string{"golang-newbies": "C2"}
+
+	if diff := cmp.Diff(want, fs.names); diff != "" {
+		t.Fatalf("names mismatch (-want +got):\n%s", diff)
+	}
+}
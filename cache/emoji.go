@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const (
+	redisEmojiKey    = "cache:emoji"
+	redisEmojiTmpKey = "cache:emoji:tmp"
+
+	emojiCacheTTL = 3 * 24 * time.Hour // 3 days
+)
+
+type emojiStore struct {
+	r *redis.Client
+}
+
+// Replace atomically replaces the cached emoji, so that removed emoji are
+// dropped from the cache.
+func (s *emojiStore) Replace(ctx context.Context, emoji map[string]string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if len(emoji) == 0 {
+		return s.r.Del(ctx, redisEmojiKey).Err()
+	}
+
+	fields := make(map[string]interface{}, len(emoji))
+
+	for name, v := range emoji {
+		fields[name] = v
+	}
+
+	pipe := s.r.TxPipeline()
+	pipe.Del(ctx, redisEmojiTmpKey)
+	pipe.HMSet(ctx, redisEmojiTmpKey, fields)
+	pipe.Rename(ctx, redisEmojiTmpKey, redisEmojiKey)
+	pipe.Expire(ctx, redisEmojiKey, emojiCacheTTL)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to replace emoji: %w", err)
+	}
+
+	return nil
+}
+
+func (s *emojiStore) Get(ctx context.Context, name string) (string, bool, error) {
+	select {
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	default:
+		// noop
+	}
+
+	v, err := s.r.HGet(ctx, redisEmojiKey, name).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", true, nil
+		}
+
+		return "", false, fmt.Errorf("failed to HGET redis key: %w", err)
+	}
+
+	return v, false, nil
+}
+
+// EmojiFiller is the custom emoji cache filler.
+type EmojiFiller struct {
+	s     *slack.Client
+	store *emojiStore
+	l     zerolog.Logger
+}
+
+// NewEmojiFiller generates a new custom emoji cache populator.
+func NewEmojiFiller(sc *slack.Client, rc *redis.Client, logger zerolog.Logger) (*EmojiFiller, error) {
+	res := rc.Set(context.Background(), redisEmojiKey+":populator_test_id_should_be_auto_removed", "foobar", time.Second)
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to set test key: %w", err)
+	}
+
+	return &EmojiFiller{
+		s:     sc,
+		store: &emojiStore{r: rc},
+		l:     logger,
+	}, nil
+}
+
+// Fill loads the cache.
+func (e *EmojiFiller) Fill(ctx context.Context) error {
+	emoji, err := e.s.GetEmojiContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get emoji: %w", err)
+	}
+
+	if err = e.store.Replace(ctx, emoji); err != nil {
+		return err
+	}
+
+	e.l.Debug().
+		Int("processed_count", len(emoji)).
+		Msg("processed emoji")
+
+	return nil
+}
+
+// Emoji represents a Redis-backed cache of the workspace's custom emoji. The
+// standard emoji aren't included, as Slack doesn't list them.
+type Emoji struct {
+	store *emojiStore
+}
+
+// NewEmoji creates a new custom emoji cache.
+func NewEmoji(rc *redis.Client) *Emoji {
+	return &Emoji{store: &emojiStore{r: rc}}
+}
+
+// Emoji finds a custom emoji by its name, without the surrounding colons, and
+// returns its image URL or "alias:" followed by the emoji it's an alias for.
+// If the emoji is not found, err will be nil and notFound true.
+func (e *Emoji) Emoji(name string) (value string, notFound bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	return e.store.Get(ctx, name)
+}
@@ -17,7 +17,7 @@ func init() {
 
 // InMemChannel represents a channel info cache.
 type InMemChannel struct {
-	sc *slack.Client
+	sc conversationLister
 	l  zerolog.Logger
 
 	mu        *sync.RWMutex
@@ -48,9 +48,9 @@ func NewInMemChannel(sc *slack.Client, l zerolog.Logger) (*InMemChannel, error)
 }
 
 func (s *InMemChannel) update(ctx context.Context) error {
-	chans, err := s.sc.GetChannelsContext(ctx, true)
+	chans, err := listChannels(ctx, s.sc)
 	if err != nil {
-		return fmt.Errorf("failed to get channels: %w", err)
+		return err
 	}
 
 	cs := make(map[string]slack.Channel, len(chans))
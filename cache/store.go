@@ -4,9 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/go-redis/redis"
+	"github.com/redis/go-redis/v9"
 	"github.com/slack-go/slack"
 )
 
@@ -22,7 +23,7 @@ type store struct {
 func (s *store) Hash(ctx context.Context, id string) (string, bool, error) {
 	key := fmt.Sprintf("%s%s:hash", redisByIDPrefix, id)
 
-	res := s.r.Get(key)
+	res := s.r.Get(ctx, key)
 	if err := res.Err(); err != nil {
 		if err == redis.Nil {
 			return "", true, nil
@@ -40,7 +41,7 @@ func (s *store) Hash(ctx context.Context, id string) (string, bool, error) {
 }
 
 func (s *store) TTL(ctx context.Context, id string) (time.Duration, bool, error) {
-	res := s.r.TTL(redisByIDPrefix + id)
+	res := s.r.TTL(ctx, redisByIDPrefix+id)
 	if err := res.Err(); err != nil {
 		if err == redis.Nil {
 			return 0, true, nil
@@ -60,17 +61,17 @@ func (s *store) TTL(ctx context.Context, id string) (time.Duration, bool, error)
 const channelCacheTTL = 14 * 24 * time.Hour // 14 days
 
 func (s *store) Put(ctx context.Context, id, name, data, hash string) error {
-	res := s.r.Set(redisByIDPrefix+id, data, channelCacheTTL)
+	res := s.r.Set(ctx, redisByIDPrefix+id, data, channelCacheTTL)
 	if err := res.Err(); err != nil {
 		return fmt.Errorf("failed to set channel data: %w", err)
 	}
 
-	res = s.r.Set(redisByNamePrefix+name, id, channelCacheTTL)
+	res = s.r.Set(ctx, redisByNamePrefix+name, id, channelCacheTTL)
 	if err := res.Err(); err != nil {
 		return fmt.Errorf("failed to set name to ID mapping: %w", err)
 	}
 
-	res = s.r.Set(redisByIDPrefix+id+":hash", hash, channelCacheTTL)
+	res = s.r.Set(ctx, redisByIDPrefix+id+":hash", hash, channelCacheTTL)
 	if err := res.Err(); err != nil {
 		return fmt.Errorf("failed to set channel data hash: %w", err)
 	}
@@ -78,8 +79,25 @@ func (s *store) Put(ctx context.Context, id, name, data, hash string) error {
 	return nil
 }
 
+// Delete removes the channel, and its name if it still maps to the channel.
+func (s *store) Delete(ctx context.Context, id, name string) error {
+	nameKey := redisByNamePrefix + name
+
+	if mapped, err := s.r.Get(ctx, nameKey).Result(); err == nil && mapped == id {
+		if err := s.r.Del(ctx, nameKey).Err(); err != nil {
+			return fmt.Errorf("failed to delete name to ID mapping: %w", err)
+		}
+	}
+
+	if err := s.r.Del(ctx, redisByIDPrefix+id, redisByIDPrefix+id+":hash").Err(); err != nil {
+		return fmt.Errorf("failed to delete channel data: %w", err)
+	}
+
+	return nil
+}
+
 func (s *store) GetByID(ctx context.Context, id string) (slack.Channel, bool, error) {
-	res := s.r.Get(redisByIDPrefix + id)
+	res := s.r.Get(ctx, redisByIDPrefix+id)
 	if err := res.Err(); err != nil {
 		if err == redis.Nil {
 			return slack.Channel{}, true, nil
@@ -102,7 +120,7 @@ func (s *store) GetByID(ctx context.Context, id string) (slack.Channel, bool, er
 }
 
 func (s *store) GetByName(ctx context.Context, name string) (slack.Channel, bool, error) {
-	res := s.r.Get(redisByNamePrefix + name)
+	res := s.r.Get(ctx, redisByNamePrefix+name)
 	if err := res.Err(); err != nil {
 		if err == redis.Nil {
 			return slack.Channel{}, true, nil
@@ -118,3 +136,59 @@ func (s *store) GetByName(ctx context.Context, name string) (slack.Channel, bool
 
 	return s.GetByID(ctx, id)
 }
+
+func (s *store) All(ctx context.Context) ([]slack.Channel, error) {
+	var (
+		keys   []string
+		cursor uint64
+	)
+
+	for {
+		ks, next, err := s.r.Scan(ctx, cursor, redisByIDPrefix+"*", 500).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan channel keys: %w", err)
+		}
+
+		for _, k := range ks {
+			id := strings.TrimPrefix(k, redisByIDPrefix)
+
+			// channel IDs are alphanumeric, unlike the hash and test keys
+			if strings.ContainsAny(id, ":_") {
+				continue
+			}
+
+			keys = append(keys, k)
+		}
+
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	vals, err := s.r.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
+	}
+
+	chans := make([]slack.Channel, 0, len(vals))
+
+	for _, v := range vals {
+		data, ok := v.(string)
+		if !ok {
+			continue // expired since the scan
+		}
+
+		var sc slack.Channel
+		if err := json.Unmarshal([]byte(data), &sc); err != nil {
+			return nil, err
+		}
+
+		chans = append(chans, sc)
+	}
+
+	return chans, nil
+}
@@ -0,0 +1,251 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const (
+	redisUserByIDPrefix = "cache:user:by_id:"
+
+	userCacheTTL = 3 * 24 * time.Hour // 3 days
+)
+
+// slimUser returns the subset of the user's info that we cache, as the full
+// profiles for every member of the workspace add up quickly.
+func slimUser(u slack.User) slack.User {
+	return slack.User{
+		ID:       u.ID,
+		TeamID:   u.TeamID,
+		Name:     u.Name,
+		Deleted:  u.Deleted,
+		RealName: u.RealName,
+		TZ:       u.TZ,
+		TZLabel:  u.TZLabel,
+		TZOffset: u.TZOffset,
+		Profile: slack.UserProfile{
+			RealName:    u.Profile.RealName,
+			DisplayName: u.Profile.DisplayName,
+		},
+		IsBot:             u.IsBot,
+		IsAdmin:           u.IsAdmin,
+		IsOwner:           u.IsOwner,
+		IsPrimaryOwner:    u.IsPrimaryOwner,
+		IsRestricted:      u.IsRestricted,
+		IsUltraRestricted: u.IsUltraRestricted,
+		IsAppUser:         u.IsAppUser,
+	}
+}
+
+type userStore struct {
+	r *redis.Client
+}
+
+func (s *userStore) Put(ctx context.Context, users ...slack.User) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	pipe := s.r.TxPipeline()
+
+	for _, u := range users {
+		// deactivated members aren't cached, and drop out of the cache when
+		// it's next filled
+		if u.Deleted {
+			pipe.Del(ctx, redisUserByIDPrefix+u.ID)
+			continue
+		}
+
+		j, err := json.Marshal(slimUser(u))
+		if err != nil {
+			return fmt.Errorf("failed to marshal user %s: %w", u.ID, err)
+		}
+
+		pipe.Set(ctx, redisUserByIDPrefix+u.ID, j, userCacheTTL)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set user data: %w", err)
+	}
+
+	return nil
+}
+
+// Purge deletes the user from the cache, returning how many records there
+// were. If dryRun is true, it's counted but not deleted.
+func (s *userStore) Purge(ctx context.Context, id string, dryRun bool) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	var n int64
+	var err error
+
+	if dryRun {
+		n, err = s.r.Exists(ctx, redisUserByIDPrefix+id).Result()
+	} else {
+		n, err = s.r.Del(ctx, redisUserByIDPrefix+id).Result()
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge user %s: %w", id, err)
+	}
+
+	return int(n), nil
+}
+
+func (s *userStore) GetByID(ctx context.Context, id string) (slack.User, bool, error) {
+	select {
+	case <-ctx.Done():
+		return slack.User{}, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, redisUserByIDPrefix+id)
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return slack.User{}, true, nil
+		}
+
+		return slack.User{}, false, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	data, err := res.Bytes()
+	if err != nil {
+		return slack.User{}, false, fmt.Errorf("failed to read bytes from redis result: %w", err)
+	}
+
+	var u slack.User
+	if err = json.Unmarshal(data, &u); err != nil {
+		return slack.User{}, false, err
+	}
+
+	return u, false, nil
+}
+
+// UserFiller is the user cache filler.
+type UserFiller struct {
+	s     *slack.Client
+	store *userStore
+	l     zerolog.Logger
+}
+
+// NewUserFiller generates a new user cache populator.
+func NewUserFiller(sc *slack.Client, rc *redis.Client, logger zerolog.Logger) (*UserFiller, error) {
+	res := rc.Set(context.Background(), redisUserByIDPrefix+"populator_test_id_should_be_auto_removed", "foobar", time.Second)
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to set test key: %w", err)
+	}
+
+	return &UserFiller{
+		s:     sc,
+		store: &userStore{r: rc},
+		l:     logger,
+	}, nil
+}
+
+// Fill loads the cache, a page of users at a time so that a large workspace
+// doesn't need to be held in memory. If Slack rate limits us, Fill waits as
+// long as it's asked to.
+func (u *UserFiller) Fill(ctx context.Context) error {
+	var count int
+
+	p := u.s.GetUsersPaginated(slack.GetUsersOptionLimit(200))
+
+	for {
+		var err error
+
+		p, err = p.Next(ctx)
+		if err != nil {
+			if p.Done(err) {
+				break
+			}
+
+			if rle, ok := err.(*slack.RateLimitedError); ok {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(rle.RetryAfter):
+					continue
+				}
+			}
+
+			return fmt.Errorf("failed to get users: %w", err)
+		}
+
+		if err = u.store.Put(ctx, p.Users...); err != nil {
+			return err
+		}
+
+		count += len(p.Users)
+	}
+
+	u.l.Debug().
+		Int("processed_count", count).
+		Msg("processed users")
+
+	return nil
+}
+
+// User represents a Redis-backed user cache.
+type User struct {
+	store *userStore
+	sc    *slack.Client
+}
+
+// NewUser creates a new user cache. If sc is not nil, users not yet in the
+// cache, like those who joined since it was last filled, are looked up with
+// the Slack API and added to it.
+func NewUser(rc *redis.Client, sc *slack.Client) *User {
+	return &User{
+		store: &userStore{r: rc},
+		sc:    sc,
+	}
+}
+
+// User finds a user by their ID in the cache. If the user is not found, err
+// will be nil and notFound true.
+func (u *User) User(id string) (user slack.User, notFound bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	user, notFound, err = u.store.GetByID(ctx, id)
+	if err != nil || !notFound || u.sc == nil {
+		return user, notFound, err
+	}
+
+	su, err := u.sc.GetUserInfoContext(ctx, id)
+	if err != nil {
+		if err.Error() == "user_not_found" {
+			return slack.User{}, true, nil
+		}
+
+		return slack.User{}, false, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	if err = u.store.Put(ctx, *su); err != nil {
+		return slack.User{}, false, err
+	}
+
+	return slimUser(*su), false, nil
+}
+
+// PurgeUser deletes the user's cached profile, returning how many records
+// there were. If dryRun is true, it's counted but not deleted. Deactivated
+// members aren't cached again. It satisfies purge.Store.
+func (u *User) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	return u.store.Purge(ctx, userID, dryRun)
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const (
+	redisUsergroupByIDPrefix = "cache:usergroup:by_id:"
+
+	// usergroups are refilled often, and disabled ones aren't returned by
+	// Slack, so let them expire if they stop being refilled
+	usergroupCacheTTL = 24 * time.Hour
+)
+
+type usergroupStore struct {
+	r *redis.Client
+}
+
+func (s *usergroupStore) Put(ctx context.Context, groups ...slack.UserGroup) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	pipe := s.r.TxPipeline()
+
+	for _, g := range groups {
+		j, err := json.Marshal(g)
+		if err != nil {
+			return fmt.Errorf("failed to marshal usergroup %s: %w", g.ID, err)
+		}
+
+		pipe.Set(ctx, redisUsergroupByIDPrefix+g.ID, j, usergroupCacheTTL)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set usergroup data: %w", err)
+	}
+
+	return nil
+}
+
+func (s *usergroupStore) GetByID(ctx context.Context, id string) (slack.UserGroup, bool, error) {
+	select {
+	case <-ctx.Done():
+		return slack.UserGroup{}, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, redisUsergroupByIDPrefix+id)
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return slack.UserGroup{}, true, nil
+		}
+
+		return slack.UserGroup{}, false, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	data, err := res.Bytes()
+	if err != nil {
+		return slack.UserGroup{}, false, fmt.Errorf("failed to read bytes from redis result: %w", err)
+	}
+
+	var g slack.UserGroup
+	if err = json.Unmarshal(data, &g); err != nil {
+		return slack.UserGroup{}, false, err
+	}
+
+	return g, false, nil
+}
+
+// UsergroupFiller is the usergroup (subteam) cache filler.
+type UsergroupFiller struct {
+	s     *slack.Client
+	store *usergroupStore
+	l     zerolog.Logger
+}
+
+// NewUsergroupFiller generates a new usergroup cache populator.
+func NewUsergroupFiller(sc *slack.Client, rc *redis.Client, logger zerolog.Logger) (*UsergroupFiller, error) {
+	res := rc.Set(context.Background(), redisUsergroupByIDPrefix+"populator_test_id_should_be_auto_removed", "foobar", time.Second)
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to set test key: %w", err)
+	}
+
+	return &UsergroupFiller{
+		s:     sc,
+		store: &usergroupStore{r: rc},
+		l:     logger,
+	}, nil
+}
+
+// Fill loads the cache, including each usergroup's members.
+func (u *UsergroupFiller) Fill(ctx context.Context) error {
+	groups, err := u.s.GetUserGroupsContext(ctx, slack.GetUserGroupsOptionIncludeUsers(true))
+	if err != nil {
+		return fmt.Errorf("failed to get usergroups: %w", err)
+	}
+
+	if len(groups) > 0 {
+		if err = u.store.Put(ctx, groups...); err != nil {
+			return err
+		}
+	}
+
+	u.l.Debug().
+		Int("processed_count", len(groups)).
+		Msg("processed usergroups")
+
+	return nil
+}
+
+// Usergroup represents a Redis-backed usergroup cache.
+type Usergroup struct {
+	store *usergroupStore
+}
+
+// NewUsergroup creates a new usergroup cache.
+func NewUsergroup(rc *redis.Client) *Usergroup {
+	return &Usergroup{store: &usergroupStore{r: rc}}
+}
+
+// Usergroup finds a usergroup by its ID in the cache, including its handle and
+// members. If the usergroup is not found, err will be nil and notFound true.
+func (u *Usergroup) Usergroup(id string) (group slack.UserGroup, notFound bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	return u.store.GetByID(ctx, id)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gobridge/gopherbot/cache"
+	"github.com/gobridge/gopherbot/internal/announce"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// setUpAnnouncer starts the worker that posts the announcements the pollers
+// publish.
+func setUpAnnouncer(ctx context.Context, logger zerolog.Logger, nr *notify.Router, rc *redis.Client, qh announce.QuietHours) (chan struct{}, error) {
+	logger = logger.With().Str("context", "announcer").Logger()
+
+	a, err := announce.NewAnnouncer(rc, nr, cache.NewChannel(rc), logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new announcer: %w", err)
+	}
+
+	a.SetQuietHours(qh)
+
+	w := make(chan struct{})
+
+	go func() {
+		defer close(w)
+		logger.Info().Msg("starting announcer")
+
+		if err := a.Run(ctx); err != nil {
+			logger.Error().
+				Err(err).
+				Msg("announcer stopped")
+
+			return
+		}
+
+		logger.Info().
+			Err(ctx.Err()).
+			Msg("context canceled: shutting down announcer")
+	}()
+
+	return w, nil
+}
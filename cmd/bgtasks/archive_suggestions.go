@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gobridge/gopherbot/cache"
+	"github.com/gobridge/gopherbot/internal/archive"
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// historyInterval is how long to wait between sampling each channel's history,
+// to stay within conversations.history's rate limit.
+const historyInterval = 1500 * time.Millisecond
+
+// addArchiveSuggestionsJob adds the job posting the channels that could be
+// archived to the moderators, on the first of every month.
+func addArchiveSuggestionsJob(cs *cron.Scheduler, logger zerolog.Logger, nr *notify.Router, sc *slack.Client, rc *redis.Client, months int, allowlist []string) error {
+	cc := cache.NewChannel(rc)
+
+	logger = logger.With().Str("context", "archive_suggestions").Logger()
+
+	return cs.Add(cron.Job{
+		Name:     "archive_suggestions",
+		Schedule: cron.MustParse("0 10 1 * *"),
+		Timeout:  time.Hour,
+		Jitter:   time.Minute,
+		Run: func(ctx context.Context) error {
+			f := archive.NewFinder(time.Now(), months, allowlist)
+
+			return suggestArchives(ctx, logger, nr, sc, cc, f, months)
+		},
+	})
+}
+
+// suggestArchives samples the history of each eligible channel in the cache,
+// and posts those without activity since the cutoff.
+func suggestArchives(ctx context.Context, logger zerolog.Logger, nr *notify.Router, sc *slack.Client, cc *cache.Channel, f *archive.Finder, months int) error {
+	chans, err := cc.All(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get channels: %w", err)
+	}
+
+	var (
+		candidates []archive.Candidate
+		unchecked  int
+	)
+
+	t := time.NewTicker(historyInterval)
+	defer t.Stop()
+
+	for _, ch := range chans {
+		if !f.Eligible(ch) {
+			continue
+		}
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		inactive, err := sampleHistory(ctx, sc, ch.ID, f.Cutoff())
+		if err != nil {
+			logger.Debug().
+				Err(err).
+				Str("channel_id", ch.ID).
+				Msg("failed to sample channel history")
+
+			unchecked++
+
+			continue
+		}
+
+		if inactive {
+			candidates = append(candidates, archive.NewCandidate(ch))
+		}
+	}
+
+	logger.Info().
+		Int("channel_count", len(chans)).
+		Int("candidate_count", len(candidates)).
+		Int("unchecked_count", unchecked).
+		Msg("found channels to suggest archiving")
+
+	_, err = nr.Notify(ctx, notify.Notification{
+		Source:   notify.Moderation,
+		Severity: notify.Info,
+		Summary:  "archive suggestions",
+		Options: []slack.MsgOption{
+			slack.MsgOptionText(archive.FormatDigest(months, candidates, unchecked), false),
+			slack.MsgOptionDisableLinkUnfurl(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+// sampleHistory returns whether the channel has no activity since the cutoff,
+// waiting and trying again if rate limited.
+func sampleHistory(ctx context.Context, sc *slack.Client, channelID string, cutoff time.Time) (bool, error) {
+	for {
+		res, err := sc.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID: channelID,
+			Oldest:    strconv.FormatInt(cutoff.Unix(), 10),
+			Limit:     archive.SampleSize,
+		})
+
+		var rle *slack.RateLimitedError
+
+		if errors.As(err, &rle) {
+			select {
+			case <-time.After(rle.RetryAfter):
+				continue
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		return archive.Inactive(res.Messages, res.HasMore), nil
+	}
+}
@@ -11,13 +11,29 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/go-redis/redis"
 	"github.com/gobridge/gopherbot/config"
+	"github.com/gobridge/gopherbot/internal/announce"
+	"github.com/gobridge/gopherbot/internal/audit"
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/gobridge/gopherbot/internal/digest"
+	"github.com/gobridge/gopherbot/internal/flags"
+	"github.com/gobridge/gopherbot/internal/github"
 	"github.com/gobridge/gopherbot/internal/heartbeat"
+	"github.com/gobridge/gopherbot/internal/idempotency"
+	"github.com/gobridge/gopherbot/internal/leader"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/pollerhealth"
+	"github.com/gobridge/gopherbot/internal/status"
+	"github.com/gobridge/gopherbot/internal/version"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/slack-go/slack"
 )
 
+// leaderTTL is how long the leader lock lasts without being renewed, and so
+// how long it takes for another bgtasks process to take over.
+const leaderTTL = 15 * time.Second
+
 // runServer starts the gateway HTTP server.
 func runServer(cfg config.C, logger zerolog.Logger) error {
 	// set up signal catching
@@ -31,6 +47,7 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 		Str("commit", cfg.Heroku.Commit).
 		Str("slack_client_id", cfg.Slack.ClientID).
 		Str("log_level", cfg.LogLevel.String()).
+		Str("poller_stagger", cfg.BGTasks.PollerStagger.String()).
 		Msg("configuration values")
 
 	rc := redis.NewClient(config.DefaultRedis(cfg))
@@ -43,11 +60,13 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 	lhb := logger.With().Str("context", "heartbeater").Logger()
 
 	// start checking Redis health
-	_, err := heartbeat.New(ctx, heartbeat.Config{
+	hb, err := heartbeat.New(ctx, heartbeat.Config{
 		RedisClient: rc,
 		Logger:      lhb,
 		AppName:     cfg.Heroku.AppName,
 		UID:         cfg.Heroku.DynoID,
+		Component:   "bgtasks",
+		Commit:      cfg.Heroku.Commit,
 		Warn:        4 * time.Second,
 		Fail:        8 * time.Second,
 	})
@@ -63,31 +82,151 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 		return fmt.Errorf("failed to heartbeat: %w", err)
 	}
 
-	sc := slack.New(cfg.Slack.BotAccessToken, slack.OptionHTTPClient(newHTTPClient()))
+	vr, err := version.NewRegistry(rc, version.Info{
+		Component:  "bgtasks",
+		DynoID:     cfg.Heroku.DynoID,
+		Commit:     cfg.Heroku.Commit,
+		ReleasedAt: cfg.Heroku.ReleasedAt,
+	}, logger.With().Str("context", "version").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build version registry: %w", err)
+	}
+
+	go vr.Run(ctx)
+
+	// everything the bot does in Slack is recorded in the audit log
+	als, err := audit.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build audit log store: %w", err)
+	}
+
+	at := &audit.Transport{
+		Base:   newHTTPTransport(),
+		Store:  als,
+		Actor:  "bot",
+		Logger: logger.With().Str("context", "audit").Logger(),
+	}
+
+	sc := slack.New(cfg.Slack.BotAccessToken, slack.OptionHTTPClient(at.Client()))
 
 	var shadowMode bool
 	if cfg.Env != config.Production {
 		shadowMode = true
 	}
 
-	gerritDone, err := setUpGerrit(ctx, shadowMode, logger, sc, rc)
+	overrides, err := notify.Overrides(cfg.Notify.Verbosity)
+	if err != nil {
+		return fmt.Errorf("failed to parse notification verbosity: %w", err)
+	}
+
+	nr := notify.New(sc, shadowMode, overrides, logger.With().Str("context", "notify_router").Logger())
+
+	fs, err := flags.NewStore(rc, shadowMode, logger.With().Str("context", "feature_flags").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build feature flag store: %w", err)
+	}
+
+	nr.SetFlags(fs, flags.Pollers)
+
+	journal, err := digest.NewJournal(rc)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to build digest journal: %w", err)
 	}
 
-	gotimeDone, err := setUpGoTime(ctx, shadowMode, logger, sc, rc)
+	nr.SetJournal(journal)
+
+	is, err := idempotency.NewStore(rc)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to build idempotency store: %w", err)
 	}
 
-	gotimeStatusDone, err := setUpGoTimeStatus(ctx, shadowMode, logger, sc, rc)
+	nr.SetIdempotency(is)
+
+	pub, err := announce.NewPublisher(rc)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to build announcement publisher: %w", err)
 	}
 
-	ccDone, err := setUpChannelCacheFiller(ctx, logger, sc, rc)
+	// announcements for channels in their quiet hours are held until after
+	qh, err := announce.ParseQuietHours(cfg.BGTasks.QuietHours)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to parse quiet hours: %w", err)
+	}
+
+	gh := github.New(newHTTPClient(), cfg.GitHub.Token)
+
+	// stagger the first run of each poller, so they don't all fire at once
+	scheds := &pollSchedules{rc: rc, stagger: cfg.BGTasks.PollerStagger}
+
+	// the jobs ported to cron schedules; the rest still use pollSchedules
+	cs, err := cron.NewScheduler(rc, "bgtasks", logger.With().Str("context", "cron").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build cron scheduler: %w", err)
+	}
+
+	if err = addGerritJobs(cs, shadowMode, logger, pub, nr, rc, cfg.BGTasks.CLDigestInterval, cfg.BGTasks.GerritChannels); err != nil {
+		return fmt.Errorf("failed to add gerrit jobs: %w", err)
+	}
+
+	if err = addGoTimeJob(cs, logger, pub, rc); err != nil {
+		return fmt.Errorf("failed to add gotime job: %w", err)
+	}
+
+	if err = addMastodonJob(cs, logger, pub, nr, rc, cfg.BGTasks.MastodonAccounts); err != nil {
+		return fmt.Errorf("failed to add mastodon job: %w", err)
+	}
+
+	if err = addRedditJob(cs, shadowMode, logger, pub, nr, rc, cfg.BGTasks.RedditMinScore, cfg.BGTasks.RedditChannels); err != nil {
+		return fmt.Errorf("failed to add reddit job: %w", err)
+	}
+
+	if err = addWorkshopsJob(cs, logger, pub, rc, cfg.BGTasks.WorkshopsFeedURL); err != nil {
+		return fmt.Errorf("failed to add workshops job: %w", err)
+	}
+
+	if err = addChannelCacheJob(cs, logger, sc, rc); err != nil {
+		return fmt.Errorf("failed to add channel cache job: %w", err)
+	}
+
+	if err = addGrowthReportJob(cs, logger, nr, rc); err != nil {
+		return fmt.Errorf("failed to add growth report job: %w", err)
+	}
+
+	if err = addNurtureJob(cs, logger, sc, fs, rc); err != nil {
+		return fmt.Errorf("failed to add nurture job: %w", err)
+	}
+
+	if err = addArchiveSuggestionsJob(cs, logger, nr, sc, rc, cfg.BGTasks.ArchiveInactiveMonths, cfg.BGTasks.ArchiveAllowlist); err != nil {
+		return fmt.Errorf("failed to add archive suggestions job: %w", err)
+	}
+
+	hs, err := pollerhealth.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build poller health store: %w", err)
+	}
+
+	// only one bgtasks process runs the pollers and announcer at a time, so
+	// they don't announce things twice
+	el, err := leader.New(rc, "bgtasks", cfg.Heroku.DynoID, leaderTTL, logger.With().Str("context", "leader").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build leader elector: %w", err)
+	}
+
+	ss := status.New(cfg.Heroku.AppName, cfg.Heroku.Commit, logger.With().Str("context", "status_server").Logger())
+	ss.Register("heartbeat", status.Heartbeat(hb))
+	ss.Register("leader", leaderCheck(el))
+	ss.Register("pollers", pollersCheck(scheds, cs))
+
+	if cfg.StatusPort > 0 {
+		go func() {
+			if err := ss.Run(ctx, cfg.StatusPort); err != nil {
+				logger.Error().
+					Err(err).
+					Msg("status server failed")
+			}
+		}()
+	} else {
+		logger.Info().Msg("no status port configured: not serving status")
 	}
 
 	// signal handling / graceful shutdown goroutine
@@ -101,13 +240,88 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 			Msg("shutting down bgtasks gracefully")
 	}()
 
-	logger.Info().Msg("presumably running...")
-	<-gerritDone
-	<-gotimeDone
-	<-gotimeStatusDone
-	<-ccDone
+	// runTasks runs everything until the context is canceled, which happens
+	// when shutting down or if we stop being the leader
+	runTasks := func(ctx context.Context) error {
+		cronDone := cs.Start(ctx)
+		healthDone := reportHealth(ctx, logger.With().Str("context", "poller_health").Logger(), hs, scheds, cs)
+
+		announcerDone, err := setUpAnnouncer(ctx, logger, nr, rc, qh)
+		if err != nil {
+			return err
+		}
+
+		proposalsDone, err := setUpProposals(ctx, logger, gh, rc, scheds.get("proposals", 4))
+		if err != nil {
+			return err
+		}
+
+		modReportDone, err := setUpModerationReport(ctx, logger, nr, rc, scheds.get("moderation_report", 5))
+		if err != nil {
+			return err
+		}
+
+		docsDone, err := setUpDocsIndexer(ctx, logger, rc, scheds.get("docs", 6))
+		if err != nil {
+			return err
+		}
+
+		ucDone, err := setUpUserCacheFiller(ctx, logger, sc, rc, scheds.get("user_cache", 7))
+		if err != nil {
+			return err
+		}
+
+		ugcDone, err := setUpUsergroupCacheFiller(ctx, logger, sc, rc, scheds.get("usergroup_cache", 8))
+		if err != nil {
+			return err
+		}
+
+		ecDone, err := setUpEmojiCacheFiller(ctx, logger, sc, rc, scheds.get("emoji_cache", 9))
+		if err != nil {
+			return err
+		}
+
+		eventsDone, err := setUpEvents(ctx, logger, cfg.BGTasks.EventsFeedURL, pub, rc, scheds.get("events", 10))
+		if err != nil {
+			return err
+		}
+
+		digestDone, err := setUpDigestPruner(ctx, logger, journal, scheds.get("digest_pruner", 11))
+		if err != nil {
+			return err
+		}
+
+		usageDone, err := setUpUsageReport(ctx, logger, nr, rc, scheds.get("usage_report", 12))
+		if err != nil {
+			return err
+		}
+
+		scheduledDone, err := setUpScheduledPosts(ctx, logger, nr, rc, scheds.get("scheduled_posts", 13))
+		if err != nil {
+			return err
+		}
+
+		logger.Info().Msg("presumably running...")
+		<-cronDone
+		<-healthDone
+		<-proposalsDone
+		<-modReportDone
+		<-docsDone
+		<-ucDone
+		<-ugcDone
+		<-ecDone
+		<-eventsDone
+		<-digestDone
+		<-usageDone
+		<-scheduledDone
+		<-announcerDone
+
+		return nil
+	}
+
+	logger.Info().Msg("campaigning to be leader")
 
-	return nil
+	return el.Run(ctx, runTasks)
 }
 
 func newHTTPClient() *http.Client {
@@ -1,63 +1,32 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"time"
 
-	"github.com/go-redis/redis"
+	"github.com/gobridge/gopherbot/cache"
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/slack-go/slack"
-	"github.com/gobridge/gopherbot/cache"
 )
 
-func setUpChannelCacheFiller(ctx context.Context, logger zerolog.Logger, sc *slack.Client, rc *redis.Client) (chan struct{}, error) {
+// addChannelCacheJob adds the job filling the channel cache, nightly. The
+// consumer keeps it up to date in between, from the channel change events, so
+// this only reconciles anything those missed.
+func addChannelCacheJob(cs *cron.Scheduler, logger zerolog.Logger, sc *slack.Client, rc *redis.Client) error {
 	logger = logger.With().Str("context", "channel_cache_filler").Logger()
 
 	filler, err := cache.NewChannelFiller(sc, rc, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build cache filler: %w", err)
+		return fmt.Errorf("failed to build cache filler: %w", err)
 	}
 
-	t := time.NewTimer(0)
-	w := make(chan struct{})
-
-	go func() {
-		logger.Info().Msg("starting channel cache filler")
-
-		for {
-			select {
-			case <-t.C:
-				gctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-
-				err := filler.Fill(gctx)
-
-				cancel()
-
-				t.Reset(10 * time.Minute)
-
-				if err != nil {
-					logger.Error().
-						Err(err).
-						Msg("trying cache fille again in 10 minutes")
-
-					continue
-				}
-
-				logger.Trace().
-					Msg("cache fill again in 10 minutes")
-
-			case <-ctx.Done():
-				defer close(w)
-
-				logger.Info().
-					Err(ctx.Err()).
-					Msg("context canceled: shutting down poller")
-
-				return
-			}
-		}
-	}()
-
-	return w, nil
+	return cs.Add(cron.Job{
+		Name:     "channel_cache",
+		Schedule: cron.MustParse("0 4 * * *"),
+		Timeout:  time.Minute,
+		Jitter:   10 * time.Minute,
+		Run:      filler.Fill,
+	})
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/digest"
+	"github.com/rs/zerolog"
+)
+
+// setUpDigestPruner periodically removes the entries older than
+// digest.Retention from the announcement journal, which the notification
+// router appends to as the pollers announce things.
+func setUpDigestPruner(ctx context.Context, logger zerolog.Logger, j *digest.Journal, sched pollSchedule) (chan struct{}, error) {
+	logger = logger.With().Str("context", "digest_pruner").Logger()
+
+	initialDur, err := sched.initialDelay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next digest prune time: %w", err)
+	}
+
+	logger.Info().
+		Str("timer_duration", initialDur.String()).
+		Msg("setting digest prune timer")
+
+	t := time.NewTimer(initialDur)
+	w := make(chan struct{})
+
+	go func() {
+		defer close(w)
+		logger.Info().Msg("starting digest pruner")
+
+		for {
+			select {
+			case <-t.C:
+				gctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+
+				n, err := j.Prune(gctx, time.Now().Add(-digest.Retention))
+
+				cancel()
+
+				t.Reset(24 * time.Hour)
+
+				if uerr := sched.update(ctx, 24*time.Hour); uerr != nil {
+					logger.Error().
+						Err(uerr).
+						Msg("failed to save next prune time")
+				}
+
+				if err != nil {
+					sched.failed(err)
+
+					logger.Error().
+						Err(err).
+						Msg("trying digest prune again in 24 hours")
+
+					continue
+				}
+
+				sched.succeeded()
+
+				logger.Debug().
+					Int64("pruned_count", n).
+					Msg("pruning digest journal again in 24 hours")
+
+			case <-ctx.Done():
+				logger.Info().
+					Err(ctx.Err()).
+					Msg("context canceled: shutting down poller")
+
+				return
+			}
+		}
+	}()
+
+	return w, nil
+}
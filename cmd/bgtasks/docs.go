@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/poller/docs"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+func setUpDocsIndexer(ctx context.Context, logger zerolog.Logger, rc *redis.Client, sched pollSchedule) (chan struct{}, error) {
+	ds, err := docs.NewStore(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build docs store: %w", err)
+	}
+
+	logger = logger.With().Str("context", "docs_indexer").Logger()
+
+	di, err := docs.New(ds, newHTTPClient(), logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new docs indexer: %w", err)
+	}
+
+	initialDur, err := sched.initialDelay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next docs index time: %w", err)
+	}
+
+	logger.Info().
+		Str("timer_duration", initialDur.String()).
+		Msg("setting docs index timer")
+
+	t := time.NewTimer(initialDur)
+	w := make(chan struct{})
+
+	go func() {
+		defer close(w)
+		logger.Info().Msg("starting docs indexer")
+
+		for {
+			select {
+			case <-t.C:
+				gctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+
+				err := di.Poll(gctx)
+
+				cancel()
+
+				t.Reset(24 * time.Hour)
+
+				if uerr := sched.update(ctx, 24*time.Hour); uerr != nil {
+					logger.Error().
+						Err(uerr).
+						Msg("failed to save next index time")
+				}
+
+				if err != nil {
+					sched.failed(err)
+
+					logger.Error().
+						Err(err).
+						Msg("trying docs index again in 24 hours")
+
+					continue
+				}
+
+				sched.succeeded()
+
+				logger.Trace().
+					Msg("indexing docs again in 24 hours")
+
+			case <-ctx.Done():
+				logger.Info().
+					Err(ctx.Err()).
+					Msg("context canceled: shutting down poller")
+
+				return
+			}
+		}
+	}()
+
+	return w, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// emojiCacheInterval is how often the custom emoji are mirrored.
+	emojiCacheInterval = 30 * time.Minute
+
+	emojiCacheTimeout = 30 * time.Second
+)
+
+func setUpEmojiCacheFiller(ctx context.Context, logger zerolog.Logger, sc *slack.Client, rc *redis.Client, sched pollSchedule) (chan struct{}, error) {
+	logger = logger.With().Str("context", "emoji_cache_filler").Logger()
+
+	filler, err := cache.NewEmojiFiller(sc, rc, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build emoji cache filler: %w", err)
+	}
+
+	initialDur, err := sched.initialDelay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next emoji cache poll time: %w", err)
+	}
+
+	logger.Info().
+		Str("timer_duration", initialDur.String()).
+		Msg("setting emoji cache poll timer")
+
+	t := time.NewTimer(initialDur)
+	w := make(chan struct{})
+
+	go func() {
+		logger.Info().Msg("starting emoji cache filler")
+
+		for {
+			select {
+			case <-t.C:
+				gctx, cancel := context.WithTimeout(ctx, emojiCacheTimeout)
+
+				err := filler.Fill(gctx)
+
+				cancel()
+
+				t.Reset(emojiCacheInterval)
+
+				if uerr := sched.update(ctx, emojiCacheInterval); uerr != nil {
+					logger.Error().
+						Err(uerr).
+						Msg("failed to save next poll time")
+				}
+
+				if err != nil {
+					sched.failed(err)
+
+					logger.Error().
+						Err(err).
+						Str("timer_duration", emojiCacheInterval.String()).
+						Msg("trying emoji cache fill again after timer fires")
+
+					continue
+				}
+
+				sched.succeeded()
+
+				logger.Info().
+					Str("timer_duration", emojiCacheInterval.String()).
+					Msg("resetting emoji cache poll timer")
+
+			case <-ctx.Done():
+				defer close(w)
+
+				logger.Info().
+					Err(ctx.Err()).
+					Msg("context canceled: shutting down poller")
+
+				return
+			}
+		}
+	}()
+
+	return w, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/announce"
+	"github.com/gobridge/gopherbot/internal/poller/events"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+func setUpEvents(ctx context.Context, logger zerolog.Logger, feedURL string, pub *announce.Publisher, rc *redis.Client, sched pollSchedule) (chan struct{}, error) {
+	logger = logger.With().Str("context", "events_poller").Logger()
+
+	w := make(chan struct{})
+
+	if len(feedURL) == 0 {
+		logger.Info().Msg("no events feed configured: not starting poller")
+
+		go func() {
+			<-ctx.Done()
+			close(w)
+		}()
+
+		return w, nil
+	}
+
+	es, err := events.NewStore(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build events store: %w", err)
+	}
+
+	ep, err := events.New(es, newHTTPClient(), feedURL, logger, pub.Events())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new events poller: %w", err)
+	}
+
+	initialDur, err := sched.initialDelay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next events poll time: %w", err)
+	}
+
+	logger.Info().
+		Str("timer_duration", initialDur.String()).
+		Msg("setting events poll timer")
+
+	t := time.NewTimer(initialDur)
+
+	go func() {
+		defer close(w)
+		logger.Info().Msg("starting events poller")
+
+		for {
+			select {
+			case <-t.C:
+				gctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+
+				err := ep.Poll(gctx)
+
+				cancel()
+
+				t.Reset(time.Hour)
+
+				if uerr := sched.update(ctx, time.Hour); uerr != nil {
+					logger.Error().
+						Err(uerr).
+						Msg("failed to save next poll time")
+				}
+
+				if err != nil {
+					sched.failed(err)
+
+					logger.Error().
+						Err(err).
+						Msg("trying events poll again in 1 hour")
+
+					continue
+				}
+
+				sched.succeeded()
+
+				logger.Trace().
+					Msg("polling events again in 1 hour")
+
+			case <-ctx.Done():
+				logger.Info().
+					Err(ctx.Err()).
+					Msg("context canceled: shutting down poller")
+
+				return
+			}
+		}
+	}()
+
+	return w, nil
+}
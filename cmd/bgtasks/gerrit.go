@@ -1,172 +1,112 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"time"
 
-	"github.com/go-redis/redis"
+	"github.com/gobridge/gopherbot/internal/announce"
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/gobridge/gopherbot/internal/notify"
 	"github.com/gobridge/gopherbot/internal/poller/gerrit"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
-	"github.com/slack-go/slack"
 )
 
-const (
-	gerritGopherdevChannelID = "C013XC5SU21"
-	gerritGolangclsChannelID = "C2VU4UTFZ"
-)
-
-func gerritNotifyFactory(logger zerolog.Logger, c *slack.Client, channelID string, shadowMode bool) gerrit.NotifyFunc {
-	return func(ctx context.Context, cl gerrit.CL) error {
-		if shadowMode {
-			logger.Info().
-				Bool("shadow_mode", true).
-				Msg("would announce merged CL")
+// gerritQueries are the Gerrit queries polled besides merged CLs, and how
+// their CLs are announced. Each is only polled if GOPHER_BGTASKS_GERRIT_CHANNELS
+// routes it to a channel.
+var gerritQueries = []struct {
+	query  gerrit.Query
+	kind   announce.Kind
+	source notify.Source
+}{
+	{query: gerrit.Reviewed, kind: announce.CLReviewed, source: notify.GerritReviewed},
+	{query: gerrit.Backports, kind: announce.CLBackport, source: notify.GerritBackports},
+}
 
-			return nil
+// addGerritJobs adds the jobs polling Gerrit, every 10 minutes or hourly in
+// shadow mode: one for merged CLs, and one for each other query routed to a
+// channel in channels, which maps channel IDs to query names. If
+// digestInterval is positive, merged CLs are buffered and announced together
+// by another job, once they've waited that long.
+func addGerritJobs(cs *cron.Scheduler, shadowMode bool, logger zerolog.Logger, pub *announce.Publisher, nr *notify.Router, rc *redis.Client, digestInterval time.Duration, channels map[string]string) error {
+	nf := pub.Gerrit()
+
+	if digestInterval > 0 {
+		buf, err := announce.NewCLBuffer(rc, pub, digestInterval)
+		if err != nil {
+			return fmt.Errorf("failed to build CL buffer: %w", err)
 		}
 
-		msg := fmt.Sprintf("[%d] %s: %s", cl.Number, cl.Message(), cl.Link())
+		nf = buf.Gerrit()
 
-		a := slack.Attachment{
-			Title:     cl.Subject,
-			TitleLink: cl.Link(),
-			Text:      cl.Revisions[cl.CurrentRevision].Commit.Message,
-			Footer:    cl.ChangeID,
+		err = cs.Add(cron.Job{
+			Name:     "gerrit_digest",
+			Schedule: cron.MustParse("* * * * *"),
+			Timeout:  10 * time.Second,
+			Run:      buf.Flush,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add gerrit digest job: %w", err)
 		}
+	}
 
-		opts := []slack.MsgOption{
-			slack.MsgOptionDisableLinkUnfurl(),
-			slack.MsgOptionText(msg, false),
-			slack.MsgOptionAttachments(a),
-		}
-
-		_, _, _, err := c.SendMessageContext(ctx, channelID, opts...)
-
+	if err := addGerritJob(cs, shadowMode, logger, rc, "gerrit", gerritLegacyPollTimeKey, gerrit.Merged, nf); err != nil {
 		return err
 	}
-}
 
-const gerritPollTimeKey = "bgtasks:poller:gerrit:last_refresh_ts"
+	for _, gq := range gerritQueries {
+		var routed bool
 
-func lastPoll(rc *redis.Client) (time.Time, error) {
-	res := rc.Get(gerritPollTimeKey)
-	if err := res.Err(); err != nil {
-		if err == redis.Nil {
-			return time.Time{}, nil
+		for id, name := range channels {
+			if name == gq.query.Name {
+				nr.AddRoute(gq.source, id)
+				routed = true
+			}
 		}
 
-		return time.Time{}, fmt.Errorf("failed to get key from redis: %w", err)
-	}
-
-	ts, err := res.Int64()
-	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to parse timestamp: %w", err)
-	}
-
-	return time.Unix(ts, 0), nil
-}
-
-func updateLastPoll(rc *redis.Client) error {
-	now := time.Now().UnixNano() / int64(time.Second)
+		if !routed {
+			continue
+		}
 
-	res := rc.Set(gerritPollTimeKey, now, 31*24*time.Hour)
-	if err := res.Err(); err != nil {
-		return fmt.Errorf("failed to set poll time: %w", err)
+		if err := addGerritJob(cs, shadowMode, logger, rc, "gerrit_"+gq.query.Name, "", gq.query, pub.GerritCLs(gq.kind)); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func initialTimer(last time.Time, duration time.Duration) time.Duration {
-	tu := time.Until(last.Add(duration))
-	if tu < 0 {
-		tu = 0
-	}
-	return tu
-}
+// gerritLegacyPollTimeKey is where the merged CL poller kept its last poll
+// time before it was a cron job.
+const gerritLegacyPollTimeKey = "bgtasks:poller:gerrit:last_refresh_ts"
 
-func setUpGerrit(ctx context.Context, shadowMode bool, logger zerolog.Logger, sc *slack.Client, rc *redis.Client) (chan struct{}, error) {
-	gs, err := gerrit.NewStore(rc)
+// addGerritJob adds the job, named name, polling Gerrit for the CLs matching
+// the query. If legacyKey is set, it's the job's cron.Job.LegacyKey.
+func addGerritJob(cs *cron.Scheduler, shadowMode bool, logger zerolog.Logger, rc *redis.Client, name, legacyKey string, q gerrit.Query, nf gerrit.NotifyFunc) error {
+	gs, err := gerrit.NewStore(rc, q)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build gerrit store: %w", err)
+		return fmt.Errorf("failed to build gerrit store: %w", err)
 	}
 
 	logger = logger.With().Str("context", "gerrit_poller").Logger()
 
-	hr := 10 * time.Minute  // healthy refresh duration
-	uhr := 10 * time.Minute // unhealthy refresh duration
-	cid := gerritGolangclsChannelID
-
+	sched := cron.MustParse("*/10 * * * *")
 	if shadowMode {
-		hr = 60 * time.Minute
-		cid = gerritGopherdevChannelID
+		sched = cron.MustParse("@hourly")
 	}
 
-	ln := logger.With().Str("context", "gerrit_notifier").Logger()
-	gp, err := gerrit.New(gs, newHTTPClient(), logger, gerritNotifyFactory(ln, sc, cid, shadowMode))
+	gp, err := gerrit.New(gs, newHTTPClient(), logger, q, nf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new gerrit poller: %w", err)
+		return fmt.Errorf("failed to create new gerrit poller: %w", err)
 	}
 
-	lp, err := lastPoll(rc)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get last gerrit poll time: %w", err)
-	}
-
-	initialDur := initialTimer(lp, hr)
-
-	logger.Info().
-		Str("timer_duration", initialDur.String()).
-		Msg("setting gerrit poll timer")
-
-	t := time.NewTimer(initialDur)
-	w := make(chan struct{})
-
-	go func() {
-		for {
-			select {
-			case <-t.C:
-				gctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-
-				err := gp.Poll(gctx)
-
-				cancel()
-
-				if err != nil {
-					logger.Error().
-						Err(err).
-						Str("timer_duration", uhr.String()).
-						Msg("trying gerrit poll again after timer fires")
-
-					t.Reset(uhr)
-
-					continue
-				}
-
-				t.Reset(hr)
-
-				if err = updateLastPoll(rc); err != nil {
-					logger.Error().
-						Err(err).
-						Msg("failed to save latest poll time")
-				}
-
-				logger.Info().
-					Str("timer_duration", hr.String()).
-					Msg("resetting gerrit poll timer")
-
-			case <-ctx.Done():
-				defer close(w)
-
-				logger.Info().
-					Err(ctx.Err()).
-					Msg("context canceled: shutting down poller")
-
-				return
-			}
-		}
-	}()
-
-	return w, nil
+	return cs.Add(cron.Job{
+		Name:      name,
+		Schedule:  sched,
+		Timeout:   10 * time.Second,
+		Jitter:    time.Minute,
+		Run:       gp.Poll,
+		LegacyKey: legacyKey,
+	})
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/gobridge/gopherbot/internal/growth"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// addGrowthReportJob adds the job posting how many members joined and posted
+// in the previous week, every Monday morning.
+func addGrowthReportJob(cs *cron.Scheduler, logger zerolog.Logger, nr *notify.Router, rc *redis.Client) error {
+	gs, err := growth.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build growth store: %w", err)
+	}
+
+	logger = logger.With().Str("context", "growth_report").Logger()
+
+	return cs.Add(cron.Job{
+		Name:     "growth_report",
+		Schedule: cron.MustParse("0 9 * * MON"),
+		Timeout:  20 * time.Second,
+		Jitter:   time.Minute,
+		Run: func(ctx context.Context) error {
+			if err := postGrowthReport(ctx, gs, nr, time.Now()); err != nil {
+				return err
+			}
+
+			logger.Info().Msg("posted growth report")
+
+			return nil
+		},
+	})
+}
+
+// postGrowthReport posts the growth in the week (Monday to Sunday, in UTC)
+// before the one now is in.
+func postGrowthReport(ctx context.Context, gs *growth.Store, nr *notify.Router, now time.Time) error {
+	now = now.UTC()
+
+	// days since Monday
+	offset := (int(now.Weekday()) + 6) % 7
+
+	to := time.Date(now.Year(), now.Month(), now.Day()-offset, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -7)
+
+	r, err := gs.Report(ctx, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to get growth report: %w", err)
+	}
+
+	title := "Growth for the week of " + from.Format("January 2, 2006")
+
+	_, err = nr.Notify(ctx, notify.Notification{
+		Source:   notify.Growth,
+		Severity: notify.Info,
+		Summary:  title,
+		Options: []slack.MsgOption{
+			slack.MsgOptionText(growth.FormatReport(title, r), false),
+			slack.MsgOptionDisableLinkUnfurl(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
@@ -14,6 +14,10 @@ func main() {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
+	if err := cfg.Validate(config.BGTasks); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+
 	logger := config.DefaultLogger(cfg)
 
 	if err := runServer(cfg, logger); err != nil {
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/announce"
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/poller/mastodon"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// addMastodonJob adds the job polling Mastodon accounts for new posts, every 5
+// minutes: @gotime@changelog.social, whose posts go to #gotimefm, and those in
+// channels, which maps channel IDs to the account announced there.
+func addMastodonJob(cs *cron.Scheduler, logger zerolog.Logger, pub *announce.Publisher, nr *notify.Router, rc *redis.Client, channels map[string]string) error {
+	accounts := []mastodon.Account{mastodon.GoTime}
+
+	for id, acct := range channels {
+		a, err := mastodon.ParseAccount(acct)
+		if err != nil {
+			return err
+		}
+
+		nr.AddRoute(notify.Mastodon(a.String()), id)
+
+		if !containsAccount(accounts, a) {
+			accounts = append(accounts, a)
+		}
+	}
+
+	ms, err := mastodon.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build mastodon store: %w", err)
+	}
+
+	logger = logger.With().Str("context", "mastodon_poller").Logger()
+
+	mp, err := mastodon.New(ms, newHTTPClient(), logger, 30*time.Minute, accounts, pub.Mastodon())
+	if err != nil {
+		return fmt.Errorf("failed to create new mastodon poller: %w", err)
+	}
+
+	return cs.Add(cron.Job{
+		Name:     "mastodon",
+		Schedule: cron.MustParse("*/5 * * * *"),
+		Timeout:  time.Duration(len(accounts)) * 10 * time.Second,
+		Jitter:   30 * time.Second,
+		Run:      mp.Poll,
+	})
+}
+
+func containsAccount(accounts []mastodon.Account, a mastodon.Account) bool {
+	for _, b := range accounts {
+		if a == b {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/moderation"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const moderationReportInterval = 7 * 24 * time.Hour
+
+// setUpModerationReport sets up the weekly job that posts the precision of each
+// moderation detector, based on moderator feedback, for the previous week.
+func setUpModerationReport(ctx context.Context, logger zerolog.Logger, nr *notify.Router, rc *redis.Client, sched pollSchedule) (chan struct{}, error) {
+	ms, err := moderation.NewStore(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build moderation store: %w", err)
+	}
+
+	logger = logger.With().Str("context", "moderation_report").Logger()
+
+	initialDur, err := sched.initialDelay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next moderation report time: %w", err)
+	}
+
+	logger.Info().
+		Str("timer_duration", initialDur.String()).
+		Msg("setting moderation report timer")
+
+	t := time.NewTimer(initialDur)
+	w := make(chan struct{})
+
+	go func() {
+		defer close(w)
+		logger.Info().Msg("starting moderation reporter")
+
+		for {
+			select {
+			case <-t.C:
+				rctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+
+				err := postModerationReport(rctx, ms, nr, moderation.Week(time.Now().Add(-moderationReportInterval)))
+
+				cancel()
+
+				t.Reset(moderationReportInterval)
+
+				if uerr := sched.update(ctx, moderationReportInterval); uerr != nil {
+					logger.Error().
+						Err(uerr).
+						Msg("failed to save next report time")
+				}
+
+				if err != nil {
+					sched.failed(err)
+
+					logger.Error().
+						Err(err).
+						Msg("failed to post moderation report; trying again in 1 week")
+
+					continue
+				}
+
+				sched.succeeded()
+
+				logger.Info().
+					Msg("posted moderation report")
+
+			case <-ctx.Done():
+				logger.Info().
+					Err(ctx.Err()).
+					Msg("context canceled: shutting down moderation reporter")
+
+				return
+			}
+		}
+	}()
+
+	return w, nil
+}
+
+func postModerationReport(ctx context.Context, ms *moderation.DefaultStore, nr *notify.Router, week string) error {
+	reports, err := moderation.BuildReports(ctx, ms, week)
+	if err != nil {
+		return fmt.Errorf("failed to build reports: %w", err)
+	}
+
+	_, err = nr.Notify(ctx, notify.Notification{
+		Source:   notify.Moderation,
+		Severity: notify.Info,
+		Summary:  fmt.Sprintf("moderation report for %s", week),
+		Options: []slack.MsgOption{
+			slack.MsgOptionText(moderation.FormatReports(week, reports), false),
+			slack.MsgOptionDisableLinkUnfurl(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
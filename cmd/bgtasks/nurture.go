@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/gobridge/gopherbot/internal/flags"
+	"github.com/gobridge/gopherbot/internal/nurture"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// nurtureBatch is the most members followed up with in each run, to stay well
+// within Slack's rate limits.
+const nurtureBatch = 50
+
+// addNurtureJob adds the job sending the follow-ups to the welcome DM, and
+// following up with the members who haven't posted in a public channel a week
+// after joining, every hour.
+func addNurtureJob(cs *cron.Scheduler, logger zerolog.Logger, sc *slack.Client, fs *flags.Store, rc *redis.Client) error {
+	ns, err := nurture.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build nurture store: %w", err)
+	}
+
+	logger = logger.With().Str("context", "nurture").Logger()
+
+	return cs.Add(cron.Job{
+		Name:     "nurture",
+		Schedule: cron.MustParse("@hourly"),
+		Timeout:  time.Minute,
+		Jitter:   time.Minute,
+		Run: func(ctx context.Context) error {
+			shadowMode := fs.Shadow(flags.Welcomes)
+
+			// the welcome follow-ups go first, so the one on how to ask
+			// questions can tell who hasn't posted before they're claimed
+			// for the other follow-up
+			if err := sendWelcomeFollowUps(ctx, logger, sc, ns, shadowMode); err != nil {
+				return err
+			}
+
+			return followUp(ctx, logger, sc, ns, shadowMode)
+		},
+	})
+}
+
+// followUp sends the follow-up to each member due one. Each is claimed before
+// it's sent, so nobody is sent two, and opting out is checked right before.
+func followUp(ctx context.Context, logger zerolog.Logger, sc *slack.Client, ns *nurture.Store, shadowMode bool) error {
+	now := time.Now()
+
+	due, err := ns.Due(ctx, now.Add(-nurture.FollowUpAfter), nurtureBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range due {
+		switch nurture.Decide(p.Joined, now) {
+		case nurture.Wait:
+			continue
+
+		case nurture.Drop:
+			if _, err := ns.Claim(ctx, p.UserID); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if shadowMode {
+			logger.Info().
+				Str("user_id", p.UserID).
+				Bool("shadow_mode", true).
+				Msg("would follow up with member")
+
+			continue
+		}
+
+		claimed, err := ns.Claim(ctx, p.UserID)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		optedOut, err := ns.OptedOut(ctx, p.UserID)
+		if err != nil {
+			return err
+		}
+
+		if optedOut {
+			continue
+		}
+
+		sent, err := sendFollowUpDM(ctx, logger, sc, p.UserID, nurture.FollowUpMessage)
+		if err != nil {
+			return err
+		}
+
+		if !sent {
+			continue
+		}
+
+		logger.Info().
+			Str("user_id", p.UserID).
+			Time("joined_time", p.Joined).
+			Msg("followed up with member")
+	}
+
+	return nil
+}
+
+// sendWelcomeFollowUps sends each of the welcome follow-ups that are due. Like
+// the follow-up for members who haven't posted, each is claimed before it's
+// sent. The one on how to ask questions isn't sent to the members who haven't
+// posted, who get that follow-up instead.
+func sendWelcomeFollowUps(ctx context.Context, logger zerolog.Logger, sc *slack.Client, ns *nurture.Store, shadowMode bool) error {
+	now := time.Now()
+
+	due, err := ns.DueWelcomes(ctx, now, nurtureBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range due {
+		if shadowMode {
+			logger.Info().
+				Str("user_id", d.UserID).
+				Str("step", string(d.Step)).
+				Bool("shadow_mode", true).
+				Msg("would send welcome follow-up")
+
+			continue
+		}
+
+		msg, claimed, err := ns.ClaimWelcome(ctx, d)
+		if err != nil {
+			return err
+		}
+
+		if !claimed || len(msg) == 0 || now.Sub(d.Due) > nurture.WelcomeLate {
+			continue
+		}
+
+		optedOut, err := ns.OptedOut(ctx, d.UserID)
+		if err != nil {
+			return err
+		}
+
+		if optedOut {
+			continue
+		}
+
+		if d.Step == nurture.AskingStep {
+			pending, err := ns.IsPending(ctx, d.UserID)
+			if err != nil {
+				return err
+			}
+
+			if pending {
+				continue
+			}
+		}
+
+		sent, err := sendFollowUpDM(ctx, logger, sc, d.UserID, msg)
+		if err != nil {
+			return err
+		}
+
+		if !sent {
+			continue
+		}
+
+		if err = ns.MarkWelcomeSent(ctx, d, time.Now()); err != nil {
+			logger.Error().
+				Err(err).
+				Str("user_id", d.UserID).
+				Str("step", string(d.Step)).
+				Msg("failed to record welcome follow-up")
+		}
+
+		logger.Info().
+			Str("user_id", d.UserID).
+			Str("step", string(d.Step)).
+			Msg("sent welcome follow-up")
+	}
+
+	return nil
+}
+
+// sendFollowUpDM sends the message to the user in a DM, returning false if
+// they've since left or are a bot.
+func sendFollowUpDM(ctx context.Context, logger zerolog.Logger, sc *slack.Client, userID, msg string) (bool, error) {
+	u, err := sc.GetUserInfoContext(ctx, userID)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Str("user_id", userID).
+			Msg("failed to get user info: not following up")
+
+		return false, nil
+	}
+
+	if u.Deleted || u.IsBot {
+		return false, nil
+	}
+
+	ch, _, _, err := sc.OpenConversationContext(ctx, &slack.OpenConversationParameters{Users: []string{userID}})
+	if err != nil {
+		return false, fmt.Errorf("failed to open DM with %s: %w", userID, err)
+	}
+
+	_, _, err = sc.PostMessageContext(ctx, ch.ID,
+		slack.MsgOptionText(msg, false),
+		slack.MsgOptionDisableLinkUnfurl(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to follow up with %s: %w", userID, err)
+	}
+
+	return true, nil
+}
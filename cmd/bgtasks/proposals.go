@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/github"
+	"github.com/gobridge/gopherbot/internal/poller/proposals"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+func setUpProposals(ctx context.Context, logger zerolog.Logger, gh *github.Client, rc *redis.Client, sched pollSchedule) (chan struct{}, error) {
+	ps, err := proposals.NewStore(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build proposals store: %w", err)
+	}
+
+	logger = logger.With().Str("context", "proposals_poller").Logger()
+
+	pp, err := proposals.New(ps, gh, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new proposals poller: %w", err)
+	}
+
+	initialDur, err := sched.initialDelay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next proposals poll time: %w", err)
+	}
+
+	logger.Info().
+		Str("timer_duration", initialDur.String()).
+		Msg("setting proposals poll timer")
+
+	t := time.NewTimer(initialDur)
+	w := make(chan struct{})
+
+	go func() {
+		defer close(w)
+		logger.Info().Msg("starting proposals poller")
+
+		for {
+			select {
+			case <-t.C:
+				gctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+
+				err := pp.Poll(gctx)
+
+				cancel()
+
+				t.Reset(time.Hour)
+
+				if uerr := sched.update(ctx, time.Hour); uerr != nil {
+					logger.Error().
+						Err(uerr).
+						Msg("failed to save next poll time")
+				}
+
+				if err != nil {
+					sched.failed(err)
+
+					logger.Error().
+						Err(err).
+						Msg("trying proposals poll again in 1 hour")
+
+					continue
+				}
+
+				sched.succeeded()
+
+				logger.Trace().
+					Msg("polling proposals in 1 hour")
+
+			case <-ctx.Done():
+				logger.Info().
+					Err(ctx.Err()).
+					Msg("context canceled: shutting down poller")
+
+				return
+			}
+		}
+	}()
+
+	return w, nil
+}
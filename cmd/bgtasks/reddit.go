@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/announce"
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/poller/reddit"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// addRedditJob adds the job polling r/golang for posts with at least minScore,
+// every 15 minutes or hourly in shadow mode, announcing them in channels. It
+// isn't added if there are no channels.
+func addRedditJob(cs *cron.Scheduler, shadowMode bool, logger zerolog.Logger, pub *announce.Publisher, nr *notify.Router, rc *redis.Client, minScore int, channels []string) error {
+	if len(channels) == 0 {
+		return nil
+	}
+
+	for _, id := range channels {
+		nr.AddRoute(notify.Reddit, id)
+	}
+
+	rs, err := reddit.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build reddit store: %w", err)
+	}
+
+	logger = logger.With().Str("context", "reddit_poller").Logger()
+
+	sched := cron.MustParse("*/15 * * * *")
+	if shadowMode {
+		sched = cron.MustParse("@hourly")
+	}
+
+	rp, err := reddit.New(rs, newHTTPClient(), logger, minScore, pub.Reddit())
+	if err != nil {
+		return fmt.Errorf("failed to create new reddit poller: %w", err)
+	}
+
+	return cs.Add(cron.Job{
+		Name:     "reddit",
+		Schedule: sched,
+		Timeout:  10 * time.Second,
+		Jitter:   time.Minute,
+		Run:      rp.Poll,
+	})
+}
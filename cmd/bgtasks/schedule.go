@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const pollScheduleKeyFormat = "bgtasks:poller:%s:next_run_ts"
+
+// pollSchedule persists when a poller should next run, so that restarts (like
+// deploys) don't cause every poller to fire immediately.
+type pollSchedule struct {
+	rc      *redis.Client
+	name    string
+	key     string
+	stagger time.Duration
+
+	// stats is shared by copies of the pollSchedule, for the status server
+	stats *pollStats
+}
+
+// pollStats tracks how a poller's runs have gone since startup.
+type pollStats struct {
+	mu          sync.Mutex
+	lastRun     time.Time
+	lastSuccess time.Time
+	lastError   string
+	interval    time.Duration
+	next        time.Time
+}
+
+// newPollSchedule returns a pollSchedule for the named poller. The stagger is
+// the minimum amount of time to wait before the first run after startup.
+func newPollSchedule(rc *redis.Client, name string, stagger time.Duration) pollSchedule {
+	return pollSchedule{
+		rc:      rc,
+		name:    name,
+		key:     fmt.Sprintf(pollScheduleKeyFormat, name),
+		stagger: stagger,
+		stats:   &pollStats{},
+	}
+}
+
+// next returns the persisted next run time. If notFound is true, there was no
+// next run time persisted.
+func (p pollSchedule) next(ctx context.Context) (next time.Time, notFound bool, err error) {
+	res := p.rc.Get(ctx, p.key)
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return time.Time{}, true, nil
+		}
+
+		return time.Time{}, false, fmt.Errorf("failed to get key from redis: %w", err)
+	}
+
+	ts, err := res.Int64()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to parse timestamp: %w", err)
+	}
+
+	return time.Unix(ts, 0), false, nil
+}
+
+// initialDelay returns how long the poller should wait before its first run.
+// It's the larger of the stagger and the time until the persisted next run.
+func (p pollSchedule) initialDelay(ctx context.Context) (time.Duration, error) {
+	next, notFound, err := p.next(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	d := p.stagger
+
+	if !notFound {
+		if tu := time.Until(next); tu > d {
+			d = tu
+		}
+	}
+
+	return d, nil
+}
+
+// update persists the next run time as d from now. Pollers call it after each
+// run, whether or not it succeeded.
+func (p pollSchedule) update(ctx context.Context, d time.Duration) error {
+	p.stats.mu.Lock()
+	p.stats.lastRun = time.Now()
+	p.stats.interval = d
+	p.stats.next = p.stats.lastRun.Add(d)
+	p.stats.mu.Unlock()
+
+	next := time.Now().Add(d).Unix()
+
+	res := p.rc.Set(ctx, p.key, next, d+24*time.Hour)
+	if err := res.Err(); err != nil {
+		return fmt.Errorf("failed to set next run time: %w", err)
+	}
+
+	return nil
+}
+
+// succeeded records that the poller's latest run succeeded.
+func (p pollSchedule) succeeded() {
+	p.stats.mu.Lock()
+	p.stats.lastSuccess = time.Now()
+	p.stats.lastError = ""
+	p.stats.mu.Unlock()
+}
+
+// failed records that the poller's latest run failed, and why.
+func (p pollSchedule) failed(err error) {
+	p.stats.mu.Lock()
+	p.stats.lastError = err.Error()
+	p.stats.mu.Unlock()
+}
+
+// pollSchedules creates the pollers' schedules, keeping them so they're reused
+// each time we become the leader and can be reported in the status.
+type pollSchedules struct {
+	rc      *redis.Client
+	stagger time.Duration
+
+	mu     sync.Mutex
+	scheds []pollSchedule
+}
+
+// get returns the named poller's schedule, creating it if needed. The first
+// run after startup waits n times the stagger.
+func (p *pollSchedules) get(name string, n int) pollSchedule {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range p.scheds {
+		if s.name == name {
+			return s
+		}
+	}
+
+	s := newPollSchedule(p.rc, name, time.Duration(n)*p.stagger)
+	p.scheds = append(p.scheds, s)
+
+	return s
+}
+
+// all returns the schedules created so far.
+func (p *pollSchedules) all() []pollSchedule {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]pollSchedule(nil), p.scheds...)
+}
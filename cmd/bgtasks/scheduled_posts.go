@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/scheduled"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// scheduledPostsInterval is how often the scheduled posts are checked, which is
+// the finest granularity of a cron expression.
+const scheduledPostsInterval = time.Minute
+
+// setUpScheduledPosts sets up the job that posts each scheduled post when its
+// cron expression fires.
+func setUpScheduledPosts(ctx context.Context, logger zerolog.Logger, nr *notify.Router, rc *redis.Client, sched pollSchedule) (chan struct{}, error) {
+	ps, err := scheduled.NewStore(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build scheduled post store: %w", err)
+	}
+
+	logger = logger.With().Str("context", "scheduled_posts").Logger()
+
+	initialDur, err := sched.initialDelay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next scheduled posts time: %w", err)
+	}
+
+	logger.Info().
+		Str("timer_duration", initialDur.String()).
+		Msg("setting scheduled posts timer")
+
+	t := time.NewTimer(initialDur)
+	w := make(chan struct{})
+
+	go func() {
+		defer close(w)
+		logger.Info().Msg("starting scheduled poster")
+
+		for {
+			select {
+			case <-t.C:
+				pctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+
+				err := postScheduled(pctx, logger, ps, nr, time.Now())
+
+				cancel()
+
+				t.Reset(scheduledPostsInterval)
+
+				if uerr := sched.update(ctx, scheduledPostsInterval); uerr != nil {
+					logger.Error().
+						Err(uerr).
+						Msg("failed to save next check time")
+				}
+
+				if err != nil {
+					sched.failed(err)
+
+					logger.Error().
+						Err(err).
+						Msg("failed to check scheduled posts; trying again in 1 minute")
+
+					continue
+				}
+
+				sched.succeeded()
+
+			case <-ctx.Done():
+				logger.Info().
+					Err(ctx.Err()).
+					Msg("context canceled: shutting down scheduled poster")
+
+				return
+			}
+		}
+	}()
+
+	return w, nil
+}
+
+// postScheduled posts each of the posts that are due. A post that's overdue,
+// like after an outage, is only posted once.
+func postScheduled(ctx context.Context, logger zerolog.Logger, ps *scheduled.Store, nr *notify.Router, now time.Time) error {
+	posts, err := ps.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list scheduled posts: %w", err)
+	}
+
+	for _, p := range posts {
+		next, err := p.Next()
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Int64("post_id", p.ID).
+				Str("schedule", p.Schedule).
+				Msg("failed to parse scheduled post's schedule")
+
+			continue
+		}
+
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+
+		// mark it first, so a failure to post isn't retried every minute, and
+		// a post removed in the meantime isn't posted
+		if err := ps.MarkRun(ctx, p.ID, now); err != nil {
+			if !errors.Is(err, scheduled.ErrNotFound) {
+				logger.Error().
+					Err(err).
+					Int64("post_id", p.ID).
+					Msg("failed to mark scheduled post run")
+			}
+
+			continue
+		}
+
+		_, err = nr.NotifyChannel(ctx, p.ChannelID, notify.Notification{
+			Source:   notify.Scheduled,
+			Severity: notify.Important,
+			Summary:  fmt.Sprintf("scheduled post %d", p.ID),
+			Options: []slack.MsgOption{
+				slack.MsgOptionText(p.Text, false),
+			},
+		})
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Int64("post_id", p.ID).
+				Str("channel_id", p.ChannelID).
+				Msg("failed to send scheduled post")
+
+			continue
+		}
+
+		logger.Info().
+			Int64("post_id", p.ID).
+			Str("channel_id", p.ChannelID).
+			Msg("sent scheduled post")
+	}
+
+	return nil
+}
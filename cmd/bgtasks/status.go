@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/gobridge/gopherbot/internal/leader"
+	"github.com/gobridge/gopherbot/internal/pollerhealth"
+	"github.com/gobridge/gopherbot/internal/status"
+	"github.com/rs/zerolog"
+)
+
+// pollStallGrace is how late a poller can be for its next run before it's
+// reported as stalled.
+const pollStallGrace = 5 * time.Minute
+
+// healthReportInterval is how often the pollers' health is saved for the
+// other components.
+const healthReportInterval = 30 * time.Second
+
+type pollerDetail struct {
+	State       status.State `json:"status"`
+	LastRun     *time.Time   `json:"last_run,omitempty"`
+	LastSuccess *time.Time   `json:"last_success,omitempty"`
+	Interval    string       `json:"interval,omitempty"`
+	Schedule    string       `json:"schedule,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.UTC()
+
+	return &t
+}
+
+// report returns how the poller is doing. Pollers that haven't run yet, like
+// those still waiting out their stagger, are OK.
+func (p pollSchedule) report() pollerDetail {
+	p.stats.mu.Lock()
+	lastRun, lastSuccess, lastError, interval := p.stats.lastRun, p.stats.lastSuccess, p.stats.lastError, p.stats.interval
+	p.stats.mu.Unlock()
+
+	d := pollerDetail{
+		State:       status.OK,
+		LastRun:     timePtr(lastRun),
+		LastSuccess: timePtr(lastSuccess),
+		Error:       lastError,
+	}
+
+	if lastRun.IsZero() {
+		return d
+	}
+
+	d.Interval = interval.String()
+
+	switch {
+	case time.Since(lastRun) > interval+pollStallGrace:
+		d.State = status.Degraded
+	case lastRun.After(lastSuccess):
+		d.State = status.Degraded
+	}
+
+	return d
+}
+
+// jobReport returns how the cron job is doing. Jobs that haven't run yet are
+// OK, unless they're well past when they were due.
+func jobReport(js cron.JobStatus) pollerDetail {
+	d := pollerDetail{
+		State:       status.OK,
+		LastRun:     timePtr(js.LastRun),
+		LastSuccess: timePtr(js.LastSuccess),
+		Schedule:    js.Schedule,
+		Error:       js.LastError,
+	}
+
+	switch {
+	case !js.Next.IsZero() && time.Since(js.Next) > pollStallGrace:
+		d.State = status.Degraded
+	case js.LastRun.After(js.LastSuccess):
+		d.State = status.Degraded
+	}
+
+	return d
+}
+
+// pollersCheck reports each poller and cron job, and is Degraded if any of
+// them are stalled or their last run failed.
+func pollersCheck(ps *pollSchedules, cs *cron.Scheduler) status.CheckFunc {
+	return func(context.Context) status.Report {
+		r := status.Report{State: status.OK}
+
+		scheds, jobs := ps.all(), cs.Status()
+		details := make(map[string]pollerDetail, len(scheds)+len(jobs))
+
+		var unhealthy []string
+
+		for _, s := range scheds {
+			d := s.report()
+			details[s.name] = d
+
+			if d.State != status.OK {
+				unhealthy = append(unhealthy, s.name)
+			}
+		}
+
+		for _, js := range jobs {
+			d := jobReport(js)
+			details[js.Name] = d
+
+			if d.State != status.OK {
+				unhealthy = append(unhealthy, js.Name)
+			}
+		}
+
+		r.Detail = details
+
+		if len(unhealthy) > 0 {
+			r.State = status.Degraded
+			r.Error = fmt.Sprintf("pollers stalled or failing: %v", unhealthy)
+		}
+
+		return r
+	}
+}
+
+// health returns how the poller is doing, to share with the other components.
+func (p pollSchedule) health(now time.Time) pollerhealth.Health {
+	p.stats.mu.Lock()
+	defer p.stats.mu.Unlock()
+
+	h := pollerhealth.Health{
+		Name:        p.name,
+		Next:        p.stats.next,
+		LastRun:     p.stats.lastRun,
+		LastSuccess: p.stats.lastSuccess,
+		LastError:   p.stats.lastError,
+		ReportedAt:  now,
+	}
+
+	if p.stats.interval > 0 {
+		h.Schedule = "every " + p.stats.interval.String()
+	}
+
+	return h
+}
+
+// pollerHealth returns how each poller and cron job is doing.
+func pollerHealth(ps *pollSchedules, cs *cron.Scheduler, now time.Time) []pollerhealth.Health {
+	scheds, jobs := ps.all(), cs.Status()
+	hs := make([]pollerhealth.Health, 0, len(scheds)+len(jobs))
+
+	for _, s := range scheds {
+		hs = append(hs, s.health(now))
+	}
+
+	for _, js := range jobs {
+		hs = append(hs, pollerhealth.Health{
+			Name:        js.Name,
+			Schedule:    js.Schedule,
+			Next:        js.Next,
+			LastRun:     js.LastRun,
+			LastSuccess: js.LastSuccess,
+			LastError:   js.LastError,
+			ReportedAt:  now,
+		})
+	}
+
+	return hs
+}
+
+// reportHealth saves how the pollers are doing in Redis until the context is
+// canceled, for the consumer's `bgtasks status` command. Only the leader
+// reports, as it's the one running them.
+func reportHealth(ctx context.Context, logger zerolog.Logger, hs *pollerhealth.Store, ps *pollSchedules, cs *cron.Scheduler) chan struct{} {
+	w := make(chan struct{})
+
+	go func() {
+		defer close(w)
+
+		t := time.NewTicker(healthReportInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				if err := hs.Save(ctx, pollerHealth(ps, cs, time.Now())); err != nil {
+					logger.Error().
+						Err(err).
+						Msg("failed to save pollers' health")
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return w
+}
+
+type leaderDetail struct {
+	Leading bool       `json:"leading"`
+	Since   *time.Time `json:"since,omitempty"`
+	Leader  string     `json:"leader,omitempty"`
+}
+
+// leaderCheck reports whether we're the leader running the pollers, and which
+// process is if we aren't. Not leading is OK, as long as someone is.
+func leaderCheck(el *leader.Elector) status.CheckFunc {
+	return func(ctx context.Context) status.Report {
+		leading, since := el.Leading()
+
+		d := leaderDetail{Leading: leading}
+		if leading {
+			d.Since = timePtr(since)
+		}
+
+		id, err := el.Leader(ctx)
+		if err != nil {
+			return status.Report{State: status.Degraded, Error: err.Error(), Detail: d}
+		}
+
+		d.Leader = id
+
+		r := status.Report{State: status.OK, Detail: d}
+
+		if len(id) == 0 {
+			r.State = status.Degraded
+			r.Error = "no bgtasks process is the leader"
+		}
+
+		return r
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/usage"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// monthStart returns the start of t's month, in UTC.
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// untilNextMonth returns how long it is from now until the start of next
+// month, in UTC.
+func untilNextMonth(now time.Time) time.Duration {
+	return monthStart(now).AddDate(0, 1, 0).Sub(now)
+}
+
+// setUpUsageReport sets up the monthly job that posts how often each command
+// was used in the previous month, so the maintainers can prune the unused ones.
+func setUpUsageReport(ctx context.Context, logger zerolog.Logger, nr *notify.Router, rc *redis.Client, sched pollSchedule) (chan struct{}, error) {
+	us, err := usage.NewStore(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build usage store: %w", err)
+	}
+
+	logger = logger.With().Str("context", "usage_report").Logger()
+
+	// the first report is at the start of next month, unless we missed one
+	_, notFound, err := sched.next(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next usage report time: %w", err)
+	}
+
+	initialDur := untilNextMonth(time.Now())
+
+	if !notFound {
+		if initialDur, err = sched.initialDelay(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get next usage report time: %w", err)
+		}
+	}
+
+	logger.Info().
+		Str("timer_duration", initialDur.String()).
+		Msg("setting usage report timer")
+
+	t := time.NewTimer(initialDur)
+	w := make(chan struct{})
+
+	go func() {
+		defer close(w)
+		logger.Info().Msg("starting usage reporter")
+
+		for {
+			select {
+			case <-t.C:
+				rctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+
+				// a minute in, so that the report is for the month just ended
+				err := postUsageReport(rctx, us, nr, monthStart(time.Now().Add(-time.Minute)).AddDate(0, -1, 0))
+
+				cancel()
+
+				next := untilNextMonth(time.Now())
+
+				t.Reset(next)
+
+				if uerr := sched.update(ctx, next); uerr != nil {
+					logger.Error().
+						Err(uerr).
+						Msg("failed to save next report time")
+				}
+
+				if err != nil {
+					sched.failed(err)
+
+					logger.Error().
+						Err(err).
+						Msg("failed to post usage report; trying again next month")
+
+					continue
+				}
+
+				sched.succeeded()
+
+				logger.Info().
+					Msg("posted usage report")
+
+			case <-ctx.Done():
+				logger.Info().
+					Err(ctx.Err()).
+					Msg("context canceled: shutting down usage reporter")
+
+				return
+			}
+		}
+	}()
+
+	return w, nil
+}
+
+// postUsageReport posts the usage of the month starting at month.
+func postUsageReport(ctx context.Context, us *usage.Store, nr *notify.Router, month time.Time) error {
+	counts, err := us.Counts(ctx, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return fmt.Errorf("failed to get usage counts: %w", err)
+	}
+
+	triggers, err := us.Triggers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get triggers: %w", err)
+	}
+
+	title := "Command usage for " + month.Format("January 2006")
+
+	_, err = nr.Notify(ctx, notify.Notification{
+		Source:   notify.Usage,
+		Severity: notify.Info,
+		Summary:  title,
+		Options: []slack.MsgOption{
+			slack.MsgOptionText(usage.FormatReport(title, counts, triggers), false),
+			slack.MsgOptionDisableLinkUnfurl(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
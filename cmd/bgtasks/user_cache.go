@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// userCacheInterval is how often the whole user list is mirrored. Users
+	// not yet in the cache are looked up on demand, so this can be long.
+	userCacheInterval = 12 * time.Hour
+
+	// userCacheTimeout is how long a fill can take, as users.list is heavily
+	// rate limited and large workspaces take many pages.
+	userCacheTimeout = 30 * time.Minute
+)
+
+func setUpUserCacheFiller(ctx context.Context, logger zerolog.Logger, sc *slack.Client, rc *redis.Client, sched pollSchedule) (chan struct{}, error) {
+	logger = logger.With().Str("context", "user_cache_filler").Logger()
+
+	filler, err := cache.NewUserFiller(sc, rc, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build user cache filler: %w", err)
+	}
+
+	initialDur, err := sched.initialDelay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next user cache poll time: %w", err)
+	}
+
+	logger.Info().
+		Str("timer_duration", initialDur.String()).
+		Msg("setting user cache poll timer")
+
+	t := time.NewTimer(initialDur)
+	w := make(chan struct{})
+
+	go func() {
+		logger.Info().Msg("starting user cache filler")
+
+		for {
+			select {
+			case <-t.C:
+				gctx, cancel := context.WithTimeout(ctx, userCacheTimeout)
+
+				err := filler.Fill(gctx)
+
+				cancel()
+
+				t.Reset(userCacheInterval)
+
+				if uerr := sched.update(ctx, userCacheInterval); uerr != nil {
+					logger.Error().
+						Err(uerr).
+						Msg("failed to save next poll time")
+				}
+
+				if err != nil {
+					sched.failed(err)
+
+					logger.Error().
+						Err(err).
+						Str("timer_duration", userCacheInterval.String()).
+						Msg("trying user cache fill again after timer fires")
+
+					continue
+				}
+
+				sched.succeeded()
+
+				logger.Info().
+					Str("timer_duration", userCacheInterval.String()).
+					Msg("resetting user cache poll timer")
+
+			case <-ctx.Done():
+				defer close(w)
+
+				logger.Info().
+					Err(ctx.Err()).
+					Msg("context canceled: shutting down poller")
+
+				return
+			}
+		}
+	}()
+
+	return w, nil
+}
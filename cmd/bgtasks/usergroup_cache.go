@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// usergroupCacheInterval is how often the usergroups are mirrored. It's a
+	// single API call, so membership changes can be picked up quickly.
+	usergroupCacheInterval = time.Hour
+
+	usergroupCacheTimeout = 30 * time.Second
+)
+
+func setUpUsergroupCacheFiller(ctx context.Context, logger zerolog.Logger, sc *slack.Client, rc *redis.Client, sched pollSchedule) (chan struct{}, error) {
+	logger = logger.With().Str("context", "usergroup_cache_filler").Logger()
+
+	filler, err := cache.NewUsergroupFiller(sc, rc, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build usergroup cache filler: %w", err)
+	}
+
+	initialDur, err := sched.initialDelay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next usergroup cache poll time: %w", err)
+	}
+
+	logger.Info().
+		Str("timer_duration", initialDur.String()).
+		Msg("setting usergroup cache poll timer")
+
+	t := time.NewTimer(initialDur)
+	w := make(chan struct{})
+
+	go func() {
+		logger.Info().Msg("starting usergroup cache filler")
+
+		for {
+			select {
+			case <-t.C:
+				gctx, cancel := context.WithTimeout(ctx, usergroupCacheTimeout)
+
+				err := filler.Fill(gctx)
+
+				cancel()
+
+				t.Reset(usergroupCacheInterval)
+
+				if uerr := sched.update(ctx, usergroupCacheInterval); uerr != nil {
+					logger.Error().
+						Err(uerr).
+						Msg("failed to save next poll time")
+				}
+
+				if err != nil {
+					sched.failed(err)
+
+					logger.Error().
+						Err(err).
+						Str("timer_duration", usergroupCacheInterval.String()).
+						Msg("trying usergroup cache fill again after timer fires")
+
+					continue
+				}
+
+				sched.succeeded()
+
+				logger.Info().
+					Str("timer_duration", usergroupCacheInterval.String()).
+					Msg("resetting usergroup cache poll timer")
+
+			case <-ctx.Done():
+				defer close(w)
+
+				logger.Info().
+					Err(ctx.Err()).
+					Msg("context canceled: shutting down poller")
+
+				return
+			}
+		}
+	}()
+
+	return w, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/announce"
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/gobridge/gopherbot/internal/poller/workshops"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// addWorkshopsJob adds the hourly job announcing upcoming workshops, from the
+// feed at feedURL if it's set, and from the submissions Workspace Admins have
+// approved.
+func addWorkshopsJob(cs *cron.Scheduler, logger zerolog.Logger, pub *announce.Publisher, rc *redis.Client, feedURL string) error {
+	ws, err := workshops.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build workshops store: %w", err)
+	}
+
+	logger = logger.With().Str("context", "workshops_poller").Logger()
+
+	wp, err := workshops.New(ws, newHTTPClient(), feedURL, logger, pub.Workshops())
+	if err != nil {
+		return fmt.Errorf("failed to create new workshops poller: %w", err)
+	}
+
+	return cs.Add(cron.Job{
+		Name:     "workshops",
+		Schedule: cron.MustParse("@hourly"),
+		Timeout:  30 * time.Second,
+		Jitter:   time.Minute,
+		Run:      wp.Poll,
+	})
+}
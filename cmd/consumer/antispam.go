@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/antispam"
+	"github.com/gobridge/gopherbot/internal/moderation"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const spamDetector = "spam"
+
+// injectSpamHandlers sets up the scoring of messages in public channels for
+// spam. Messages scoring at least flagThreshold are flagged to the moderators,
+// and those scoring at least deleteThreshold are deleted when the detector is
+// in the moderation.Enforce mode.
+func injectSpamHandlers(ma *handler.MessageActions, del *antispam.Deleter, mod *moderation.Moderator, flagThreshold, deleteThreshold float64) {
+	match := func(_ bool, m handler.Messenger) bool {
+		return m.ChannelType() == handler.ChannelPublic
+	}
+
+	ma.HandleDynamic(match, func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+		score := antispam.Rate(m.RawText(), m.AllMentions())
+
+		if score.Total < flagThreshold {
+			return nil
+		}
+
+		var enforce moderation.EnforceFunc
+
+		if score.Total >= deleteThreshold {
+			enforce = func(ctx context.Context) error {
+				return del.Delete(ctx, m.ChannelID(), m.MessageTS())
+			}
+		}
+
+		return mod.Flag(ctx, moderation.Decision{
+			Detector:  spamDetector,
+			ChannelID: m.ChannelID(),
+			UserID:    m.UserID(),
+			MessageTS: m.MessageTS(),
+			Score:     score.Total,
+			Threshold: flagThreshold,
+			Reason:    score.Reason(),
+		}, enforce)
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/audit"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const (
+	auditUsage = "Usage: `audit last <n>` shows the last n things I did in Slack, up to 100: the messages I sent, reactions I added, and moderation actions I took. In a DM, `audit export <n>` sends up to the last 10000 as a snippet."
+
+	// auditMaxEntries is the most entries the audit command shows at once.
+	auditMaxEntries = 100
+
+	// auditMaxExport is the most entries exported at once.
+	auditMaxExport = 10000
+)
+
+// injectAuditHandlers registers the command Workspace Admins use to see what
+// the bot did recently, from the audit log.
+func injectAuditHandlers(ma *handler.MessageActions, als *audit.Store) {
+	ma.HandleCommand("audit", auditUsage, "(admins only) `audit last 20` shows the last 20 things I did in Slack",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can see the audit log.")
+			}
+
+			if len(c.Args) != 2 {
+				return &handler.UsageError{}
+			}
+
+			limit := auditMaxEntries
+
+			switch strings.ToLower(c.Arg(0)) {
+			case "last":
+			case "export":
+				// the log isn't for everyone in the channel to see
+				if m.ChannelType() != handler.ChannelDM {
+					return r.RespondEphemeral(ctx, "Sorry, I only export the audit log in a DM.")
+				}
+
+				limit = auditMaxExport
+			default:
+				return &handler.UsageError{}
+			}
+
+			n, err := strconv.Atoi(c.Arg(1))
+			if err != nil || n < 1 || n > limit {
+				return handler.Usagef("`%s` isn't a number from 1 to %d", c.Arg(1), limit)
+			}
+
+			entries, err := als.Last(ctx, n)
+			if err != nil {
+				return fmt.Errorf("failed to get audit log: %w", err)
+			}
+
+			if len(entries) == 0 {
+				return r.RespondEphemeral(ctx, "The audit log is empty.")
+			}
+
+			if strings.EqualFold(c.Arg(0), "export") {
+				return r.RespondSnippet(ctx, fmt.Sprintf("Audit log, last %d entries", len(entries)), "text", formatAuditEntries(entries))
+			}
+
+			return r.RespondEphemeralTextAttachment(ctx, fmt.Sprintf("The last %d things I did, newest first:", len(entries)), formatAuditEntries(entries))
+		},
+	)
+}
+
+func formatAuditEntries(entries []audit.Entry) string {
+	var b strings.Builder
+
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s %s %s", e.Time.UTC().Format("2006-01-02 15:04:05"), e.Actor, e.Action)
+
+		if len(e.ChannelID) > 0 {
+			fmt.Fprintf(&b, " in <#%s>", e.ChannelID)
+		}
+
+		for i, id := range e.UserIDs {
+			if i == 0 {
+				b.WriteString(" on")
+			}
+
+			fmt.Fprintf(&b, " <@%s>", id)
+		}
+
+		if len(e.Trigger) > 0 {
+			fmt.Fprintf(&b, " for event %s", e.Trigger)
+		}
+
+		if len(e.Error) > 0 {
+			fmt.Fprintf(&b, " (failed: %s)", e.Error)
+		}
+
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
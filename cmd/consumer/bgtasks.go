@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/pollerhealth"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const bgtasksUsage = "Usage: `bgtasks status` shows when each of the background pollers last succeeded, why it last failed, and when it runs next."
+
+// healthStaleAfter is how long after bgtasks last saved the pollers' health
+// it's assumed to have stopped reporting.
+const healthStaleAfter = 2 * time.Minute
+
+// injectBGTasksHandlers registers the command reporting how the bgtasks
+// pollers are doing.
+func injectBGTasksHandlers(ma *handler.MessageActions, hs *pollerhealth.Store) {
+	ma.HandleCommand("bgtasks", bgtasksUsage, "(admins only) `bgtasks status` shows how the background pollers are doing",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			if len(c.Args) != 1 || !strings.EqualFold(c.Arg(0), "status") {
+				return &handler.UsageError{}
+			}
+
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can see how the pollers are doing.")
+			}
+
+			pollers, err := hs.All(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get pollers' health: %w", err)
+			}
+
+			if len(pollers) == 0 {
+				return r.RespondEphemeral(ctx, "bgtasks hasn't reported how its pollers are doing yet.")
+			}
+
+			return r.RespondEphemeralTextAttachment(ctx, "Here's how the pollers are doing:", formatPollerHealth(pollers, time.Now()))
+		},
+	)
+}
+
+func formatPollerHealth(pollers []pollerhealth.Health, now time.Time) string {
+	var b strings.Builder
+
+	var reportedAt time.Time
+
+	for _, p := range pollers {
+		if p.ReportedAt.After(reportedAt) {
+			reportedAt = p.ReportedAt
+		}
+
+		emoji := ":white_check_mark:"
+		if p.Failing() {
+			emoji = ":x:"
+		}
+
+		fmt.Fprintf(&b, "%s *%s*", emoji, p.Name)
+
+		if len(p.Schedule) > 0 {
+			fmt.Fprintf(&b, " (`%s`)", p.Schedule)
+		}
+
+		if p.LastSuccess.IsZero() {
+			b.WriteString(": no successful run yet")
+		} else {
+			fmt.Fprintf(&b, ": last succeeded %s ago", formatUptime(now.Sub(p.LastSuccess)))
+		}
+
+		if d := p.Next.Sub(now); !p.Next.IsZero() && d > 0 {
+			fmt.Fprintf(&b, ", next run in %s", formatUptime(d))
+		}
+
+		if len(p.LastError) > 0 {
+			fmt.Fprintf(&b, "\n    last error: %s", p.LastError)
+		}
+
+		b.WriteByte('\n')
+	}
+
+	if since := now.Sub(reportedAt); since > healthStaleAfter {
+		fmt.Fprintf(&b, "\n:warning: bgtasks last reported %s ago, so it may not be running.\n", formatUptime(since))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/pollerhealth"
+)
+
+func Test_formatPollerHealth(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	pollers := []pollerhealth.Health{
+		{
+			Name:        "gerrit",
+			Schedule:    "*/10 * * * *",
+			Next:        now.Add(4 * time.Minute),
+			LastRun:     now.Add(-6 * time.Minute),
+			LastSuccess: now.Add(-6 * time.Minute),
+			ReportedAt:  now.Add(-10 * time.Second),
+		},
+		{
+			Name:        "mastodon",
+			Schedule:    "every 5m0s",
+			Next:        now.Add(2 * time.Minute),
+			LastRun:     now.Add(-3 * time.Minute),
+			LastSuccess: now.Add(-2 * time.Hour),
+			LastError:   "context deadline exceeded",
+			ReportedAt:  now.Add(-10 * time.Second),
+		},
+		{
+			Name:       "user_cache",
+			ReportedAt: now.Add(-10 * time.Second),
+		},
+	}
+
+	want := ":white_check_mark: *gerrit* (`*/10 * * * *`): last succeeded 6m ago, next run in 4m\n" +
+		":x: *mastodon* (`every 5m0s`): last succeeded 2h 0m ago, next run in 2m\n" +
+		"    last error: context deadline exceeded\n" +
+		":white_check_mark: *user_cache*: no successful run yet\n"
+
+	if got := formatPollerHealth(pollers, now); got != want {
+		t.Fatalf("formatPollerHealth() = %q, want %q", got, want)
+	}
+
+	t.Run("stale", func(t *testing.T) {
+		got := formatPollerHealth(pollers, now.Add(time.Hour))
+
+		if want := "\n:warning: bgtasks last reported 1h 0m ago, so it may not be running.\n"; got[len(got)-len(want):] != want {
+			t.Fatalf("formatPollerHealth() = %q, should end with %q", got, want)
+		}
+	})
+}
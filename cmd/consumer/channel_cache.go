@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/gobridge/gopherbot/cache"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// channelCacheHandler keeps the channel cache up to date as channels are
+// created, renamed, archived, or unarchived, between bgtasks' nightly refills.
+func channelCacheHandler(f *cache.ChannelFiller) workqueue.ChannelChangeHandler {
+	return func(ctx workqueue.Context, cc *workqueue.ChannelChangeEvent) (bool, bool, error) {
+		ctx.Logger().Debug().
+			Str("channel_id", cc.ChannelID).
+			Str("change", cc.Type).
+			Msg("updating channel cache")
+
+		if err := f.Update(ctx, cc.ChannelID); err != nil {
+			return true, false, err
+		}
+
+		return false, false, nil
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net"
 	"net/http"
@@ -11,20 +12,64 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/go-redis/redis"
 	"github.com/gobridge/gopherbot/cache"
 	"github.com/gobridge/gopherbot/cmd/consumer/playground"
 	"github.com/gobridge/gopherbot/config"
 	"github.com/gobridge/gopherbot/glossary"
 	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/antispam"
+	"github.com/gobridge/gopherbot/internal/audit"
+	"github.com/gobridge/gopherbot/internal/changelog"
+	"github.com/gobridge/gopherbot/internal/contrib"
+	"github.com/gobridge/gopherbot/internal/crosspost"
+	"github.com/gobridge/gopherbot/internal/digest"
+	"github.com/gobridge/gopherbot/internal/faq"
+	"github.com/gobridge/gopherbot/internal/flags"
+	"github.com/gobridge/gopherbot/internal/github"
+	"github.com/gobridge/gopherbot/internal/godl"
+	"github.com/gobridge/gopherbot/internal/growth"
 	"github.com/gobridge/gopherbot/internal/heartbeat"
+	"github.com/gobridge/gopherbot/internal/i18n"
+	"github.com/gobridge/gopherbot/internal/joins"
+	"github.com/gobridge/gopherbot/internal/kb"
+	"github.com/gobridge/gopherbot/internal/mentionsonly"
+	"github.com/gobridge/gopherbot/internal/moderation"
+	"github.com/gobridge/gopherbot/internal/modmail"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/nurture"
+	"github.com/gobridge/gopherbot/internal/outbox"
+	"github.com/gobridge/gopherbot/internal/poller/docs"
+	"github.com/gobridge/gopherbot/internal/poller/events"
+	"github.com/gobridge/gopherbot/internal/poller/proposals"
+	"github.com/gobridge/gopherbot/internal/poller/workshops"
+	"github.com/gobridge/gopherbot/internal/pollerhealth"
+	"github.com/gobridge/gopherbot/internal/prefs"
+	"github.com/gobridge/gopherbot/internal/ratelimit"
+	"github.com/gobridge/gopherbot/internal/rng"
+	"github.com/gobridge/gopherbot/internal/scheduled"
+	"github.com/gobridge/gopherbot/internal/sqlstore"
+	"github.com/gobridge/gopherbot/internal/status"
+	"github.com/gobridge/gopherbot/internal/threading"
+	"github.com/gobridge/gopherbot/internal/topicwatch"
+	"github.com/gobridge/gopherbot/internal/undo"
+	"github.com/gobridge/gopherbot/internal/unfurl"
+	"github.com/gobridge/gopherbot/internal/usage"
+	"github.com/gobridge/gopherbot/internal/version"
+	"github.com/gobridge/gopherbot/issue"
+	"github.com/gobridge/gopherbot/poll"
+	"github.com/gobridge/gopherbot/spec"
+	"github.com/gobridge/gopherbot/tip"
 	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/gobridge/gopherbot/xkcd"
+	_ "github.com/lib/pq" // registers the postgres database/sql driver
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/slack-go/slack"
 )
 
-// playgroundChannelBlacklist sets a list of channels the playground uploader will
-// not operate in
+// playgroundChannelBlacklist sets the default list of channels the playground
+// uploader will not operate in, until an admin says otherwise with the
+// "playground enable" or "playground disable" commands
 var playgroundChannelBlacklist = []string{
 	"C4U9J9QBT", // #admin-help
 	"C029RQSEG", // #random
@@ -57,6 +102,9 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, syscall.SIGTERM, syscall.SIGINT)
 
+	reloadCh := make(chan os.Signal, 1)
+	signal.Notify(reloadCh, syscall.SIGHUP)
+
 	logger.Info().
 		Str("env", string(cfg.Env)).
 		Str("app", cfg.Heroku.AppName).
@@ -65,9 +113,19 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 		Str("slack_request_token", cfg.Slack.RequestToken).
 		Str("slack_client_id", cfg.Slack.ClientID).
 		Str("log_level", cfg.LogLevel.String()).
+		Bool("slack_admin_token_set", len(cfg.Slack.AdminAccessToken) > 0).
 		Msg("configuration values")
 
-	sc := slack.New(cfg.Slack.BotAccessToken, slack.OptionHTTPClient(newHTTPClient()))
+	rc := redis.NewClient(config.DefaultRedis(cfg))
+	defer func() { _ = rc.Close() }()
+
+	// everything the bot does in Slack is recorded in the audit log
+	als, err := audit.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build audit log store: %w", err)
+	}
+
+	sc := slack.New(cfg.Slack.BotAccessToken, slack.OptionHTTPClient(auditedHTTPClient(als, "bot", logger)))
 
 	// test credentails and get self reference
 	self, err := getSelf(sc)
@@ -75,8 +133,22 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 		return err
 	}
 
-	rc := redis.NewClient(config.DefaultRedis(cfg))
-	defer func() { _ = rc.Close() }()
+	// the stores that support it use the database instead of Redis, if one is
+	// configured
+	var db *sql.DB
+
+	if len(cfg.Database.URL) > 0 {
+		db, err = sqlstore.Open(context.Background(), cfg.Database.Driver, cfg.Database.URL)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = db.Close() }()
+
+		logger.Info().
+			Str("driver", cfg.Database.Driver).
+			Msg("using database for persistent state")
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -85,11 +157,13 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 	lhb := logger.With().Str("context", "heartbeater").Logger()
 
 	// start checking Redis health
-	_, err = heartbeat.New(ctx, heartbeat.Config{
+	hb, err := heartbeat.New(ctx, heartbeat.Config{
 		RedisClient: rc,
 		Logger:      lhb,
 		AppName:     cfg.Heroku.AppName,
 		UID:         cfg.Heroku.DynoID,
+		Component:   "consumer",
+		Commit:      cfg.Heroku.Commit,
 		Warn:        4 * time.Second,
 		Fail:        8 * time.Second,
 	})
@@ -105,26 +179,76 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 		return fmt.Errorf("failed to heartbeat: %w", err)
 	}
 
+	vr, err := version.NewRegistry(rc, version.Info{
+		Component:  "consumer",
+		DynoID:     cfg.Heroku.DynoID,
+		Commit:     cfg.Heroku.Commit,
+		ReleasedAt: cfg.Heroku.ReleasedAt,
+	}, logger.With().Str("context", "version").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build version registry: %w", err)
+	}
+
+	go vr.Run(ctx)
+
 	cCache := cache.NewChannel(rc)
+	uCache := cache.NewUser(rc, sc)
+	gCache := cache.NewUsergroup(rc)
+	eCache := cache.NewEmoji(rc)
+
+	ccf, err := cache.NewChannelFiller(sc, rc, logger.With().Str("context", "channel_cache").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build channel cache filler: %w", err)
+	}
+
+	var shadowMode bool
+	if cfg.Env != config.Production {
+		shadowMode = true
+	}
+
+	overrides, err := notify.Overrides(cfg.Notify.Verbosity)
+	if err != nil {
+		return fmt.Errorf("failed to parse notification verbosity: %w", err)
+	}
+
+	nr := notify.New(sc, shadowMode, overrides, logger.With().Str("context", "notify_router").Logger())
+
+	go announceDeploy(ctx, vr, nr, cfg.Notify.DevChannelID, logger.With().Str("context", "version").Logger())
+
+	// report handler panics to the maintainers, if there's somewhere to
+	var panicHandler workqueue.PanicHandler
+	if id := cfg.Notify.ErrorsChannelID; len(id) > 0 {
+		panicHandler = newPanicReporter(nr, id, logger.With().Str("context", "panic_reporter").Logger())
+	}
 
 	// set up the workqueue
+	def, priorities, streams := streamConfigs(cfg.Workqueue)
+
 	q, err := workqueue.New(workqueue.Config{
 		ConsumerName:      cfg.Heroku.DynoID,
 		ConsumerGroup:     cfg.Heroku.AppName,
 		VisibilityTimeout: 10 * time.Second,
 		RedisClient:       rc,
+		Default:           def,
+		Priorities:        priorities,
+		Streams:           streams,
 		Logger:            &logger,
 		SlackClient:       sc,
 		SlackUser:         self,
 		ChannelCache:      cCache,
+		UserCache:         uCache,
+		UsergroupCache:    gCache,
+		EmojiCache:        eCache,
+		PanicHandler:      panicHandler,
+		Tracer:            config.DefaultTracer(ctx, cfg, "gopherbot-consumer", logger),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to build workqueue: %w", err)
 	}
 
-	var shadowMode bool
-	if cfg.Env != config.Production {
-		shadowMode = true
+	fs, err := flags.NewStore(rc, shadowMode, logger.With().Str("context", "feature_flags").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build feature flag store: %w", err)
 	}
 
 	ma, err := handler.NewMessageActions(
@@ -136,39 +260,456 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 		return fmt.Errorf("failed to build MessageActions handler: %w", err)
 	}
 
+	ma.SetFlags(fs, flags.Responses)
+
+	us, err := usage.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build usage store: %w", err)
+	}
+
+	ma.SetUsage(us)
+
+	ls, err := i18n.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build language store: %w", err)
+	}
+
+	catalog := newMessageCatalog()
+	loc := i18n.NewLocalizer(catalog, ls)
+	ma.SetLocalizer(loc)
+
+	// don't let the same reaction trigger fire over and over in busy channels
+	var reactionCooldown handler.Limiter
+	if cfg.Reactions.Cooldown > 0 {
+		rl, err := ratelimit.New(rc, "reaction_cooldown", 1, cfg.Reactions.Cooldown)
+		if err != nil {
+			return fmt.Errorf("failed to build reaction cooldown: %w", err)
+		}
+
+		reactionCooldown = rl
+	}
+
+	ma.SetReactionLimits(reactionCooldown, cfg.Reactions.RandomProbability)
+
+	// coin flips, dice, and random reactions all share the one RNG
+	rnd := rng.New()
+	ma.SetRNG(rnd)
+
+	// respond where the message was, unless the trigger or the admins say
+	// otherwise
+	ma.SetThreading(handler.ThreadingChannel)
+
+	ths, err := threading.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build threading store: %w", err)
+	}
+
+	ma.SetThreadingOverrides(ths)
+	ma.SetFollowUps(ths)
+
+	// stay quiet in the channels the admins asked, unless mentioned
+	mos, err := mentionsonly.NewStore(rc, logger.With().Str("context", "mentions_only").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build mentions-only store: %w", err)
+	}
+
+	ma.SetMentionsOnly(mos)
+
+	// remember who triggered the responses, so they can delete them
+	uds, err := undo.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build undo store: %w", err)
+	}
+
+	ma.SetReplies(uds)
+
 	gloss := glossary.New(glossary.Prefix)
 
+	ps, err := proposals.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build proposals store: %w", err)
+	}
+
+	tracker := tip.New(tip.Prefix, ps)
+
+	ds, err := docs.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build docs store: %w", err)
+	}
+
+	specs := spec.New(spec.Prefix, ds)
+
+	es, err := events.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build events store: %w", err)
+	}
+
+	is, err := issue.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build issue store: %w", err)
+	}
+
+	issues := issue.New(issue.Prefix, github.New(newHTTPClient(), cfg.GitHub.Token), is)
+
+	modes, err := moderation.Modes(cfg.Moderation.Modes)
+	if err != nil {
+		return fmt.Errorf("failed to parse moderation modes: %w", err)
+	}
+
+	mstore, err := moderation.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build moderation store: %w", err)
+	}
+
+	journal, err := digest.NewJournal(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build digest journal: %w", err)
+	}
+
+	mod := moderation.New(mstore, nr, modes, logger.With().Str("context", "moderation").Logger())
+
+	// nudge after a message is posted in a second channel within 10 minutes,
+	// and alert the moderators once it's in 3
+	xpd, err := crosspost.New(rc, 10*time.Minute, 3)
+	if err != nil {
+		return fmt.Errorf("failed to build cross-post detector: %w", err)
+	}
+
+	// deleting other users' messages needs a Workspace Admin's token
+	del := antispam.NewDeleter(cfg.Slack.AdminAccessToken, slack.OptionHTTPClient(auditedHTTPClient(als, "admin", logger)))
+
+	js, err := joins.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build joins store: %w", err)
+	}
+
+	// only alert moderators about each new account once
+	nal, err := ratelimit.New(rc, "new_account_alerts", 1, cfg.Moderation.NewAccountWindow)
+	if err != nil {
+		return fmt.Errorf("failed to build new account alert rate limiter: %w", err)
+	}
+
+	pls, err := poll.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build poll store: %w", err)
+	}
+
+	polls := poll.New(pls)
+
 	tja := handler.NewTeamJoinActions(
 		shadowMode,
 		logger.With().Str("context", "team_join_actions").Logger(),
 	)
 
+	tja.SetFlags(fs, flags.Welcomes)
+
 	cja := handler.NewChannelJoinActions(
 		shadowMode,
 		logger.With().Str("context", "channel_join_actions").Logger(),
 	)
 
+	cja.SetFlags(fs, flags.Welcomes)
+
+	raa := handler.NewReactionAddedActions(
+		shadowMode,
+		logger.With().Str("context", "reaction_added_actions").Logger(),
+	)
+
+	ia := handler.NewInteractionActions(
+		shadowMode,
+		logger.With().Str("context", "interaction_actions").Logger(),
+	)
+
+	sca := handler.NewSlashCommandActions(
+		shadowMode,
+		logger.With().Str("context", "slash_command_actions").Logger(),
+	)
+
 	// set up all the responders and reacters
 	injectMessageResponses(ma)
-	injectMessageResponseFuncs(ma)
+	injectMessageResponseFuncs(ma, rnd)
+	injectRandomHandlers(ma, rnd)
 	injectMessageReactions(ma)
 	injectMessageResponsePrefix(ma)
+	injectGopherHandlers(ma, rnd)
+
+	gds, err := godl.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build Go releases store: %w", err)
+	}
+
+	injectGoVersionHandlers(ma, godl.New(newHTTPClient(), gds))
+
+	cs, err := contrib.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build contributor issues store: %w", err)
+	}
+
+	injectContributeHandlers(ma, contrib.New(github.New(newHTTPClient(), cfg.GitHub.Token), cs))
+
+	ks, err := kb.NewStore(rc, logger.With().Str("context", "kb").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build knowledge base store: %w", err)
+	}
+
+	injectKBHandlers(ma, ks)
+
+	fqs, err := faq.NewStore(rc, logger.With().Str("context", "faq").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build FAQ store: %w", err)
+	}
+
+	injectFAQHandlers(ma, raa, fqs, ks)
+
+	// point people asking to ask, or posting screenshots of code, to the
+	// guidance at most once a day each
+	askLimit, err := ratelimit.New(rc, "ask_guidance", 1, 24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to build ask guidance limiter: %w", err)
+	}
+
+	screenshotLimit, err := ratelimit.New(rc, "screenshot_guidance", 1, 24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to build screenshot guidance limiter: %w", err)
+	}
+
+	injectGuidanceHandlers(ma, loc, askLimit, screenshotLimit)
+
+	xs, err := xkcd.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build xkcd store: %w", err)
+	}
+
+	ma.HandleCommand("xkcd", xkcd.Usage, "show an xkcd comic, like `xkcd 303` or `xkcd latest`", xkcd.New(newHTTPClient(), xs).Handler)
 
 	// handle "define " prefixed command
 	ma.HandlePrefix(glossary.Prefix, "find a definition in the glossary of Go-related terms", gloss.DefineHandler)
 
+	// handle "tip " prefixed command
+	ma.HandlePrefix(tip.Prefix, "find out the status of a Go feature or proposal", tracker.Handler)
+
+	// handle "spec " prefixed command
+	ma.HandlePrefix(spec.Prefix, "link to the section of the Go spec or Effective Go about a topic", specs.Handler)
+
+	// handle "issue " prefixed command, and golang/go#N references
+	ma.HandlePrefix(issue.Prefix, "summarize a golang/go issue", issues.Handler)
+	ma.HandleDynamicFeature(flags.Responses, issues.MessageMatchFn, issues.LinkHandler)
+
+	// handle the poll command
+	ma.HandleCommand(poll.Command, poll.Usage, "start a poll that people vote on with reactions, or tally one with `poll results`", polls.Handler)
+
 	// set up the Go Playground uploader
 	lp := logger.With().Str("context", "playground")
-	pg := playground.New(newHTTPClient(), lp.Logger(), playgroundChannelBlacklist)
-	ma.HandleDynamic(pg.MessageMatchFn, pg.Handler)
+	pgs, err := playground.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build playground store: %w", err)
+	}
+
+	ups, err := prefs.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build preferences store: %w", err)
+	}
+
+	pgo := playgroundOptOuts{prefs: ups, legacy: pgs}
+
+	pg := playground.New(newHTTPClient(), lp.Logger(), playgroundChannelBlacklist, pgs, pgo, pgs)
+	ma.HandleDynamicFeature(flags.Playground, pg.MessageMatchFn, pg.Handler)
+
+	// snippets posted by webhooks and workflows are uploaded too
+	ma.HandleSubtypes([]string{handler.SubtypeBotMessage, handler.SubtypeMessageMetadata}, flags.Playground)
+
+	ma.Handle("playground off", "stop uploading your long code messages to the playground", nil, pg.OptOutHandler)
+	ma.Handle("playground on", "start uploading your long code messages to the playground again", nil, pg.OptInHandler)
+	ma.Handle("got it", "stop reminding you how to share code with the playground", nil, pg.GotItHandler)
+	ma.Handle("playground disable", "(admins only) stop uploading code to the playground in the mentioned channels", nil, pg.DisableHandler)
+	ma.Handle("playground enable", "(admins only) start uploading code to the playground in the mentioned channels", nil, pg.EnableHandler)
+	ia.HandleShortcut("share_to_playground", playground.ShareCallbackID, pg.ShortcutHandler)
+	sca.Handle("playground", playground.SlashCommand, pg.SlashCommandHandler)
+	ia.HandleViewSubmission("playground_upload", playground.UploadCallbackID, pg.UploadHandler)
+
+	// set up the Go Playground runner, limiting each user to 5 runs a minute
+	rl, err := ratelimit.New(rc, "playground_run", 5, time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to build playground run rate limiter: %w", err)
+	}
+
+	ma.HandlePrefix(playground.RunPrefix, "run a code block, Go Playground link, or attached file and show the output", playground.NewRunner(pg, rl).Handler)
+
+	// the longest responses, which bury the conversation they're in
+	ma.SetTriggerThreading(handler.ThreadingThread, "help", playground.RunPrefix)
+
+	injectPermalinkHandlers(ma, raa)
+	injectGoTimeHandlers(ma, changelog.New(newHTTPClient()))
+	injectEventsHandlers(ma, es)
+	injectDigestHandlers(ma, journal, nr)
+	injectFlagHandlers(ma, fs)
+	injectUsageHandlers(ma, us)
+	injectLanguageHandlers(ma, catalog, ls)
+	injectThreadingHandlers(ma, ths)
+	injectMentionsOnlyHandlers(ma, mos)
+	injectAuditHandlers(ma, als)
+	injectUndoHandlers(ma, raa, uds)
+	injectVersionHandlers(ma, vr)
+	injectFleetHandlers(ma, rc, cfg.Heroku.AppName)
+	injectPrefsHandlers(ma, ups, pgo)
+
+	phs, err := pollerhealth.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build poller health store: %w", err)
+	}
+
+	injectBGTasksHandlers(ma, phs)
+
+	gs, err := growth.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build growth store: %w", err)
+	}
 
-	injectTeamJoinHandlers(tja)
+	injectGrowthHandlers(tja, cja, ma, gs)
+
+	ns, err := nurture.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build nurture store: %w", err)
+	}
+
+	injectNurtureHandlers(tja, ma, ns)
+
+	sps, err := scheduled.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build scheduled post store: %w", err)
+	}
+
+	injectScheduledPostHandlers(ma, sps)
+
+	reloads := &reloader{}
+	reloads.add("Feature flags", fs.Refresh)
+	reloads.add("Playground channels", pg.ReloadChannels)
+
+	injectReloadHandlers(ma, reloads)
+	injectModerationHandlers(raa, mod)
+	injectReportHandlers(ia, nr)
+
+	mms, err := modmail.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build mod mail store: %w", err)
+	}
+
+	injectModmailHandlers(ma, nr, mms)
+
+	wks, err := workshops.NewStore(rc)
+	if err != nil {
+		return fmt.Errorf("failed to build workshops store: %w", err)
+	}
+
+	injectWorkshopHandlers(ma, wks, nr)
+
+	// everything stored about members, for purging a member who asks to be
+	// removed; the database only holds channel state so far
+	pur := newPurger(memberStores{
+		prefs:      ups,
+		language:   ls,
+		playground: pgs,
+		joins:      js,
+		followUps:  ns,
+		growth:     gs,
+		moderation: mstore,
+		modmail:    mms,
+		workshops:  wks,
+		faq:        fqs,
+		undo:       uds,
+		threading:  ths,
+		crosspost:  xpd,
+		users:      uCache,
+		polls:      pls,
+		scheduled:  sps,
+		kb:         ks,
+		audit:      als,
+	})
+
+	injectPurgeHandlers(ma, pur)
+
+	twp, err := topicwatch.NewPolicy(cfg.Moderation.TopicWatch)
+	if err != nil {
+		return fmt.Errorf("failed to build topic watch policy: %w", err)
+	}
+
+	var tws topicwatch.Approvals
+
+	if db != nil {
+		tws = topicwatch.NewSQLStore(db)
+	} else {
+		tws, err = topicwatch.NewStore(rc)
+		if err != nil {
+			return fmt.Errorf("failed to build topic watch store: %w", err)
+		}
+	}
+
+	injectCrosspostHandlers(shadowMode, ma, xpd, mod)
+	injectSpamHandlers(ma, del, mod, cfg.Moderation.SpamFlagThreshold, cfg.Moderation.SpamDeleteThreshold)
+	injectTeamJoinHandlers(tja, js, ups, ns)
+	injectNewAccountHandlers(tja, ma, js, nal, del, mod, cfg.Moderation.NewAccountWindow)
 	injectChannelJoinHandlers(cja)
 
+	tctx, tcancel := context.WithTimeout(ctx, 5*time.Second)
+
+	if err := saveTriggers(tctx, ma, us); err != nil {
+		logger.Warn().
+			Err(err).
+			Msg("failed to save triggers for usage reports")
+	}
+
+	tcancel()
+
 	q.RegisterTeamJoinsHandler(2*time.Second, tja.Handler)
 	q.RegisterChannelJoinsHandler(10*time.Second, cja.Handler)
 	q.RegisterPublicMessagesHandler(10*time.Second, ma.Handler)
 	q.RegisterPrivateMessagesHandler(10*time.Second, ma.Handler)
+	q.RegisterReactionAddedHandler(10*time.Second, raa.Handler)
+	q.RegisterInteractionsHandler(10*time.Second, ia.Handler)
+	q.RegisterChannelTopicsHandler(10*time.Second, topicWatchHandler(shadowMode, twp, tws, nr))
+	q.RegisterChannelChangesHandler(10*time.Second, channelCacheHandler(ccf))
+	q.RegisterLinkSharedHandler(10*time.Second, unfurlHandler(shadowMode, unfurl.New(newHTTPClient())))
+	q.RegisterSlashCommandsHandler(10*time.Second, sca.Handler)
+
+	ss := status.New(cfg.Heroku.AppName, cfg.Heroku.Commit, logger.With().Str("context", "status_server").Logger())
+	ss.Register("heartbeat", status.Heartbeat(hb))
+	ss.Register("workqueue", queueCheck(rc, cfg.Heroku.AppName))
+	ss.Register("throttle", throttleCheck(q))
+
+	publishQueueDepth(rc, cfg.Heroku.AppName)
+
+	if cfg.StatusPort > 0 {
+		go func() {
+			if err := ss.Run(ctx, cfg.StatusPort); err != nil {
+				logger.Error().
+					Err(err).
+					Msg("status server failed")
+			}
+		}()
+	} else {
+		logger.Info().Msg("no status port configured: not serving status")
+	}
+
+	// deliver the messages handlers queued in the outbox
+	obs, err := outbox.NewSender(rc, sc, logger.With().Str("context", "outbox_sender").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build outbox sender: %w", err)
+	}
+
+	go obs.Run(ctx)
+
+	// reload on SIGHUP
+	go func() {
+		for range reloadCh {
+			rctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+
+			logger.Info().
+				Str("summary", reloads.reload(rctx, logger)).
+				Msg("reloaded configuration on SIGHUP")
+
+			cancel()
+		}
+	}()
 
 	// signal handling / graceful shutdown goroutine
 	go func() {
@@ -188,12 +729,71 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 	return nil
 }
 
+// streamConfigs returns the default, per-priority, and per-stream workqueue
+// configs from the "default", priority name, and stream name keys in the
+// config.
+func streamConfigs(w config.W) (workqueue.StreamConfig, map[workqueue.Priority]workqueue.StreamConfig, map[string]workqueue.StreamConfig) {
+	var def workqueue.StreamConfig
+
+	priorities := make(map[workqueue.Priority]workqueue.StreamConfig)
+	streams := make(map[string]workqueue.StreamConfig)
+
+	set := func(m map[string]int, fn func(sc *workqueue.StreamConfig, n int)) {
+		for k, n := range m {
+			if k == "default" {
+				fn(&def, n)
+				continue
+			}
+
+			if pri := workqueue.Priority(k); isPriority(pri) {
+				sc := priorities[pri]
+				fn(&sc, n)
+				priorities[pri] = sc
+
+				continue
+			}
+
+			sc := streams[k]
+			fn(&sc, n)
+			streams[k] = sc
+		}
+	}
+
+	set(w.Concurrency, func(sc *workqueue.StreamConfig, n int) { sc.Concurrency = n })
+	set(w.BufferSize, func(sc *workqueue.StreamConfig, n int) { sc.BufferSize = n })
+
+	return def, priorities, streams
+}
+
+func isPriority(p workqueue.Priority) bool {
+	for _, v := range workqueue.Priorities {
+		if v == p {
+			return true
+		}
+	}
+
+	return false
+}
+
 func newHTTPClient() *http.Client {
 	return &http.Client{
 		Transport: newHTTPTransport(),
 	}
 }
 
+// auditedHTTPClient returns an *http.Client for a Slack client, which records
+// the actions it takes as the actor in the audit log.
+func auditedHTTPClient(als *audit.Store, actor string, logger zerolog.Logger) *http.Client {
+	t := &audit.Transport{
+		Base:   newHTTPTransport(),
+		Store:  als,
+		Actor:  actor,
+		Logger: logger.With().Str("context", "audit").Logger(),
+	}
+
+	return t.Client()
+}
+
 // newHTTPTransport returns an *http.Transport with some reasonable defaults.
 func newHTTPTransport() *http.Transport {
 	return &http.Transport{
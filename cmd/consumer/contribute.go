@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/contrib"
+	"github.com/gobridge/gopherbot/internal/github"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// injectContributeHandlers registers the commands that walk people through
+// contributing to Go: the steps in the contribution guide, setting up Gerrit,
+// and finding an issue to start with.
+func injectContributeHandlers(ma *handler.MessageActions, cc *contrib.Client) {
+	ma.HandleMessage("contribute", "how to contribute to Go itself", []string{"contributing", "contribute to go", "how to contribute"}, "contribute")
+
+	ma.HandleMessage("gerrit setup", "how to set up Gerrit and git-codereview to send changes to Go", []string{"setup gerrit", "git codereview", "git-codereview"}, "gerrit_setup")
+
+	ma.Handle("first issue", "golang/go issues looking for help, to start contributing with", []string{"first issues", "good first issue", "good first issues"},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			is, err := cc.FirstIssues(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get first issues: %w", err)
+			}
+
+			return r.RespondTo(ctx, formatFirstIssues(is))
+		},
+	)
+}
+
+// formatFirstIssues lists the issues, with the labels that got them picked.
+func formatFirstIssues(is []github.Issue) string {
+	const all = `<https://github.com/golang/go/issues?q=is%3Aissue+is%3Aopen+label%3A%22help+wanted%22|all of them>`
+
+	var b strings.Builder
+
+	n := 0
+
+	for _, i := range is {
+		if i.IsPR {
+			continue
+		}
+
+		if n == 0 {
+			b.WriteString("Here are some golang/go issues looking for help, most recently updated first:\n")
+		}
+
+		fmt.Fprintf(&b, "- <%s|#%d> %s", i.URL, i.Number, i.Title)
+
+		if l := firstIssueLabels(i.Labels); len(l) > 0 {
+			fmt.Fprintf(&b, " (%s)", l)
+		}
+
+		b.WriteString("\n")
+		n++
+	}
+
+	if n == 0 {
+		return "I couldn't find any golang/go issues looking for help right now. You can check " + all + " on GitHub, or ask in the issue tracker about one you'd like to fix."
+	}
+
+	b.WriteString("Comment on the issue before starting, in case someone is already on it. You can also see " + all + ", and `contribute` walks through sending your change.")
+
+	return b.String()
+}
+
+// firstIssueLabels returns the labels of an issue that say it's looking for
+// help, joined by commas.
+func firstIssueLabels(labels []string) string {
+	var ls []string
+
+	for _, l := range labels {
+		switch strings.ToLower(l) {
+		case "help wanted", "good first issue":
+			ls = append(ls, l)
+		}
+	}
+
+	return strings.Join(ls, ", ")
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gobridge/gopherbot/internal/github"
+)
+
+func Test_formatFirstIssues(t *testing.T) {
+	is := []github.Issue{
+		{Number: 1, Title: "x/tools: add a flag", URL: "https://github.com/golang/go/issues/1", Labels: []string{"NeedsFix", "help wanted"}},
+		{Number: 2, Title: "cmd/go: fix it", URL: "https://github.com/golang/go/pull/2", IsPR: true},
+		{Number: 3, Title: "doc: typo", URL: "https://github.com/golang/go/issues/3", Labels: []string{"good first issue", "Documentation"}},
+	}
+
+	got := formatFirstIssues(is)
+
+	for _, want := range []string{
+		"- <https://github.com/golang/go/issues/1|#1> x/tools: add a flag (help wanted)\n",
+		"- <https://github.com/golang/go/issues/3|#3> doc: typo (good first issue)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatFirstIssues() = %q, want it to contain %q", got, want)
+		}
+	}
+
+	if strings.Contains(got, "#2") {
+		t.Errorf("formatFirstIssues() = %q, want pull requests left out", got)
+	}
+
+	if got := formatFirstIssues(nil); !strings.HasPrefix(got, "I couldn't find any") {
+		t.Errorf("formatFirstIssues(nil) = %q, want it to say none were found", got)
+	}
+}
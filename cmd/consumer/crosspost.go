@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/crosspost"
+	"github.com/gobridge/gopherbot/internal/moderation"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const (
+	crosspostGuidance = `Please keep your questions to a single channel. If you don't get a reply in a while, then consider cross-posting.`
+
+	crosspostDetector = "crosspost"
+)
+
+// injectCrosspostHandlers sets up the detection of the same message being
+// posted to multiple public channels. The user is nudged, ephemerally, when
+// it's posted to a second channel, and moderators are alerted once it reaches
+// the detector's alert threshold. Nudges are only sent when the detector is in
+// the moderation.Enforce mode.
+func injectCrosspostHandlers(shadowMode bool, ma *handler.MessageActions, d *crosspost.Detector, mod *moderation.Moderator) {
+	match := func(_ bool, m handler.Messenger) bool {
+		if m.ChannelType() != handler.ChannelPublic || len(m.ThreadTS()) > 0 || m.BotMentioned() {
+			return false
+		}
+
+		_, ok := crosspost.Fingerprint(m.Text())
+
+		return ok
+	}
+
+	ma.HandleDynamic(match, func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+		fp, _ := crosspost.Fingerprint(m.Text())
+
+		res, err := d.Check(ctx, m.UserID(), fp, m.ChannelID(), ctx.Meta().Time)
+		if err != nil {
+			return fmt.Errorf("failed to check for cross-post: %w", err)
+		}
+
+		if res.Nudge {
+			if mod.Mode(crosspostDetector) != moderation.Enforce || shadowMode {
+				ctx.Logger().Info().
+					Str("user_id", m.UserID()).
+					Int("channel_count", len(res.Channels)).
+					Msg("would nudge user about cross-posting")
+			} else if err := r.RespondEphemeral(ctx, crosspostGuidance); err != nil {
+				return fmt.Errorf("failed to nudge user: %w", err)
+			}
+		}
+
+		if !res.Alert {
+			return nil
+		}
+
+		refs := make([]string, len(res.Channels))
+		for i, id := range res.Channels {
+			refs[i] = mparser.Mention{Type: mparser.TypeChannelRef, ID: id}.String()
+		}
+
+		return mod.Flag(ctx, moderation.Decision{
+			Detector:  crosspostDetector,
+			ChannelID: m.ChannelID(),
+			UserID:    m.UserID(),
+			MessageTS: m.MessageTS(),
+			Score:     float64(len(res.Channels)),
+			Threshold: float64(d.AlertAt()),
+			Reason:    fmt.Sprintf("the same message was posted in %d channels: %s", len(res.Channels), strings.Join(refs, ", ")),
+			Time:      time.Now(),
+		}, nil)
+	})
+}
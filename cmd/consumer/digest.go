@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/digest"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+// digestPeriod is how far back the digest command looks for announcements.
+const digestPeriod = 7 * 24 * time.Hour
+
+// injectDigestHandlers registers the digest command, which lets Workspace
+// Admins post the weekly summary of announcements to the channel mentioned in
+// the message, or the current channel if none was.
+func injectDigestHandlers(ma *handler.MessageActions, j *digest.Journal, nr *notify.Router) {
+	ma.Handle("digest", "post the weekly digest of announcements to a channel (admins only)", nil,
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can post the digest.")
+			}
+
+			channelID := m.ChannelID()
+
+			for _, mention := range m.AllMentions() {
+				if mention.Type == mparser.TypeChannelRef {
+					channelID = mention.ID
+					break
+				}
+			}
+
+			since := time.Now().Add(-digestPeriod)
+
+			entries, err := j.Since(ctx, since)
+			if err != nil {
+				return fmt.Errorf("failed to get journal entries: %w", err)
+			}
+
+			_, err = nr.NotifyChannel(ctx, channelID, notify.Notification{
+				Source:   notify.Digest,
+				Severity: notify.Important,
+				Summary:  fmt.Sprintf("weekly digest with %d entries", len(entries)),
+				Options: []slack.MsgOption{
+					slack.MsgOptionDisableLinkUnfurl(),
+					slack.MsgOptionText(digest.Format(entries, since), false),
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to post digest: %w", err)
+			}
+
+			if channelID == m.ChannelID() {
+				return nil
+			}
+
+			return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I posted the digest in <#%s>.", channelID))
+		},
+	)
+}
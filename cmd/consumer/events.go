@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/poller/events"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// maxUpcomingEvents is how many events the upcoming events command lists.
+const maxUpcomingEvents = 5
+
+func injectEventsHandlers(ma *handler.MessageActions, es *events.DefaultStore) {
+	ma.Handle("upcoming events", "list the upcoming Go conferences and GoBridge events", []string{"events"},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			upcoming, err := es.Upcoming(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get upcoming events: %w", err)
+			}
+
+			if len(upcoming) == 0 {
+				return r.Respond(ctx, "I don't know of any upcoming events right now. Keep an eye on #remotemeetup for announcements.")
+			}
+
+			if len(upcoming) > maxUpcomingEvents {
+				upcoming = upcoming[:maxUpcomingEvents]
+			}
+
+			var b strings.Builder
+
+			b.WriteString("Here are the upcoming events I know of:\n")
+
+			for _, e := range upcoming {
+				title := e.Title
+				if len(e.URL) > 0 {
+					title = fmt.Sprintf("<%s|%s>", e.URL, e.Title)
+				}
+
+				fmt.Fprintf(&b, "• *%s*", title)
+
+				if len(e.Location) > 0 {
+					fmt.Fprintf(&b, " (%s)", e.Location)
+				}
+
+				fmt.Fprintf(&b, ", %s\n", slackDate(e.Start))
+			}
+
+			return r.Respond(ctx, b.String())
+		},
+	)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/faq"
+	"github.com/gobridge/gopherbot/internal/flags"
+	"github.com/gobridge/gopherbot/internal/kb"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+const faqUsage = "Usage: `faq enable|disable [#channel]` sets whether I link the canonical answer when someone asks a very common question in the channel, " +
+	"and `faq stats` shows how often each was answered, and how the answers were received."
+
+// injectFAQHandlers registers the handler answering frequently asked questions
+// in the channels it's enabled in, the reactions people give feedback on the
+// answers with, and the commands for the moderators to enable it and see how
+// it's doing. Questions the knowledge base explains an error in are left to
+// it.
+func injectFAQHandlers(ma *handler.MessageActions, raa *handler.ReactionAddedActions, fs *faq.Store, ks *kb.Store) {
+	ix := faq.NewIndex(faq.Builtin)
+
+	ma.HandleDynamicFeature(flags.Responses,
+		func(shadowMode bool, m handler.Messenger) bool {
+			if shadowMode || len(m.ThreadTS()) > 0 || m.BotMentioned() || !fs.Enabled(m.ChannelID()) {
+				return false
+			}
+
+			if ks.Enabled(m.ChannelID()) && len(kb.Match(ks.Entries(), m.Text())) > 0 {
+				return false
+			}
+
+			_, _, ok := ix.Match(m.Text())
+
+			return ok
+		},
+		func(ctx workqueue.Context, m handler.Messenger, _ handler.Responder) error {
+			e, score, ok := ix.Match(m.Text())
+			if !ok {
+				return nil
+			}
+
+			text := fmt.Sprintf("That's a common question, answered here: *<%s|%s>*\n"+
+				"React with :%s: if it helped, or :%s: if it's not what you asked.",
+				e.URL, e.Question, faq.HelpfulReaction, faq.WrongReaction)
+
+			// posted directly, rather than with the Responder, to know the
+			// answer's timestamp for the feedback on it
+			_, ts, err := ctx.Slack().PostMessageContext(ctx, m.ChannelID(),
+				slack.MsgOptionText(text, false),
+				slack.MsgOptionTS(m.MessageTS()),
+				slack.MsgOptionDisableLinkUnfurl(),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to answer FAQ %s: %w", e.ID, err)
+			}
+
+			ctx.Logger().Info().
+				Str("faq_id", e.ID).
+				Float64("score", score).
+				Str("answer_ts", ts).
+				Msg("answered frequently asked question")
+
+			return fs.RecordAnswer(ctx, m.ChannelID(), ts, e.ID)
+		},
+	)
+
+	feedback := func(v faq.Verdict) handler.ReactionAddedActionFn {
+		return func(ctx workqueue.Context, ra handler.Reactor, _ handler.Responder) error {
+			if ra.ItemUserID() != ctx.Self().ID {
+				return nil
+			}
+
+			id, found, err := fs.Feedback(ctx, ra.ChannelID(), ra.MessageTS(), ra.UserID(), v)
+			if err != nil {
+				return fmt.Errorf("failed to record FAQ feedback: %w", err)
+			}
+
+			if found {
+				ctx.Logger().Info().
+					Str("faq_id", id).
+					Str("user_id", ra.UserID()).
+					Str("verdict", string(v)).
+					Msg("recorded FAQ feedback")
+			}
+
+			return nil
+		}
+	}
+
+	raa.Handle("faq_feedback_helpful", faq.HelpfulReaction, feedback(faq.Helpful))
+	raa.Handle("faq_feedback_wrong", faq.WrongReaction, feedback(faq.Wrong))
+
+	ma.HandleCommand("faq", faqUsage, "(admins only) `faq enable #channel` links the answers to very common questions asked in a channel",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can change where I answer common questions.")
+			}
+
+			switch strings.ToLower(c.Arg(0)) {
+			case "enable", "disable":
+				enabled := strings.EqualFold(c.Arg(0), "enable")
+
+				channelID := m.ChannelID()
+
+				for _, mention := range m.AllMentions() {
+					if mention.Type == mparser.TypeChannelRef {
+						channelID = mention.ID
+						break
+					}
+				}
+
+				if err := fs.SetEnabled(ctx, channelID, enabled); err != nil {
+					return err
+				}
+
+				if !enabled {
+					return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I'll stop answering common questions in <#%s>.", channelID))
+				}
+
+				return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I'll link the answers to very common questions asked in <#%s>.", channelID))
+
+			case "stats":
+				stats, err := fs.Stats(ctx)
+				if err != nil {
+					return err
+				}
+
+				if len(stats) == 0 {
+					return r.RespondEphemeral(ctx, "I haven't answered any common questions yet.")
+				}
+
+				return r.RespondEphemeral(ctx, formatFAQStats(stats))
+
+			default:
+				return &handler.UsageError{}
+			}
+		},
+	)
+}
+
+func formatFAQStats(stats []faq.Stat) string {
+	var b strings.Builder
+
+	for _, s := range stats {
+		fmt.Fprintf(&b, "- `%s`: answered %d times, %d helpful, %d wrong\n", s.ID, s.Answered, s.Helpful, s.Wrong)
+	}
+
+	return b.String()
+}
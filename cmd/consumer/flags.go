@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/flags"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const flagUsage = "Usage: `flag <feature> shadow|live|default`, where feature is one of: "
+
+// injectFlagHandlers registers the commands Workspace Admins use to see and
+// toggle which features are in shadow mode.
+func injectFlagHandlers(ma *handler.MessageActions, fs *flags.Store) {
+	ma.Handle("flags", "(admins only) list which features are live, or in shadow mode", nil,
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can see the feature flags.")
+			}
+
+			states, err := fs.States(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get feature flag states: %w", err)
+			}
+
+			var b strings.Builder
+
+			for _, s := range states {
+				state := "live"
+				if s.Shadow {
+					state = "shadow"
+				}
+
+				fmt.Fprintf(&b, "- `%s`: %s", s.Feature, state)
+
+				if s.Overridden {
+					b.WriteString(" (overridden)")
+				}
+
+				b.WriteString("\n")
+			}
+
+			return r.RespondEphemeral(ctx, b.String())
+		},
+	)
+
+	usage := flagUsage + strings.Join(flags.Features, ", ")
+
+	ma.HandleCommand("flag", usage, "(admins only) `flag <feature> shadow|live|default` switches a feature between acting and only logging what it would do",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			if !m.BotMentioned() && m.ChannelType() != handler.ChannelDM {
+				return nil
+			}
+
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can change the feature flags.")
+			}
+
+			if len(c.Args) != 2 {
+				return &handler.UsageError{}
+			}
+
+			feature, state := c.Arg(0), strings.ToLower(c.Arg(1))
+
+			if !flags.Valid(feature) {
+				return handler.Usagef("I don't know the feature `%s`", feature)
+			}
+
+			switch state {
+			case "shadow":
+				err = fs.Set(ctx, feature, true)
+			case "live":
+				err = fs.Set(ctx, feature, false)
+			case "default":
+				err = fs.Clear(ctx, feature)
+			default:
+				return handler.Usagef("I don't know the state `%s`", c.Arg(1))
+			}
+
+			if err != nil {
+				return fmt.Errorf("failed to update feature flag %s: %w", feature, err)
+			}
+
+			ctx.Logger().Info().
+				Str("user_id", m.UserID()).
+				Str("feature", feature).
+				Str("state", state).
+				Msg("feature flag updated")
+
+			return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, `%s` is now %s. Other consumers pick this up within a few seconds.", feature, state))
+		},
+	)
+}
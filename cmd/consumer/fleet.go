@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/heartbeat"
+	"github.com/gobridge/gopherbot/internal/version"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/redis/go-redis/v9"
+)
+
+const fleetUsage = "Usage: `fleet status` shows which gateway, consumer, and bgtasks instances are heartbeating, the commit each is running, and when each last beat."
+
+// fleetComponents are the components that should always have an instance
+// running.
+var fleetComponents = []string{"gateway", "consumer", "bgtasks"}
+
+// injectFleetHandlers registers the command reporting which instances of each
+// component are alive, from their heartbeats.
+func injectFleetHandlers(ma *handler.MessageActions, rc *redis.Client, appName string) {
+	ma.HandleCommand("fleet", fleetUsage, "(admins only) `fleet status` shows which of my instances are alive",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			if len(c.Args) != 1 || !strings.EqualFold(c.Arg(0), "status") {
+				return &handler.UsageError{}
+			}
+
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can see which instances are alive.")
+			}
+
+			peers, err := heartbeat.Peers(ctx, rc, appName)
+			if err != nil {
+				return fmt.Errorf("failed to get heartbeats: %w", err)
+			}
+
+			return r.RespondEphemeralTextAttachment(ctx, "Here's who's heartbeating:", formatFleet(peers, time.Now()))
+		},
+	)
+}
+
+func formatFleet(peers []heartbeat.Peer, now time.Time) string {
+	var b strings.Builder
+
+	alive := make(map[string]bool)
+
+	for _, p := range peers {
+		emoji := ":white_check_mark:"
+		if p.Alive(now) {
+			alive[p.Component] = true
+		} else {
+			emoji = ":x:"
+		}
+
+		component := p.Component
+		if len(component) == 0 {
+			component = "unknown"
+		}
+
+		fmt.Fprintf(&b, "%s *%s* (%s): %s, last beat %s ago\n",
+			emoji, component, p.UID, version.Info{Commit: p.Commit}.ShortCommit(), formatBeatAge(now.Sub(p.LastBeat)),
+		)
+	}
+
+	for _, c := range fleetComponents {
+		if !alive[c] {
+			fmt.Fprintf(&b, ":warning: no %s instance is alive.\n", c)
+		}
+	}
+
+	return b.String()
+}
+
+// formatBeatAge returns the duration in seconds, if it's under a minute, or
+// like formatUptime otherwise.
+func formatBeatAge(d time.Duration) string {
+	if d < time.Minute {
+		if d < 0 {
+			d = 0
+		}
+
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+
+	return formatUptime(d)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/heartbeat"
+)
+
+func Test_formatFleet(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	peers := []heartbeat.Peer{
+		{
+			UID:       "c1",
+			Component: "consumer",
+			Commit:    "deadbeefcafe1234",
+			LastBeat:  now.Add(-time.Second),
+			Fail:      8 * time.Second,
+		},
+		{
+			UID:       "c2",
+			Component: "consumer",
+			Commit:    "cafebabe",
+			LastBeat:  now.Add(-70 * time.Second),
+			Fail:      8 * time.Second,
+		},
+		{
+			UID:       "g1",
+			Component: "gateway",
+			Commit:    "deadbeefcafe1234",
+			LastBeat:  now.Add(-2 * time.Second),
+		},
+		{
+			UID:      "x1",
+			LastBeat: now.Add(-30 * time.Second),
+		},
+	}
+
+	want := ":white_check_mark: *consumer* (c1): deadbee, last beat 1s ago\n" +
+		":x: *consumer* (c2): cafebab, last beat 1m ago\n" +
+		":white_check_mark: *gateway* (g1): deadbee, last beat 2s ago\n" +
+		":x: *unknown* (x1): unknown, last beat 30s ago\n" +
+		":warning: no bgtasks instance is alive.\n"
+
+	if got := formatFleet(peers, now); got != want {
+		t.Fatalf("formatFleet() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/rng"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+type gopherImage struct {
+	name string
+	url  string
+}
+
+// gopherImages are the gophers the gopher command picks from, by Renée French
+// and published on go.dev under CC BY 4.0.
+var gopherImages = []gopherImage{
+	{name: "The Go gopher", url: "https://go.dev/doc/gopher/frontpage.png"},
+	{name: "Gopher reading the docs", url: "https://go.dev/doc/gopher/doc.png"},
+	{name: "Gopher packing up", url: "https://go.dev/doc/gopher/pkg.png"},
+	{name: "Gopher on the run", url: "https://go.dev/doc/gopher/run.png"},
+	{name: "Gopher giving a talk", url: "https://go.dev/doc/gopher/talks.png"},
+	{name: "Gopher at work on a project", url: "https://go.dev/doc/gopher/project.png"},
+	{name: "Gopher lending a hand", url: "https://go.dev/doc/gopher/help.png"},
+	{name: "Gopher in a biplane", url: "https://go.dev/doc/gopher/biplane.jpg"},
+	{name: "Gophers celebrating five years of Go", url: "https://go.dev/doc/gopher/fiveyears.jpg"},
+	{name: "Gopher in black and white", url: "https://go.dev/doc/gopher/gopherbw.png"},
+}
+
+func injectGopherHandlers(ma *handler.MessageActions, rnd rng.RNG) {
+	ma.Handle("gopher", "show a random gopher", []string{"gopher me"},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			g := gopherImages[rnd.Intn(len(gopherImages))]
+
+			return r.Respond(ctx, g.name, slack.Attachment{
+				Fallback: g.name,
+				ImageURL: g.url,
+			})
+		},
+	)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/changelog"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// goTimeLiveWindow is how long after an episode's scheduled start we consider
+// it to be live, rather than in the past.
+const goTimeLiveWindow = 90 * time.Minute
+
+// slackDate formats the time using Slack's date formatting, so each reader
+// sees it in their own time zone. The fallback is shown by clients that don't
+// support it.
+func slackDate(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_long_pretty} at {time}|%s>", t.Unix(), t.UTC().Format("Monday, January 2 at 15:04 UTC"))
+}
+
+func injectGoTimeHandlers(ma *handler.MessageActions, cl *changelog.Client) {
+	ma.Handle("next gotime", "find out when the next GoTimeFM episode is recorded live", []string{"gotime", "when is gotime"},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			next, err := cl.NextEpisode(ctx, changelog.GoTime)
+			if err != nil {
+				return fmt.Errorf("failed to get next GoTime episode: %w", err)
+			}
+
+			now := time.Now()
+
+			episode := "The next GoTimeFM episode"
+			if len(next.Title) > 0 {
+				episode = fmt.Sprintf("The next GoTimeFM episode, _%s_,", next.Title)
+			}
+
+			switch {
+			case next.At.IsZero() || next.At.Before(now.Add(-goTimeLiveWindow)):
+				return r.Respond(ctx, "There isn't a GoTimeFM episode scheduled right now. Check <https://changelog.com/gotime> for the latest episodes.")
+
+			case next.At.Before(now):
+				return r.Respond(ctx, "GoTimeFM should be live right now! Tune in at <https://changelog.com/live>.")
+
+			default:
+				return r.Respond(ctx, fmt.Sprintf("%s is recorded live %s. Tune in at <https://changelog.com/live>.", episode, slackDate(next.At)))
+			}
+		},
+	)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/godl"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// injectGoVersionHandlers registers the command for the current Go releases,
+// which people ask about often enough.
+func injectGoVersionHandlers(ma *handler.MessageActions, dl *godl.Client) {
+	ma.Handle("latest go version", "show the latest Go release, and the previous one still supported", []string{"go version", "latest go"},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			rs, err := dl.Releases(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get Go releases: %w", err)
+			}
+
+			current, previous := godl.Supported(rs)
+			if len(current.Version) == 0 {
+				return r.RespondTo(ctx, "Sorry, I couldn't find the latest Go release. You can find it at <https://go.dev/dl/>.")
+			}
+
+			var b strings.Builder
+
+			fmt.Fprintf(&b, "The latest Go release is %s", describeRelease(current))
+
+			if len(previous.Version) > 0 {
+				fmt.Fprintf(&b, ", and the previous supported release is %s", describeRelease(previous))
+			}
+
+			b.WriteString(". Download them from <https://go.dev/dl/>.")
+
+			return r.RespondTo(ctx, b.String())
+		},
+	)
+}
+
+func describeRelease(r godl.Release) string {
+	if r.Date.IsZero() {
+		return fmt.Sprintf("*%s*", r.Version)
+	}
+
+	return fmt.Sprintf("*%s* (released %s)", r.Version, r.Date.Format("2006-01-02"))
+}
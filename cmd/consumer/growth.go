@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/growth"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// growthStatsWindow is how far back the stats growth command looks.
+const growthStatsWindow = 7 * 24 * time.Hour
+
+// injectGrowthHandlers records members joining the workspace and channels, and
+// posting in public channels, and registers the command Workspace Admins use to
+// see how the workspace grew recently.
+func injectGrowthHandlers(tja *handler.TeamJoinActions, cja *handler.ChannelJoinActions, ma *handler.MessageActions, gs *growth.Store) {
+	tja.Handle("record growth",
+		func(ctx workqueue.Context, tj handler.TeamJoiner, r handler.Responder) error {
+			return gs.RecordJoin(ctx, tj.User().ID, ctx.Meta().Time)
+		},
+	)
+
+	cja.HandleAll("record growth",
+		func(ctx workqueue.Context, cj handler.ChannelJoiner, r handler.Responder) error {
+			return gs.RecordChannelJoin(ctx, cj.ChannelID(), ctx.Meta().Time)
+		},
+	)
+
+	ma.HandleDynamic(
+		func(_ bool, m handler.Messenger) bool {
+			return m.ChannelType() == handler.ChannelPublic
+		},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			return gs.RecordActive(ctx, m.UserID(), ctx.Meta().Time)
+		},
+	)
+
+	ma.Handle("stats growth", "(admins only) show how many members joined and posted in the last 7 days", nil,
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can see the growth stats.")
+			}
+
+			now := time.Now()
+
+			rep, err := gs.Report(ctx, now.Add(-growthStatsWindow), now)
+			if err != nil {
+				return fmt.Errorf("failed to get growth report: %w", err)
+			}
+
+			return r.RespondEphemeral(ctx, growth.FormatReport("Growth over the last 7 days", rep))
+		},
+	)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/flags"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// askToAskRegexps match the whole of a message that only asks whether it's
+// okay to ask, or whether anyone knows about something, once the greeting and
+// punctuation around it are trimmed. The topic is limited to a few words, as a
+// longer one is usually the question itself.
+var askToAskRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`^(can|could|may) (i|we) ask (you )?(a |an |some |one )?((quick|small|simple|stupid|dumb|noob|newbie|beginner|general|go|golang) )*(question|questions|something|for help)( about( \S+){1,4})?( here)?( please)?$`),
+	regexp.MustCompile(`^(i have|i've got|got) (a |an |some |one )?((quick|small|simple|stupid|dumb|noob|newbie|beginner) )*(question|questions)( about( \S+){1,4})?$`),
+	regexp.MustCompile(`^(does |do )?(any|some)(one|body) (here )?(know|knows|know about|familiar with|have experience with|has experience with|experienced with|use|used|good at|work with|worked with)( \S+){1,4}$`),
+	regexp.MustCompile(`^(is )?(there )?(any|some)(one|body) (here|around|online|awake|available)( who can help)?$`),
+	regexp.MustCompile(`^can (any|some)(one|body) help( me)?( with( \S+){1,4})?( please)?$`),
+	regexp.MustCompile(`^(i )?need (some )?help( with( \S+){1,4})?( please)?$`),
+}
+
+// greetingRegexp matches a greeting before the message, like "hi all,".
+var greetingRegexp = regexp.MustCompile(`^((hi|hello|hey|hiya|yo|greetings|good (morning|afternoon|evening))( (all|everyone|folks|guys|gophers|there|team|people))?|sorry|excuse me|quick question)\b[\s,!.:-]*`)
+
+// isAskToAsk returns whether the message only asks whether it can ask, rather
+// than asking.
+func isAskToAsk(text string) bool {
+	t := strings.ToLower(strings.TrimSpace(text))
+	t = greetingRegexp.ReplaceAllString(t, "")
+	t = strings.TrimRight(t, " ?!.:")
+	t = strings.Join(strings.Fields(t), " ")
+
+	if len(t) == 0 || len(t) > 100 {
+		return false
+	}
+
+	for _, re := range askToAskRegexps {
+		if re.MatchString(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// screenshotNameRegexp matches the default names of screenshots, and of
+// images pasted from the clipboard.
+var screenshotNameRegexp = regexp.MustCompile(`(?i)^(screen ?shot|screenshot|capture|cleanshot|snip|image|pasted|clipboard|shot)[\s_-]?`)
+
+// codeWordRegexp matches the words people post a screenshot of code or its
+// output with.
+var codeWordRegexp = regexp.MustCompile(`(?i)\b(error|errors|code|panic|panics|compile|compiler|bug|wrong|why|work|working|fix|help|issue|output|terminal|stack ?trace|build)\b`)
+
+// maxScreenshotWords is the most words a message with a screenshot can have
+// to be treated as only a screenshot.
+const maxScreenshotWords = 30
+
+// isCodeScreenshot returns whether the message is only images, at least one of
+// them named like a screenshot, or said to be of code or its output. Without
+// looking at the images, it can't be sure they're of code, so only messages
+// with little text besides are matched.
+func isCodeScreenshot(text string, files []slackevents.File) bool {
+	if len(files) == 0 || len(strings.Fields(text)) > maxScreenshotWords {
+		return false
+	}
+
+	named := false
+
+	for _, f := range files {
+		if !strings.HasPrefix(f.Mimetype, "image/") {
+			return false
+		}
+
+		named = named || screenshotNameRegexp.MatchString(f.Name)
+	}
+
+	return named || codeWordRegexp.MatchString(text)
+}
+
+// injectGuidanceHandlers registers the handlers that notice someone asking to
+// ask, or posting a screenshot of code, and reply with the same guidance as the
+// ask and screenshots commands, privately. Each is sent to a user at most once
+// per cooldown, enforced by the limiters.
+func injectGuidanceHandlers(ma *handler.MessageActions, loc handler.Localizer, askLimit, screenshotLimit handler.Limiter) {
+	guide := func(key string, limit handler.Limiter) handler.MessageActionFn {
+		return func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			allowed, _, err := limit.Allow(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !allowed {
+				return nil
+			}
+
+			msg, err := loc.Localize(ctx, m.UserID(), key)
+			if err != nil {
+				if len(msg) == 0 {
+					return err
+				}
+
+				ctx.Logger().Warn().
+					Err(err).
+					Str("message_key", key).
+					Msg("failed to localize message: using English")
+			}
+
+			return r.RespondEphemeral(ctx, msg)
+		}
+	}
+
+	ma.HandleDynamicFeature(flags.Responses,
+		func(shadowMode bool, m handler.Messenger) bool {
+			return !shadowMode && m.ChannelType() != handler.ChannelDM && !m.BotMentioned() && isAskToAsk(m.Text())
+		},
+		guide("ask", askLimit),
+	)
+
+	ma.HandleDynamicFeature(flags.Responses,
+		func(shadowMode bool, m handler.Messenger) bool {
+			return !shadowMode && m.ChannelType() != handler.ChannelDM && isCodeScreenshot(m.Text(), m.Files())
+		},
+		guide("screenshots", screenshotLimit),
+	)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+func Test_isAskToAsk(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{text: "can I ask a question?", want: true},
+		{text: "Hi all, can I ask a quick question about generics?", want: true},
+		{text: "hello everyone! does anyone know gRPC?", want: true},
+		{text: "anyone here familiar with kubernetes operators", want: true},
+		{text: "Is anyone around?", want: true},
+		{text: "can someone help me please", want: true},
+		{text: "I have a noob question", want: true},
+		{text: "hey, need help with cgo", want: true},
+		{text: "can I ask a question: why does my map panic when I assign to it?"},
+		{text: "does anyone know why my goroutine leaks when the context is cancelled before the worker reads from the channel?"},
+		{text: "can someone help me understand why this deadlocks: ```ch := make(chan int); ch <- 1```"},
+		{text: "I know gRPC pretty well"},
+		{text: "hi"},
+		{text: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.text, func(t *testing.T) {
+			if got := isAskToAsk(tt.text); got != tt.want {
+				t.Fatalf("isAskToAsk(%q) = %t, want %t", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isCodeScreenshot(t *testing.T) {
+	screenshot := slackevents.File{Name: "Screen Shot 2026-10-16 at 12.00.00.png", Mimetype: "image/png"}
+	photo := slackevents.File{Name: "IMG_1234.jpg", Mimetype: "image/jpeg"}
+	snippet := slackevents.File{Name: "main.go", Mimetype: "text/plain"}
+
+	tests := []struct {
+		name  string
+		text  string
+		files []slackevents.File
+		want  bool
+	}{
+		{
+			name:  "screenshot_only",
+			files: []slackevents.File{screenshot},
+			want:  true,
+		},
+		{
+			name:  "pasted",
+			text:  "what am I doing wrong",
+			files: []slackevents.File{{Name: "image.png", Mimetype: "image/png"}},
+			want:  true,
+		},
+		{
+			name:  "photo_of_error",
+			text:  "getting this error when I build",
+			files: []slackevents.File{photo},
+			want:  true,
+		},
+		{
+			name:  "photo",
+			text:  "my gopher plushie arrived!",
+			files: []slackevents.File{photo},
+		},
+		{
+			name:  "with_snippet",
+			files: []slackevents.File{screenshot, snippet},
+		},
+		{
+			name: "long_explanation",
+			text: "here's the architecture diagram for the service I described above, the error handling is in the middle box, " +
+				"and the retries happen in the queue consumer which then writes to postgres and emits events",
+			files: []slackevents.File{screenshot},
+		},
+		{
+			name: "no_files",
+			text: "why does this code panic?",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCodeScreenshot(tt.text, tt.files); got != tt.want {
+				t.Fatalf("isCodeScreenshot() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/cmd/consumer/playground"
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/flags"
+	"github.com/gobridge/gopherbot/internal/kb"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const kbUsage = "Usage: `kb enable|disable [#channel]` sets whether I explain the Go errors people post in the channel, " +
+	"`kb add <id> \"<pattern>\" \"<explanation>\"` adds an error (the pattern is a regular expression), " +
+	"`kb remove <id>` removes one added that way, and `kb list` lists them all."
+
+// injectKBHandlers registers the handler explaining the errors in messages and
+// snippets in the channels it's enabled in, and the commands for the
+// moderators to enable it and contribute to it.
+func injectKBHandlers(ma *handler.MessageActions, ks *kb.Store) {
+	ma.HandleDynamicFeature(flags.Responses,
+		func(shadowMode bool, m handler.Messenger) bool {
+			if shadowMode || !ks.Enabled(m.ChannelID()) {
+				return false
+			}
+
+			return playground.HasSnippet(m.Files()) || len(kb.Match(ks.Entries(), m.Text())) > 0
+		},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			texts := []string{m.Text()}
+
+			if playground.HasSnippet(m.Files()) {
+				snippets, err := playground.Snippets(ctx, m.Files())
+				if err != nil {
+					ctx.Logger().Warn().
+						Err(err).
+						Msg("failed to download snippets for the knowledge base")
+				}
+
+				for _, s := range snippets {
+					texts = append(texts, string(s))
+				}
+			}
+
+			entries := kb.Match(ks.Entries(), strings.Join(texts, "\n"))
+			if len(entries) == 0 {
+				return nil
+			}
+
+			var b strings.Builder
+
+			b.WriteString("That looks like an error I know:")
+
+			for _, e := range entries {
+				fmt.Fprintf(&b, "\n\n*%s*: %s", e.ID, e.Explanation)
+			}
+
+			return r.Respond(ctx, b.String())
+		},
+	)
+
+	ma.HandleCommand("kb", kbUsage, "(admins only) `kb enable #channel` explains Go errors posted in a channel",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			if !m.BotMentioned() && m.ChannelType() != handler.ChannelDM {
+				return nil
+			}
+
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can change the errors I explain.")
+			}
+
+			switch strings.ToLower(c.Arg(0)) {
+			case "enable", "disable":
+				enabled := strings.EqualFold(c.Arg(0), "enable")
+
+				channelID := m.ChannelID()
+
+				for _, mention := range m.AllMentions() {
+					if mention.Type == mparser.TypeChannelRef {
+						channelID = mention.ID
+						break
+					}
+				}
+
+				if err := ks.SetEnabled(ctx, channelID, enabled); err != nil {
+					return err
+				}
+
+				if !enabled {
+					return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I'll stop explaining errors in <#%s>.", channelID))
+				}
+
+				return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I'll explain the Go errors people post in <#%s>.", channelID))
+
+			case "add":
+				if len(c.Args) != 4 {
+					return &handler.UsageError{}
+				}
+
+				if err := ks.Add(ctx, c.Arg(1), c.Arg(2), c.Arg(3), m.UserID()); err != nil {
+					return handler.Usagef("I couldn't add it: %s", err)
+				}
+
+				ctx.Logger().Info().
+					Str("user_id", m.UserID()).
+					Str("kb_id", c.Arg(1)).
+					Msg("knowledge base entry added")
+
+				return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I'll explain `%s` errors from now on.", c.Arg(1)))
+
+			case "remove":
+				if len(c.Args) != 2 {
+					return &handler.UsageError{}
+				}
+
+				removed, err := ks.Remove(ctx, c.Arg(1))
+				if err != nil {
+					return err
+				}
+
+				if !removed {
+					return r.RespondEphemeral(ctx, fmt.Sprintf("There's no added entry `%s`. The built-in ones can be replaced by adding one with the same ID.", c.Arg(1)))
+				}
+
+				return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I removed `%s`.", c.Arg(1)))
+
+			case "list":
+				var b strings.Builder
+
+				for _, e := range ks.Entries() {
+					fmt.Fprintf(&b, "- `%s`: `%s`", e.ID, strings.TrimPrefix(e.Pattern.String(), "(?i)"))
+
+					if len(e.Author) > 0 {
+						fmt.Fprintf(&b, " (added by %s)", mparser.Mention{Type: mparser.TypeUser, ID: e.Author}.String())
+					}
+
+					b.WriteString("\n")
+				}
+
+				return r.RespondEphemeral(ctx, b.String())
+
+			default:
+				return &handler.UsageError{}
+			}
+		},
+	)
+}
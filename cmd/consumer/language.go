@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/i18n"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// injectLanguageHandlers registers the command people use to choose the
+// language of my canned responses.
+func injectLanguageHandlers(ma *handler.MessageActions, c *i18n.Catalog, ls *i18n.Store) {
+	langs := "`" + strings.Join(c.Languages(), "`, `") + "`"
+	usage := "To choose the language I respond in, use `language <code>`, where the code is one of: " + langs
+
+	ma.HandleCommand("language", usage, "choose the language of my canned responses, like `language es`",
+		func(ctx workqueue.Context, m handler.Messenger, cmd handler.Command, r handler.Responder) error {
+			if !m.BotMentioned() && m.ChannelType() != handler.ChannelDM {
+				return nil
+			}
+
+			if len(cmd.Args) == 0 {
+				lang, err := ls.Language(ctx, m.UserID())
+				if err != nil {
+					return fmt.Errorf("failed to get language: %w", err)
+				}
+
+				return r.RespondEphemeral(ctx, fmt.Sprintf("I respond to you in `%s`. %s", lang, usage))
+			}
+
+			lang := strings.ToLower(cmd.Arg(0))
+
+			if len(cmd.Args) > 1 || !c.Supports(lang) {
+				return handler.Usagef("I don't have any responses in `%s`", cmd.Arg(0))
+			}
+
+			if err := ls.SetLanguage(ctx, m.UserID(), lang); err != nil {
+				return fmt.Errorf("failed to set language: %w", err)
+			}
+
+			return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I'll respond to you in `%s` where I can, and in English where I can't.", lang))
+		},
+	)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/mentionsonly"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const mentionsOnlyUsage = "Usage: `mentionsonly on|off [#channel]` sets whether I stay quiet in the channel unless I'm mentioned: no reactions, and no responding to messages that aren't for me. Without arguments, it lists the mentions-only channels."
+
+// injectMentionsOnlyHandlers registers the command Workspace Admins use to
+// keep the bot quiet in a channel unless it's mentioned.
+func injectMentionsOnlyHandlers(ma *handler.MessageActions, ms *mentionsonly.Store) {
+	ma.HandleCommand("mentionsonly", mentionsOnlyUsage, "(admins only) `mentionsonly on #channel` keeps me quiet in a channel unless I'm mentioned",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			if !m.BotMentioned() && m.ChannelType() != handler.ChannelDM {
+				return nil
+			}
+
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can change where I stay quiet.")
+			}
+
+			if len(c.Args) == 0 {
+				channels, err := ms.Channels(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to get mentions-only channels: %w", err)
+				}
+
+				if len(channels) == 0 {
+					return r.RespondEphemeral(ctx, "No channels are mentions-only.")
+				}
+
+				var b strings.Builder
+
+				for _, cid := range channels {
+					fmt.Fprintf(&b, "- <#%s>\n", cid)
+				}
+
+				return r.RespondEphemeral(ctx, b.String())
+			}
+
+			if len(c.Args) != 1 {
+				return &handler.UsageError{}
+			}
+
+			var on bool
+
+			switch strings.ToLower(c.Arg(0)) {
+			case "on":
+				on = true
+			case "off":
+				on = false
+			default:
+				return handler.Usagef("I don't know the mode `%s`", c.Arg(0))
+			}
+
+			channelID := m.ChannelID()
+
+			for _, mention := range m.AllMentions() {
+				if mention.Type == mparser.TypeChannelRef {
+					channelID = mention.ID
+					break
+				}
+			}
+
+			if err := ms.Set(ctx, channelID, on); err != nil {
+				return err
+			}
+
+			ctx.Logger().Info().
+				Str("user_id", m.UserID()).
+				Str("channel_id", channelID).
+				Bool("mentions_only", on).
+				Msg("mentions-only mode updated")
+
+			if !on {
+				return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I'll chime in on messages in <#%s> again.", channelID))
+			}
+
+			return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I'll stay quiet in <#%s> unless I'm mentioned.", channelID))
+		},
+	)
+}
@@ -0,0 +1,218 @@
+package main
+
+import "github.com/gobridge/gopherbot/internal/i18n"
+
+// newMessageCatalog returns the canned responses registered with HandleMessage,
+// in English and whatever they've been translated to.
+func newMessageCatalog() *i18n.Catalog {
+	c := i18n.NewCatalog()
+
+	addEnglishMessages(c)
+	addSpanishMessages(c)
+
+	return c
+}
+
+// addEnglishMessages adds the English messages, which every message must have
+// as it's the default language.
+func addEnglishMessages(c *i18n.Catalog) {
+	c.Add(i18n.English, "recommended",
+		`Here are some popular blog posts and Twitter accounts you should follow:`,
+		`- Peter Bourgon <https://twitter.com/peterbourgon|@peterbourgon> - <https://peter.bourgon.org/blog>`,
+		`- Carlisia Campos <https://twitter.com/carlisia|@carlisia>`,
+		`- Dave Cheney <https://twitter.com/davecheney|@davecheney> - <http://dave.cheney.net>`,
+		`- Jaana Burcu Dogan <https://twitter.com/rakyll|@rakyll> - <http://golang.rakyll.org>`,
+		`- Jessie Frazelle <https://twitter.com/jessfraz|@jessfraz> - <https://blog.jessfraz.com>`,
+		`- William "Bill" Kennedy <https://twitter.com/goinggodotnet|@goinggodotnet> - <https://www.goinggo.net>`,
+		`- Brian Ketelsen <https://twitter.com/bketelsen|@bketelsen> - <https://www.brianketelsen.com/blog>`,
+	)
+
+	c.Add(i18n.English, "books",
+		`Here are some popular books you can use to get started:`,
+		`- William Kennedy, Brian Ketelsen, Erik St. Martin Go In Action <https://www.manning.com/books/go-in-action>`,
+		`- Alan A A Donovan, Brian W Kernighan The Go Programming Language <https://www.gopl.io>`,
+		`- Mat Ryer Go Programming Blueprints 2nd Edition <https://www.packtpub.com/application-development/go-programming-blueprints-second-edition>`,
+	)
+
+	c.Add(i18n.English, "oss_help_wanted",
+		`Here's a list of projects which could need some help from contributors like you: <https://github.com/corylanou/oss-helpwanted>`,
+	)
+
+	c.Add(i18n.English, "work_with_forks",
+		`Here's how to work with package forks in Go: <http://blog.sgmansfield.com/2016/06/working-with-forks-in-go/>`,
+	)
+
+	c.Add(i18n.English, "block_forever",
+		"Here's the most common way to block forever in Go: `select {}`.",
+		"For other ways check out this post: <https://blog.sgmansfield.com/2016/06/how-to-block-forever-in-go/>",
+	)
+
+	c.Add(i18n.English, "http_timeouts",
+		`Here's a blog post which will help with http timeouts in Go: <https://blog.cloudflare.com/the-complete-guide-to-golang-net-http-timeouts/>`,
+	)
+
+	c.Add(i18n.English, "slices",
+		`The following posts will explain how slices, maps and strings work in Go:`,
+		`- <https://blog.golang.org/go-slices-usage-and-internals>`,
+		`- <https://blog.golang.org/slices>`,
+		`- <https://blog.golang.org/strings>`,
+	)
+
+	c.Add(i18n.English, "database_tutorial",
+		`Here's how to work with database/sql in Go: <http://go-database-sql.org/>`,
+	)
+
+	c.Add(i18n.English, "empty_struct",
+		"You can find out more about the `struct{}` type in this post by Dave Cheney: <https://dave.cheney.net/2014/03/25/the-empty-struct>",
+	)
+
+	c.Add(i18n.English, "project_layout",
+		`These articles will explain how to organize your Go packages:`,
+		`- <https://rakyll.org/style-packages/>`,
+		`- <https://www.gobeyond.dev/standard-package-layout/>`,
+		`- <https://peter.bourgon.org/go-best-practices-2016/#repository-structure>`,
+		`- <https://christine.website/blog/within-go-repo-layout-2020-09-07>`,
+		``,
+		`This article will help you understand the design philosophy for packages: <https://www.goinggo.net/2017/02/design-philosophy-on-packaging.html>`,
+	)
+
+	c.Add(i18n.English, "go_challenges",
+		"Check out the growing list of Go challenges - <https://tutorialedge.net/challenges/go/>",
+	)
+
+	c.Add(i18n.English, "idiomatic_go",
+		`Tips on how to write idiomatic Go code <https://dmitri.shuralyov.com/idiomatic-go>`,
+	)
+
+	c.Add(i18n.English, "invite_link",
+		`<https://invite.slack.golangbridge.org/>`,
+	)
+
+	c.Add(i18n.English, "avoid_gotchas",
+		`Read this article if you want to understand and avoid common gotchas in Go <https://divan.github.io/posts/avoid_gotchas>`,
+	)
+
+	c.Add(i18n.English, "source_code",
+		`You can find my source code, including all of my configured responses, here:`,
+		`- <https://github.com/gobridge/gopherbot>`,
+	)
+
+	c.Add(i18n.English, "dependency_injection",
+		`If you'd like to learn more about how to use Dependency Injection in Go, please review this post:`,
+		`- <https://appliedgo.net/di/>`,
+	)
+
+	c.Add(i18n.English, "pointer_performance",
+		`The answer to whether using a pointer offers a performance gain is complex and is not always the case. Please read these posts for more information:`,
+		`- <https://medium.com/@vCabbage/go-are-pointers-a-performance-optimization-a95840d3ef85>`,
+		`- <https://segment.com/blog/allocation-efficiency-in-high-performance-go-services/>`,
+	)
+
+	c.Add(i18n.English, "gopath",
+		"Your project should be structured as follows:",
+		"```GOPATH=~/go",
+		"~/go/src/sourcecontrol/username/project/```",
+		"Whilst you _can_ get around the GOPATH, it's ill-advised. Read more about the GOPATH here: https://github.com/golang/go/wiki/GOPATH",
+	)
+
+	c.Add(i18n.English, "playground",
+		`Please share your code via the Go Playground. The Playground offers a specialized runtime environment, that others can use to read your code, iterate on it, and share it back with you:`,
+		`- <https://go.dev/play/>`,
+		`There is also this stylized alternate front-end: <https://goplay.space/>`,
+	)
+
+	c.Add(i18n.English, "code_of_conduct",
+		`We're all expected to follow the GoBridge Code of Conduct, which is itself a superset of the Go Community Code of Conduct. You can find both here:`,
+		`- <http://coc.golangbridge.org>`,
+		`- <https://golang.org/conduct>`,
+		`If you have any questions or concerns please reach out in <#C4U9J9QBT> or email support@gobridge.org.`,
+	)
+
+	c.Add(i18n.English, "screenshots",
+		`Screenshots are neither easy to read nor very accessible for many people. Please consider copying code, errors, or command output into a Slack snippet, the <https://go.dev/play/|Go Playground>, or a <https://gist.github.com/|GitHub Gist>.`,
+	)
+
+	c.Add(i18n.English, "go_tour",
+		`The Go Tour is a great resource for learning Go, and can be found here: <https://tour.golang.org/welcome/1>`,
+	)
+
+	c.Add(i18n.English, "doesnt_work",
+		`Can you share more context on what you expected and what you saw instead?`,
+		`If there's an error, are you able to provide it in full and share how you generated that error?`,
+	)
+
+	c.Add(i18n.English, "ask",
+		`Don't ask to ask. Just ask. We'll let you know if there's a better place to ask.`,
+		`- <https://dontasktoask.com/>`,
+	)
+
+	c.Add(i18n.English, "crosspost",
+		crosspostGuidance,
+	)
+
+	c.Add(i18n.English, "contribute",
+		`Contributing to Go itself goes through Gerrit rather than GitHub pull requests. The steps, from the contribution guide <https://go.dev/doc/contribute>:`,
+		"1. Sign the Google CLA, with the account you'll use for Gerrit: <https://cla.developers.google.com/>",
+		"2. Set up Gerrit and `git-codereview` (try `gerrit setup`): <https://go.dev/doc/contribute#config_git_auth>",
+		"3. Find an issue to work on (try `first issue`), and comment that you're taking it, or open one to discuss the change first: <https://go.dev/doc/contribute#before_contributing>",
+		"4. Make the change on a branch, with tests, and run `./all.bash` from `src`: <https://go.dev/doc/contribute#making_a_change>",
+		"5. Write the commit message the Go way, like `net/http: fix panic on empty header`, mentioning the issue: <https://go.dev/doc/contribute#commit_messages>",
+		"6. Send it with `git codereview mail`, and address the review comments: <https://go.dev/doc/contribute#review>",
+		`The golang-dev mailing list is where changes to Go are discussed: <https://groups.google.com/g/golang-dev>`,
+	)
+
+	c.Add(i18n.English, "gerrit_setup",
+		`To send changes to Go you need a Gerrit account and the git-codereview tool:`,
+		"1. Sign in to <https://go-review.googlesource.com/login/> with the Google account you signed the CLA with.",
+		"2. Get a password for git at <https://go.googlesource.com/>, using *Generate Password*, and run the script it gives you.",
+		"3. Install the tool with `go install golang.org/x/review/git-codereview@latest`.",
+		"4. Clone with `git clone https://go.googlesource.com/go`, and in it run `git codereview hooks`.",
+		"Then `git codereview change` commits to a branch, and `git codereview mail` sends the change for review. More at <https://go.dev/doc/contribute#config_git_auth>.",
+	)
+
+	addFyneEnglishMessages(c)
+}
+
+func addFyneEnglishMessages(c *i18n.Catalog) {
+	c.Add(i18n.English, "fyne_books",
+		`Here are some helpful books that discuss Fyne:`,
+		`- Andrew Williams Building Cross-Platform GUI Applications with Fyne <https://www.packtpub.com/product/building-cross-platform-gui-applications-with-fyne/9781800563162>`,
+		`- Andrew Williams Hands-On GUI Application Development in Go <https://www.packtpub.com/product/hands-on-gui-application-development-in-go/9781789138412>`,
+	)
+
+	c.Add(i18n.English, "fyne_tour",
+		`The Fyne Tour is a starting point for learning the toolkit, and can be found here: <https://tour.fyne.io/>`,
+	)
+
+	c.Add(i18n.English, "fyne_compile",
+		`To compile a Fyne app you will need CGo working which requires a C compiler as well as Go.
+			For more information follow the platform specific hints at <https://developer.fyne.io/started/>.
+			Windows based developers may need to double check that their C compiler is 64bit as many default to 32`,
+	)
+
+	c.Add(i18n.English, "fyne_docs",
+		`There are various sources of Fyne documentation depending on what you are looking for:`,
+		`- Getting started <https://developer.fyne.io/started/>`,
+		`- API documentation <https://developer.fyne.io/api/>`,
+		`- Tutorials and tips for advanced topics <https://developer.fyne.io/tutorial/>`,
+		`- Video tutorials and conference recordings <https://www.youtube.com/c/fyne-io>`,
+	)
+
+	c.Add(i18n.English, "fyne_layouts",
+		`In Fyne, a containers layout controls the size and position of widgets,
+			there is a list of standard layouts at <https://developer.fyne.io/started/layouts>
+			and you can build your own <https://developer.fyne.io/tutorial/custom-layout>`,
+	)
+
+	c.Add(i18n.English, "fyne_sizes",
+		`In Fyne, a widgets size and position is controlled by the layout of the container that it is inside <https://developer.fyne.io/faq/layout>.
+		If your widget is too small perhaps consider avoiding use of VBox and HBox which try to pack items in small.`,
+	)
+
+	c.Add(i18n.English, "fyne_theme",
+		`The look and feel of a Fyne application is controlled by the current theme.
+			The default material design theme has both light and dark modes (set by user preference)
+			Apps can specify a custom theme <https://developer.fyne.io/tutorial/custom-theme>,
+			more info about customisation at <https://developer.fyne.io/faq/theme>`,
+	)
+}
@@ -0,0 +1,46 @@
+package main
+
+import "github.com/gobridge/gopherbot/internal/i18n"
+
+// spanish is the language code for Spanish.
+const spanish = "es"
+
+// addSpanishMessages adds the messages translated to Spanish. Those that
+// aren't are sent in English.
+func addSpanishMessages(c *i18n.Catalog) {
+	c.Add(spanish, "source_code",
+		`Puedes encontrar mi código fuente, incluidas todas mis respuestas configuradas, aquí:`,
+		`- <https://github.com/gobridge/gopherbot>`,
+	)
+
+	c.Add(spanish, "playground",
+		`Por favor, comparte tu código a través del Go Playground. El Playground ofrece un entorno de ejecución especializado que otros pueden usar para leer tu código, modificarlo y compartirlo contigo:`,
+		`- <https://go.dev/play/>`,
+		`También existe esta interfaz alternativa con estilo: <https://goplay.space/>`,
+	)
+
+	c.Add(spanish, "code_of_conduct",
+		`Todos debemos seguir el Código de Conducta de GoBridge, que a su vez amplía el Código de Conducta de la Comunidad Go. Puedes encontrar ambos aquí:`,
+		`- <http://coc.golangbridge.org>`,
+		`- <https://golang.org/conduct>`,
+		`Si tienes preguntas o inquietudes, escríbenos en <#C4U9J9QBT> o envía un correo a support@gobridge.org.`,
+	)
+
+	c.Add(spanish, "screenshots",
+		`Las capturas de pantalla no son fáciles de leer ni muy accesibles para muchas personas. Por favor, considera copiar el código, los errores o la salida de los comandos en un snippet de Slack, el <https://go.dev/play/|Go Playground> o un <https://gist.github.com/|GitHub Gist>.`,
+	)
+
+	c.Add(spanish, "go_tour",
+		`El Tour de Go es un gran recurso para aprender Go, y lo puedes encontrar aquí: <https://go.dev/tour/welcome/1?lang=es>`,
+	)
+
+	c.Add(spanish, "doesnt_work",
+		`¿Puedes compartir más contexto sobre lo que esperabas y lo que obtuviste en su lugar?`,
+		`Si hay un error, ¿puedes compartirlo completo y explicar cómo lo generaste?`,
+	)
+
+	c.Add(spanish, "ask",
+		`No preguntes si puedes preguntar. Solo pregunta. Te avisaremos si hay un mejor lugar para hacerlo.`,
+		`- <https://dontasktoask.com/es/>`,
+	)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/moderation"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// injectModerationHandlers registers the reactions moderators use to give
+// feedback on the alerts sent by the moderation detectors.
+func injectModerationHandlers(ra *handler.ReactionAddedActions, mod *moderation.Moderator) {
+	feedback := func(v moderation.Verdict) handler.ReactionAddedActionFn {
+		return func(ctx workqueue.Context, rr handler.Reactor, _ handler.Responder) error {
+			found, err := mod.Feedback(ctx, rr.ChannelID(), rr.MessageTS(), v)
+			if err != nil {
+				return fmt.Errorf("failed to record moderation feedback: %w", err)
+			}
+
+			if found {
+				ctx.Logger().Info().
+					Str("user_id", rr.UserID()).
+					Str("message_ts", rr.MessageTS()).
+					Str("verdict", string(v)).
+					Msg("recorded moderation feedback")
+			}
+
+			return nil
+		}
+	}
+
+	ra.Handle("moderation_feedback_correct", moderation.CorrectReaction, feedback(moderation.Correct))
+	ra.Handle("moderation_feedback_incorrect", moderation.IncorrectReaction, feedback(moderation.Incorrect))
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/modmail"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/outbox"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+const (
+	modmailCommand = "modmail"
+
+	// modmailNotePrefix starts the replies in a conversation's thread in the
+	// moderators' channel that are only for the other moderators, and aren't
+	// sent to the member.
+	modmailNotePrefix = "//"
+)
+
+// modmailBody returns the message to send the moderators, if the text is a
+// modmail command, ignoring a mention of the bot before it.
+func modmailBody(raw string) (string, bool) {
+	s := strings.TrimSpace(raw)
+
+	if strings.HasPrefix(s, "<@") {
+		if i := strings.IndexByte(s, '>'); i > 0 {
+			s = strings.TrimSpace(s[i+1:])
+		}
+	}
+
+	f := strings.Fields(s)
+	if len(f) == 0 || !strings.EqualFold(f[0], modmailCommand) {
+		return "", false
+	}
+
+	return strings.TrimSpace(s[len(f[0]):]), true
+}
+
+func modmailQuote(s string) string {
+	return "> " + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n> ")
+}
+
+func modmailOpened(userID, body string) string {
+	return fmt.Sprintf(":incoming_envelope: Mod mail from <@%s>:\n%s\n_Reply in this thread to answer them. Replies starting with `%s` aren't sent to them._",
+		userID, modmailQuote(body), modmailNotePrefix,
+	)
+}
+
+func modmailFromMember(userID, text string) string {
+	return fmt.Sprintf("<@%s> replied:\n%s", userID, modmailQuote(text))
+}
+
+func modmailFromModerators(text string) string {
+	return fmt.Sprintf("The moderators replied:\n%s", modmailQuote(text))
+}
+
+// isThreadReply returns whether the message is a reply in a thread, rather
+// than the message that started it.
+func isThreadReply(m handler.Messenger) bool {
+	ts := m.ThreadTS()
+	return len(ts) > 0 && ts != m.MessageTS()
+}
+
+// modmailMessage returns the outbox message posting the text in the thread,
+// for the message with the timestamp ts. Retrying the event it's for queues
+// the same message again, which is then dropped as a duplicate.
+func modmailMessage(t modmail.Thread, ts, text string) outbox.Message {
+	return outbox.Message{
+		Key:       fmt.Sprintf("modmail:%s:%s:%s", ts, t.ChannelID, t.TS),
+		ChannelID: t.ChannelID,
+		ThreadTS:  t.TS,
+		Text:      text,
+	}
+}
+
+// postModmail posts the text in the thread.
+func postModmail(ctx workqueue.Context, t modmail.Thread, text string) error {
+	_, _, err := ctx.Slack().PostMessageContext(ctx, t.ChannelID,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionTS(t.TS),
+		slack.MsgOptionDisableLinkUnfurl(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to post in thread %s in channel %s: %w", t.TS, t.ChannelID, err)
+	}
+
+	return nil
+}
+
+// injectModmailHandlers registers the modmail command, which members DM the
+// bot to start a private conversation with the moderators. The message is
+// posted to the moderators' channel, and from then on replies in its thread
+// there are relayed to a thread in the member's DM, and back, without the
+// moderators answering being named.
+func injectModmailHandlers(ma *handler.MessageActions, nr *notify.Router, store *modmail.Store) {
+	ma.HandlePrefix(modmailCommand, "privately message the moderators, in a DM with me", func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+		body, ok := modmailBody(m.RawText())
+		if !ok {
+			return nil
+		}
+
+		if m.ChannelType() != handler.ChannelDM {
+			// "modmail" starting a message in a channel is only a command
+			// when it's addressed to the bot
+			if !m.BotMentioned() {
+				return nil
+			}
+
+			return r.RespondEphemeral(ctx, fmt.Sprintf("To keep it private, send `%s <message>` in a DM with me.", modmailCommand))
+		}
+
+		if isThreadReply(m) {
+			dm := modmail.Thread{ChannelID: m.ChannelID(), TS: m.ThreadTS()}
+
+			// replies in a conversation's thread are relayed like any other
+			_, found, err := store.ByDM(ctx, dm)
+			if err != nil || found {
+				return err
+			}
+
+			return r.Respond(ctx, fmt.Sprintf("Send `%s <message>` as a new message, rather than in a thread, to start a conversation with the moderators.", modmailCommand))
+		}
+
+		if len(body) == 0 {
+			return r.Respond(ctx, fmt.Sprintf("Tell me what you'd like to send the moderators, like `%s someone is sending me spam`.", modmailCommand))
+		}
+
+		dm := modmail.Thread{ChannelID: m.ChannelID(), TS: m.MessageTS()}
+
+		ds, err := nr.Notify(ctx, notify.Notification{
+			Source:   notify.Moderation,
+			Severity: notify.Important,
+			Summary:  fmt.Sprintf("mod mail from %s", m.UserID()),
+			Options: []slack.MsgOption{
+				slack.MsgOptionText(modmailOpened(m.UserID(), body), false),
+				slack.MsgOptionDisableLinkUnfurl(),
+			},
+		})
+		if err != nil {
+			if len(ds) == 0 {
+				return fmt.Errorf("failed to send mod mail to moderators: %w", err)
+			}
+
+			// some of the moderators' channels got it, which is enough
+			ctx.Logger().Warn().
+				Err(err).
+				Msg("failed to send mod mail to every moderators' channel")
+		}
+
+		if len(ds) == 0 {
+			return postModmail(ctx, dm, "Sorry, I couldn't reach the moderators right now. Please try again later, or ask in <#C4U9J9QBT>.")
+		}
+
+		c := modmail.Conversation{
+			UserID: m.UserID(),
+			DM:     dm,
+			Admin:  make([]modmail.Thread, len(ds)),
+		}
+
+		for i, d := range ds {
+			c.Admin[i] = modmail.Thread{ChannelID: d.ChannelID, TS: d.TS}
+		}
+
+		ack := modmailMessage(dm, m.MessageTS(), "I sent your message to the moderators. Their replies will show up in this thread, and you can reply here to add to the conversation.")
+
+		if err := store.Save(ctx, c, ack); err != nil {
+			return fmt.Errorf("failed to save mod mail conversation: %w", err)
+		}
+
+		return nil
+	})
+
+	// relay the replies in the conversations' threads, in DMs and the
+	// (private) moderators' channels
+	ma.HandleDynamic(
+		func(_ bool, m handler.Messenger) bool {
+			if !isThreadReply(m) {
+				return false
+			}
+
+			switch m.ChannelType() {
+			case handler.ChannelDM, handler.ChannelPrivate:
+				return true
+			default:
+				return false
+			}
+		},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			t := modmail.Thread{ChannelID: m.ChannelID(), TS: m.ThreadTS()}
+
+			var (
+				c     modmail.Conversation
+				found bool
+				err   error
+			)
+
+			dm := m.ChannelType() == handler.ChannelDM
+
+			if dm {
+				c, found, err = store.ByDM(ctx, t)
+			} else {
+				c, found, err = store.ByAdmin(ctx, t)
+			}
+
+			if err != nil || !found {
+				return err
+			}
+
+			var msgs []outbox.Message
+
+			switch {
+			case dm:
+				for _, at := range c.Admin {
+					msgs = append(msgs, modmailMessage(at, m.MessageTS(), modmailFromMember(c.UserID, m.RawText())))
+				}
+
+			case strings.HasPrefix(strings.TrimSpace(m.RawText()), modmailNotePrefix):
+				return nil
+
+			default:
+				msgs = append(msgs, modmailMessage(c.DM, m.MessageTS(), modmailFromModerators(m.RawText())))
+			}
+
+			// relaying it also keeps the conversation going for as long as
+			// it's active
+			if err := store.Save(ctx, c, msgs...); err != nil {
+				return fmt.Errorf("failed to relay mod mail: %w", err)
+			}
+
+			return r.React(ctx, "white_check_mark")
+		},
+	)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/antispam"
+	"github.com/gobridge/gopherbot/internal/joins"
+	"github.com/gobridge/gopherbot/internal/moderation"
+	"github.com/gobridge/gopherbot/internal/ratelimit"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const newAccountDetector = "new_account"
+
+// slackLinkRegex matches links as Slack formats them in the raw message text.
+var slackLinkRegex = regexp.MustCompile(`<https?://[^>]+>`)
+
+// newAccountRisk returns what a new account did that it shouldn't have, or an
+// empty string if nothing.
+func newAccountRisk(m handler.Messenger) string {
+	var risks []string
+
+	for _, mn := range m.AllMentions() {
+		if mn.Type == mparser.TypeHere || mn.Type == mparser.TypeChannel || mn.Type == mparser.TypeEveryone {
+			risks = append(risks, "mentioned the whole channel")
+			break
+		}
+	}
+
+	if slackLinkRegex.MatchString(m.RawText()) {
+		risks = append(risks, "posted a link")
+	}
+
+	return strings.Join(risks, " and ")
+}
+
+// injectNewAccountHandlers records when users join, and flags those who post
+// links or mention the whole channel within the window after joining. When the
+// detector is in the moderation.Enforce mode, their messages are also deleted.
+// Moderators are only alerted once per user per window, by the limiter.
+func injectNewAccountHandlers(tja *handler.TeamJoinActions, ma *handler.MessageActions, js *joins.Store, limiter *ratelimit.Limiter, del *antispam.Deleter, mod *moderation.Moderator, window time.Duration) {
+	tja.Handle("record join time",
+		func(ctx workqueue.Context, tj handler.TeamJoiner, r handler.Responder) error {
+			return js.Record(ctx, tj.User().ID, ctx.Meta().Time)
+		},
+	)
+
+	match := func(_ bool, m handler.Messenger) bool {
+		return m.ChannelType() == handler.ChannelPublic && len(newAccountRisk(m)) > 0
+	}
+
+	ma.HandleDynamic(match, func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+		joined, found, err := js.JoinedAt(ctx, m.UserID())
+		if err != nil {
+			return fmt.Errorf("failed to get join time: %w", err)
+		}
+
+		age := ctx.Meta().Time.Sub(joined)
+
+		if !found || age > window {
+			return nil
+		}
+
+		enforce := func(ctx context.Context) error {
+			if err := del.Delete(ctx, m.ChannelID(), m.MessageTS()); err != nil {
+				return err
+			}
+
+			msg := fmt.Sprintf("Welcome! To protect the community from spam, new members can't post links or mention the whole channel for their first %s. Please try again a bit later.", window)
+
+			return r.RespondEphemeral(ctx, msg)
+		}
+
+		allowed, _, err := limiter.Allow(ctx, m.UserID())
+		if err != nil {
+			return fmt.Errorf("failed to check alert rate limit: %w", err)
+		}
+
+		if !allowed {
+			// moderators already know about this user
+			if mod.Mode(newAccountDetector) == moderation.Enforce {
+				return enforce(ctx)
+			}
+
+			return nil
+		}
+
+		return mod.Flag(ctx, moderation.Decision{
+			Detector:  newAccountDetector,
+			ChannelID: m.ChannelID(),
+			UserID:    m.UserID(),
+			MessageTS: m.MessageTS(),
+			Score:     1,
+			Threshold: 1,
+			Reason:    fmt.Sprintf("%s %s after joining", newAccountRisk(m), age.Round(time.Second)),
+		}, enforce)
+	})
+}
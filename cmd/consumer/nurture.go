@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/nurture"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// injectNurtureHandlers tracks new members until they post in a public channel,
+// so bgtasks can follow up with those who haven't a week after joining, and
+// registers the commands to opt out of, or back in to, the follow-ups.
+func injectNurtureHandlers(tja *handler.TeamJoinActions, ma *handler.MessageActions, ns *nurture.Store) {
+	tja.Handle("track first message",
+		func(ctx workqueue.Context, tj handler.TeamJoiner, r handler.Responder) error {
+			u := tj.User()
+
+			if u.IsBot || u.IsRestricted {
+				return nil
+			}
+
+			optedOut, err := ns.OptedOut(ctx, u.ID)
+			if err != nil || optedOut {
+				return err
+			}
+
+			return ns.Track(ctx, u.ID, ctx.Meta().Time)
+		},
+	)
+
+	ma.HandleDynamic(
+		func(_ bool, m handler.Messenger) bool {
+			return m.ChannelType() == handler.ChannelPublic
+		},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			return ns.Seen(ctx, m.UserID())
+		},
+	)
+
+	ma.Handle("followups off", "stop the follow-up DMs I send in your first week", nil,
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			if err := ns.SetOptedOut(ctx, m.UserID(), true); err != nil {
+				return fmt.Errorf("failed to opt out of follow-ups: %w", err)
+			}
+
+			return r.RespondEphemeral(ctx, "Okay, I won't send you any follow-ups. "+
+				`Tell me "followups on" if you change your mind.`,
+			)
+		},
+	)
+
+	ma.Handle("followups on", "undo followups off", nil,
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			if err := ns.SetOptedOut(ctx, m.UserID(), false); err != nil {
+				return fmt.Errorf("failed to opt in to follow-ups: %w", err)
+			}
+
+			return r.RespondEphemeral(ctx, "Okay, you're opted back in to follow-ups.")
+		},
+	)
+}
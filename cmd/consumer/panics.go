@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// panicReportInterval is how often each handler's panics are reported, so a
+// handler panicking on every event doesn't flood the channel.
+const panicReportInterval = 10 * time.Minute
+
+// maxPanicStack is how much of the stack trace is included in reports.
+const maxPanicStack = 2500
+
+// newPanicReporter returns a workqueue.PanicHandler that posts handler panics
+// to the channel.
+func newPanicReporter(nr *notify.Router, channelID string, logger zerolog.Logger) workqueue.PanicHandler {
+	var mu sync.Mutex
+	last := make(map[string]time.Time)
+
+	return func(p workqueue.Panic) {
+		mu.Lock()
+
+		if time.Since(last[p.Handler]) < panicReportInterval {
+			mu.Unlock()
+			return
+		}
+
+		last[p.Handler] = time.Now()
+
+		mu.Unlock()
+
+		stack := strings.TrimSpace(string(p.Stack))
+		if len(stack) > maxPanicStack {
+			stack = stack[:maxPanicStack] + "\n..."
+		}
+
+		msg := fmt.Sprintf(
+			":rotating_light: The `%s` handler panicked on event `%s` (redis message `%s`): `%v`\n```%s```",
+			p.Handler, p.Event.ID, p.Event.RedisEvent, p.Value, stack,
+		)
+
+		n := notify.Notification{
+			Source:   notify.Errors,
+			Severity: notify.Important,
+			Summary:  fmt.Sprintf("%s handler panic", p.Handler),
+			Options: []slack.MsgOption{
+				slack.MsgOptionText(msg, false),
+			},
+		}
+
+		// don't hold up acknowledging the message
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if _, err := nr.NotifyChannel(ctx, channelID, n); err != nil {
+				logger.Error().
+					Err(err).
+					Str("handler", p.Handler).
+					Msg("failed to report handler panic")
+			}
+		}()
+	}
+}
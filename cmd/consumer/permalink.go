@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// permalinkReaction is the emoji that, when added to a message, has the bot
+// reply in-thread with the canonical link to the conversation.
+const permalinkReaction = "link"
+
+func injectPermalinkHandlers(ma *handler.MessageActions, ra *handler.ReactionAddedActions) {
+	ma.Handle("permalink", "link to this message, or the thread it's in", []string{"link this thread", "thread link"},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			link, err := r.Permalink(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get permalink: %w", err)
+			}
+
+			return r.Respond(ctx, fmt.Sprintf("Here's the link to this conversation: <%s>", link))
+		},
+	)
+
+	ra.Handle("permalink", permalinkReaction,
+		func(ctx workqueue.Context, rr handler.Reactor, r handler.Responder) error {
+			link, err := r.Permalink(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get permalink: %w", err)
+			}
+
+			ctx.Logger().Debug().
+				Str("channel_id", rr.ChannelID()).
+				Str("message_ts", rr.MessageTS()).
+				Str("user_id", rr.UserID()).
+				Msg("sharing permalink for reaction")
+
+			return r.Respond(ctx, fmt.Sprintf("Here's the link to this conversation: <%s>", link))
+		},
+	)
+}
@@ -0,0 +1,190 @@
+package playground
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+)
+
+// ChannelStore is the interface to keep track of the channels admins have
+// disabled or enabled the playground uploader in, overriding the defaults.
+type ChannelStore interface {
+	ChannelOverrides(ctx context.Context) (map[string]bool, error)
+	SetChannelDisabled(ctx context.Context, channelID string, disabled bool) error
+}
+
+// blacklistTTL is how long the channel overrides are cached for, so other
+// consumers pick up changes within this long.
+const blacklistTTL = time.Minute
+
+// blacklist is the set of channels the uploader won't operate in: the defaults
+// plus any overrides from the ChannelStore, which are cached so that matching
+// messages doesn't hit Redis every time.
+type blacklist struct {
+	defaults map[string]struct{}
+	s        ChannelStore
+	logger   zerolog.Logger
+
+	mu        sync.Mutex
+	overrides map[string]bool
+	fetched   time.Time
+}
+
+func newBlacklist(defaults []string, s ChannelStore, logger zerolog.Logger) *blacklist {
+	m := make(map[string]struct{}, len(defaults))
+
+	for _, cid := range defaults {
+		m[cid] = struct{}{}
+	}
+
+	return &blacklist{
+		defaults: m,
+		s:        s,
+		logger:   logger,
+	}
+}
+
+// disabled returns whether the uploader is disabled in the channel. If the
+// overrides can't be refreshed, the last ones fetched are used.
+func (b *blacklist) disabled(channelID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if time.Since(b.fetched) > blacklistTTL {
+		ctx, cancel := context.WithTimeout(context.Background(), optOutTimeout)
+
+		overrides, err := b.s.ChannelOverrides(ctx)
+
+		cancel()
+
+		if err != nil {
+			b.logger.Error().
+				Err(err).
+				Msg("failed to refresh playground channel overrides")
+		} else {
+			b.overrides = overrides
+			b.fetched = time.Now()
+		}
+	}
+
+	if disabled, ok := b.overrides[channelID]; ok {
+		return disabled
+	}
+
+	_, ok := b.defaults[channelID]
+
+	return ok
+}
+
+func (b *blacklist) set(ctx context.Context, channelID string, disabled bool) error {
+	if err := b.s.SetChannelDisabled(ctx, channelID, disabled); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.fetched = time.Time{} // refresh on next use
+	b.mu.Unlock()
+
+	return nil
+}
+
+// refresh fetches the overrides now, instead of waiting for the cached ones to
+// expire, and describes each channel whose override changed as a result.
+func (b *blacklist) refresh(ctx context.Context) ([]string, error) {
+	overrides, err := b.s.ChannelOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	old := b.overrides
+	b.overrides = overrides
+	b.fetched = time.Now()
+	b.mu.Unlock()
+
+	var changes []string
+
+	for cid, disabled := range overrides {
+		if was, ok := old[cid]; ok && was == disabled {
+			continue
+		}
+
+		state := "enabled"
+		if disabled {
+			state = "disabled"
+		}
+
+		changes = append(changes, fmt.Sprintf("playground uploads %s in <#%s>", state, cid))
+	}
+
+	for cid := range old {
+		if _, ok := overrides[cid]; !ok {
+			changes = append(changes, fmt.Sprintf("playground uploads back to the default in <#%s>", cid))
+		}
+	}
+
+	sort.Strings(changes)
+
+	return changes, nil
+}
+
+// ReloadChannels refreshes the channels admins have enabled or disabled the
+// uploader in, describing what changed.
+func (c *Client) ReloadChannels(ctx context.Context) ([]string, error) {
+	return c.blacklist.refresh(ctx)
+}
+
+func (c *Client) setChannels(ctx workqueue.Context, m handler.Messenger, r handler.Responder, disabled bool) error {
+	admin, err := handler.IsAdmin(ctx, m.UserID())
+	if err != nil {
+		return err
+	}
+
+	if !admin {
+		return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can change where I upload code to the playground.")
+	}
+
+	var channels []string
+
+	for _, mention := range m.AllMentions() {
+		if mention.Type == mparser.TypeChannelRef {
+			channels = append(channels, mention.ID)
+		}
+	}
+
+	if len(channels) == 0 {
+		return r.RespondEphemeral(ctx, "Please tell me which channels, like `playground disable #random`.")
+	}
+
+	for _, cid := range channels {
+		if err := c.blacklist.set(ctx, cid, disabled); err != nil {
+			return fmt.Errorf("failed to update playground channel %s: %w", cid, err)
+		}
+	}
+
+	state := "enabled"
+	if disabled {
+		state = "disabled"
+	}
+
+	return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, playground uploads are now %s in %d channel(s).", state, len(channels)))
+}
+
+// DisableHandler is a handler.MessageActionFn, which lets Workspace Admins
+// stop the uploader from operating in the channels mentioned in the message.
+func (c *Client) DisableHandler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	return c.setChannels(ctx, m, r, true)
+}
+
+// EnableHandler is a handler.MessageActionFn, which undoes DisableHandler, or
+// enables the uploader in a channel it's disabled in by default.
+func (c *Client) EnableHandler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	return c.setChannels(ctx, m, r, false)
+}
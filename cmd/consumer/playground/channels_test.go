@@ -0,0 +1,95 @@
+package playground
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rs/zerolog"
+)
+
+type fakeChannelStore map[string]bool
+
+func (f fakeChannelStore) ChannelOverrides(context.Context) (map[string]bool, error) {
+	m := make(map[string]bool, len(f))
+
+	for k, v := range f {
+		m[k] = v
+	}
+
+	return m, nil
+}
+
+func (f fakeChannelStore) SetChannelDisabled(_ context.Context, channelID string, disabled bool) error {
+	f[channelID] = disabled
+	return nil
+}
+
+func Test_blacklist_disabled(t *testing.T) {
+	s := fakeChannelStore{
+		"C2": false, // enabled, despite being a default
+		"C3": true,
+	}
+
+	b := newBlacklist([]string{"C1", "C2"}, s, zerolog.Nop())
+
+	tests := []struct {
+		channelID string
+		want      bool
+	}{
+		{channelID: "C1", want: true},
+		{channelID: "C2", want: false},
+		{channelID: "C3", want: true},
+		{channelID: "C4", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := b.disabled(tt.channelID); got != tt.want {
+			t.Errorf("disabled(%q) = %t, want %t", tt.channelID, got, tt.want)
+		}
+	}
+
+	if err := b.set(context.Background(), "C4", true); err != nil {
+		t.Fatalf("set() unexpected error: %v", err)
+	}
+
+	if !b.disabled("C4") {
+		t.Error("disabled(\"C4\") = false after set(), want true")
+	}
+}
+
+func Test_blacklist_refresh(t *testing.T) {
+	s := fakeChannelStore{
+		"C1": true,
+		"C2": false,
+	}
+
+	b := newBlacklist(nil, s, zerolog.Nop())
+
+	if !b.disabled("C1") {
+		t.Fatal("disabled(\"C1\") = false, want true")
+	}
+
+	s["C2"] = true
+	s["C3"] = false
+	delete(s, "C1")
+
+	got, err := b.refresh(context.Background())
+	if err != nil {
+		t.Fatalf("refresh() unexpected error: %v", err)
+	}
+
+	want := []string{
+		"playground uploads back to the default in <#C1>",
+		"playground uploads disabled in <#C2>",
+		"playground uploads enabled in <#C3>",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("refresh() mismatch (-want +got):\n%s", diff)
+	}
+
+	if b.disabled("C1") || !b.disabled("C2") {
+		t.Error("refresh() didn't replace the cached overrides")
+	}
+}
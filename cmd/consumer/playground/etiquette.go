@@ -0,0 +1,93 @@
+package playground
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const (
+	// messagePrompt and filePrompt are the etiquette prompts, after long
+	// code messages and uploaded Go files.
+	messagePrompt = "message"
+	filePrompt    = "file"
+
+	// EtiquetteLimit is how many times each etiquette prompt is shown to
+	// someone, before it stops.
+	EtiquetteLimit = 3
+
+	// NoHelpToken in a message stops the etiquette prompt for it, but not
+	// the upload.
+	NoHelpToken = "nohelp"
+)
+
+// EtiquetteStore is the interface to keep track of how many times each user has
+// seen each etiquette prompt, and who has asked to stop seeing them.
+type EtiquetteStore interface {
+	EtiquetteSeen(ctx context.Context, userID, prompt string) (count int, dismissed bool, err error)
+	RecordEtiquette(ctx context.Context, userID, prompt string) error
+	DismissEtiquette(ctx context.Context, userID string) error
+}
+
+// etiquetteDue returns whether to show an etiquette prompt after the message,
+// given how many times the user has seen it, and whether they dismissed them.
+func etiquetteDue(rawText string, count int, dismissed bool) bool {
+	return !strings.Contains(rawText, NoHelpToken) && !dismissed && count < EtiquetteLimit
+}
+
+// showEtiquette returns whether to show the user the etiquette prompt for the
+// message. If the store can't be read, it is shown. It isn't shown for
+// messages from integrations, which have no one to remind.
+func (c *Client) showEtiquette(ctx workqueue.Context, m handler.Messenger, prompt string) bool {
+	if len(m.UserID()) == 0 || !etiquetteDue(m.RawText(), 0, false) {
+		return false
+	}
+
+	count, dismissed, err := c.etiquette.EtiquetteSeen(ctx, m.UserID(), prompt)
+	if err != nil {
+		ctx.Logger().Error().
+			Err(err).
+			Str("prompt", prompt).
+			Msg("failed to check how often the etiquette prompt was seen")
+
+		return true
+	}
+
+	return etiquetteDue(m.RawText(), count, dismissed)
+}
+
+// respondEtiquette shows the user the etiquette prompt, if they should see it,
+// and records that they have.
+func (c *Client) respondEtiquette(ctx workqueue.Context, m handler.Messenger, r handler.Responder, prompt, msg string) {
+	if !c.showEtiquette(ctx, m, prompt) {
+		return
+	}
+
+	if err := r.RespondEphemeral(ctx, msg); err != nil {
+		ctx.Logger().Error().
+			Err(err).
+			Msg("failed to respond with ephemeral Go Playground etiquette")
+
+		return
+	}
+
+	if err := c.etiquette.RecordEtiquette(ctx, m.UserID(), prompt); err != nil {
+		ctx.Logger().Error().
+			Err(err).
+			Str("prompt", prompt).
+			Msg("failed to record etiquette prompt")
+	}
+}
+
+// GotItHandler is a handler.MessageActionFn, which stops the etiquette prompts
+// for the user.
+func (c *Client) GotItHandler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	if err := c.etiquette.DismissEtiquette(ctx, m.UserID()); err != nil {
+		return fmt.Errorf("failed to dismiss etiquette prompts: %w", err)
+	}
+
+	return r.RespondEphemeral(ctx, "Thanks! I won't remind you about sharing code with the playground anymore.")
+}
@@ -0,0 +1,28 @@
+package playground
+
+import "testing"
+
+func Test_etiquetteDue(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		count     int
+		dismissed bool
+		want      bool
+	}{
+		{name: "first", text: "func main() {}", want: true},
+		{name: "under_limit", text: "func main() {}", count: EtiquetteLimit - 1, want: true},
+		{name: "at_limit", text: "func main() {}", count: EtiquetteLimit},
+		{name: "dismissed", text: "func main() {}", dismissed: true},
+		{name: "nohelp", text: "nohelp\nfunc main() {}"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etiquetteDue(tt.text, tt.count, tt.dismissed); got != tt.want {
+				t.Fatalf("etiquetteDue() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
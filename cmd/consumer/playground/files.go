@@ -0,0 +1,142 @@
+package playground
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// fileAPI is the part of *slack.Client used to download snippets.
+type fileAPI interface {
+	GetFileInfoContext(ctx context.Context, fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error)
+	GetFile(downloadURL string, writer io.Writer) error
+}
+
+// snippetFiletypes are the Slack filetypes that can hold code. Snippets
+// created in the newer clients are plain_text, rather than text.
+var snippetFiletypes = map[string]struct{}{
+	"go":         {},
+	"text":       {},
+	"plain_text": {},
+}
+
+// maxSnippetSize is the largest file that's downloaded, which is about as much
+// as the playground accepts.
+const maxSnippetSize = 64 << 10
+
+// isSnippet returns whether the file might hold code, going by what Slack says
+// about it. Some files, like those shared from canvases, only say what they
+// hold in their mimetype.
+func isSnippet(f slackevents.File) bool {
+	if _, ok := snippetFiletypes[f.Filetype]; ok {
+		return true
+	}
+
+	return len(f.Filetype) == 0 && strings.HasPrefix(f.Mimetype, "text/plain")
+}
+
+// HasSnippet returns whether any of the files might hold code, or other text.
+func HasSnippet(files []slackevents.File) bool {
+	for _, f := range files {
+		if isSnippet(f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var goSourceRegexp = regexp.MustCompile(`(?m)^\s*(package\s+\w+|func\s|import\s*[("])`)
+
+// looksLikeGo returns whether the text has a package clause, function, or
+// import in it, for telling code from other plain text.
+func looksLikeGo(b []byte) bool {
+	return goSourceRegexp.Match(b)
+}
+
+// isBinary returns whether the content isn't text.
+func isBinary(b []byte) bool {
+	return !utf8.Valid(b) || bytes.IndexByte(b, 0) >= 0
+}
+
+// Snippets downloads the snippets in files, of any length and whether or not
+// they look like Go, for the handlers looking for something other than code to
+// upload, like error messages. Like the uploader, it waits a second first, as
+// Slack may not be ready to serve a file just uploaded.
+func Snippets(ctx workqueue.Context, files []slackevents.File) ([][]byte, error) {
+	time.Sleep(1 * time.Second)
+
+	return downloadSnippets(ctx, ctx.Slack(), files, 0, false)
+}
+
+// downloadSnippets downloads each of the files that hold code, in order.
+// Binary files, files too large for the playground, and those with fewer than
+// minLines lines are skipped, as are files other than Go files that don't look
+// like Go if goOnly is set. Failing to download one file doesn't stop the
+// others: the error returned is for the first that failed, along with the
+// snippets that didn't.
+func downloadSnippets(ctx context.Context, api fileAPI, files []slackevents.File, minLines int, goOnly bool) ([][]byte, error) {
+	var (
+		snippets [][]byte
+		firstErr error
+	)
+
+	for _, f := range files {
+		if !isSnippet(f) {
+			continue
+		}
+
+		b, err := downloadSnippet(ctx, api, f.ID)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			continue
+		}
+
+		switch {
+		case b == nil, isBinary(b):
+			continue
+
+		case bytes.Count(bytes.TrimRight(b, "\n"), []byte("\n"))+1 < minLines:
+			continue
+
+		case goOnly && f.Filetype != "go" && !looksLikeGo(b):
+			continue
+		}
+
+		snippets = append(snippets, b)
+	}
+
+	return snippets, firstErr
+}
+
+// downloadSnippet downloads the file, or returns nil if it's too large or can't
+// be downloaded from Slack.
+func downloadSnippet(ctx context.Context, api fileAPI, fileID string) ([]byte, error) {
+	i, _, _, err := api.GetFileInfoContext(ctx, fileID, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info for %s: %w", fileID, err)
+	}
+
+	if i.IsExternal || len(i.URLPrivateDownload) == 0 || i.Size > maxSnippetSize {
+		return nil, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := api.GetFile(i.URLPrivateDownload, buf); err != nil {
+		return nil, fmt.Errorf("failed to get file %s: %w", fileID, err)
+	}
+
+	return buf.Bytes(), nil
+}
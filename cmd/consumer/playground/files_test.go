@@ -0,0 +1,200 @@
+package playground
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	goSnippet    = "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n"
+	proseSnippet = "one\ntwo\nthree\nfour\nfive\nsix\nseven\n"
+)
+
+// fakeFile is a file served by newFileServer.
+type fakeFile struct {
+	content  string
+	external bool
+	missing  bool
+}
+
+// newFileServer returns a fake Slack API, serving files.info and the files'
+// downloads.
+func newFileServer(t *testing.T, files map[string]fakeFile) (*slack.Client, func()) {
+	t.Helper()
+
+	var srv *httptest.Server
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/files.info", func(w http.ResponseWriter, r *http.Request) {
+		id := r.FormValue("file")
+
+		f, ok := files[id]
+
+		resp := map[string]interface{}{"ok": ok && !f.missing}
+
+		if ok && !f.missing {
+			resp["file"] = map[string]interface{}{
+				"id":                   id,
+				"size":                 len(f.content),
+				"is_external":          f.external,
+				"url_private_download": srv.URL + "/download/" + id,
+			}
+		} else {
+			resp["error"] = "file_not_found"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	mux.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
+		f, ok := files[strings.TrimPrefix(r.URL.Path, "/download/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte(f.content))
+	})
+
+	srv = httptest.NewServer(mux)
+
+	return slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/")), srv.Close
+}
+
+func Test_downloadSnippets(t *testing.T) {
+	files := map[string]fakeFile{
+		"F_GO":       {content: goSnippet},
+		"F_TEXT":     {content: goSnippet + "// text\n"},
+		"F_PLAIN":    {content: goSnippet + "// plain_text\n"},
+		"F_PROSE":    {content: proseSnippet},
+		"F_SHORT":    {content: "package main\n"},
+		"F_BINARY":   {content: "package main\x00\x01\x02\n\n\n\n\n\n"},
+		"F_INVALID":  {content: "\xff\xfe" + goSnippet},
+		"F_LARGE":    {content: strings.Repeat("x\n", maxSnippetSize)},
+		"F_EXTERNAL": {content: goSnippet, external: true},
+		"F_MISSING":  {missing: true},
+	}
+
+	api, done := newFileServer(t, files)
+	defer done()
+
+	tests := []struct {
+		name     string
+		files    []slackevents.File
+		minLines int
+		goOnly   bool
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "go",
+			files:    []slackevents.File{{ID: "F_GO", Filetype: "go"}},
+			minLines: 6,
+			goOnly:   true,
+			want:     []string{goSnippet},
+		},
+		{
+			name: "multiple",
+			files: []slackevents.File{
+				{ID: "F_GO", Filetype: "go"},
+				{ID: "F_TEXT", Filetype: "text"},
+				{ID: "F_PLAIN", Filetype: "plain_text"},
+			},
+			minLines: 6,
+			goOnly:   true,
+			want:     []string{goSnippet, files["F_TEXT"].content, files["F_PLAIN"].content},
+		},
+		{
+			name:  "mimetype",
+			files: []slackevents.File{{ID: "F_GO", Mimetype: "text/plain; charset=utf-8"}},
+			want:  []string{goSnippet},
+		},
+		{
+			name: "not_snippets",
+			files: []slackevents.File{
+				{ID: "F_GO", Filetype: "png", Mimetype: "image/png"},
+				{ID: "F_GO", Filetype: "pdf"},
+			},
+		},
+		{
+			name: "binary",
+			files: []slackevents.File{
+				{ID: "F_BINARY", Filetype: "text"},
+				{ID: "F_INVALID", Filetype: "go"},
+				{ID: "F_GO", Filetype: "go"},
+			},
+			want: []string{goSnippet},
+		},
+		{
+			name: "too_large_or_external",
+			files: []slackevents.File{
+				{ID: "F_LARGE", Filetype: "text"},
+				{ID: "F_EXTERNAL", Filetype: "go"},
+			},
+		},
+		{
+			name: "too_short",
+			files: []slackevents.File{
+				{ID: "F_SHORT", Filetype: "go"},
+				{ID: "F_GO", Filetype: "go"},
+			},
+			minLines: 6,
+			want:     []string{goSnippet},
+		},
+		{
+			name: "not_go",
+			files: []slackevents.File{
+				{ID: "F_PROSE", Filetype: "text"},
+			},
+			minLines: 6,
+			goOnly:   true,
+		},
+		{
+			name: "prose_allowed",
+			files: []slackevents.File{
+				{ID: "F_PROSE", Filetype: "text"},
+			},
+			minLines: 6,
+			want:     []string{proseSnippet},
+		},
+		{
+			name: "failed_file",
+			files: []slackevents.File{
+				{ID: "F_MISSING", Filetype: "go"},
+				{ID: "F_GO", Filetype: "go"},
+			},
+			want:    []string{goSnippet},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := downloadSnippets(context.Background(), api, tt.files, tt.minLines, tt.goOnly)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("downloadSnippets() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			var gotStrs []string
+
+			for _, b := range got {
+				gotStrs = append(gotStrs, string(b))
+			}
+
+			if diff := cmp.Diff(tt.want, gotStrs); diff != "" {
+				t.Fatalf("downloadSnippets() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
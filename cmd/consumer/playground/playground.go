@@ -17,132 +17,184 @@ import (
 	"github.com/gobridge/gopherbot/mparser"
 	"github.com/gobridge/gopherbot/workqueue"
 	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
 )
 
+// OptOutStore is the interface to keep track of the users who don't want their
+// messages automatically uploaded to the playground.
+type OptOutStore interface {
+	OptedOut(ctx context.Context, userID string) (bool, error)
+	SetOptedOut(ctx context.Context, userID string, optedOut bool) error
+}
+
 // Client is the Go Playground client.
 type Client struct {
 	httpc     *http.Client
 	logger    zerolog.Logger
-	blacklist map[string]struct{}
+	blacklist *blacklist
+	optOuts   OptOutStore
+	etiquette EtiquetteStore
 }
 
 // New takes an HTTP client and returns a Playground Client. If httpc is nil
-// this program will probably panic at some point.
-func New(httpc *http.Client, logger zerolog.Logger, channelBlacklist []string) *Client {
-	m := make(map[string]struct{}, len(channelBlacklist))
-
-	for _, cid := range channelBlacklist {
-		m[cid] = struct{}{}
-	}
-
+// this program will probably panic at some point. The channelBlacklist is the
+// default list of channels the uploader won't operate in, which admins can
+// override using the channels store. The etiquette store caps how often each
+// user is shown the etiquette prompts.
+func New(httpc *http.Client, logger zerolog.Logger, channelBlacklist []string, channels ChannelStore, optOuts OptOutStore, etiquette EtiquetteStore) *Client {
 	return &Client{
 		httpc:     httpc,
 		logger:    logger,
-		blacklist: m,
+		blacklist: newBlacklist(channelBlacklist, channels, logger),
+		optOuts:   optOuts,
+		etiquette: etiquette,
 	}
 }
 
-// Handler is a handler.ActionFn.
+// Handler is a handler.ActionFn. The code in any attached snippets is uploaded,
+// or if there aren't any, the code in the message.
 func (c *Client) Handler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
-	for _, file := range m.Files() {
-		if file.Filetype == "go" || file.Filetype == "text" {
-			return c.pgForFiles(ctx, m, r)
-		}
+	if HasSnippet(m.Files()) {
+		return c.pgForFiles(ctx, m, r)
 	}
 
 	return c.pgForMessage(ctx, m, r)
 }
 
-func (c *Client) pgForMessage(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+// aboveCodeFormat introduces the link for code in a message, or an uploaded
+// file, which the link is posted below.
+const aboveCodeFormat = "The above code from %s in the playground"
 
-	link, err := c.upload(ctx, messageToPlayground(m.Text()))
-	if err != nil {
-		return fmt.Errorf("failed to upload to playground: %w", err)
+// diagnoseTimeout is how long we wait for gofmt / go vet results, before
+// giving up and responding without them.
+const diagnoseTimeout = 4 * time.Second
+
+// codeFrom returns the introduction to the link for code the user posted, to
+// go in front of the link by respondWithLink. The userID is empty for code
+// posted by an integration, like a webhook, rather than a member.
+func codeFrom(format, userID string) string {
+	if len(userID) == 0 {
+		return fmt.Sprintf(format, "an integration")
 	}
 
 	mention := mparser.Mention{
 		Type: mparser.TypeUser,
-		ID:   m.UserID(),
+		ID:   userID,
 	}
 
-	msg := fmt.Sprintf("The above code from %s in the playground: <%s>", mention.String(), link)
+	return fmt.Sprintf(format, mention.String())
+}
 
-	err = r.Respond(ctx, msg)
+// respondWithLink uploads the code to the playground and responds with the
+// link following intro, attaching a short diagnostics summary if gofmt or go
+// vet found anything worth pointing out.
+func (c *Client) respondWithLink(ctx workqueue.Context, intro string, r handler.Responder, code []byte) error {
+	link, err := c.upload(ctx, bytes.NewReader(code))
 	if err != nil {
-		return fmt.Errorf("failed to send message with Playground link: %w", err)
+		return fmt.Errorf("failed to upload to playground: %w", err)
 	}
 
-	err = r.RespondEphemeral(ctx, `I've noticed you've written a large block of text (more than 9 lines). `+
-		`To faciliate collaboration and make the conversation easier to follow, `+
-		`please consider using <https://go.dev/play/> to share code. If you wish to not `+
-		`link against the playground, please start the message with "nolink". Thank you!`,
-	)
+	msg := fmt.Sprintf("%s: <%s>", intro, link)
+
+	var attachments []slack.Attachment
+
+	dctx, cancel := context.WithTimeout(ctx, diagnoseTimeout)
+
+	summary, err := c.diagnose(dctx, code)
+
+	cancel()
+
 	if err != nil {
 		ctx.Logger().Error().
 			Err(err).
-			Msg("failed to respond with ephemeral Go Playground etiquette")
+			Msg("failed to get snippet diagnostics")
+	} else if len(summary) > 0 {
+		attachments = append(attachments, slack.Attachment{
+			Title:      "Snippet diagnostics",
+			Text:       summary,
+			MarkdownIn: []string{"text"},
+		})
+	}
+
+	if err = r.Respond(ctx, msg, attachments...); err != nil {
+		return fmt.Errorf("failed to send message with Playground link: %w", err)
 	}
 
 	return nil
 }
 
-func (c *Client) pgForFiles(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
-	sc := ctx.Slack()
-	files := m.Files()
+func (c *Client) pgForMessage(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	if err := c.respondWithLink(ctx, codeFrom(aboveCodeFormat, m.UserID()), r, messageToPlayground(m.Text()).Bytes()); err != nil {
+		return err
+	}
 
+	c.respondEtiquette(ctx, m, r, messagePrompt, `I've noticed you've written a large block of text (more than 9 lines). `+
+		`To faciliate collaboration and make the conversation easier to follow, `+
+		`please consider using <https://go.dev/play/> to share code. If you wish to not `+
+		`link against the playground, please start the message with "nolink", or tell me `+
+		`"playground off" to stop it for all your messages. Tell me "got it" to stop these `+
+		`reminders. Thank you!`,
+	)
+
+	return nil
+}
+
+func (c *Client) pgForFiles(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
 	// XXX(theckman): following comment and code has been copied verbatim from gopherv1
 	//
 	// Empirically, attempting to call GetFileInfoContext too quickly after a
 	// file is uploaded can cause a "file_not_found" error.
 	time.Sleep(1 * time.Second)
 
-	for _, f := range files {
-		i, _, _, err := sc.GetFileInfoContext(ctx, f.ID, 0, 0)
-		if err != nil {
-			return fmt.Errorf("failed to get file info for %s: %w", f.ID, err)
-		}
-
-		if i.Lines < 6 || i.PrettyType == "Plain Text" {
-			return nil
-		}
-
-		buf := &bytes.Buffer{}
-		err = sc.GetFile(i.URLPrivateDownload, buf)
-		if err != nil {
-			return fmt.Errorf("failed to get file %s: %w", f.ID, err)
+	snippets, err := downloadSnippets(ctx, ctx.Slack(), m.Files(), 6, true)
+	if err != nil {
+		if len(snippets) == 0 {
+			return err
 		}
 
-		link, err := c.upload(ctx, buf)
-		if err != nil {
-			return fmt.Errorf("failed to upload to playground: %w", err)
-		}
+		ctx.Logger().Warn().
+			Err(err).
+			Msg("failed to download some of the snippets")
+	}
 
-		mention := mparser.Mention{
-			Type: mparser.TypeUser,
-			ID:   m.UserID(),
-		}
+	if len(snippets) == 0 {
+		return nil
+	}
 
-		msg := fmt.Sprintf("The above code from %s in the playground: <%s>", mention.String(), link)
-		err = r.Respond(ctx, msg)
-		if err != nil {
-			return fmt.Errorf("failed to send message with Playground link: %w", err)
+	for _, code := range snippets {
+		if err := c.respondWithLink(ctx, codeFrom(aboveCodeFormat, m.UserID()), r, code); err != nil {
+			return err
 		}
 	}
 
-	err := r.RespondEphemeral(ctx, `I've noticed you uploaded a Go file. To facilitate collaboration and make `+
+	c.respondEtiquette(ctx, m, r, filePrompt, `I've noticed you uploaded a Go file. To facilitate collaboration and make `+
 		`it easier for others to share back the snippet, please consider using: `+
 		`<https://go.dev/play/>. If you wish to not link against the playground, please use `+
-		`"nolink" in the message. Thank you!`,
+		`"nolink" in the message. Tell me "got it" to stop these reminders. Thank you!`,
 	)
-	if err != nil {
-		ctx.Logger().Error().
-			Err(err).
-			Msg("failed to respond with ephemeral Go Playground etiquette")
-	}
 
 	return nil
 }
 
+// ShareCallbackID is the callback_id of the "Share to Playground" message
+// shortcut, as configured in the App's settings.
+const ShareCallbackID = "share_to_playground"
+
+// ShortcutHandler is a handler.ShortcutActionFn, which uploads the code in the
+// message the shortcut was used on to the playground. It lets anyone share a
+// message on demand, regardless of how long it is.
+func (c *Client) ShortcutHandler(ctx workqueue.Context, s handler.Shortcut, r handler.Responder) error {
+	if c.blacklist.disabled(s.ChannelID()) {
+		return r.RespondEphemeral(ctx, "Sorry, I don't share code to the playground from this channel.")
+	}
+
+	if len(strings.TrimSpace(s.MessageText())) == 0 {
+		return r.RespondEphemeral(ctx, "Sorry, that message doesn't have any text for me to share to the playground.")
+	}
+
+	return c.respondWithLink(ctx, codeFrom(aboveCodeFormat, s.MessageUserID()), r, messageToPlayground(s.MessageText()).Bytes())
+}
+
 func (c *Client) upload(ctx context.Context, body io.Reader) (link string, err error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://go.dev/_/share", body)
 	if err != nil {
@@ -174,16 +226,22 @@ func (c *Client) upload(ctx context.Context, body io.Reader) (link string, err e
 // MessageMatchFn satisfies handler.MessageMatchFn
 func (c *Client) MessageMatchFn(shadowMode bool, m handler.Messenger) bool {
 	// channel is blacklisted
-	if _, ok := c.blacklist[m.ChannelID()]; ok {
+	if c.blacklist.disabled(m.ChannelID()) {
 		c.logger.Debug().
 			Str("reason", "channel not permitted").
 			Msg("playground match skipped")
 		return false
 	}
 
+	// the runner handles these
+	if m.BotMentioned() && strings.HasPrefix(m.Text(), RunPrefix) {
+		return false
+	}
+
 	rt := m.RawText()
 
-	if strings.Contains(rt, "nolink") || (len(m.Files()) == 0 && strings.Count(rt, "\n") < 10) {
+	// attachments that aren't code, like images, don't count
+	if strings.Contains(rt, "nolink") || (!HasSnippet(m.Files()) && strings.Count(rt, "\n") < 10) {
 		return false
 	}
 
@@ -195,9 +253,59 @@ func (c *Client) MessageMatchFn(shadowMode bool, m handler.Messenger) bool {
 		return false
 	}
 
+	if c.optedOut(m.UserID()) {
+		c.logger.Debug().
+			Str("reason", "user opted out").
+			Msg("playground match skipped")
+
+		return false
+	}
+
 	return true
 }
 
+// optOutTimeout is how long MessageMatchFn waits to find out if the user has
+// opted out, before assuming they haven't.
+const optOutTimeout = 500 * time.Millisecond
+
+func (c *Client) optedOut(userID string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), optOutTimeout)
+	defer cancel()
+
+	optedOut, err := c.optOuts.OptedOut(ctx, userID)
+	if err != nil {
+		c.logger.Error().
+			Err(err).
+			Str("user_id", userID).
+			Msg("failed to check playground opt-out")
+
+		return false
+	}
+
+	return optedOut
+}
+
+// OptOutHandler is a handler.MessageActionFn, which stops the user's messages
+// from being automatically uploaded to the playground.
+func (c *Client) OptOutHandler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	if err := c.optOuts.SetOptedOut(ctx, m.UserID(), true); err != nil {
+		return fmt.Errorf("failed to opt out of playground uploads: %w", err)
+	}
+
+	return r.RespondEphemeral(ctx, "Okay, I won't upload your messages to the playground anymore. "+
+		`Tell me "playground on" if you change your mind.`,
+	)
+}
+
+// OptInHandler is a handler.MessageActionFn, which undoes OptOutHandler.
+func (c *Client) OptInHandler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	if err := c.optOuts.SetOptedOut(ctx, m.UserID(), false); err != nil {
+		return fmt.Errorf("failed to opt in to playground uploads: %w", err)
+	}
+
+	return r.RespondEphemeral(ctx, "Okay, I'll upload your long code messages to the playground again.")
+}
+
 // messageToPlayground converts the text of a post into code for the playground. It is not perfect but works most of the time.
 // Text outside of ``` quotes is converted into a comment and included in the code, everything inside of those quotes is
 // considered code and pasted as-is.
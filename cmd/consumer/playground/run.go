@@ -0,0 +1,206 @@
+package playground
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+// RunPrefix is the prefix that's intended to be used by the Runner's Handler.
+const RunPrefix = "run"
+
+// RateLimiter is the interface to describe the rate limiter the Runner uses,
+// generally satisfied by a *ratelimit.Limiter.
+type RateLimiter interface {
+	Allow(ctx context.Context, id string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// Runner executes snippets in the playground sandbox, and replies with their
+// output.
+type Runner struct {
+	c       *Client
+	limiter RateLimiter
+}
+
+// NewRunner returns a *Runner, which uses the Client for talking to the
+// playground. Each user's runs are limited by the RateLimiter.
+func NewRunner(c *Client, limiter RateLimiter) *Runner {
+	return &Runner{
+		c:       c,
+		limiter: limiter,
+	}
+}
+
+var playgroundLinkRegexp = regexp.MustCompile(`(?:go\.dev/play|play\.golang\.org)/p/([A-Za-z0-9_\-]+)`)
+
+const (
+	maxOutputLen   = 1500
+	maxOutputLines = 30
+)
+
+// Handler satisfies handler.MessageActionFn.
+func (rn *Runner) Handler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	if !m.BotMentioned() {
+		return nil
+	}
+
+	// avoid matching things like "running"
+	t := m.Text()
+	if t != RunPrefix && !strings.HasPrefix(t, RunPrefix+" ") && !strings.HasPrefix(t, RunPrefix+"\n") {
+		return nil
+	}
+
+	allowed, retryAfter, err := rn.limiter.Allow(ctx, m.UserID())
+	if err != nil {
+		return fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	if !allowed {
+		return r.RespondEphemeral(ctx, fmt.Sprintf("You're running code a bit too quickly, please try again in %s.", retryAfter.Round(time.Second)))
+	}
+
+	code, err := rn.code(ctx, m)
+	if err != nil {
+		return fmt.Errorf("failed to get code to run: %w", err)
+	}
+
+	if len(bytes.TrimSpace(code)) == 0 {
+		return r.RespondTo(ctx, "Please give me some code to run: a code block, a Go Playground link, or an attached Go file.")
+	}
+
+	cr, err := rn.c.Compile(ctx, code, false)
+	if err != nil {
+		return fmt.Errorf("failed to run code: %w", err)
+	}
+
+	if len(cr.Errors) > 0 {
+		return r.RespondTo(ctx, "The code failed to build:", codeAttachment(cr.Errors))
+	}
+
+	b := &strings.Builder{}
+
+	for _, e := range cr.Events {
+		b.WriteString(e.Message)
+	}
+
+	out := b.String()
+	if len(strings.TrimSpace(out)) == 0 {
+		out = "(no output)"
+	}
+
+	msg := "Here's the output:"
+	if cr.Status != 0 {
+		msg = fmt.Sprintf("The program exited with status %d. Here's the output:", cr.Status)
+	}
+
+	return r.RespondTo(ctx, msg, codeAttachment(out))
+}
+
+// code finds the code to run in the message, looking for a playground link,
+// then a code block, and then any attached Go files.
+func (rn *Runner) code(ctx workqueue.Context, m handler.Messenger) ([]byte, error) {
+	if match := playgroundLinkRegexp.FindStringSubmatch(m.RawText()); match != nil {
+		return rn.c.Fetch(ctx, match[1])
+	}
+
+	if parts := strings.Split(html.UnescapeString(m.Text()), "```"); len(parts) > 2 {
+		var buf bytes.Buffer
+
+		for i := 1; i < len(parts); i += 2 {
+			buf.WriteString(strings.Trim(parts[i], "\n"))
+			buf.WriteByte('\n')
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	snippets, err := downloadSnippets(ctx, ctx.Slack(), m.Files(), 0, false)
+	if len(snippets) > 0 {
+		return snippets[0], nil
+	}
+
+	return nil, err
+}
+
+// Fetch gets the source code of a shared playground snippet by its ID.
+func (c *Client) Fetch(ctx context.Context, id string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://go.dev/play/p/"+id+".go", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", "Gophers Slack Bot V2")
+
+	resp, err := c.httpc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("unexpected HTTP response status: %s", resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return body, nil
+}
+
+// sanitizeOutput makes program output safe to include in a Slack code block,
+// escaping Slack's control characters and truncating it to a sensible size.
+func sanitizeOutput(s string) string {
+	s = strings.TrimRight(s, "\n")
+
+	var truncated bool
+
+	if lines := strings.Split(s, "\n"); len(lines) > maxOutputLines {
+		s = strings.Join(lines[:maxOutputLines], "\n")
+		truncated = true
+	}
+
+	if len(s) > maxOutputLen {
+		// cut at the start of a rune, so a multi-byte character isn't split
+		n := maxOutputLen
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+
+		s = s[:n]
+		truncated = true
+	}
+
+	s = strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		"```", "` ` `",
+	).Replace(s)
+
+	if truncated {
+		s += "\n... (output truncated)"
+	}
+
+	return s
+}
+
+func codeAttachment(s string) slack.Attachment {
+	return slack.Attachment{
+		Text:       "```" + sanitizeOutput(s) + "```",
+		MarkdownIn: []string{"text"},
+	}
+}
@@ -0,0 +1,48 @@
+package playground
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_sanitizeOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "plain",
+			input: "hello, world\n",
+			want:  "hello, world",
+		},
+		{
+			name:  "escaping",
+			input: "<@U1234> & ```code```",
+			want:  "&lt;@U1234&gt; &amp; ` ` `code` ` `",
+		},
+		{
+			name:  "too_many_lines",
+			input: strings.Repeat("x\n", maxOutputLines+5),
+			want:  strings.TrimSuffix(strings.Repeat("x\n", maxOutputLines), "\n") + "\n... (output truncated)",
+		},
+		{
+			name:  "too_long",
+			input: strings.Repeat("x", maxOutputLen+5),
+			want:  strings.Repeat("x", maxOutputLen) + "\n... (output truncated)",
+		},
+		{
+			name:  "too_long_multibyte",
+			input: strings.Repeat("x", maxOutputLen-1) + "世界",
+			want:  strings.Repeat("x", maxOutputLen-1) + "\n... (output truncated)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeOutput(tt.input); got != tt.want {
+				t.Fatalf("sanitizeOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package playground
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	playgroundFmtURL     = "https://go.dev/_/fmt"
+	playgroundCompileURL = "https://go.dev/_/compile"
+)
+
+// FormatResult is the response from the playground's gofmt endpoint.
+type FormatResult struct {
+	// Body is the formatted source code.
+	Body string `json:"Body"`
+
+	// Error is set if the source code could not be parsed.
+	Error string `json:"Error"`
+}
+
+// CompileEvent is a single output event from running a program in the
+// playground sandbox.
+type CompileEvent struct {
+	// Message is the output.
+	Message string `json:"Message"`
+
+	// Kind is either "stdout" or "stderr".
+	Kind string `json:"Kind"`
+}
+
+// CompileResult is the response from the playground's compile endpoint.
+type CompileResult struct {
+	// Errors are any build errors.
+	Errors string `json:"Errors"`
+
+	// Events are the output of the program, if it built and ran.
+	Events []CompileEvent `json:"Events"`
+
+	// Status is the exit status of the program.
+	Status int `json:"Status"`
+
+	// VetErrors are any problems reported by go vet, if vet was requested.
+	VetErrors string `json:"VetErrors"`
+}
+
+// Format runs the code through the playground's gofmt endpoint.
+func (c *Client) Format(ctx context.Context, code []byte) (FormatResult, error) {
+	v := url.Values{}
+	v.Set("body", string(code))
+
+	var fr FormatResult
+
+	if err := c.postForm(ctx, playgroundFmtURL, v, &fr); err != nil {
+		return FormatResult{}, fmt.Errorf("failed to format code: %w", err)
+	}
+
+	return fr, nil
+}
+
+// Compile builds and runs the code in the playground sandbox. If vet is true,
+// go vet is also run against the code.
+func (c *Client) Compile(ctx context.Context, code []byte, vet bool) (CompileResult, error) {
+	v := url.Values{}
+	v.Set("version", "2")
+	v.Set("body", string(code))
+
+	if vet {
+		v.Set("withVet", "true")
+	}
+
+	var cr CompileResult
+
+	if err := c.postForm(ctx, playgroundCompileURL, v, &cr); err != nil {
+		return CompileResult{}, fmt.Errorf("failed to compile code: %w", err)
+	}
+
+	return cr, nil
+}
+
+func (c *Client) postForm(ctx context.Context, u string, v url.Values, i interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(v.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Add("User-Agent", "Gophers Slack Bot V2")
+
+	resp, err := c.httpc.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("unexpected HTTP response status: %s", resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if err = json.Unmarshal(body, i); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+// maxDiagnosticLen is the longest a single diagnostic block can be before it's
+// truncated.
+const maxDiagnosticLen = 500
+
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+
+	if len(s) <= max {
+		return s
+	}
+
+	return s[:max] + "\n..."
+}
+
+// diagnose runs the code through gofmt and go vet, returning a short summary
+// of any findings. If there is nothing of note, summary is empty.
+func (c *Client) diagnose(ctx context.Context, code []byte) (summary string, err error) {
+	fr, err := c.Format(ctx, code)
+	if err != nil {
+		return "", err
+	}
+
+	// if it doesn't parse, vet has nothing to add
+	if len(fr.Error) > 0 {
+		return fmt.Sprintf(":x: The code doesn't parse:\n```%s```", truncate(fr.Error, maxDiagnosticLen)), nil
+	}
+
+	var findings []string
+
+	if strings.TrimSpace(fr.Body) != strings.TrimSpace(string(code)) {
+		findings = append(findings, ":warning: The code isn't formatted with `gofmt`.")
+	}
+
+	cr, err := c.Compile(ctx, code, true)
+	if err != nil {
+		return "", err
+	}
+
+	if len(cr.Errors) > 0 {
+		findings = append(findings, fmt.Sprintf(":x: The code doesn't build:\n```%s```", truncate(cr.Errors, maxDiagnosticLen)))
+	}
+
+	if len(cr.VetErrors) > 0 {
+		findings = append(findings, fmt.Sprintf(":mag: `go vet` reported:\n```%s```", truncate(cr.VetErrors, maxDiagnosticLen)))
+	}
+
+	return strings.Join(findings, "\n"), nil
+}
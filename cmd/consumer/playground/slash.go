@@ -0,0 +1,130 @@
+package playground
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// SlashCommand is the slash command for uploading code to the playground
+	// from anywhere, as configured in the App's settings.
+	SlashCommand = "/playground"
+
+	// UploadCallbackID is the callback_id of the modal SlashCommand opens
+	// when it's invoked without any code.
+	UploadCallbackID = "playground_upload"
+
+	uploadBlockID  = "playground_code"
+	uploadActionID = "code"
+
+	// slashCodeFormat introduces the link for code given to SlashCommand,
+	// which was never posted.
+	slashCodeFormat = "Code from %s in the playground"
+)
+
+// uploadTarget is the upload modal's private_metadata, so that the submission
+// knows where the command was invoked.
+type uploadTarget struct {
+	ChannelID   string `json:"channel_id"`
+	ResponseURL string `json:"response_url"`
+}
+
+func uploadModal(ut uploadTarget) (slack.ModalViewRequest, error) {
+	md, err := json.Marshal(ut)
+	if err != nil {
+		return slack.ModalViewRequest{}, fmt.Errorf("failed to marshal upload target: %w", err)
+	}
+
+	input := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "package main", false, false),
+		uploadActionID,
+	)
+	input.Multiline = true
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      UploadCallbackID,
+		PrivateMetadata: string(md),
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, "Go Playground", false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Share", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks: slack.Blocks{
+			BlockSet: []slack.Block{
+				slack.NewInputBlock(
+					uploadBlockID,
+					slack.NewTextBlockObject(slack.PlainTextType, "Code", false, false),
+					input,
+				),
+			},
+		},
+	}, nil
+}
+
+// slashCode converts the text given to SlashCommand into code for the
+// playground. Unlike a message, text outside of ``` quotes is only made into
+// comments if there are any quotes, as otherwise it's all code.
+func slashCode(text string) []byte {
+	if strings.Contains(text, "```") {
+		return messageToPlayground(text).Bytes()
+	}
+
+	return []byte(strings.TrimSpace(html.UnescapeString(text)) + "\n")
+}
+
+// SlashCommandHandler is a handler.SlashCommandActionFn, which uploads the code
+// given with the command to the playground, or opens a modal to paste it in if
+// there isn't any. The link is posted where the command was invoked, unless
+// the uploader is disabled there.
+func (c *Client) SlashCommandHandler(ctx workqueue.Context, sc handler.SlashCommand, r handler.Responder) error {
+	if c.blacklist.disabled(sc.ChannelID()) {
+		return r.RespondEphemeral(ctx, "Sorry, I don't share code to the playground from this channel.")
+	}
+
+	if len(sc.Text()) > 0 {
+		return c.respondWithLink(ctx, codeFrom(slashCodeFormat, sc.UserID()), r, slashCode(sc.Text()))
+	}
+
+	modal, err := uploadModal(uploadTarget{
+		ChannelID:   sc.ChannelID(),
+		ResponseURL: sc.ResponseURL(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := ctx.Slack().OpenViewContext(ctx, sc.TriggerID(), modal); err != nil {
+		return fmt.Errorf("failed to open playground upload modal: %w", err)
+	}
+
+	return nil
+}
+
+// UploadHandler is a handler.ViewSubmissionActionFn, which uploads the code
+// submitted in the modal opened by SlashCommandHandler.
+func (c *Client) UploadHandler(ctx workqueue.Context, vs handler.ViewSubmission) error {
+	var ut uploadTarget
+
+	if err := json.Unmarshal([]byte(vs.PrivateMetadata()), &ut); err != nil {
+		return fmt.Errorf("failed to unmarshal upload target: %w", err)
+	}
+
+	r := handler.NewSlashCommandResponder(ctx.Slack(), ut.ChannelID, vs.UserID(), ut.ResponseURL)
+
+	// the channel could have been disabled while the modal was open
+	if c.blacklist.disabled(ut.ChannelID) {
+		return r.RespondEphemeral(ctx, "Sorry, I don't share code to the playground from this channel.")
+	}
+
+	code := vs.Value(uploadBlockID, uploadActionID)
+	if len(strings.TrimSpace(code)) == 0 {
+		return nil
+	}
+
+	return c.respondWithLink(ctx, codeFrom(slashCodeFormat, vs.UserID()), r, []byte(code))
+}
@@ -0,0 +1,35 @@
+package playground
+
+import "testing"
+
+func Test_slashCode(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "plain",
+			text: "package main\n\nfunc main() {}",
+			want: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name: "escaped",
+			text: "  fmt.Println(1 &lt; 2 &amp;&amp; true)  ",
+			want: "fmt.Println(1 < 2 && true)\n",
+		},
+		{
+			name: "quoted",
+			text: "why?\n```package main```",
+			want: "\n// why?\n\npackage main\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(slashCode(tt.text)); got != tt.want {
+				t.Fatalf("slashCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package playground
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisOptOutKey          = "playground:opt_out"
+	redisChannelsKey        = "playground:channels"
+	redisEtiquetteKeyFormat = "playground:etiquette:%s" // user
+	redisTestKey            = "playground:test_key"
+
+	// etiquetteRetention is how long after the last prompt someone's counts
+	// are kept.
+	etiquetteRetention = 365 * 24 * time.Hour
+
+	// etiquetteDismissed is the field in a user's etiquette counts that's set
+	// when they ask to stop seeing the prompts.
+	etiquetteDismissed = "dismissed"
+)
+
+// Store stores which users have opted out of playground auto-uploads, which
+// channels the uploader has been disabled or enabled in, and how often each
+// user has been shown the etiquette prompts.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// OptedOut returns whether the user has opted out.
+func (s *Store) OptedOut(ctx context.Context, userID string) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// noop
+	}
+
+	ok, err := s.r.SIsMember(ctx, redisOptOutKey, userID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to SISMEMBER redis key: %w", err)
+	}
+
+	return ok, nil
+}
+
+// SetOptedOut sets whether the user has opted out.
+func (s *Store) SetOptedOut(ctx context.Context, userID string, optedOut bool) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	var err error
+
+	if optedOut {
+		err = s.r.SAdd(ctx, redisOptOutKey, userID).Err()
+	} else {
+		err = s.r.SRem(ctx, redisOptOutKey, userID).Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update playground opt-out: %w", err)
+	}
+
+	return nil
+}
+
+// ChannelOverrides returns the channels an admin has disabled or enabled the
+// playground uploader in, mapped to whether it's disabled.
+func (s *Store) ChannelOverrides(ctx context.Context) (map[string]bool, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	res, err := s.r.HGetAll(ctx, redisChannelsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to HGETALL redis key: %w", err)
+	}
+
+	overrides := make(map[string]bool, len(res))
+
+	for channelID, v := range res {
+		overrides[channelID] = v == "1"
+	}
+
+	return overrides, nil
+}
+
+// SetChannelDisabled sets whether the playground uploader is disabled in the
+// channel.
+func (s *Store) SetChannelDisabled(ctx context.Context, channelID string, disabled bool) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	v := "0"
+	if disabled {
+		v = "1"
+	}
+
+	if err := s.r.HSet(ctx, redisChannelsKey, channelID, v).Err(); err != nil {
+		return fmt.Errorf("failed to HSET redis key: %w", err)
+	}
+
+	return nil
+}
+
+// EtiquetteSeen returns how many times the user has been shown the prompt, and
+// whether they've asked to stop seeing the prompts.
+func (s *Store) EtiquetteSeen(ctx context.Context, userID, prompt string) (int, bool, error) {
+	select {
+	case <-ctx.Done():
+		return 0, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	vals, err := s.r.HMGet(ctx, fmt.Sprintf(redisEtiquetteKeyFormat, userID), prompt, etiquetteDismissed).Result()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to HMGET redis key: %w", err)
+	}
+
+	var count int
+
+	if v, ok := vals[0].(string); ok {
+		if count, err = strconv.Atoi(v); err != nil {
+			return 0, false, fmt.Errorf("failed to parse etiquette count %q: %w", v, err)
+		}
+	}
+
+	return count, vals[1] != nil, nil
+}
+
+// RecordEtiquette records the user being shown the prompt once more.
+func (s *Store) RecordEtiquette(ctx context.Context, userID, prompt string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	key := fmt.Sprintf(redisEtiquetteKeyFormat, userID)
+
+	pipe := s.r.TxPipeline()
+	pipe.HIncrBy(ctx, key, prompt, 1)
+	pipe.Expire(ctx, key, etiquetteRetention)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record etiquette prompt: %w", err)
+	}
+
+	return nil
+}
+
+// DismissEtiquette stops the prompts for the user.
+func (s *Store) DismissEtiquette(ctx context.Context, userID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	key := fmt.Sprintf(redisEtiquetteKeyFormat, userID)
+
+	pipe := s.r.TxPipeline()
+	pipe.HSet(ctx, key, etiquetteDismissed, "1")
+	pipe.Expire(ctx, key, etiquetteRetention)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to dismiss etiquette prompts: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeUser deletes whether the user opted out and how often they were shown
+// the etiquette prompts, returning how many of those were recorded. If dryRun
+// is true, they're counted but not deleted. It satisfies purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	key := fmt.Sprintf(redisEtiquetteKeyFormat, userID)
+
+	pipe := s.r.Pipeline()
+	optedOut := pipe.SIsMember(ctx, redisOptOutKey, userID)
+	etiquette := pipe.Exists(ctx, key)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to look up %s: %w", userID, err)
+	}
+
+	n := int(etiquette.Val())
+
+	if optedOut.Val() {
+		n++
+	}
+
+	if n == 0 || dryRun {
+		return n, nil
+	}
+
+	tx := s.r.TxPipeline()
+	tx.SRem(ctx, redisOptOutKey, userID)
+	tx.Del(ctx, key)
+
+	if _, err := tx.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to purge %s: %w", userID, err)
+	}
+
+	return n, nil
+}
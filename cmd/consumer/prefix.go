@@ -7,15 +7,9 @@ import (
 
 	"github.com/gobridge/gopherbot/handler"
 	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/gobridge/gopherbot/xkcd"
 )
 
-var xkcdAliases = map[string]uint64{
-	"compiling":    303,
-	"ballmer":      323,
-	"standards":    927,
-	"optimization": 1691,
-}
-
 func injectMessageResponsePrefix(ma *handler.MessageActions) {
 	ma.HandlePrefix("xkcd:", "helpfully give you the XKCD link you want",
 		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
@@ -32,14 +26,14 @@ func injectMessageResponsePrefix(ma *handler.MessageActions) {
 
 			idStr := parts[1][:i]
 
-			comicID, ok := xkcdAliases[idStr]
+			comicID, ok := xkcd.Aliases[idStr]
 			if !ok {
 				u64, err := strconv.ParseUint(idStr, 10, 64)
 				if err != nil {
 					return r.RespondMentions(ctx, "That was almost right. Proper format is `xkcd:1234`")
 				}
 
-				comicID = u64
+				comicID = int(u64)
 			}
 
 			return r.RespondMentionsUnfurled(ctx, fmt.Sprintf("https://xkcd.com/%d", comicID))
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/cmd/consumer/playground"
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/prefs"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const setPrefUsage = "Usage: `set pref <name>=<value>`, like `set pref tz=Europe/Berlin`. Use `prefs` to see your preferences, and which you can set."
+
+// injectPrefsHandlers registers the commands for users to see and set their
+// preferences. The playground preference goes through optOuts, so that it
+// agrees with `playground off` and `playground on`.
+func injectPrefsHandlers(ma *handler.MessageActions, ps *prefs.Store, optOuts playground.OptOutStore) {
+	ma.HandleCommand("set", setPrefUsage, "set one of your preferences, like `set pref playground=off`",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			// other set commands may come along, and "set" starts plenty
+			// of messages not meant for us
+			if !strings.EqualFold(c.Arg(0), "pref") || (!m.BotMentioned() && m.ChannelType() != handler.ChannelDM) {
+				return nil
+			}
+
+			if len(c.Args) != 2 {
+				return &handler.UsageError{}
+			}
+
+			p, v, err := prefs.ParseAssignment(c.Arg(1))
+			if err != nil {
+				return handler.Usagef("%s", err)
+			}
+
+			if p.Name == prefs.Playground {
+				err = optOuts.SetOptedOut(ctx, m.UserID(), v == "off")
+			} else {
+				err = ps.Set(ctx, m.UserID(), p.Name, v)
+			}
+
+			if err != nil {
+				return fmt.Errorf("failed to set preference %s: %w", p.Name, err)
+			}
+
+			return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, your `%s` preference is now `%s`.", p.Name, v))
+		},
+	)
+
+	ma.Handle("prefs", "list your preferences", []string{"preferences"},
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			vs, err := ps.All(ctx, m.UserID())
+			if err != nil {
+				return fmt.Errorf("failed to get preferences: %w", err)
+			}
+
+			var b strings.Builder
+
+			b.WriteString("Your preferences, which you can change with `set pref <name>=<value>`:\n")
+
+			for _, v := range vs {
+				if v.Name == prefs.Playground {
+					optedOut, err := optOuts.OptedOut(ctx, m.UserID())
+					if err != nil {
+						return fmt.Errorf("failed to get playground opt-out: %w", err)
+					}
+
+					v.Value = "on"
+					if optedOut {
+						v.Value = "off"
+					}
+				}
+
+				fmt.Fprintf(&b, "- `%s=%s`: %s", v.Name, v.Value, v.Description)
+
+				if !v.Set {
+					b.WriteString(" _(default)_")
+				}
+
+				b.WriteString("\n")
+			}
+
+			return r.RespondEphemeral(ctx, b.String())
+		},
+	)
+}
+
+// playgroundOptOuts is the playground.OptOutStore kept in the playground
+// preference. It still honors the opt-outs made before there were preferences,
+// in legacy, clearing them if the user opts back in.
+type playgroundOptOuts struct {
+	prefs  *prefs.Store
+	legacy playground.OptOutStore
+}
+
+func (o playgroundOptOuts) OptedOut(ctx context.Context, userID string) (bool, error) {
+	enabled, err := o.prefs.Enabled(ctx, userID, prefs.Playground)
+	if err != nil {
+		return false, err
+	}
+
+	if !enabled {
+		return true, nil
+	}
+
+	return o.legacy.OptedOut(ctx, userID)
+}
+
+func (o playgroundOptOuts) SetOptedOut(ctx context.Context, userID string, optedOut bool) error {
+	v := "on"
+	if optedOut {
+		v = "off"
+	}
+
+	if err := o.prefs.Set(ctx, userID, prefs.Playground, v); err != nil {
+		return err
+	}
+
+	if optedOut {
+		return nil
+	}
+
+	return o.legacy.SetOptedOut(ctx, userID, false)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/purge"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const purgeUsage = "Usage: `purge user <id>` shows what I store about a member, like their preferences, join tracking, and the audit log entries naming them. " +
+	"Check it, then run `purge user <id> --confirm` to delete it all, such as when a deactivated member asks to be removed."
+
+// memberStores are the stores holding data about members.
+type memberStores struct {
+	prefs      purge.Store
+	language   purge.Store
+	playground purge.Store
+	joins      purge.Store
+	followUps  purge.Store
+	growth     purge.Store
+	moderation purge.Store
+	modmail    purge.Store
+	workshops  purge.Store
+	faq        purge.Store
+	undo       purge.Store
+	threading  purge.Store
+	crosspost  purge.Store
+	users      purge.Store
+	polls      purge.Store
+	scheduled  purge.Store
+	kb         purge.Store
+	audit      purge.Store
+}
+
+// newPurger returns a *purge.Purger for the stores, each named for what it
+// holds in the report.
+func newPurger(s memberStores) *purge.Purger {
+	p := purge.New()
+	p.Add("preferences", s.prefs)
+	p.Add("language", s.language)
+	p.Add("playground settings", s.playground)
+	p.Add("join tracking", s.joins)
+	p.Add("new member follow-ups", s.followUps)
+	p.Add("join and activity counts", s.growth)
+	p.Add("moderation decisions", s.moderation)
+	p.Add("mod mail conversations", s.modmail)
+	p.Add("workshop submissions", s.workshops)
+	p.Add("FAQ feedback", s.faq)
+	p.Add("undoable responses", s.undo)
+	p.Add("follow-up threads", s.threading)
+	p.Add("cross-post fingerprints", s.crosspost)
+	p.Add("cached profile", s.users)
+	p.Add("polls", s.polls)
+	p.Add("scheduled posts they created", s.scheduled)
+	p.Add("knowledge base entries they wrote", s.kb)
+	p.Add("audit log", s.audit)
+
+	return p
+}
+
+// injectPurgeHandlers registers the command Workspace Admins use to delete
+// everything the bot stores about a member, after a dry run.
+func injectPurgeHandlers(ma *handler.MessageActions, p *purge.Purger) {
+	ma.HandleCommand("purge", purgeUsage, "(admins only) `purge user <id>` deletes what I store about a member",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can purge members' data.")
+			}
+
+			if len(c.Args) != 2 || !strings.EqualFold(c.Arg(0), "user") {
+				return &handler.UsageError{}
+			}
+
+			userID := purgeUserID(m, c.Arg(1))
+			if !purge.ValidUserID(userID) {
+				return handler.Usagef("`%s` isn't a user ID, like U012AB3CD", c.Arg(1))
+			}
+
+			dryRun := !c.HasFlag("confirm")
+
+			report, err := p.Purge(ctx, userID, dryRun)
+
+			ctx.Logger().Info().
+				Str("user_id", m.UserID()).
+				Str("purged_user_id", userID).
+				Bool("dry_run", dryRun).
+				Int("records", report.Total()).
+				Bool("failed", err != nil).
+				Msg("purged user data")
+
+			if err != nil && len(report.Results) == 0 {
+				return err
+			}
+
+			return r.RespondEphemeral(ctx, formatPurgeReport(report))
+		},
+	)
+}
+
+// purgeUserID returns the ID of the member mentioned, or the argument if
+// nobody was, as deactivated members can't always be mentioned.
+func purgeUserID(m handler.Messenger, arg string) string {
+	for _, mention := range m.AllMentions() {
+		if mention.Type == mparser.TypeUser {
+			return mention.ID
+		}
+	}
+
+	return strings.ToUpper(strings.Trim(arg, "<@>"))
+}
+
+// formatPurgeReport describes what was found about the member, and whether it
+// was deleted.
+func formatPurgeReport(r purge.Report) string {
+	var b strings.Builder
+
+	switch {
+	case r.Total() == 0 && len(r.Failed()) == 0:
+		return fmt.Sprintf("I don't store anything about `%s`.", r.UserID)
+	case r.DryRun:
+		fmt.Fprintf(&b, "Here's what I store about `%s`:\n", r.UserID)
+	default:
+		fmt.Fprintf(&b, "I deleted what I stored about `%s`:\n", r.UserID)
+	}
+
+	for _, res := range r.Results {
+		switch {
+		case res.Err != nil:
+			fmt.Fprintf(&b, "- %s: failed (%v)\n", res.Name, res.Err)
+		case res.Count > 0:
+			fmt.Fprintf(&b, "- %s: %d\n", res.Name, res.Count)
+		}
+	}
+
+	switch {
+	case len(r.Failed()) > 0 && r.DryRun:
+		fmt.Fprintf(&b, "I couldn't look everywhere. Run `purge user %s` again to retry.", r.UserID)
+	case len(r.Failed()) > 0:
+		fmt.Fprintf(&b, "Not everything could be deleted. Run `purge user %s --confirm` again to retry.", r.UserID)
+	case r.DryRun:
+		fmt.Fprintf(&b, "Run `purge user %s --confirm` to delete it all. Messages they sent are in Slack, and aren't deleted.", r.UserID)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
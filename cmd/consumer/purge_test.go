@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gobridge/gopherbot/internal/purge"
+	"github.com/google/go-cmp/cmp"
+)
+
+// oneRecord is a store holding one record about every member.
+type oneRecord struct{}
+
+func (oneRecord) PurgeUser(context.Context, string, bool) (int, error) { return 1, nil }
+
+func TestNewPurger(t *testing.T) {
+	var s oneRecord
+
+	p := newPurger(memberStores{
+		prefs: s, language: s, playground: s, joins: s, followUps: s, growth: s,
+		moderation: s, modmail: s, workshops: s, faq: s, undo: s, threading: s,
+		crosspost: s, users: s, polls: s, scheduled: s, kb: s, audit: s,
+	})
+
+	report, err := p.Purge(context.Background(), "U012AB3CD", true)
+	if err != nil {
+		t.Fatalf("Purge() unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, res := range report.Results {
+		got = append(got, res.Name)
+	}
+
+	want := []string{
+		"preferences", "language", "playground settings", "join tracking",
+		"new member follow-ups", "join and activity counts", "moderation decisions",
+		"mod mail conversations", "workshop submissions", "FAQ feedback",
+		"undoable responses", "follow-up threads", "cross-post fingerprints",
+		"cached profile", "polls", "scheduled posts they created",
+		"knowledge base entries they wrote", "audit log",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("report stores mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormatPurgeReport(t *testing.T) {
+	results := []purge.Result{
+		{Name: "preferences", Count: 2},
+		{Name: "language"},
+		{Name: "audit log", Count: 5},
+	}
+
+	tests := []struct {
+		name   string
+		report purge.Report
+		want   string
+	}{
+		{
+			name:   "nothing",
+			report: purge.Report{UserID: "U012AB3CD", DryRun: true, Results: []purge.Result{{Name: "preferences"}}},
+			want:   "I don't store anything about `U012AB3CD`.",
+		},
+		{
+			name:   "dry_run",
+			report: purge.Report{UserID: "U012AB3CD", DryRun: true, Results: results},
+			want: "Here's what I store about `U012AB3CD`:\n- preferences: 2\n- audit log: 5\n" +
+				"Run `purge user U012AB3CD --confirm` to delete it all. Messages they sent are in Slack, and aren't deleted.",
+		},
+		{
+			name:   "deleted",
+			report: purge.Report{UserID: "U012AB3CD", Results: results},
+			want:   "I deleted what I stored about `U012AB3CD`:\n- preferences: 2\n- audit log: 5",
+		},
+		{
+			name: "failed",
+			report: purge.Report{UserID: "U012AB3CD", Results: []purge.Result{
+				{Name: "preferences", Count: 2},
+				{Name: "mod mail conversations", Err: errors.New("redis down")},
+			}},
+			want: "I deleted what I stored about `U012AB3CD`:\n- preferences: 2\n- mod mail conversations: failed (redis down)\n" +
+				"Not everything could be deleted. Run `purge user U012AB3CD --confirm` again to retry.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatPurgeReport(tt.report); got != tt.want {
+				t.Errorf("formatPurgeReport() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/rng"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const (
+	rollUsage = "Usage: `roll <dice>`, like `roll 2d6` for two six-sided dice, or `roll d20`."
+	pickUsage = "Usage: `pick <choices>`, like `pick tabs, spaces` or `pick vim or emacs`."
+)
+
+// injectRandomHandlers registers the dice and picking commands, which get
+// their randomness from rnd.
+func injectRandomHandlers(ma *handler.MessageActions, rnd rng.RNG) {
+	ma.HandleCommand("roll", rollUsage, "roll some dice, like `roll 2d6`",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			if !m.BotMentioned() && m.ChannelType() != handler.ChannelDM {
+				return nil
+			}
+
+			if len(c.Args) != 1 {
+				return &handler.UsageError{}
+			}
+
+			d, err := rng.ParseDice(c.Arg(0))
+			if err != nil {
+				return handler.Usagef("%s", err)
+			}
+
+			return r.RespondTo(ctx, rollResult(d, d.Roll(rnd)))
+		},
+	)
+
+	ma.HandleCommand("pick", pickUsage, "pick one of some choices, like `pick tabs, spaces`",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			if !m.BotMentioned() && m.ChannelType() != handler.ChannelDM {
+				return nil
+			}
+
+			choices := rng.ParseChoices(strings.Join(c.Args, " "))
+			if len(choices) < 2 {
+				return handler.Usagef("I need at least two choices to pick from")
+			}
+
+			return r.RespondTo(ctx, fmt.Sprintf("I pick: %s", choices[rnd.Intn(len(choices))]))
+		},
+	)
+}
+
+// rollResult describes the rolls, with their total if there's more than one.
+func rollResult(d rng.Dice, rolls []int) string {
+	if len(rolls) == 1 {
+		return fmt.Sprintf(":game_die: %s: %d", d, rolls[0])
+	}
+
+	strs := make([]string, len(rolls))
+
+	var total int
+
+	for i, n := range rolls {
+		strs[i] = strconv.Itoa(n)
+		total += n
+	}
+
+	return fmt.Sprintf(":game_die: %s: %s = %d", d, strings.Join(strs, " + "), total)
+}
@@ -3,12 +3,12 @@ package main
 import "github.com/gobridge/gopherbot/handler"
 
 func injectMessageReactions(r *handler.MessageActions) {
-	r.HandleReaction("bbq", "bbqgopher")
+	r.HandleReaction("bbq", "bbqgopher|meat_on_bone")
 	r.HandleReaction("ghost", "ghost")
 	r.HandleReaction("spacex", "rocket")
-	r.HandleReaction("buffalo", "gobuffalo")
-	r.HandleReaction("gobuffalo", "gobuffalo")
-	r.HandleReaction("spacemacs", "spacemacs")
+	r.HandleReaction("buffalo", "gobuffalo|water_buffalo")
+	r.HandleReaction("gobuffalo", "gobuffalo|water_buffalo")
+	r.HandleReaction("spacemacs", "spacemacs|rocket")
 	r.HandleReaction("my adorable little gophers", "gopher")
 
 	r.HandleReaction("dragon", "dragon")
@@ -16,13 +16,14 @@ func injectMessageReactions(r *handler.MessageActions) {
 	r.HandleReaction("ermergerd", "dragon")
 	r.HandleReaction("ermahgerd", "dragon")
 
-	r.HandleReaction("rubberduck", "rubberduck")
-	r.HandleReaction("rubber duck", "rubberduck")
-	r.HandleReaction("rubber-duck", "rubberduck")
+	r.HandleReaction("rubberduck", "rubberduck|duck")
+	r.HandleReaction("rubber duck", "rubberduck|duck")
+	r.HandleReaction("rubber-duck", "rubberduck|duck")
 
 	r.HandleReaction("beer me", "beer", "beers")
 
 	r.HandleMentionedReaction("thank", "gopher")
+	r.HandleMentionedReaction("thanks", "gopher")
 	r.HandleMentionedReaction("cheers", "gopher")
 	r.HandleMentionedReaction("hello", "gopher")
 	r.HandleMentionedReaction("wave", "wave", "gopher")
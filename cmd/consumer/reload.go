@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+)
+
+// reloadFunc refreshes some cached configuration, describing what changed.
+type reloadFunc func(ctx context.Context) (changes []string, err error)
+
+type reloadSource struct {
+	name string
+	fn   reloadFunc
+}
+
+// reloader refreshes the Redis-backed configuration the consumer caches, so
+// that changes made by other consumers apply immediately instead of when the
+// caches expire. The environment is only read on startup, so changing it
+// still needs a restart.
+type reloader struct {
+	mu      sync.Mutex
+	sources []reloadSource
+}
+
+func (r *reloader) add(name string, fn reloadFunc) {
+	r.sources = append(r.sources, reloadSource{name: name, fn: fn})
+}
+
+// reload refreshes each source, even if refreshing an earlier one failed, and
+// returns a summary of what changed.
+func (r *reloader) reload(ctx context.Context, logger zerolog.Logger) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+
+	for _, s := range r.sources {
+		changes, err := s.fn(ctx)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str("source", s.name).
+				Msg("failed to reload")
+
+			fmt.Fprintf(&b, "*%s*: failed to reload: %s\n", s.name, err)
+
+			continue
+		}
+
+		logger.Info().
+			Str("source", s.name).
+			Strs("changes", changes).
+			Msg("reloaded")
+
+		if len(changes) == 0 {
+			fmt.Fprintf(&b, "*%s*: no changes\n", s.name)
+			continue
+		}
+
+		fmt.Fprintf(&b, "*%s*:\n", s.name)
+
+		for _, c := range changes {
+			fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+
+	return b.String()
+}
+
+// injectReloadHandlers registers the reload command, which lets Workspace
+// Admins refresh the consumer's cached configuration.
+func injectReloadHandlers(ma *handler.MessageActions, rl *reloader) {
+	ma.Handle("reload", "(admins only) reload the feature flags and channel settings", nil,
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can reload my configuration.")
+			}
+
+			summary := rl.reload(ctx, *ctx.Logger())
+
+			return r.RespondEphemeral(ctx, "Reloaded this consumer's configuration, others pick up changes within a minute:\n"+summary)
+		},
+	)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// reportCallbackID is the callback_id of both the "Report to moderators"
+	// message shortcut, as configured in the App's settings, and the modal
+	// it opens.
+	reportCallbackID = "report_message"
+
+	reportBlockID  = "report_reason"
+	reportActionID = "reason"
+)
+
+// reportedMessage is the modal's private_metadata, so that the submission
+// knows which message was reported.
+type reportedMessage struct {
+	ChannelID string `json:"channel_id"`
+	MessageTS string `json:"message_ts"`
+	UserID    string `json:"user_id"`
+}
+
+func reportModal(rm reportedMessage) (slack.ModalViewRequest, error) {
+	md, err := json.Marshal(rm)
+	if err != nil {
+		return slack.ModalViewRequest{}, fmt.Errorf("failed to marshal reported message: %w", err)
+	}
+
+	input := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "What's wrong with this message?", false, false),
+		reportActionID,
+	)
+	input.Multiline = true
+	input.MaxLength = 2000
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      reportCallbackID,
+		PrivateMetadata: string(md),
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, "Report to moderators", false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Send", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks: slack.Blocks{
+			BlockSet: []slack.Block{
+				slack.NewInputBlock(
+					reportBlockID,
+					slack.NewTextBlockObject(slack.PlainTextType, "Description", false, false),
+					input,
+				),
+				slack.NewContextBlock("",
+					slack.NewTextBlockObject(slack.MarkdownType, "Only the moderators will see this report, including who sent it.", false, false),
+				),
+			},
+		},
+	}, nil
+}
+
+func reportMessage(reporterID, authorID, channelID, link, reason string) string {
+	quoted := "> " + strings.ReplaceAll(strings.TrimSpace(reason), "\n", "\n> ")
+
+	return fmt.Sprintf(":rotating_light: <@%s> reported a message from <@%s> in <#%s>: %s\n%s",
+		reporterID, authorID, channelID, link, quoted,
+	)
+}
+
+// injectReportHandlers registers the "Report to moderators" message shortcut,
+// which opens a modal for the reporter to describe the problem. Submissions
+// are only sent to the moderators, so the reporter isn't revealed to anyone
+// else.
+func injectReportHandlers(ia *handler.InteractionActions, nr *notify.Router) {
+	ia.HandleShortcut("report_message", reportCallbackID,
+		func(ctx workqueue.Context, s handler.Shortcut, _ handler.Responder) error {
+			modal, err := reportModal(reportedMessage{
+				ChannelID: s.ChannelID(),
+				MessageTS: s.MessageTS(),
+				UserID:    s.MessageUserID(),
+			})
+			if err != nil {
+				return err
+			}
+
+			if _, err := ctx.Slack().OpenViewContext(ctx, s.TriggerID(), modal); err != nil {
+				return fmt.Errorf("failed to open report modal: %w", err)
+			}
+
+			return nil
+		},
+	)
+
+	ia.HandleViewSubmission("report_message", reportCallbackID,
+		func(ctx workqueue.Context, vs handler.ViewSubmission) error {
+			var rm reportedMessage
+
+			if err := json.Unmarshal([]byte(vs.PrivateMetadata()), &rm); err != nil {
+				return fmt.Errorf("failed to unmarshal reported message: %w", err)
+			}
+
+			link, err := ctx.Slack().GetPermalinkContext(ctx, &slack.PermalinkParameters{
+				Channel: rm.ChannelID,
+				Ts:      rm.MessageTS,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to get permalink for channel %s ts %s: %w", rm.ChannelID, rm.MessageTS, err)
+			}
+
+			_, err = nr.Notify(ctx, notify.Notification{
+				Source:   notify.Moderation,
+				Severity: notify.Important,
+				Summary:  fmt.Sprintf("message %s:%s reported", rm.ChannelID, rm.MessageTS),
+				Options: []slack.MsgOption{
+					slack.MsgOptionText(reportMessage(vs.UserID(), rm.UserID, rm.ChannelID, link, vs.Value(reportBlockID, reportActionID)), false),
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to send report to moderators: %w", err)
+			}
+
+			return nil
+		},
+	)
+}
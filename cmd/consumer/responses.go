@@ -2,11 +2,11 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
 	"sort"
 	"strings"
 
 	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/rng"
 	"github.com/gobridge/gopherbot/mparser"
 	"github.com/gobridge/gopherbot/workqueue"
 )
@@ -46,11 +46,11 @@ var recommendedChannels = []recommendedChannel{
 
 const newbiesChanID = "C02A8LZKT"
 
-func injectMessageResponseFuncs(ma *handler.MessageActions) {
+func injectMessageResponseFuncs(ma *handler.MessageActions, rnd rng.RNG) {
 	ma.Handle("flip a coin", "flips a coin, returning heads or tails", []string{"flip coin", "coin flip"},
 		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
 			var msg string
-			if rand.Intn(2) == 0 {
+			if rnd.Intn(2) == 0 {
 				msg = "heads"
 			} else {
 				msg = "tails"
@@ -153,188 +153,102 @@ func injectMessageResponseFuncs(ma *handler.MessageActions) {
 			return r.RespondMentionsTextAttachment(ctx, "I respond to the following commands in public channels, or via a direct (private) message:", b.String())
 		},
 	)
+	ma.HandleMiss("suggest the commands closest to one I don't know",
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			suggestions := ma.Suggest(m.Text(), 3)
+
+			if len(suggestions) == 0 {
+				// mentions are often just conversation, so only reply in DMs
+				if m.ChannelType() != handler.ChannelDM {
+					return nil
+				}
+
+				return r.Respond(ctx, "Sorry, I don't know that one. Try `help` to see what I can do.")
+			}
+
+			for i, s := range suggestions {
+				suggestions[i] = "`" + s + "`"
+			}
+
+			msg := "I don't know that one. Did you mean " + suggestions[0] + "?"
+
+			if len(suggestions) > 1 {
+				msg = "I don't know that one. Did you mean one of " + strings.Join(suggestions, ", ") + "?"
+			}
+
+			return r.RespondEphemeral(ctx, msg)
+		},
+	)
 }
 
 func injectMessageResponses(ma *handler.MessageActions) {
-	ma.HandleStatic("recommended", "returns a list of recommended blogs or twitter feeds", []string{"recommended blogs"},
-		`Here are some popular blog posts and Twitter accounts you should follow:`,
-		`- Peter Bourgon <https://twitter.com/peterbourgon|@peterbourgon> - <https://peter.bourgon.org/blog>`,
-		`- Carlisia Campos <https://twitter.com/carlisia|@carlisia>`,
-		`- Dave Cheney <https://twitter.com/davecheney|@davecheney> - <http://dave.cheney.net>`,
-		`- Jaana Burcu Dogan <https://twitter.com/rakyll|@rakyll> - <http://golang.rakyll.org>`,
-		`- Jessie Frazelle <https://twitter.com/jessfraz|@jessfraz> - <https://blog.jessfraz.com>`,
-		`- William "Bill" Kennedy <https://twitter.com/goinggodotnet|@goinggodotnet> - <https://www.goinggo.net>`,
-		`- Brian Ketelsen <https://twitter.com/bketelsen|@bketelsen> - <https://www.brianketelsen.com/blog>`,
-	)
+	ma.HandleMessage("recommended", "returns a list of recommended blogs or twitter feeds", []string{"recommended blogs"}, "recommended")
 
-	ma.HandleStatic("books", "returns a list of books about Go that can help you get started", nil,
-		`Here are some popular books you can use to get started:`,
-		`- William Kennedy, Brian Ketelsen, Erik St. Martin Go In Action <https://www.manning.com/books/go-in-action>`,
-		`- Alan A A Donovan, Brian W Kernighan The Go Programming Language <https://www.gopl.io>`,
-		`- Mat Ryer Go Programming Blueprints 2nd Edition <https://www.packtpub.com/application-development/go-programming-blueprints-second-edition>`,
-	)
+	ma.HandleMessage("books", "returns a list of books about Go that can help you get started", nil, "books")
 
-	ma.HandleStatic("oss help wanted", "find projects with help wanted", []string{"help wanted", "oss help"},
-		`Here's a list of projects which could need some help from contributors like you: <https://github.com/corylanou/oss-helpwanted>`,
-	)
+	ma.HandleMessage("oss help wanted", "find projects with help wanted", []string{"help wanted", "oss help"}, "oss_help_wanted")
 
-	ma.HandleStatic("work with forks", "info on how to work with forks in Go", []string{"working with forks"},
-		`Here's how to work with package forks in Go: <http://blog.sgmansfield.com/2016/06/working-with-forks-in-go/>`,
-	)
+	ma.HandleMessage("work with forks", "info on how to work with forks in Go", []string{"working with forks"}, "work_with_forks")
 
-	ma.HandleStatic("block forever", "how to block forever", []string{"how to block forever"},
-		"Here's the most common way to block forever in Go: `select {}`.",
-		"For other ways check out this post: <https://blog.sgmansfield.com/2016/06/how-to-block-forever-in-go/>",
-	)
+	ma.HandleMessage("block forever", "how to block forever", []string{"how to block forever"}, "block_forever")
 
-	ma.HandleStatic("http timeouts", "info on http timeouts in Go", nil,
-		`Here's a blog post which will help with http timeouts in Go: <https://blog.cloudflare.com/the-complete-guide-to-golang-net-http-timeouts/>`,
-	)
+	ma.HandleMessage("http timeouts", "info on http timeouts in Go", nil, "http_timeouts")
 
-	ma.HandleStatic("slices", "info on slices and how to use them", []string{"slice internals"},
-		`The following posts will explain how slices, maps and strings work in Go:`,
-		`- <https://blog.golang.org/go-slices-usage-and-internals>`,
-		`- <https://blog.golang.org/slices>`,
-		`- <https://blog.golang.org/strings>`,
-	)
+	ma.HandleMessage("slices", "info on slices and how to use them", []string{"slice internals"}, "slices")
 
-	ma.HandleStatic("database tutorial", "working with SQL in Go", []string{"databases"},
-		`Here's how to work with database/sql in Go: <http://go-database-sql.org/>`,
-	)
+	ma.HandleMessage("database tutorial", "working with SQL in Go", []string{"databases"}, "database_tutorial")
 
-	ma.HandleStatic("empty struct", "information about values of type `struct{}`", []string{"zero struct", "struct{}"},
-		"You can find out more about the `struct{}` type in this post by Dave Cheney: <https://dave.cheney.net/2014/03/25/the-empty-struct>",
-	)
+	ma.HandleMessage("empty struct", "information about values of type `struct{}`", []string{"zero struct", "struct{}"}, "empty_struct")
 
-	ma.HandleStatic("project layout", "guidance on how to structure your Go projects", []string{"project structure", "package layout", "package structure"},
-		`These articles will explain how to organize your Go packages:`,
-		`- <https://rakyll.org/style-packages/>`,
-		`- <https://www.gobeyond.dev/standard-package-layout/>`,
-		`- <https://peter.bourgon.org/go-best-practices-2016/#repository-structure>`,
-		`- <https://christine.website/blog/within-go-repo-layout-2020-09-07>`,
-		``,
-		`This article will help you understand the design philosophy for packages: <https://www.goinggo.net/2017/02/design-philosophy-on-packaging.html>`,
-	)
+	ma.HandleMessage("project layout", "guidance on how to structure your Go projects", []string{"project structure", "package layout", "package structure"}, "project_layout")
 
-	ma.HandleStatic("go challenges", "want to challenge your Go skills in interactive?", []string{"challenges", "challenge"},
-		"Check out the growing list of Go challenges - <https://tutorialedge.net/challenges/go/>",
-	)
+	ma.HandleMessage("go challenges", "want to challenge your Go skills in interactive?", []string{"challenges", "challenge"}, "go_challenges")
 
-	ma.HandleStatic("idiomatic go", "want tips on writing idiomatic Go?", nil,
-		`Tips on how to write idiomatic Go code <https://dmitri.shuralyov.com/idiomatic-go>`,
-	)
+	ma.HandleMessage("idiomatic go", "want tips on writing idiomatic Go?", nil, "idiomatic_go")
 
-	ma.HandleStatic("invite link", "URL for getting an invite to the workspace", []string{"invite", "slack invite", "workspace invite"},
-		`<https://invite.slack.golangbridge.org/>`,
-	)
+	ma.HandleMessage("invite link", "URL for getting an invite to the workspace", []string{"invite", "slack invite", "workspace invite"}, "invite_link")
 
-	ma.HandleStatic("avoid gotchas", "avoid common Go gotchas", []string{"gotchas"},
-		`Read this article if you want to understand and avoid common gotchas in Go <https://divan.github.io/posts/avoid_gotchas>`,
-	)
+	ma.HandleMessage("avoid gotchas", "avoid common Go gotchas", []string{"gotchas"}, "avoid_gotchas")
 
-	ma.HandleStatic("source code", "where does this bot's source live?", []string{"source"},
-		`You can find my source code, including all of my configured responses, here:`,
-		`- <https://github.com/gobridge/gopherbot>`,
-	)
+	ma.HandleMessage("source code", "where does this bot's source live?", []string{"source"}, "source_code")
 
-	ma.HandleStatic("dependency injection", "learn more about DI in Go", []string{"di"},
-		`If you'd like to learn more about how to use Dependency Injection in Go, please review this post:`,
-		`- <https://appliedgo.net/di/>`,
-	)
+	ma.HandleMessage("dependency injection", "learn more about DI in Go", []string{"di"}, "dependency_injection")
 
-	ma.HandleStatic("pointer performance", "learn more about pointers and their impact on performance", nil,
-		`The answer to whether using a pointer offers a performance gain is complex and is not always the case. Please read these posts for more information:`,
-		`- <https://medium.com/@vCabbage/go-are-pointers-a-performance-optimization-a95840d3ef85>`,
-		`- <https://segment.com/blog/allocation-efficiency-in-high-performance-go-services/>`,
-	)
+	ma.HandleMessage("pointer performance", "learn more about pointers and their impact on performance", nil, "pointer_performance")
 
-	ma.HandleStatic("gopath", "learn more about using the GOPATH", []string{"gopath problem", "issue with gopath", "help with gopath"},
-		"Your project should be structured as follows:",
-		"```GOPATH=~/go",
-		"~/go/src/sourcecontrol/username/project/```",
-		"Whilst you _can_ get around the GOPATH, it's ill-advised. Read more about the GOPATH here: https://github.com/golang/go/wiki/GOPATH",
-	)
+	ma.HandleMessage("gopath", "learn more about using the GOPATH", []string{"gopath problem", "issue with gopath", "help with gopath"}, "gopath")
 
-	ma.HandleStatic("playground", "info on sharing Go code via the Go Playground", []string{"go playground", "goplay", "goplay space"},
-		`Please share your code via the Go Playground. The Playground offers a specialized runtime environment, that others can use to read your code, iterate on it, and share it back with you:`,
-		`- <https://go.dev/play/>`,
-		`There is also this stylized alternate front-end: <https://goplay.space/>`,
-	)
+	ma.HandleMessage("playground", "info on sharing Go code via the Go Playground", []string{"go playground", "goplay", "goplay space"}, "playground")
 
-	ma.HandleStatic("code of conduct", "info about the code of conduct", []string{"coc"},
-		`We're all expected to follow the GoBridge Code of Conduct, which is itself a superset of the Go Community Code of Conduct. You can find both here:`,
-		`- <http://coc.golangbridge.org>`,
-		`- <https://golang.org/conduct>`,
-		`If you have any questions or concerns please reach out in <#C4U9J9QBT> or email support@gobridge.org.`,
-	)
+	ma.HandleMessage("code of conduct", "info about the code of conduct", []string{"coc"}, "code_of_conduct")
 
-	ma.HandleStatic("screenshots", "why you shouldn't use screenshots", []string{"screenshot"},
-		`Screenshots are neither easy to read nor very accessible for many people. Please consider copying code, errors, or command output into a Slack snippet, the <https://go.dev/play/|Go Playground>, or a <https://gist.github.com/|GitHub Gist>.`,
-	)
+	ma.HandleMessage("screenshots", "why you shouldn't use screenshots", []string{"screenshot"}, "screenshots")
 
-	ma.HandleStatic("go tour", "link to the Go tour", []string{"tour"},
-		`The Go Tour is a great resource for learning Go, and can be found here: <https://tour.golang.org/welcome/1>`,
-	)
+	ma.HandleMessage("go tour", "link to the Go tour", []string{"tour"}, "go_tour")
 
-	ma.HandleStatic("doesn't work", "x doesn't work", []string{"more context", "doesnt work", "doesntwork"},
-		`Can you share more context on what you expected and what you saw instead?`,
-		`If there's an error, are you able to provide it in full and share how you generated that error?`,
-	)
+	ma.HandleMessage("doesn't work", "x doesn't work", []string{"more context", "doesnt work", "doesntwork"}, "doesnt_work")
 
-	ma.HandleStatic("ask", "how to ask questions", []string{"don't ask", "dont ask", "dontask", "just ask", "justask"},
-		`Don't ask to ask. Just ask. We'll let you know if there's a better place to ask.`,
-		`- <https://dontasktoask.com/>`,
-	)
+	ma.HandleMessage("ask", "how to ask questions", []string{"don't ask", "dont ask", "dontask", "just ask", "justask"}, "ask")
 
-	ma.HandleStatic("crosspost", "cross-posting to multiple channels", []string{"xpost"},
-		`Please keep your questions to a single channel. If you don't get a reply in a while, then consider cross-posting.`,
-	)
+	ma.HandleMessage("crosspost", "cross-posting to multiple channels", []string{"xpost"}, "crosspost")
 
 	injectFyneMessageResponses(ma)
 }
 
 func injectFyneMessageResponses(ma *handler.MessageActions) {
-	ma.HandleStatic("fyne books", "returns a list of books that cover the Fyne toolkit", nil,
-		`Here are some helpful books that discuss Fyne:`,
-		`- Andrew Williams Building Cross-Platform GUI Applications with Fyne <https://www.packtpub.com/product/building-cross-platform-gui-applications-with-fyne/9781800563162>`,
-		`- Andrew Williams Hands-On GUI Application Development in Go <https://www.packtpub.com/product/hands-on-gui-application-development-in-go/9781789138412>`,
-	)
+	ma.HandleMessage("fyne books", "returns a list of books that cover the Fyne toolkit", nil, "fyne_books")
 
-	ma.HandleStatic("fyne tour", "link to the Fyne tour", nil,
-		`The Fyne Tour is a starting point for learning the toolkit, and can be found here: <https://tour.fyne.io/>`,
-	)
+	ma.HandleMessage("fyne tour", "link to the Fyne tour", nil, "fyne_tour")
 
-	ma.HandleStatic("fyne compile", "tips for common Fyne compile issues", []string{"fyne cgo", "fyne windows"},
-		`To compile a Fyne app you will need CGo working which requires a C compiler as well as Go.
-			For more information follow the platform specific hints at <https://developer.fyne.io/started/>.
-			Windows based developers may need to double check that their C compiler is 64bit as many default to 32`,
-	)
+	ma.HandleMessage("fyne compile", "tips for common Fyne compile issues", []string{"fyne cgo", "fyne windows"}, "fyne_compile")
 
-	ma.HandleStatic("fyne docs", "link to the Fyne documentation", []string{"fyne doc"},
-		`There are various sources of Fyne documentation depending on what you are looking for:`,
-		`- Getting started <https://developer.fyne.io/started/>`,
-		`- API documentation <https://developer.fyne.io/api/>`,
-		`- Tutorials and tips for advanced topics <https://developer.fyne.io/tutorial/>`,
-		`- Video tutorials and conference recordings <https://www.youtube.com/c/fyne-io>`,
-	)
+	ma.HandleMessage("fyne docs", "link to the Fyne documentation", []string{"fyne doc"}, "fyne_docs")
 
-	ma.HandleStatic("fyne layouts", "information about Fyne layout handling", []string{"fyne layout"},
-		`In Fyne, a containers layout controls the size and position of widgets,
-			there is a list of standard layouts at <https://developer.fyne.io/started/layouts>
-			and you can build your own <https://developer.fyne.io/tutorial/custom-layout>`,
-	)
+	ma.HandleMessage("fyne layouts", "information about Fyne layout handling", []string{"fyne layout"}, "fyne_layouts")
 
-	ma.HandleStatic("fyne sizes", "information about widget sizing in Fyne", []string{"fyne size"},
-		`In Fyne, a widgets size and position is controlled by the layout of the container that it is inside <https://developer.fyne.io/faq/layout>.
-		If your widget is too small perhaps consider avoiding use of VBox and HBox which try to pack items in small.`,
-	)
+	ma.HandleMessage("fyne sizes", "information about widget sizing in Fyne", []string{"fyne size"}, "fyne_sizes")
 
-	ma.HandleStatic("fyne theme", "links for information about Fyne themes", []string{"fyne themes", "fyne style", "fyne styles"},
-		`The look and feel of a Fyne application is controlled by the current theme.
-			The default material design theme has both light and dark modes (set by user preference)
-			Apps can specify a custom theme <https://developer.fyne.io/tutorial/custom-theme>,
-			more info about customisation at <https://developer.fyne.io/faq/theme>`,
-	)
+	ma.HandleMessage("fyne theme", "links for information about Fyne themes", []string{"fyne themes", "fyne style", "fyne styles"}, "fyne_theme")
 }
 
 const newbieResourcesMessage = `First you should take the language tour: <https://tour.golang.org/>
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/handler/handlertest"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// fixedRNG always returns the same number, to pick the result of a coin flip.
+type fixedRNG int
+
+func (f fixedRNG) Intn(n int) int   { return int(f) % n }
+func (f fixedRNG) Float64() float64 { return 0 }
+
+func testResponseFuncs(tb testing.TB, rnd fixedRNG) *handler.MessageActions {
+	tb.Helper()
+
+	ma, err := handler.NewMessageActions(handlertest.BotID, false, zerolog.Nop())
+	if err != nil {
+		tb.Fatalf("NewMessageActions() unexpected error: %v", err)
+	}
+
+	injectMessageResponseFuncs(ma, rnd)
+
+	return ma
+}
+
+func TestFlipACoin(t *testing.T) {
+	tests := []struct {
+		name string
+		rnd  fixedRNG
+		text string
+		want string
+	}{
+		{name: "heads", rnd: 0, text: "flip a coin", want: "heads\n"},
+		{name: "tails", rnd: 1, text: "flip a coin", want: "tails\n"},
+		{name: "alias", rnd: 0, text: "coin flip", want: "heads\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ma := testResponseFuncs(t, tt.rnd)
+
+			rec, err := handlertest.Dispatch(handlertest.NewContext(), ma, handlertest.NewMessage(tt.text).MentioningBot())
+			if err != nil {
+				t.Fatalf("Dispatch() unexpected error: %v", err)
+			}
+
+			if got := rec.Text(); got != tt.want {
+				t.Fatalf("responses = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecommendedChannels(t *testing.T) {
+	ma := testResponseFuncs(t, 0)
+
+	// the channels that aren't found are left out
+	ctx := handlertest.NewContext().
+		AddChannel("C1", "general").
+		AddChannel("C2", "jobs").
+		AddChannel("C3", "admin-help")
+
+	rec, err := handlertest.Dispatch(ctx, ma, handlertest.NewMessage("channels").InDM("D1"))
+	if err != nil {
+		t.Fatalf("Dispatch() unexpected error: %v", err)
+	}
+
+	want := []handlertest.Response{
+		{
+			Method: "RespondMentionsTextAttachment",
+			Text:   "Here is a list of recommended channels",
+			Attachments: []slack.Attachment{{
+				Text: "- <#C1> -> for general Go questions or help\n" +
+					"- <#C2> -> for jobs related to Go\n" +
+					"- <#C3> -> for engaging with the moderators / admins of this Slack workspace\n",
+			}},
+		},
+	}
+
+	if diff := cmp.Diff(want, rec.Responses()); diff != "" {
+		t.Fatalf("responses differ (-want +got):\n%s", diff)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	ma := testResponseFuncs(t, 0)
+	ma.HandlePrefix("run ", "run some code", func(workqueue.Context, handler.Messenger, handler.Responder) error { return nil })
+
+	rec, err := handlertest.Dispatch(handlertest.NewContext(), ma, handlertest.NewMessage("help").MentioningBot())
+	if err != nil {
+		t.Fatalf("Dispatch() unexpected error: %v", err)
+	}
+
+	want := []handlertest.Response{
+		{
+			Method: "RespondMentionsTextAttachment",
+			Text:   "I respond to the following commands in public channels, or via a direct (private) message:",
+			Attachments: []slack.Attachment{{
+				Text: "- `flip a coin`: flips a coin, returning heads or tails\n\t- aliases: `flip coin`,`coin flip`\n\n" +
+					"- `help`: show the commands I support\n\t- aliases: `commands`\n\n" +
+					"- `newbie resources`: resources for newbies\n\n" +
+					"- `recommended channels`: channels we recommend folks join\n\t- aliases: `channels`\n\n" +
+					"\n\nThere are also these special message prefixes:\n\n" +
+					"- `run `: run some code\n\n",
+			}},
+		},
+	}
+
+	if diff := cmp.Diff(want, rec.Responses()); diff != "" {
+		t.Fatalf("responses differ (-want +got):\n%s", diff)
+	}
+}
+
+func TestMiss(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *handlertest.Message
+		want []handlertest.Response
+	}{
+		{
+			name: "suggestion",
+			msg:  handlertest.NewMessage("hlep").MentioningBot(),
+			want: []handlertest.Response{
+				{Method: "RespondEphemeral", Text: "I don't know that one. Did you mean `help`?"},
+			},
+		},
+		{
+			name: "conversation",
+			msg:  handlertest.NewMessage("thanks for all your hard work").MentioningBot(),
+		},
+		{
+			name: "dm",
+			msg:  handlertest.NewMessage("thanks for all your hard work").InDM("D1"),
+			want: []handlertest.Response{
+				{Method: "Respond", Text: "Sorry, I don't know that one. Try `help` to see what I can do."},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ma := testResponseFuncs(t, 0)
+
+			rec, err := handlertest.Dispatch(handlertest.NewContext(), ma, tt.msg)
+			if err != nil {
+				t.Fatalf("Dispatch() unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, rec.Responses()); diff != "" {
+				t.Fatalf("responses differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
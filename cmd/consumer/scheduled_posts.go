@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/gobridge/gopherbot/internal/scheduled"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const scheduleUsage = "To schedule a recurring post, use `schedule add #channel \"<cron expression>\" \"<text>\"`, like `schedule add #jobs \"0 9 * * MON\" \"Please read the job posting rules\"`. " +
+	"Times are in UTC, unless the expression starts with a time zone like `TZ=Europe/London`. " +
+	"Use `schedule list` to see the posts, and `schedule remove <id>` to stop one."
+
+// injectScheduledPostHandlers registers the command Workspace Admins use to
+// manage recurring posts, which bgtasks posts.
+func injectScheduledPostHandlers(ma *handler.MessageActions, ps *scheduled.Store) {
+	ma.HandleCommand("schedule", scheduleUsage, "(admins only) schedule recurring posts, like a weekly introductions thread",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			if !m.BotMentioned() && m.ChannelType() != handler.ChannelDM {
+				return nil
+			}
+
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can schedule posts.")
+			}
+
+			switch strings.ToLower(c.Arg(0)) {
+			case "add":
+				return addScheduledPost(ctx, m, c, r, ps)
+
+			case "list":
+				return listScheduledPosts(ctx, r, ps)
+
+			case "remove":
+				id, err := strconv.ParseInt(c.Arg(1), 10, 64)
+				if err != nil || len(c.Args) != 2 {
+					return handler.Usagef("I need the ID of the post to remove")
+				}
+
+				err = ps.Remove(ctx, id)
+				if errors.Is(err, scheduled.ErrNotFound) {
+					return r.RespondEphemeral(ctx, fmt.Sprintf("There's no scheduled post %d.", id))
+				}
+
+				if err != nil {
+					return fmt.Errorf("failed to remove scheduled post: %w", err)
+				}
+
+				return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, I removed scheduled post %d.", id))
+
+			default:
+				return &handler.UsageError{}
+			}
+		},
+	)
+}
+
+func addScheduledPost(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder, ps *scheduled.Store) error {
+	if len(c.Args) < 3 {
+		return handler.Usagef("I need a schedule and the text to post")
+	}
+
+	sched, err := cron.Parse(c.Arg(1))
+	if err != nil {
+		return handler.Usagef("I couldn't understand the schedule: %s", err)
+	}
+
+	channelID := m.ChannelID()
+
+	for _, mention := range m.AllMentions() {
+		if mention.Type == mparser.TypeChannelRef {
+			channelID = mention.ID
+			break
+		}
+	}
+
+	p, err := ps.Add(ctx, scheduled.Post{
+		ChannelID: channelID,
+		Schedule:  sched.String(),
+		Text:      strings.Join(c.Args[2:], " "),
+		CreatedBy: m.UserID(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add scheduled post: %w", err)
+	}
+
+	next, err := p.Next()
+	if err != nil {
+		return fmt.Errorf("failed to get next run of scheduled post: %w", err)
+	}
+
+	return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, scheduled post %d goes to <#%s> next at %s.", p.ID, p.ChannelID, next.UTC().Format(time.RFC1123)))
+}
+
+func listScheduledPosts(ctx workqueue.Context, r handler.Responder, ps *scheduled.Store) error {
+	posts, err := ps.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list scheduled posts: %w", err)
+	}
+
+	if len(posts) == 0 {
+		return r.RespondEphemeral(ctx, "There are no scheduled posts.")
+	}
+
+	b := &strings.Builder{}
+
+	for _, p := range posts {
+		text := p.Text
+		if r := []rune(text); len(r) > 80 {
+			text = string(r[:77]) + "..."
+		}
+
+		fmt.Fprintf(b, "- %d: `%s` in <#%s> by <@%s>: %s\n", p.ID, p.Schedule, p.ChannelID, p.CreatedBy, text)
+	}
+
+	return r.RespondEphemeral(ctx, b.String())
+}
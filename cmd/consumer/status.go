@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/status"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// queueLagWarn is how long an event can wait to be delivered before the
+	// queue is reported as degraded.
+	queueLagWarn = 30 * time.Second
+
+	// queueLagFail is how long an event can wait to be delivered before the
+	// queue is reported as down.
+	queueLagFail = 5 * time.Minute
+)
+
+type streamDetail struct {
+	Pending     int64      `json:"pending"`
+	Undelivered int64      `json:"undelivered"`
+	Oldest      *time.Time `json:"oldest_undelivered,omitempty"`
+	Lag         float64    `json:"lag_seconds"`
+}
+
+// queueCheck reports how far behind the consumer group is on each of the
+// workqueue streams, based on the oldest event not yet delivered.
+func queueCheck(rc *redis.Client, group string) status.CheckFunc {
+	return func(ctx context.Context) status.Report {
+		lags, err := workqueue.Lag(ctx, rc, group)
+		if err != nil {
+			return status.Report{State: status.Down, Error: err.Error()}
+		}
+
+		r := status.Report{State: status.OK}
+
+		details := make(map[string]streamDetail, len(lags))
+
+		var worst time.Duration
+
+		for _, l := range lags {
+			d := streamDetail{Pending: l.Pending, Undelivered: l.Undelivered}
+
+			if !l.Oldest.IsZero() {
+				oldest := l.Oldest.UTC()
+				lag := time.Since(oldest)
+
+				d.Oldest = &oldest
+				d.Lag = lag.Seconds()
+
+				if lag > worst {
+					worst = lag
+				}
+			}
+
+			details[l.Stream] = d
+		}
+
+		r.Detail = details
+
+		switch {
+		case worst >= queueLagFail:
+			r.State = status.Down
+		case worst >= queueLagWarn:
+			r.State = status.Degraded
+		}
+
+		if r.State != status.OK {
+			r.Error = fmt.Sprintf("events waiting %s to be delivered", worst.Truncate(time.Second))
+		}
+
+		return r
+	}
+}
+
+type throttleDetail struct {
+	Limit       int        `json:"limit"`
+	Max         int        `json:"max"`
+	InFlight    int        `json:"in_flight"`
+	PausedUntil *time.Time `json:"paused_until,omitempty"`
+}
+
+// throttleCheck reports whether the workqueue is backing off because Slack
+// rate limited us, which is Degraded until it's back to full concurrency.
+func throttleCheck(q *workqueue.I) status.CheckFunc {
+	return func(context.Context) status.Report {
+		ts := q.ThrottleStats()
+
+		d := throttleDetail{
+			Limit:    ts.Limit,
+			Max:      ts.Max,
+			InFlight: ts.InFlight,
+		}
+
+		if !ts.PausedUntil.IsZero() {
+			pu := ts.PausedUntil.UTC()
+			d.PausedUntil = &pu
+		}
+
+		r := status.Report{State: status.OK, Detail: d}
+
+		if ts.Limit < ts.Max {
+			r.State = status.Degraded
+			r.Error = fmt.Sprintf("rate limited by Slack: handling %d events at once, down from %d", ts.Limit, ts.Max)
+		}
+
+		return r
+	}
+}
+
+// publishQueueDepth publishes how many events are waiting on each stream as the
+// workqueue_queue_depth expvar, so it can be graphed from /debug/vars.
+func publishQueueDepth(rc *redis.Client, group string) {
+	expvar.Publish("workqueue_queue_depth", expvar.Func(func() interface{} {
+		lags, err := workqueue.Lag(context.Background(), rc, group)
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+
+		depths := make(map[string]int64, len(lags))
+		for _, l := range lags {
+			depths[l.Stream] = l.Pending + l.Undelivered
+		}
+
+		return depths
+	}))
+}
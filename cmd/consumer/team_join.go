@@ -1,29 +1,115 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/joins"
+	"github.com/gobridge/gopherbot/internal/nurture"
+	"github.com/gobridge/gopherbot/internal/prefs"
 	"github.com/gobridge/gopherbot/workqueue"
 )
 
-func injectTeamJoinHandlers(t *handler.TeamJoinActions) {
+// injectTeamJoinHandlers welcomes new members, at most once each: js records
+// each welcome before it's sent, so retries and restarts don't send another.
+// Members rejoining with the dm_welcome preference off aren't welcomed. The
+// welcome is short, and the rest of what new members should know is scheduled
+// in ns as follow-ups, unless they've opted out of them. The follow-ups are
+// scheduled before the welcome is sent, and scheduling them again on a retry
+// doesn't change them.
+func injectTeamJoinHandlers(t *handler.TeamJoinActions, js *joins.Store, ps *prefs.Store, ns *nurture.Store) {
 	t.Handle("new members",
 		func(ctx workqueue.Context, tj handler.TeamJoiner, r handler.Responder) error {
-			wmsg, err := welcomeMessage(recommendedChannels, ctx.ChannelSvc(), ctx.Self().ID, ctx.Self().Name)
+			w, err := newWelcome(recommendedChannels, ctx.ChannelSvc(), ctx.Self().ID, ctx.Self().Name)
 			if err != nil {
 				return fmt.Errorf("failed to generate welcome message: %w", err)
 			}
 
+			uid := tj.User().ID
+
+			welcome, err := ps.Enabled(ctx, uid, prefs.DMWelcome)
+			if err != nil {
+				return fmt.Errorf("failed to get welcome preference: %w", err)
+			}
+
+			if !welcome {
+				ctx.Logger().Info().
+					Str("user_id", uid).
+					Msg("user turned off the welcome DM")
+
+				return nil
+			}
+
+			followUps := !tj.User().IsBot
+
+			if followUps {
+				optedOut, err := ns.OptedOut(ctx, uid)
+				if err != nil {
+					return fmt.Errorf("failed to get follow-up opt-out: %w", err)
+				}
+
+				followUps = !optedOut
+			}
+
+			claimed, err := js.ClaimWelcome(ctx, uid)
+			if err != nil {
+				return fmt.Errorf("failed to record welcome: %w", err)
+			}
+
+			if !claimed {
+				ctx.Logger().Info().
+					Str("user_id", uid).
+					Msg("user was already welcomed")
+
+				return nil
+			}
+
+			// release the claim if the welcome wasn't sent, so a retry does
+			// it all again
+			release := func() {
+				rctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				defer cancel()
+
+				if rerr := js.ReleaseWelcome(rctx, uid); rerr != nil {
+					ctx.Logger().Error().
+						Err(rerr).
+						Str("user_id", uid).
+						Msg("failed to release welcome record: user won't be welcomed on retry")
+				}
+			}
+
+			// the follow-ups are scheduled before the welcome is sent, as a
+			// retry after it's sent finds it claimed and does nothing
+			if followUps {
+				if err := ns.ScheduleWelcome(ctx, uid, ctx.Meta().Time, w.followUps); err != nil {
+					release()
+					return err
+				}
+			}
+
 			ctx.Logger().Debug().
-				Str("user_id", tj.User().ID).
+				Str("user_id", uid).
 				Str("user_email", tj.User().Profile.Email).
 				Time("joined_time", ctx.Meta().Time).
-				Int("msg_len", len(wmsg)).
+				Int("msg_len", len(w.message)).
 				Msg("welcoming user")
 
-			return r.RespondDM(ctx, wmsg)
+			if err := r.RespondDM(ctx, w.message); err != nil {
+				// if we timed out, the welcome may have been sent anyway, so
+				// err on the side of not sending it twice
+				if ctx.Err() != nil {
+					return fmt.Errorf("welcome may not have been sent: %w", err)
+				}
+
+				release()
+
+				return err
+			}
+
+			return nil
 		},
 	)
 }
@@ -33,16 +119,22 @@ const (
 	sausheongID = "U03QZHXD8"
 )
 
-func welcomeMessage(channels []recommendedChannel, cs workqueue.ChannelSvc, selfID, selfName string) (string, error) {
+// welcome is the short welcome DM, and the follow-ups sent in the days after.
+type welcome struct {
+	message   string
+	followUps map[nurture.Step]string
+}
+
+func newWelcome(channels []recommendedChannel, cs workqueue.ChannelSvc, selfID, selfName string) (welcome, error) {
 	b := &strings.Builder{}
 
-	var generalID, adminHelpID string
+	var generalID, newbiesID, adminHelpID string
 
 	for _, c := range channels {
 		if c.welcome {
 			ch, notFound, err := cs.Lookup(c.name)
 			if err != nil {
-				return "", fmt.Errorf("failed to look up channel: %w", err)
+				return welcome{}, fmt.Errorf("failed to look up channel: %w", err)
 			}
 
 			if notFound {
@@ -52,6 +144,8 @@ func welcomeMessage(channels []recommendedChannel, cs workqueue.ChannelSvc, self
 			switch c.name {
 			case "general":
 				generalID = ch.ID
+			case "newbies":
+				newbiesID = ch.ID
 			case "admin-help":
 				adminHelpID = ch.ID
 			}
@@ -61,37 +155,50 @@ func welcomeMessage(channels []recommendedChannel, cs workqueue.ChannelSvc, self
 		}
 	}
 
-	return fmt.Sprintf(teamJoinWelcomeMessageFormat, adminHelpID, selfID, selfName, generalID, b.String(), bkennedyID, sausheongID), nil
+	return welcome{
+		message: fmt.Sprintf(teamJoinWelcomeMessageFormat, adminHelpID, generalID, newbiesID, selfID, selfName),
+		followUps: map[nurture.Step]string{
+			nurture.ChannelsStep: fmt.Sprintf(welcomeChannelsMessageFormat, b.String(), bkennedyID, sausheongID),
+			nurture.AskingStep:   fmt.Sprintf(welcomeAskingMessageFormat, generalID),
+		},
+	}, nil
 }
 
-// because of the usage of backticks and quotes in the welcome message, this
-// constant has become a bit ridiculous.
+// because of the usage of backticks and quotes in the welcome messages, these
+// constants have become a bit ridiculous.
 //
-// maybe it would be easier to read if it were a slice of strings?
+// maybe they would be easier to read if they were slices of strings?
 const teamJoinWelcomeMessageFormat = `Welcome to the Gophers Slack Workspace! This space is meant to connect gophers from all over the world in a central place. I am the community chat bot, and do have a few functions available to help you during your time here. :simple_smile:
 
 Before getting started, we ask that you take a look at the rules all members are expected to follow: <http://coc.golangbridge.org>. If you ever need help from our workspace's community moderators or administrators, please reach out in <#%s>.
 
-If you'd like to learn more about the functions I offer, please send me the ` + " `help` " + `command. You can send commands to me via a DM (like this one), or by mentioning me (<@%s>) in one of the main public channels:
+Go related questions are welcome in <#%s>, and if you're new to Go, <#%s> is the place to learn together.
+
+To see what else I can do, send me the` + " `help` " + `command, here in a DM or by mentioning me (<@%s>) in one of the main public channels:
 
 ` + "```" + `
 @%s help
 ` + "```" + `
 
-There is also a forum <https://forum.golangbridge.org>, which you might want to check it out as well if a Forum is more your style.
+Over the next week I'll send you a couple of short tips: which channels to join, and how to ask questions. If you'd rather I didn't, send me` + " `followups off`" + `.`
 
-<#%s> can sometimes seem busy, but please don't hesitate to ask your Go related questions there. To share code while asking a question, you should use: <https://go.dev/play/> as it makes it easy for others to help you.
-
-Here's a list of a few other channels you could join:
+const welcomeChannelsMessageFormat = `Hi again! :wave: Here's a list of a few channels you could join:
 %s
-
 If you want more channel suggestions, type` + " `recommended channels` " + `in a direct message to me.
 
 There are quite a few other channels, depending on your interests or location (we have city / country wide channels). Just click on the :heavy_plus_sign: next to the channel list in the sidebar, and click Browse Channels to search for anything that interests you.
 
 If you are new to Go and want a copy of the Go In Action book, <https://www.manning.com/books/go-in-action>, please send an email to <@%s> at bill@ardanlabs.com
 
-If you are interested in a free copy of the Go Web Programming book by Sau Sheong Chang, <@%s>, please send him an email at sausheong@gmail.com
+If you are interested in a free copy of the Go Web Programming book by Sau Sheong Chang, <@%s>, please send him an email at sausheong@gmail.com`
+
+const welcomeAskingMessageFormat = `Hi again! :wave: Here's how to get the most out of asking questions here.
+
+<#%s> can sometimes seem busy, but please don't hesitate to ask your Go related questions there. Just ask, rather than asking whether you can ask: <https://dontasktoask.com/>
+
+Say what you expected to happen and what happened instead, with any error in full. To share code, use <https://go.dev/play/>, or a Slack snippet, as it makes it easy for others to help you, and screenshots of code are hard to read.
+
+There is also a forum <https://forum.golangbridge.org>, which you might want to check out as well if a Forum is more your style.
 
 In case you want to customize your profile picture, you can use <https://gopherize.me/> to create a custom gopher.
 
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gobridge/gopherbot/handler/handlertest"
+	"github.com/gobridge/gopherbot/internal/nurture"
+)
+
+func Test_newWelcome(t *testing.T) {
+	ctx := handlertest.NewContext().
+		AddChannel("C1", "general").
+		AddChannel("C2", "newbies").
+		AddChannel("C3", "admin-help").
+		AddChannel("C4", "jobs")
+
+	w, err := newWelcome(recommendedChannels, ctx.ChannelSvc(), "U0BOT", "gopher")
+	if err != nil {
+		t.Fatalf("newWelcome() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"<#C1>", "<#C2>", "<#C3>", "`followups off`"} {
+		if !strings.Contains(w.message, want) {
+			t.Errorf("welcome message missing %q:\n%s", want, w.message)
+		}
+	}
+
+	if strings.Contains(w.message, "<#C4>") {
+		t.Errorf("welcome message should leave the channel list to the follow-up:\n%s", w.message)
+	}
+
+	if got := w.followUps[nurture.ChannelsStep]; !strings.Contains(got, "- <#C4> -> for jobs related to Go\n") {
+		t.Errorf("channels follow-up missing #jobs:\n%s", got)
+	}
+
+	if got := w.followUps[nurture.AskingStep]; !strings.Contains(got, "<#C1> can sometimes seem busy") {
+		t.Errorf("asking follow-up missing #general:\n%s", got)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/threading"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+const threadingUsage = "Usage: `threading channel|thread|ephemeral|default [#channel]` sets where my responses go in the channel: where the message was, in a thread on it, or only to the person who asked. Without arguments, it lists the channels with their own policy."
+
+// injectThreadingHandlers registers the command Workspace Admins use to
+// override where responses go in a channel, such as keeping them in threads in
+// a busy one.
+func injectThreadingHandlers(ma *handler.MessageActions, ts *threading.Store) {
+	ma.HandleCommand("threading", threadingUsage, "(admins only) `threading thread #channel` keeps my responses in a channel in threads",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			if !m.BotMentioned() && m.ChannelType() != handler.ChannelDM {
+				return nil
+			}
+
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can change where I respond.")
+			}
+
+			if len(c.Args) == 0 {
+				os, err := ts.All(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to get threading policies: %w", err)
+				}
+
+				if len(os) == 0 {
+					return r.RespondEphemeral(ctx, "No channels have their own threading policy.")
+				}
+
+				var b strings.Builder
+
+				for _, o := range os {
+					fmt.Fprintf(&b, "- <#%s>: %s\n", o.ChannelID, o.Policy)
+				}
+
+				return r.RespondEphemeral(ctx, b.String())
+			}
+
+			if len(c.Args) != 1 {
+				return &handler.UsageError{}
+			}
+
+			channelID := m.ChannelID()
+
+			for _, mention := range m.AllMentions() {
+				if mention.Type == mparser.TypeChannelRef {
+					channelID = mention.ID
+					break
+				}
+			}
+
+			if strings.EqualFold(c.Arg(0), "default") {
+				if err := ts.Clear(ctx, channelID); err != nil {
+					return err
+				}
+
+				return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, <#%s> uses each command's own threading policy again.", channelID))
+			}
+
+			t, err := handler.ParseThreading(c.Arg(0))
+			if err != nil {
+				return handler.Usagef("I don't know the policy `%s`", c.Arg(0))
+			}
+
+			if err := ts.Set(ctx, channelID, t.String()); err != nil {
+				return err
+			}
+
+			ctx.Logger().Info().
+				Str("user_id", m.UserID()).
+				Str("channel_id", channelID).
+				Str("threading", t.String()).
+				Msg("threading policy updated")
+
+			return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, my responses in <#%s> now go to the %s.", channelID, threadingPlace(t)))
+		},
+	)
+}
+
+func threadingPlace(t handler.Threading) string {
+	switch t {
+	case handler.ThreadingThread:
+		return "thread on the message they respond to"
+	case handler.ThreadingEphemeral:
+		return "person who asked, only"
+	default:
+		return "channel or thread the message was in"
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/topicwatch"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+func topicQuote(s string) string {
+	if len(strings.TrimSpace(s)) == 0 {
+		return "> _(empty)_"
+	}
+
+	return "> " + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n> ")
+}
+
+// topicChangeMessage is the report of a change to the moderators. outcome says
+// what was done about it, if anything.
+func topicChangeMessage(c topicwatch.Change, outcome string) string {
+	msg := fmt.Sprintf(":memo: <@%s> changed the %s of <#%s> to:\n%s", c.UserID, c.Field, c.ChannelID, topicQuote(c.Value))
+
+	if len(outcome) > 0 {
+		msg += "\n" + outcome
+	}
+
+	return msg
+}
+
+// topicWatchHandler handles changes to the topic and purpose of the channels
+// watched by the policy. They're reported to the moderators, and, where the
+// policy says to, changes by anyone but an admin are reverted to the last
+// value an admin set.
+func topicWatchHandler(shadowMode bool, p *topicwatch.Policy, store topicwatch.Approvals, nr *notify.Router) workqueue.ChannelTopicHandler {
+	return func(ctx workqueue.Context, ct *workqueue.ChannelTopicEvent) (bool, bool, error) {
+		// including our own reverts
+		if ct.User == ctx.Self().ID || !p.Watched(ct.Channel) {
+			return false, false, nil
+		}
+
+		c := topicwatch.Change{
+			ChannelID: ct.Channel,
+			Field:     topicwatch.Topic,
+			UserID:    ct.User,
+			Value:     ct.Topic,
+		}
+
+		if ct.IsPurpose() {
+			c.Field, c.Value = topicwatch.Purpose, ct.Purpose
+		}
+
+		admin, err := handler.IsAdmin(ctx, ct.User)
+		if err != nil {
+			return true, false, err
+		}
+
+		c.ByAdmin = admin
+
+		d := p.Decide(c)
+
+		var outcome string
+
+		if d.Approve {
+			if err := store.Approve(ctx, c.ChannelID, c.Field, c.Value); err != nil {
+				return true, false, err
+			}
+		}
+
+		if d.Revert {
+			prev, found, err := store.Approved(ctx, c.ChannelID, c.Field)
+			if err != nil {
+				return true, false, err
+			}
+
+			switch {
+			case !found:
+				outcome = fmt.Sprintf("I didn't change it back, as no admin has set the %s since it's been watched.", c.Field)
+
+			case prev == c.Value:
+				// nothing to revert
+
+			case shadowMode:
+				ctx.Logger().Info().
+					Str("channel_id", c.ChannelID).
+					Str("field", string(c.Field)).
+					Msg("would revert channel change")
+
+				outcome = fmt.Sprintf("I would have changed it back, to:\n%s", topicQuote(prev))
+
+			default:
+				if c.Field == topicwatch.Purpose {
+					_, err = ctx.Slack().SetPurposeOfConversationContext(ctx, c.ChannelID, prev)
+				} else {
+					_, err = ctx.Slack().SetTopicOfConversationContext(ctx, c.ChannelID, prev)
+				}
+
+				if err != nil {
+					ctx.Logger().Error().
+						Err(err).
+						Str("channel_id", c.ChannelID).
+						Str("field", string(c.Field)).
+						Msg("failed to revert channel change")
+
+					outcome = fmt.Sprintf("I couldn't change it back: %s", err)
+				} else {
+					outcome = fmt.Sprintf("I changed it back, to:\n%s", topicQuote(prev))
+				}
+			}
+		}
+
+		if !d.Report {
+			return false, false, nil
+		}
+
+		severity := notify.Info
+		if d.Revert {
+			severity = notify.Important
+		}
+
+		_, err = nr.Notify(ctx, notify.Notification{
+			Source:   notify.Moderation,
+			Severity: severity,
+			Summary:  fmt.Sprintf("%s of %s changed by %s", c.Field, c.ChannelID, c.UserID),
+			Options: []slack.MsgOption{
+				slack.MsgOptionText(topicChangeMessage(c, outcome), false),
+				slack.MsgOptionDisableLinkUnfurl(),
+			},
+		})
+		if err != nil {
+			return false, false, fmt.Errorf("failed to report channel change: %w", err)
+		}
+
+		return false, false, nil
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/undo"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// undoReaction is the emoji that deletes a response, when added to it by who
+// triggered it or a Workspace Admin.
+const undoReaction = "wastebasket"
+
+// injectUndoHandlers lets whoever triggered one of the bot's responses, or a
+// Workspace Admin, delete it within undo.Window by reacting to it with
+// :wastebasket:, or replying "delete" in its thread.
+func injectUndoHandlers(ma *handler.MessageActions, raa *handler.ReactionAddedActions, us *undo.Store) {
+	raa.Handle("undo_reply", undoReaction,
+		func(ctx workqueue.Context, ra handler.Reactor, _ handler.Responder) error {
+			if ra.ItemUserID() != ctx.Self().ID {
+				return nil
+			}
+
+			author, found, err := us.TriggeredBy(ctx, ra.ChannelID(), ra.MessageTS())
+			if err != nil {
+				return err
+			}
+
+			if !found {
+				return nil
+			}
+
+			return undoReply(ctx, us, ra.ChannelID(), ra.MessageTS(), author, ra.UserID())
+		},
+	)
+
+	ma.HandleDynamic(
+		func(shadowMode bool, m handler.Messenger) bool {
+			return !shadowMode && len(m.ThreadTS()) > 0 && strings.EqualFold(m.Text(), "delete")
+		},
+		func(ctx workqueue.Context, m handler.Messenger, _ handler.Responder) error {
+			ts, found, err := us.LatestReply(ctx, m.ChannelID(), m.ThreadTS(), m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if found {
+				return undoReply(ctx, us, m.ChannelID(), ts, m.UserID(), m.UserID())
+			}
+
+			// it could be someone else's response starting the thread,
+			// which only an admin can delete
+			author, found, err := us.TriggeredBy(ctx, m.ChannelID(), m.ThreadTS())
+			if err != nil {
+				return err
+			}
+
+			if !found {
+				return nil
+			}
+
+			return undoReply(ctx, us, m.ChannelID(), m.ThreadTS(), author, m.UserID())
+		},
+	)
+}
+
+// undoReply deletes the response triggered by author, if it's who asked or a
+// Workspace Admin.
+func undoReply(ctx workqueue.Context, us *undo.Store, channelID, ts, author, userID string) error {
+	if userID != author {
+		admin, err := handler.IsAdmin(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if !admin {
+			return nil
+		}
+	}
+
+	if _, _, err := ctx.Slack().DeleteMessageContext(ctx, channelID, ts); err != nil {
+		return fmt.Errorf("failed to delete reply %s in %s: %w", ts, channelID, err)
+	}
+
+	ctx.Logger().Info().
+		Str("channel_id", channelID).
+		Str("reply_ts", ts).
+		Str("user_id", userID).
+		Bool("by_author", userID == author).
+		Msg("deleted reply")
+
+	return us.Forget(ctx, channelID, ts)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"github.com/gobridge/gopherbot/internal/unfurl"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// unfurlHandler renders the previews for links to pkg.go.dev, the playground,
+// and CLs. A link that can't be rendered is left for Slack to unfurl as it
+// would any other.
+func unfurlHandler(shadowMode bool, r *unfurl.Renderer) workqueue.LinkSharedHandler {
+	return func(ctx workqueue.Context, ls *slackevents.LinkSharedEvent) (bool, bool, error) {
+		unfurls := make(map[string]slack.Attachment, len(ls.Links))
+
+		for _, sl := range ls.Links {
+			l, ok := unfurl.Parse(sl.URL)
+			if !ok {
+				continue
+			}
+
+			a, err := r.Render(ctx, l)
+			if err != nil {
+				ctx.Logger().Warn().
+					Err(err).
+					Str("url", sl.URL).
+					Msg("failed to render unfurl")
+
+				continue
+			}
+
+			unfurls[sl.URL] = a
+		}
+
+		if len(unfurls) == 0 {
+			return false, false, nil
+		}
+
+		if shadowMode {
+			ctx.Logger().Info().
+				Str("channel_id", ls.Channel).
+				Int("unfurls", len(unfurls)).
+				Msg("would unfurl links")
+
+			return false, false, nil
+		}
+
+		_, _, _, err := ctx.Slack().SendMessageContext(ctx, ls.Channel, slack.MsgOptionUnfurl(ls.MessageTimeStamp.String(), unfurls))
+		if err != nil {
+			return false, false, err
+		}
+
+		return false, false, nil
+	}
+}
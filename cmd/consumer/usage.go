@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/usage"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// usageStatsWindow is how far back the stats commands command looks.
+const usageStatsWindow = 30 * 24 * time.Hour
+
+// saveTriggers saves the registered triggers, so the ones that are never used
+// can be reported. It must be called after registering the handlers.
+func saveTriggers(ctx context.Context, ma *handler.MessageActions, us *usage.Store) error {
+	hs := ma.Registered()
+
+	triggers := make([]string, len(hs))
+	for i, h := range hs {
+		triggers[i] = strings.TrimSpace(h.Trigger)
+	}
+
+	return us.SetTriggers(ctx, triggers)
+}
+
+// injectUsageHandlers registers the command Workspace Admins use to see how
+// often each command has been used recently.
+func injectUsageHandlers(ma *handler.MessageActions, us *usage.Store) {
+	ma.Handle("stats commands", "(admins only) show how often each command was used in the last 30 days", nil,
+		func(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can see the command stats.")
+			}
+
+			now := time.Now()
+
+			counts, err := us.Counts(ctx, now.Add(-usageStatsWindow), now)
+			if err != nil {
+				return fmt.Errorf("failed to get usage counts: %w", err)
+			}
+
+			triggers, err := us.Triggers(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get triggers: %w", err)
+			}
+
+			return r.RespondEphemeral(ctx, usage.FormatReport("Command usage for the last 30 days", counts, triggers))
+		},
+	)
+}
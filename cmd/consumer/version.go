@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/version"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const versionUsage = "Usage: `version` shows the commit each of my components is running, when it was built, and how long it's been up."
+
+// injectVersionHandlers registers the command reporting which version of each
+// component is running.
+func injectVersionHandlers(ma *handler.MessageActions, vr *version.Registry) {
+	ma.HandleCommand("version", versionUsage, "shows which version of me is running, and for how long",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			procs, err := vr.All(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get running versions: %w", err)
+			}
+
+			// this process is running, even if it hasn't been recorded yet
+			if len(procs) == 0 {
+				procs = []version.Info{vr.Self()}
+			}
+
+			return r.RespondTextAttachment(ctx, "Here's what's running:", formatVersions(procs))
+		},
+	)
+}
+
+func formatVersions(procs []version.Info) string {
+	var b strings.Builder
+
+	for _, p := range procs {
+		fmt.Fprintf(&b, "%s (%s): %s", p.Component, p.DynoID, p.ShortCommit())
+
+		if !p.ReleasedAt.IsZero() {
+			fmt.Fprintf(&b, ", built %s", p.ReleasedAt.UTC().Format("2006-01-02 15:04 MST"))
+		}
+
+		fmt.Fprintf(&b, ", up %s\n", formatUptime(p.Uptime()))
+	}
+
+	return b.String()
+}
+
+// formatUptime returns the duration in days, hours, and minutes.
+func formatUptime(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+
+	hours := d / time.Hour
+	d -= hours * time.Hour
+
+	minutes := d / time.Minute
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// announceDeploy posts in the dev channel if this process is the first to
+// start with a new commit, so the maintainers know it's live.
+func announceDeploy(ctx context.Context, vr *version.Registry, nr *notify.Router, channelID string, logger zerolog.Logger) {
+	previous, isNew, err := vr.Deployed(ctx)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to check for a new deploy")
+
+		return
+	}
+
+	if !isNew {
+		return
+	}
+
+	self := vr.Self()
+
+	logger.Info().
+		Str("previous", previous).
+		Str("commit", self.Commit).
+		Msg("new version deployed")
+
+	if len(channelID) == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf(":rocket: Version `%s` is live, replacing `%s`.", self.ShortCommit(), version.Info{Commit: previous}.ShortCommit())
+
+	_, err = nr.NotifyChannel(ctx, channelID, notify.Notification{
+		Source:   notify.Deploys,
+		Severity: notify.Info,
+		Summary:  fmt.Sprintf("version %s deployed", self.ShortCommit()),
+		Options: []slack.MsgOption{
+			slack.MsgOptionText(msg, false),
+		},
+	})
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to announce new deploy")
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/poller/workshops"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+// workshopStartLayout is how the start of a submitted workshop is written, in
+// UTC.
+const workshopStartLayout = "2006-01-02T15:04"
+
+const workshopUsage = "To submit a GoBridge or GDI workshop to be announced in #remotemeetup and #general, use `workshop submit \"<title>\" <YYYY-MM-DDTHH:MM> <url>`, with the start time in UTC, like `workshop submit \"Intro to Go\" 2026-11-07T17:00 https://gobridge.org/workshops/intro --organizer=GDI --location=Online`. " +
+	"It's announced two weeks before it starts, once a Workspace Admin approves it. " +
+	"Admins can see the submissions waiting with `workshop queue`, the approved ones with `workshop list`, and review one with `workshop approve <id>` or `workshop reject <id>`."
+
+// injectWorkshopHandlers registers the command organizers use to submit
+// workshops, and Workspace Admins to review them, which bgtasks announces.
+// New submissions are sent to the moderators to review.
+func injectWorkshopHandlers(ma *handler.MessageActions, ws *workshops.Store, nr *notify.Router) {
+	ma.HandleCommand("workshop", workshopUsage, "submit a GoBridge or GDI workshop to be announced",
+		func(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+			if !m.BotMentioned() && m.ChannelType() != handler.ChannelDM {
+				return nil
+			}
+
+			sub := strings.ToLower(c.Arg(0))
+
+			if sub == "submit" {
+				return submitWorkshop(ctx, m, c, r, ws, nr)
+			}
+
+			if sub != "queue" && sub != "list" && sub != "approve" && sub != "reject" {
+				return &handler.UsageError{}
+			}
+
+			admin, err := handler.IsAdmin(ctx, m.UserID())
+			if err != nil {
+				return err
+			}
+
+			if !admin {
+				return r.RespondEphemeral(ctx, "Sorry, only Workspace Admins can review workshops.")
+			}
+
+			switch sub {
+			case "queue":
+				return listWorkshops(ctx, r, ws, workshops.Pending)
+
+			case "list":
+				return listWorkshops(ctx, r, ws, workshops.Approved)
+
+			default:
+				return reviewWorkshop(ctx, m, c, r, ws, sub)
+			}
+		},
+	)
+}
+
+func submitWorkshop(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder, ws *workshops.Store, nr *notify.Router) error {
+	if len(c.Args) != 4 {
+		return handler.Usagef("I need the workshop's title, start time, and link")
+	}
+
+	start, err := time.Parse(workshopStartLayout, c.Arg(2))
+	if err != nil {
+		return handler.Usagef("I couldn't understand the start time %q", c.Arg(2))
+	}
+
+	if !start.After(time.Now()) {
+		return handler.Usagef("the workshop needs to start in the future")
+	}
+
+	link := strings.Trim(c.Arg(3), "<>")
+
+	if u, err := url.Parse(link); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return handler.Usagef("I need a link to the workshop, like its sign up page")
+	}
+
+	organizer, _ := c.Flag("organizer")
+	location, _ := c.Flag("location")
+
+	s, err := ws.Submit(ctx, workshops.Workshop{
+		Title:     c.Arg(1),
+		URL:       link,
+		Organizer: organizer,
+		Location:  location,
+		Start:     start,
+	}, m.UserID())
+	if err != nil {
+		return fmt.Errorf("failed to submit workshop: %w", err)
+	}
+
+	_, err = nr.Notify(ctx, notify.Notification{
+		Source:   notify.Moderation,
+		Severity: notify.Important,
+		Summary:  fmt.Sprintf("workshop %d submitted", s.ID),
+		Options: []slack.MsgOption{
+			slack.MsgOptionDisableLinkUnfurl(),
+			slack.MsgOptionText(fmt.Sprintf(":gopher: <@%s> submitted a workshop to announce: %s\nUse `workshop approve %d` or `workshop reject %d` to review it.",
+				m.UserID(), describeSubmission(s), s.ID, s.ID), false),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send workshop submission to moderators: %w", err)
+	}
+
+	return r.RespondEphemeral(ctx, fmt.Sprintf("Thanks! Workshop %d is waiting for a Workspace Admin to review it, and will be announced two weeks before it starts once it's approved.", s.ID))
+}
+
+func reviewWorkshop(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder, ws *workshops.Store, sub string) error {
+	id, err := strconv.ParseInt(c.Arg(1), 10, 64)
+	if err != nil || len(c.Args) != 2 {
+		return handler.Usagef("I need the ID of the workshop to %s", sub)
+	}
+
+	status := workshops.Approved
+	if sub == "reject" {
+		status = workshops.Rejected
+	}
+
+	s, err := ws.Review(ctx, id, status, m.UserID())
+	if errors.Is(err, workshops.ErrNotFound) {
+		return r.RespondEphemeral(ctx, fmt.Sprintf("There's no workshop %d.", id))
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to review workshop: %w", err)
+	}
+
+	return r.RespondEphemeral(ctx, fmt.Sprintf("Okay, workshop %d from <@%s> is %s.", s.ID, s.SubmittedBy, s.Status))
+}
+
+func listWorkshops(ctx workqueue.Context, r handler.Responder, ws *workshops.Store, status workshops.Status) error {
+	subs, err := ws.List(ctx, status)
+	if err != nil {
+		return fmt.Errorf("failed to list workshops: %w", err)
+	}
+
+	if len(subs) == 0 {
+		return r.RespondEphemeral(ctx, fmt.Sprintf("There are no %s workshops.", status))
+	}
+
+	b := &strings.Builder{}
+
+	for _, s := range subs {
+		fmt.Fprintf(b, "- %d: %s, by <@%s>\n", s.ID, describeSubmission(s), s.SubmittedBy)
+	}
+
+	return r.RespondEphemeral(ctx, b.String())
+}
+
+func describeSubmission(s workshops.Submission) string {
+	w := s.Workshop
+
+	d := fmt.Sprintf("<%s|%s>", w.URL, w.Title)
+
+	if len(w.Organizer) > 0 {
+		d += " by " + w.Organizer
+	}
+
+	if len(w.Location) > 0 {
+		d += " (" + w.Location + ")"
+	}
+
+	return d + " on " + w.Start.UTC().Format("Jan 2, 2006 at 15:04 UTC")
+}
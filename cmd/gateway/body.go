@@ -0,0 +1,111 @@
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// maxBodySize is the most a request body can be, after it's decoded. Slack's
+// events are nowhere near it, so anything larger isn't from Slack.
+const maxBodySize = 2 * 1024 * 1024 // 2 MB
+
+var (
+	// errBodyTooLarge is returned by readBody when the body, or its decoded
+	// stream, is larger than maxBodySize.
+	errBodyTooLarge = errors.New("request body too large")
+
+	// errUnsupportedEncoding is returned by readBody when the body has a
+	// Content-Encoding we can't decode.
+	errUnsupportedEncoding = errors.New("unsupported content encoding")
+)
+
+// supportedEncodings is the Accept-Encoding sent with a 415 for an unsupported
+// Content-Encoding.
+const supportedEncodings = "gzip, identity"
+
+// readBody reads the request body, transparently decoding it if it's gzipped
+// (as some proxies do) and enforcing maxBodySize on the decoded stream, so a
+// small compressed body can't expand into something huge.
+//
+// After it's decoded the Content-Encoding header is removed, so the handlers
+// reading the body again after the middleware don't decode it twice.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.ContentLength > maxBodySize {
+		return nil, errBodyTooLarge
+	}
+
+	var rdr io.Reader = r.Body
+
+	switch enc := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); enc {
+	case "", "identity":
+
+	case "gzip", "x-gzip":
+		gr, err := gzip.NewReader(io.LimitReader(r.Body, maxBodySize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip header: %w", err)
+		}
+
+		defer func() { _ = gr.Close() }()
+
+		rdr = gr
+
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedEncoding, enc)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(rdr, maxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	if len(body) > maxBodySize {
+		return nil, errBodyTooLarge
+	}
+
+	r.Header.Del("Content-Encoding")
+
+	return body, nil
+}
+
+// writeBodyError logs the error from readBody, and responds with the matching
+// status code.
+func writeBodyError(w http.ResponseWriter, err error, logger zerolog.Logger) {
+	switch {
+	case errors.Is(err, errBodyTooLarge):
+		logger.Warn().
+			Err(err).
+			Int("max_body_size", maxBodySize).
+			Msg("request body too large")
+
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+
+	case errors.Is(err, errUnsupportedEncoding):
+		logger.Warn().
+			Err(err).
+			Msg("request body has unsupported encoding")
+
+		w.Header().Set("Accept-Encoding", supportedEncodings)
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+
+	default:
+		logger.Error().
+			Err(err).
+			Msg("failed to read request body")
+
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// utf8Charset returns whether the Content-Type parameters are for UTF-8, which
+// is all Slack sends. No charset is taken to be UTF-8.
+func utf8Charset(params map[string]string) bool {
+	cs, ok := params["charset"]
+	return !ok || strings.EqualFold(cs, "utf-8") || strings.EqualFold(cs, "utf8")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	gw := gzip.NewWriter(&buf)
+
+	if _, err := gw.Write(b); err != nil {
+		t.Fatalf("failed to gzip body: %v", err)
+	}
+
+	if err := gw.Close(); err != nil {
+		t.Fatalf("failed to gzip body: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func Test_readBody(t *testing.T) {
+	const doc = `{"type":"event_callback"}`
+
+	large := bytes.Repeat([]byte("a"), maxBodySize+1)
+
+	tests := []struct {
+		name     string
+		body     []byte
+		encoding string
+		want     string
+		err      error
+	}{
+		{
+			name: "plain",
+			body: []byte(doc),
+			want: doc,
+		},
+		{
+			name:     "identity",
+			body:     []byte(doc),
+			encoding: "identity",
+			want:     doc,
+		},
+		{
+			name:     "gzip",
+			body:     gzipped(t, []byte(doc)),
+			encoding: "gzip",
+			want:     doc,
+		},
+		{
+			name:     "x-gzip",
+			body:     gzipped(t, []byte(doc)),
+			encoding: "X-Gzip",
+			want:     doc,
+		},
+		{
+			name: "too_large",
+			body: large,
+			err:  errBodyTooLarge,
+		},
+		{
+			name:     "gzip_decodes_too_large",
+			body:     gzipped(t, large),
+			encoding: "gzip",
+			err:      errBodyTooLarge,
+		},
+		{
+			name:     "zstd",
+			body:     []byte(doc),
+			encoding: "zstd",
+			err:      errUnsupportedEncoding,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/slack/event", bytes.NewReader(tt.body))
+			if len(tt.encoding) > 0 {
+				r.Header.Set("Content-Encoding", tt.encoding)
+			}
+
+			got, err := readBody(r)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Fatalf("body = %q, want %q", got, tt.want)
+			}
+
+			if enc := r.Header.Get("Content-Encoding"); len(enc) > 0 {
+				t.Fatalf("Content-Encoding = %q, want it removed", enc)
+			}
+		})
+	}
+
+	t.Run("corrupt_gzip", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/slack/event", strings.NewReader(doc))
+		r.Header.Set("Content-Encoding", "gzip")
+
+		_, err := readBody(r)
+		if err == nil || errors.Is(err, errBodyTooLarge) || errors.Is(err, errUnsupportedEncoding) {
+			t.Fatalf("err = %v, want a read error", err)
+		}
+	})
+}
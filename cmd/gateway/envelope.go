@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// The types of envelope the Events API sends.
+const (
+	envelopeURLVerification = "url_verification"
+	envelopeEventCallback   = "event_callback"
+	envelopeAppRateLimited  = "app_rate_limited"
+)
+
+// The versions of the Events API envelope schema. Slack replaced the
+// authed_users and authed_teams fields, listing who could see the event, with
+// authorizations, listing the installations the event was delivered for, and
+// event_context, for listing any others.
+const (
+	schemaUnknown = iota
+	schemaLegacy
+	schemaAuthorizations
+)
+
+var (
+	// eventsUnknown counts the events of types the gateway doesn't handle,
+	// by type, which are acknowledged without being published.
+	eventsUnknown = expvar.NewMap("gateway_events_unknown")
+
+	// eventsRateLimited counts the app_rate_limited notices from Slack,
+	// which mean it's dropping events it would have sent.
+	eventsRateLimited = expvar.NewInt("gateway_events_rate_limited")
+)
+
+// envelope is the outer JSON document of an Events API request. Fields it
+// doesn't have are ignored.
+type envelope struct {
+	Token    string `json:"token"`
+	Type     string `json:"type"`
+	TeamID   string `json:"team_id"`
+	APIAppID string `json:"api_app_id"`
+
+	// Challenge is set for url_verification requests.
+	Challenge string `json:"challenge"`
+
+	// MinuteRateLimited is the minute Slack started dropping events, as a
+	// Unix timestamp, for app_rate_limited requests.
+	MinuteRateLimited int64 `json:"minute_rate_limited"`
+
+	// The rest are set for event_callback requests.
+	EventID        string          `json:"event_id"`
+	EventTime      int64           `json:"event_time"`
+	EventContext   string          `json:"event_context"`
+	Authorizations []authorization `json:"authorizations"`
+	AuthedUsers    []string        `json:"authed_users"`
+	Event          json.RawMessage `json:"event"`
+}
+
+// authorization is an installation of the app an event was delivered for.
+// Slack includes at most one; the rest can be listed with the envelope's
+// event_context.
+type authorization struct {
+	EnterpriseID        string `json:"enterprise_id"`
+	TeamID              string `json:"team_id"`
+	UserID              string `json:"user_id"`
+	IsBot               bool   `json:"is_bot"`
+	IsEnterpriseInstall bool   `json:"is_enterprise_install"`
+}
+
+// innerEvent is the part of an event_callback's event the gateway needs to
+// route it. The event is published as Slack sent it.
+type innerEvent struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"`
+	ChannelType string `json:"channel_type"`
+	Text        string `json:"text"`
+}
+
+// parseEnvelope unmarshals the request body, without checking it has the
+// fields its type needs.
+func parseEnvelope(body []byte) (*envelope, error) {
+	var env envelope
+
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON document: %w", err)
+	}
+
+	return &env, nil
+}
+
+// decodeEnvelope unmarshals the request body, and checks it has the fields its
+// type needs. The inner event is decoded too, for event_callback requests.
+func decodeEnvelope(body []byte) (*envelope, innerEvent, error) {
+	env, err := parseEnvelope(body)
+	if err != nil {
+		return nil, innerEvent{}, err
+	}
+
+	switch env.Type {
+	case "":
+		return nil, innerEvent{}, errors.New("failed to get type field: key does not exist")
+
+	case envelopeURLVerification:
+		if len(env.Challenge) == 0 {
+			return nil, innerEvent{}, errors.New("failed to get challenge field: key does not exist")
+		}
+
+		return env, innerEvent{}, nil
+
+	case envelopeEventCallback:
+		// checked below
+
+	default:
+		return env, innerEvent{}, nil
+	}
+
+	if len(env.EventID) == 0 {
+		return nil, innerEvent{}, errors.New("failed to get event_id field: key does not exist")
+	}
+
+	if env.EventTime <= 0 {
+		return nil, innerEvent{}, errors.New("failed to get event_time field: key does not exist")
+	}
+
+	if env.schemaVersion() == schemaUnknown {
+		return nil, innerEvent{}, errors.New("unsupported schema: neither authorizations nor authed_users field exists")
+	}
+
+	if len(env.Event) == 0 || string(env.Event) == "null" {
+		return nil, innerEvent{}, errors.New("failed to get event field: key does not exist")
+	}
+
+	var ev innerEvent
+
+	if err := json.Unmarshal(env.Event, &ev); err != nil {
+		return nil, innerEvent{}, fmt.Errorf("failed to unmarshal event field: %w", err)
+	}
+
+	if len(ev.Type) == 0 {
+		return nil, innerEvent{}, errors.New("failed to get event type field: key does not exist")
+	}
+
+	return env, ev, nil
+}
+
+// schemaVersion returns which version of the schema the event_callback
+// envelope is, from the fields saying who the event was delivered for.
+func (e *envelope) schemaVersion() int {
+	switch {
+	case e.Authorizations != nil:
+		return schemaAuthorizations
+	case e.AuthedUsers != nil:
+		return schemaLegacy
+	default:
+		return schemaUnknown
+	}
+}
+
+// botUserIDs returns the bot's user IDs the event was delivered for, from the
+// authorizations in the event callback.
+func (e *envelope) botUserIDs() []string {
+	var ids []string
+
+	for _, a := range e.Authorizations {
+		if a.IsBot && len(a.UserID) > 0 {
+			ids = append(ids, a.UserID)
+		}
+	}
+
+	return ids
+}
+
+// channelMessageEvent returns the Event for a message in a public channel,
+// which is prioritized if it mentions the bot, as that's how commands are
+// given in channels.
+func channelMessageEvent(ev innerEvent, botIDs []string) workqueue.Event {
+	for _, id := range botIDs {
+		if strings.Contains(ev.Text, "<@"+id+">") || strings.Contains(ev.Text, "<@"+id+"|") {
+			return workqueue.SlackMessageMention
+		}
+	}
+
+	return workqueue.SlackMessageChannel
+}
+
+// wqEventType returns the workqueue Event for the inner event, and false if
+// it's of a type the gateway doesn't handle.
+func wqEventType(ev innerEvent, botIDs []string) (workqueue.Event, bool) {
+	switch ev.Type {
+	case "message":
+		switch ev.Subtype {
+		case "channel_topic", "channel_purpose", "group_topic", "group_purpose":
+			return workqueue.SlackChannelTopic, true
+		}
+
+		switch ev.ChannelType {
+		case "app_home":
+			return workqueue.SlackMessageAppHome, true
+		case "group":
+			return workqueue.SlackMessageGroup, true
+		case "im":
+			return workqueue.SlackMessageIM, true
+		case "mpim":
+			return workqueue.SlackMessageMPIM, true
+		default:
+			return channelMessageEvent(ev, botIDs), true
+		}
+
+	case "team_join":
+		return workqueue.SlackTeamJoin, true
+
+	case "member_joined_channel":
+		return workqueue.SlackChannelJoin, true
+
+	case "reaction_added":
+		return workqueue.SlackReactionAdded, true
+
+	case "channel_created", "channel_rename", "channel_archive", "channel_unarchive":
+		return workqueue.SlackChannelChange, true
+
+	case "link_shared":
+		return workqueue.SlackLinkShared, true
+
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+func Test_decodeEnvelope(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		schema int
+		ev     innerEvent
+		err    bool
+	}{
+		{
+			name: "url_verification",
+			body: `{"token":"t","type":"url_verification","challenge":"abc"}`,
+		},
+		{
+			name: "url_verification_without_challenge",
+			body: `{"token":"t","type":"url_verification"}`,
+			err:  true,
+		},
+		{
+			name:   "event_callback",
+			body:   `{"type":"event_callback","event_id":"Ev1","event_time":1234,"event_context":"4-abc","event":{"type":"message","channel_type":"channel","text":"hi","blocks":[]},"authorizations":[{"user_id":"U1","is_bot":true}],"is_ext_shared_channel":false}`,
+			schema: schemaAuthorizations,
+			ev:     innerEvent{Type: "message", ChannelType: "channel", Text: "hi"},
+		},
+		{
+			name:   "legacy_event_callback",
+			body:   `{"type":"event_callback","event_id":"Ev1","event_time":1234,"event":{"type":"team_join"},"authed_users":["U1"]}`,
+			schema: schemaLegacy,
+			ev:     innerEvent{Type: "team_join"},
+		},
+		{
+			name: "unknown_schema",
+			body: `{"type":"event_callback","event_id":"Ev1","event_time":1234,"event":{"type":"team_join"}}`,
+			err:  true,
+		},
+		{
+			name: "missing_event_id",
+			body: `{"type":"event_callback","event_time":1234,"event":{"type":"team_join"},"authorizations":[]}`,
+			err:  true,
+		},
+		{
+			name: "string_event_time",
+			body: `{"type":"event_callback","event_id":"Ev1","event_time":"1234","event":{"type":"team_join"},"authorizations":[]}`,
+			err:  true,
+		},
+		{
+			name: "null_event",
+			body: `{"type":"event_callback","event_id":"Ev1","event_time":1234,"event":null,"authorizations":[]}`,
+			err:  true,
+		},
+		{
+			name: "array_event",
+			body: `{"type":"event_callback","event_id":"Ev1","event_time":1234,"event":[],"authorizations":[]}`,
+			err:  true,
+		},
+		{
+			name: "event_without_type",
+			body: `{"type":"event_callback","event_id":"Ev1","event_time":1234,"event":{},"authorizations":[]}`,
+			err:  true,
+		},
+		{
+			name: "unknown_envelope_type",
+			body: `{"type":"app_uninstalled_v2"}`,
+		},
+		{
+			name: "missing_type",
+			body: `{"token":"t"}`,
+			err:  true,
+		},
+		{
+			name: "not_an_object",
+			body: `[]`,
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, ev, err := decodeEnvelope([]byte(tt.body))
+			if (err != nil) != tt.err {
+				t.Fatalf("decodeEnvelope() error = %v, want error %t", err, tt.err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if env.Type == envelopeEventCallback {
+				if got := env.schemaVersion(); got != tt.schema {
+					t.Errorf("schemaVersion() = %d, want %d", got, tt.schema)
+				}
+			}
+
+			if ev != tt.ev {
+				t.Errorf("event = %+v, want %+v", ev, tt.ev)
+			}
+		})
+	}
+}
+
+func Test_wqEventType(t *testing.T) {
+	botIDs := []string{"UBOT"}
+
+	tests := []struct {
+		name string
+		ev   innerEvent
+		want workqueue.Event
+		ok   bool
+	}{
+		{name: "channel", ev: innerEvent{Type: "message", ChannelType: "channel", Text: "hi"}, want: workqueue.SlackMessageChannel, ok: true},
+		{name: "no_channel_type", ev: innerEvent{Type: "message"}, want: workqueue.SlackMessageChannel, ok: true},
+		{name: "mention", ev: innerEvent{Type: "message", ChannelType: "channel", Text: "<@UBOT> help"}, want: workqueue.SlackMessageMention, ok: true},
+		{name: "mention_with_name", ev: innerEvent{Type: "message", Text: "<@UBOT|gopher> help"}, want: workqueue.SlackMessageMention, ok: true},
+		{name: "im", ev: innerEvent{Type: "message", ChannelType: "im"}, want: workqueue.SlackMessageIM, ok: true},
+		{name: "topic", ev: innerEvent{Type: "message", Subtype: "channel_topic", ChannelType: "channel"}, want: workqueue.SlackChannelTopic, ok: true},
+		{name: "team_join", ev: innerEvent{Type: "team_join"}, want: workqueue.SlackTeamJoin, ok: true},
+		{name: "unknown", ev: innerEvent{Type: "pin_added"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := wqEventType(tt.ev, botIDs)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("wqEventType() = %q, %t, want %q, %t", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
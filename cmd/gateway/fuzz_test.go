@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+)
+
+// FuzzEventExtraction makes sure no event body, however malformed, panics while
+// pulling the values the gateway needs out of it.
+func FuzzEventExtraction(f *testing.F) {
+	seeds := []string{
+		`{"type":"url_verification","challenge":"abc"}`,
+		`{"type":"event_callback","event_id":"Ev1","event_time":1234,"event":{"type":"message","channel_type":"channel","text":"hi <@U1>"},"authorizations":[{"user_id":"U1","is_bot":true}]}`,
+		`{"type":"event_callback","event_id":"Ev2","event_time":"1234","event":{"type":"reaction_added"}}`,
+		`{"type":"event_callback","event":{"type":"message","subtype":"bot_message"},"authorizations":[{"is_bot":"yes"}]}`,
+		`{"type":1,"event":[],"authorizations":{}}`,
+		`[]`,
+	}
+
+	for _, s := range seeds {
+		f.Add([]byte(s))
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		env, ev, err := decodeEnvelope(body)
+		if err != nil {
+			return
+		}
+
+		_, _ = wqEventType(ev, env.botUserIDs())
+	})
+}
+
+// FuzzInteractionExtraction is FuzzEventExtraction for interactivity requests,
+// where the JSON document is form encoded.
+func FuzzInteractionExtraction(f *testing.F) {
+	seeds := []string{
+		`payload=%7B%22type%22%3A%22block_actions%22%2C%22trigger_id%22%3A%22T1%22%2C%22actions%22%3A%5B%7B%22action_ts%22%3A%221548426417.840180%22%7D%5D%7D`,
+		`payload=%7B%22action_ts%22%3A%22.%22%7D`,
+		`payload=`,
+		`%zz`,
+	}
+
+	for _, s := range seeds {
+		f.Add([]byte(s))
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		document, err := interactionPayload(body)
+		if err != nil {
+			return
+		}
+
+		_, _ = interactionValues(document)
+	})
+}
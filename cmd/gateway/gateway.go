@@ -10,10 +10,16 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/go-redis/redis"
 	"github.com/gobridge/gopherbot/config"
+	"github.com/gobridge/gopherbot/internal/eventarchive"
 	"github.com/gobridge/gopherbot/internal/heartbeat"
+	"github.com/gobridge/gopherbot/internal/idempotency"
+	"github.com/gobridge/gopherbot/internal/oauth"
+	"github.com/gobridge/gopherbot/internal/status"
+	"github.com/gobridge/gopherbot/internal/version"
+	"github.com/gobridge/gopherbot/signing"
 	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 )
 
@@ -42,11 +48,13 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 	lhb := logger.With().Str("context", "heartbeater").Logger()
 
 	// start checking Redis health
-	_, err := heartbeat.New(ctx, heartbeat.Config{
+	hb, err := heartbeat.New(ctx, heartbeat.Config{
 		RedisClient: rc,
 		Logger:      lhb,
 		AppName:     cfg.Heroku.AppName,
 		UID:         cfg.Heroku.DynoID,
+		Component:   "gateway",
+		Commit:      cfg.Heroku.Commit,
 		Warn:        4 * time.Second,
 		Fail:        8 * time.Second,
 	})
@@ -62,6 +70,18 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 		return fmt.Errorf("failed to heartbeat: %w", err)
 	}
 
+	vr, err := version.NewRegistry(rc, version.Info{
+		Component:  "gateway",
+		DynoID:     cfg.Heroku.DynoID,
+		Commit:     cfg.Heroku.Commit,
+		ReleasedAt: cfg.Heroku.ReleasedAt,
+	}, logger.With().Str("context", "version").Logger())
+	if err != nil {
+		return fmt.Errorf("failed to build version registry: %w", err)
+	}
+
+	go vr.Run(ctx)
+
 	// set up the workqueue
 	q, err := workqueue.New(workqueue.Config{
 		ConsumerName:      cfg.Heroku.DynoID,
@@ -69,15 +89,65 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 		VisibilityTimeout: 10 * time.Second,
 		RedisClient:       rc,
 		Logger:            &logger,
+		Tracer:            config.DefaultTracer(ctx, cfg, "gopherbot-gateway", logger),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to build workqueue: %w", err)
 	}
 
+	var accepted *idempotency.Store
+
+	if cfg.Gateway.SkipRetries {
+		if accepted, err = idempotency.NewStore(rc); err != nil {
+			return fmt.Errorf("failed to build idempotency store: %w", err)
+		}
+	}
+
+	pub := newAsyncPublisher(q, accepted)
+
+	ss := status.New(cfg.Heroku.AppName, cfg.Heroku.Commit, logger.With().Str("context", "status_server").Logger())
+	ss.Register("heartbeat", status.Heartbeat(hb))
+	ss.Register("publisher", publisherCheck(pub))
+
+	if cfg.StatusPort > 0 {
+		go func() {
+			if err := ss.Run(ctx, cfg.StatusPort); err != nil {
+				logger.Error().
+					Err(err).
+					Msg("status server failed")
+			}
+		}()
+	} else {
+		logger.Info().Msg("no status port configured: not serving status")
+	}
+
 	// set up the handler
 	hnd := handler{
 		l: &logger,
-		q: q,
+		p: pub,
+	}
+
+	if len(cfg.Gateway.ArchiveURL) > 0 {
+		bucket, err := eventarchive.ParseURL(cfg.Gateway.ArchiveURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to parse archive URL: %w", err)
+		}
+
+		hnd.a, err = eventarchive.New(eventarchive.Config{
+			Storage:   bucket,
+			Prefix:    bucket.Prefix(),
+			Name:      cfg.Heroku.DynoID,
+			Scrub:     cfg.Gateway.ArchiveScrub,
+			Retention: cfg.Gateway.ArchiveRetention,
+			Logger:    logger.With().Str("context", "event_archive").Logger(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build event archiver: %w", err)
+		}
+
+		go hnd.a.Run(ctx)
+	} else {
+		logger.Info().Msg("no archive URL configured: not archiving events")
 	}
 
 	// set up the router
@@ -85,17 +155,93 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 	mux.HandleFunc("/", hnd.handleNotFound)
 	mux.HandleFunc("/_ruok", hnd.handleRUOK)
 
+	// while the signing secret is being rotated, accept requests signed with
+	// either the new or the previous one
+	sv := signing.Validator{
+		Secrets: []string{cfg.Slack.RequestSecret, cfg.Slack.PreviousRequestSecret},
+		MaxSkew: cfg.Slack.RequestMaxSkew,
+	}
+
 	// wrap our slack event handler in the slackSignature middleware.
 	// wrap the slackSignature middleware in the context / heroku header middleware
 	slackHandler := chMiddlewareFactory(
 		logger,
 		slackSignatureMiddlewareFactory(
-			cfg.Slack.RequestSecret, cfg.Slack.RequestToken, cfg.Slack.AppID, cfg.Slack.TeamID, &logger, hnd.handleSlackEvent,
+			sv, cfg.Slack.RequestToken, cfg.Slack.AppID, cfg.Slack.TeamID, &logger, hnd.handleSlackEvent,
 		),
 	)
 
 	mux.HandleFunc("/slack/event", slackHandler)
 
+	// interactivity requests (button clicks, menus, etc.) are form-encoded
+	// so they get their own signature middleware
+	interactionHandler := chMiddlewareFactory(
+		logger,
+		slackInteractionMiddlewareFactory(
+			sv, cfg.Slack.RequestToken, cfg.Slack.AppID, cfg.Slack.TeamID, &logger, hnd.handleSlackInteraction,
+		),
+	)
+
+	mux.HandleFunc("/slack/interactive", interactionHandler)
+
+	// slash commands are plain forms, rather than a JSON document in one
+	commandHandler := chMiddlewareFactory(
+		logger,
+		slackCommandMiddlewareFactory(
+			sv, cfg.Slack.RequestToken, cfg.Slack.AppID, cfg.Slack.TeamID, &logger, hnd.handleSlackCommand,
+		),
+	)
+
+	mux.HandleFunc("/slack/command", commandHandler)
+
+	// the OAuth flow for installing the app in new workspaces needs the app's
+	// client credentials, and keys to encrypt the tokens it receives, so only
+	// serve it if they're configured
+	switch {
+	case len(cfg.Slack.ClientID) == 0 || len(cfg.Slack.ClientSecret) == 0:
+		logger.Info().Msg("no Slack client credentials: not serving OAuth installation")
+
+	case cfg.Secrets.Keyring == nil:
+		logger.Warn().Msg("no secrets keys to encrypt bot tokens: not serving OAuth installation")
+
+	default:
+		ostore, err := oauth.NewStore(rc, cfg.Secrets.Keyring)
+		if err != nil {
+			return fmt.Errorf("failed to build OAuth store: %w", err)
+		}
+
+		// after a key rotation, move the stored tokens to the new primary key
+		go func() {
+			n, err := ostore.Reencrypt(ctx)
+			if err != nil {
+				logger.Error().
+					Err(err).
+					Int("reencrypted", n).
+					Msg("failed to re-encrypt bot tokens")
+
+				return
+			}
+
+			if n > 0 {
+				logger.Info().
+					Int("reencrypted", n).
+					Str("key_id", cfg.Secrets.Keyring.Primary()).
+					Msg("re-encrypted bot tokens with primary key")
+			}
+		}()
+
+		oh := &oauthHandler{
+			l:            &logger,
+			store:        ostore,
+			clientID:     cfg.Slack.ClientID,
+			clientSecret: cfg.Slack.ClientSecret,
+			http:         &http.Client{Timeout: 10 * time.Second},
+		}
+
+		mux.HandleFunc("/slack/install", oh.handleInstall)
+		mux.HandleFunc("/slack/oauth", oh.handleCallback)
+	}
+
 	socketAddr := fmt.Sprintf("0.0.0.0:%d", cfg.Port)
 	logger.Info().
 		Str("addr", socketAddr).
@@ -144,6 +290,22 @@ func runServer(cfg config.C, logger zerolog.Logger) error {
 				Err(shutdownErr).
 				Msg("failed to gracefully shut down HTTP server")
 		}
+
+		// publish the events we've already acknowledged
+		if err := pub.shutdown(cctx); err != nil {
+			logger.Error().
+				Err(err).
+				Msg("failed to publish all acknowledged events before shutting down")
+		}
+
+		// and archive the rest of the batch
+		if hnd.a != nil {
+			if err := hnd.a.Flush(cctx); err != nil {
+				logger.Error().
+					Err(err).
+					Msg("failed to archive events before shutting down")
+			}
+		}
 	}()
 
 	// wait for it to die
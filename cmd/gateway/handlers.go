@@ -1,22 +1,34 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gobridge/gopherbot/internal/eventarchive"
 	"github.com/gobridge/gopherbot/workqueue"
 	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
 	"github.com/valyala/fastjson"
 )
 
-const maxBodySize = 2 * 1024 * 1024 // 2 MB
+// acceptedTTL is how long the events we've published are remembered, so
+// Slack's retries of them can be skipped. Slack retries three times, over
+// about five minutes.
+const acceptedTTL = time.Hour
 
 type handler struct {
 	l *zerolog.Logger
-	q workqueue.Q
+	p *asyncPublisher
+
+	// a archives the raw events, and is nil if they aren't archived
+	a *eventarchive.Archiver
 }
 
 func (s *handler) handleNotFound(w http.ResponseWriter, r *http.Request) {
@@ -44,100 +56,215 @@ func getJSONString(document *fastjson.Value, key string) (string, error) {
 	return string(s), nil
 }
 
-func getJSONInt64(document *fastjson.Value, key string) (int64, error) {
-	if !document.Exists(key) {
-		return -1, fmt.Errorf("failed to get field %s: key does not exist", key)
-	}
+func urlVerification(w http.ResponseWriter, challenge string) {
+	w.Header().Set("Content-Type", "plain/text")
+	fmt.Fprint(w, challenge)
+}
 
-	v, err := document.Get(key).Int64()
-	if err != nil {
-		return -1, fmt.Errorf("failed to get field %s: %w", key, err)
+// slackRetry returns which of Slack's retries the request is, and why Slack
+// retried, from the X-Slack-Retry-Num and X-Slack-Retry-Reason headers. The
+// number is zero if the request isn't a retry.
+func slackRetry(r *http.Request) (int, string) {
+	n, err := strconv.Atoi(r.Header.Get("X-Slack-Retry-Num"))
+	if err != nil || n < 0 {
+		return 0, ""
 	}
 
-	return v, nil
+	return n, r.Header.Get("X-Slack-Retry-Reason")
 }
 
-func requestValues(document *fastjson.Value) (eventType, eventID string, eventTimestamp int64, err error) {
-	eventType, err = getJSONString(document, "type")
-	if err != nil {
-		return "", "", 0, fmt.Errorf("failed to get type field: %w", err)
+func (s *handler) handleSlackEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	lc := s.l.With().Str("context", "event_handler")
+
+	rid, ok := ctxRequestID(ctx)
+	if ok {
+		lc = lc.Str("request_id", rid)
 	}
 
-	if eventType == "url_verification" {
+	logger := lc.Logger()
+
+	if r.Method != http.MethodPost {
+		logger.Info().
+			Str("http_method", r.Method).
+			Msg("unexpected HTTP method")
+
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	eventID, err = getJSONString(document, "event_id")
+	mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
 	if err != nil {
-		return "", "", 0, fmt.Errorf("failed to get event_id field")
+		logger.Warn().
+			Err(err).
+			Msg("failed to parse Content-Type")
+
+		w.Header().Set("Accept", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusBadRequest)
+		return
 	}
 
-	eventTimestamp, err = getJSONInt64(document, "event_time")
-	if err != nil {
-		return "", "", 0, fmt.Errorf("failed to get event_time field: %w", err)
+	if mt != "application/json" || !utf8Charset(params) {
+		logger.Warn().
+			Str("content_type", mt).
+			Str("charset", params["charset"]).
+			Msg("content type was not UTF-8 JSON")
+
+		w.Header().Set("Accept", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
 	}
 
-	return
-}
+	body, err := readBody(r)
+	if err != nil {
+		writeBodyError(w, err, logger)
+		return
+	}
 
-func urlVerification(w http.ResponseWriter, r *http.Request, document *fastjson.Value, logger zerolog.Logger) {
-	challenge, err := getJSONString(document, "challenge")
+	env, ev, err := decodeEnvelope(body)
 	if err != nil {
-		logger.Error().
+		logger.Warn().
 			Err(err).
-			Msg("failed URL verification")
+			Msg("failed to decode JSON document")
 
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
 	}
 
-	w.Header().Set("Content-Type", "plain/text")
-	fmt.Fprint(w, challenge)
-}
+	switch env.Type {
+	case envelopeURLVerification:
+		urlVerification(w, env.Challenge)
+		return
 
-func wqEventType(event *fastjson.Value) (workqueue.Event, error) {
-	eventType, err := getJSONString(event, "type")
-	if err != nil {
-		return "", fmt.Errorf("failed to get type field: %w", err)
+	case envelopeEventCallback:
+		// handled below
+
+	case envelopeAppRateLimited:
+		eventsRateLimited.Add(1)
+
+		logger.Warn().
+			Int64("minute_rate_limited", env.MinuteRateLimited).
+			Msg("Slack is rate limiting the events it sends us")
+
+		return
+
+	default:
+		eventsUnknown.Add("envelope:"+env.Type, 1)
+
+		logger.Info().
+			Str("envelope_type", env.Type).
+			Msg("ignoring unknown envelope type")
+
+		return
 	}
 
-	switch eventType {
-	case "message":
-		if !event.Exists("channel_type") {
-			return workqueue.SlackMessageChannel, nil
-		}
+	logger = logger.With().
+		Str("event_type", ev.Type).
+		Str("event_id", env.EventID).
+		Int64("event_time", env.EventTime).
+		Int("schema_version", env.schemaVersion()).
+		Logger()
+
+	et, ok := wqEventType(ev, env.botUserIDs())
+	if !ok {
+		eventsUnknown.Add(ev.Type, 1)
 
-		ct, _ := getJSONString(event, "channel_type")
-
-		switch ct {
-		case "app_home":
-			return workqueue.SlackMessageAppHome, nil
-		case "channel":
-			return workqueue.SlackMessageChannel, nil
-		case "group":
-			return workqueue.SlackMessageGroup, nil
-		case "im":
-			return workqueue.SlackMessageIM, nil
-		case "mpim":
-			return workqueue.SlackMessageMPIM, nil
-		default:
-			return workqueue.SlackMessageChannel, nil
+		logger.Info().Msg("ignoring unknown event type")
+
+		return
+	}
+
+	var opts []workqueue.PublishOption
+
+	if len(env.EventContext) > 0 {
+		opts = append(opts, workqueue.WithEventContext(env.EventContext))
+	}
+
+	retryNum, retryReason := slackRetry(r)
+	if retryNum > 0 {
+		opts = append(opts, workqueue.WithRetry(retryNum, retryReason))
+
+		logger = logger.With().
+			Int("retry_num", retryNum).
+			Str("retry_reason", retryReason).
+			Logger()
+	}
+
+	// acknowledge the event now, and publish it in the background, so Slack
+	// isn't left waiting on Redis
+	ok = s.p.enqueue(publishJob{
+		event:     et,
+		timestamp: env.EventTime,
+		eventID:   env.EventID,
+		requestID: rid,
+		data:      env.Event,
+		opts:      opts,
+		retry:     retryNum > 0,
+		logger:    logger,
+	})
+
+	if !ok {
+		logger.Error().Msg("publish buffer full: asking Slack to retry the event")
+		w.WriteHeader(http.StatusServiceUnavailable)
+
+		return
+	}
+
+	if s.a != nil {
+		if err := s.a.Add(body); err != nil {
+			logger.Warn().
+				Err(err).
+				Msg("failed to archive event")
 		}
+	}
+}
+
+func wqInteractionType(interactionType string) (workqueue.Event, error) {
+	switch interactionType {
+	case "block_actions":
+		return workqueue.SlackBlockActions, nil
 
-	case "team_join":
-		return workqueue.SlackTeamJoin, nil
+	case "message_action":
+		return workqueue.SlackMessageAction, nil
 
-	case "member_joined_channel":
-		return workqueue.SlackChannelJoin, nil
+	case "view_submission":
+		return workqueue.SlackViewSubmission, nil
 
 	default:
-		return "", fmt.Errorf("unknown type %s", eventType)
+		return "", fmt.Errorf("unknown interaction type %s", interactionType)
 	}
 }
 
-func (s *handler) handleSlackEvent(w http.ResponseWriter, r *http.Request) {
+// interactionValues returns the values we use to identify an interaction, as
+// interactivity payloads don't have an event_id or event_time like events do.
+func interactionValues(document *fastjson.Value) (interactionID string, actionTimestamp int64) {
+	interactionID, _ = getJSONString(document, "trigger_id")
+
+	ts, err := getJSONString(document, "action_ts")
+	if err != nil && document.Exists("actions") {
+		if actions := document.GetArray("actions"); len(actions) > 0 {
+			ts, err = getJSONString(actions[0], "action_ts")
+		}
+	}
+
+	if err == nil {
+		// timestamps are in the format of 1548426417.840180
+		if i := strings.IndexByte(ts, '.'); i > 0 {
+			ts = ts[:i]
+		}
+
+		if actionTimestamp, err = strconv.ParseInt(ts, 10, 64); err == nil {
+			return interactionID, actionTimestamp
+		}
+	}
+
+	return interactionID, time.Now().Unix()
+}
+
+func (s *handler) handleSlackInteraction(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	lc := s.l.With().Str("context", "event_handler")
+	lc := s.l.With().Str("context", "interaction_handler")
 
 	rid, ok := ctxRequestID(ctx)
 	if ok {
@@ -156,107 +283,205 @@ func (s *handler) handleSlackEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
 	if err != nil {
-		logger.Error().
+		logger.Warn().
 			Err(err).
 			Msg("failed to parse Content-Type")
 
-		w.Header().Set("Accept", "application/json; charset=utf-8")
+		w.Header().Set("Accept", "application/x-www-form-urlencoded")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	if mt != "application/json" {
-		logger.Error().
+	if mt != "application/x-www-form-urlencoded" || !utf8Charset(params) {
+		logger.Warn().
 			Str("content_type", mt).
-			Msg("content type was not JSON")
+			Str("charset", params["charset"]).
+			Msg("content type was not a UTF-8 form")
 
-		w.Header().Set("Accept", "application/json; charset=utf-8")
+		w.Header().Set("Accept", "application/x-www-form-urlencoded")
 		w.WriteHeader(http.StatusUnsupportedMediaType)
 		return
 	}
 
-	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	body, err := readBody(r)
 	if err != nil {
-		logger.Error().
+		writeBodyError(w, err, logger)
+		return
+	}
+
+	document, err := interactionPayload(body)
+	if err != nil {
+		logger.Warn().
 			Err(err).
-			Msg("failed to read request body")
+			Msg("failed to parse interaction payload")
 
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
 	}
 
-	document, err := fastjson.ParseBytes(body)
+	interactionType, err := getJSONString(document, "type")
 	if err != nil {
-		logger.Error().
+		logger.Warn().
 			Err(err).
-			Msg("failed to unmarshal JSON document")
+			Msg("failed to parse values from JSON document")
 
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
 	}
 
-	eventType, eventID, eventTimestamp, err := requestValues(document)
+	interactionID, actionTimestamp := interactionValues(document)
+
+	logger = logger.With().Str("interaction_type", interactionType).Str("interaction_id", interactionID).Int64("action_time", actionTimestamp).Logger()
+
+	et, err := wqInteractionType(interactionType)
 	if err != nil {
-		logger.Error().
+		logger.Warn().
 			Err(err).
-			Msg("failed to parse values from JSON document")
+			Msg("failed to determine interaction type")
 
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
 	}
 
-	if eventType == "url_verification" {
-		urlVerification(w, r, document, logger)
+	object := document.MarshalTo(make([]byte, 0, 4*1024))
+
+	ok = s.p.enqueue(publishJob{
+		event:     et,
+		timestamp: actionTimestamp,
+		eventID:   interactionID,
+		requestID: rid,
+		data:      object,
+		logger:    logger,
+	})
+
+	if !ok {
+		logger.Error().Msg("publish buffer full: failing the interaction")
+		w.WriteHeader(http.StatusServiceUnavailable)
+
 		return
 	}
+}
 
-	logger = logger.With().Str("event_type", eventType).Str("event_id", eventID).Int64("event_time", eventTimestamp).Logger()
+// slashCommand returns the slash command from the form Slack sends it as,
+// leaving out the verification token.
+func slashCommand(values url.Values) slack.SlashCommand {
+	return slack.SlashCommand{
+		TeamID:         values.Get("team_id"),
+		TeamDomain:     values.Get("team_domain"),
+		EnterpriseID:   values.Get("enterprise_id"),
+		EnterpriseName: values.Get("enterprise_name"),
+		ChannelID:      values.Get("channel_id"),
+		ChannelName:    values.Get("channel_name"),
+		UserID:         values.Get("user_id"),
+		UserName:       values.Get("user_name"),
+		Command:        values.Get("command"),
+		Text:           values.Get("text"),
+		ResponseURL:    values.Get("response_url"),
+		TriggerID:      values.Get("trigger_id"),
+	}
+}
 
-	if !document.Exists("event") {
-		logger.Error().
-			Str("error", "event field does not exist").
-			Msg("failed to unmarshal JSON document")
+func (s *handler) handleSlackCommand(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	lc := s.l.With().Str("context", "command_handler")
 
-		w.WriteHeader(http.StatusUnprocessableEntity)
+	rid, ok := ctxRequestID(ctx)
+	if ok {
+		lc = lc.Str("request_id", rid)
+	}
+
+	logger := lc.Logger()
+
+	if r.Method != http.MethodPost {
+		logger.Info().
+			Str("http_method", r.Method).
+			Msg("unexpected HTTP method")
+
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	event := document.Get("event")
-	et, err := wqEventType(event)
+	mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
 	if err != nil {
-		logger.Error().
+		logger.Warn().
 			Err(err).
-			Msg("failed to determine event type")
+			Msg("failed to parse Content-Type")
 
-		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Header().Set("Accept", "application/x-www-form-urlencoded")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if mt != "application/x-www-form-urlencoded" || !utf8Charset(params) {
+		logger.Warn().
+			Str("content_type", mt).
+			Str("charset", params["charset"]).
+			Msg("content type was not a UTF-8 form")
+
+		w.Header().Set("Accept", "application/x-www-form-urlencoded")
+		w.WriteHeader(http.StatusUnsupportedMediaType)
 		return
 	}
 
-	obj, err := event.Object()
+	body, err := readBody(r)
 	if err != nil {
-		logger.Error().
+		writeBodyError(w, err, logger)
+		return
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		logger.Warn().
 			Err(err).
-			Msg("failed to convert event field to object")
+			Msg("failed to parse form body")
 
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
 	}
 
-	object := obj.MarshalTo(make([]byte, 0, 4*1024))
+	sc := slashCommand(values)
+
+	if len(sc.Command) == 0 {
+		logger.Warn().
+			Str("error", "command field does not exist").
+			Msg("failed to parse slash command")
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
 
-	err = s.q.Publish(et, eventTimestamp, eventID, rid, object)
+	// like interactions, slash commands don't have an event_id or
+	// event_time, so the trigger_id identifies them
+	commandID, commandTimestamp := sc.TriggerID, time.Now().Unix()
+
+	logger = logger.With().Str("command", sc.Command).Str("command_id", commandID).Int64("command_time", commandTimestamp).Logger()
+
+	object, err := json.Marshal(sc)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to publish event to workqueue")
+		logger.Error().
+			Err(err).
+			Msg("failed to marshal slash command")
+
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	logger.Debug().
-		Str("event_type", string(et)).
-		Int64("event_timestamp", eventTimestamp).
-		Str("event_id", eventID).
-		Bool("object_has_len", len(object) > 0).
-		Msg("published event")
+	ok = s.p.enqueue(publishJob{
+		event:     workqueue.SlackSlashCommand,
+		timestamp: commandTimestamp,
+		eventID:   commandID,
+		requestID: rid,
+		data:      object,
+		logger:    logger,
+	})
+
+	if !ok {
+		logger.Error().Msg("publish buffer full: failing the slash command")
+		w.WriteHeader(http.StatusServiceUnavailable)
+
+		return
+	}
 }
@@ -14,6 +14,10 @@ func main() {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
+	if err := c.Validate(config.Gateway); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+
 	l := config.DefaultLogger(c)
 
 	if err := runServer(c, l); err != nil {
@@ -3,9 +3,13 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/gobridge/gopherbot/signing"
@@ -33,15 +37,33 @@ func ctxRequestID(ctx context.Context) (string, bool) {
 	return rid, true
 }
 
+// newRequestID returns a random request ID, formatted like a UUID.
+func newRequestID() string {
+	var b [16]byte
+
+	// crypto/rand doesn't fail on the platforms we run on
+	_, _ = io.ReadFull(rand.Reader, b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
 func chMiddlewareFactory(baseLogger zerolog.Logger, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := context.Background()
 
-		if rid := r.Header.Get("X-Request-ID"); len(rid) > 0 {
-			ctx = context.WithValue(ctx, ctxKeyReqID, rid)
-			w.Header().Set("X-Request-ID", rid)
+		// Heroku's router sets the request ID, but make sure there always is
+		// one, as it's also the trace ID
+		rid := r.Header.Get("X-Request-ID")
+		if len(rid) == 0 {
+			rid = newRequestID()
 		}
 
+		ctx = context.WithValue(ctx, ctxKeyReqID, rid)
+		w.Header().Set("X-Request-ID", rid)
+
 		// Slack expects a response within 3 seconds, give ourselves 2.9 seconds
 		ctx, cancel := context.WithTimeout(ctx, 2900*time.Millisecond)
 
@@ -51,7 +73,7 @@ func chMiddlewareFactory(baseLogger zerolog.Logger, next http.HandlerFunc) http.
 	}
 }
 
-func slackSignatureMiddlewareFactory(hmacKey, token, appID, teamID string, baseLogger *zerolog.Logger, next http.HandlerFunc) http.HandlerFunc {
+func slackSignatureMiddlewareFactory(sv signing.Validator, token, appID, teamID string, baseLogger *zerolog.Logger, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		lc := baseLogger.With()
 
@@ -60,26 +82,129 @@ func slackSignatureMiddlewareFactory(hmacKey, token, appID, teamID string, baseL
 
 		logger := lc.Str("context", "slack_middleware").Logger()
 
-		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		body, err := readBody(r)
 		if err != nil {
-			logger.Error().
+			writeBodyError(w, err, logger)
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		// validate that the signature looks good
+		err = sv.Validate(signing.Request{
+			Body:      body,
+			Timestamp: r.Header.Get(signing.SlackTimestampHeader),
+			Signature: r.Header.Get(signing.SlackSignatureHeader),
+		})
+		if err != nil {
+			logger.Warn().
 				Err(err).
-				Msg("failed to read request body")
+				Msg("failed to validated Slack request")
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		env, err := parseEnvelope(body)
+		if err != nil {
+			logger.Warn().
+				Err(err).
+				Msg("failed to unmarshal JSON document")
+
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+
+		if env.Token != token {
+			logger.Warn().
+				Str("error", "mismatched token").
+				Str("token", env.Token).
+				Msg("failed to validate Slack request")
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// the following items will NOT be present
+		// so let's skip them
+		if env.Type == envelopeURLVerification {
+			next(w, r)
+			return
+		}
+
+		if env.APIAppID != appID {
+			logger.Warn().
+				Str("error", "mismatched api_app_id").
+				Str("api_app_id", env.APIAppID).
+				Msg("failed to validate Slack request")
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if env.TeamID != teamID {
+			logger.Warn().
+				Str("error", "mismatched team_id").
+				Str("team_id", env.TeamID).
+				Msg("failed to validate Slack request")
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// interactionPayload returns the JSON document from the payload form field of
+// an interactivity request, which is how Slack sends them.
+func interactionPayload(body []byte) (*fastjson.Value, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse form body: %w", err)
+	}
+
+	payload := values.Get("payload")
+	if len(payload) == 0 {
+		return nil, errors.New("payload field does not exist")
+	}
+
+	document, err := fastjson.Parse(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload JSON document: %w", err)
+	}
+
+	return document, nil
+}
+
+// slackInteractionMiddlewareFactory is the slackSignatureMiddlewareFactory
+// equivalent for interactivity requests, where the JSON document is in a form
+// field and the team ID is nested within a team object.
+func slackInteractionMiddlewareFactory(sv signing.Validator, token, appID, teamID string, baseLogger *zerolog.Logger, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lc := baseLogger.With()
+
+		rid, _ := ctxRequestID(r.Context())
+		lc = lc.Str("request_id", rid)
+
+		logger := lc.Str("context", "slack_interaction_middleware").Logger()
 
-			w.WriteHeader(http.StatusInternalServerError)
+		body, err := readBody(r)
+		if err != nil {
+			writeBodyError(w, err, logger)
 			return
 		}
 
 		r.Body = ioutil.NopCloser(bytes.NewReader(body))
 
 		// validate that the signature looks good
-		err = signing.Validate(hmacKey, signing.Request{
+		err = sv.Validate(signing.Request{
 			Body:      body,
 			Timestamp: r.Header.Get(signing.SlackTimestampHeader),
 			Signature: r.Header.Get(signing.SlackSignatureHeader),
 		})
 		if err != nil {
-			logger.Error().
+			logger.Warn().
 				Err(err).
 				Msg("failed to validated Slack request")
 
@@ -87,11 +212,11 @@ func slackSignatureMiddlewareFactory(hmacKey, token, appID, teamID string, baseL
 			return
 		}
 
-		document, err := fastjson.ParseBytes(body)
+		document, err := interactionPayload(body)
 		if err != nil {
-			logger.Error().
+			logger.Warn().
 				Err(err).
-				Msg("failed to unmarshal JSON document")
+				Msg("failed to parse interaction payload")
 
 			w.WriteHeader(http.StatusUnprocessableEntity)
 			return
@@ -99,7 +224,7 @@ func slackSignatureMiddlewareFactory(hmacKey, token, appID, teamID string, baseL
 
 		rToken, err := getJSONString(document, "token")
 		if err != nil {
-			logger.Error().
+			logger.Warn().
 				Err(err).
 				Msg("failed to validate Slack request")
 
@@ -108,7 +233,7 @@ func slackSignatureMiddlewareFactory(hmacKey, token, appID, teamID string, baseL
 		}
 
 		if rToken != token {
-			logger.Error().
+			logger.Warn().
 				Str("error", "mismatched token").
 				Str("token", rToken).
 				Msg("failed to validate Slack request")
@@ -117,9 +242,9 @@ func slackSignatureMiddlewareFactory(hmacKey, token, appID, teamID string, baseL
 			return
 		}
 
-		typeValue, err := getJSONString(document, "type")
+		rAppID, err := getJSONString(document, "api_app_id")
 		if err != nil {
-			logger.Error().
+			logger.Warn().
 				Err(err).
 				Msg("failed to validate Slack request")
 
@@ -127,16 +252,28 @@ func slackSignatureMiddlewareFactory(hmacKey, token, appID, teamID string, baseL
 			return
 		}
 
-		// the following items will NOT be present
-		// so let's skip them
-		if typeValue == "url_verification" {
-			next(w, r)
+		if rAppID != appID {
+			logger.Warn().
+				Str("error", "mismatched api_app_id").
+				Str("api_app_id", rAppID).
+				Msg("failed to validate Slack request")
+
+			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
 
-		rAppID, err := getJSONString(document, "api_app_id")
+		if !document.Exists("team") {
+			logger.Warn().
+				Str("error", "team field does not exist").
+				Msg("failed to validate Slack request")
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		rTeamID, err := getJSONString(document.Get("team"), "id")
 		if err != nil {
-			logger.Error().
+			logger.Warn().
 				Err(err).
 				Msg("failed to validate Slack request")
 
@@ -144,27 +281,87 @@ func slackSignatureMiddlewareFactory(hmacKey, token, appID, teamID string, baseL
 			return
 		}
 
-		if rAppID != appID {
-			logger.Error().
-				Str("error", "mismatched api_app_id").
-				Str("api_app_id", rAppID).
+		if rTeamID != teamID {
+			logger.Warn().
+				Str("error", "mismatched team_id").
+				Str("team_id", rTeamID).
 				Msg("failed to validate Slack request")
 
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
 
-		rTeamID, err := getJSONString(document, "team_id")
+		next(w, r)
+	}
+}
+
+// slackCommandMiddlewareFactory is the slackSignatureMiddlewareFactory
+// equivalent for slash command requests, which are plain forms rather than
+// JSON documents.
+func slackCommandMiddlewareFactory(sv signing.Validator, token, appID, teamID string, baseLogger *zerolog.Logger, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lc := baseLogger.With()
+
+		rid, _ := ctxRequestID(r.Context())
+		lc = lc.Str("request_id", rid)
+
+		logger := lc.Str("context", "slack_command_middleware").Logger()
+
+		body, err := readBody(r)
+		if err != nil {
+			writeBodyError(w, err, logger)
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		// validate that the signature looks good
+		err = sv.Validate(signing.Request{
+			Body:      body,
+			Timestamp: r.Header.Get(signing.SlackTimestampHeader),
+			Signature: r.Header.Get(signing.SlackSignatureHeader),
+		})
+		if err != nil {
+			logger.Warn().
+				Err(err).
+				Msg("failed to validated Slack request")
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		values, err := url.ParseQuery(string(body))
 		if err != nil {
-			logger.Error().
+			logger.Warn().
 				Err(err).
+				Msg("failed to parse form body")
+
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+
+		if rToken := values.Get("token"); rToken != token {
+			logger.Warn().
+				Str("error", "mismatched token").
+				Str("token", rToken).
+				Msg("failed to validate Slack request")
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if rAppID := values.Get("api_app_id"); rAppID != appID {
+			logger.Warn().
+				Str("error", "mismatched api_app_id").
+				Str("api_app_id", rAppID).
 				Msg("failed to validate Slack request")
 
 			w.WriteHeader(http.StatusBadRequest)
+			return
 		}
 
-		if rTeamID != teamID {
-			logger.Error().
+		if rTeamID := values.Get("team_id"); rTeamID != teamID {
+			logger.Warn().
 				Str("error", "mismatched team_id").
 				Str("team_id", rTeamID).
 				Msg("failed to validate Slack request")
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/oauth"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const slackAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+
+// botScopes are the bot token scopes requested when installing the app.
+var botScopes = []string{
+	"channels:history",
+	"channels:read",
+	"chat:write",
+	"chat:write.customize",
+	"emoji:read",
+	"files:read",
+	"groups:history",
+	"groups:read",
+	"im:history",
+	"reactions:write",
+	"usergroups:read",
+	"users:read",
+}
+
+// oauthHandler implements the Slack OAuth v2 flow, so workspaces can install
+// the app and have their bot token saved without it being provisioned by
+// hand.
+type oauthHandler struct {
+	l            *zerolog.Logger
+	store        *oauth.Store
+	clientID     string
+	clientSecret string
+	http         *http.Client
+}
+
+func (o *oauthHandler) logger(r *http.Request, context string) zerolog.Logger {
+	lc := o.l.With().Str("context", context)
+
+	if rid := r.Header.Get("X-Request-ID"); len(rid) > 0 {
+		lc = lc.Str("request_id", rid)
+	}
+
+	return lc.Logger()
+}
+
+// handleInstall redirects to Slack to approve the installation, with a state
+// value that the callback verifies.
+func (o *oauthHandler) handleInstall(w http.ResponseWriter, r *http.Request) {
+	logger := o.logger(r, "oauth_install")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	state, err := o.store.NewState(ctx)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to create OAuth state")
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	v := url.Values{
+		"client_id": {o.clientID},
+		"scope":     {strings.Join(botScopes, ",")},
+		"state":     {state},
+	}
+
+	http.Redirect(w, r, slackAuthorizeURL+"?"+v.Encode(), http.StatusFound)
+}
+
+// handleCallback is where Slack sends the user after they approve (or deny)
+// the installation. It exchanges the code for the bot token and saves it.
+func (o *oauthHandler) handleCallback(w http.ResponseWriter, r *http.Request) {
+	logger := o.logger(r, "oauth_callback")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+
+	if e := q.Get("error"); len(e) > 0 {
+		logger.Info().
+			Str("error", e).
+			Msg("installation was not approved")
+
+		writeOAuthPage(w, http.StatusOK, "The installation was canceled.")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	ok, err := o.store.ConsumeState(ctx, q.Get("state"))
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to check OAuth state")
+
+		writeOAuthPage(w, http.StatusInternalServerError, "Something went wrong, please try again.")
+		return
+	}
+
+	if !ok {
+		logger.Info().Msg("OAuth callback with unknown or reused state")
+
+		writeOAuthPage(w, http.StatusBadRequest, "This installation link has expired, please start over.")
+		return
+	}
+
+	code := q.Get("code")
+	if len(code) == 0 {
+		writeOAuthPage(w, http.StatusBadRequest, "The request from Slack was missing its code.")
+		return
+	}
+
+	resp, err := slack.GetOAuthV2ResponseContext(ctx, o.http, o.clientID, o.clientSecret, code, "")
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to exchange OAuth code")
+
+		writeOAuthPage(w, http.StatusBadGateway, "Slack didn't accept the installation, please try again.")
+		return
+	}
+
+	in := oauth.Installation{
+		TeamID:       resp.Team.ID,
+		TeamName:     resp.Team.Name,
+		EnterpriseID: resp.Enterprise.ID,
+		AppID:        resp.AppID,
+		BotUserID:    resp.BotUserID,
+		BotToken:     resp.AccessToken,
+		Scope:        resp.Scope,
+		InstalledBy:  resp.AuthedUser.ID,
+		InstalledAt:  time.Now(),
+	}
+
+	if err := o.store.Put(ctx, in); err != nil {
+		logger.Error().
+			Err(err).
+			Str("team_id", in.TeamID).
+			Msg("failed to save installation")
+
+		writeOAuthPage(w, http.StatusInternalServerError, "Something went wrong, please try again.")
+		return
+	}
+
+	logger.Info().
+		Str("team_id", in.TeamID).
+		Str("team_name", in.TeamName).
+		Str("installed_by", in.InstalledBy).
+		Str("scope", in.Scope).
+		Msg("app installed")
+
+	writeOAuthPage(w, http.StatusOK, fmt.Sprintf("Gopherbot is now installed in %s.", in.TeamName))
+}
+
+func writeOAuthPage(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintln(w, msg)
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/idempotency"
+	"github.com/gobridge/gopherbot/internal/status"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// publishBufferSize is how many events can be waiting to be published
+	// before the gateway starts turning them away.
+	publishBufferSize = 1024
+
+	// publishWorkers is how many events are published at once.
+	publishWorkers = 4
+
+	// publishAttempts is how many times publishing an event is tried before
+	// it's dropped.
+	publishAttempts = 3
+)
+
+var (
+	// publishQueued is how many events are waiting to be published.
+	publishQueued = expvar.NewInt("gateway_publish_queued")
+
+	// publishOverflowed counts the events turned away because the buffer was
+	// full, which Slack then retries.
+	publishOverflowed = expvar.NewInt("gateway_publish_overflowed")
+
+	// publishDropped counts the events that couldn't be published, after
+	// Slack was told they were.
+	publishDropped = expvar.NewInt("gateway_publish_dropped")
+
+	// publishSkipped counts Slack's retries of events already published,
+	// which were skipped.
+	publishSkipped = expvar.NewInt("gateway_publish_skipped")
+)
+
+// publishJob is an event waiting to be published.
+type publishJob struct {
+	event     workqueue.Event
+	timestamp int64
+	eventID   string
+	requestID string
+	data      []byte
+	opts      []workqueue.PublishOption
+
+	// retry is whether it's one of Slack's retries, which are skipped if
+	// the event was already published
+	retry bool
+
+	logger zerolog.Logger
+}
+
+// asyncPublisher publishes events to the workqueue in the background, so the
+// gateway can acknowledge them as soon as they're validated, rather than Slack
+// waiting on Redis and retrying if it's slow.
+type asyncPublisher struct {
+	q workqueue.Publisher
+
+	// accepted records the events published, so Slack's retries of them can
+	// be skipped. It's nil if retries aren't skipped.
+	accepted *idempotency.Store
+
+	// mu guards against enqueuing after the jobs are closed, if the HTTP
+	// server didn't shut down cleanly
+	mu     sync.RWMutex
+	closed bool
+	jobs   chan publishJob
+
+	wg sync.WaitGroup
+}
+
+func newAsyncPublisher(q workqueue.Publisher, accepted *idempotency.Store) *asyncPublisher {
+	p := &asyncPublisher{
+		q:        q,
+		accepted: accepted,
+		jobs:     make(chan publishJob, publishBufferSize),
+	}
+
+	p.wg.Add(publishWorkers)
+
+	for i := 0; i < publishWorkers; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// enqueue queues the event to be published, returning false if the buffer is
+// full or we're shutting down, in which case the request should fail so Slack
+// retries it.
+func (p *asyncPublisher) enqueue(j publishJob) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return false
+	}
+
+	select {
+	case p.jobs <- j:
+		publishQueued.Add(1)
+		return true
+
+	default:
+		publishOverflowed.Add(1)
+		return false
+	}
+}
+
+// shutdown stops taking events, and waits for the queued ones to be published
+// until the context is canceled.
+func (p *asyncPublisher) shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *asyncPublisher) work() {
+	defer p.wg.Done()
+
+	for j := range p.jobs {
+		publishQueued.Add(-1)
+		p.publish(j)
+	}
+}
+
+func (p *asyncPublisher) publish(j publishJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key := "gateway_event:" + j.eventID
+
+	var claimed bool
+
+	if p.accepted != nil {
+		var err error
+
+		claimed, err = p.accepted.Claim(ctx, key, acceptedTTL)
+
+		switch {
+		case err != nil:
+			// publishing it twice is better than not at all
+			j.logger.Error().
+				Err(err).
+				Msg("failed to record event as accepted")
+
+		case !claimed && j.retry:
+			publishSkipped.Add(1)
+			j.logger.Info().Msg("skipping retry of event already published")
+
+			return
+		}
+	}
+
+	var err error
+
+	for attempt := 1; attempt <= publishAttempts; attempt++ {
+		if err = p.q.Publish(j.event, j.timestamp, j.eventID, j.requestID, j.data, j.opts...); err == nil {
+			break
+		}
+
+		if attempt < publishAttempts {
+			time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+		}
+	}
+
+	if err != nil {
+		publishDropped.Add(1)
+
+		j.logger.Error().
+			Err(err).
+			Msg("failed to publish event to workqueue: dropping it")
+
+		// so it's not mistaken for published if Slack sends it again
+		if claimed {
+			if err := p.accepted.Release(ctx, key); err != nil {
+				j.logger.Error().
+					Err(err).
+					Msg("failed to release event after failing to publish it")
+			}
+		}
+
+		return
+	}
+
+	j.logger.Debug().
+		Str("event_type", string(j.event)).
+		Int64("event_timestamp", j.timestamp).
+		Str("event_id", j.eventID).
+		Bool("object_has_len", len(j.data) > 0).
+		Msg("published event")
+}
+
+type publisherDetail struct {
+	Queued     int64 `json:"queued"`
+	Overflowed int64 `json:"overflowed"`
+	Dropped    int64 `json:"dropped"`
+	Skipped    int64 `json:"skipped"`
+}
+
+// publisherCheck reports the events waiting to be published, and is Degraded
+// while the buffer is more than half full.
+func publisherCheck(p *asyncPublisher) status.CheckFunc {
+	return func(context.Context) status.Report {
+		d := publisherDetail{
+			Queued:     int64(len(p.jobs)),
+			Overflowed: publishOverflowed.Value(),
+			Dropped:    publishDropped.Value(),
+			Skipped:    publishSkipped.Value(),
+		}
+
+		r := status.Report{State: status.OK, Detail: d}
+
+		if d.Queued > publishBufferSize/2 {
+			r.State = status.Degraded
+			r.Error = fmt.Sprintf("%d events waiting to be published", d.Queued)
+		}
+
+		return r
+	}
+}
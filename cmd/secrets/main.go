@@ -0,0 +1,70 @@
+// Command secrets generates master keys for GOPHER_SECRETS_KEYS, and encrypts
+// values with them so they can be set as environment variables.
+//
+// Usage:
+//
+//	secrets genkey <id>
+//	GOPHER_SECRETS_KEYS=... secrets encrypt < plaintext
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gobridge/gopherbot/internal/secrets"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "secrets: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: secrets genkey <id> | secrets encrypt")
+	}
+
+	switch args[0] {
+	case "genkey":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: secrets genkey <id>")
+		}
+
+		key, err := secrets.GenerateKey()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s=%s\n", args[1], key)
+
+		return nil
+
+	case "encrypt":
+		k, err := secrets.ParseKeyring(os.Getenv("GOPHER_SECRETS_KEYS"))
+		if err != nil {
+			return fmt.Errorf("failed to parse GOPHER_SECRETS_KEYS: %w", err)
+		}
+
+		// read a single line, so the value doesn't end up in shell history
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && len(line) == 0 {
+			return fmt.Errorf("failed to read value from stdin: %w", err)
+		}
+
+		v, err := k.Encrypt(strings.TrimRight(line, "\r\n"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(v)
+
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
@@ -0,0 +1,262 @@
+// Command wqadmin inspects the workqueue's Redis streams, re-publishes events
+// so the consumer handles them again, like after deploying a fix for events it
+// dropped or mishandled, and trims the streams without losing events that
+// haven't been handled yet. It connects to Redis using the same environment
+// variables as the other components.
+//
+// Usage:
+//
+//	wqadmin info
+//	wqadmin show [-start ID] [-end ID] [-count N] <stream>
+//	wqadmin replay [-dry-run] <stream> <start> <end>
+//	wqadmin trim [-force] [-dry-run] -maxlen N <stream>
+//
+// IDs are stream IDs, "-" and "+" for the oldest and newest, or RFC 3339
+// times, like 2020-05-01T15:04:05Z.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gobridge/gopherbot/config"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/redis/go-redis/v9"
+)
+
+const usage = `usage:
+	wqadmin info
+	wqadmin show [-start ID] [-end ID] [-count N] <stream>
+	wqadmin replay [-dry-run] <stream> <start> <end>
+	wqadmin trim [-force] [-dry-run] -maxlen N <stream>`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "wqadmin: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	cfg, err := config.LoadEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	rc := redis.NewClient(config.DefaultRedis(cfg))
+	defer func() { _ = rc.Close() }()
+
+	// stop waiting on Redis on ^C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch args[0] {
+	case "info":
+		return info(ctx, rc, w)
+	case "show":
+		return show(ctx, rc, args[1:], w)
+	case "replay":
+		return replay(ctx, rc, args[1:], w)
+	case "trim":
+		return trim(ctx, rc, args[1:], w)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+// parseID returns the stream ID, converting RFC 3339 times to the millisecond
+// IDs Redis accepts in ranges.
+func parseID(s string) (string, error) {
+	if s == "-" || s == "+" {
+		return s, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10), nil
+	}
+
+	ms := strings.SplitN(s, "-", 2)[0]
+	if _, err := strconv.ParseUint(ms, 10, 64); err != nil {
+		return "", fmt.Errorf("invalid ID %q: want a stream ID, - or +, or an RFC 3339 time", s)
+	}
+
+	return s, nil
+}
+
+func info(ctx context.Context, rc *redis.Client, w io.Writer) error {
+	infos, err := workqueue.Inspect(ctx, rc)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "STREAM\tLENGTH\tFIRST\tLAST\tGROUP\tCONSUMERS\tPENDING\tLAST DELIVERED")
+
+	for _, si := range infos {
+		if len(si.Groups) == 0 {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t-\t-\t-\t-\n", si.Stream, si.Length, si.FirstID, si.LastID)
+			continue
+		}
+
+		for _, g := range si.Groups {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%d\t%d\t%s\n", si.Stream, si.Length, si.FirstID, si.LastID, g.Name, g.Consumers, g.Pending, g.LastDeliveredID)
+		}
+	}
+
+	return tw.Flush()
+}
+
+type entryJSON struct {
+	ID        string          `json:"id"`
+	Published time.Time       `json:"published"`
+	EventID   string          `json:"event_id"`
+	RequestID string          `json:"request_id,omitempty"`
+	ReplayOf  string          `json:"replay_of,omitempty"`
+	Event     json.RawMessage `json:"event,omitempty"`
+}
+
+func writeEntries(w io.Writer, entries []workqueue.Entry) error {
+	enc := json.NewEncoder(w)
+
+	for _, e := range entries {
+		ej := entryJSON{
+			ID:        e.ID,
+			Published: e.Published.UTC(),
+			EventID:   e.EventID,
+			RequestID: e.RequestID,
+			ReplayOf:  e.ReplayOf,
+		}
+
+		if json.Valid([]byte(e.JSON)) {
+			ej.Event = json.RawMessage(e.JSON)
+		}
+
+		if err := enc.Encode(ej); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func show(ctx context.Context, rc *redis.Client, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	start := fs.String("start", "-", "oldest ID to show")
+	end := fs.String("end", "+", "newest ID to show")
+	count := fs.Int64("count", 10, "most events to show")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: wqadmin show [-start ID] [-end ID] [-count N] <stream>")
+	}
+
+	s, err := parseID(*start)
+	if err != nil {
+		return err
+	}
+
+	e, err := parseID(*end)
+	if err != nil {
+		return err
+	}
+
+	entries, err := workqueue.Entries(ctx, rc, fs.Arg(0), s, e, *count)
+	if err != nil {
+		return err
+	}
+
+	return writeEntries(w, entries)
+}
+
+func replay(ctx context.Context, rc *redis.Client, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only list the events that would be re-published")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 3 {
+		return errors.New("usage: wqadmin replay [-dry-run] <stream> <start> <end>")
+	}
+
+	s, err := parseID(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	e, err := parseID(fs.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	entries, err := workqueue.Replay(ctx, rc, fs.Arg(0), s, e, *dryRun)
+
+	if werr := writeEntries(w, entries); werr != nil && err == nil {
+		err = werr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	verb := "re-published"
+	if *dryRun {
+		verb = "would re-publish"
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %d events\n", verb, len(entries))
+
+	return nil
+}
+
+func trim(ctx context.Context, rc *redis.Client, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("trim", flag.ContinueOnError)
+	maxLen := fs.Int64("maxlen", -1, "how many of the newest events to keep")
+	force := fs.Bool("force", false, "trim even events not yet handled by every consumer group")
+	dryRun := fs.Bool("dry-run", false, "only count the events that would be removed")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 || *maxLen < 0 {
+		return errors.New("usage: wqadmin trim [-force] [-dry-run] -maxlen N <stream>")
+	}
+
+	n, err := workqueue.Trim(ctx, rc, fs.Arg(0), *maxLen, *force, *dryRun)
+	if err != nil {
+		if errors.Is(err, workqueue.ErrUnsafeTrim) {
+			return fmt.Errorf("%w: use -force to trim anyway", err)
+		}
+
+		return err
+	}
+
+	verb := "removed"
+	if *dryRun {
+		verb = "would remove"
+	}
+
+	fmt.Fprintf(w, "%s %d events\n", verb, n)
+
+	return nil
+}
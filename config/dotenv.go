@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DotEnvFile is the file of environment variables loaded in development, from
+// the working directory, so they don't have to be exported in each shell.
+const DotEnvFile = ".env"
+
+// loadDotEnv sets the environment variables in the file, which has a KEY=value
+// pair on each line, optionally quoted or prefixed with export, with blank
+// lines and lines starting with # ignored. Variables already set in the
+// environment aren't overridden. It does nothing if the file doesn't exist.
+func loadDotEnv(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	s := bufio.NewScanner(f)
+
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		kv := strings.SplitN(line, "=", 2)
+		k := strings.TrimSpace(kv[0])
+
+		if len(kv) != 2 || len(k) == 0 || strings.ContainsAny(k, " \t") {
+			return fmt.Errorf("failed to parse %s: line %d is not of the form KEY=value", name, n)
+		}
+
+		v := strings.TrimSpace(kv[1])
+
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+
+		if _, set := os.LookupEnv(k); set {
+			continue
+		}
+
+		if err := os.Setenv(k, v); err != nil {
+			return fmt.Errorf("failed to set %s from %s: %w", k, name, err)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return nil
+}
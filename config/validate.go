@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Component is one of gopher's processes, each of which needs different
+// configuration to run.
+type Component string
+
+const (
+	// Gateway is the process receiving requests from Slack
+	Gateway Component = "gateway"
+
+	// Consumer is the process handling the events the gateway publishes
+	Consumer Component = "consumer"
+
+	// BGTasks is the process running the background pollers
+	BGTasks Component = "bgtasks"
+)
+
+// requirement is an environment variable a component can't run without.
+type requirement struct {
+	env string
+	set func(C) bool
+
+	// devOptional is whether it has a usable default in development, like
+	// Redis on localhost
+	devOptional bool
+}
+
+var (
+	// the Redis keys, and the consumer group, are named after the app and
+	// dyno
+	requireAppName = requirement{
+		env: "HEROKU_APP_NAME",
+		set: func(c C) bool { return len(c.Heroku.AppName) > 0 },
+	}
+
+	requireDynoID = requirement{
+		env: "HEROKU_DYNO_ID",
+		set: func(c C) bool { return len(c.Heroku.DynoID) > 0 },
+	}
+
+	requireRedis = requirement{
+		env:         "REDIS_URL",
+		set:         func(c C) bool { return len(c.Redis.Addr) > 0 },
+		devOptional: true,
+	}
+
+	requireBotAccessToken = requirement{
+		env: "GOPHER_SLACK_BOT_ACCESS_TOKEN",
+		set: func(c C) bool { return len(c.Slack.BotAccessToken) > 0 },
+	}
+)
+
+// requirements are the required environment variables of each component.
+var requirements = map[Component][]requirement{
+	Gateway: {
+		{env: "PORT", set: func(c C) bool { return c.Port > 0 }},
+		requireAppName,
+		requireDynoID,
+		requireRedis,
+		{env: "GOPHER_SLACK_APP_ID", set: func(c C) bool { return len(c.Slack.AppID) > 0 }},
+		{env: "GOPHER_SLACK_TEAM_ID", set: func(c C) bool { return len(c.Slack.TeamID) > 0 }},
+		{env: "GOPHER_SLACK_REQUEST_SECRET", set: func(c C) bool { return len(c.Slack.RequestSecret) > 0 }},
+		{env: "GOPHER_SLACK_REQUEST_TOKEN", set: func(c C) bool { return len(c.Slack.RequestToken) > 0 }},
+	},
+	Consumer: {
+		requireAppName,
+		requireDynoID,
+		requireRedis,
+		requireBotAccessToken,
+	},
+	BGTasks: {
+		requireAppName,
+		requireDynoID,
+		requireRedis,
+		requireBotAccessToken,
+	},
+}
+
+// MissingError is the error returned by C.Validate, listing every required
+// environment variable that isn't set, so they can all be fixed at once.
+type MissingError struct {
+	Component Component
+	Env       []string
+}
+
+// Error satisfies the error interface.
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("%s is missing required environment variables: %s", e.Component, strings.Join(e.Env, ", "))
+}
+
+// Validate returns a *MissingError listing the environment variables the
+// component requires that weren't set, if there are any. In development, those
+// with a usable default, like REDIS_URL, aren't required.
+func (c C) Validate(comp Component) error {
+	reqs, ok := requirements[comp]
+	if !ok {
+		return fmt.Errorf("unknown component %q", comp)
+	}
+
+	var missing []string
+
+	for _, r := range reqs {
+		if r.devOptional && c.Env == Development {
+			continue
+		}
+
+		if !r.set(c) {
+			missing = append(missing, r.env)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &MissingError{Component: comp, Env: missing}
+	}
+
+	return nil
+}
@@ -1,20 +1,23 @@
-// +heroku goVersion go1.14
+// +heroku goVersion go1.21
 
 module github.com/gobridge/gopherbot
 
-go 1.14
+go 1.21
 
 require (
-	github.com/go-redis/redis v6.15.7+incompatible
 	github.com/google/go-cmp v0.4.0
 	github.com/heroku/x v0.0.22
-	github.com/onsi/ginkgo v1.10.1 // indirect
-	github.com/onsi/gomega v1.7.0 // indirect
-	github.com/robinjoseph08/redisqueue v1.1.0
+	github.com/lib/pq v1.10.9
+	github.com/redis/go-redis/v9 v9.17.2
 	github.com/rs/zerolog v1.18.0
 	github.com/slack-go/slack v0.6.4
 	github.com/valyala/fastjson v1.5.1
-	golang.org/x/tools v0.0.0-20200420001825-978e26b7c37c // indirect
-	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/gorilla/websocket v1.2.0 // indirect
+	github.com/pkg/errors v0.8.2-0.20190227000051-27936f6d90f9 // indirect
 	gopkg.in/yaml.v2 v2.2.4 // indirect
 )
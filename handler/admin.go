@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// IsAdmin returns whether the user is a Workspace Admin or Owner, according to
+// the user cache.
+func IsAdmin(ctx workqueue.Context, userID string) (bool, error) {
+	u, notFound, err := ctx.UserSvc().User(userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user info for %s: %w", userID, err)
+	}
+
+	if notFound {
+		return false, nil
+	}
+
+	return u.IsAdmin || u.IsOwner, nil
+}
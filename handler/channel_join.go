@@ -39,7 +39,10 @@ type channelJoinAction struct {
 // ChannelJoinActions represents actions to be taken on a team join event.
 type ChannelJoinActions struct {
 	shadow  bool
+	flags   Flags
+	feature string
 	actions map[string][]channelJoinAction
+	all     []channelJoinAction
 	l       zerolog.Logger
 }
 
@@ -52,6 +55,22 @@ func NewChannelJoinActions(shadowMode bool, l zerolog.Logger) *ChannelJoinAction
 	}
 }
 
+// SetFlags makes the actions consult f on each event for whether feature is in
+// shadow mode, instead of the shadowMode given to NewChannelJoinActions. It
+// must be called before handling events.
+func (c *ChannelJoinActions) SetFlags(f Flags, feature string) {
+	c.flags = f
+	c.feature = feature
+}
+
+func (c *ChannelJoinActions) isShadow() bool {
+	if c.flags == nil {
+		return c.shadow
+	}
+
+	return c.flags.Shadow(c.feature)
+}
+
 // Handler satisfies workqueue.ChannelJoinHandler.
 func (c *ChannelJoinActions) Handler(ctx workqueue.Context, cj *slackevents.MemberJoinedChannelEvent) (bool, bool, error) {
 	j := channelJoiner{
@@ -63,7 +82,7 @@ func (c *ChannelJoinActions) Handler(ctx workqueue.Context, cj *slackevents.Memb
 		Type: mparser.TypeUser,
 		ID:   j.userID,
 	}
-	msg := NewMessage(j.channelID, cj.ChannelType, j.userID, "", "", "", "", nil)
+	msg := NewMessage(j.channelID, cj.ChannelType, j.userID, "", "", "", "", nil, ctx.Meta())
 	msg.allMentions = []mparser.Mention{mention}
 	msg.userMentions = []mparser.Mention{mention}
 
@@ -72,15 +91,17 @@ func (c *ChannelJoinActions) Handler(ctx workqueue.Context, cj *slackevents.Memb
 		m:  msg,
 	}
 
-	actions, ok := c.actions[j.channelID]
-	if !ok {
+	actions := append(c.all[:len(c.all):len(c.all)], c.actions[j.channelID]...)
+	if len(actions) == 0 {
 		return false, true, nil // no reason given, as it's normal and shouldn't be logged
 	}
 
 	var someWorked bool
 
+	shadow := c.isShadow()
+
 	for _, a := range actions {
-		if c.shadow {
+		if shadow {
 			c.l.Info().
 				Str("channel_id", j.channelID).
 				Str("user_id", j.userID).
@@ -99,7 +120,7 @@ func (c *ChannelJoinActions) Handler(ctx workqueue.Context, cj *slackevents.Memb
 					Str("join_action", a.name).
 					Msg("failed to take action")
 
-				return false, false, nil
+				return false, false, rateLimited(err)
 			}
 
 			// if it's too old discard
@@ -131,6 +152,12 @@ func (c *ChannelJoinActions) Handle(name, channelID string, fn ChannelJoinAction
 	c.actions[channelID] = slice
 }
 
+// HandleAll registers a ChannelJoinActionFn to be taken on join events in any
+// channel, before those registered for the channel.
+func (c *ChannelJoinActions) HandleAll(name string, fn ChannelJoinActionFn) {
+	c.all = append(c.all, channelJoinAction{name: name, fn: fn})
+}
+
 // HandleStatic registers a ChannelJoinActionFn that sends an ephemeral message
 // to the joining user. The message is the content variadic, joined by newlines.
 func (c *ChannelJoinActions) HandleStatic(name, channelID string, content ...string) {
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// Command is a message parsed as a command, like:
+//
+//	poll "Tabs or spaces?" Tabs Spaces --anonymous
+//
+// Arguments are separated by spaces, unless they're quoted with straight or
+// curly quotes (as the Slack clients like to replace them). Flags start with
+// -- (or —, as some clients replace that too), and either have a value like
+// --for=1h or are set with no value like --anonymous. Anything after a bare --
+// is an argument, even if it looks like a flag.
+type Command struct {
+	// Name is the first word of the message, in lower case.
+	Name string
+
+	// Args are the positional arguments after the name.
+	Args []string
+
+	// Flags are the flags given, by name. Flags given without a value have
+	// an empty value.
+	Flags map[string]string
+}
+
+// Arg returns the positional argument at index i, or an empty string if there
+// aren't that many.
+func (c Command) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+
+	return c.Args[i]
+}
+
+// Flag returns the value of the flag, and whether it was given.
+func (c Command) Flag(name string) (string, bool) {
+	v, ok := c.Flags[name]
+	return v, ok
+}
+
+// HasFlag returns whether the flag was given, with or without a value.
+func (c Command) HasFlag(name string) bool {
+	_, ok := c.Flags[name]
+	return ok
+}
+
+// ErrUnterminatedQuote is returned by ParseCommand when a quoted argument isn't
+// closed.
+var ErrUnterminatedQuote = errors.New("there's a quote without a closing quote")
+
+// isQuote returns whether r opens or closes a quoted argument.
+func isQuote(r rune) bool {
+	return r == '"' || r == '“' || r == '”'
+}
+
+type token struct {
+	s      string
+	quoted bool
+}
+
+// tokenize splits text into its space-separated words, keeping quoted strings
+// together.
+func tokenize(text string) ([]token, error) {
+	var (
+		tokens  []token
+		b       strings.Builder
+		inWord  bool
+		quoted  bool
+		inQuote bool
+	)
+
+	for _, r := range text {
+		switch {
+		case isQuote(r):
+			inQuote = !inQuote
+			inWord = true
+			quoted = true
+
+		case unicode.IsSpace(r) && !inQuote:
+			if inWord {
+				tokens = append(tokens, token{s: b.String(), quoted: quoted})
+				b.Reset()
+			}
+
+			inWord, quoted = false, false
+
+		default:
+			b.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inQuote {
+		return nil, ErrUnterminatedQuote
+	}
+
+	if inWord {
+		tokens = append(tokens, token{s: b.String(), quoted: quoted})
+	}
+
+	return tokens, nil
+}
+
+// flagName returns the flag's name without its leading dashes, and whether the
+// token is a flag at all.
+func flagName(s string) (string, bool) {
+	switch {
+	case strings.HasPrefix(s, "--"):
+		s = s[2:]
+	case strings.HasPrefix(s, "—"):
+		s = s[utf8.RuneLen('—'):]
+	default:
+		return "", false
+	}
+
+	return s, len(s) > 0 && s[0] != '='
+}
+
+// ParseCommand parses the text of a message as a Command.
+func ParseCommand(text string) (Command, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return Command{}, err
+	}
+
+	if len(tokens) == 0 {
+		return Command{}, errors.New("there's no command")
+	}
+
+	c := Command{Name: strings.ToLower(tokens[0].s)}
+
+	var argsOnly bool
+
+	for _, t := range tokens[1:] {
+		if !argsOnly && !t.quoted {
+			if t.s == "--" {
+				argsOnly = true
+				continue
+			}
+
+			if name, ok := flagName(t.s); ok {
+				var value string
+
+				if i := strings.IndexByte(name, '='); i >= 0 {
+					name, value = name[:i], name[i+1:]
+				}
+
+				if c.Flags == nil {
+					c.Flags = make(map[string]string)
+				}
+
+				c.Flags[strings.ToLower(name)] = value
+
+				continue
+			}
+		}
+
+		c.Args = append(c.Args, t.s)
+	}
+
+	return c, nil
+}
+
+// CommandFn is the function signature for handlers registered with
+// HandleCommand, which are given the message already parsed.
+type CommandFn func(ctx workqueue.Context, m Messenger, c Command, r Responder) error
+
+// UsageError is returned by a CommandFn when the command was used incorrectly,
+// so the user is told what was wrong along with how to use it.
+type UsageError struct {
+	Problem string
+}
+
+func (e *UsageError) Error() string { return e.Problem }
+
+// Usagef returns a *UsageError, with the problem formatted like fmt.Sprintf.
+func Usagef(format string, a ...interface{}) error {
+	return &UsageError{Problem: fmt.Sprintf(format, a...)}
+}
+
+// RespondUsage tells the user what was wrong with their command, if anything,
+// and how to use it. It responds ephemerally, so the mistake doesn't clutter
+// the channel.
+func RespondUsage(ctx context.Context, r Responder, problem, usage string) error {
+	if len(problem) == 0 {
+		return r.RespondEphemeral(ctx, usage)
+	}
+
+	return r.RespondEphemeral(ctx, fmt.Sprintf("%s. %s", problem, usage))
+}
+
+// HandleCommand handles a message starting with the command's name, regardless
+// of the mentions in it, parsing it as a Command for fn. If the message can't
+// be parsed, or fn returns a *UsageError, the user is sent the usage.
+func (m *MessageActions) HandleCommand(name, usage, description string, fn CommandFn) {
+	if fn == nil {
+		panic("fn cannot be nil")
+	}
+
+	name = strings.ToLower(name)
+
+	m.HandlePrefix(name, description, func(ctx workqueue.Context, msg Messenger, r Responder) error {
+		// the prefix also matches longer words, like flags for flag
+		if f := strings.Fields(msg.Text()); len(f) == 0 || strings.ToLower(f[0]) != name {
+			return nil
+		}
+
+		c, err := ParseCommand(msg.Text())
+		if err != nil {
+			return RespondUsage(ctx, r, fmt.Sprintf("I couldn't understand that: %s", err), usage)
+		}
+
+		err = fn(ctx, msg, c, r)
+
+		var ue *UsageError
+		if errors.As(err, &ue) {
+			return RespondUsage(ctx, r, ue.Problem, usage)
+		}
+
+		return err
+	})
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Command
+		err  string
+	}{
+		{
+			name: "name_only",
+			text: "Poll",
+			want: Command{Name: "poll"},
+		},
+		{
+			name: "args",
+			text: "flag  playground\tshadow",
+			want: Command{Name: "flag", Args: []string{"playground", "shadow"}},
+		},
+		{
+			name: "straight_quotes",
+			text: `poll "Tabs or spaces?" "Tabs" Spaces`,
+			want: Command{Name: "poll", Args: []string{"Tabs or spaces?", "Tabs", "Spaces"}},
+		},
+		{
+			name: "curly_quotes",
+			text: `poll “Best release?” “Go 1.18” "Go 1.21"`,
+			want: Command{Name: "poll", Args: []string{"Best release?", "Go 1.18", "Go 1.21"}},
+		},
+		{
+			name: "empty_quotes",
+			text: `poll "" ""`,
+			want: Command{Name: "poll", Args: []string{"", ""}},
+		},
+		{
+			name: "quotes_within_word",
+			text: `remind me"in an hour"`,
+			want: Command{Name: "remind", Args: []string{"mein an hour"}},
+		},
+		{
+			name: "flags",
+			text: `remind --in=1h "stand up" --Quiet —to=here`,
+			want: Command{
+				Name:  "remind",
+				Args:  []string{"stand up"},
+				Flags: map[string]string{"in": "1h", "quiet": "", "to": "here"},
+			},
+		},
+		{
+			name: "flag_empty_value",
+			text: "doc --pkg= fmt",
+			want: Command{Name: "doc", Args: []string{"fmt"}, Flags: map[string]string{"pkg": ""}},
+		},
+		{
+			name: "quoted_flag_is_arg",
+			text: `doc "--help"`,
+			want: Command{Name: "doc", Args: []string{"--help"}},
+		},
+		{
+			name: "double_dash_ends_flags",
+			text: "doc --short -- --help",
+			want: Command{Name: "doc", Args: []string{"--help"}, Flags: map[string]string{"short": ""}},
+		},
+		{
+			name: "not_a_flag",
+			text: "doc --=x",
+			want: Command{Name: "doc", Args: []string{"--=x"}},
+		},
+		{
+			name: "unterminated_quote",
+			text: `poll "Tabs or spaces? Tabs Spaces`,
+			err:  ErrUnterminatedQuote.Error(),
+		},
+		{
+			name: "empty",
+			text: "  ",
+			err:  "there's no command",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCommand(tt.text)
+			if len(tt.err) > 0 {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("ParseCommand() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseCommand() unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("ParseCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCommand_accessors(t *testing.T) {
+	c := Command{Name: "remind", Args: []string{"me"}, Flags: map[string]string{"in": "1h", "quiet": ""}}
+
+	if got := c.Arg(0); got != "me" {
+		t.Errorf("Arg(0) = %q, want %q", got, "me")
+	}
+
+	if got := c.Arg(1); got != "" {
+		t.Errorf("Arg(1) = %q, want empty", got)
+	}
+
+	if v, ok := c.Flag("in"); !ok || v != "1h" {
+		t.Errorf(`Flag("in") = %q, %t, want "1h", true`, v, ok)
+	}
+
+	if !c.HasFlag("quiet") {
+		t.Error(`HasFlag("quiet") = false, want true`)
+	}
+
+	if c.HasFlag("loud") {
+		t.Error(`HasFlag("loud") = true, want false`)
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// ReactionAlternativeSep separates the alternatives in a reaction given to
+// HandleReaction and friends, like "bbqgopher|meat_on_bone". The first
+// alternative that exists in the workspace is used.
+const ReactionAlternativeSep = "|"
+
+// isInvalidName returns whether the error is Slack saying the emoji doesn't
+// exist.
+func isInvalidName(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "invalid_name")
+}
+
+// reactWithAlternatives reacts with the first of the reaction's alternatives
+// that exists. Custom emoji in the emoji cache are known to exist, but the
+// standard emoji aren't listed by Slack, so anything else is tried and skipped
+// if Slack says it doesn't exist. That's normally a custom emoji that has been
+// removed.
+func reactWithAlternatives(ctx workqueue.Context, r Responder, reaction string) error {
+	alternatives := strings.Split(reaction, ReactionAlternativeSep)
+
+	for _, emoji := range alternatives {
+		if svc := ctx.EmojiSvc(); svc != nil {
+			_, notFound, err := svc.Emoji(emoji)
+			if err != nil {
+				ctx.Logger().Error().
+					Err(err).
+					Str("emoji", emoji).
+					Msg("failed to look up custom emoji")
+			} else if !notFound {
+				return r.React(ctx, emoji)
+			}
+		}
+
+		err := r.React(ctx, emoji)
+		if err == nil {
+			return nil
+		}
+
+		if !isInvalidName(err) {
+			return err
+		}
+
+		ctx.Logger().Warn().
+			Str("emoji", emoji).
+			Msg("emoji does not exist, trying the next alternative")
+	}
+
+	return fmt.Errorf("none of the emoji in %q exist", reaction)
+}
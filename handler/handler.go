@@ -3,14 +3,42 @@
 package handler
 
 import (
-	"math/rand"
+	"context"
+	"errors"
 	"time"
 
 	"github.com/slack-go/slack"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
+// Flags reports whether features are in shadow mode at runtime. It's generally
+// implemented by a *flags.Store.
+type Flags interface {
+	Shadow(feature string) bool
+}
+
+// MentionsOnly reports whether a channel is one the bot should stay quiet in
+// unless it's mentioned. It's generally implemented by a *mentionsonly.Store.
+type MentionsOnly interface {
+	MentionsOnly(channelID string) bool
+}
+
+// Limiter limits how often something can happen, per ID. It's generally
+// implemented by a *ratelimit.Limiter.
+type Limiter interface {
+	Allow(ctx context.Context, id string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// UsageRecorder records each time a handler fires, for usage analytics. It's
+// generally implemented by a *usage.Store.
+type UsageRecorder interface {
+	Record(ctx context.Context, trigger, channelID string) error
+}
+
+// Localizer returns messages in the language a user has chosen. It's generally
+// implemented by an *i18n.Localizer.
+type Localizer interface {
+	Has(key string) bool
+	Localize(ctx context.Context, userID, key string) (string, error)
 }
 
 // ChannelCache is the interface to describe the shape of a channel cache we
@@ -18,3 +46,16 @@ func init() {
 type ChannelCache interface {
 	Lookup(name string) (slack.Channel, bool)
 }
+
+// rateLimited returns err if it's from Slack rate limiting an action, or nil.
+// Handlers log the errors from their actions rather than returning them, but
+// return these so the workqueue's throttle backs off.
+func rateLimited(err error) error {
+	var rle *slack.RateLimitedError
+
+	if errors.As(err, &rle) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package handler_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/handler/handlertest"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// TestMessageActions_Handler_rateLimited checks that a 429 from an action is
+// returned for the workqueue's throttle, while other failures are only logged.
+func TestMessageActions_Handler_rateLimited(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		limited bool
+	}{
+		{name: "ok"},
+		{name: "failed", err: errors.New("channel_not_found")},
+		{name: "rate_limited", err: &slack.RateLimitedError{RetryAfter: time.Second}, limited: true},
+		{name: "wrapped", err: fmt.Errorf("failed to post: %w", &slack.RateLimitedError{}), limited: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ma, err := handler.NewMessageActions(handlertest.BotID, false, zerolog.Nop())
+			if err != nil {
+				t.Fatalf("NewMessageActions() unexpected error: %v", err)
+			}
+
+			ma.Handle("ping", "fails", nil, func(workqueue.Context, handler.Messenger, handler.Responder) error {
+				return tt.err
+			})
+
+			me := &slackevents.MessageEvent{
+				Channel:     "C1",
+				ChannelType: "channel",
+				User:        "U1",
+				Text:        "<@" + handlertest.BotID + "> ping",
+				TimeStamp:   strconv.FormatInt(time.Now().Unix(), 10) + ".000100",
+			}
+
+			_, _, err = ma.Handler(handlertest.NewContext(), me)
+
+			var rle *slack.RateLimitedError
+			if got := errors.As(err, &rle); got != tt.limited {
+				t.Fatalf("Handler() error = %v, want rate limited %t", err, tt.limited)
+			}
+
+			if !tt.limited && err != nil {
+				t.Fatalf("Handler() unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Package handlertest provides fakes for unit testing handler functions: a
+// workqueue.Context backed by maps rather than the caches, a Responder that
+// records what it's asked to send rather than sending it, and a builder for
+// the messages that trigger them.
+//
+// A handler registered on a *handler.MessageActions can be tested end to end,
+// from matching the message to the responses, with Dispatch:
+//
+//	ctx := handlertest.NewContext()
+//	rec, err := handlertest.Dispatch(ctx, ma, handlertest.NewMessage("flip a coin").InDM("D1"))
+package handlertest
+
+import (
+	"context"
+
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// BotID is the user ID of the bot in the Context, and the ID mentions of the
+// bot in a Message are for. Use it when building the *handler.MessageActions.
+const BotID = "U0BOT"
+
+// Context is a workqueue.Context for tests. The services it provides look
+// things up in its maps, which can be set before it's used.
+type Context struct {
+	context.Context
+
+	// Event is the metadata of the event being handled.
+	Event workqueue.EventMetadata
+
+	// Bot is the bot user. Its ID is BotID.
+	Bot slack.User
+
+	// Client is the Slack client, which is nil unless it's set, like to one
+	// for an httptest.Server. Responses don't use it.
+	Client *slack.Client
+
+	// Log is the logger, which discards everything.
+	Log zerolog.Logger
+
+	// Channels are the channels, by name.
+	Channels map[string]slack.Channel
+
+	// Users are the users, by ID.
+	Users map[string]slack.User
+
+	// Usergroups are the usergroups, by ID.
+	Usergroups map[string]slack.UserGroup
+
+	// Emoji are the custom emoji, by name.
+	Emoji map[string]string
+}
+
+// NewContext returns a new *Context with no channels, users, usergroups, or
+// emoji.
+func NewContext() *Context {
+	return &Context{
+		Context: context.Background(),
+		Event:   workqueue.EventMetadata{ID: "Ev0TEST"},
+		Bot:     slack.User{ID: BotID, Name: "gopher"},
+		Log:     zerolog.Nop(),
+
+		Channels:   make(map[string]slack.Channel),
+		Users:      make(map[string]slack.User),
+		Usergroups: make(map[string]slack.UserGroup),
+		Emoji:      make(map[string]string),
+	}
+}
+
+// AddChannel adds a channel to be found by its name, and returns the Context.
+func (c *Context) AddChannel(id, name string) *Context {
+	var ch slack.Channel
+
+	ch.ID = id
+	ch.Name = name
+
+	c.Channels[name] = ch
+
+	return c
+}
+
+// AddUser adds a user to be found by their ID, and returns the Context.
+func (c *Context) AddUser(u slack.User) *Context {
+	c.Users[u.ID] = u
+
+	return c
+}
+
+var _ workqueue.Context = (*Context)(nil)
+
+// Meta satisfies workqueue.Context.
+func (c *Context) Meta() workqueue.EventMetadata { return c.Event }
+
+// Logger satisfies workqueue.Context.
+func (c *Context) Logger() *zerolog.Logger { return &c.Log }
+
+// Slack satisfies workqueue.Context.
+func (c *Context) Slack() *slack.Client { return c.Client }
+
+// Self satisfies workqueue.Context.
+func (c *Context) Self() slack.User { return c.Bot }
+
+// ChannelSvc satisfies workqueue.Context.
+func (c *Context) ChannelSvc() workqueue.ChannelSvc { return channels(c.Channels) }
+
+// UserSvc satisfies workqueue.Context.
+func (c *Context) UserSvc() workqueue.UserSvc { return users(c.Users) }
+
+// UsergroupSvc satisfies workqueue.Context.
+func (c *Context) UsergroupSvc() workqueue.UsergroupSvc { return usergroups(c.Usergroups) }
+
+// EmojiSvc satisfies workqueue.Context.
+func (c *Context) EmojiSvc() workqueue.EmojiSvc { return emoji(c.Emoji) }
+
+type channels map[string]slack.Channel
+
+func (c channels) Lookup(name string) (slack.Channel, bool, error) {
+	ch, ok := c[name]
+	return ch, !ok, nil
+}
+
+type users map[string]slack.User
+
+func (u users) User(id string) (slack.User, bool, error) {
+	user, ok := u[id]
+	return user, !ok, nil
+}
+
+type usergroups map[string]slack.UserGroup
+
+func (g usergroups) Usergroup(id string) (slack.UserGroup, bool, error) {
+	group, ok := g[id]
+	return group, !ok, nil
+}
+
+type emoji map[string]string
+
+func (e emoji) Emoji(name string) (string, bool, error) {
+	value, ok := e[name]
+	return value, !ok, nil
+}
@@ -0,0 +1,222 @@
+package handlertest
+
+import (
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// Message is a handler.Messenger for tests, built up from its text. It's in a
+// public channel, from a user, unless it's changed.
+type Message struct {
+	raw         string
+	channelID   string
+	channelType handler.ChannelType
+	userID      string
+	threadTS    string
+	messageTS   string
+	broadcast   bool
+	files       []slackevents.File
+	meta        *workqueue.EventMetadata
+}
+
+var _ handler.Messenger = (*Message)(nil)
+
+// NewMessage returns a new *Message with the text, which can have mentions
+// in Slack's format, like <@U1234>.
+func NewMessage(text string) *Message {
+	return &Message{
+		raw:         text,
+		channelID:   "C0TEST",
+		channelType: handler.ChannelPublic,
+		userID:      "U0TEST",
+		messageTS:   "1588334400.000100",
+	}
+}
+
+// InChannel moves the message to the public channel, and returns it.
+func (m *Message) InChannel(channelID string) *Message {
+	m.channelID, m.channelType = channelID, handler.ChannelPublic
+	return m
+}
+
+// InPrivate moves the message to the private channel, and returns it.
+func (m *Message) InPrivate(channelID string) *Message {
+	m.channelID, m.channelType = channelID, handler.ChannelPrivate
+	return m
+}
+
+// InDM moves the message to the DM with the bot, and returns it.
+func (m *Message) InDM(channelID string) *Message {
+	m.channelID, m.channelType = channelID, handler.ChannelDM
+	return m
+}
+
+// InThread moves the message to the thread, and returns it.
+func (m *Message) InThread(threadTS string) *Message {
+	m.threadTS = threadTS
+	return m
+}
+
+// Broadcast makes the message a thread reply also sent to the channel, and
+// returns it.
+func (m *Message) Broadcast(threadTS string) *Message {
+	m.threadTS, m.broadcast = threadTS, true
+	return m
+}
+
+// At sets the message's timestamp, and returns it.
+func (m *Message) At(messageTS string) *Message {
+	m.messageTS = messageTS
+	return m
+}
+
+// From sets who sent the message, and returns it.
+func (m *Message) From(userID string) *Message {
+	m.userID = userID
+	return m
+}
+
+// MentioningBot prefixes the message with a mention of the bot, and returns
+// it.
+func (m *Message) MentioningBot() *Message {
+	m.raw = "<@" + BotID + "> " + m.raw
+	return m
+}
+
+// WithEventMeta sets the metadata of the event the message came in, and
+// returns it. Otherwise, Dispatch uses the Context's Event.
+func (m *Message) WithEventMeta(meta workqueue.EventMetadata) *Message {
+	m.meta = &meta
+	return m
+}
+
+// WithFiles attaches the files to the message, and returns it.
+func (m *Message) WithFiles(files ...slackevents.File) *Message {
+	m.files = append(m.files, files...)
+	return m
+}
+
+// Message returns the handler.Message that would be built from the Slack
+// event for the message, to match against a *handler.MessageActions.
+func (m *Message) Message() handler.Message {
+	var subType string
+	if m.broadcast {
+		subType = "thread_broadcast"
+	}
+
+	return handler.NewMessage(m.channelID, slackChannelType(m.channelType), m.userID, m.threadTS, m.messageTS, subType, m.raw, m.files, m.EventMeta())
+}
+
+func slackChannelType(ct handler.ChannelType) string {
+	switch ct {
+	case handler.ChannelPrivate:
+		return "group"
+	case handler.ChannelDM:
+		return "im"
+	case handler.ChannelGroupDM:
+		return "mpim"
+	default:
+		return "channel"
+	}
+}
+
+// ChannelID satisfies handler.Messenger.
+func (m *Message) ChannelID() string { return m.channelID }
+
+// ChannelType satisfies handler.Messenger.
+func (m *Message) ChannelType() handler.ChannelType { return m.channelType }
+
+// UserID satisfies handler.Messenger.
+func (m *Message) UserID() string { return m.userID }
+
+// ThreadTS satisfies handler.Messenger.
+func (m *Message) ThreadTS() string { return m.threadTS }
+
+// MessageTS satisfies handler.Messenger.
+func (m *Message) MessageTS() string { return m.messageTS }
+
+// AllMentions satisfies handler.Messenger.
+func (m *Message) AllMentions() []mparser.Mention {
+	_, mentions := mparser.ParseAndSplice(m.raw, m.channelID)
+	return mentions
+}
+
+// UserMentions satisfies handler.Messenger. Like the messages handlers are
+// given, mentions of the bot are left out.
+func (m *Message) UserMentions() []mparser.Mention {
+	var mentions []mparser.Mention
+
+	for _, mention := range m.AllMentions() {
+		if mention.Type == mparser.TypeUser && mention.ID != BotID {
+			mentions = append(mentions, mention)
+		}
+	}
+
+	return mentions
+}
+
+// Text satisfies handler.Messenger.
+func (m *Message) Text() string {
+	text, _ := mparser.ParseAndSplice(m.raw, m.channelID)
+	return strings.TrimSpace(text)
+}
+
+// RawText satisfies handler.Messenger.
+func (m *Message) RawText() string { return m.raw }
+
+// BotMentioned satisfies handler.Messenger.
+func (m *Message) BotMentioned() bool {
+	for _, mention := range m.AllMentions() {
+		if mention.Type == mparser.TypeUser && mention.ID == BotID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Files satisfies handler.Messenger.
+func (m *Message) Files() []slackevents.File { return m.files }
+
+// IsThreadBroadcast satisfies handler.Messenger.
+func (m *Message) IsThreadBroadcast() bool { return m.broadcast }
+
+// PermalinkHint satisfies handler.Messenger.
+func (m *Message) PermalinkHint() handler.PermalinkHint { return m.Message().PermalinkHint() }
+
+// EventMeta satisfies handler.Messenger. It's the zero value unless it was set
+// with WithEventMeta.
+func (m *Message) EventMeta() workqueue.EventMetadata {
+	if m.meta == nil {
+		return workqueue.EventMetadata{}
+	}
+
+	return *m.meta
+}
+
+// Dispatch matches the message against the handlers, like the consumer does,
+// and calls each that matched with a Recorder. It returns the Recorder once
+// they've all been called, with the first error any of them returned.
+func Dispatch(ctx *Context, ma *handler.MessageActions, m *Message) (*Recorder, error) {
+	rec := &Recorder{}
+
+	var first error
+
+	if m.meta == nil {
+		mc := *m
+		mc.meta = &ctx.Event
+		m = &mc
+	}
+
+	for _, a := range ma.Match(m.Message()) {
+		if err := a.DoWith(ctx, rec); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return rec, first
+}
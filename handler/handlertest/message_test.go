@@ -0,0 +1,57 @@
+package handlertest
+
+import (
+	"testing"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMessage(t *testing.T) {
+	m := NewMessage("thanks <@U1>!").MentioningBot().InPrivate("G1").InThread("1.1").From("U2")
+
+	if got, want := m.Text(), "thanks !"; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+
+	if !m.BotMentioned() {
+		t.Error("BotMentioned() = false, want true")
+	}
+
+	want := []mparser.Mention{{Type: mparser.TypeUser, ID: "U1"}}
+	if diff := cmp.Diff(want, m.UserMentions()); diff != "" {
+		t.Errorf("UserMentions() differs (-want +got):\n%s", diff)
+	}
+
+	// the handler.Message is parsed the same way once it's matched
+	hm := m.Message()
+
+	if hm.ChannelID() != "G1" || hm.ChannelType() != handler.ChannelPrivate || hm.ThreadTS() != "1.1" || hm.UserID() != "U2" {
+		t.Errorf("Message() = %+v, want it in thread 1.1 of private channel G1 from U2", hm)
+	}
+
+	if got, want := hm.RawText(), "<@U0BOT> thanks <@U1>!"; got != want {
+		t.Errorf("Message().RawText() = %q, want %q", got, want)
+	}
+}
+
+func TestMessage_Broadcast(t *testing.T) {
+	m := NewMessage("hello").Broadcast("1.1").WithEventMeta(workqueue.EventMetadata{ID: "Ev1"})
+
+	hm := m.Message()
+
+	if !m.IsThreadBroadcast() || !hm.IsThreadBroadcast() {
+		t.Errorf("IsThreadBroadcast() = %t, Message().IsThreadBroadcast() = %t, want true", m.IsThreadBroadcast(), hm.IsThreadBroadcast())
+	}
+
+	want := handler.PermalinkHint{ChannelID: "C0TEST", TS: "1.1", InThread: true}
+	if got := hm.PermalinkHint(); got != want {
+		t.Errorf("Message().PermalinkHint() = %+v, want %+v", got, want)
+	}
+
+	if got := hm.EventMeta().ID; got != "Ev1" {
+		t.Errorf("Message().EventMeta().ID = %q, want %q", got, "Ev1")
+	}
+}
@@ -0,0 +1,152 @@
+package handlertest
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/slack-go/slack"
+)
+
+// Response is one call to a Recorder.
+type Response struct {
+	// Method is the Responder method called, like "Respond" or "React".
+	Method string
+
+	// Text is the message, or the emoji for React.
+	Text string
+
+	// Attachments are the attachments sent with the message. The text
+	// attachment of the TextAttachment methods is the first one.
+	Attachments []slack.Attachment
+
+	// Filetype and Content are the snippet's, for RespondSnippet, whose
+	// title is the Text.
+	Filetype string
+	Content  string
+}
+
+// Recorder is a handler.Responder recording the responses, rather than sending
+// them. It's safe for concurrent use.
+type Recorder struct {
+	// Err, if set, is returned from each call, after it's recorded.
+	Err error
+
+	// PermalinkURL is what Permalink returns.
+	PermalinkURL string
+
+	mu        sync.Mutex
+	responses []Response
+}
+
+var _ handler.Responder = (*Recorder)(nil)
+
+// Responses returns the responses recorded, in order.
+func (r *Recorder) Responses() []Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Response(nil), r.responses...)
+}
+
+// Text returns the text of each response, one per line, for comparing with
+// what's expected when the method and attachments don't matter.
+func (r *Recorder) Text() string {
+	var b strings.Builder
+
+	for _, resp := range r.Responses() {
+		b.WriteString(resp.Text)
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+func (r *Recorder) record(method, msg string, attachments []slack.Attachment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.responses = append(r.responses, Response{
+		Method:      method,
+		Text:        msg,
+		Attachments: attachments,
+	})
+
+	return r.Err
+}
+
+// React satisfies handler.Responder.
+func (r *Recorder) React(_ context.Context, emoji string) error {
+	return r.record("React", emoji, nil)
+}
+
+// Respond satisfies handler.Responder.
+func (r *Recorder) Respond(_ context.Context, msg string, attachments ...slack.Attachment) error {
+	return r.record("Respond", msg, attachments)
+}
+
+// RespondTo satisfies handler.Responder.
+func (r *Recorder) RespondTo(_ context.Context, msg string, attachments ...slack.Attachment) error {
+	return r.record("RespondTo", msg, attachments)
+}
+
+// RespondUnfurled satisfies handler.Responder.
+func (r *Recorder) RespondUnfurled(_ context.Context, msg string, attachments ...slack.Attachment) error {
+	return r.record("RespondUnfurled", msg, attachments)
+}
+
+// RespondTextAttachment satisfies handler.Responder.
+func (r *Recorder) RespondTextAttachment(_ context.Context, msg, attachment string) error {
+	return r.record("RespondTextAttachment", msg, []slack.Attachment{{Text: attachment}})
+}
+
+// RespondMentions satisfies handler.Responder.
+func (r *Recorder) RespondMentions(_ context.Context, msg string, attachments ...slack.Attachment) error {
+	return r.record("RespondMentions", msg, attachments)
+}
+
+// RespondMentionsUnfurled satisfies handler.Responder.
+func (r *Recorder) RespondMentionsUnfurled(_ context.Context, msg string, attachments ...slack.Attachment) error {
+	return r.record("RespondMentionsUnfurled", msg, attachments)
+}
+
+// RespondMentionsTextAttachment satisfies handler.Responder.
+func (r *Recorder) RespondMentionsTextAttachment(_ context.Context, msg, attachment string) error {
+	return r.record("RespondMentionsTextAttachment", msg, []slack.Attachment{{Text: attachment}})
+}
+
+// RespondEphemeral satisfies handler.Responder.
+func (r *Recorder) RespondEphemeral(_ context.Context, msg string, attachments ...slack.Attachment) error {
+	return r.record("RespondEphemeral", msg, attachments)
+}
+
+// RespondEphemeralTextAttachment satisfies handler.Responder.
+func (r *Recorder) RespondEphemeralTextAttachment(_ context.Context, msg, attachment string) error {
+	return r.record("RespondEphemeralTextAttachment", msg, []slack.Attachment{{Text: attachment}})
+}
+
+// RespondDM satisfies handler.Responder.
+func (r *Recorder) RespondDM(_ context.Context, msg string, attachments ...slack.Attachment) error {
+	return r.record("RespondDM", msg, attachments)
+}
+
+// RespondSnippet satisfies handler.Responder.
+func (r *Recorder) RespondSnippet(_ context.Context, title, filetype, content string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.responses = append(r.responses, Response{
+		Method:   "RespondSnippet",
+		Text:     title,
+		Filetype: filetype,
+		Content:  content,
+	})
+
+	return r.Err
+}
+
+// Permalink satisfies handler.Responder.
+func (r *Recorder) Permalink(context.Context) (string, error) {
+	return r.PermalinkURL, nil
+}
@@ -0,0 +1,458 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// Interactor is the interface to represent an incoming block_actions
+// interaction, like someone clicking a button in one of our messages.
+type Interactor interface {
+	// ActionID is the action_id of the block element that was interacted
+	// with.
+	ActionID() string
+
+	// BlockID is the block_id of the block containing the element.
+	BlockID() string
+
+	// Value is the value of the button clicked, or of the option selected
+	// in a menu.
+	Value() string
+
+	// UserID is the ID of the user who interacted with the element.
+	UserID() string
+
+	// ChannelID is the ID of the channel the message is in.
+	ChannelID() string
+
+	// MessageTS is the ID of the message containing the element.
+	MessageTS() string
+
+	// ThreadTS is the thread the message is in, if it's in one.
+	ThreadTS() string
+
+	// TriggerID can be used to open a modal in response to the interaction,
+	// for a few seconds after it happens.
+	TriggerID() string
+}
+
+type interactor struct {
+	actionID  string
+	blockID   string
+	value     string
+	userID    string
+	channelID string
+	messageTS string
+	threadTS  string
+	triggerID string
+}
+
+var _ Interactor = interactor{}
+
+func (i interactor) ActionID() string  { return i.actionID }
+func (i interactor) BlockID() string   { return i.blockID }
+func (i interactor) Value() string     { return i.value }
+func (i interactor) UserID() string    { return i.userID }
+func (i interactor) ChannelID() string { return i.channelID }
+func (i interactor) MessageTS() string { return i.messageTS }
+func (i interactor) ThreadTS() string  { return i.threadTS }
+func (i interactor) TriggerID() string { return i.triggerID }
+
+// Shortcut is the interface to represent an incoming message_action
+// interaction, sent when someone chooses one of our message shortcuts from a
+// message's menu.
+type Shortcut interface {
+	// CallbackID is the callback_id of the shortcut chosen.
+	CallbackID() string
+
+	// UserID is the ID of the user who chose the shortcut.
+	UserID() string
+
+	// ChannelID is the ID of the channel the message is in.
+	ChannelID() string
+
+	// MessageTS is the ID of the message the shortcut was used on.
+	MessageTS() string
+
+	// ThreadTS is the thread the message is in, if it's in one.
+	ThreadTS() string
+
+	// MessageUserID is the ID of the user who sent the message.
+	MessageUserID() string
+
+	// MessageText is the text of the message.
+	MessageText() string
+
+	// TriggerID can be used to open a modal in response to the shortcut, for
+	// a few seconds after it happens.
+	TriggerID() string
+}
+
+type shortcut struct {
+	callbackID    string
+	userID        string
+	channelID     string
+	messageTS     string
+	threadTS      string
+	messageUserID string
+	messageText   string
+	triggerID     string
+}
+
+var _ Shortcut = shortcut{}
+
+func (s shortcut) CallbackID() string    { return s.callbackID }
+func (s shortcut) UserID() string        { return s.userID }
+func (s shortcut) ChannelID() string     { return s.channelID }
+func (s shortcut) MessageTS() string     { return s.messageTS }
+func (s shortcut) ThreadTS() string      { return s.threadTS }
+func (s shortcut) MessageUserID() string { return s.messageUserID }
+func (s shortcut) MessageText() string   { return s.messageText }
+func (s shortcut) TriggerID() string     { return s.triggerID }
+
+// ViewSubmission is the interface to represent an incoming view_submission
+// interaction, sent when someone submits one of our modals.
+type ViewSubmission interface {
+	// CallbackID is the callback_id of the modal submitted.
+	CallbackID() string
+
+	// UserID is the ID of the user who submitted the modal.
+	UserID() string
+
+	// PrivateMetadata is the private_metadata the modal was opened with.
+	PrivateMetadata() string
+
+	// Value is the value of the input element with actionID, in the block
+	// with blockID.
+	Value(blockID, actionID string) string
+}
+
+type viewSubmission struct {
+	callbackID      string
+	userID          string
+	privateMetadata string
+	values          map[string]map[string]slack.BlockAction
+}
+
+var _ ViewSubmission = viewSubmission{}
+
+func (v viewSubmission) CallbackID() string      { return v.callbackID }
+func (v viewSubmission) UserID() string          { return v.userID }
+func (v viewSubmission) PrivateMetadata() string { return v.privateMetadata }
+
+func (v viewSubmission) Value(blockID, actionID string) string {
+	ba, ok := v.values[blockID][actionID]
+	if !ok {
+		return ""
+	}
+
+	if len(ba.Value) > 0 {
+		return ba.Value
+	}
+
+	return ba.SelectedOption.Value
+}
+
+// InteractionActionFn is a function for handlers to take actions against
+// block_actions interactions. Any responses are sent in a thread on the message
+// that was interacted with.
+type InteractionActionFn func(ctx workqueue.Context, ia Interactor, r Responder) error
+
+// ShortcutActionFn is a function for handlers to take actions against
+// message_action interactions. Any responses are sent in a thread on the
+// message the shortcut was used on.
+type ShortcutActionFn func(ctx workqueue.Context, s Shortcut, r Responder) error
+
+// ViewSubmissionActionFn is a function for handlers to take actions against
+// view_submission interactions. As a modal isn't in a channel, there is no
+// Responder; anything needing one should be kept in the modal's
+// private_metadata.
+type ViewSubmissionActionFn func(ctx workqueue.Context, vs ViewSubmission) error
+
+type interactionAction struct {
+	name string
+	fn   InteractionActionFn
+}
+
+type shortcutAction struct {
+	name string
+	fn   ShortcutActionFn
+}
+
+type viewSubmissionAction struct {
+	name string
+	fn   ViewSubmissionActionFn
+}
+
+// InteractionActions represents actions to be taken when someone interacts
+// with a block element, like a button or menu, in one of our messages, uses one
+// of our message shortcuts, or submits one of our modals.
+type InteractionActions struct {
+	shadow    bool
+	actions   map[string]interactionAction
+	shortcuts map[string]shortcutAction
+	views     map[string]viewSubmissionAction
+	l         zerolog.Logger
+}
+
+// NewInteractionActions returns an InteractionActions for use.
+func NewInteractionActions(shadowMode bool, l zerolog.Logger) *InteractionActions {
+	return &InteractionActions{
+		shadow:    shadowMode,
+		actions:   make(map[string]interactionAction),
+		shortcuts: make(map[string]shortcutAction),
+		views:     make(map[string]viewSubmissionAction),
+		l:         l,
+	}
+}
+
+// actionKey returns the part of the action_id used to find the action, which
+// is everything before the first colon. This lets the remainder carry state,
+// like which option in a list a button is for.
+func actionKey(actionID string) string {
+	if i := strings.IndexByte(actionID, ':'); i >= 0 {
+		return actionID[:i]
+	}
+
+	return actionID
+}
+
+// Handler satisfies workqueue.InteractionHandler.
+func (a *InteractionActions) Handler(ctx workqueue.Context, ic *slack.InteractionCallback) (bool, bool, error) {
+	if ic.User.ID == ctx.Self().ID {
+		return false, true, nil // no reason given, as it's normal and shouldn't be logged
+	}
+
+	if time.Since(ctx.Meta().Time) > 30*time.Second {
+		return false, true, fmt.Errorf("discarding interaction: older than 30 seconds")
+	}
+
+	switch ic.Type {
+	case slack.InteractionTypeBlockActions:
+		return a.blockActions(ctx, ic)
+
+	case slack.InteractionTypeMessageAction:
+		return a.messageAction(ctx, ic)
+
+	case slack.InteractionTypeViewSubmission:
+		return a.viewSubmission(ctx, ic)
+
+	default:
+		return false, true, fmt.Errorf("discarding interaction of type %s", ic.Type)
+	}
+}
+
+// threadResponse returns a response which sends messages in a thread on the
+// message, or the thread it's already in.
+func threadResponse(ctx workqueue.Context, channelID, userID, threadTS, messageTS string) response {
+	if len(threadTS) == 0 {
+		threadTS = messageTS
+	}
+
+	return response{
+		sc: ctx.Slack(),
+		m:  NewMessage(channelID, "", userID, threadTS, messageTS, "", "", nil, ctx.Meta()),
+	}
+}
+
+func (a *InteractionActions) blockActions(ctx workqueue.Context, ic *slack.InteractionCallback) (bool, bool, error) {
+	if len(ic.ActionCallback.BlockActions) == 0 {
+		return false, true, fmt.Errorf("discarding block_actions interaction without actions")
+	}
+
+	ba := ic.ActionCallback.BlockActions[0]
+
+	action, ok := a.actions[actionKey(ba.ActionID)]
+	if !ok {
+		return false, true, fmt.Errorf("discarding interaction with unknown action_id %s", ba.ActionID)
+	}
+
+	value := ba.Value
+	if len(value) == 0 {
+		value = ba.SelectedOption.Value
+	}
+
+	i := interactor{
+		actionID:  ba.ActionID,
+		blockID:   ba.BlockID,
+		value:     value,
+		userID:    ic.User.ID,
+		channelID: ic.Channel.ID,
+		messageTS: ic.Message.Timestamp,
+		threadTS:  ic.Message.ThreadTimestamp,
+		triggerID: ic.TriggerID,
+	}
+
+	resp := threadResponse(ctx, i.channelID, i.userID, i.threadTS, i.messageTS)
+
+	if a.shadow {
+		a.l.Info().
+			Str("channel_id", i.channelID).
+			Str("user_id", i.userID).
+			Str("action_id", i.actionID).
+			Bool("shadow_mode", true).
+			Msg("would take interaction action")
+
+		return false, false, nil
+	}
+
+	if err := action.fn(ctx, i, resp); err != nil {
+		a.l.Error().
+			Err(err).
+			Str("channel_id", i.channelID).
+			Str("user_id", i.userID).
+			Str("interaction_action", action.name).
+			Msg("failed to take action")
+
+		return false, false, rateLimited(err)
+	}
+
+	return false, false, nil
+}
+
+func (a *InteractionActions) messageAction(ctx workqueue.Context, ic *slack.InteractionCallback) (bool, bool, error) {
+	action, ok := a.shortcuts[ic.CallbackID]
+	if !ok {
+		return false, true, fmt.Errorf("discarding shortcut with unknown callback_id %s", ic.CallbackID)
+	}
+
+	s := shortcut{
+		callbackID:    ic.CallbackID,
+		userID:        ic.User.ID,
+		channelID:     ic.Channel.ID,
+		messageTS:     ic.Message.Timestamp,
+		threadTS:      ic.Message.ThreadTimestamp,
+		messageUserID: ic.Message.User,
+		messageText:   ic.Message.Text,
+		triggerID:     ic.TriggerID,
+	}
+
+	resp := threadResponse(ctx, s.channelID, s.userID, s.threadTS, s.messageTS)
+
+	if a.shadow {
+		a.l.Info().
+			Str("channel_id", s.channelID).
+			Str("user_id", s.userID).
+			Str("callback_id", s.callbackID).
+			Bool("shadow_mode", true).
+			Msg("would take shortcut action")
+
+		return false, false, nil
+	}
+
+	if err := action.fn(ctx, s, resp); err != nil {
+		a.l.Error().
+			Err(err).
+			Str("channel_id", s.channelID).
+			Str("user_id", s.userID).
+			Str("shortcut_action", action.name).
+			Msg("failed to take action")
+
+		return false, false, rateLimited(err)
+	}
+
+	return false, false, nil
+}
+
+func (a *InteractionActions) viewSubmission(ctx workqueue.Context, ic *slack.InteractionCallback) (bool, bool, error) {
+	action, ok := a.views[ic.View.CallbackID]
+	if !ok {
+		return false, true, fmt.Errorf("discarding view submission with unknown callback_id %s", ic.View.CallbackID)
+	}
+
+	vs := viewSubmission{
+		callbackID:      ic.View.CallbackID,
+		userID:          ic.User.ID,
+		privateMetadata: ic.View.PrivateMetadata,
+	}
+
+	if ic.View.State != nil {
+		vs.values = ic.View.State.Values
+	}
+
+	if a.shadow {
+		a.l.Info().
+			Str("user_id", vs.userID).
+			Str("callback_id", vs.callbackID).
+			Bool("shadow_mode", true).
+			Msg("would take view submission action")
+
+		return false, false, nil
+	}
+
+	if err := action.fn(ctx, vs); err != nil {
+		a.l.Error().
+			Err(err).
+			Str("user_id", vs.userID).
+			Str("view_submission_action", action.name).
+			Msg("failed to take action")
+
+		return false, false, rateLimited(err)
+	}
+
+	return false, false, nil
+}
+
+// Handle registers an InteractionActionFn to be taken when someone interacts
+// with a block element whose action_id is actionID, or starts with actionID
+// followed by a colon. Each actionID may only be registered once.
+func (a *InteractionActions) Handle(name, actionID string, fn InteractionActionFn) {
+	if len(actionID) == 0 {
+		panic("actionID cannot be empty string")
+	}
+
+	if strings.Contains(actionID, ":") {
+		panic("actionID cannot contain a colon")
+	}
+
+	if fn == nil {
+		panic("fn cannot be nil")
+	}
+
+	if _, ok := a.actions[actionID]; ok {
+		panic(fmt.Sprintf("actionID %s already registered", actionID))
+	}
+
+	a.actions[actionID] = interactionAction{name: name, fn: fn}
+}
+
+// HandleShortcut registers a ShortcutActionFn to be taken when someone uses
+// the message shortcut with callbackID, as configured in the App's settings.
+func (a *InteractionActions) HandleShortcut(name, callbackID string, fn ShortcutActionFn) {
+	if len(callbackID) == 0 {
+		panic("callbackID cannot be empty string")
+	}
+
+	if fn == nil {
+		panic("fn cannot be nil")
+	}
+
+	if _, ok := a.shortcuts[callbackID]; ok {
+		panic(fmt.Sprintf("shortcut callbackID %s already registered", callbackID))
+	}
+
+	a.shortcuts[callbackID] = shortcutAction{name: name, fn: fn}
+}
+
+// HandleViewSubmission registers a ViewSubmissionActionFn to be taken when
+// someone submits a modal opened with callbackID.
+func (a *InteractionActions) HandleViewSubmission(name, callbackID string, fn ViewSubmissionActionFn) {
+	if len(callbackID) == 0 {
+		panic("callbackID cannot be empty string")
+	}
+
+	if fn == nil {
+		panic("fn cannot be nil")
+	}
+
+	if _, ok := a.views[callbackID]; ok {
+		panic(fmt.Sprintf("view callbackID %s already registered", callbackID))
+	}
+
+	a.views[callbackID] = viewSubmissionAction{name: name, fn: fn}
+}
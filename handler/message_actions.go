@@ -3,11 +3,15 @@ package handler
 import (
 	"errors"
 	"fmt"
-	"math/rand"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/gobridge/gopherbot/internal/fuzzy"
+	"github.com/gobridge/gopherbot/internal/rng"
 	"github.com/gobridge/gopherbot/mparser"
 	"github.com/gobridge/gopherbot/workqueue"
 	"github.com/rs/zerolog"
@@ -25,11 +29,18 @@ type MessageActionFn func(ctx workqueue.Context, m Messenger, r Responder) error
 type MessageMatchFn func(shadowMode bool, m Messenger) bool
 
 type reactiveAction struct {
+	feature           string
 	description       string
 	onlyWhenMentioned bool
 	aliases           []string
 	fn                MessageActionFn
 	matchfn           MessageMatchFn
+	re                *regexp.Regexp
+	threading         Threading
+
+	// subtypes are the message subtypes it matches, besides messages
+	// without one. See HandleSubtypes.
+	subtypes []string
 }
 
 // MessageAction represents a single piece of interactive action to be taken.
@@ -39,16 +50,54 @@ type MessageAction struct {
 	fn          MessageActionFn
 
 	m Message
+
+	threading Threading
+	overrides ThreadingOverrides
+
+	// followUpKey identifies the handler for follow-up threads, if it can
+	// have them. See SetFollowUps.
+	followUpKey string
+	followUps   FollowUps
+
+	replies Replies
 }
 
 // Do is the MessageAction's enacter. It uses the Slack client from the
 // workqueue.Context to for handler functions to use.
 func (a MessageAction) Do(ctx workqueue.Context) error {
+	ct, err := a.channelThreading(ctx)
+	if err != nil {
+		ctx.Logger().Warn().
+			Err(err).
+			Str("channel_id", a.m.channelID).
+			Msg("failed to get channel threading policy")
+	}
+
 	r := response{
-		sc: ctx.Slack(),
-		m:  a.m,
+		sc:        ctx.Slack(),
+		m:         a.m,
+		threading: resolveThreading(ct, a.threading),
+		followUp:  a.followUp(ctx),
+		sent:      a.sentReplies(),
 	}
 
+	err = a.fn(ctx, a.m, r)
+
+	// the responses sent before a failure can still be deleted
+	a.rememberReplies(ctx, r.sent)
+
+	if err != nil {
+		return err
+	}
+
+	a.rememberFollowUp(ctx, r.followUp)
+
+	return nil
+}
+
+// DoWith calls the action's handler function with the Responder, rather than
+// one responding in Slack. It's for tests, like with package handlertest.
+func (a MessageAction) DoWith(ctx workqueue.Context, r Responder) error {
 	return a.fn(ctx, a.m, r)
 }
 
@@ -67,13 +116,34 @@ type MessageActions struct {
 	responses       map[string]reactiveAction
 	prefixResponses map[string]reactiveAction
 	reactions       map[string]reactiveAction
+	regexps         []reactiveAction
 	dynamic         []reactiveAction
+	miss            *reactiveAction
 
 	aliases map[string]string
 
+	suggestOnce sync.Once
+	suggestions *fuzzy.Index
+
 	selfID     string
 	shadowMode bool
+	flags      Flags
+	feature    string
 	logger     zerolog.Logger
+
+	reactionCooldown    Limiter
+	reactionProbability float64
+	rng                 rng.RNG
+
+	usage     UsageRecorder
+	localizer Localizer
+
+	threading Threading
+	overrides ThreadingOverrides
+	followUps FollowUps
+	replies   Replies
+
+	mentionsOnly MentionsOnly
 }
 
 // NewMessageActions returns a new MessageActions struct.
@@ -90,11 +160,89 @@ func NewMessageActions(selfID string, shadowMode bool, logger zerolog.Logger) (*
 		selfID:          selfID,
 		shadowMode:      shadowMode,
 		logger:          logger,
+
+		rng: rng.New(),
 	}
 
 	return ma, nil
 }
 
+// SetFlags makes the handlers consult f on each message for whether they're in
+// shadow mode, instead of the shadowMode given to NewMessageActions. The
+// handlers are under feature, except for dynamic handlers registered with
+// HandleDynamicFeature. It must be called before handling messages.
+func (m *MessageActions) SetFlags(f Flags, feature string) {
+	m.flags = f
+	m.feature = feature
+}
+
+// SetReactionLimits limits how often the reactions registered with
+// HandleReaction and HandleReactionRand fire, so they don't get spammy in busy
+// channels. The cooldown is asked whether each trigger can fire in the channel,
+// and can be nil for no cooldown. The probability is the chance of each
+// HandleReactionRand reaction firing, which never happens until it's set, like
+// to config.DefaultReactionRandomProbability. It must be called before handling
+// messages.
+func (m *MessageActions) SetReactionLimits(cooldown Limiter, probability float64) {
+	m.reactionCooldown = cooldown
+	m.reactionProbability = probability
+}
+
+// SetRNG sets where the randomness for HandleReactionRand comes from, instead
+// of crypto/rand, such as a seeded RNG in tests. It must be called before
+// handling messages.
+func (m *MessageActions) SetRNG(r rng.RNG) {
+	m.rng = r
+}
+
+// SetUsage records each trigger that fires to u, by channel. Dynamic handlers
+// and the miss handler aren't recorded, as they don't have a trigger. It must
+// be called before handling messages.
+func (m *MessageActions) SetUsage(u UsageRecorder) {
+	m.usage = u
+}
+
+// SetLocalizer sets where the messages for HandleMessage come from. It must be
+// called before registering them.
+func (m *MessageActions) SetLocalizer(l Localizer) {
+	m.localizer = l
+}
+
+// SetMentionsOnly makes the handlers that would otherwise match messages that
+// don't mention the bot skip them in the channels mo says are mentions-only:
+// the reactions, the regexp and prefix handlers, and the dynamic handlers
+// registered with HandleDynamicFeature. The dynamic handlers registered with
+// HandleDynamic, which generally keep track of or moderate messages rather
+// than respond to them, still match. It must be called before handling
+// messages.
+func (m *MessageActions) SetMentionsOnly(mo MentionsOnly) {
+	m.mentionsOnly = mo
+}
+
+// quiet returns whether the message is in a mentions-only channel, without
+// mentioning the bot.
+func (m *MessageActions) quiet(message Message) bool {
+	if m.mentionsOnly == nil || message.botMentioned || isDM(message.channelType) {
+		return false
+	}
+
+	return m.mentionsOnly.MentionsOnly(message.channelID)
+}
+
+// shadow returns whether the feature is in shadow mode. An empty feature is
+// the default one given to SetFlags.
+func (m *MessageActions) shadow(feature string) bool {
+	if m.flags == nil {
+		return m.shadowMode
+	}
+
+	if len(feature) == 0 {
+		feature = m.feature
+	}
+
+	return m.flags.Shadow(feature)
+}
+
 // Registered returns a list of registered handlers. You could use this to build
 // help output.
 func (m *MessageActions) Registered() []RegisteredMessageHandler {
@@ -122,7 +270,7 @@ func (m *MessageActions) Registered() []RegisteredMessageHandler {
 }
 
 func shouldDiscard(m *slackevents.MessageEvent) (string, bool) {
-	if len(m.SubType) > 0 && m.SubType != "thread_broadcast" {
+	if !subtypeAllowed(m.SubType) {
 		return fmt.Sprintf("message has subtype %s", m.SubType), true
 	}
 
@@ -156,7 +304,10 @@ func shouldDiscard(m *slackevents.MessageEvent) (string, bool) {
 
 // Handler is the method that should satisfy a workqueue handler.
 func (m *MessageActions) Handler(ctx workqueue.Context, me *slackevents.MessageEvent) (bool, bool, error) {
-	if me.User == ctx.Self().ID {
+	self := ctx.Self()
+
+	// bot messages may only have the bot ID of whoever posted them
+	if me.User == self.ID || (len(me.BotID) > 0 && me.BotID == self.Profile.BotID) {
 		ctx.Logger().Debug().Msg("ignoring message from self")
 		return false, false, nil // no reason given, as it's normal and shouldn't be logged
 	}
@@ -167,10 +318,12 @@ func (m *MessageActions) Handler(ctx workqueue.Context, me *slackevents.MessageE
 
 	actions := m.Match(
 		NewMessage(
-			me.Channel, me.ChannelType, me.User, me.ThreadTimeStamp, me.TimeStamp, me.SubType, me.Text, me.Files,
+			me.Channel, me.ChannelType, me.User, me.ThreadTimeStamp, me.TimeStamp, me.SubType, me.Text, me.Files, ctx.Meta(),
 		),
 	)
 
+	var limited error
+
 	for _, a := range actions {
 		ctx.Logger().Debug().
 			Str("action", a.Self).
@@ -182,6 +335,21 @@ func (m *MessageActions) Handler(ctx workqueue.Context, me *slackevents.MessageE
 				Err(err).
 				Str("action_description", a.Description).
 				Msg("failed to take action")
+
+			if limited == nil {
+				limited = rateLimited(err)
+			}
+
+			continue
+		}
+
+		if m.usage != nil && len(a.Self) > 0 {
+			if err := m.usage.Record(ctx, strings.TrimSpace(a.Self), me.Channel); err != nil {
+				ctx.Logger().Warn().
+					Err(err).
+					Str("action", a.Self).
+					Msg("failed to record usage")
+			}
 		}
 	}
 
@@ -190,7 +358,7 @@ func (m *MessageActions) Handler(ctx workqueue.Context, me *slackevents.MessageE
 		Int("actions", len(actions)).
 		Msg("message handled")
 
-	return false, false, nil
+	return false, false, limited
 }
 
 func onlyOtherUserMMentions(selfID string, mentions []mparser.Mention) ([]mparser.Mention, bool) {
@@ -249,27 +417,53 @@ func (m *MessageActions) Match(message Message) []MessageAction {
 	var aa []MessageAction
 
 	dm := isDM(message.channelType)
+	quiet := m.quiet(message)
 
-	if dm || message.botMentioned || !m.shadowMode {
+	if !quiet && (dm || message.botMentioned || !m.shadow("")) {
 		for k, v := range m.reactions {
-			if strings.Contains(lt, k) && (!v.onlyWhenMentioned || message.botMentioned) {
+			if v.onlyWhenMentioned && !message.botMentioned {
+				continue
+			}
+
+			if !v.handlesSubtype(message.subType) {
+				continue
+			}
+
+			// the substring check is much cheaper, and rules out most
+			// messages before checking the trigger is a whole word
+			if strings.Contains(lt, k) && v.re.MatchString(lt) {
 				a := MessageAction{
 					Self:        k,
 					Description: v.description,
 					fn:          v.fn,
 					m:           message,
+					threading:   v.threading,
+				}
+				aa = append(aa, a)
+			}
+		}
+
+		for _, v := range m.regexps {
+			if v.handlesSubtype(message.subType) && v.re.MatchString(t) {
+				a := MessageAction{
+					Self:        v.re.String(),
+					Description: v.description,
+					fn:          v.fn,
+					m:           message,
+					threading:   v.threading,
 				}
 				aa = append(aa, a)
 			}
 		}
 
 		for k, v := range m.prefixResponses {
-			if strings.HasPrefix(lt, k) {
+			if v.handlesSubtype(message.subType) && strings.HasPrefix(lt, k) {
 				a := MessageAction{
 					Self:        k,
 					Description: v.description,
 					fn:          v.fn,
 					m:           message,
+					threading:   v.threading,
 				}
 				aa = append(aa, a)
 			}
@@ -278,30 +472,61 @@ func (m *MessageActions) Match(message Message) []MessageAction {
 
 	if dm || message.botMentioned {
 		for k, v := range m.responses {
-			if strings.EqualFold(k, t) {
+			if v.handlesSubtype(message.subType) && strings.EqualFold(k, t) {
 				a := MessageAction{
 					Self:        k,
 					Description: v.description,
 					fn:          v.fn,
 					m:           message,
+					threading:   v.threading,
 				}
 				aa = append(aa, a)
 			}
 		}
+
+		// the dynamic handlers aren't commands, so don't stop this
+		if len(aa) == 0 && m.miss != nil && len(t) > 0 && m.miss.handlesSubtype(message.subType) {
+			a := MessageAction{
+				Description: m.miss.description,
+				fn:          m.miss.fn,
+				m:           message,
+			}
+			aa = append(aa, a)
+		}
 	}
 
 	for _, v := range m.dynamic {
-		if v.matchfn(m.shadowMode, message) {
+		if quiet && len(v.feature) > 0 {
+			continue
+		}
+
+		if !v.handlesSubtype(message.subType) {
+			continue
+		}
+
+		if v.matchfn(m.shadow(v.feature), message) {
 			a := MessageAction{
 				Description: v.description,
 				fn:          v.fn,
 				m:           message,
+				followUpKey: v.feature,
 			}
 
 			aa = append(aa, a)
 		}
 	}
 
+	for i := range aa {
+		aa[i].threading = resolveThreading(aa[i].threading, m.threading)
+		aa[i].overrides = m.overrides
+		aa[i].followUps = m.followUps
+		aa[i].replies = m.replies
+
+		if len(aa[i].Self) > 0 {
+			aa[i].followUpKey = aa[i].Self
+		}
+	}
+
 	return aa
 }
 
@@ -354,9 +579,41 @@ func (m *MessageActions) HandleStatic(trigger, description string, aliases []str
 	m.Handle(trigger, description, aliases, fn)
 }
 
+// HandleMessage is HandleStatic, except the content is the message with the
+// key, in the language the user has chosen. See SetLocalizer.
+func (m *MessageActions) HandleMessage(trigger, description string, aliases []string, key string) {
+	if m.localizer == nil {
+		panic("SetLocalizer must be called before HandleMessage")
+	}
+
+	if !m.localizer.Has(key) {
+		panic(fmt.Sprintf("message %q does not exist", key))
+	}
+
+	l := m.localizer
+
+	fn := func(ctx workqueue.Context, m Messenger, r Responder) error {
+		msg, err := l.Localize(ctx, m.UserID(), key)
+		if err != nil {
+			if len(msg) == 0 {
+				return err
+			}
+
+			ctx.Logger().Warn().
+				Err(err).
+				Str("message_key", key).
+				Msg("failed to localize message: using English")
+		}
+
+		return r.RespondMentions(ctx, msg)
+	}
+
+	m.Handle(trigger, description, aliases, fn)
+}
+
 // HandleStaticContains handles reacting to messages that contain trigger
-// anywhere in the message, except it responds instead of reacting with an
-// emoji.
+// anywhere in the message, as whole words, except it responds instead of
+// reacting with an emoji.
 func (m *MessageActions) HandleStaticContains(contains string, content ...string) {
 	if len(contains) == 0 {
 		panic("contains cannot be empty string")
@@ -373,6 +630,7 @@ func (m *MessageActions) HandleStaticContains(contains string, content ...string
 	msg := strings.Join(content, "\n")
 
 	m.reactions[contains] = reactiveAction{
+		re: triggerRegexp(contains),
 		fn: func(ctx workqueue.Context, m Messenger, r Responder) error {
 			return r.Respond(ctx, msg)
 		},
@@ -380,7 +638,9 @@ func (m *MessageActions) HandleStaticContains(contains string, content ...string
 }
 
 // HandleReaction handles reacting to messages that contain trigger anywhere in
-// the message.
+// the message, as whole words, subject to the cooldown set with SetReactionLimits. Each
+// reaction may list alternatives separated by ReactionAlternativeSep, in case a
+// custom emoji is removed.
 func (m *MessageActions) HandleReaction(trigger string, reactions ...string) {
 	if len(trigger) == 0 {
 		panic("trigger cannot be empty string")
@@ -395,12 +655,13 @@ func (m *MessageActions) HandleReaction(trigger string, reactions ...string) {
 	}
 
 	m.reactions[trigger] = reactiveAction{
-		fn: reactionFactory(false, 0, reactions...),
+		re: triggerRegexp(trigger),
+		fn: m.reactionFactory(trigger, false, true, reactions...),
 	}
 }
 
 // HandleMentionedReaction handles reacting to messages that contain trigger anywhere in
-// the message, but only if the bot is mentioned.
+// the message, as whole words, but only if the bot is mentioned.
 func (m *MessageActions) HandleMentionedReaction(trigger string, reactions ...string) {
 	if len(trigger) == 0 {
 		panic("trigger cannot be empty string")
@@ -416,12 +677,14 @@ func (m *MessageActions) HandleMentionedReaction(trigger string, reactions ...st
 
 	m.reactions[trigger] = reactiveAction{
 		onlyWhenMentioned: true,
-		fn:                reactionFactory(false, 0, reactions...),
+		re:                triggerRegexp(trigger),
+		fn:                m.reactionFactory(trigger, false, false, reactions...),
 	}
 }
 
 // HandleReactionRand handles reacting to messages that contain trigger anywhere in
-// the message, but only doing it periodically.
+// the message, as whole words, but only doing it periodically: with the probability and subject
+// to the cooldown set with SetReactionLimits.
 func (m *MessageActions) HandleReactionRand(trigger string, reactions ...string) {
 	if len(trigger) == 0 {
 		panic("trigger cannot be empty string")
@@ -436,20 +699,76 @@ func (m *MessageActions) HandleReactionRand(trigger string, reactions ...string)
 	}
 
 	m.reactions[trigger] = reactiveAction{
-		fn: reactionFactory(true, 0x2A, reactions...),
+		re: triggerRegexp(trigger),
+		fn: m.reactionFactory(trigger, true, true, reactions...),
 	}
 }
 
-func reactionFactory(random bool, randFactor int, reactions ...string) func(ctx workqueue.Context, m Messenger, r Responder) error {
-	return func(ctx workqueue.Context, m Messenger, r Responder) error {
-		if random && rand.Intn(150) != 0x2A { // not this time, maybe next time!
+// triggerRegexp returns a pattern matching the trigger only as whole words, so
+// "bot" doesn't match "botanist" or "robot". An end of the trigger that isn't a
+// letter, digit, or underscore, like the "︵" of a table flip, can be next to
+// anything.
+func triggerRegexp(trigger string) *regexp.Regexp {
+	p := regexp.QuoteMeta(trigger)
+
+	if r, _ := utf8.DecodeRuneInString(trigger); isWordRune(r) {
+		p = `\b` + p
+	}
+
+	if r, _ := utf8.DecodeLastRuneInString(trigger); isWordRune(r) {
+		p += `\b`
+	}
+
+	return regexp.MustCompile(p)
+}
+
+// isWordRune returns whether r is a word character, as far as \b is concerned.
+func isWordRune(r rune) bool {
+	return r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
+
+// HandleRegexp handles messages matching re anywhere in the message, such as
+// `(?i)\bgo ?lang\b`, regardless of the mentions in it. The message text is
+// matched as sent, so use (?i) to ignore case.
+func (m *MessageActions) HandleRegexp(re *regexp.Regexp, fn MessageActionFn) {
+	if re == nil {
+		panic("re cannot be nil")
+	}
+
+	if fn == nil {
+		panic("fn cannot be nil")
+	}
+
+	m.regexps = append(m.regexps, reactiveAction{
+		re: re,
+		fn: fn,
+	})
+}
+
+func (m *MessageActions) reactionFactory(trigger string, random, cooldown bool, reactions ...string) MessageActionFn {
+	return func(ctx workqueue.Context, msg Messenger, r Responder) error {
+		if random && m.rng.Float64() >= m.reactionProbability { // not this time, maybe next time!
 			return nil
 		}
 
+		if cooldown && m.reactionCooldown != nil {
+			allowed, _, err := m.reactionCooldown.Allow(ctx, trigger+":"+msg.ChannelID())
+			if err != nil {
+				return fmt.Errorf("failed to check reaction cooldown: %w", err)
+			}
+
+			if !allowed {
+				ctx.Logger().Debug().
+					Str("trigger", trigger).
+					Msg("reaction trigger cooling down")
+
+				return nil
+			}
+		}
+
 		for _, reaction := range reactions {
-			if err := r.React(ctx, reaction); err != nil {
-				// if err := ctx.Slack().AddReactionContext(ctx, r, slack.ItemRef{Channel: channelID, Timestamp: messageTS}); err != nil {
-				return fmt.Errorf("failed to react with %s: %w", r, err)
+			if err := reactWithAlternatives(ctx, r, reaction); err != nil {
+				return fmt.Errorf("failed to react with %s: %w", reaction, err)
 			}
 		}
 
@@ -478,11 +797,73 @@ func (m *MessageActions) HandlePrefix(prefix, description string, fn MessageActi
 	}
 }
 
+// HandleMiss handles a DM or mention that didn't match any other handler,
+// besides the dynamic ones, such as to say what they might have meant with
+// Suggest. There can only be one.
+func (m *MessageActions) HandleMiss(description string, fn MessageActionFn) {
+	if fn == nil {
+		panic("fn cannot be nil")
+	}
+
+	if m.miss != nil {
+		panic("miss handler already exists")
+	}
+
+	m.miss = &reactiveAction{
+		description: description,
+		fn:          fn,
+	}
+}
+
+// Suggest returns up to n of the registered triggers, aliases, and prefixes
+// closest to text, the closest first, for when it didn't match any of them. The
+// suggestions are indexed the first time it's called, so it must be called
+// after registering the handlers.
+func (m *MessageActions) Suggest(text string, n int) []string {
+	m.suggestOnce.Do(func() {
+		terms := make([]string, 0, len(m.responses)+len(m.aliases)+len(m.prefixResponses))
+
+		for k := range m.responses {
+			terms = append(terms, k)
+		}
+
+		for k := range m.aliases {
+			terms = append(terms, k)
+		}
+
+		for k := range m.prefixResponses {
+			terms = append(terms, k)
+		}
+
+		m.suggestions = fuzzy.NewIndex(terms...)
+	})
+
+	if s := m.suggestions.Closest(text, n); len(s) > 0 {
+		return s
+	}
+
+	// a prefixed command, with its arguments, is only close by the first word
+	if f := strings.Fields(text); len(f) > 1 {
+		return m.suggestions.Closest(f[0], n)
+	}
+
+	return nil
+}
+
 // HandleDynamic allows you to define a handler where you control whether it
 // matches by providing your own MessageMatchFn. This allows for the handler to
 // be dynamic.
 func (m *MessageActions) HandleDynamic(matchFn MessageMatchFn, actionFn MessageActionFn) {
+	m.HandleDynamicFeature("", matchFn, actionFn)
+}
+
+// HandleDynamicFeature is HandleDynamic, except the shadowMode given to the
+// MessageMatchFn is that of feature, so it can be toggled separately from the
+// other handlers. See SetFlags. Unlike HandleDynamic, it doesn't match messages
+// in mentions-only channels unless the bot is mentioned. See SetMentionsOnly.
+func (m *MessageActions) HandleDynamicFeature(feature string, matchFn MessageMatchFn, actionFn MessageActionFn) {
 	ra := reactiveAction{
+		feature: feature,
 		fn:      actionFn,
 		matchfn: matchFn,
 	}
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rs/zerolog"
+)
+
+func noopAction(workqueue.Context, Messenger, Responder) error { return nil }
+
+func testMessageActions(tb testing.TB) *MessageActions {
+	ma, err := NewMessageActions("U0BOT", false, zerolog.Nop())
+	if err != nil {
+		tb.Fatalf("NewMessageActions() unexpected error: %v", err)
+	}
+
+	ma.HandleReaction("bot", "robot_face")
+	ma.HandleReaction("beer me", "beer")
+	ma.HandleReaction("rubber-duck", "duck")
+	ma.HandleMentionedReaction("thank", "gopher")
+	ma.HandleStaticContains("︵", "┬─┬ノ( º _ ºノ)")
+	ma.HandleRegexp(regexp.MustCompile(`(?i)\bgo ?lang\b`), noopAction)
+	ma.Handle("help", "show the commands", []string{"commands"}, noopAction)
+	ma.HandlePrefix("issue ", "link to an issue", noopAction)
+	ma.HandleMiss("suggest a command", noopAction)
+
+	return ma
+}
+
+func TestMessageActions_Match(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "word",
+			text: "is this a bot?",
+			want: []string{"bot"},
+		},
+		{
+			name: "case",
+			text: "BOT",
+			want: []string{"bot"},
+		},
+		{
+			name: "prefix_of_word",
+			text: "I'm a botanist",
+		},
+		{
+			name: "suffix_of_word",
+			text: "a robot",
+		},
+		{
+			name: "phrase",
+			text: "please beer me!",
+			want: []string{"beer me"},
+		},
+		{
+			name: "punctuation",
+			text: "where's my rubber-duck",
+			want: []string{"rubber-duck"},
+		},
+		{
+			name: "non_word_trigger",
+			text: "(╯°□°)╯︵ ┻━┻",
+			want: []string{"︵"},
+		},
+		{
+			name: "mentioned_only",
+			text: "thank you",
+		},
+		{
+			name: "mentioned",
+			text: "<@U0BOT> thank you",
+			want: []string{"thank"},
+		},
+		{
+			name: "regexp",
+			text: "I love GoLang and go lang",
+			want: []string{`(?i)\bgo ?lang\b`},
+		},
+		{
+			name: "regexp_no_match",
+			text: "have you tried golangci-lint?",
+		},
+		{
+			name: "command",
+			text: "<@U0BOT> help",
+			want: []string{"help"},
+		},
+		{
+			name: "miss",
+			text: "<@U0BOT> hepl",
+			want: []string{""},
+		},
+		{
+			name: "miss_not_mentioned",
+			text: "hepl",
+		},
+		{
+			name: "miss_only_mentioned",
+			text: "<@U0BOT>",
+		},
+	}
+
+	ma := testMessageActions(t)
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actions := ma.Match(NewMessage("C0PUBLIC", "channel", "U0USER", "", "1.2", "", tt.text, nil, workqueue.EventMetadata{}))
+
+			var got []string
+
+			for _, a := range actions {
+				got = append(got, a.Self)
+			}
+
+			sort.Strings(got)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Match() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type mentionsOnlyChannels map[string]bool
+
+func (c mentionsOnlyChannels) MentionsOnly(channelID string) bool { return c[channelID] }
+
+func TestMessageActions_Match_mentionsOnly(t *testing.T) {
+	tests := []struct {
+		name      string
+		channelID string
+		text      string
+		want      []string
+	}{
+		{
+			name:      "reaction",
+			channelID: "C0QUIET",
+			text:      "is this a bot?",
+			want:      []string{"dynamic"},
+		},
+		{
+			name:      "reaction_elsewhere",
+			channelID: "C0PUBLIC",
+			text:      "is this a bot?",
+			want:      []string{"bot", "dynamic", "playground"},
+		},
+		{
+			name:      "contains",
+			channelID: "C0QUIET",
+			text:      "(╯°□°)╯︵ ┻━┻",
+			want:      []string{"dynamic"},
+		},
+		{
+			name:      "regexp",
+			channelID: "C0QUIET",
+			text:      "I love golang",
+			want:      []string{"dynamic"},
+		},
+		{
+			name:      "prefix",
+			channelID: "C0QUIET",
+			text:      "issue 1234",
+			want:      []string{"dynamic"},
+		},
+		{
+			name:      "mentioned",
+			channelID: "C0QUIET",
+			text:      "<@U0BOT> is this a bot?",
+			want:      []string{"bot", "dynamic", "playground"},
+		},
+		{
+			name:      "command",
+			channelID: "C0QUIET",
+			text:      "<@U0BOT> help",
+			want:      []string{"dynamic", "help", "playground"},
+		},
+	}
+
+	ma := testMessageActions(t)
+	ma.HandleDynamic(func(bool, Messenger) bool { return true }, noopAction)
+	ma.HandleDynamicFeature("playground", func(bool, Messenger) bool { return true }, noopAction)
+	ma.SetMentionsOnly(mentionsOnlyChannels{"C0QUIET": true})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actions := ma.Match(NewMessage(tt.channelID, "channel", "U0USER", "", "1.2", "", tt.text, nil, workqueue.EventMetadata{}))
+
+			var got []string
+
+			for _, a := range actions {
+				switch {
+				case len(a.Self) > 0:
+					got = append(got, a.Self)
+				case len(a.followUpKey) > 0:
+					got = append(got, a.followUpKey)
+				default:
+					got = append(got, "dynamic")
+				}
+			}
+
+			sort.Strings(got)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Match() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMessageActions_Suggest(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "trigger",
+			text: "hepl",
+			want: []string{"help"},
+		},
+		{
+			name: "alias",
+			text: "comands",
+			want: []string{"commands"},
+		},
+		{
+			name: "prefix_with_args",
+			text: "isue 1234",
+			want: []string{"issue"},
+		},
+		{
+			name: "nothing_close",
+			text: "what do you think of generics?",
+		},
+	}
+
+	ma := testMessageActions(t)
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, ma.Suggest(tt.text, 3)); diff != "" {
+				t.Fatalf("Suggest() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func BenchmarkMessageActions_Match(b *testing.B) {
+	ma := testMessageActions(b)
+
+	// roughly what the consumer registers
+	for _, trigger := range []string{
+		"bbq", "ghost", "spacex", "buffalo", "gobuffalo", "spacemacs", "dragon",
+		"dargon", "ermergerd", "ermahgerd", "rubberduck", "rubber duck", "vim", "emacs",
+	} {
+		ma.HandleReaction(trigger, "gopher")
+	}
+
+	benchmarks := []struct {
+		name string
+		text string
+	}{
+		{"no_match", "Does anyone know why my goroutines are leaking when I close the channel early?"},
+		{"substring_only", "I'm a botanist who likes vimeo and ghostwriting"},
+		{"match", "rubber duck debugging with vim is the best"},
+	}
+
+	for _, bm := range benchmarks {
+		msg := NewMessage("C0PUBLIC", "channel", "U0USER", "", "1.2", "", bm.text, nil, workqueue.EventMetadata{})
+
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_ = ma.Match(msg)
+			}
+		})
+	}
+}
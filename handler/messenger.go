@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
 	"github.com/slack-go/slack/slackevents"
 )
 
@@ -100,6 +101,34 @@ type Messenger interface {
 
 	// Files are any files attached to the message
 	Files() []slackevents.File
+
+	// IsThreadBroadcast indicates the message is a thread reply that was
+	// also sent to the channel.
+	IsThreadBroadcast() bool
+
+	// PermalinkHint is the conversation a link to the message should point
+	// to, without asking Slack for its permalink.
+	PermalinkHint() PermalinkHint
+
+	// EventMeta is the metadata of the event the message came in, like when
+	// Slack sent it, and which of Slack's retries it was.
+	EventMeta() workqueue.EventMetadata
+}
+
+// PermalinkHint identifies the conversation a message is part of: the thread
+// it's in, or else the message itself. Responder.Permalink asks Slack for the
+// URL of the same message.
+type PermalinkHint struct {
+	// ChannelID is the channel, or DM, the message is in.
+	ChannelID string
+
+	// TS is the timestamp of the thread's parent message, if the message is
+	// in a thread, or else of the message. It's empty if there's no message,
+	// like for a slash command.
+	TS string
+
+	// InThread indicates TS is the thread's parent, rather than the message.
+	InThread bool
 }
 
 // Message is a singular message to be processed. Satisfies Messenger interface.
@@ -116,12 +145,14 @@ type Message struct {
 	botMentioned bool
 	rawText      string
 	files        []slackevents.File
+	meta         workqueue.EventMetadata
 }
 
 var _ Messenger = Message{}
 
-// NewMessage generates a new message from the various inputs.
-func NewMessage(channelID, channelType, userID, threadTS, messageTS, subType, text string, files []slackevents.File) Message {
+// NewMessage generates a new message from the various inputs, and the metadata
+// of the event it came in.
+func NewMessage(channelID, channelType, userID, threadTS, messageTS, subType, text string, files []slackevents.File, meta workqueue.EventMetadata) Message {
 	return Message{
 		channelID:   channelID,
 		channelType: strToChan(channelType),
@@ -131,6 +162,7 @@ func NewMessage(channelID, channelType, userID, threadTS, messageTS, subType, te
 		subType:     subType,
 		rawText:     text,
 		files:       files,
+		meta:        meta,
 	}
 }
 
@@ -169,3 +201,18 @@ func (m Message) BotMentioned() bool { return m.botMentioned }
 
 // Files satisfies the Messenger interface.
 func (m Message) Files() []slackevents.File { return m.files }
+
+// IsThreadBroadcast satisfies the Messenger interface.
+func (m Message) IsThreadBroadcast() bool { return m.subType == subtypeThreadBroadcast }
+
+// PermalinkHint satisfies the Messenger interface.
+func (m Message) PermalinkHint() PermalinkHint {
+	if len(m.threadTS) > 0 {
+		return PermalinkHint{ChannelID: m.channelID, TS: m.threadTS, InThread: true}
+	}
+
+	return PermalinkHint{ChannelID: m.channelID, TS: m.messageTS}
+}
+
+// EventMeta satisfies the Messenger interface.
+func (m Message) EventMeta() workqueue.EventMetadata { return m.meta }
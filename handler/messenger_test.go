@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+func TestMessage_context(t *testing.T) {
+	meta := workqueue.EventMetadata{ID: "Ev1", Time: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC), RetryNum: 1}
+
+	tests := []struct {
+		name      string
+		threadTS  string
+		subType   string
+		broadcast bool
+		hint      PermalinkHint
+	}{
+		{
+			name: "message",
+			hint: PermalinkHint{ChannelID: "C0PUBLIC", TS: "1.2"},
+		},
+		{
+			name:     "thread_reply",
+			threadTS: "1.1",
+			hint:     PermalinkHint{ChannelID: "C0PUBLIC", TS: "1.1", InThread: true},
+		},
+		{
+			name:      "thread_broadcast",
+			threadTS:  "1.1",
+			subType:   "thread_broadcast",
+			broadcast: true,
+			hint:      PermalinkHint{ChannelID: "C0PUBLIC", TS: "1.1", InThread: true},
+		},
+		{
+			name:    "bot_message",
+			subType: SubtypeBotMessage,
+			hint:    PermalinkHint{ChannelID: "C0PUBLIC", TS: "1.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMessage("C0PUBLIC", "channel", "U0USER", tt.threadTS, "1.2", tt.subType, "hi", nil, meta)
+
+			if got := m.IsThreadBroadcast(); got != tt.broadcast {
+				t.Errorf("IsThreadBroadcast() = %t, want %t", got, tt.broadcast)
+			}
+
+			if got := m.PermalinkHint(); got != tt.hint {
+				t.Errorf("PermalinkHint() = %+v, want %+v", got, tt.hint)
+			}
+
+			if got := m.EventMeta(); got != meta {
+				t.Errorf("EventMeta() = %+v, want %+v", got, meta)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// Reactor is the interface to represent an incoming reaction_added event.
+type Reactor interface {
+	// Reaction is the name of the emoji, without the surrounding colons.
+	Reaction() string
+
+	// UserID is the ID of the user who added the reaction.
+	UserID() string
+
+	// ItemUserID is the ID of the user who created the item reacted to.
+	ItemUserID() string
+
+	// ChannelID is the ID of the channel the reacted message is in.
+	ChannelID() string
+
+	// MessageTS is the ID of the message that was reacted to.
+	MessageTS() string
+}
+
+type reactor struct {
+	reaction   string
+	userID     string
+	itemUserID string
+	channelID  string
+	messageTS  string
+}
+
+var _ Reactor = reactor{}
+
+func (r reactor) Reaction() string   { return r.reaction }
+func (r reactor) UserID() string     { return r.userID }
+func (r reactor) ItemUserID() string { return r.itemUserID }
+func (r reactor) ChannelID() string  { return r.channelID }
+func (r reactor) MessageTS() string  { return r.messageTS }
+
+// ReactionAddedActionFn is a function for handlers to take actions against
+// reaction_added events. Any responses are sent in a thread on the message that
+// was reacted to.
+type ReactionAddedActionFn func(ctx workqueue.Context, ra Reactor, r Responder) error
+
+type reactionAddedAction struct {
+	name string
+	fn   ReactionAddedActionFn
+}
+
+// ReactionAddedActions represents actions to be taken when a reaction is added
+// to a message.
+type ReactionAddedActions struct {
+	shadow  bool
+	actions map[string][]reactionAddedAction
+	l       zerolog.Logger
+}
+
+// NewReactionAddedActions returns a ReactionAddedActions for use.
+func NewReactionAddedActions(shadowMode bool, l zerolog.Logger) *ReactionAddedActions {
+	return &ReactionAddedActions{
+		shadow:  shadowMode,
+		actions: make(map[string][]reactionAddedAction),
+		l:       l,
+	}
+}
+
+// Handler satisfies workqueue.ReactionAddedHandler.
+func (a *ReactionAddedActions) Handler(ctx workqueue.Context, ra *slackevents.ReactionAddedEvent) (bool, bool, error) {
+	if ra.User == ctx.Self().ID {
+		return false, true, nil // no reason given, as it's normal and shouldn't be logged
+	}
+
+	actions, ok := a.actions[ra.Reaction]
+	if !ok {
+		return false, true, nil // no reason given, as it's normal and shouldn't be logged
+	}
+
+	if ra.Item.Type != "message" {
+		return false, true, fmt.Errorf("discarding reaction to item of type %s", ra.Item.Type)
+	}
+
+	if time.Since(ctx.Meta().Time) > 30*time.Second {
+		return false, true, fmt.Errorf("discarding reaction: older than 30 seconds")
+	}
+
+	r := reactor{
+		reaction:   ra.Reaction,
+		userID:     ra.User,
+		itemUserID: ra.ItemUser,
+		channelID:  ra.Item.Channel,
+		messageTS:  ra.Item.Timestamp,
+	}
+
+	// responses go in a thread on the message reacted to
+	msg := NewMessage(r.channelID, "", r.userID, r.messageTS, r.messageTS, "", "", nil, ctx.Meta())
+
+	resp := response{
+		sc: ctx.Slack(),
+		m:  msg,
+	}
+
+	var limited error
+
+	for _, action := range actions {
+		if a.shadow {
+			a.l.Info().
+				Str("channel_id", r.channelID).
+				Str("user_id", r.userID).
+				Str("reaction", r.reaction).
+				Bool("shadow_mode", true).
+				Msg("would take reaction action")
+			continue
+		}
+
+		if err := action.fn(ctx, r, resp); err != nil {
+			a.l.Error().
+				Err(err).
+				Str("channel_id", r.channelID).
+				Str("user_id", r.userID).
+				Str("reaction_action", action.name).
+				Msg("failed to take action")
+
+			if limited == nil {
+				limited = rateLimited(err)
+			}
+		}
+	}
+
+	return false, false, limited
+}
+
+// Handle registers a ReactionAddedActionFn to be taken when the reaction emoji
+// is added to a message. The emoji should not include the surrounding colons.
+func (a *ReactionAddedActions) Handle(name, reaction string, fn ReactionAddedActionFn) {
+	if len(reaction) == 0 {
+		panic("reaction cannot be empty string")
+	}
+
+	if fn == nil {
+		panic("fn cannot be nil")
+	}
+
+	a.actions[reaction] = append(a.actions[reaction], reactionAddedAction{name: name, fn: fn})
+}
@@ -51,11 +51,40 @@ type Responder interface {
 	// RespondeDM is for sending a DM to the user instead of responding in
 	// the channel, or with an ephemeral message.
 	RespondDM(ctx context.Context, msg string, attachments ...slack.Attachment) error
+
+	// RespondSnippet responds in the channel or thread with the content as a
+	// Slack snippet, with the title and a filetype like "go" or "text" for
+	// highlighting. It's for content too long to read as a message. Where
+	// files can't be sent, it responds with the content as a text attachment.
+	RespondSnippet(ctx context.Context, title, filetype, content string) error
+
+	// Permalink returns the canonical URL for the thread the message was sent
+	// in. If the message isn't in a thread, it's the URL for the message
+	// itself.
+	Permalink(ctx context.Context) (string, error)
 }
 
 type response struct {
 	sc *slack.Client
 	m  Message
+
+	// threading is the policy for responses in the channel the message was
+	// in, which is ThreadingChannel if unset.
+	threading Threading
+
+	// followUp is the thread responses in the channel go in, if the same
+	// person used the same handler there recently, and records the thread
+	// they went in. It's nil if follow-ups aren't kept.
+	followUp *followUp
+
+	// sent collects the responses that were sent, other than ephemeral
+	// ones, so they can be remembered for deleting. It's nil if they
+	// aren't remembered.
+	sent *sentReplies
+
+	// responseURL is where responses in the channel are sent, for slash
+	// commands, which can be used in channels the bot isn't in.
+	responseURL string
 }
 
 // interface implementation check
@@ -75,46 +104,141 @@ func (r response) React(ctx context.Context, emoji string) error {
 }
 
 func (r response) Respond(ctx context.Context, msg string, attachments ...slack.Attachment) error {
-	return r.respond(ctx, false, false, false, false, r.m.channelID, r.m.threadTS, r.m.subType, msg, attachments...)
+	return r.respond(ctx, false, false, false, false, r.m.channelID, r.m.threadTS, msg, attachments...)
 }
 
 func (r response) RespondTo(ctx context.Context, msg string, attachments ...slack.Attachment) error {
-	return r.respond(ctx, true, false, false, false, r.m.channelID, r.m.threadTS, r.m.subType, msg, attachments...)
+	return r.respond(ctx, true, false, false, false, r.m.channelID, r.m.threadTS, msg, attachments...)
 }
 
 func (r response) RespondDM(ctx context.Context, msg string, attachments ...slack.Attachment) error {
-	return r.respond(ctx, false, false, false, false, r.m.userID, r.m.threadTS, r.m.subType, msg, attachments...)
+	return r.respond(ctx, false, false, false, false, r.m.userID, r.m.threadTS, msg, attachments...)
 }
 
 func (r response) RespondUnfurled(ctx context.Context, msg string, attachments ...slack.Attachment) error {
-	return r.respond(ctx, false, false, false, true, r.m.channelID, r.m.threadTS, r.m.subType, msg, attachments...)
+	return r.respond(ctx, false, false, false, true, r.m.channelID, r.m.threadTS, msg, attachments...)
 }
 
 func (r response) RespondTextAttachment(ctx context.Context, msg, attachment string) error {
-	return r.respond(ctx, false, false, false, false, r.m.channelID, r.m.threadTS, r.m.subType, msg, slack.Attachment{Text: attachment})
+	return r.respond(ctx, false, false, false, false, r.m.channelID, r.m.threadTS, msg, slack.Attachment{Text: attachment})
 }
 
 func (r response) RespondMentions(ctx context.Context, msg string, attachments ...slack.Attachment) error {
-	return r.respond(ctx, false, true, false, false, r.m.channelID, r.m.threadTS, r.m.subType, msg, attachments...)
+	return r.respond(ctx, false, true, false, false, r.m.channelID, r.m.threadTS, msg, attachments...)
 }
 
 func (r response) RespondMentionsUnfurled(ctx context.Context, msg string, attachments ...slack.Attachment) error {
-	return r.respond(ctx, false, true, false, true, r.m.channelID, r.m.threadTS, r.m.subType, msg, attachments...)
+	return r.respond(ctx, false, true, false, true, r.m.channelID, r.m.threadTS, msg, attachments...)
 }
 
 func (r response) RespondMentionsTextAttachment(ctx context.Context, msg, attachment string) error {
-	return r.respond(ctx, false, true, false, false, r.m.channelID, r.m.threadTS, r.m.subType, msg, slack.Attachment{Text: attachment})
+	return r.respond(ctx, false, true, false, false, r.m.channelID, r.m.threadTS, msg, slack.Attachment{Text: attachment})
 }
 
 func (r response) RespondEphemeral(ctx context.Context, msg string, attachments ...slack.Attachment) error {
-	return r.respond(ctx, true, false, true, false, r.m.channelID, r.m.threadTS, r.m.subType, msg, attachments...)
+	return r.respond(ctx, true, false, true, false, r.m.channelID, r.m.threadTS, msg, attachments...)
 }
 
 func (r response) RespondEphemeralTextAttachment(ctx context.Context, msg, attachment string) error {
-	return r.respond(ctx, true, false, true, false, r.m.channelID, r.m.threadTS, r.m.subType, msg, slack.Attachment{Text: attachment})
+	return r.respond(ctx, true, false, true, false, r.m.channelID, r.m.threadTS, msg, slack.Attachment{Text: attachment})
+}
+
+func (r response) RespondSnippet(ctx context.Context, title, filetype, content string) error {
+	// files can't be ephemeral, and a slash command's channel may not have
+	// the bot in it to upload to
+	if len(r.responseURL) > 0 || (r.threading == ThreadingEphemeral && !isDM(r.m.channelType)) {
+		return r.RespondTextAttachment(ctx, title, content)
+	}
+
+	inChannel := !isDM(r.m.channelType)
+	threadTS := r.m.threadTS
+
+	if inChannel && len(threadTS) == 0 && r.followUp != nil {
+		threadTS = r.followUp.ts
+	}
+
+	if inChannel && len(threadTS) == 0 && r.threading == ThreadingThread {
+		threadTS = r.m.messageTS
+	}
+
+	f, err := r.sc.UploadFileContext(ctx, slack.FileUploadParameters{
+		Content:         content,
+		Filetype:        filetype,
+		Title:           title,
+		Channels:        []string{r.m.channelID},
+		ThreadTimestamp: threadTS,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to UploadFileContext to channel %s: %w", r.m.channelID, err)
+	}
+
+	// the message sharing the file is the response
+	ts, ok := shareTS(f, r.m.channelID)
+	if !ok {
+		return nil
+	}
+
+	if inChannel && r.followUp != nil {
+		r.followUp.sent(threadTS, ts)
+	}
+
+	if r.sent != nil {
+		r.sent.add(r.m.channelID, threadTS, ts)
+	}
+
+	return nil
+}
+
+// shareTS returns the timestamp of the message sharing the file in the
+// channel.
+func shareTS(f *slack.File, channelID string) (string, bool) {
+	shares := f.Shares.Public[channelID]
+	if len(shares) == 0 {
+		shares = f.Shares.Private[channelID]
+	}
+
+	if len(shares) == 0 {
+		return "", false
+	}
+
+	return shares[0].Ts, true
 }
 
-func (r response) respond(ctx context.Context, mentionUser, useMentions, ephemeral, unfurled bool, channelID, threadTS, subType, msg string, attachments ...slack.Attachment) error {
+func (r response) Permalink(ctx context.Context) (string, error) {
+	// if we're in a thread, this links to the parent message
+	h := r.m.PermalinkHint()
+
+	link, err := r.sc.GetPermalinkContext(ctx, &slack.PermalinkParameters{
+		Channel: h.ChannelID,
+		Ts:      h.TS,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to GetPermalinkContext for channel %s ts %s: %w", h.ChannelID, h.TS, err)
+	}
+
+	return link, nil
+}
+
+func (r response) respond(ctx context.Context, mentionUser, useMentions, ephemeral, unfurled bool, channelID, threadTS, msg string, attachments ...slack.Attachment) error {
+	// the policy only applies in the channel the message was in, and can't
+	// make an ephemeral response public
+	inChannel := channelID == r.m.channelID && !ephemeral && !isDM(r.m.channelType)
+
+	if inChannel {
+		if r.threading == ThreadingEphemeral {
+			ephemeral, useMentions, inChannel = true, false, false
+		}
+
+		// follow-ups go in the thread of the earlier response
+		if inChannel && len(threadTS) == 0 && r.followUp != nil {
+			threadTS = r.followUp.ts
+		}
+
+		if inChannel && len(threadTS) == 0 && r.threading == ThreadingThread {
+			threadTS = r.m.messageTS
+		}
+	}
+
 	if useMentions && ephemeral {
 		return errors.New("cannot use mentions for ephemeral messages")
 	}
@@ -133,6 +257,31 @@ func (r response) respond(ctx context.Context, mentionUser, useMentions, ephemer
 		msg = fmt.Sprintf("%s %s", u.String(), msg)
 	}
 
+	// if it's a command that was triggered in a shared thread reply
+	// we should share our reply with the channel too
+	//
+	// TODO(theckman): re-enable this functionality once gopher is able to
+	// recognize thread_broadcast messages from itself. See TODO in
+	// message_actions.go for more context.
+	//
+	// if r.m.IsThreadBroadcast() {
+	// 	opts = append(opts, slack.MsgOptionBroadcast())
+	// }
+
+	// long responses are sent as several messages, in order, rather than
+	// being cut off by Slack
+	for i, p := range splitMessage(msg, attachments) {
+		if err := r.send(ctx, ephemeral, unfurled, inChannel, i == 0, channelID, threadTS, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send sends one part of a response. The first part of a response in the
+// channel is the one follow-ups are threaded on.
+func (r response) send(ctx context.Context, ephemeral, unfurled, inChannel, first bool, channelID, threadTS string, p messagePart) error {
 	var opts []slack.MsgOption
 
 	if unfurled {
@@ -144,25 +293,29 @@ func (r response) respond(ctx context.Context, mentionUser, useMentions, ephemer
 		)
 	}
 
-	opts = append(opts, slack.MsgOptionText(msg, false))
+	opts = append(opts, slack.MsgOptionText(p.text, false))
 
 	if len(threadTS) > 0 {
 		opts = append(opts, slack.MsgOptionTS(threadTS))
 	}
 
-	// if it's a command that was triggered in a shared thread reply
-	// we should share our reply with the channel too
-	//
-	// TODO(theckman): re-enable this functionality once gopher is able to
-	// recognize thread_broadcast messages from itself. See TODO in
-	// message_actions.go for more context.
-	//
-	// if len(subType) > 0 && subType == "thread_broadcast" {
-	// 	opts = append(opts, slack.MsgOptionBroadcast())
-	// }
+	if len(p.attachments) > 0 {
+		opts = append(opts, slack.MsgOptionAttachments(p.attachments...))
+	}
+
+	if len(r.responseURL) > 0 && channelID == r.m.channelID {
+		rt := slack.ResponseTypeInChannel
+		if ephemeral {
+			rt = slack.ResponseTypeEphemeral
+		}
+
+		opts = append(opts, slack.MsgOptionResponseURL(r.responseURL, rt))
+
+		if _, _, _, err := r.sc.SendMessageContext(ctx, channelID, opts...); err != nil {
+			return fmt.Errorf("failed to SendMessageContext to response URL: %w", err)
+		}
 
-	if len(attachments) > 0 {
-		opts = append(opts, slack.MsgOptionAttachments(attachments...))
+		return nil
 	}
 
 	if ephemeral {
@@ -170,9 +323,18 @@ func (r response) respond(ctx context.Context, mentionUser, useMentions, ephemer
 			return fmt.Errorf("failed to PostEphemeralContext to channel %s user %s: %w", channelID, r.m.userID, err)
 		}
 	} else {
-		if _, _, _, err := r.sc.SendMessageContext(ctx, channelID, opts...); err != nil {
+		_, ts, _, err := r.sc.SendMessageContext(ctx, channelID, opts...)
+		if err != nil {
 			return fmt.Errorf("failed to SendMessageContext: %w", err)
 		}
+
+		if first && inChannel && r.followUp != nil {
+			r.followUp.sent(threadTS, ts)
+		}
+
+		if r.sent != nil {
+			r.sent.add(channelID, threadTS, ts)
+		}
 	}
 
 	return nil
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// SlashCommand is the interface to represent someone invoking one of our slash
+// commands.
+type SlashCommand interface {
+	// Command is the command invoked, including the leading slash.
+	Command() string
+
+	// Text is everything after the command.
+	Text() string
+
+	// UserID is the ID of the user who invoked the command.
+	UserID() string
+
+	// ChannelID is the ID of the channel the command was invoked in.
+	ChannelID() string
+
+	// TriggerID can be used to open a modal in response to the command, for
+	// a few seconds after it's invoked.
+	TriggerID() string
+
+	// ResponseURL is where responses to the command are sent, for up to 30
+	// minutes after it's invoked. It's for keeping in a modal's
+	// private_metadata, to build a Responder with NewSlashCommandResponder.
+	ResponseURL() string
+}
+
+type slashCommand struct {
+	command     string
+	text        string
+	userID      string
+	channelID   string
+	triggerID   string
+	responseURL string
+}
+
+var _ SlashCommand = slashCommand{}
+
+func (s slashCommand) Command() string     { return s.command }
+func (s slashCommand) Text() string        { return s.text }
+func (s slashCommand) UserID() string      { return s.userID }
+func (s slashCommand) ChannelID() string   { return s.channelID }
+func (s slashCommand) TriggerID() string   { return s.triggerID }
+func (s slashCommand) ResponseURL() string { return s.responseURL }
+
+// NewSlashCommandResponder returns a Responder for a slash command invoked by
+// userID in channelID, which responds using its responseURL. Responses work
+// whether or not the bot is in the channel, but there's no message to react to
+// or link to.
+func NewSlashCommandResponder(sc *slack.Client, channelID, userID, responseURL string) Responder {
+	return response{
+		sc:          sc,
+		m:           NewMessage(channelID, "", userID, "", "", "", "", nil, workqueue.EventMetadata{}),
+		responseURL: responseURL,
+	}
+}
+
+// SlashCommandActionFn is a function for handlers to take actions against
+// slash commands. Responses are sent to the channel the command was invoked in.
+type SlashCommandActionFn func(ctx workqueue.Context, sc SlashCommand, r Responder) error
+
+type slashCommandAction struct {
+	name string
+	fn   SlashCommandActionFn
+}
+
+// SlashCommandActions represents actions to be taken when someone invokes one
+// of our slash commands.
+type SlashCommandActions struct {
+	shadow   bool
+	commands map[string]slashCommandAction
+	l        zerolog.Logger
+}
+
+// NewSlashCommandActions returns a SlashCommandActions for use.
+func NewSlashCommandActions(shadowMode bool, l zerolog.Logger) *SlashCommandActions {
+	return &SlashCommandActions{
+		shadow:   shadowMode,
+		commands: make(map[string]slashCommandAction),
+		l:        l,
+	}
+}
+
+// Handler satisfies workqueue.SlashCommandHandler.
+func (a *SlashCommandActions) Handler(ctx workqueue.Context, sc *slack.SlashCommand) (bool, bool, error) {
+	// the response URL stops working after 30 minutes, but the trigger ID
+	// for opening a modal only lasts seconds
+	if time.Since(ctx.Meta().Time) > 30*time.Second {
+		return false, true, fmt.Errorf("discarding slash command: older than 30 seconds")
+	}
+
+	action, ok := a.commands[sc.Command]
+	if !ok {
+		return false, true, fmt.Errorf("discarding unknown slash command %s", sc.Command)
+	}
+
+	s := slashCommand{
+		command:     sc.Command,
+		text:        strings.TrimSpace(sc.Text),
+		userID:      sc.UserID,
+		channelID:   sc.ChannelID,
+		triggerID:   sc.TriggerID,
+		responseURL: sc.ResponseURL,
+	}
+
+	if a.shadow {
+		a.l.Info().
+			Str("channel_id", s.channelID).
+			Str("user_id", s.userID).
+			Str("command", s.command).
+			Bool("shadow_mode", true).
+			Msg("would take slash command action")
+
+		return false, false, nil
+	}
+
+	resp := NewSlashCommandResponder(ctx.Slack(), s.channelID, s.userID, s.responseURL)
+
+	if err := action.fn(ctx, s, resp); err != nil {
+		a.l.Error().
+			Err(err).
+			Str("channel_id", s.channelID).
+			Str("user_id", s.userID).
+			Str("slash_command_action", action.name).
+			Msg("failed to take action")
+
+		return false, false, rateLimited(err)
+	}
+
+	return false, false, nil
+}
+
+// Handle registers a SlashCommandActionFn to be taken when someone invokes
+// command, like "/playground", as configured in the App's settings.
+func (a *SlashCommandActions) Handle(name, command string, fn SlashCommandActionFn) {
+	if !strings.HasPrefix(command, "/") || len(command) < 2 {
+		panic("command must be a slash followed by its name")
+	}
+
+	if fn == nil {
+		panic("fn cannot be nil")
+	}
+
+	if _, ok := a.commands[command]; ok {
+		panic(fmt.Sprintf("command %s already registered", command))
+	}
+
+	a.commands[command] = slashCommandAction{name: name, fn: fn}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// maxTextLen is the most text sent in one message. Slack truncates
+	// messages past 40,000 characters, and this leaves room for the
+	// mentions and code fences added to each part.
+	maxTextLen = 39000
+
+	// maxAttachmentLen is the most attachment text sent in one message.
+	// Slack cuts off long attachments well before the message limit.
+	maxAttachmentLen = 8000
+
+	codeFence = "```"
+)
+
+// messagePart is one of the messages a response is sent as.
+type messagePart struct {
+	text        string
+	attachments []slack.Attachment
+}
+
+// splitMessage splits a response into the messages it's sent as, in order, so
+// none are over Slack's limits. The text goes first, split into as many
+// messages as it takes, and the attachments go with the last of them, split
+// the same way once they're too long to go together.
+func splitMessage(msg string, attachments []slack.Attachment) []messagePart {
+	texts := splitText(msg, maxTextLen)
+
+	parts := make([]messagePart, len(texts))
+	for i, t := range texts {
+		parts[i].text = t
+	}
+
+	var size int
+
+	for _, a := range attachments {
+		for i, chunk := range splitText(a.Text, maxAttachmentLen) {
+			// the rest of the attachment only goes with its first part,
+			// so titles and fields aren't repeated
+			ac := a
+			if i > 0 {
+				ac = slack.Attachment{Color: a.Color}
+			}
+
+			ac.Text = chunk
+
+			if last := parts[len(parts)-1]; len(last.attachments) > 0 && size+len(chunk) > maxAttachmentLen {
+				parts = append(parts, messagePart{})
+				size = 0
+			}
+
+			parts[len(parts)-1].attachments = append(parts[len(parts)-1].attachments, ac)
+			size += len(chunk)
+		}
+	}
+
+	return parts
+}
+
+// splitText splits s into chunks of at most n bytes, preferring to split
+// between lines, then between words. A code block split between chunks is
+// closed at the end of one and reopened at the start of the next, so it's
+// still formatted. It always returns at least one chunk.
+func splitText(s string, n int) []string {
+	if len(s) <= n {
+		return []string{s}
+	}
+
+	// room to close and reopen a code block
+	n -= 2 * (len(codeFence) + 1)
+
+	var (
+		chunks []string
+		inCode bool
+	)
+
+	for len(s) > 0 {
+		var chunk string
+
+		if len(s) <= n {
+			chunk, s = s, ""
+		} else {
+			cut, skip := splitPoint(s, n)
+			chunk, s = s[:cut], s[cut+skip:]
+		}
+
+		reopen := inCode
+
+		if strings.Count(chunk, codeFence)%2 == 1 {
+			inCode = !inCode
+		}
+
+		if reopen {
+			chunk = codeFence + "\n" + chunk
+		}
+
+		if inCode && len(s) > 0 {
+			chunk += "\n" + codeFence
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// splitPoint returns where to split s so the first part is at most n bytes,
+// and how many separating bytes after it to drop.
+func splitPoint(s string, n int) (cut, skip int) {
+	if i := strings.LastIndexByte(s[:n+1], '\n'); i > 0 {
+		return i, 1
+	}
+
+	if i := strings.LastIndexByte(s[:n+1], ' '); i > 0 {
+		return i, 1
+	}
+
+	// no whitespace to split on, so don't split a character
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return n, 0
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/slack-go/slack"
+)
+
+func Test_splitText(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want []string
+	}{
+		{
+			name: "short",
+			s:    "hello",
+			n:    20,
+			want: []string{"hello"},
+		},
+		{
+			name: "empty",
+			n:    20,
+			want: []string{""},
+		},
+		{
+			name: "lines",
+			s:    "first line\nsecond line\nthird line",
+			n:    20,
+			want: []string{"first line", "second line", "third line"},
+		},
+		{
+			name: "words",
+			s:    "one two three four five six",
+			n:    22,
+			want: []string{"one two three", "four five six"},
+		},
+		{
+			name: "no_whitespace",
+			s:    strings.Repeat("é", 10),
+			n:    17,
+			want: []string{"éééé", "éééé", "éé"},
+		},
+		{
+			name: "code_block",
+			s:    "look:\n```\nfmt.Println(1)\nfmt.Println(2)\n```\ndone",
+			n:    35,
+			want: []string{
+				"look:\n```\nfmt.Println(1)\n```",
+				"```\nfmt.Println(2)\n```\ndone",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitText(tt.s, tt.n)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("splitText() differs (-want +got):\n%s", diff)
+			}
+
+			for _, c := range got {
+				if len(c) > tt.n {
+					t.Errorf("chunk %q is %d bytes, more than %d", c, len(c), tt.n)
+				}
+			}
+		})
+	}
+}
+
+func Test_splitMessage(t *testing.T) {
+	line := strings.Repeat("x", 99) + "\n"
+	long := strings.Repeat(line, 100) // 10,000 bytes
+
+	t.Run("short", func(t *testing.T) {
+		got := splitMessage("hi", []slack.Attachment{{Text: "a"}, {Text: "b"}})
+
+		want := []messagePart{{text: "hi", attachments: []slack.Attachment{{Text: "a"}, {Text: "b"}}}}
+
+		if diff := cmp.Diff(want, got, cmp.AllowUnexported(messagePart{})); diff != "" {
+			t.Fatalf("splitMessage() differs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("long_attachment", func(t *testing.T) {
+		got := splitMessage("Here's the list", []slack.Attachment{{Title: "list", Color: "good", Text: long}})
+
+		if len(got) != 2 {
+			t.Fatalf("splitMessage() returned %d parts, want 2", len(got))
+		}
+
+		if got[0].text != "Here's the list" || len(got[1].text) != 0 {
+			t.Errorf("texts = %q, %q, want the text only in the first part", got[0].text, got[1].text)
+		}
+
+		first, second := got[0].attachments[0], got[1].attachments[0]
+
+		if first.Title != "list" || len(second.Title) != 0 || second.Color != "good" {
+			t.Errorf("attachments = %+v, %+v, want the title only on the first and the color on both", first, second)
+		}
+
+		if joined := first.Text + "\n" + second.Text; joined != long {
+			t.Errorf("attachment text wasn't kept in order")
+		}
+	})
+
+	t.Run("long_text", func(t *testing.T) {
+		got := splitMessage(strings.Repeat(long, 4), []slack.Attachment{{Text: "a"}})
+
+		if len(got) != 2 {
+			t.Fatalf("splitMessage() returned %d parts, want 2", len(got))
+		}
+
+		if len(got[0].attachments) != 0 || len(got[1].attachments) != 1 {
+			t.Errorf("attachments went with part %v, want only the last", got)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"fmt"
+)
+
+const (
+	// SubtypeBotMessage is the subtype of messages posted by integrations,
+	// like incoming webhooks and Workflow Builder, rather than a member.
+	SubtypeBotMessage = "bot_message"
+
+	// SubtypeMessageMetadata is the subtype of messages carrying metadata
+	// for other apps, like the events one bot posts for another to act on.
+	SubtypeMessageMetadata = "message_metadata"
+
+	// subtypeThreadBroadcast is the subtype of thread replies also sent to
+	// the channel, which are handled like any other message.
+	subtypeThreadBroadcast = "thread_broadcast"
+)
+
+// allowedSubtypes are the subtypes of message that handlers can opt in to
+// with HandleSubtypes. Messages with other subtypes, like edits and channel
+// joins, are discarded.
+var allowedSubtypes = map[string]struct{}{
+	SubtypeBotMessage:      {},
+	SubtypeMessageMetadata: {},
+}
+
+// subtypeAllowed returns whether messages with the subtype are handled at all.
+func subtypeAllowed(subtype string) bool {
+	if len(subtype) == 0 || subtype == subtypeThreadBroadcast {
+		return true
+	}
+
+	_, ok := allowedSubtypes[subtype]
+
+	return ok
+}
+
+// HandleSubtypes makes the handlers already registered for the triggers,
+// prefixes, regexps, or, for dynamic handlers, features, also match messages
+// with the subtypes, like SubtypeBotMessage, which are otherwise ignored. The
+// sender of such a message may not be a member, so its UserID can be empty.
+// It panics if a subtype isn't one handlers can opt in to, or a trigger isn't
+// registered.
+func (m *MessageActions) HandleSubtypes(subtypes []string, triggers ...string) {
+	for _, s := range subtypes {
+		if _, ok := allowedSubtypes[s]; !ok {
+			panic(fmt.Sprintf("subtype %q can't be handled", s))
+		}
+	}
+
+	for _, trigger := range triggers {
+		var found bool
+
+		for _, actions := range []map[string]reactiveAction{m.responses, m.prefixResponses, m.reactions} {
+			if a, ok := actions[trigger]; ok {
+				a.subtypes = append(a.subtypes, subtypes...)
+				actions[trigger] = a
+				found = true
+			}
+		}
+
+		for i := range m.regexps {
+			if m.regexps[i].re.String() == trigger {
+				m.regexps[i].subtypes = append(m.regexps[i].subtypes, subtypes...)
+				found = true
+			}
+		}
+
+		for i := range m.dynamic {
+			if len(trigger) > 0 && m.dynamic[i].feature == trigger {
+				m.dynamic[i].subtypes = append(m.dynamic[i].subtypes, subtypes...)
+				found = true
+			}
+		}
+
+		if !found {
+			panic(fmt.Sprintf("trigger %q isn't registered", trigger))
+		}
+	}
+}
+
+// handlesSubtype returns whether the handler matches messages with the
+// subtype.
+func (a reactiveAction) handlesSubtype(subtype string) bool {
+	if len(subtype) == 0 || subtype == subtypeThreadBroadcast {
+		return true
+	}
+
+	for _, s := range a.subtypes {
+		if s == subtype {
+			return true
+		}
+	}
+
+	return false
+}
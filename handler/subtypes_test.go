@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/google/go-cmp/cmp"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestMessageActions_HandleSubtypes(t *testing.T) {
+	ma := testMessageActions(t)
+	ma.HandleDynamicFeature("playground", func(bool, Messenger) bool { return true }, noopAction)
+	ma.HandleSubtypes([]string{SubtypeBotMessage}, "issue ", "playground")
+
+	tests := []struct {
+		name    string
+		subtype string
+		text    string
+		want    []string
+	}{
+		{
+			name: "no_subtype",
+			text: "issue 1234 bot",
+			want: []string{"", "bot", "issue "},
+		},
+		{
+			name:    "thread_broadcast",
+			subtype: "thread_broadcast",
+			text:    "issue 1234 bot",
+			want:    []string{"", "bot", "issue "},
+		},
+		{
+			name:    "opted_in",
+			subtype: SubtypeBotMessage,
+			text:    "issue 1234 bot",
+			want:    []string{"", "issue "},
+		},
+		{
+			name:    "not_opted_in",
+			subtype: SubtypeMessageMetadata,
+			text:    "issue 1234 bot",
+		},
+		{
+			name:    "miss",
+			subtype: SubtypeBotMessage,
+			text:    "<@U0BOT> hepl",
+			want:    []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actions := ma.Match(NewMessage("C0PUBLIC", "channel", "", "", "1.2", tt.subtype, tt.text, nil, workqueue.EventMetadata{}))
+
+			var got []string
+
+			for _, a := range actions {
+				got = append(got, a.Self)
+			}
+
+			sort.Strings(got)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Match() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMessageActions_HandleSubtypes_panics(t *testing.T) {
+	tests := []struct {
+		name     string
+		subtypes []string
+		trigger  string
+	}{
+		{name: "unknown_trigger", subtypes: []string{SubtypeBotMessage}, trigger: "nope"},
+		{name: "disallowed_subtype", subtypes: []string{"message_changed"}, trigger: "help"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ma := testMessageActions(t)
+
+			defer func() {
+				if recover() == nil {
+					t.Fatal("HandleSubtypes() didn't panic")
+				}
+			}()
+
+			ma.HandleSubtypes(tt.subtypes, tt.trigger)
+		})
+	}
+}
+
+func Test_shouldDiscard_subtypes(t *testing.T) {
+	tests := []struct {
+		subtype string
+		want    bool
+	}{
+		{subtype: "", want: false},
+		{subtype: "thread_broadcast", want: false},
+		{subtype: SubtypeBotMessage, want: false},
+		{subtype: SubtypeMessageMetadata, want: false},
+		{subtype: "message_changed", want: true},
+		{subtype: "channel_join", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subtype, func(t *testing.T) {
+			// the timestamp is malformed, so only the subtype's reason counts
+			reason, got := shouldDiscard(&slackevents.MessageEvent{SubType: tt.subtype, TimeStamp: "x"})
+
+			if isSubtype := got && reason != "timestamp malformed"; isSubtype != tt.want {
+				t.Fatalf("shouldDiscard() = %q, %t, want subtype discarded %t", reason, got, tt.want)
+			}
+		})
+	}
+}
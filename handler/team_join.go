@@ -30,6 +30,8 @@ type teamJoinAction struct {
 // TeamJoinActions represents actions to be taken on a team join event.
 type TeamJoinActions struct {
 	shadow  bool
+	flags   Flags
+	feature string
 	actions []teamJoinAction
 	l       zerolog.Logger
 }
@@ -39,6 +41,22 @@ func NewTeamJoinActions(shadowMode bool, l zerolog.Logger) *TeamJoinActions {
 	return &TeamJoinActions{shadow: shadowMode, l: l}
 }
 
+// SetFlags makes the actions consult f on each event for whether feature is in
+// shadow mode, instead of the shadowMode given to NewTeamJoinActions. It must
+// be called before handling events.
+func (t *TeamJoinActions) SetFlags(f Flags, feature string) {
+	t.flags = f
+	t.feature = feature
+}
+
+func (t *TeamJoinActions) isShadow() bool {
+	if t.flags == nil {
+		return t.shadow
+	}
+
+	return t.flags.Shadow(t.feature)
+}
+
 // Handler satisfies workqueue.TeamJoinHandler.
 func (t *TeamJoinActions) Handler(ctx workqueue.Context, tj *slack.TeamJoinEvent) (bool, bool, error) {
 	j := teamJoiner(tj.User)
@@ -47,7 +65,7 @@ func (t *TeamJoinActions) Handler(ctx workqueue.Context, tj *slack.TeamJoinEvent
 		Type: mparser.TypeUser,
 		ID:   j.ID,
 	}
-	msg := NewMessage(j.ID, "im", j.ID, "", "", "", "", nil)
+	msg := NewMessage(j.ID, "im", j.ID, "", "", "", "", nil, ctx.Meta())
 	msg.allMentions = []mparser.Mention{mention}
 	msg.userMentions = []mparser.Mention{mention}
 
@@ -58,8 +76,10 @@ func (t *TeamJoinActions) Handler(ctx workqueue.Context, tj *slack.TeamJoinEvent
 
 	var someWorked bool
 
+	shadow := t.isShadow()
+
 	for _, a := range t.actions {
-		if t.shadow {
+		if shadow {
 			t.l.Info().
 				Str("user_id", tj.User.ID).
 				Bool("shadow_mode", true).
@@ -75,7 +95,7 @@ func (t *TeamJoinActions) Handler(ctx workqueue.Context, tj *slack.TeamJoinEvent
 					Str("join_action", a.name).
 					Msg("failed to take action")
 
-				return false, false, nil
+				return false, false, rateLimited(err)
 			}
 
 			// force a retry
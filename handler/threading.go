@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// Threading is where the responses to a message in a channel go. Responses in
+// DMs, and those sent with RespondEphemeral or RespondDM, aren't affected.
+type Threading uint8
+
+const (
+	// ThreadingUnset defers to the policy at the next level: a channel's
+	// policy overrides the trigger's, which overrides the default.
+	ThreadingUnset Threading = iota
+
+	// ThreadingChannel responds where the message was: in its thread if it
+	// was in one, and in the channel otherwise.
+	ThreadingChannel
+
+	// ThreadingThread responds in a thread on the message, starting one if
+	// it wasn't in one.
+	ThreadingThread
+
+	// ThreadingEphemeral responds so only the person who sent the message
+	// sees it. Mentions of other users aren't added.
+	ThreadingEphemeral
+)
+
+func (t Threading) String() string {
+	switch t {
+	case ThreadingChannel:
+		return "channel"
+	case ThreadingThread:
+		return "thread"
+	case ThreadingEphemeral:
+		return "ephemeral"
+	default:
+		return "unset"
+	}
+}
+
+// ParseThreading parses the policy's name, as returned by String.
+func ParseThreading(s string) (Threading, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "channel":
+		return ThreadingChannel, nil
+	case "thread":
+		return ThreadingThread, nil
+	case "ephemeral":
+		return ThreadingEphemeral, nil
+	default:
+		return ThreadingUnset, fmt.Errorf("unknown threading policy %q", s)
+	}
+}
+
+// ThreadingOverrides returns the threading policy the admins set for a
+// channel, by name, or an empty string if they didn't. It's generally
+// implemented by a *threading.Store.
+type ThreadingOverrides interface {
+	Threading(ctx context.Context, channelID string) (string, error)
+}
+
+// resolveThreading returns the first of the policies that's set, or
+// ThreadingChannel if none are.
+func resolveThreading(ts ...Threading) Threading {
+	for _, t := range ts {
+		if t != ThreadingUnset {
+			return t
+		}
+	}
+
+	return ThreadingChannel
+}
+
+// SetThreading sets the default threading policy, for the handlers without one
+// of their own. It's ThreadingChannel if not set.
+func (m *MessageActions) SetThreading(t Threading) {
+	m.threading = t
+}
+
+// SetThreadingOverrides sets where the per-channel threading policies come
+// from, which take precedence over the others.
+func (m *MessageActions) SetThreadingOverrides(o ThreadingOverrides) {
+	m.overrides = o
+}
+
+// SetTriggerThreading sets the threading policy of the handlers already
+// registered for the triggers, or prefixes, overriding the default. It panics
+// if one isn't registered.
+func (m *MessageActions) SetTriggerThreading(t Threading, triggers ...string) {
+	for _, trigger := range triggers {
+		switch {
+		case setThreading(m.responses, trigger, t):
+		case setThreading(m.prefixResponses, trigger, t):
+		case setThreading(m.reactions, trigger, t):
+		default:
+			panic(fmt.Sprintf("trigger %q isn't registered", trigger))
+		}
+	}
+}
+
+func setThreading(actions map[string]reactiveAction, trigger string, t Threading) bool {
+	a, ok := actions[trigger]
+	if !ok {
+		return false
+	}
+
+	a.threading = t
+	actions[trigger] = a
+
+	return true
+}
+
+// channelThreading returns the policy set for the channel, if any. It's only
+// looked up for the channels it applies to.
+func (a MessageAction) channelThreading(ctx context.Context) (Threading, error) {
+	if a.overrides == nil || isDM(a.m.channelType) {
+		return ThreadingUnset, nil
+	}
+
+	s, err := a.overrides.Threading(ctx, a.m.channelID)
+	if err != nil || len(s) == 0 {
+		return ThreadingUnset, err
+	}
+
+	return ParseThreading(s)
+}
+
+// FollowUps remembers which thread a handler's responses to someone in a
+// channel went in, for a short while, so the responses to their follow-ups go
+// in the same thread instead of the channel. It's generally implemented by a
+// *threading.Store.
+type FollowUps interface {
+	// FollowUpThread returns the thread to respond in, or an empty string if
+	// there isn't one.
+	FollowUpThread(ctx context.Context, channelID, userID, key string) (string, error)
+
+	// RememberFollowUp records the thread the responses went in.
+	RememberFollowUp(ctx context.Context, channelID, userID, key, threadTS string) error
+}
+
+// SetFollowUps makes the responses to someone using the same handler again in
+// a channel, before f forgets, go in the thread the earlier responses went in.
+// Messages already in a thread, and the handlers without a trigger or feature
+// to tell them apart, aren't affected.
+func (m *MessageActions) SetFollowUps(f FollowUps) {
+	m.followUps = f
+}
+
+// followUp tracks the thread of the responses to one message.
+type followUp struct {
+	// ts is the thread to respond in, if any.
+	ts string
+
+	// thread is the thread the first response went in, or started.
+	thread string
+}
+
+// sent records where a response went: in the thread, or if it wasn't in
+// one, the thread that can be started on the response with the timestamp ts.
+func (f *followUp) sent(threadTS, ts string) {
+	if len(f.thread) > 0 {
+		return
+	}
+
+	f.thread = threadTS
+
+	if len(f.thread) == 0 {
+		f.thread = ts
+	}
+}
+
+// followUp returns the follow-up to respond with, or nil if the message can't
+// have one.
+func (a MessageAction) followUp(ctx workqueue.Context) *followUp {
+	if a.followUps == nil || len(a.followUpKey) == 0 || isDM(a.m.channelType) || len(a.m.threadTS) > 0 {
+		return nil
+	}
+
+	ts, err := a.followUps.FollowUpThread(ctx, a.m.channelID, a.m.userID, a.followUpKey)
+	if err != nil {
+		ctx.Logger().Warn().
+			Err(err).
+			Str("channel_id", a.m.channelID).
+			Msg("failed to get follow-up thread")
+	}
+
+	return &followUp{ts: ts}
+}
+
+// rememberFollowUp records the thread the responses went in, if any did.
+func (a MessageAction) rememberFollowUp(ctx workqueue.Context, f *followUp) {
+	if f == nil || len(f.thread) == 0 {
+		return
+	}
+
+	if err := a.followUps.RememberFollowUp(ctx, a.m.channelID, a.m.userID, a.followUpKey, f.thread); err != nil {
+		ctx.Logger().Warn().
+			Err(err).
+			Str("channel_id", a.m.channelID).
+			Msg("failed to remember follow-up thread")
+	}
+}
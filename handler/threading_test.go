@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+type fakeOverrides map[string]string
+
+func (f fakeOverrides) Threading(_ context.Context, channelID string) (string, error) {
+	return f[channelID], nil
+}
+
+func TestMessageActions_threading(t *testing.T) {
+	ma := testMessageActions(t)
+
+	ma.Handle("play", "run code in the playground", nil, noopAction)
+	ma.SetTriggerThreading(ThreadingThread, "play", "issue ")
+	ma.SetThreading(ThreadingEphemeral)
+	ma.SetThreadingOverrides(fakeOverrides{"C0NOISY": "thread", "C0BAD": "sideways"})
+
+	tests := []struct {
+		name    string
+		channel string
+		chType  string
+		text    string
+		want    Threading
+		err     bool
+	}{
+		{name: "default", channel: "C0PUBLIC", chType: "channel", text: "<@U0BOT> help", want: ThreadingEphemeral},
+		{name: "trigger", channel: "C0PUBLIC", chType: "channel", text: "<@U0BOT> play", want: ThreadingThread},
+		{name: "prefix", channel: "C0PUBLIC", chType: "channel", text: "issue 1234", want: ThreadingThread},
+		{name: "channel", channel: "C0NOISY", chType: "channel", text: "<@U0BOT> help", want: ThreadingThread},
+		{name: "bad_channel_policy", channel: "C0BAD", chType: "channel", text: "<@U0BOT> help", want: ThreadingEphemeral, err: true},
+		{name: "dm", channel: "C0NOISY", chType: "im", text: "help", want: ThreadingEphemeral},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actions := ma.Match(NewMessage(tt.channel, tt.chType, "U0USER", "", "1.2", "", tt.text, nil, workqueue.EventMetadata{}))
+			if len(actions) != 1 {
+				t.Fatalf("Match() returned %d actions, want 1", len(actions))
+			}
+
+			a := actions[0]
+
+			ct, err := a.channelThreading(context.Background())
+			if (err != nil) != tt.err {
+				t.Fatalf("channelThreading() error = %v, want error %t", err, tt.err)
+			}
+
+			if got := resolveThreading(ct, a.threading); got != tt.want {
+				t.Fatalf("threading = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageActions_SetTriggerThreading_unknown(t *testing.T) {
+	ma := testMessageActions(t)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("SetTriggerThreading() didn't panic for an unregistered trigger")
+		}
+	}()
+
+	ma.SetTriggerThreading(ThreadingThread, "nope")
+}
+
+func TestFollowUp_sent(t *testing.T) {
+	tests := []struct {
+		name string
+		sent [][2]string
+		want string
+	}{
+		{name: "in_thread", sent: [][2]string{{"1.1", "1.2"}}, want: "1.1"},
+		{name: "in_channel", sent: [][2]string{{"", "1.2"}}, want: "1.2"},
+		{name: "first_response", sent: [][2]string{{"", "1.2"}, {"1.2", "1.3"}}, want: "1.2"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			f := &followUp{}
+
+			for _, s := range tt.sent {
+				f.sent(s[0], s[1])
+			}
+
+			if f.thread != tt.want {
+				t.Fatalf("thread = %q, want %q", f.thread, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// Replies remembers who triggered the responses sent by the handlers, so they
+// can have them deleted for a short while. It's generally implemented by an
+// *undo.Store.
+type Replies interface {
+	// RememberReply records that the response with the timestamp ts, in the
+	// thread threadTS if it's in one, was triggered by the user.
+	RememberReply(ctx context.Context, channelID, threadTS, ts, userID string) error
+}
+
+// SetReplies makes the handlers' responses, other than ephemeral ones, be
+// remembered by rs.
+func (m *MessageActions) SetReplies(rs Replies) {
+	m.replies = rs
+}
+
+// sentReply is a response that was sent.
+type sentReply struct {
+	channelID string
+	threadTS  string
+	ts        string
+}
+
+// sentReplies collects the responses sent to one message.
+type sentReplies struct {
+	replies []sentReply
+}
+
+func (s *sentReplies) add(channelID, threadTS, ts string) {
+	s.replies = append(s.replies, sentReply{channelID: channelID, threadTS: threadTS, ts: ts})
+}
+
+// sentReplies returns where to collect the responses to the message, or nil if
+// they aren't remembered.
+func (a MessageAction) sentReplies() *sentReplies {
+	if a.replies == nil {
+		return nil
+	}
+
+	return &sentReplies{}
+}
+
+// rememberReplies records the responses that were sent, if any.
+func (a MessageAction) rememberReplies(ctx workqueue.Context, s *sentReplies) {
+	if s == nil {
+		return
+	}
+
+	for _, sr := range s.replies {
+		if err := a.replies.RememberReply(ctx, sr.channelID, sr.threadTS, sr.ts, a.m.userID); err != nil {
+			ctx.Logger().Warn().
+				Err(err).
+				Str("channel_id", sr.channelID).
+				Str("reply_ts", sr.ts).
+				Msg("failed to remember reply")
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+type fakeReplies struct{}
+
+func (fakeReplies) RememberReply(_ context.Context, _, _, _, _ string) error { return nil }
+
+func TestMessageActions_SetReplies(t *testing.T) {
+	ma := testMessageActions(t)
+
+	ma.Handle("play", "run code in the playground", nil, noopAction)
+
+	actions := ma.Match(NewMessage("C0PUBLIC", "channel", "U0USER", "", "1.2", "", "<@U0BOT> play", nil, workqueue.EventMetadata{}))
+	if len(actions) != 1 {
+		t.Fatalf("Match() returned %d actions, want 1", len(actions))
+	}
+
+	if s := actions[0].sentReplies(); s != nil {
+		t.Fatal("sentReplies() isn't nil without SetReplies")
+	}
+
+	ma.SetReplies(fakeReplies{})
+
+	actions = ma.Match(NewMessage("C0PUBLIC", "channel", "U0USER", "", "1.2", "", "<@U0BOT> play", nil, workqueue.EventMetadata{}))
+	if len(actions) != 1 {
+		t.Fatalf("Match() returned %d actions, want 1", len(actions))
+	}
+
+	s := actions[0].sentReplies()
+	if s == nil {
+		t.Fatal("sentReplies() is nil after SetReplies")
+	}
+
+	s.add("C0PUBLIC", "", "1.3")
+	s.add("C0PUBLIC", "1.3", "1.4")
+
+	want := []sentReply{
+		{channelID: "C0PUBLIC", ts: "1.3"},
+		{channelID: "C0PUBLIC", threadTS: "1.3", ts: "1.4"},
+	}
+
+	if len(s.replies) != len(want) {
+		t.Fatalf("replies = %+v, want %+v", s.replies, want)
+	}
+
+	for i := range want {
+		if s.replies[i] != want[i] {
+			t.Fatalf("replies[%d] = %+v, want %+v", i, s.replies[i], want[i])
+		}
+	}
+}
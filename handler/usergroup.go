@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// Usergroup is a usergroup (subteam) mentioned in a message, resolved using the
+// usergroup cache.
+type Usergroup struct {
+	// ID is the usergroup's ID, starting with S.
+	ID string
+
+	// Handle is the name used to mention the usergroup, without the @.
+	Handle string
+
+	// Name is the human-readable name of the usergroup.
+	Name string
+
+	// UserIDs are the IDs of the usergroup's members.
+	UserIDs []string
+}
+
+// Usergroups resolves the usergroups mentioned, like <!subteam^S1234>, to
+// their handles and members. Usergroups that aren't in the cache are skipped.
+func Usergroups(svc workqueue.UsergroupSvc, mentions []mparser.Mention) ([]Usergroup, error) {
+	var groups []Usergroup
+
+	for _, m := range mentions {
+		if m.Type != mparser.TypeGroup {
+			continue
+		}
+
+		g, notFound, err := svc.Usergroup(m.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up usergroup %s: %w", m.ID, err)
+		}
+
+		if notFound {
+			continue
+		}
+
+		groups = append(groups, Usergroup{
+			ID:      g.ID,
+			Handle:  g.Handle,
+			Name:    g.Name,
+			UserIDs: g.Users,
+		})
+	}
+
+	return groups, nil
+}
+
+// ExpandUsergroups returns the user mentions for the members of any usergroups
+// mentioned, without duplicates, followed by the other user mentions. This
+// lets a handler respond to the people behind something like @go-mods.
+func ExpandUsergroups(svc workqueue.UsergroupSvc, mentions []mparser.Mention) ([]mparser.Mention, error) {
+	groups, err := Usergroups(svc, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+
+	var users []mparser.Mention
+
+	add := func(id string) {
+		if _, ok := seen[id]; ok {
+			return
+		}
+
+		seen[id] = struct{}{}
+		users = append(users, mparser.Mention{Type: mparser.TypeUser, ID: id})
+	}
+
+	for _, g := range groups {
+		for _, id := range g.UserIDs {
+			add(id)
+		}
+	}
+
+	for _, m := range mentions {
+		if m.Type == mparser.TypeUser {
+			add(m.ID)
+		}
+	}
+
+	return users, nil
+}
@@ -0,0 +1,352 @@
+// Package announce is the announcement bus shared by the pollers. Pollers
+// publish typed Announcements onto a Redis stream, and a single Announcer
+// worker formats and posts them, so that retries, channel routing, and shadow
+// mode are all handled in one place.
+package announce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/changelog"
+	"github.com/gobridge/gopherbot/internal/idempotency"
+	"github.com/gobridge/gopherbot/internal/poller/events"
+	"github.com/gobridge/gopherbot/internal/poller/gerrit"
+	"github.com/gobridge/gopherbot/internal/poller/gotime"
+	"github.com/gobridge/gopherbot/internal/poller/mastodon"
+	"github.com/gobridge/gopherbot/internal/poller/reddit"
+	"github.com/gobridge/gopherbot/internal/poller/workshops"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	streamKey    = "announce:stream"
+	streamMaxLen = 1024
+	streamField  = "announcement"
+	groupName    = "announcer"
+	consumerName = "bgtasks"
+	redisTestKey = "announce:test_key"
+
+	// publishedKeyTTL is how long an Announcement's Key is remembered, to
+	// stop it being published again.
+	publishedKeyTTL = 30 * 24 * time.Hour
+)
+
+// Kind is the type of an Announcement.
+type Kind string
+
+const (
+	// CLMerged is for a merged Go CL, see Announcement.CL.
+	CLMerged Kind = "cl_merged"
+
+	// GoTimeLive is for the GoTimeFM live show starting, see
+	// Announcement.Episode.
+	GoTimeLive Kind = "gotime_live"
+
+	// MastodonStatus is for a post from one of the Mastodon accounts polled,
+	// see Announcement.Status. Its value predates there being more than the
+	// GoTimeFM account.
+	MastodonStatus Kind = "gotime_status"
+
+	// EventReminder is for an upcoming conference or meetup, see
+	// Announcement.Event.
+	EventReminder Kind = "event_reminder"
+
+	// CLDigest is for the Go CLs merged over a while, grouped into one
+	// message, see Announcement.CLs.
+	CLDigest Kind = "cl_digest"
+
+	// CLReviewed is for a Go CL approved for submission, see
+	// Announcement.CL.
+	CLReviewed Kind = "cl_reviewed"
+
+	// CLBackport is for a Go CL backporting a fix to a release branch, see
+	// Announcement.CL.
+	CLBackport Kind = "cl_backport"
+
+	// RedditPost is for an r/golang post upvoted past the score threshold,
+	// see Announcement.Post.
+	RedditPost Kind = "reddit_post"
+
+	// WorkshopUpcoming is for an upcoming GoBridge or GDI workshop, see
+	// Announcement.Workshop.
+	WorkshopUpcoming Kind = "workshop_upcoming"
+)
+
+// CL is a Go CL, merged unless the Kind says otherwise.
+type CL struct {
+	Number int64 `json:"number"`
+
+	// Project is the Gerrit project, like "go" or "tools".
+	Project string `json:"project,omitempty"`
+
+	// Title is the subject, prefixed with the project if it's not the main
+	// Go repository.
+	Title    string `json:"title"`
+	Subject  string `json:"subject"`
+	Link     string `json:"link"`
+	Message  string `json:"message"`
+	ChangeID string `json:"change_id"`
+}
+
+// Status is a post from a Mastodon account. Account, Name, and AvatarURL are
+// empty for posts announced when only the GoTimeFM account was polled.
+type Status struct {
+	URL       string `json:"url"`
+	Account   string `json:"account,omitempty"`
+	Name      string `json:"name,omitempty"`
+	AvatarURL string `json:"avatar_url,omitempty"`
+}
+
+// Event is a reminder for an upcoming event.
+type Event struct {
+	Event    events.Event    `json:"event"`
+	Reminder events.Reminder `json:"reminder"`
+}
+
+// Episode is the GoTimeFM episode being recorded live. Its fields are empty if
+// changelog.com hasn't published them.
+type Episode struct {
+	Title  string   `json:"title,omitempty"`
+	URL    string   `json:"url,omitempty"`
+	Guests []string `json:"guests,omitempty"`
+}
+
+// Post is a popular r/golang post.
+type Post struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	Permalink   string `json:"permalink"`
+	Score       int    `json:"score"`
+	NumComments int    `json:"num_comments"`
+}
+
+// Announcement is a single thing to announce. Kind says which of the other
+// fields is set.
+type Announcement struct {
+	Kind Kind      `json:"kind"`
+	At   time.Time `json:"at"`
+
+	// Key identifies what's being announced, so it's only announced once.
+	// It's set by Publish if empty.
+	Key string `json:"key,omitempty"`
+
+	CL     *CL     `json:"cl,omitempty"`
+	Status *Status `json:"status,omitempty"`
+	Event  *Event  `json:"event,omitempty"`
+	CLs    []CL    `json:"cls,omitempty"`
+
+	Episode  *Episode            `json:"episode,omitempty"`
+	Post     *Post               `json:"post,omitempty"`
+	Workshop *workshops.Workshop `json:"workshop,omitempty"`
+}
+
+// Publisher publishes Announcements onto the stream.
+type Publisher struct {
+	r         *redis.Client
+	published *idempotency.Store
+}
+
+// NewPublisher returns a new *Publisher.
+func NewPublisher(rc *redis.Client) (*Publisher, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	is, err := idempotency.NewStore(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build idempotency store: %w", err)
+	}
+
+	return &Publisher{r: rc, published: is}, nil
+}
+
+// Key returns the idempotency key for the Announcement, identifying the CL,
+// status, event reminder, or workshop. Going live is once per day at most.
+func Key(a Announcement) string {
+	switch {
+	case (a.Kind == CLMerged || a.Kind == CLReviewed || a.Kind == CLBackport) && a.CL != nil:
+		return fmt.Sprintf("%s:%d", a.Kind, a.CL.Number)
+	case a.Kind == MastodonStatus && a.Status != nil:
+		return fmt.Sprintf("%s:%s", a.Kind, a.Status.URL)
+	case a.Kind == EventReminder && a.Event != nil:
+		return fmt.Sprintf("%s:%s:%s", a.Kind, a.Event.Event.UID, a.Event.Reminder)
+	case a.Kind == CLDigest && len(a.CLs) > 0:
+		return fmt.Sprintf("%s:%d:%d:%d", a.Kind, a.CLs[0].Number, a.CLs[len(a.CLs)-1].Number, len(a.CLs))
+	case a.Kind == RedditPost && a.Post != nil:
+		return fmt.Sprintf("%s:%s", a.Kind, a.Post.ID)
+	case a.Kind == WorkshopUpcoming && a.Workshop != nil:
+		return fmt.Sprintf("%s:%s", a.Kind, a.Workshop.ID)
+	case a.Kind == GoTimeLive:
+		return fmt.Sprintf("%s:%s", a.Kind, a.At.UTC().Format("2006-01-02"))
+	default:
+		return ""
+	}
+}
+
+// Publish adds the Announcement to the stream, to be posted by the Announcer.
+// If an Announcement with the same Key was already published, it's skipped, so
+// pollers can safely retry after partial failures.
+func (p *Publisher) Publish(ctx context.Context, a Announcement) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if a.At.IsZero() {
+		a.At = time.Now()
+	}
+
+	if len(a.Key) == 0 {
+		a.Key = Key(a)
+	}
+
+	if len(a.Key) > 0 {
+		claimed, err := p.published.Claim(ctx, "announce:"+a.Key, publishedKeyTTL)
+		if err != nil {
+			return fmt.Errorf("failed to claim announcement key: %w", err)
+		}
+
+		if !claimed {
+			return nil
+		}
+	}
+
+	if err := p.add(ctx, a); err != nil {
+		if len(a.Key) > 0 {
+			rctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			if rerr := p.published.Release(rctx, "announce:"+a.Key); rerr != nil {
+				return fmt.Errorf("%w (and failed to release key: %v)", err, rerr)
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (p *Publisher) add(ctx context.Context, a Announcement) error {
+	j, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to marshal announcement: %w", err)
+	}
+
+	err = p.r.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamKey,
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{streamField: string(j)},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to XADD announcement: %w", err)
+	}
+
+	return nil
+}
+
+// Gerrit returns the gerrit.NotifyFunc that publishes merged CLs.
+func (p *Publisher) Gerrit() gerrit.NotifyFunc {
+	return p.GerritCLs(CLMerged)
+}
+
+// GerritCLs returns the gerrit.NotifyFunc that publishes the CLs matching a
+// query as the Kind, one of CLMerged, CLReviewed, or CLBackport.
+func (p *Publisher) GerritCLs(kind Kind) gerrit.NotifyFunc {
+	return func(ctx context.Context, cl gerrit.CL) error {
+		c := newCL(cl)
+
+		return p.Publish(ctx, Announcement{
+			Kind: kind,
+			CL:   &c,
+		})
+	}
+}
+
+func newCL(cl gerrit.CL) CL {
+	return CL{
+		Number:   cl.Number,
+		Project:  cl.Project,
+		Title:    cl.Message(),
+		Subject:  cl.Subject,
+		Link:     cl.Link(),
+		Message:  cl.Revisions[cl.CurrentRevision].Commit.Message,
+		ChangeID: cl.ChangeID,
+	}
+}
+
+// GoTime returns the gotime.NotifyFunc that publishes the show going live.
+func (p *Publisher) GoTime() gotime.NotifyFunc {
+	return func(ctx context.Context, ep changelog.Episode) error {
+		return p.Publish(ctx, Announcement{
+			Kind: GoTimeLive,
+			Episode: &Episode{
+				Title:  ep.Title,
+				URL:    ep.URL,
+				Guests: ep.Guests,
+			},
+		})
+	}
+}
+
+// Mastodon returns the mastodon.NotifyFunc that publishes new posts.
+func (p *Publisher) Mastodon() mastodon.NotifyFunc {
+	return func(ctx context.Context, a mastodon.Account, pr mastodon.Profile, statusURL string) error {
+		return p.Publish(ctx, Announcement{
+			Kind: MastodonStatus,
+			Status: &Status{
+				URL:       statusURL,
+				Account:   a.String(),
+				Name:      pr.Name(a),
+				AvatarURL: pr.AvatarURL,
+			},
+		})
+	}
+}
+
+// Reddit returns the reddit.NotifyFunc that publishes hot r/golang posts.
+func (p *Publisher) Reddit() reddit.NotifyFunc {
+	return func(ctx context.Context, rp reddit.Post) error {
+		return p.Publish(ctx, Announcement{
+			Kind: RedditPost,
+			Post: &Post{
+				ID:          rp.ID,
+				Title:       rp.Title,
+				Author:      rp.Author,
+				Permalink:   rp.Permalink,
+				Score:       rp.Score,
+				NumComments: rp.NumComments,
+			},
+		})
+	}
+}
+
+// Events returns the events.NotifyFunc that publishes event reminders.
+func (p *Publisher) Events() events.NotifyFunc {
+	return func(ctx context.Context, e events.Event, r events.Reminder) error {
+		return p.Publish(ctx, Announcement{
+			Kind:  EventReminder,
+			Event: &Event{Event: e, Reminder: r},
+		})
+	}
+}
+
+// Workshops returns the workshops.NotifyFunc that publishes upcoming
+// workshops.
+func (p *Publisher) Workshops() workshops.NotifyFunc {
+	return func(ctx context.Context, w workshops.Workshop) error {
+		return p.Publish(ctx, Announcement{
+			Kind:     WorkshopUpcoming,
+			Workshop: &w,
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package announce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// maxAttempts is how many times posting an announcement is tried before
+	// it's dropped.
+	maxAttempts = 3
+
+	// retryBackoff is how long to wait before the first retry, doubling for
+	// each one after that.
+	retryBackoff = 2 * time.Second
+
+	// postTimeout is how long each attempt at posting has.
+	postTimeout = 10 * time.Second
+)
+
+// Notifier is the part of *notify.Router the Announcer uses.
+type Notifier interface {
+	Notify(ctx context.Context, n notify.Notification) ([]notify.Delivery, error)
+	NotifyChannel(ctx context.Context, channelID string, n notify.Notification) ([]notify.Delivery, error)
+	Channels(s notify.Source) []string
+}
+
+// ChannelLookup finds channels by name, generally implemented by a
+// *cache.Channel.
+type ChannelLookup interface {
+	Lookup(name string) (channel slack.Channel, notFound bool, err error)
+}
+
+// Announcer reads Announcements from the stream and posts them.
+type Announcer struct {
+	r        *redis.Client
+	notifier Notifier
+	channels ChannelLookup
+	quiet    QuietHours
+	logger   zerolog.Logger
+}
+
+// NewAnnouncer returns a new *Announcer.
+func NewAnnouncer(rc *redis.Client, n Notifier, channels ChannelLookup, logger zerolog.Logger) (*Announcer, error) {
+	if n == nil {
+		return nil, fmt.Errorf("notifier cannot be nil")
+	}
+
+	if channels == nil {
+		return nil, fmt.Errorf("channel lookup cannot be nil")
+	}
+
+	return &Announcer{
+		r:        rc,
+		notifier: n,
+		channels: channels,
+		logger:   logger,
+	}, nil
+}
+
+// SetQuietHours sets the quiet hours of the channels that have them, during
+// which announcements for them are held, and posted once they're over. It's
+// not safe to call once the Announcer is running.
+func (a *Announcer) SetQuietHours(q QuietHours) {
+	a.quiet = q
+}
+
+// Run posts Announcements until the context is canceled. It starts with any
+// that were read, but not acknowledged, before the last shutdown.
+func (a *Announcer) Run(ctx context.Context) error {
+	err := a.r.XGroupCreateMkStream(ctx, streamKey, groupName, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	// "0" reads our pending messages, ">" reads new ones
+	id := "0"
+
+	var flushed time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			// noop
+		}
+
+		if len(a.quiet) > 0 && time.Since(flushed) >= flushInterval {
+			a.flush(ctx)
+			flushed = time.Now()
+		}
+
+		streams, err := a.r.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    groupName,
+			Consumer: consumerName,
+			Streams:  []string{streamKey, id},
+			Count:    10,
+			Block:    5 * time.Second,
+		}).Result()
+
+		if err != nil {
+			if err == redis.Nil {
+				continue
+			}
+
+			a.logger.Error().
+				Err(err).
+				Msg("failed to read announcements; trying again in 5 seconds")
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(5 * time.Second):
+			}
+
+			continue
+		}
+
+		var count int
+
+		for _, s := range streams {
+			for _, m := range s.Messages {
+				count++
+				a.handle(ctx, m)
+			}
+		}
+
+		if id == "0" && count == 0 {
+			id = ">"
+		}
+	}
+}
+
+// handle posts the announcement in the message, and acknowledges it whether or
+// not that succeeded, as it has already been retried. The only exception is
+// shutting down, where it's left pending to be posted after we restart.
+func (a *Announcer) handle(ctx context.Context, m redis.XMessage) {
+	logger := a.logger.With().Str("redis_message", m.ID).Logger()
+
+	ack := true
+
+	defer func() {
+		if !ack {
+			return
+		}
+
+		if err := a.r.XAck(ctx, streamKey, groupName, m.ID).Err(); err != nil {
+			logger.Error().
+				Err(err).
+				Msg("failed to acknowledge announcement")
+		}
+	}()
+
+	v, ok := m.Values[streamField].(string)
+	if !ok {
+		// trimmed from the stream while pending
+		logger.Warn().Msg("announcement missing from stream message")
+		return
+	}
+
+	var ann Announcement
+	if err := json.Unmarshal([]byte(v), &ann); err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to parse announcement")
+
+		return
+	}
+
+	logger = logger.With().Str("kind", string(ann.Kind)).Logger()
+
+	n, channelNames, err := format(ann)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to format announcement")
+
+		return
+	}
+
+	backoff := retryBackoff
+
+	// the channels it was posted in or held for, which retries skip
+	done := make(map[string]struct{})
+
+	for attempt := 1; ; attempt++ {
+		err = a.post(ctx, ann, n, channelNames, done)
+		if err == nil {
+			logger.Info().
+				Str("summary", n.Summary).
+				Int("attempt", attempt).
+				Msg("posted announcement")
+
+			return
+		}
+
+		if attempt == maxAttempts {
+			break
+		}
+
+		logger.Warn().
+			Err(err).
+			Int("attempt", attempt).
+			Str("retry_in", backoff.String()).
+			Msg("failed to post announcement")
+
+		select {
+		case <-ctx.Done():
+			logger.Info().Msg("context canceled: leaving announcement for next start")
+			ack = false
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+
+	logger.Error().
+		Err(err).
+		Str("summary", n.Summary).
+		Msg("giving up on announcement")
+}
+
+// post posts the announcement in the channels, or the channels routed for its
+// Source if channelNames is empty. It's held for the channels in quiet hours.
+// The IDs of the channels it's posted in or held for are added to done, and
+// the channels already in done are skipped, so a retry after some of them
+// failed only posts in the rest.
+func (a *Announcer) post(ctx context.Context, ann Announcement, n notify.Notification, channelNames []string, done map[string]struct{}) error {
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+
+	var ids []string
+
+	if len(channelNames) == 0 {
+		ids = a.notifier.Channels(n.Source)
+
+		if len(done) == 0 && !a.quiet.any(ids) {
+			ds, err := a.notifier.Notify(ctx, n)
+
+			for _, d := range ds {
+				done[d.ChannelID] = struct{}{}
+			}
+
+			return err
+		}
+	}
+
+	for _, name := range channelNames {
+		c, notFound, err := a.channels.Lookup(name)
+		if err != nil {
+			return fmt.Errorf("failed to look up #%s: %w", name, err)
+		}
+
+		if notFound {
+			return fmt.Errorf("channel #%s not found in cache", name)
+		}
+
+		ids = append(ids, c.ID)
+	}
+
+	now := time.Now()
+
+	for _, id := range ids {
+		if _, ok := done[id]; ok {
+			continue
+		}
+
+		if a.quiet.Quiet(id, now) {
+			if err := a.hold(ctx, id, ann); err != nil {
+				return err
+			}
+
+			done[id] = struct{}{}
+
+			a.logger.Info().
+				Str("channel_id", id).
+				Str("summary", n.Summary).
+				Msg("holding announcement until quiet hours are over")
+
+			continue
+		}
+
+		if _, err := a.notifier.NotifyChannel(ctx, id, n); err != nil {
+			return err
+		}
+
+		done[id] = struct{}{}
+	}
+
+	return nil
+}
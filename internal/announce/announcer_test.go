@@ -0,0 +1,122 @@
+package announce
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+type fakeNotifier struct {
+	routes []string
+
+	// failures is how many more times posting in each channel fails
+	failures map[string]int
+
+	posted []string
+}
+
+func (f *fakeNotifier) send(id string) error {
+	if f.failures[id] > 0 {
+		f.failures[id]--
+		return errors.New("channel_not_found")
+	}
+
+	f.posted = append(f.posted, id)
+
+	return nil
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, _ notify.Notification) ([]notify.Delivery, error) {
+	var ds []notify.Delivery
+	var err error
+
+	for _, id := range f.routes {
+		if serr := f.send(id); serr != nil {
+			err = serr
+			continue
+		}
+
+		ds = append(ds, notify.Delivery{ChannelID: id})
+	}
+
+	return ds, err
+}
+
+func (f *fakeNotifier) NotifyChannel(_ context.Context, channelID string, _ notify.Notification) ([]notify.Delivery, error) {
+	if err := f.send(channelID); err != nil {
+		return nil, err
+	}
+
+	return []notify.Delivery{{ChannelID: channelID}}, nil
+}
+
+func (f *fakeNotifier) Channels(_ notify.Source) []string { return f.routes }
+
+type fakeLookup map[string]string
+
+func (f fakeLookup) Lookup(name string) (slack.Channel, bool, error) {
+	id, ok := f[name]
+	if !ok {
+		return slack.Channel{}, true, nil
+	}
+
+	var c slack.Channel
+	c.ID = id
+
+	return c, false, nil
+}
+
+func TestAnnouncer_post_retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		routes       []string
+		channelNames []string
+		failures     map[string]int
+		want         []string
+	}{
+		{
+			name:     "routed",
+			routes:   []string{"C1", "C2", "C3"},
+			failures: map[string]int{"C2": 1},
+			want:     []string{"C1", "C3", "C2"},
+		},
+		{
+			name:         "named",
+			channelNames: []string{"golang-nuts", "general", "newbies"},
+			failures:     map[string]int{"C2": 1},
+			want:         []string{"C1", "C2", "C3"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &fakeNotifier{routes: tt.routes, failures: tt.failures}
+			lookup := fakeLookup{"golang-nuts": "C1", "general": "C2", "newbies": "C3"}
+
+			a, err := NewAnnouncer(nil, fn, lookup, zerolog.Nop())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			done := make(map[string]struct{})
+
+			if err := a.post(context.Background(), Announcement{}, notify.Notification{}, tt.channelNames, done); err == nil {
+				t.Fatal("post() first attempt error = <nil>")
+			}
+
+			if err := a.post(context.Background(), Announcement{}, notify.Notification{}, tt.channelNames, done); err != nil {
+				t.Fatalf("post() retry unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, fn.posted); diff != "" {
+				t.Fatalf("posted mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
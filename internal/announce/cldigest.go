@@ -0,0 +1,158 @@
+package announce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/poller/gerrit"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisCLBufferKey   = "announce:cl_buffer"          // list of CLs merged since the last digest
+	redisCLSinceKey    = "announce:cl_buffer:since"    // when the first CL in the buffer was added
+	redisCLFlushingKey = "announce:cl_buffer:flushing" // the CLs being published as a digest
+)
+
+// CLBuffer collects merged CLs in Redis, to publish them as one CLDigest
+// announcement once the first has waited for the interval. The buffer
+// survives restarts, and a digest that was being published when the process
+// stopped is published on the next Flush.
+type CLBuffer struct {
+	r        *redis.Client
+	pub      *Publisher
+	interval time.Duration
+}
+
+// NewCLBuffer returns a new *CLBuffer, publishing digests with pub.
+func NewCLBuffer(rc *redis.Client, pub *Publisher, interval time.Duration) (*CLBuffer, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive")
+	}
+
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &CLBuffer{r: rc, pub: pub, interval: interval}, nil
+}
+
+// Gerrit returns the gerrit.NotifyFunc that adds merged CLs to the buffer,
+// instead of publishing each one.
+func (b *CLBuffer) Gerrit() gerrit.NotifyFunc {
+	return func(ctx context.Context, cl gerrit.CL) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// noop
+		}
+
+		j, err := json.Marshal(newCL(cl))
+		if err != nil {
+			return fmt.Errorf("failed to marshal CL: %w", err)
+		}
+
+		_, err = b.r.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.RPush(ctx, redisCLBufferKey, string(j))
+			p.SetNX(ctx, redisCLSinceKey, time.Now().Unix(), 0)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to buffer CL %d: %w", cl.Number, err)
+		}
+
+		return nil
+	}
+}
+
+// Flush publishes the buffered CLs as a digest, if the first of them has
+// waited for the interval. It's meant to be run often, like every minute.
+func (b *CLBuffer) Flush(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	// finish a digest that was being published when we last stopped
+	if err := b.publishFlushing(ctx); err != nil {
+		return err
+	}
+
+	since, err := b.r.Get(ctx, redisCLSinceKey).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return nil
+		}
+
+		return fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	if time.Since(time.Unix(since, 0)) < b.interval {
+		return nil
+	}
+
+	n, err := b.r.LLen(ctx, redisCLBufferKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to LLEN redis key: %w", err)
+	}
+
+	// move the buffer aside in one step, so CLs merged while the digest is
+	// published start the next one
+	_, err = b.r.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, redisCLSinceKey)
+
+		if n > 0 {
+			p.Rename(ctx, redisCLBufferKey, redisCLFlushingKey)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start CL digest: %w", err)
+	}
+
+	return b.publishFlushing(ctx)
+}
+
+// publishFlushing publishes the CLs moved aside for a digest, if there are
+// any, then deletes them. The digest's Key stops it being published twice if
+// deleting them fails.
+func (b *CLBuffer) publishFlushing(ctx context.Context) error {
+	raw, err := b.r.LRange(ctx, redisCLFlushingKey, 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("failed to LRANGE redis key: %w", err)
+	}
+
+	if len(raw) == 0 {
+		return nil
+	}
+
+	cls := make([]CL, 0, len(raw))
+
+	for _, v := range raw {
+		var cl CL
+
+		if err := json.Unmarshal([]byte(v), &cl); err != nil {
+			return fmt.Errorf("failed to unmarshal buffered CL: %w", err)
+		}
+
+		cls = append(cls, cl)
+	}
+
+	if err := b.pub.Publish(ctx, Announcement{Kind: CLDigest, CLs: cls}); err != nil {
+		return fmt.Errorf("failed to publish CL digest: %w", err)
+	}
+
+	if err := b.r.Del(ctx, redisCLFlushingKey).Err(); err != nil {
+		return fmt.Errorf("failed to DEL redis key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,425 @@
+package announce
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gobridge/gopherbot/internal/digest"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/poller/events"
+	"github.com/gobridge/gopherbot/internal/poller/mastodon"
+	"github.com/gobridge/gopherbot/internal/poller/workshops"
+	"github.com/slack-go/slack"
+)
+
+const goTimeMsg = ":tada: GoTimeFM is now live :tada:"
+
+const goTimeLiveURL = "https://changelog.com/live"
+
+// changelogIconURL is the avatar of the Changelog social account, used when
+// reposting GoTimeFM statuses announced before the account's own was known.
+const changelogIconURL = "https://cdn.changelog.social/accounts/avatars/109/365/688/871/983/824/original/5d1bcf4960706353.png"
+
+// eventsChannelName is the channel event reminders are posted to. It's looked
+// up in the channel cache, as reminders are infrequent and we don't hardcode
+// its ID anywhere else.
+const eventsChannelName = "remotemeetup"
+
+// workshopChannelNames are the channels workshops are announced in, looked up
+// the same way as eventsChannelName.
+var workshopChannelNames = []string{eventsChannelName, "general"}
+
+// format builds the notification for the Announcement. If channelNames is not
+// empty, the notification goes to those channels instead of the ones routed
+// for its Source.
+func format(a Announcement) (n notify.Notification, channelNames []string, err error) {
+	n, channelNames, err = formatKind(a)
+	if err != nil {
+		return notify.Notification{}, nil, err
+	}
+
+	// the digest should reflect when it was announced, not when it was posted
+	if n.Digest != nil {
+		n.Digest.At = a.At
+	}
+
+	n.Key = a.Key
+
+	return n, channelNames, nil
+}
+
+func formatKind(a Announcement) (notify.Notification, []string, error) {
+	switch a.Kind {
+	case CLMerged, CLReviewed, CLBackport:
+		if a.CL == nil {
+			return notify.Notification{}, nil, fmt.Errorf("%s announcement missing CL", a.Kind)
+		}
+
+		return formatCL(a.Kind, *a.CL), nil, nil
+
+	case CLDigest:
+		if len(a.CLs) == 0 {
+			return notify.Notification{}, nil, fmt.Errorf("%s announcement missing CLs", a.Kind)
+		}
+
+		return formatCLDigest(a.CLs), nil, nil
+
+	case GoTimeLive:
+		return formatGoTimeLive(a.Episode), nil, nil
+
+	case MastodonStatus:
+		if a.Status == nil {
+			return notify.Notification{}, nil, fmt.Errorf("%s announcement missing status", a.Kind)
+		}
+
+		return formatStatus(*a.Status), nil, nil
+
+	case EventReminder:
+		if a.Event == nil {
+			return notify.Notification{}, nil, fmt.Errorf("%s announcement missing event", a.Kind)
+		}
+
+		return formatEvent(*a.Event), []string{eventsChannelName}, nil
+
+	case RedditPost:
+		if a.Post == nil {
+			return notify.Notification{}, nil, fmt.Errorf("%s announcement missing post", a.Kind)
+		}
+
+		return formatPost(*a.Post), nil, nil
+
+	case WorkshopUpcoming:
+		if a.Workshop == nil {
+			return notify.Notification{}, nil, fmt.Errorf("%s announcement missing workshop", a.Kind)
+		}
+
+		return formatWorkshop(*a.Workshop), workshopChannelNames, nil
+
+	default:
+		return notify.Notification{}, nil, fmt.Errorf("unknown announcement kind %q", a.Kind)
+	}
+}
+
+// clKinds are how the CLs of each Kind are announced. Only merged CLs are
+// included in the weekly digest.
+var clKinds = map[Kind]struct {
+	source notify.Source
+	state  string
+}{
+	CLMerged:   {source: notify.Gerrit, state: "merged"},
+	CLReviewed: {source: notify.GerritReviewed, state: "approved"},
+	CLBackport: {source: notify.GerritBackports, state: "backport"},
+}
+
+func formatCL(kind Kind, cl CL) notify.Notification {
+	k := clKinds[kind]
+
+	msg := fmt.Sprintf("[%d] %s: %s", cl.Number, cl.Title, cl.Link)
+
+	a := slack.Attachment{
+		Title:     cl.Subject,
+		TitleLink: cl.Link,
+		Text:      cl.Message,
+		Footer:    cl.ChangeID,
+	}
+
+	n := notify.Notification{
+		Source:   k.source,
+		Severity: notify.Info,
+		Summary:  fmt.Sprintf("%s CL %d", k.state, cl.Number),
+		Options: []slack.MsgOption{
+			slack.MsgOptionDisableLinkUnfurl(),
+			slack.MsgOptionText(msg, false),
+			slack.MsgOptionAttachments(a),
+		},
+	}
+
+	if kind == CLMerged {
+		n.Digest = &digest.Entry{
+			Section: "Merged CLs",
+			Title:   fmt.Sprintf("[%d] %s", cl.Number, cl.Title),
+			URL:     cl.Link,
+		}
+	}
+
+	return n
+}
+
+// clDigestText is the digest message for the CLs, grouped by project with the
+// main Go repository first.
+func clDigestText(cls []CL) string {
+	byProject := make(map[string][]CL)
+
+	var projects []string
+
+	for _, cl := range cls {
+		if _, ok := byProject[cl.Project]; !ok {
+			projects = append(projects, cl.Project)
+		}
+
+		byProject[cl.Project] = append(byProject[cl.Project], cl)
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i] == "go" || projects[j] == "go" {
+			return projects[i] == "go"
+		}
+
+		return projects[i] < projects[j]
+	})
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ":gopher: %s merged:", clCount(len(cls)))
+
+	for _, p := range projects {
+		name := p
+		if len(name) == 0 {
+			name = "unknown"
+		}
+
+		fmt.Fprintf(&b, "\n\n*%s*", name)
+
+		for _, cl := range byProject[p] {
+			fmt.Fprintf(&b, "\n<%s|[%d]> %s", cl.Link, cl.Number, cl.Subject)
+		}
+	}
+
+	return b.String()
+}
+
+func clCount(n int) string {
+	if n == 1 {
+		return "1 CL"
+	}
+
+	return fmt.Sprintf("%d CLs", n)
+}
+
+func formatCLDigest(cls []CL) notify.Notification {
+	return notify.Notification{
+		Source:   notify.Gerrit,
+		Severity: notify.Info,
+		Summary:  fmt.Sprintf("digest of %s", clCount(len(cls))),
+		Options: []slack.MsgOption{
+			slack.MsgOptionDisableLinkUnfurl(),
+			slack.MsgOptionText(clDigestText(cls), false),
+		},
+		Digest: &digest.Entry{
+			Section: "Merged CLs",
+			Title:   fmt.Sprintf("%s merged", clCount(len(cls))),
+			URL:     "https://go-review.googlesource.com/q/status:merged",
+		},
+	}
+}
+
+// goTimeBlocks is the card announcing the show going live, with the
+// episode's title, link, and guests if they're known.
+func goTimeBlocks(ep *Episode) []slack.Block {
+	text := "*" + goTimeMsg + "*"
+
+	if ep != nil && len(ep.Title) > 0 {
+		title := ep.Title
+		if len(ep.URL) > 0 {
+			title = fmt.Sprintf("<%s|%s>", ep.URL, ep.Title)
+		}
+
+		text += "\n" + title
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+	}
+
+	if ep != nil && len(ep.Guests) > 0 {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, "With "+strings.Join(ep.Guests, ", "), false, false),
+		))
+	}
+
+	listen := slack.NewButtonBlockElement("gotime_listen", "listen",
+		slack.NewTextBlockObject(slack.PlainTextType, "Listen live", false, false),
+	)
+	listen.URL = goTimeLiveURL
+
+	return append(blocks, slack.NewActionBlock("", listen))
+}
+
+func formatGoTimeLive(ep *Episode) notify.Notification {
+	d := &digest.Entry{
+		Section: "GoTimeFM",
+		Title:   "GoTimeFM was live",
+		URL:     "https://changelog.com/gotime",
+	}
+
+	if ep != nil && len(ep.Title) > 0 {
+		d.Title = fmt.Sprintf("GoTimeFM was live: %s", ep.Title)
+	}
+
+	if ep != nil && len(ep.URL) > 0 {
+		d.URL = ep.URL
+	}
+
+	return notify.Notification{
+		Source:   notify.GoTime,
+		Severity: notify.Important,
+		Summary:  "GoTime is live",
+		Options: []slack.MsgOption{
+			// the text is the fallback for notifications and clients
+			// without blocks
+			slack.MsgOptionText(goTimeMsg, false),
+			slack.MsgOptionBlocks(goTimeBlocks(ep)...),
+			slack.MsgOptionDisableLinkUnfurl(),
+		},
+		Digest: d,
+	}
+}
+
+// eventText is the reminder message for the event.
+func eventText(e events.Event, r events.Reminder) string {
+	when := "next week"
+	if r == events.DayBefore {
+		when = "soon"
+	}
+
+	title := e.Title
+	if len(e.URL) > 0 {
+		title = fmt.Sprintf("<%s|%s>", e.URL, e.Title)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ":spiral_calendar_pad: Coming up %s: *%s*", when, title)
+
+	if len(e.Location) > 0 {
+		fmt.Fprintf(&b, " (%s)", e.Location)
+	}
+
+	fmt.Fprintf(&b, ", starting <!date^%d^{date_short_pretty} at {time}|%s>.",
+		e.Start.Unix(), e.Start.UTC().Format("Jan 2 at 15:04 UTC"),
+	)
+
+	return b.String()
+}
+
+func formatEvent(ev Event) notify.Notification {
+	return notify.Notification{
+		Source:   notify.Events,
+		Severity: notify.Info,
+		Summary:  fmt.Sprintf("%s reminder for %s", ev.Reminder, ev.Event.Title),
+		Options: []slack.MsgOption{
+			slack.MsgOptionDisableLinkUnfurl(),
+			slack.MsgOptionText(eventText(ev.Event, ev.Reminder), false),
+		},
+		Digest: &digest.Entry{
+			Section: "Upcoming events",
+			Title:   ev.Event.Title,
+			URL:     ev.Event.URL,
+		},
+	}
+}
+
+// workshopText is the announcement of the workshop.
+func workshopText(w workshops.Workshop) string {
+	title := w.Title
+	if len(w.URL) > 0 {
+		title = fmt.Sprintf("<%s|%s>", w.URL, w.Title)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ":gopher: Upcoming workshop: *%s*", title)
+
+	if len(w.Organizer) > 0 {
+		fmt.Fprintf(&b, " by %s", w.Organizer)
+	}
+
+	if len(w.Location) > 0 {
+		fmt.Fprintf(&b, " (%s)", w.Location)
+	}
+
+	fmt.Fprintf(&b, ", on <!date^%d^{date_short_pretty} at {time}|%s>.",
+		w.Start.Unix(), w.Start.UTC().Format("Jan 2 at 15:04 UTC"),
+	)
+
+	if len(w.URL) > 0 {
+		b.WriteString(" Sign up at the link!")
+	}
+
+	return b.String()
+}
+
+func formatWorkshop(w workshops.Workshop) notify.Notification {
+	return notify.Notification{
+		Source:   notify.Workshops,
+		Severity: notify.Info,
+		Summary:  fmt.Sprintf("workshop %s", w.ID),
+		Options: []slack.MsgOption{
+			slack.MsgOptionDisableLinkUnfurl(),
+			slack.MsgOptionText(workshopText(w), false),
+		},
+		Digest: &digest.Entry{
+			Section: "Upcoming events",
+			Title:   w.Title,
+			URL:     w.URL,
+		},
+	}
+}
+
+// formatStatus reposts the status as its account, letting Slack unfurl it.
+func formatStatus(st Status) notify.Notification {
+	account, name, icon := st.Account, st.Name, st.AvatarURL
+	if len(account) == 0 {
+		account, name, icon = mastodon.GoTime.String(), "Changelog", changelogIconURL
+	}
+
+	opts := []slack.MsgOption{
+		slack.MsgOptionUsername(name),
+		// urls must be enclosed in `<>`, and not escaped, otherwise the
+		// link will break and won't unfurl.
+		// See: https://api.slack.com/reference/messaging/link-unfurling
+		slack.MsgOptionText(fmt.Sprintf("<%s>", st.URL), false),
+		slack.MsgOptionEnableLinkUnfurl(),
+	}
+
+	if len(icon) > 0 {
+		opts = append(opts, slack.MsgOptionIconURL(icon))
+	}
+
+	return notify.Notification{
+		Source:   notify.Mastodon(account),
+		Severity: notify.Info,
+		Summary:  fmt.Sprintf("%s status %s", account, st.URL),
+		Options:  opts,
+		Digest: &digest.Entry{
+			Section: "Mastodon",
+			Title:   name + " posted an update",
+			URL:     st.URL,
+		},
+	}
+}
+
+// linkTextEscaper escapes the characters Slack's mrkdwn needs escaped, for
+// text like Reddit titles that anyone can write.
+var linkTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func formatPost(p Post) notify.Notification {
+	text := fmt.Sprintf(":fire: <%s|%s>\n%d points and %d comments on r/golang, posted by u/%s",
+		p.Permalink, linkTextEscaper.Replace(p.Title), p.Score, p.NumComments, p.Author)
+
+	return notify.Notification{
+		Source:   notify.Reddit,
+		Severity: notify.Info,
+		Summary:  fmt.Sprintf("hot r/golang post %s", p.ID),
+		Options: []slack.MsgOption{
+			slack.MsgOptionDisableLinkUnfurl(),
+			slack.MsgOptionText(text, false),
+		},
+		Digest: &digest.Entry{
+			Section: "r/golang",
+			Title:   p.Title,
+			URL:     p.Permalink,
+		},
+	}
+}
@@ -0,0 +1,367 @@
+package announce
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/digest"
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/internal/poller/events"
+	"github.com/gobridge/gopherbot/internal/poller/workshops"
+	"github.com/google/go-cmp/cmp"
+	"github.com/slack-go/slack"
+)
+
+func Test_format(t *testing.T) {
+	at := time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC)
+
+	type result struct {
+		Source       notify.Source
+		Severity     notify.Severity
+		Summary      string
+		Digest       *digest.Entry
+		ChannelNames []string
+	}
+
+	tests := []struct {
+		name string
+		a    Announcement
+		want result
+		err  string
+	}{
+		{
+			name: "cl_merged",
+			a: Announcement{
+				Kind: CLMerged,
+				At:   at,
+				CL:   &CL{Number: 42, Title: "[tools] gopls: fix", Link: "https://golang.org/cl/42/"},
+			},
+			want: result{
+				Source:   notify.Gerrit,
+				Severity: notify.Info,
+				Summary:  "merged CL 42",
+				Digest:   &digest.Entry{Section: "Merged CLs", Title: "[42] [tools] gopls: fix", URL: "https://golang.org/cl/42/", At: at},
+			},
+		},
+		{
+			name: "cl_backport",
+			a: Announcement{
+				Kind: CLBackport,
+				At:   at,
+				CL:   &CL{Number: 43, Title: "[release-branch.go1.27] cmd/go: fix", Link: "https://golang.org/cl/43/"},
+			},
+			want: result{
+				Source:   notify.GerritBackports,
+				Severity: notify.Info,
+				Summary:  "backport CL 43",
+			},
+		},
+		{
+			name: "cl_digest",
+			a: Announcement{
+				Kind: CLDigest,
+				At:   at,
+				CLs:  []CL{{Number: 42, Project: "go"}, {Number: 43, Project: "tools"}},
+			},
+			want: result{
+				Source:   notify.Gerrit,
+				Severity: notify.Info,
+				Summary:  "digest of 2 CLs",
+				Digest:   &digest.Entry{Section: "Merged CLs", Title: "2 CLs merged", URL: "https://go-review.googlesource.com/q/status:merged", At: at},
+			},
+		},
+		{
+			name: "empty_digest",
+			a:    Announcement{Kind: CLDigest},
+			err:  "missing CLs",
+		},
+		{
+			name: "gotime_live",
+			a:    Announcement{Kind: GoTimeLive, At: at},
+			want: result{
+				Source:   notify.GoTime,
+				Severity: notify.Important,
+				Summary:  "GoTime is live",
+				Digest:   &digest.Entry{Section: "GoTimeFM", Title: "GoTimeFM was live", URL: "https://changelog.com/gotime", At: at},
+			},
+		},
+		{
+			name: "gotime_live_episode",
+			a: Announcement{
+				Kind:    GoTimeLive,
+				At:      at,
+				Episode: &Episode{Title: "Go 2 is here", URL: "https://changelog.com/gotime/400"},
+			},
+			want: result{
+				Source:   notify.GoTime,
+				Severity: notify.Important,
+				Summary:  "GoTime is live",
+				Digest:   &digest.Entry{Section: "GoTimeFM", Title: "GoTimeFM was live: Go 2 is here", URL: "https://changelog.com/gotime/400", At: at},
+			},
+		},
+		{
+			name: "mastodon_status",
+			a: Announcement{
+				Kind:   MastodonStatus,
+				At:     at,
+				Status: &Status{URL: "https://hachyderm.io/@golang/1", Account: "golang@hachyderm.io", Name: "Go"},
+			},
+			want: result{
+				Source:   notify.Mastodon("golang@hachyderm.io"),
+				Severity: notify.Info,
+				Summary:  "golang@hachyderm.io status https://hachyderm.io/@golang/1",
+				Digest:   &digest.Entry{Section: "Mastodon", Title: "Go posted an update", URL: "https://hachyderm.io/@golang/1", At: at},
+			},
+		},
+		{
+			name: "gotime_status_before_accounts",
+			a: Announcement{
+				Kind:   MastodonStatus,
+				At:     at,
+				Status: &Status{URL: "https://changelog.social/@gotime/1"},
+			},
+			want: result{
+				Source:   notify.GoTimeStatus,
+				Severity: notify.Info,
+				Summary:  "gotime@changelog.social status https://changelog.social/@gotime/1",
+				Digest:   &digest.Entry{Section: "Mastodon", Title: "Changelog posted an update", URL: "https://changelog.social/@gotime/1", At: at},
+			},
+		},
+		{
+			name: "event_reminder",
+			a: Announcement{
+				Kind:  EventReminder,
+				At:    at,
+				Event: &Event{Event: events.Event{Title: "GopherCon", URL: "https://gophercon.com"}, Reminder: events.WeekBefore},
+			},
+			want: result{
+				Source:       notify.Events,
+				Severity:     notify.Info,
+				Summary:      "week reminder for GopherCon",
+				Digest:       &digest.Entry{Section: "Upcoming events", Title: "GopherCon", URL: "https://gophercon.com", At: at},
+				ChannelNames: []string{"remotemeetup"},
+			},
+		},
+		{
+			name: "reddit_post",
+			a: Announcement{
+				Kind: RedditPost,
+				At:   at,
+				Post: &Post{ID: "abc12", Title: "Go 1.27 & <generics>", Permalink: "https://www.reddit.com/r/golang/comments/abc12/go_127/", Score: 250},
+			},
+			want: result{
+				Source:   notify.Reddit,
+				Severity: notify.Info,
+				Summary:  "hot r/golang post abc12",
+				Digest:   &digest.Entry{Section: "r/golang", Title: "Go 1.27 & <generics>", URL: "https://www.reddit.com/r/golang/comments/abc12/go_127/", At: at},
+			},
+		},
+		{
+			name: "workshop",
+			a: Announcement{
+				Kind:     WorkshopUpcoming,
+				At:       at,
+				Workshop: &workshops.Workshop{ID: "s3", Title: "Intro to Go", URL: "https://gobridge.org/w/3", Start: at.Add(72 * time.Hour)},
+			},
+			want: result{
+				Source:       notify.Workshops,
+				Severity:     notify.Info,
+				Summary:      "workshop s3",
+				Digest:       &digest.Entry{Section: "Upcoming events", Title: "Intro to Go", URL: "https://gobridge.org/w/3", At: at},
+				ChannelNames: []string{"remotemeetup", "general"},
+			},
+		},
+		{
+			name: "missing_payload",
+			a:    Announcement{Kind: MastodonStatus},
+			err:  "missing status",
+		},
+		{
+			name: "unknown_kind",
+			a:    Announcement{Kind: "nope"},
+			err:  "unknown announcement kind",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			n, channelNames, err := format(tt.a)
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("format() error = %v, should contain %q", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("format() unexpected error: %v", err)
+			}
+
+			got := result{
+				Source:       n.Source,
+				Severity:     n.Severity,
+				Summary:      n.Summary,
+				Digest:       n.Digest,
+				ChannelNames: channelNames,
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("format() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_goTimeBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		ep      *Episode
+		section string
+		context string
+	}{
+		{
+			name:    "no_episode",
+			section: "*:tada: GoTimeFM is now live :tada:*",
+		},
+		{
+			name:    "title_only",
+			ep:      &Episode{Title: "Go 2 is here"},
+			section: "*:tada: GoTimeFM is now live :tada:*\nGo 2 is here",
+		},
+		{
+			name:    "everything",
+			ep:      &Episode{Title: "Go 2 is here", URL: "https://changelog.com/gotime/400", Guests: []string{"Rob", "Ken"}},
+			section: "*:tada: GoTimeFM is now live :tada:*\n<https://changelog.com/gotime/400|Go 2 is here>",
+			context: "With Rob, Ken",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := goTimeBlocks(tt.ep)
+
+			want := 2
+			if len(tt.context) > 0 {
+				want = 3
+			}
+
+			if len(blocks) != want {
+				t.Fatalf("goTimeBlocks() returned %d blocks, want %d", len(blocks), want)
+			}
+
+			if got := blocks[0].(*slack.SectionBlock).Text.Text; got != tt.section {
+				t.Errorf("section = %q, want %q", got, tt.section)
+			}
+
+			if len(tt.context) > 0 {
+				if got := blocks[1].(*slack.ContextBlock).ContextElements.Elements[0].(*slack.TextBlockObject).Text; got != tt.context {
+					t.Errorf("context = %q, want %q", got, tt.context)
+				}
+			}
+
+			button := blocks[len(blocks)-1].(*slack.ActionBlock).Elements.ElementSet[0].(*slack.ButtonBlockElement)
+			if button.URL != goTimeLiveURL {
+				t.Errorf("button URL = %q, want %q", button.URL, goTimeLiveURL)
+			}
+		})
+	}
+}
+
+func Test_eventText(t *testing.T) {
+	e := events.Event{
+		Title:    "GopherCon",
+		URL:      "https://gophercon.com",
+		Location: "Seattle",
+		Start:    time.Date(2026, 9, 1, 16, 0, 0, 0, time.UTC),
+	}
+
+	want := ":spiral_calendar_pad: Coming up soon: *<https://gophercon.com|GopherCon>* (Seattle), starting <!date^1788278400^{date_short_pretty} at {time}|Sep 1 at 16:00 UTC>."
+
+	if got := eventText(e, events.DayBefore); got != want {
+		t.Fatalf("eventText() = %q, want %q", got, want)
+	}
+}
+
+func Test_clDigestText(t *testing.T) {
+	cls := []CL{
+		{Number: 3, Project: "tools", Subject: "gopls: fix hover", Link: "https://go.dev/cl/3"},
+		{Number: 1, Project: "go", Subject: "cmd/go: fix build", Link: "https://go.dev/cl/1"},
+		{Number: 4, Project: "net", Subject: "http2: fix race", Link: "https://go.dev/cl/4"},
+		{Number: 2, Project: "go", Subject: "runtime: fix GC", Link: "https://go.dev/cl/2"},
+	}
+
+	want := ":gopher: 4 CLs merged:" +
+		"\n\n*go*\n<https://go.dev/cl/1|[1]> cmd/go: fix build\n<https://go.dev/cl/2|[2]> runtime: fix GC" +
+		"\n\n*net*\n<https://go.dev/cl/4|[4]> http2: fix race" +
+		"\n\n*tools*\n<https://go.dev/cl/3|[3]> gopls: fix hover"
+
+	if got := clDigestText(cls); got != want {
+		t.Fatalf("clDigestText() = %q, want %q", got, want)
+	}
+}
+
+func TestKey(t *testing.T) {
+	at := time.Date(2026, 10, 5, 23, 30, 0, 0, time.FixedZone("PDT", -7*60*60))
+
+	tests := []struct {
+		name string
+		a    Announcement
+		want string
+	}{
+		{
+			name: "cl",
+			a:    Announcement{Kind: CLMerged, CL: &CL{Number: 12345}},
+			want: "cl_merged:12345",
+		},
+		{
+			name: "cl_reviewed",
+			a:    Announcement{Kind: CLReviewed, CL: &CL{Number: 12345}},
+			want: "cl_reviewed:12345",
+		},
+		{
+			name: "status",
+			a:    Announcement{Kind: MastodonStatus, Status: &Status{URL: "https://changelog.social/@gotime/1"}},
+			want: "gotime_status:https://changelog.social/@gotime/1",
+		},
+		{
+			name: "event",
+			a: Announcement{Kind: EventReminder, Event: &Event{
+				Event:    events.Event{UID: "gc26@gophercon.com"},
+				Reminder: events.WeekBefore,
+			}},
+			want: "event_reminder:gc26@gophercon.com:week",
+		},
+		{
+			name: "cl_digest",
+			a:    Announcement{Kind: CLDigest, CLs: []CL{{Number: 10}, {Number: 7}, {Number: 12}}},
+			want: "cl_digest:10:12:3",
+		},
+		{
+			name: "reddit_post",
+			a:    Announcement{Kind: RedditPost, Post: &Post{ID: "abc12"}},
+			want: "reddit_post:abc12",
+		},
+		{
+			name: "gotime_live_utc_day",
+			a:    Announcement{Kind: GoTimeLive, At: at},
+			want: "gotime_live:2026-10-06",
+		},
+		{
+			name: "missing_payload",
+			a:    Announcement{Kind: CLMerged},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key(tt.a); got != tt.want {
+				t.Fatalf("Key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
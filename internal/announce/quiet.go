@@ -0,0 +1,278 @@
+package announce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisHeldFormat = "announce:held:%s" // channel ID; sorted set of announcements by when they were held
+
+	// flushInterval is how often the held announcements are checked for
+	// channels whose quiet hours are over.
+	flushInterval = time.Minute
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is the quiet hours of a channel: a span of the day, in UTC, on some
+// days of the week. A span ending before it starts runs past midnight, into
+// the next day.
+type Window struct {
+	// Start and End are the time since midnight the window opens and
+	// closes.
+	Start, End time.Duration
+
+	// Days are the days of the week the window opens on.
+	Days [7]bool
+}
+
+// ParseWindow parses quiet hours like "01:00-07:00", every day, or
+// "01:00-07:00 sat-sun", only on weekends. Days can be a range, like
+// "mon-fri", or a single day, like "sun".
+func ParseWindow(s string) (Window, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 || len(fields) > 2 {
+		return Window{}, fmt.Errorf("invalid quiet hours %q, want HH:MM-HH:MM with optional days like sat-sun", s)
+	}
+
+	var w Window
+
+	span := strings.SplitN(fields[0], "-", 2)
+	if len(span) != 2 {
+		return Window{}, fmt.Errorf("invalid quiet hours %q, want HH:MM-HH:MM", fields[0])
+	}
+
+	var err error
+
+	if w.Start, err = parseClock(span[0]); err != nil {
+		return Window{}, err
+	}
+
+	if w.End, err = parseClock(span[1]); err != nil {
+		return Window{}, err
+	}
+
+	if w.Start == w.End {
+		return Window{}, fmt.Errorf("quiet hours %q are empty", fields[0])
+	}
+
+	if len(fields) == 1 {
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+
+		return w, nil
+	}
+
+	days := strings.SplitN(fields[1], "-", 2)
+
+	first, ok := weekdays[days[0]]
+	if !ok {
+		return Window{}, fmt.Errorf("unknown day %q", days[0])
+	}
+
+	last := first
+
+	if len(days) == 2 {
+		if last, ok = weekdays[days[1]]; !ok {
+			return Window{}, fmt.Errorf("unknown day %q", days[1])
+		}
+	}
+
+	// ranges can wrap past Saturday, like fri-mon
+	for d := first; ; d = (d + 1) % 7 {
+		w.Days[d] = true
+
+		if d == last {
+			break
+		}
+	}
+
+	return w, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains returns whether t is in the quiet hours.
+func (w Window) Contains(t time.Time) bool {
+	t = t.UTC()
+
+	day := t.Weekday()
+	d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	if w.Start < w.End {
+		return w.Days[day] && d >= w.Start && d < w.End
+	}
+
+	// past midnight, the window opened the day before
+	return (w.Days[day] && d >= w.Start) || (w.Days[(day+6)%7] && d < w.End)
+}
+
+// QuietHours maps channel IDs to their quiet hours.
+type QuietHours map[string]Window
+
+// ParseQuietHours parses the quiet hours of each channel ID, as given in
+// GOPHER_BGTASKS_QUIET_HOURS.
+func ParseQuietHours(m map[string]string) (QuietHours, error) {
+	q := make(QuietHours, len(m))
+
+	for id, s := range m {
+		w, err := ParseWindow(s)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", id, err)
+		}
+
+		q[id] = w
+	}
+
+	return q, nil
+}
+
+// Quiet returns whether it's quiet hours in the channel at t.
+func (q QuietHours) Quiet(channelID string, t time.Time) bool {
+	w, ok := q[channelID]
+	return ok && w.Contains(t)
+}
+
+// any returns whether any of the channels have quiet hours.
+func (q QuietHours) any(channelIDs []string) bool {
+	for _, id := range channelIDs {
+		if _, ok := q[id]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hold keeps the announcement to post in the channel once its quiet hours are
+// over. Holding the same announcement again, like when posting it is retried,
+// doesn't post it twice.
+func (a *Announcer) hold(ctx context.Context, channelID string, ann Announcement) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	j, err := json.Marshal(ann)
+	if err != nil {
+		return fmt.Errorf("failed to marshal announcement: %w", err)
+	}
+
+	err = a.r.ZAddNX(ctx, fmt.Sprintf(redisHeldFormat, channelID), redis.Z{
+		Score:  float64(time.Now().UnixNano()),
+		Member: string(j),
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to ZADD held announcement: %w", err)
+	}
+
+	return nil
+}
+
+// flush posts the announcements held for each channel whose quiet hours are
+// over, in the order they were held. An announcement that fails to post is
+// left to try again at the next flush.
+func (a *Announcer) flush(ctx context.Context) {
+	now := time.Now()
+
+	for id := range a.quiet {
+		if a.quiet.Quiet(id, now) {
+			continue
+		}
+
+		key := fmt.Sprintf(redisHeldFormat, id)
+
+		held, err := a.r.ZRange(ctx, key, 0, -1).Result()
+		if err != nil {
+			a.logger.Error().
+				Err(err).
+				Str("channel_id", id).
+				Msg("failed to get held announcements")
+
+			continue
+		}
+
+		for _, v := range held {
+			if ctx.Err() != nil {
+				return
+			}
+
+			logger := a.logger.With().Str("channel_id", id).Logger()
+
+			if err := a.postHeld(ctx, id, v); err != nil {
+				logger.Error().
+					Err(err).
+					Msg("failed to post held announcement; trying again later")
+
+				break
+			}
+
+			if err := a.r.ZRem(ctx, key, v).Err(); err != nil {
+				logger.Error().
+					Err(err).
+					Msg("failed to remove posted announcement from held set")
+			}
+		}
+	}
+}
+
+func (a *Announcer) postHeld(ctx context.Context, channelID, v string) error {
+	var ann Announcement
+	if err := json.Unmarshal([]byte(v), &ann); err != nil {
+		// it can't be posted, so it's dropped like a malformed one would be
+		a.logger.Error().
+			Err(err).
+			Str("channel_id", channelID).
+			Msg("failed to parse held announcement")
+
+		return nil
+	}
+
+	n, _, err := format(ann)
+	if err != nil {
+		a.logger.Error().
+			Err(err).
+			Str("kind", string(ann.Kind)).
+			Msg("failed to format held announcement")
+
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+
+	if _, err := a.notifier.NotifyChannel(ctx, channelID, n); err != nil {
+		return err
+	}
+
+	a.logger.Info().
+		Str("channel_id", channelID).
+		Str("summary", n.Summary).
+		Msg("posted held announcement")
+
+	return nil
+}
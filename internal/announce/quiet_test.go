@@ -0,0 +1,97 @@
+package announce
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Window
+		wantErr bool
+	}{
+		{
+			name: "every_day",
+			s:    "01:00-07:00",
+			want: Window{Start: time.Hour, End: 7 * time.Hour, Days: [7]bool{true, true, true, true, true, true, true}},
+		},
+		{
+			name: "weekends",
+			s:    "01:00-07:30 Sat-Sun",
+			want: Window{Start: time.Hour, End: 7*time.Hour + 30*time.Minute, Days: [7]bool{time.Saturday: true, time.Sunday: true}},
+		},
+		{
+			name: "one_day",
+			s:    "22:00-02:00 fri",
+			want: Window{Start: 22 * time.Hour, End: 2 * time.Hour, Days: [7]bool{time.Friday: true}},
+		},
+		{
+			name: "wrapping_days",
+			s:    "00:00-06:00 fri-mon",
+			want: Window{End: 6 * time.Hour, Days: [7]bool{time.Friday: true, time.Saturday: true, time.Sunday: true, time.Monday: true}},
+		},
+		{name: "empty", s: "", wantErr: true},
+		{name: "no_end", s: "01:00", wantErr: true},
+		{name: "bad_time", s: "25:00-07:00", wantErr: true},
+		{name: "same_time", s: "01:00-01:00", wantErr: true},
+		{name: "bad_day", s: "01:00-07:00 someday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWindow(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWindow() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Fatalf("ParseWindow() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindow_Contains(t *testing.T) {
+	weekends, err := ParseWindow("01:00-07:00 sat-sun")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Friday night into Saturday morning only
+	overnight, err := ParseWindow("22:00-02:00 fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2020-05-01 is a Friday
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2020, 5, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		w    Window
+		t    time.Time
+		want bool
+	}{
+		{name: "weekend_inside", w: weekends, t: at(2, 3, 0), want: true},
+		{name: "weekend_start", w: weekends, t: at(3, 1, 0), want: true},
+		{name: "weekend_end", w: weekends, t: at(2, 7, 0)},
+		{name: "weekday", w: weekends, t: at(1, 3, 0)},
+		{name: "other_time_zone", w: weekends, t: at(2, 3, 0).In(time.FixedZone("PDT", -7*60*60)), want: true},
+		{name: "overnight_evening", w: overnight, t: at(1, 23, 0), want: true},
+		{name: "overnight_morning", w: overnight, t: at(2, 1, 59), want: true},
+		{name: "overnight_after", w: overnight, t: at(2, 2, 0)},
+		{name: "overnight_other_day", w: overnight, t: at(2, 23, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.w.Contains(tt.t); got != tt.want {
+				t.Fatalf("Contains(%s) = %t, want %t", tt.t, got, tt.want)
+			}
+		})
+	}
+}
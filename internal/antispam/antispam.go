@@ -0,0 +1,225 @@
+// Package antispam scores messages on how likely they are to be spam, like
+// mass mentions, repeated links, invite links to other communities, and known
+// scam phrases, and deletes them when a moderator should not have to.
+package antispam
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/slack-go/slack"
+)
+
+// the weights of each signal
+const (
+	broadcastWeight     = 3.0
+	extraMentionWeight  = 0.5
+	extraLinkWeight     = 0.5
+	repeatedLinkWeight  = 1.0
+	inviteLinkWeight    = 2.0
+	scamPhraseWeight    = 2.0
+	dmRequestWeight     = 1.5
+	scamWithDMBonus     = 2.0
+	freeMentions        = 3
+	freeLinks           = 2
+	maxExtraMentions    = 10
+	maxScoredScamPhrase = 3
+)
+
+var scamPhrases = []string{
+	"crypto job",
+	"job offer",
+	"investment opportunity",
+	"guaranteed profit",
+	"guaranteed returns",
+	"passive income",
+	"forex",
+	"airdrop",
+	"free nft",
+	"recover your funds",
+	"recover your wallet",
+	"earn $",
+	"work from home and earn",
+}
+
+var dmPhrases = []string{
+	"dm me",
+	"send me a dm",
+	"message me privately",
+	"inbox me",
+	"pm me",
+	"whatsapp me",
+	"telegram me",
+	"contact me on telegram",
+	"contact me on whatsapp",
+}
+
+var inviteHosts = []string{
+	"discord.gg",
+	"discord.com/invite",
+	"t.me",
+	"chat.whatsapp.com",
+	"join.slack.com",
+}
+
+// linkRegex matches links as Slack formats them, like <https://example.org> or
+// <https://example.org|example.org>.
+var linkRegex = regexp.MustCompile(`<(https?://[^|>]+)(?:\|[^>]*)?>`)
+
+// Score is the spam score of a message.
+type Score struct {
+	Total   float64
+	Reasons []string
+}
+
+func (s *Score) add(weight float64, format string, a ...interface{}) {
+	s.Total += weight
+	s.Reasons = append(s.Reasons, fmt.Sprintf(format, a...))
+}
+
+// Reason is the human-readable explanation of the score.
+func (s Score) Reason() string {
+	return strings.Join(s.Reasons, "; ")
+}
+
+func isInvite(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	hp := strings.ToLower(strings.TrimPrefix(u.Host, "www.") + u.Path)
+
+	for _, h := range inviteHosts {
+		if strings.HasPrefix(hp, h) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rate scores the message, using its raw text and parsed mentions.
+func Rate(rawText string, mentions []mparser.Mention) Score {
+	var s Score
+
+	var users int
+	var broadcast bool
+
+	for _, m := range mentions {
+		switch m.Type {
+		case mparser.TypeHere, mparser.TypeChannel, mparser.TypeEveryone:
+			broadcast = true
+		case mparser.TypeUser, mparser.TypeGroup:
+			users++
+		}
+	}
+
+	if broadcast {
+		s.add(broadcastWeight, "mentions the whole channel")
+	}
+
+	if users > freeMentions {
+		extra := users - freeMentions
+		if extra > maxExtraMentions {
+			extra = maxExtraMentions
+		}
+
+		s.add(float64(extra)*extraMentionWeight, "mentions %d users", users)
+	}
+
+	links := linkRegex.FindAllStringSubmatch(rawText, -1)
+
+	if len(links) > freeLinks {
+		s.add(float64(len(links)-freeLinks)*extraLinkWeight, "contains %d links", len(links))
+	}
+
+	seen := make(map[string]int, len(links))
+	var invites int
+
+	for _, l := range links {
+		link := strings.ToLower(l[1])
+		seen[link]++
+
+		if seen[link] == 1 && isInvite(link) {
+			invites++
+		}
+	}
+
+	for link, n := range seen {
+		if n > 1 {
+			s.add(float64(n-1)*repeatedLinkWeight, "repeats the link %s %d times", link, n)
+		}
+	}
+
+	if invites > 0 {
+		s.add(float64(invites)*inviteLinkWeight, "contains %d invite links to other communities", invites)
+	}
+
+	lt := strings.ToLower(rawText)
+
+	var scams []string
+
+	for _, p := range scamPhrases {
+		if strings.Contains(lt, p) {
+			scams = append(scams, p)
+		}
+	}
+
+	if len(scams) > 0 {
+		n := len(scams)
+		if n > maxScoredScamPhrase {
+			n = maxScoredScamPhrase
+		}
+
+		s.add(float64(n)*scamPhraseWeight, "contains scam phrases: %s", strings.Join(scams, ", "))
+	}
+
+	for _, p := range dmPhrases {
+		if strings.Contains(lt, p) {
+			s.add(dmRequestWeight, "asks to be contacted privately")
+
+			if len(scams) > 0 {
+				s.add(scamWithDMBonus, "scam phrase and a request to be contacted privately")
+			}
+
+			break
+		}
+	}
+
+	return s
+}
+
+// Deleter deletes messages.
+type Deleter struct {
+	sc *slack.Client
+}
+
+// NewDeleter returns a *Deleter using the Slack admin access token. Bot tokens
+// can only delete the bot's own messages, so to delete other users' messages
+// chat.delete needs a user token from a Workspace Admin with the chat:write
+// scope. If the token is empty, every call to Delete fails.
+func NewDeleter(adminToken string, opts ...slack.Option) *Deleter {
+	if len(adminToken) == 0 {
+		return &Deleter{}
+	}
+
+	return &Deleter{sc: slack.New(adminToken, opts...)}
+}
+
+// Delete deletes the message.
+func (d *Deleter) Delete(ctx context.Context, channelID, messageTS string) error {
+	if d.sc == nil {
+		return fmt.Errorf("cannot delete message: no Slack admin access token configured")
+	}
+
+	if _, _, err := d.sc.DeleteMessageContext(ctx, channelID, messageTS); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package antispam
+
+import (
+	"testing"
+
+	"github.com/gobridge/gopherbot/mparser"
+)
+
+func TestRate(t *testing.T) {
+	users := func(n int) []mparser.Mention {
+		m := make([]mparser.Mention, n)
+		for i := range m {
+			m[i] = mparser.Mention{Type: mparser.TypeUser, ID: "U1"}
+		}
+		return m
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		mentions []mparser.Mention
+		want     float64
+	}{
+		{
+			name: "normal_question",
+			text: "How do I read a file line by line? I tried <https://pkg.go.dev/bufio|bufio> but got confused.",
+		},
+		{
+			name:     "broadcast",
+			text:     "hey everyone",
+			mentions: []mparser.Mention{{Type: mparser.TypeHere}},
+			want:     broadcastWeight,
+		},
+		{
+			name:     "mass_mentions",
+			text:     "look at this",
+			mentions: users(5),
+			want:     2 * extraMentionWeight,
+		},
+		{
+			name: "repeated_links",
+			text: "<https://example.org> <https://example.org> <https://EXAMPLE.org|x>",
+			want: extraLinkWeight + 2*repeatedLinkWeight,
+		},
+		{
+			name: "invite_links",
+			text: "join us <https://discord.gg/abc> or <https://t.me/xyz|telegram>",
+			want: 2 * inviteLinkWeight,
+		},
+		{
+			name: "scam_with_dm",
+			text: "Crypto job offer, guaranteed profit! DM me for details",
+			want: 3*scamPhraseWeight + dmRequestWeight + scamWithDMBonus,
+		},
+		{
+			name: "dm_only",
+			text: "I can help with that, dm me",
+			want: dmRequestWeight,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rate(tt.text, tt.mentions); got.Total != tt.want {
+				t.Fatalf("Rate().Total = %v, want %v (reasons: %s)", got.Total, tt.want, got.Reason())
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Package archive finds public channels nobody has posted in for months, so
+// the moderators can consider archiving them. Only the messages since the
+// cutoff are sampled for each channel, so a quiet channel is a single request.
+package archive
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// SampleSize is how many of the messages since the cutoff are sampled. If none
+// of them count as activity and there are more, the channel is assumed to be
+// active, rather than paging through them all.
+const SampleSize = 100
+
+// Candidate is a channel that could be archived.
+type Candidate struct {
+	ID      string
+	Name    string
+	Members int
+	Created time.Time
+}
+
+// Finder decides which channels are candidates for archiving.
+type Finder struct {
+	cutoff time.Time
+	allow  map[string]struct{}
+}
+
+// NewFinder returns a *Finder for channels without activity since months
+// before now, except those in the allowlist.
+func NewFinder(now time.Time, months int, allowlist []string) *Finder {
+	allow := make(map[string]struct{}, len(allowlist))
+	for _, id := range allowlist {
+		allow[id] = struct{}{}
+	}
+
+	return &Finder{
+		cutoff: now.AddDate(0, -months, 0),
+		allow:  allow,
+	}
+}
+
+// Cutoff returns the time channels need activity since to not be candidates.
+func (f *Finder) Cutoff() time.Time {
+	return f.cutoff
+}
+
+// Eligible returns whether the channel's history should be sampled at all.
+// Archived, private, allowlisted, and general channels aren't, nor those
+// created after the cutoff.
+func (f *Finder) Eligible(ch slack.Channel) bool {
+	if ch.IsArchived || ch.IsPrivate || ch.IsGeneral {
+		return false
+	}
+
+	if _, ok := f.allow[ch.ID]; ok {
+		return false
+	}
+
+	return ch.Created.Time().Before(f.cutoff)
+}
+
+// notActivity are the message subtypes that don't show anyone is using the
+// channel.
+var notActivity = map[string]struct{}{
+	"channel_join":    {},
+	"channel_leave":   {},
+	"channel_archive": {},
+	"bot_add":         {},
+	"bot_remove":      {},
+}
+
+// Inactive returns whether the sampled messages since the cutoff show the
+// channel isn't used. hasMore is whether there were more messages than
+// sampled.
+func Inactive(msgs []slack.Message, hasMore bool) bool {
+	for _, m := range msgs {
+		if _, ok := notActivity[m.SubType]; !ok {
+			return false
+		}
+	}
+
+	return !hasMore
+}
+
+// NewCandidate returns the Candidate for the channel.
+func NewCandidate(ch slack.Channel) Candidate {
+	return Candidate{
+		ID:      ch.ID,
+		Name:    ch.Name,
+		Members: ch.NumMembers,
+		Created: ch.Created.Time(),
+	}
+}
+
+// digestMax is how many candidates are listed in a digest.
+const digestMax = 40
+
+// FormatDigest formats the candidates as a Slack message, the oldest channels
+// first.
+func FormatDigest(months int, cs []Candidate, unchecked int) string {
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Created.Equal(cs[j].Created) {
+			return cs[i].Name < cs[j].Name
+		}
+
+		return cs[i].Created.Before(cs[j].Created)
+	})
+
+	b := &strings.Builder{}
+
+	if len(cs) == 0 {
+		fmt.Fprintf(b, "*No channels have gone %d months without a message.*\n", months)
+	} else {
+		fmt.Fprintf(b, "*%d channels have gone %d months without a message, and could be archived:*\n", len(cs), months)
+	}
+
+	for i, c := range cs {
+		if i == digestMax {
+			fmt.Fprintf(b, "…and %d more\n", len(cs)-digestMax)
+			break
+		}
+
+		fmt.Fprintf(b, "- <#%s> (%d members, created %s)\n", c.ID, c.Members, c.Created.UTC().Format("January 2006"))
+	}
+
+	if unchecked > 0 {
+		fmt.Fprintf(b, "\nI couldn't check %d channels, most likely because I'm not in them.\n", unchecked)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package archive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/slack-go/slack"
+)
+
+func channel(id string, created time.Time, fn func(ch *slack.Channel)) slack.Channel {
+	var ch slack.Channel
+
+	ch.ID = id
+	ch.Created = slack.JSONTime(created.Unix())
+
+	if fn != nil {
+		fn(&ch)
+	}
+
+	return ch
+}
+
+func TestFinder_Eligible(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	old := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	f := NewFinder(now, 6, []string{"C3"})
+
+	if want := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC); !f.Cutoff().Equal(want) {
+		t.Fatalf("Cutoff() = %s, want %s", f.Cutoff(), want)
+	}
+
+	tests := []struct {
+		name string
+		ch   slack.Channel
+		want bool
+	}{
+		{name: "old", ch: channel("C1", old, nil), want: true},
+		{name: "new", ch: channel("C2", now.AddDate(0, -1, 0), nil)},
+		{name: "allowlisted", ch: channel("C3", old, nil)},
+		{name: "archived", ch: channel("C4", old, func(ch *slack.Channel) { ch.IsArchived = true })},
+		{name: "general", ch: channel("C5", old, func(ch *slack.Channel) { ch.IsGeneral = true })},
+		{name: "private", ch: channel("C6", old, func(ch *slack.Channel) { ch.IsPrivate = true })},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Eligible(tt.ch); got != tt.want {
+				t.Fatalf("Eligible() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func message(subtype string) slack.Message {
+	var m slack.Message
+	m.SubType = subtype
+	return m
+}
+
+func TestInactive(t *testing.T) {
+	tests := []struct {
+		name    string
+		msgs    []slack.Message
+		hasMore bool
+		want    bool
+	}{
+		{name: "no_messages", want: true},
+		{name: "only_joins", msgs: []slack.Message{message("channel_join"), message("channel_leave")}, want: true},
+		{name: "only_joins_with_more", msgs: []slack.Message{message("channel_join")}, hasMore: true},
+		{name: "message", msgs: []slack.Message{message("channel_join"), message("")}},
+		{name: "thread_broadcast", msgs: []slack.Message{message("thread_broadcast")}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Inactive(tt.msgs, tt.hasMore); got != tt.want {
+				t.Fatalf("Inactive() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDigest(t *testing.T) {
+	cs := []Candidate{
+		{ID: "C2", Name: "b", Members: 3, Created: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "C1", Name: "a", Members: 12, Created: time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	want := "*2 channels have gone 6 months without a message, and could be archived:*\n" +
+		"- <#C1> (12 members, created February 2018)\n" +
+		"- <#C2> (3 members, created May 2020)\n" +
+		"\nI couldn't check 4 channels, most likely because I'm not in them.\n"
+
+	if diff := cmp.Diff(want, FormatDigest(6, cs, 4)); diff != "" {
+		t.Fatalf("FormatDigest() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := FormatDigest(3, nil, 0); got != "*No channels have gone 3 months without a message.*\n" {
+		t.Fatalf("FormatDigest() = %q", got)
+	}
+}
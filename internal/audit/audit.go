@@ -0,0 +1,225 @@
+// Package audit keeps an append-only log of the bot's actions in Slack: the
+// messages it sends, the reactions it adds, and the moderation actions it
+// takes, so the moderators can account for what it did and why.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	streamKey   = "audit:log"
+	streamField = "entry"
+
+	// streamMaxLen is about how many entries are kept, which is months of
+	// the bot's activity.
+	streamMaxLen = 100000
+
+	redisTestKey = "audit:test_key"
+)
+
+// Entry is one action the bot took.
+type Entry struct {
+	// ID is the entry's ID in the stream, which is set when it's read.
+	ID string `json:"-"`
+
+	// Time is when the action was taken.
+	Time time.Time `json:"time"`
+
+	// Actor is the identity the action was taken as, like "bot", or "admin"
+	// for actions needing the admin token.
+	Actor string `json:"actor"`
+
+	// Action is the Slack API method called, like "chat.postMessage".
+	Action string `json:"action"`
+
+	// Trigger is the ID of the event being handled when the action was
+	// taken, or empty if it wasn't taken for an event, like scheduled posts.
+	Trigger string `json:"trigger,omitempty"`
+
+	// RequestID is the gateway request the event came in on, to find it in
+	// the logs.
+	RequestID string `json:"request_id,omitempty"`
+
+	// ChannelID is the channel the action was taken in, if any.
+	ChannelID string `json:"channel_id,omitempty"`
+
+	// UserIDs are the members the action was taken on, if any, like who an
+	// ephemeral message was shown to, or who was invited or kicked.
+	UserIDs []string `json:"user_ids,omitempty"`
+
+	// Error is why Slack rejected the action, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// Store is the Redis stream the entries are appended to.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Append adds the entry to the end of the log.
+func (s *Store) Append(ctx context.Context, e Entry) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	j, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	err = s.r.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamKey,
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{streamField: string(j)},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to XADD audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// Last returns the n most recent entries, newest first.
+func (s *Store) Last(ctx context.Context, n int) ([]Entry, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	msgs, err := s.r.XRevRangeN(ctx, streamKey, "+", "-", int64(n)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to XREVRANGE audit log: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(msgs))
+
+	for _, m := range msgs {
+		raw, _ := m.Values[streamField].(string)
+
+		var e Entry
+
+		if err := json.Unmarshal([]byte(raw), &e); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit entry %s: %w", m.ID, err)
+		}
+
+		e.ID = m.ID
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// purgePageSize is how many entries PurgeUser reads at once.
+const purgePageSize = 1000
+
+// names returns whether the entry is about the user: as who took the action,
+// who it was taken on, or as the channel, which is how DMs are sent.
+func (e Entry) names(userID string) bool {
+	if e.Actor == userID || e.ChannelID == userID {
+		return true
+	}
+
+	for _, id := range e.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PurgeUser deletes the entries naming the user, as the actor, the member the
+// action was taken on, or the channel of a DM, returning how many there were.
+// If dryRun is true, they're counted but not deleted. It satisfies
+// purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	var found []string
+
+	start := "-"
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+			// noop
+		}
+
+		msgs, err := s.r.XRangeN(ctx, streamKey, start, "+", purgePageSize).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to XRANGE audit log: %w", err)
+		}
+
+		for _, m := range msgs {
+			raw, _ := m.Values[streamField].(string)
+
+			var e Entry
+
+			if err := json.Unmarshal([]byte(raw), &e); err != nil {
+				return 0, fmt.Errorf("failed to unmarshal audit entry %s: %w", m.ID, err)
+			}
+
+			if e.names(userID) {
+				found = append(found, m.ID)
+			}
+		}
+
+		if len(msgs) < purgePageSize {
+			break
+		}
+
+		// the range is inclusive, so start from just after the last entry
+		start = nextID(msgs[len(msgs)-1].ID)
+	}
+
+	if len(found) == 0 || dryRun {
+		return len(found), nil
+	}
+
+	if err := s.r.XDel(ctx, streamKey, found...).Err(); err != nil {
+		return 0, fmt.Errorf("failed to XDEL audit entries: %w", err)
+	}
+
+	return len(found), nil
+}
+
+// nextID returns the smallest stream ID after id, which is of the form
+// <milliseconds>-<sequence>.
+func nextID(id string) string {
+	i := strings.IndexByte(id, '-')
+	if i < 0 {
+		return id
+	}
+
+	seq, err := strconv.ParseUint(id[i+1:], 10, 64)
+	if err != nil {
+		return id
+	}
+
+	return id[:i+1] + strconv.FormatUint(seq+1, 10)
+}
@@ -0,0 +1,38 @@
+package audit
+
+import "testing"
+
+func TestNextID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "1760605200000-0", want: "1760605200000-1"},
+		{id: "1760605200000-9", want: "1760605200000-10"},
+	}
+
+	for _, tt := range tests {
+		if got := nextID(tt.id); got != tt.want {
+			t.Errorf("nextID(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestEntry_names(t *testing.T) {
+	tests := []struct {
+		name string
+		e    Entry
+		want bool
+	}{
+		{name: "dm", e: Entry{Actor: "bot", ChannelID: "U123"}, want: true},
+		{name: "actor", e: Entry{Actor: "U123", ChannelID: "C123"}, want: true},
+		{name: "target", e: Entry{Actor: "admin", ChannelID: "C123", UserIDs: []string{"U456", "U123"}}, want: true},
+		{name: "other", e: Entry{Actor: "bot", ChannelID: "C123", UserIDs: []string{"U456"}}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.e.names("U123"); got != tt.want {
+			t.Errorf("%s: names() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/rs/zerolog"
+)
+
+// appendTimeout is how long recording an action can take. It's not bound to
+// the request's context, so actions taken at the end of a handler's timeout
+// are still recorded.
+const appendTimeout = time.Second
+
+// responseURLHost is where slash command and interaction responses are sent,
+// rather than to an API method.
+const responseURLHost = "hooks.slack.com"
+
+// actions are the Slack API methods that are recorded: those that post,
+// change, or remove something people see, or act on members.
+var actions = map[string]struct{}{
+	"chat.postMessage":         {},
+	"chat.postEphemeral":       {},
+	"chat.meMessage":           {},
+	"chat.scheduleMessage":     {},
+	"chat.update":              {},
+	"chat.delete":              {},
+	"chat.unfurl":              {},
+	"reactions.add":            {},
+	"reactions.remove":         {},
+	"files.upload":             {},
+	"conversations.invite":     {},
+	"conversations.kick":       {},
+	"conversations.archive":    {},
+	"conversations.setTopic":   {},
+	"conversations.setPurpose": {},
+	"users.admin.setInactive":  {},
+}
+
+// Transport is an http.RoundTripper for the Slack client, which records the
+// actions it's used to take in the Store. Actions taken while handling an
+// event are recorded with the event's ID as their trigger.
+type Transport struct {
+	// Base is the transport the requests are sent with.
+	Base http.RoundTripper
+
+	// Store is where the actions are recorded.
+	Store *Store
+
+	// Actor is the identity the client's token is for, like "bot".
+	Actor string
+
+	// Logger is for when recording an action fails, which doesn't fail the
+	// request.
+	Logger zerolog.Logger
+}
+
+// Client returns an *http.Client sending its requests with the Transport.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// RoundTrip satisfies http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	action, ok := requestAction(req)
+	if !ok {
+		return t.Base.RoundTrip(req)
+	}
+
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+
+		_ = req.Body.Close()
+
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	e := Entry{
+		Time:      time.Now(),
+		Actor:     t.Actor,
+		Action:    action,
+		ChannelID: requestChannel(req.Header.Get("Content-Type"), body),
+		UserIDs:   requestUsers(req.Header.Get("Content-Type"), body),
+	}
+
+	if meta, ok := workqueue.MetaFromContext(req.Context()); ok {
+		e.Trigger, e.RequestID = meta.ID, meta.RequestID
+	}
+
+	if e.Error, err = responseError(resp); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), appendTimeout)
+
+	if err := t.Store.Append(ctx, e); err != nil {
+		t.Logger.Error().
+			Err(err).
+			Str("action", e.Action).
+			Str("channel_id", e.ChannelID).
+			Str("trigger", e.Trigger).
+			Msg("failed to record action in audit log")
+	}
+
+	cancel()
+
+	return resp, nil
+}
+
+// requestAction returns the Slack API method the request is for, if it's one
+// that's recorded. Responses sent to a response URL are the "response_url"
+// action.
+func requestAction(req *http.Request) (string, bool) {
+	if req.Method != http.MethodPost {
+		return "", false
+	}
+
+	if req.URL.Host == responseURLHost {
+		return "response_url", true
+	}
+
+	action := path.Base(req.URL.Path)
+
+	_, ok := actions[action]
+
+	return action, ok
+}
+
+// requestChannel returns the channel the request is for, from the form or JSON
+// document in the body.
+func requestChannel(contentType string, body []byte) string {
+	mt, _, _ := mime.ParseMediaType(contentType)
+
+	if mt == "application/json" {
+		var doc struct {
+			Channel string `json:"channel"`
+		}
+
+		_ = json.Unmarshal(body, &doc)
+
+		return doc.Channel
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return ""
+	}
+
+	if c := values.Get("channel"); len(c) > 0 {
+		return c
+	}
+
+	// files.upload takes a list of channels
+	c := values.Get("channels")
+	if i := strings.IndexByte(c, ','); i >= 0 {
+		c = c[:i]
+	}
+
+	return c
+}
+
+// requestUsers returns the members the request acts on, from the user or
+// comma-separated users in the form or JSON document in the body.
+func requestUsers(contentType string, body []byte) []string {
+	mt, _, _ := mime.ParseMediaType(contentType)
+
+	var user, users string
+
+	if mt == "application/json" {
+		var doc struct {
+			User  string `json:"user"`
+			Users string `json:"users"`
+		}
+
+		_ = json.Unmarshal(body, &doc)
+
+		user, users = doc.User, doc.Users
+	} else {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil
+		}
+
+		user, users = values.Get("user"), values.Get("users")
+	}
+
+	var ids []string
+
+	if len(user) > 0 {
+		ids = append(ids, user)
+	}
+
+	for _, id := range strings.Split(users, ",") {
+		if id = strings.TrimSpace(id); len(id) > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// responseError returns the error Slack responded with, if any, leaving the
+// response body to be read again.
+func responseError(resp *http.Response) (string, error) {
+	if resp.StatusCode != http.StatusOK {
+		return resp.Status, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return "", err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var doc struct {
+		OK    *bool  `json:"ok"`
+		Error string `json:"error"`
+	}
+
+	// response URLs respond with plain text
+	if err := json.Unmarshal(body, &doc); err != nil || doc.OK == nil {
+		return "", nil
+	}
+
+	if !*doc.OK {
+		return strings.TrimSpace(doc.Error), nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_requestAction(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "post_message",
+			method: http.MethodPost,
+			url:    "https://slack.com/api/chat.postMessage",
+			want:   "chat.postMessage",
+			wantOK: true,
+		},
+		{
+			name:   "reaction",
+			method: http.MethodPost,
+			url:    "https://slack.com/api/reactions.add",
+			want:   "reactions.add",
+			wantOK: true,
+		},
+		{
+			name:   "response_url",
+			method: http.MethodPost,
+			url:    "https://hooks.slack.com/commands/T1/2/abc",
+			want:   "response_url",
+			wantOK: true,
+		},
+		{
+			name:   "read_only",
+			method: http.MethodPost,
+			url:    "https://slack.com/api/users.info",
+			want:   "users.info",
+		},
+		{
+			name:   "get",
+			method: http.MethodGet,
+			url:    "https://slack.com/api/chat.postMessage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := requestAction(httptest.NewRequest(tt.method, tt.url, nil))
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("requestAction() = %q, %t, want %q, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_requestChannel(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "token=xoxb&channel=C123&text=hi",
+			want:        "C123",
+		},
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"channel":"C456","text":"hi"}`,
+			want:        "C456",
+		},
+		{
+			name:        "file_upload",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "token=xoxb&channels=C789%2CC012&content=hi",
+			want:        "C789",
+		},
+		{
+			name:        "no_channel",
+			contentType: "application/json",
+			body:        `{"text":"hi"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestChannel(tt.contentType, []byte(tt.body)); got != tt.want {
+				t.Fatalf("requestChannel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_requestUsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []string
+	}{
+		{
+			name:        "ephemeral",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "token=xoxb&channel=C123&user=U123&text=hi",
+			want:        []string{"U123"},
+		},
+		{
+			name:        "invite",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "token=xoxb&channel=C123&users=U123%2C+U456",
+			want:        []string{"U123", "U456"},
+		},
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"channel":"C456","user":"U789"}`,
+			want:        []string{"U789"},
+		},
+		{
+			name:        "no_user",
+			contentType: "application/json",
+			body:        `{"channel":"C456","text":"hi"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := requestUsers(tt.contentType, []byte(tt.body))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("requestUsers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_responseError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{
+			name:   "ok",
+			status: http.StatusOK,
+			body:   `{"ok":true,"ts":"1.2"}`,
+		},
+		{
+			name:   "error",
+			status: http.StatusOK,
+			body:   `{"ok":false,"error":"not_in_channel"}`,
+			want:   "not_in_channel",
+		},
+		{
+			name:   "plain_text",
+			status: http.StatusOK,
+			body:   "ok",
+		},
+		{
+			name:   "status",
+			status: http.StatusTooManyRequests,
+			want:   "429 Too Many Requests",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Status:     "429 Too Many Requests",
+				Body:       ioutil.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			got, err := responseError(resp)
+			if err != nil {
+				t.Fatalf("responseError() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("responseError() = %q, want %q", got, tt.want)
+			}
+
+			// the body can still be read by the Slack client
+			body, _ := ioutil.ReadAll(resp.Body)
+			if tt.status == http.StatusOK && string(body) != tt.body {
+				t.Fatalf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
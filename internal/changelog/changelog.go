@@ -0,0 +1,114 @@
+// Package changelog is a small client for the changelog.com endpoints the bot
+// uses to find out when GoTime is streaming and what its next episode is.
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const baseURL = "https://changelog.com"
+
+// GoTime is the name of the GoTime podcast, as used in changelog.com URLs.
+const GoTime = "gotime"
+
+// Client is a changelog.com client.
+type Client struct {
+	http *http.Client
+}
+
+// New returns a *Client using the HTTP client.
+func New(c *http.Client) *Client {
+	return &Client{http: c}
+}
+
+// Streaming returns whether any changelog.com show is currently streaming.
+func (c *Client) Streaming(ctx context.Context) (bool, error) {
+	var status struct {
+		Streaming bool
+	}
+
+	if err := c.get(ctx, baseURL+"/live/status", &status); err != nil {
+		return false, err
+	}
+
+	return status.Streaming, nil
+}
+
+// Episode is a podcast episode scheduled to be recorded live.
+type Episode struct {
+	// At is when it's scheduled to start.
+	At time.Time
+
+	// Title, URL, and Guests are only known once changelog.com has
+	// published them, which is usually shortly before the recording.
+	Title  string
+	URL    string
+	Guests []string
+}
+
+// NextEpisode returns the podcast's next episode scheduled to be recorded
+// live. If there isn't one scheduled, its At is zero.
+func (c *Client) NextEpisode(ctx context.Context, podcast string) (Episode, error) {
+	var countdown struct {
+		Data    time.Time
+		Episode *struct {
+			Title  string
+			URL    string
+			Guests []struct {
+				Name string
+			}
+		}
+	}
+
+	if err := c.get(ctx, baseURL+"/slack/countdown/"+podcast, &countdown); err != nil {
+		return Episode{}, err
+	}
+
+	ep := Episode{At: countdown.Data}
+
+	if e := countdown.Episode; e != nil {
+		ep.Title = e.Title
+		ep.URL = e.URL
+
+		for _, g := range e.Guests {
+			ep.Guests = append(ep.Guests, g.Name)
+		}
+	}
+
+	return ep, nil
+}
+
+// get makes an HTTP request to url and unmarshals the JSON response into i.
+func (c *Client) get(ctx context.Context, url string, i interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("making http request: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-200 status code: %d - %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response body: %v", err)
+	}
+
+	err = json.Unmarshal(body, i)
+	if err != nil {
+		return fmt.Errorf("unmarshaling response: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+// Package contrib finds the golang/go issues suited to first-time
+// contributors, caching them so the first issue command doesn't search GitHub
+// every time it's used.
+package contrib
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gobridge/gopherbot/internal/github"
+)
+
+// query matches the open issues in golang/go labeled as wanting help, or as
+// good first issues.
+const query = `repo:golang/go is:issue is:open label:"help wanted","good first issue"`
+
+// perPage is how many issues are fetched, and cached, at once.
+const perPage = 10
+
+// Client gets the issues from GitHub, caching them in the Store.
+type Client struct {
+	gh    *github.Client
+	cache *Store
+}
+
+// New returns a *Client.
+func New(gh *github.Client, cache *Store) *Client {
+	return &Client{
+		gh:    gh,
+		cache: cache,
+	}
+}
+
+// FirstIssues returns the open issues labeled help wanted or good first issue,
+// most recently updated first.
+func (c *Client) FirstIssues(ctx context.Context) ([]github.Issue, error) {
+	is, found, err := c.cache.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		return is, nil
+	}
+
+	is, err = c.gh.SearchIssues(ctx, query, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+
+	if err = c.cache.Set(ctx, is); err != nil {
+		return nil, err
+	}
+
+	return is, nil
+}
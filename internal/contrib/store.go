@@ -0,0 +1,84 @@
+package contrib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/github"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKey     = "contrib:first_issues"
+	redisTestKey = "contrib:test_key"
+
+	// cacheTTL is how long the issues are cached. They're picked up slowly, so
+	// a list this old is still useful, and the search API has a low rate limit.
+	cacheTTL = 30 * time.Minute
+)
+
+// Store caches the issues in Redis.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Get returns the cached issues, if found.
+func (s *Store) Get(ctx context.Context) ([]github.Issue, bool, error) {
+	select {
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, redisKey)
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	var is []github.Issue
+
+	if err := json.Unmarshal([]byte(res.Val()), &is); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal issues: %w", err)
+	}
+
+	return is, true, nil
+}
+
+// Set caches the issues.
+func (s *Store) Set(ctx context.Context, is []github.Issue) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	j, err := json.Marshal(is)
+	if err != nil {
+		return fmt.Errorf("failed to marshal issues: %w", err)
+	}
+
+	if err = s.r.Set(ctx, redisKey, string(j), cacheTTL).Err(); err != nil {
+		return fmt.Errorf("failed to cache issues: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,274 @@
+// Package cron parses cron expressions, like "0 9 * * MON" for nine o'clock
+// every Monday, and works out when they next fire.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	expr string
+	loc  *time.Location
+
+	// the set bits are the values each field matches
+	minute, hour, dom, month, dow uint64
+
+	// whether the day fields were *, as a day matches if either of the day
+	// fields do only when both are restricted
+	domStar, dowStar bool
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is also Sunday, and is folded into 0 after parsing
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// tzPrefix is the optional prefix for the time zone the expression is in, like
+// "TZ=Europe/London 0 9 * * *". The default is UTC.
+const tzPrefix = "TZ="
+
+// Parse parses a standard five field cron expression: minute, hour, day of
+// month, month, and day of week. Each field can be *, a value, a range like
+// 1-5, a step like */15 or 0-30/10, or a comma-separated list of those. Months
+// and days of the week can also be given by their first three letters. The
+// macros @yearly, @monthly, @weekly, @daily, and @hourly are also supported.
+func Parse(expr string) (Schedule, error) {
+	s := Schedule{expr: strings.TrimSpace(expr), loc: time.UTC}
+
+	spec := s.expr
+
+	if strings.HasPrefix(spec, tzPrefix) {
+		i := strings.IndexByte(spec, ' ')
+		if i < 0 {
+			return Schedule{}, errors.New("time zone must be followed by a schedule")
+		}
+
+		loc, err := time.LoadLocation(spec[len(tzPrefix):i])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("failed to load time zone: %w", err)
+		}
+
+		s.loc = loc
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if m, ok := macros[strings.ToLower(spec)]; ok {
+		spec = m
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("expected 5 fields, found %d", len(fields))
+	}
+
+	var err error
+
+	if s.minute, _, err = parseField(fields[0], minuteField); err != nil {
+		return Schedule{}, err
+	}
+
+	if s.hour, _, err = parseField(fields[1], hourField); err != nil {
+		return Schedule{}, err
+	}
+
+	if s.dom, s.domStar, err = parseField(fields[2], domField); err != nil {
+		return Schedule{}, err
+	}
+
+	if s.month, _, err = parseField(fields[3], monthField); err != nil {
+		return Schedule{}, err
+	}
+
+	if s.dow, s.dowStar, err = parseField(fields[4], dowField); err != nil {
+		return Schedule{}, err
+	}
+
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+
+	return s, nil
+}
+
+// MustParse is Parse, except it panics if the expression is invalid. It's for
+// expressions in the code.
+func MustParse(expr string) Schedule {
+	s, err := Parse(expr)
+	if err != nil {
+		panic(fmt.Sprintf("invalid cron expression %q: %v", expr, err))
+	}
+
+	return s
+}
+
+// parseField returns the bits set for the values matched by the field, and
+// whether it was *.
+func parseField(s string, f field) (uint64, bool, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, step := f.min, f.max, 1
+
+		rng := part
+
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, false, fmt.Errorf("invalid %s step %q", f.name, part[i+1:])
+			}
+
+			rng, step = part[:i], n
+		}
+
+		switch {
+		case rng == "*":
+			// the whole range
+
+		case strings.IndexByte(rng, '-') > 0:
+			i := strings.IndexByte(rng, '-')
+
+			var err error
+
+			if lo, err = f.value(rng[:i]); err != nil {
+				return 0, false, err
+			}
+
+			if hi, err = f.value(rng[i+1:]); err != nil {
+				return 0, false, err
+			}
+
+			if lo > hi {
+				return 0, false, fmt.Errorf("invalid %s range %q", f.name, rng)
+			}
+
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, false, err
+			}
+
+			lo = v
+
+			// a single value with a step, like 5/15, runs to the end
+			if step == 1 {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, s == "*", nil
+}
+
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q: must be %d-%d", f.name, s, f.min, f.max)
+	}
+
+	return v, nil
+}
+
+// String returns the expression the schedule was parsed from.
+func (s Schedule) String() string {
+	return s.expr
+}
+
+// IsZero returns whether the schedule is the zero value, rather than parsed.
+func (s Schedule) IsZero() bool {
+	return s.loc == nil
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom, dow := has(s.dom, t.Day()), has(s.dow, int(t.Weekday()))
+
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// searchLimit is how far ahead Next looks, for expressions that never fire,
+// like February 30th.
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// Next returns the first time the schedule fires after t, or the zero time if
+// it never does.
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.IsZero() {
+		return time.Time{}
+	}
+
+	orig := t.Location()
+
+	t = t.In(s.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+			continue
+		}
+
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc)
+			continue
+		}
+
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t.In(orig)
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,235 @@
+package cron
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+func TestParse_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		err  string
+	}{
+		{name: "too_few", expr: "* * * *", err: "expected 5 fields, found 4"},
+		{name: "too_many", expr: "* * * * * *", err: "expected 5 fields, found 6"},
+		{name: "minute_range", expr: "60 * * * *", err: `invalid minute "60": must be 0-59`},
+		{name: "bad_name", expr: "0 0 * * FUN", err: `invalid day of week "FUN"`},
+		{name: "bad_step", expr: "*/0 * * * *", err: `invalid minute step "0"`},
+		{name: "backwards", expr: "0 0 * * 5-1", err: `invalid day of week range "5-1"`},
+		{name: "bad_tz", expr: "TZ=Nowhere/Special 0 0 * * *", err: "failed to load time zone"},
+		{name: "tz_only", expr: "TZ=UTC", err: "time zone must be followed by a schedule"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("Parse(%q) error = %v, should contain %q", tt.expr, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2026, time.October, 14, 10, 30, 15, 0, time.UTC)
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "every_minute",
+			expr: "* * * * *",
+			want: time.Date(2026, time.October, 14, 10, 31, 0, 0, time.UTC),
+		},
+		{
+			name: "step",
+			expr: "*/15 * * * *",
+			want: time.Date(2026, time.October, 14, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "next_hour",
+			expr: "15 * * * *",
+			want: time.Date(2026, time.October, 14, 11, 15, 0, 0, time.UTC),
+		},
+		{
+			name: "weekly_by_name",
+			expr: "0 9 * * MON",
+			want: time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "sunday_as_7",
+			expr: "0 0 * * 7",
+			want: time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekdays_list",
+			expr: "0 8,17 * * 1-5",
+			want: time.Date(2026, time.October, 14, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "dom_or_dow",
+			expr: "0 0 1 * FRI",
+			want: time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "next_year",
+			expr: "0 0 1 jan *",
+			want: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "leap_day",
+			expr: "0 12 29 2 *",
+			want: time.Date(2028, time.February, 29, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "macro",
+			expr: "@monthly",
+			want: time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "time_zone",
+			expr: "TZ=America/New_York 0 9 * * *",
+			want: time.Date(2026, time.October, 14, 9, 0, 0, 0, ny),
+		},
+		{
+			name: "never",
+			expr: "0 0 30 2 *",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.expr, err)
+			}
+
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Fatalf("Next() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_Add(t *testing.T) {
+	run := func(context.Context) error { return nil }
+	every := MustParse("* * * * *")
+
+	tests := []struct {
+		name string
+		job  Job
+		err  string
+	}{
+		{name: "valid", job: Job{Name: "a", Schedule: every, Timeout: time.Second, Run: run}},
+		{name: "duplicate", job: Job{Name: "a", Schedule: every, Timeout: time.Second, Run: run}, err: "job a already exists"},
+		{name: "no_name", job: Job{Schedule: every, Timeout: time.Second, Run: run}, err: "job name cannot be empty"},
+		{name: "no_schedule", job: Job{Name: "b", Timeout: time.Second, Run: run}, err: "job b must have a schedule"},
+		{name: "no_timeout", job: Job{Name: "b", Schedule: every, Run: run}, err: "job b must have a timeout"},
+		{name: "negative_jitter", job: Job{Name: "b", Schedule: every, Timeout: time.Second, Jitter: -1, Run: run}, err: "job b jitter cannot be negative"},
+		{name: "no_run", job: Job{Name: "b", Schedule: every, Timeout: time.Second}, err: "job b must have a Run func"},
+	}
+
+	s := &Scheduler{}
+
+	for _, tt := range tests {
+		err := s.Add(tt.job)
+
+		if len(tt.err) == 0 {
+			if err != nil {
+				t.Fatalf("%s: Add() unexpected error: %v", tt.name, err)
+			}
+
+			continue
+		}
+
+		if err == nil || err.Error() != tt.err {
+			t.Fatalf("%s: Add() error = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+
+	if got := s.Status(); len(got) != 1 || got[0].Name != "a" || got[0].Schedule != "* * * * *" {
+		t.Fatalf("Status() = %+v, want only job a", got)
+	}
+}
+
+// TestScheduler_lastRun reads last run times from the Redis at
+// GOPHER_TEST_REDIS_URL, falling back to a job's LegacyKey.
+func TestScheduler_lastRun(t *testing.T) {
+	url := os.Getenv("GOPHER_TEST_REDIS_URL")
+	if len(url) == 0 {
+		t.Skip("GOPHER_TEST_REDIS_URL not set")
+	}
+
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("failed to parse GOPHER_TEST_REDIS_URL: %v", err)
+	}
+
+	rc := redis.NewClient(opts)
+	defer func() { _ = rc.Close() }()
+
+	ctx := context.Background()
+
+	s, err := NewScheduler(rc, "test", zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewScheduler() unexpected error: %v", err)
+	}
+
+	const legacyKey = "test:cron:legacy_last_run_ts"
+
+	keys := []string{s.key("job"), legacyKey}
+
+	if err := rc.Del(ctx, keys...).Err(); err != nil {
+		t.Fatalf("failed to DEL keys: %v", err)
+	}
+	defer func() { _ = rc.Del(ctx, keys...).Err() }()
+
+	j := Job{Name: "job", LegacyKey: legacyKey}
+
+	check := func(want time.Time) {
+		t.Helper()
+
+		got, err := s.lastRun(ctx, j)
+		if err != nil {
+			t.Fatalf("lastRun() unexpected error: %v", err)
+		}
+
+		if !got.Equal(want) {
+			t.Fatalf("lastRun() = %s, want %s", got, want)
+		}
+	}
+
+	check(time.Time{})
+
+	legacy := time.Unix(1700000000, 0)
+	if err := rc.Set(ctx, legacyKey, legacy.Unix(), 0).Err(); err != nil {
+		t.Fatalf("failed to SET %s: %v", legacyKey, err)
+	}
+
+	check(legacy)
+
+	last := legacy.Add(10 * time.Minute)
+	if err := s.setLastRun(ctx, j.Name, last); err != nil {
+		t.Fatalf("setLastRun() unexpected error: %v", err)
+	}
+
+	check(last)
+}
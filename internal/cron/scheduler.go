@@ -0,0 +1,311 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const redisLastRunKeyFormat = "cron:%s:%s:last_run_ts" // scheduler, job
+
+// Job is something run on a schedule.
+type Job struct {
+	// Name identifies the job in the logs, status, and Redis.
+	Name string
+
+	Schedule Schedule
+
+	// Timeout is how long each run has before its context is canceled.
+	Timeout time.Duration
+
+	// Jitter is the most each run is randomly delayed by, so jobs on the
+	// same schedule don't all run at once.
+	Jitter time.Duration
+
+	// Run does the job.
+	Run func(ctx context.Context) error
+
+	// LegacyKey is the Redis key the job's last run time, in Unix seconds,
+	// was kept in before it was moved to the Scheduler. It's read when the
+	// Scheduler hasn't recorded a run yet, so the move doesn't run the job
+	// early. Optional.
+	LegacyKey string
+}
+
+// JobStatus is how a job's runs have gone since the process started.
+type JobStatus struct {
+	Name        string
+	Schedule    string
+	Next        time.Time
+	LastRun     time.Time
+	LastSuccess time.Time
+	LastError   string
+}
+
+type entry struct {
+	job Job
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+func (e *entry) update(fn func(s *JobStatus)) {
+	e.mu.Lock()
+	fn(&e.status)
+	e.mu.Unlock()
+}
+
+// Scheduler runs jobs on their schedules. Each job runs in its own goroutine,
+// so a slow job doesn't delay the others, and one run of a job never overlaps
+// another: if a run is still going when the next is due, that next run is
+// skipped.
+//
+// When each job last ran is kept in Redis, so a restart (like a deploy)
+// doesn't run every job at once, and a run missed while the process was down
+// happens once when it's back.
+type Scheduler struct {
+	r    *redis.Client
+	name string
+	l    zerolog.Logger
+
+	mu   sync.Mutex
+	jobs []*entry
+}
+
+// NewScheduler returns a new *Scheduler. The name namespaces the jobs' keys in
+// Redis.
+func NewScheduler(rc *redis.Client, name string, logger zerolog.Logger) (*Scheduler, error) {
+	if rc == nil {
+		return nil, errors.New("redis client cannot be nil")
+	}
+
+	if len(name) == 0 {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	return &Scheduler{
+		r:    rc,
+		name: name,
+		l:    logger,
+	}, nil
+}
+
+// Add adds a job. It must be called before Start.
+func (s *Scheduler) Add(j Job) error {
+	switch {
+	case len(j.Name) == 0:
+		return errors.New("job name cannot be empty")
+	case j.Schedule.IsZero():
+		return fmt.Errorf("job %s must have a schedule", j.Name)
+	case j.Timeout <= 0:
+		return fmt.Errorf("job %s must have a timeout", j.Name)
+	case j.Jitter < 0:
+		return fmt.Errorf("job %s jitter cannot be negative", j.Name)
+	case j.Run == nil:
+		return fmt.Errorf("job %s must have a Run func", j.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.jobs {
+		if e.job.Name == j.Name {
+			return fmt.Errorf("job %s already exists", j.Name)
+		}
+	}
+
+	s.jobs = append(s.jobs, &entry{
+		job:    j,
+		status: JobStatus{Name: j.Name, Schedule: j.Schedule.String()},
+	})
+
+	return nil
+}
+
+// Status returns the status of each job, in the order they were added.
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, len(s.jobs))
+
+	for i, e := range s.jobs {
+		e.mu.Lock()
+		statuses[i] = e.status
+		e.mu.Unlock()
+	}
+
+	return statuses
+}
+
+// Start runs the jobs until the context is canceled. The returned channel is
+// closed once they've all stopped, including any runs in progress.
+func (s *Scheduler) Start(ctx context.Context) chan struct{} {
+	s.mu.Lock()
+	jobs := append([]*entry(nil), s.jobs...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+
+	for _, e := range jobs {
+		wg.Add(1)
+
+		go func(e *entry) {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+
+	w := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(w)
+	}()
+
+	return w
+}
+
+func (s *Scheduler) key(job string) string {
+	return fmt.Sprintf(redisLastRunKeyFormat, s.name, job)
+}
+
+// lastRun returns when the job last ran, from its LegacyKey if the Scheduler
+// hasn't recorded it, or the zero time if it's not known.
+func (s *Scheduler) lastRun(ctx context.Context, j Job) (time.Time, error) {
+	ts, err := s.r.Get(ctx, s.key(j.Name)).Int64()
+	if err == redis.Nil && len(j.LegacyKey) > 0 {
+		ts, err = s.r.Get(ctx, j.LegacyKey).Int64()
+	}
+
+	if err != nil {
+		if err == redis.Nil {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, fmt.Errorf("failed to get last run time: %w", err)
+	}
+
+	return time.Unix(ts, 0), nil
+}
+
+func (s *Scheduler) setLastRun(ctx context.Context, job string, t time.Time) error {
+	if err := s.r.Set(ctx, s.key(job), t.Unix(), 0).Err(); err != nil {
+		return fmt.Errorf("failed to set last run time: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	logger := s.l.With().
+		Str("job", e.job.Name).
+		Str("schedule", e.job.Schedule.String()).
+		Logger()
+
+	from, err := s.lastRun(ctx, e.job)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to get when job last ran: waiting for its next run")
+	}
+
+	if from.IsZero() {
+		from = time.Now()
+	}
+
+	for {
+		next := e.job.Schedule.Next(from)
+		if next.IsZero() {
+			logger.Error().Msg("job's schedule never fires: not running it")
+			return
+		}
+
+		var jitter time.Duration
+		if e.job.Jitter > 0 {
+			jitter = time.Duration(rand.Int63n(int64(e.job.Jitter)))
+		}
+
+		e.update(func(st *JobStatus) { st.Next = next.Add(jitter) })
+
+		logger.Trace().
+			Time("next_run", next).
+			Dur("jitter", jitter).
+			Msg("waiting for job's next run")
+
+		t := time.NewTimer(time.Until(next) + jitter)
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+
+			logger.Info().
+				Err(ctx.Err()).
+				Msg("context canceled: shutting down job")
+
+			return
+
+		case <-t.C:
+		}
+
+		s.run(ctx, logger, e)
+
+		// skip the runs that were due while this one ran, rather than
+		// running them back to back
+		from = next
+		if now := time.Now(); e.job.Schedule.Next(from).Before(now) {
+			logger.Warn().
+				Time("scheduled", next).
+				Msg("job ran past its next run: skipping to the one after")
+
+			from = now
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, logger zerolog.Logger, e *entry) {
+	start := time.Now()
+
+	if err := s.setLastRun(ctx, e.job.Name, start); err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to save when job last ran")
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, e.job.Timeout)
+	err := e.job.Run(rctx)
+	cancel()
+
+	dur := time.Since(start)
+
+	e.update(func(st *JobStatus) {
+		st.LastRun = start
+
+		if err != nil {
+			st.LastError = err.Error()
+			return
+		}
+
+		st.LastSuccess = start
+		st.LastError = ""
+	})
+
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Dur("duration", dur).
+			Msg("job failed")
+
+		return
+	}
+
+	logger.Debug().
+		Dur("duration", dur).
+		Msg("job succeeded")
+}
@@ -0,0 +1,170 @@
+// Package crosspost detects the same message being posted to multiple public
+// channels within a short window.
+package crosspost
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// minFingerprintLen is the shortest normalized message we'll fingerprint, so
+// that common short messages like "thank you!" aren't treated as cross-posts.
+const minFingerprintLen = 40
+
+// Fingerprint returns a hash of the normalized message text, which ignores
+// case, punctuation, and whitespace. If ok is false, the message is too short
+// to fingerprint.
+func Fingerprint(text string) (fp string, ok bool) {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	if b.Len() < minFingerprintLen {
+		return "", false
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+
+	return hex.EncodeToString(sum[:16]), true
+}
+
+const (
+	redisKeyFormat = "crosspost:%s:%s"
+	redisTestKey   = "crosspost:test_key"
+)
+
+// Result is what the Detector found.
+type Result struct {
+	// Channels are the channels the same message was posted to within the
+	// window, including this one.
+	Channels []string
+
+	// Nudge is whether the user should be nudged to keep to one channel. It's
+	// only true the first time a message is seen in each additional channel.
+	Nudge bool
+
+	// Alert is whether moderators should be alerted. It's only true once per
+	// message, when it reaches the alert threshold.
+	Alert bool
+}
+
+// Detector tracks recent message fingerprints by user.
+type Detector struct {
+	r       *redis.Client
+	window  time.Duration
+	alertAt int
+}
+
+// New returns a *Detector that considers messages posted within window of each
+// other as cross-posts, and alerts once a message has been posted to alertAt
+// channels.
+func New(rc *redis.Client, window time.Duration, alertAt int) (*Detector, error) {
+	if alertAt < 2 {
+		return nil, fmt.Errorf("alertAt must be at least 2")
+	}
+
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Detector{
+		r:       rc,
+		window:  window,
+		alertAt: alertAt,
+	}, nil
+}
+
+// AlertAt is the number of channels at which moderators are alerted.
+func (d *Detector) AlertAt() int { return d.alertAt }
+
+// Check records that the user posted the message with the fingerprint in the
+// channel, and returns what was detected. The window slides, so only posts
+// within the window of now are counted.
+func (d *Detector) Check(ctx context.Context, userID, fp, channelID string, now time.Time) (Result, error) {
+	select {
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	default:
+		// noop
+	}
+
+	key := fmt.Sprintf(redisKeyFormat, userID, fp)
+	nowMS := now.UnixNano() / int64(time.Millisecond)
+	minMS := now.Add(-d.window).UnixNano() / int64(time.Millisecond)
+
+	pipe := d.r.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprintf("(%d", minMS))
+	added := pipe.ZAdd(ctx, key, redis.Z{Score: float64(nowMS), Member: channelID})
+	channels := pipe.ZRange(ctx, key, 0, -1)
+	pipe.Expire(ctx, key, d.window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return Result{}, fmt.Errorf("failed to record fingerprint: %w", err)
+	}
+
+	r := Result{Channels: channels.Val()}
+
+	// only new channels count, so editing or reposting in the same channel
+	// doesn't trigger anything
+	if added.Val() == 0 {
+		return r, nil
+	}
+
+	n := len(r.Channels)
+
+	r.Nudge = n >= 2
+	r.Alert = n == d.alertAt
+
+	return r, nil
+}
+
+// PurgeUser deletes the fingerprints of the user's recent messages, returning
+// how many there were. If dryRun is true, they're counted but not deleted. It
+// satisfies purge.Store.
+func (d *Detector) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	var cursor uint64
+	var found []string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+			// noop
+		}
+
+		keys, next, err := d.r.Scan(ctx, cursor, fmt.Sprintf(redisKeyFormat, userID, "*"), 100).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to SCAN redis keys: %w", err)
+		}
+
+		found = append(found, keys...)
+
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	if len(found) == 0 || dryRun {
+		return len(found), nil
+	}
+
+	if err := d.r.Del(ctx, found...).Err(); err != nil {
+		return 0, fmt.Errorf("failed to purge message fingerprints of %s: %w", userID, err)
+	}
+
+	return len(found), nil
+}
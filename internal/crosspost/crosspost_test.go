@@ -0,0 +1,33 @@
+package crosspost
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	const msg = "How do I convert a []byte to a string without allocating?"
+
+	fp, ok := Fingerprint(msg)
+	if !ok {
+		t.Fatalf("Fingerprint(%q) ok = false, want true", msg)
+	}
+
+	same := []string{
+		"how do i convert a []byte to a string without allocating",
+		"  How do I convert a []byte\nto a string without allocating??? ",
+		"HOW DO I CONVERT A BYTE TO A STRING WITHOUT ALLOCATING",
+	}
+
+	for _, s := range same {
+		got, ok := Fingerprint(s)
+		if !ok || got != fp {
+			t.Errorf("Fingerprint(%q) = %q, %t; want %q, true", s, got, ok, fp)
+		}
+	}
+
+	if got, _ := Fingerprint("How do I convert a string to a []byte without allocating?"); got == fp {
+		t.Errorf("different messages have the same fingerprint %q", fp)
+	}
+
+	if _, ok := Fingerprint("thank you so much!"); ok {
+		t.Error("Fingerprint() of a short message ok = true, want false")
+	}
+}
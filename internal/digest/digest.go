@@ -0,0 +1,221 @@
+// Package digest keeps a journal of the announcements the bot makes, like
+// merged CLs and GoTimeFM episodes, and formats them into a weekly summary.
+package digest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKey     = "digest:journal"
+	redisTestKey = "digest:test_key"
+)
+
+// Retention is how long entries are kept in the journal before Prune removes
+// them.
+const Retention = 28 * 24 * time.Hour
+
+// Entry is a single announcement in the journal.
+type Entry struct {
+	// Section is the heading the entry is listed under in the digest, like
+	// "Merged CLs".
+	Section string `json:"section"`
+
+	// Title describes the announcement.
+	Title string `json:"title"`
+
+	// URL is an optional link to more information.
+	URL string `json:"url,omitempty"`
+
+	// At is when the announcement was made. It's set by Record if zero.
+	At time.Time `json:"at"`
+}
+
+// Journal is the Redis-backed log of announcements.
+type Journal struct {
+	r *redis.Client
+}
+
+// NewJournal returns a new *Journal.
+func NewJournal(rc *redis.Client) (*Journal, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Journal{r: rc}, nil
+}
+
+// Key identifies what the entry announces, leaving out when, so announcing the
+// same thing again, like in another channel, is the same entry.
+func (e Entry) Key() string {
+	b, _ := json.Marshal(struct {
+		Section string `json:"section"`
+		Title   string `json:"title"`
+		URL     string `json:"url,omitempty"`
+	}{e.Section, e.Title, e.URL})
+
+	return string(b)
+}
+
+// Record adds the entry to the journal, unless the same entry was already
+// recorded, in which case it keeps when it was first recorded.
+func (j *Journal) Record(ctx context.Context, e Entry) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+
+	// the member is the Key, and when it was recorded is the score
+	z := redis.Z{
+		Score:  float64(e.At.Unix()),
+		Member: e.Key(),
+	}
+
+	if err := j.r.ZAddNX(ctx, redisKey, z).Err(); err != nil {
+		return fmt.Errorf("failed to ZADD redis key: %w", err)
+	}
+
+	return nil
+}
+
+// Since returns the entries recorded at or after t, oldest first.
+func (j *Journal) Since(ctx context.Context, t time.Time) ([]Entry, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := j.r.ZRangeByScoreWithScores(ctx, redisKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(t.Unix(), 10),
+		Max: "+inf",
+	})
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to ZRANGEBYSCORE redis key: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(res.Val()))
+
+	for _, z := range res.Val() {
+		m, _ := z.Member.(string)
+
+		e, err := parseMember(m, z.Score)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// parseMember returns the entry stored as the member, recorded at the score.
+// Entries recorded before the member was the Key have their time in it.
+func parseMember(m string, score float64) (Entry, error) {
+	var e Entry
+
+	if err := json.Unmarshal([]byte(m), &e); err != nil {
+		return Entry{}, fmt.Errorf("failed to unmarshal entry: %w", err)
+	}
+
+	if e.At.IsZero() {
+		e.At = time.Unix(int64(score), 0)
+	}
+
+	return e, nil
+}
+
+// Prune removes the entries recorded before t, returning how many there were.
+func (j *Journal) Prune(ctx context.Context, t time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	n, err := j.r.ZRemRangeByScore(ctx, redisKey, "-inf", "("+strconv.FormatInt(t.Unix(), 10)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to ZREMRANGEBYSCORE redis key: %w", err)
+	}
+
+	return n, nil
+}
+
+// escaper escapes the characters Slack uses for its control sequences.
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Format returns the entries as a Slack message, grouped by section in the
+// order each section first appears. Repeated announcements of the same thing,
+// like an event's week and day reminders, are only listed once.
+func Format(entries []Entry, since time.Time) string {
+	var sections []string
+	bySection := make(map[string][]Entry)
+	seen := make(map[Entry]struct{})
+
+	for _, e := range entries {
+		key := Entry{Section: e.Section, Title: e.Title, URL: e.URL}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		if _, ok := bySection[e.Section]; !ok {
+			sections = append(sections, e.Section)
+		}
+
+		bySection[e.Section] = append(bySection[e.Section], e)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ":newspaper: *Gophers digest for the week of %s*\n", since.UTC().Format("January 2, 2006"))
+
+	if len(sections) == 0 {
+		b.WriteString("\nIt was a quiet week, nothing was announced.")
+		return b.String()
+	}
+
+	for _, s := range sections {
+		es := bySection[s]
+
+		sort.SliceStable(es, func(i, j int) bool {
+			return es[i].At.Before(es[j].At)
+		})
+
+		fmt.Fprintf(&b, "\n*%s*\n", s)
+
+		for _, e := range es {
+			title := escaper.Replace(e.Title)
+
+			if len(e.URL) > 0 {
+				fmt.Fprintf(&b, "• <%s|%s>\n", e.URL, title)
+				continue
+			}
+
+			fmt.Fprintf(&b, "• %s\n", title)
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,78 @@
+package digest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormat(t *testing.T) {
+	since := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	at := func(d int) time.Time { return since.Add(time.Duration(d) * time.Hour) }
+
+	tests := []struct {
+		name    string
+		entries []Entry
+		want    string
+	}{
+		{
+			name: "empty",
+			want: ":newspaper: *Gophers digest for the week of October 5, 2026*\n\nIt was a quiet week, nothing was announced.",
+		},
+		{
+			name: "sections",
+			entries: []Entry{
+				{Section: "Merged CLs", Title: "[2] net/http: fix <nil> deref", URL: "https://go.dev/cl/2", At: at(5)},
+				{Section: "Events", Title: "GopherCon", URL: "https://gophercon.com", At: at(2)},
+				{Section: "Merged CLs", Title: "[1] cmd/go: add flag", URL: "https://go.dev/cl/1", At: at(1)},
+				{Section: "Events", Title: "GopherCon", URL: "https://gophercon.com", At: at(100)},
+				{Section: "GoTimeFM", Title: "GoTimeFM went live", At: at(50)},
+			},
+			want: ":newspaper: *Gophers digest for the week of October 5, 2026*\n" +
+				"\n*Merged CLs*\n" +
+				"• <https://go.dev/cl/1|[1] cmd/go: add flag>\n" +
+				"• <https://go.dev/cl/2|[2] net/http: fix &lt;nil&gt; deref>\n" +
+				"\n*Events*\n" +
+				"• <https://gophercon.com|GopherCon>\n" +
+				"\n*GoTimeFM*\n" +
+				"• GoTimeFM went live",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := Format(tt.entries, since)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Format() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEntry_Key(t *testing.T) {
+	first := Entry{Section: "Events", Title: "GopherCon", URL: "https://gophercon.com", At: time.Unix(100, 0)}
+	again := first
+	again.At = time.Unix(200, 0)
+
+	if first.Key() != again.Key() {
+		t.Fatalf("Key() = %q and %q for the same announcement at different times", first.Key(), again.Key())
+	}
+
+	got, err := parseMember(first.Key(), 100)
+	if err != nil {
+		t.Fatalf("parseMember() unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(first, got); diff != "" {
+		t.Fatalf("parseMember() mismatch (-want +got):\n%s", diff)
+	}
+
+	legacy := `{"section":"Events","title":"GopherCon","at":"1970-01-01T00:01:40Z"}`
+
+	if got, err = parseMember(legacy, 0); err != nil || !got.At.Equal(time.Unix(100, 0)) {
+		t.Fatalf("parseMember() of a legacy member = %+v, %v, want At from the member", got, err)
+	}
+}
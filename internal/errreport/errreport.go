@@ -0,0 +1,196 @@
+// Package errreport sends the errors we log to an error aggregator, like
+// Sentry, with where they were logged from and the context that was logged
+// with them, so they can be grouped and alerted on rather than only being in
+// the logs.
+package errreport
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// dropped counts the events not reported because the queue was full.
+var dropped = expvar.NewInt("errreport_dropped")
+
+// queueSize is how many events can be waiting to be reported before new ones
+// are dropped, so a burst of errors doesn't block logging.
+const queueSize = 100
+
+// reportTimeout is how long each event has to be reported.
+const reportTimeout = 5 * time.Second
+
+// maxFrames is the deepest stack trace captured.
+const maxFrames = 64
+
+// Frame is a stack frame.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// Event is an error to report.
+type Event struct {
+	Time  time.Time
+	Level zerolog.Level
+
+	// Message is the log message, like "failed to publish event".
+	Message string
+
+	// Error is the error that was logged, if any.
+	Error string
+
+	// Fields are the rest of the fields logged, like the event or request ID.
+	Fields map[string]interface{}
+
+	// Stack is where the error was logged from, innermost frame first.
+	Stack []Frame
+}
+
+// Reporter sends events to an error aggregator.
+type Reporter interface {
+	Report(ctx context.Context, e Event) error
+}
+
+// Writer is a zerolog.LevelWriter that reports each error logged to it, to be
+// combined with the usual output using zerolog.MultiLevelWriter. Errors are
+// reported in the background, except for fatal and panic levels, which are
+// reported before the write returns as the process is about to exit.
+type Writer struct {
+	r      Reporter
+	events chan Event
+}
+
+var _ zerolog.LevelWriter = (*Writer)(nil)
+
+// NewWriter returns a *Writer reporting to r. It starts a goroutine that
+// reports for the life of the process.
+func NewWriter(r Reporter) *Writer {
+	w := &Writer{
+		r:      r,
+		events: make(chan Event, queueSize),
+	}
+
+	go w.run()
+
+	return w
+}
+
+func (w *Writer) run() {
+	for e := range w.events {
+		w.report(e)
+	}
+}
+
+func (w *Writer) report(e Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+
+	// we can't log this without it being reported, and looping
+	_ = w.r.Report(ctx, e)
+}
+
+// Write satisfies io.Writer. Writes without a level aren't reported.
+func (w *Writer) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteLevel reports the log line if it's at least an error.
+func (w *Writer) WriteLevel(l zerolog.Level, p []byte) (int, error) {
+	if l < zerolog.ErrorLevel || l == zerolog.NoLevel {
+		return len(p), nil
+	}
+
+	e, err := parseEvent(l, p)
+	if err != nil {
+		return len(p), nil
+	}
+
+	e.Stack = callers()
+
+	if l >= zerolog.FatalLevel {
+		w.report(e)
+		return len(p), nil
+	}
+
+	select {
+	case w.events <- e:
+	default:
+		dropped.Add(1)
+	}
+
+	return len(p), nil
+}
+
+// parseEvent parses a JSON log line.
+func parseEvent(l zerolog.Level, p []byte) (Event, error) {
+	var fields map[string]interface{}
+
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return Event{}, fmt.Errorf("failed to unmarshal log line: %w", err)
+	}
+
+	e := Event{
+		Time:  time.Now(),
+		Level: l,
+	}
+
+	if m, ok := fields[zerolog.MessageFieldName].(string); ok {
+		e.Message = m
+	}
+
+	if m, ok := fields[zerolog.ErrorFieldName].(string); ok {
+		e.Error = m
+	}
+
+	delete(fields, zerolog.LevelFieldName)
+	delete(fields, zerolog.MessageFieldName)
+	delete(fields, zerolog.ErrorFieldName)
+	delete(fields, zerolog.TimestampFieldName)
+
+	e.Fields = fields
+
+	return e, nil
+}
+
+// callers returns the stack of the goroutine logging, skipping the frames
+// inside zerolog and this package.
+func callers() []Frame {
+	pcs := make([]uintptr, maxFrames)
+	n := runtime.Callers(2, pcs)
+
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []Frame
+
+	for {
+		f, more := frames.Next()
+
+		if len(stack) > 0 || !internalFrame(f.Function) {
+			stack = append(stack, Frame{
+				Function: f.Function,
+				File:     f.File,
+				Line:     f.Line,
+			})
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return stack
+}
+
+func internalFrame(fn string) bool {
+	return strings.HasPrefix(fn, "github.com/rs/zerolog.") ||
+		strings.HasPrefix(fn, "github.com/gobridge/gopherbot/internal/errreport.(*Writer).") ||
+		fn == "github.com/gobridge/gopherbot/internal/errreport.callers"
+}
@@ -0,0 +1,258 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// tagFields are the logged fields sent to Sentry as tags, which can be
+// searched and filtered on, rather than extra data.
+var tagFields = map[string]struct{}{
+	"context":      {},
+	"handler":      {},
+	"poller":       {},
+	"event_type":   {},
+	"event_id":     {},
+	"request_id":   {},
+	"redis_stream": {},
+}
+
+// inAppPrefix is the prefix of our own functions, which Sentry highlights in
+// stack traces.
+const inAppPrefix = "github.com/gobridge/gopherbot/"
+
+// Sentry reports events to Sentry, or anything else accepting its store API.
+type Sentry struct {
+	storeURL    string
+	auth        string
+	environment string
+	release     string
+	serverName  string
+	http        *http.Client
+}
+
+var _ Reporter = (*Sentry)(nil)
+
+// ParseDSN parses a Sentry DSN, like https://key@sentry.example.com/42,
+// returning the URL to send events to and the key to authenticate with.
+func ParseDSN(dsn string) (storeURL, key string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse DSN: %w", err)
+	}
+
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", "", fmt.Errorf("DSN scheme must be http or https, got %q", u.Scheme)
+	}
+
+	if u.User == nil || len(u.User.Username()) == 0 {
+		return "", "", fmt.Errorf("DSN is missing the key")
+	}
+
+	prefix, project := path.Split(strings.TrimSuffix(u.Path, "/"))
+	if len(project) == 0 {
+		return "", "", fmt.Errorf("DSN is missing the project ID")
+	}
+
+	store := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   path.Join(prefix, "api", project, "store") + "/",
+	}
+
+	return store.String(), u.User.Username(), nil
+}
+
+// NewSentry returns a *Sentry for the DSN. The environment, release, and
+// server name are added to each event, and can be empty.
+func NewSentry(dsn, environment, release, serverName string, httpc *http.Client) (*Sentry, error) {
+	storeURL, key, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpc == nil {
+		httpc = &http.Client{Timeout: reportTimeout}
+	}
+
+	return &Sentry{
+		storeURL:    storeURL,
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=gopherbot/1.0, sentry_key=%s", key),
+		environment: environment,
+		release:     release,
+		serverName:  serverName,
+		http:        httpc,
+	}, nil
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value,omitempty"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger,omitempty"`
+	Message     string                 `json:"message,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Exception   []sentryException      `json:"exception,omitempty"`
+}
+
+func sentryLevel(l zerolog.Level) string {
+	switch l {
+	case zerolog.FatalLevel, zerolog.PanicLevel:
+		return "fatal"
+	case zerolog.WarnLevel:
+		return "warning"
+	default:
+		return l.String()
+	}
+}
+
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func (s *Sentry) event(e Event) (sentryEvent, error) {
+	id, err := newEventID()
+	if err != nil {
+		return sentryEvent{}, fmt.Errorf("failed to generate event ID: %w", err)
+	}
+
+	se := sentryEvent{
+		EventID:     id,
+		Timestamp:   e.Time.UTC().Format(time.RFC3339),
+		Level:       sentryLevel(e.Level),
+		Platform:    "go",
+		Message:     e.Message,
+		Environment: s.environment,
+		Release:     s.release,
+		ServerName:  s.serverName,
+	}
+
+	for k, v := range e.Fields {
+		sv, ok := v.(string)
+		if _, tag := tagFields[k]; tag && ok {
+			if se.Tags == nil {
+				se.Tags = make(map[string]string)
+			}
+
+			se.Tags[k] = sv
+			continue
+		}
+
+		if se.Extra == nil {
+			se.Extra = make(map[string]interface{})
+		}
+
+		se.Extra[k] = v
+	}
+
+	if c, ok := se.Tags["context"]; ok {
+		se.Logger = c
+	}
+
+	ex := sentryException{
+		Type:  e.Message,
+		Value: e.Error,
+	}
+
+	if len(ex.Type) == 0 {
+		ex.Type = "error"
+	}
+
+	if len(e.Stack) > 0 {
+		// Sentry wants the outermost frame first
+		frames := make([]sentryFrame, len(e.Stack))
+
+		for i, f := range e.Stack {
+			frames[len(e.Stack)-1-i] = sentryFrame{
+				Function: f.Function,
+				AbsPath:  f.File,
+				Lineno:   f.Line,
+				InApp:    strings.HasPrefix(f.Function, inAppPrefix),
+			}
+		}
+
+		ex.Stacktrace = &sentryStacktrace{Frames: frames}
+	}
+
+	se.Exception = []sentryException{ex}
+
+	return se, nil
+}
+
+// Report satisfies Reporter.
+func (s *Sentry) Report(ctx context.Context, e Event) error {
+	se, err := s.event(e)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(se)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,319 @@
+// Package eventarchive archives the raw Slack events the gateway validates to
+// S3-compatible object storage, for offline analysis and replay. Events are
+// buffered as gzipped JSON Lines and uploaded in hourly batches, optionally
+// scrubbed of message text and user details first, and batches older than the
+// retention period are deleted.
+package eventarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"expvar"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	// archived counts the events added to a batch.
+	archived = expvar.NewInt("eventarchive_events")
+
+	// uploaded counts the batches uploaded.
+	uploaded = expvar.NewInt("eventarchive_batches")
+
+	// lost counts the events in batches that couldn't be uploaded.
+	lost = expvar.NewInt("eventarchive_lost")
+)
+
+const (
+	// FlushInterval is how often the batch is uploaded.
+	FlushInterval = time.Hour
+
+	// maxBatchSize is how large the compressed batch can get before it's
+	// uploaded early, to bound the memory it takes during a busy hour.
+	maxBatchSize = 32 << 20
+
+	// uploadAttempts is how many times uploading a batch is tried before its
+	// events are lost.
+	uploadAttempts = 3
+)
+
+// Config is the configuration for an Archiver.
+type Config struct {
+	// Storage is where the batches are uploaded.
+	Storage Storage
+
+	// Prefix is the key prefix the batches are uploaded under, and pruned
+	// from. Keys look like <prefix>/2026/10/16/09/<name>-<unix nanos>.jsonl.gz.
+	// It can only be empty if batches are kept forever, so pruning never lists
+	// the whole bucket.
+	Prefix string
+
+	// Name identifies the process in the batches' keys, so gateways running
+	// side by side don't overwrite each other's batches.
+	Name string
+
+	// Scrub is what's removed from each event before it's archived.
+	Scrub Scrub
+
+	// Retention is how long batches are kept. They're kept forever if zero.
+	// Only the batches with this Name are pruned.
+	Retention time.Duration
+
+	Logger zerolog.Logger
+}
+
+// Archiver buffers events and uploads them in batches.
+type Archiver struct {
+	storage   Storage
+	prefix    string
+	name      string
+	scrub     Scrub
+	retention time.Duration
+	logger    zerolog.Logger
+
+	mu      sync.Mutex
+	buf     *bytes.Buffer
+	gz      *gzip.Writer
+	n       int
+	started time.Time
+
+	// full is signaled when the batch passes maxBatchSize
+	full chan struct{}
+
+	// retryDelay is multiplied by the attempt number to wait between uploads
+	retryDelay time.Duration
+
+	now func() time.Time
+}
+
+// New returns a new *Archiver.
+func New(cfg Config) (*Archiver, error) {
+	if cfg.Storage == nil {
+		return nil, fmt.Errorf("storage cannot be nil")
+	}
+
+	if len(cfg.Name) == 0 {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+
+	if cfg.Retention > 0 && len(strings.Trim(cfg.Prefix, "/")) == 0 {
+		return nil, fmt.Errorf("prefix cannot be empty when batches are pruned")
+	}
+
+	a := &Archiver{
+		storage:   cfg.Storage,
+		prefix:    cfg.Prefix,
+		name:      cfg.Name,
+		scrub:     cfg.Scrub,
+		retention: cfg.Retention,
+		logger:    cfg.Logger,
+		full:      make(chan struct{}, 1),
+
+		retryDelay: time.Second,
+		now:        time.Now,
+	}
+
+	a.reset()
+
+	return a, nil
+}
+
+// reset starts a new batch. The caller must hold mu, or have the only
+// reference to a.
+func (a *Archiver) reset() {
+	a.buf = &bytes.Buffer{}
+	a.gz = gzip.NewWriter(a.buf)
+	a.n = 0
+	a.started = time.Time{}
+}
+
+// Add scrubs the event document and adds it to the batch, as a single line.
+func (a *Archiver) Add(document []byte) error {
+	line, err := a.scrub.Apply(document)
+	if err != nil {
+		return fmt.Errorf("failed to scrub event: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.n == 0 {
+		a.started = a.now()
+	}
+
+	if _, err := a.gz.Write(line); err != nil {
+		return fmt.Errorf("failed to compress event: %w", err)
+	}
+
+	a.n++
+	archived.Add(1)
+
+	if a.buf.Len() >= maxBatchSize {
+		select {
+		case a.full <- struct{}{}:
+		default:
+			// already signaled
+		}
+	}
+
+	return nil
+}
+
+// key returns the key for a batch started at t.
+func (a *Archiver) key(t time.Time) string {
+	t = t.UTC()
+
+	return path.Join(
+		a.prefix,
+		t.Format("2006/01/02/15"),
+		fmt.Sprintf("%s-%d.jsonl.gz", a.name, t.UnixNano()),
+	)
+}
+
+// Flush uploads the batch, if there are any events in it, and starts a new
+// one. Its events are lost if it can't be uploaded.
+func (a *Archiver) Flush(ctx context.Context) error {
+	a.mu.Lock()
+
+	if a.n == 0 {
+		a.mu.Unlock()
+		return nil
+	}
+
+	gz, buf, n, started := a.gz, a.buf, a.n, a.started
+	a.reset()
+
+	a.mu.Unlock()
+
+	if err := gz.Close(); err != nil {
+		lost.Add(int64(n))
+		return fmt.Errorf("failed to compress batch: %w", err)
+	}
+
+	key := a.key(started)
+
+	var err error
+
+	for i := 0; i < uploadAttempts; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				lost.Add(int64(n))
+				return ctx.Err()
+			case <-time.After(time.Duration(i) * a.retryDelay):
+			}
+		}
+
+		if err = a.storage.Put(ctx, key, buf.Bytes()); err == nil {
+			uploaded.Add(1)
+
+			a.logger.Debug().
+				Str("key", key).
+				Int("events", n).
+				Int("bytes", buf.Len()).
+				Msg("archived events")
+
+			return nil
+		}
+	}
+
+	lost.Add(int64(n))
+
+	return fmt.Errorf("failed to upload batch of %d events: %w", n, err)
+}
+
+// Prune deletes this archiver's batches older than the retention period,
+// returning how many were deleted. Other objects under the prefix are left
+// alone. It does nothing if batches are kept forever.
+func (a *Archiver) Prune(ctx context.Context) (int, error) {
+	if a.retention <= 0 {
+		return 0, nil
+	}
+
+	objs, err := a.storage.List(ctx, strings.Trim(a.prefix, "/")+"/")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list batches: %w", err)
+	}
+
+	cutoff := a.now().Add(-a.retention)
+
+	var deleted int
+
+	for _, o := range objs {
+		if !a.isBatch(o.Key) || !o.LastModified.Before(cutoff) {
+			continue
+		}
+
+		if err := a.storage.Delete(ctx, o.Key); err != nil {
+			return deleted, err
+		}
+
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// isBatch returns whether the key is one of this archiver's batches, named
+// <name>-<unix nanos>.jsonl.gz.
+func (a *Archiver) isBatch(key string) bool {
+	base := path.Base(key)
+
+	if !strings.HasPrefix(base, a.name+"-") || !strings.HasSuffix(base, ".jsonl.gz") {
+		return false
+	}
+
+	nanos := strings.TrimSuffix(strings.TrimPrefix(base, a.name+"-"), ".jsonl.gz")
+
+	_, err := strconv.ParseInt(nanos, 10, 64)
+
+	return err == nil
+}
+
+// Run uploads the batch every FlushInterval, or sooner if it gets large, and
+// then prunes old batches, until the context is canceled. The batch in
+// progress then is left for the caller to Flush while shutting down.
+func (a *Archiver) Run(ctx context.Context) {
+	t := time.NewTicker(FlushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-a.full:
+		}
+
+		if err := a.Flush(ctx); err != nil {
+			a.logger.Error().
+				Err(err).
+				Msg("failed to archive events")
+		}
+
+		n, err := a.Prune(ctx)
+		if err != nil {
+			a.logger.Error().
+				Err(err).
+				Int("deleted", n).
+				Msg("failed to prune archived events")
+
+			continue
+		}
+
+		if n > 0 {
+			a.logger.Info().
+				Int("deleted", n).
+				Msg("pruned archived events")
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package eventarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rs/zerolog"
+)
+
+type memStorage struct {
+	objects map[string][]byte
+	times   map[string]time.Time
+	fail    int
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{
+		objects: make(map[string][]byte),
+		times:   make(map[string]time.Time),
+	}
+}
+
+func (m *memStorage) Put(_ context.Context, key string, body []byte) error {
+	if m.fail > 0 {
+		m.fail--
+		return fmt.Errorf("unavailable")
+	}
+
+	m.objects[key] = append([]byte(nil), body...)
+	return nil
+}
+
+func (m *memStorage) List(_ context.Context, prefix string) ([]Object, error) {
+	var objs []Object
+
+	for k := range m.objects {
+		if len(k) >= len(prefix) && k[:len(prefix)] == prefix {
+			objs = append(objs, Object{Key: k, LastModified: m.times[k]})
+		}
+	}
+
+	sort.Slice(objs, func(i, j int) bool { return objs[i].Key < objs[j].Key })
+
+	return objs, nil
+}
+
+func (m *memStorage) Delete(_ context.Context, key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func gunzip(t *testing.T, b []byte) string {
+	t.Helper()
+
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+
+	out, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	return string(out)
+}
+
+func TestArchiver_Flush(t *testing.T) {
+	ms := newMemStorage()
+
+	a, err := New(Config{Storage: ms, Prefix: "events", Name: "web.1", Logger: zerolog.Nop()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	start := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	a.now = func() time.Time { return start }
+	a.retryDelay = 0
+
+	if err := a.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() with no events error = %v", err)
+	}
+
+	if len(ms.objects) != 0 {
+		t.Fatalf("Flush() with no events uploaded %d objects", len(ms.objects))
+	}
+
+	for _, doc := range []string{"{\n  \"event_id\": \"Ev1\"\n}", `{"event_id":"Ev2"}`} {
+		if err := a.Add([]byte(doc)); err != nil {
+			t.Fatalf("Add(%q) error = %v", doc, err)
+		}
+	}
+
+	ms.fail = 2
+
+	if err := a.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	key := fmt.Sprintf("events/2026/10/16/09/web.1-%d.jsonl.gz", start.UnixNano())
+
+	body, ok := ms.objects[key]
+	if !ok {
+		t.Fatalf("Flush() didn't upload %s, got %v", key, ms.objects)
+	}
+
+	want := "{\"event_id\":\"Ev1\"}\n{\"event_id\":\"Ev2\"}\n"
+	if got := gunzip(t, body); got != want {
+		t.Errorf("batch = %q, want %q", got, want)
+	}
+
+	if err := a.Flush(context.Background()); err != nil {
+		t.Fatalf("second Flush() error = %v", err)
+	}
+
+	if len(ms.objects) != 1 {
+		t.Errorf("second Flush() uploaded an empty batch")
+	}
+}
+
+func TestArchiver_FlushFails(t *testing.T) {
+	ms := newMemStorage()
+	ms.fail = uploadAttempts
+
+	a, err := New(Config{Storage: ms, Name: "web.1", Logger: zerolog.Nop()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	a.retryDelay = 0
+
+	if err := a.Add([]byte(`{}`)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if err := a.Flush(context.Background()); err == nil {
+		t.Fatal("Flush() error = nil, want an error")
+	}
+
+	if err := a.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() after failing error = %v, want the batch to be dropped", err)
+	}
+}
+
+func TestArchiver_Prune(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	ms := newMemStorage()
+	for k, age := range map[string]time.Duration{
+		"events/2026/07/01/00/web.1-1.jsonl.gz":    107 * 24 * time.Hour,
+		"events/2026/07/20/00/web.1-2.jsonl.gz":    88 * 24 * time.Hour,
+		"events/2026/07/01/00/web.2-4.jsonl.gz":    107 * 24 * time.Hour,
+		"events/2026/07/01/00/web.1-5.json":        107 * 24 * time.Hour,
+		"events/README":                            288 * 24 * time.Hour,
+		"eventsold/2026/01/01/00/web.1-6.jsonl.gz": 288 * 24 * time.Hour,
+		"other/2026/01/01/00/web.1-3.jsonl.gz":     288 * 24 * time.Hour,
+	} {
+		ms.objects[k] = nil
+		ms.times[k] = now.Add(-age)
+	}
+
+	a, err := New(Config{Storage: ms, Prefix: "events", Name: "web.1", Retention: 90 * 24 * time.Hour, Logger: zerolog.Nop()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	a.now = func() time.Time { return now }
+
+	n, err := a.Prune(context.Background())
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+
+	if n != 1 {
+		t.Errorf("Prune() = %d, want 1", n)
+	}
+
+	var keys []string
+	for k := range ms.objects {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	want := []string{
+		"events/2026/07/01/00/web.1-5.json",
+		"events/2026/07/01/00/web.2-4.jsonl.gz",
+		"events/2026/07/20/00/web.1-2.jsonl.gz",
+		"events/README",
+		"eventsold/2026/01/01/00/web.1-6.jsonl.gz",
+		"other/2026/01/01/00/web.1-3.jsonl.gz",
+	}
+
+	if diff := cmp.Diff(want, keys); diff != "" {
+		t.Errorf("objects after Prune() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNew_prefix(t *testing.T) {
+	tests := []struct {
+		name      string
+		prefix    string
+		retention time.Duration
+		err       bool
+	}{
+		{name: "pruned", prefix: "events", retention: time.Hour},
+		{name: "kept_forever", retention: 0},
+		{name: "pruned_without_prefix", retention: time.Hour, err: true},
+		{name: "pruned_with_slash_prefix", prefix: "/", retention: time.Hour, err: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{Storage: newMemStorage(), Prefix: tt.prefix, Name: "web.1", Retention: tt.retention, Logger: zerolog.Nop()})
+			if (err != nil) != tt.err {
+				t.Fatalf("New() error = %v, want error %t", err, tt.err)
+			}
+		})
+	}
+}
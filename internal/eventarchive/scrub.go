@@ -0,0 +1,209 @@
+package eventarchive
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Scrub is what's removed from events before they're archived. The zero value
+// archives them as Slack sent them.
+type Scrub struct {
+	// Text removes what members wrote: message text, blocks, attachments,
+	// files, and channel topics and purposes.
+	Text bool
+
+	// Users replaces user IDs with pseudonyms, which are the same in every
+	// batch so a member's events can still be correlated, and removes their
+	// names, emails, and profiles.
+	Users bool
+}
+
+// ParseScrub parses a list of what to scrub, "text" and "users", or "none".
+func ParseScrub(list []string) (Scrub, error) {
+	var s Scrub
+
+	for _, v := range list {
+		switch strings.ToLower(v) {
+		case "text":
+			s.Text = true
+		case "users":
+			s.Users = true
+		case "none":
+			if len(list) > 1 {
+				return Scrub{}, fmt.Errorf("none cannot be combined with other values")
+			}
+		default:
+			return Scrub{}, fmt.Errorf("unknown value %q: must be text, users, or none", v)
+		}
+	}
+
+	return s, nil
+}
+
+// textFields are the fields removed by Scrub.Text, wherever they are in the
+// event.
+var textFields = map[string]struct{}{
+	"text":        {},
+	"blocks":      {},
+	"attachments": {},
+	"files":       {},
+	"topic":       {},
+	"purpose":     {},
+	"title":       {},
+	"preview":     {},
+}
+
+// userFields are the fields holding user IDs, which Scrub.Users pseudonymizes.
+var userFields = map[string]struct{}{
+	"user":           {},
+	"user_id":        {},
+	"inviter":        {},
+	"item_user":      {},
+	"creator":        {},
+	"parent_user_id": {},
+	"reply_users":    {},
+	"authed_users":   {},
+	"users":          {},
+}
+
+// personalFields are the fields describing a member, which Scrub.Users
+// removes wherever they are in the event.
+var personalFields = map[string]struct{}{
+	"profile":      {},
+	"user_profile": {},
+	"email":        {},
+	"username":     {},
+}
+
+// userObjectFields are the fields of user objects, like the one in team_join
+// events, which Scrub.Users removes along with personalFields.
+var userObjectFields = map[string]struct{}{
+	"name":      {},
+	"real_name": {},
+	"tz":        {},
+	"tz_label":  {},
+	"tz_offset": {},
+}
+
+// mentionRegex matches user mentions in text, like <@U012AB3CD> or
+// <@U012AB3CD|name>.
+var mentionRegex = regexp.MustCompile(`<@([UW][A-Z0-9]+)(\|[^>]*)?>`)
+
+// userIDRegex matches what looks like a user ID.
+var userIDRegex = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+
+// Pseudonym returns the pseudonym for a user ID, which starts with X so it
+// can't be mistaken for a real one.
+func Pseudonym(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return "X" + strings.ToUpper(hex.EncodeToString(sum[:6]))
+}
+
+// Apply returns the document scrubbed, compacted to a single line.
+func (s Scrub) Apply(document []byte) ([]byte, error) {
+	if !s.Text && !s.Users {
+		var buf bytes.Buffer
+
+		if err := json.Compact(&buf, document); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(document))
+	dec.UseNumber()
+
+	var v interface{}
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(s.walk(v))
+}
+
+func (s Scrub) walk(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range t {
+			if _, ok := textFields[k]; ok && s.Text {
+				delete(t, k)
+				continue
+			}
+
+			if _, ok := personalFields[k]; ok && s.Users {
+				delete(t, k)
+				continue
+			}
+
+			if _, ok := userFields[k]; ok && s.Users {
+				t[k] = s.pseudonymize(fv)
+				continue
+			}
+
+			if k == "text" && s.Users {
+				if str, ok := fv.(string); ok {
+					t[k] = mentionRegex.ReplaceAllStringFunc(str, func(m string) string {
+						return "<@" + Pseudonym(mentionRegex.FindStringSubmatch(m)[1]) + ">"
+					})
+
+					continue
+				}
+			}
+
+			t[k] = s.walk(fv)
+		}
+
+		return t
+
+	case []interface{}:
+		for i, e := range t {
+			t[i] = s.walk(e)
+		}
+
+		return t
+
+	default:
+		return v
+	}
+}
+
+// pseudonymize replaces the user IDs in a user field, which is either an ID,
+// a list of them, or a user object.
+func (s Scrub) pseudonymize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		if userIDRegex.MatchString(t) {
+			return Pseudonym(t)
+		}
+
+		return t
+
+	case []interface{}:
+		for i, e := range t {
+			t[i] = s.pseudonymize(e)
+		}
+
+		return t
+
+	case map[string]interface{}:
+		if id, ok := t["id"].(string); ok {
+			t["id"] = Pseudonym(id)
+		}
+
+		for k := range userObjectFields {
+			delete(t, k)
+		}
+
+		return s.walk(t)
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,142 @@
+package eventarchive
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseScrub(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    []string
+		want    Scrub
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "none", list: []string{"none"}},
+		{name: "text", list: []string{"text"}, want: Scrub{Text: true}},
+		{name: "both", list: []string{"users", "TEXT"}, want: Scrub{Text: true, Users: true}},
+		{name: "none_combined", list: []string{"none", "text"}, wantErr: true},
+		{name: "unknown", list: []string{"emails"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScrub(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseScrub() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseScrub() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+const messageEvent = `{
+	"type": "event_callback",
+	"event_id": "Ev123",
+	"event_time": 1760605200,
+	"authorizations": [{"user_id": "U0BOT", "is_bot": true}],
+	"event": {
+		"type": "message",
+		"channel": "C123",
+		"user": "U012AB3CD",
+		"text": "hey <@U0ABC|gopher>, have a look",
+		"blocks": [{"type": "rich_text"}],
+		"user_profile": {"real_name": "Gopher"},
+		"ts": "1760605200.000100"
+	}
+}`
+
+const teamJoinEvent = `{
+	"type": "event_callback",
+	"event_id": "Ev124",
+	"event": {
+		"type": "team_join",
+		"user": {
+			"id": "W012AB3CD",
+			"name": "gopher",
+			"real_name": "Gopher",
+			"is_bot": false,
+			"profile": {"email": "gopher@example.com"}
+		}
+	}
+}`
+
+func TestScrub_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		scrub    Scrub
+		document string
+		want     string
+	}{
+		{
+			name:     "none",
+			document: "{\n  \"event\": {\"text\": \"hi\", \"user\": \"U1AB\"}\n}",
+			want:     `{"event":{"text":"hi","user":"U1AB"}}`,
+		},
+		{
+			name:     "text",
+			scrub:    Scrub{Text: true},
+			document: messageEvent,
+			want: `{"authorizations":[{"is_bot":true,"user_id":"U0BOT"}],"event":{"channel":"C123","type":"message",` +
+				`"ts":"1760605200.000100","user":"U012AB3CD","user_profile":{"real_name":"Gopher"}},` +
+				`"event_id":"Ev123","event_time":1760605200,"type":"event_callback"}`,
+		},
+		{
+			name:     "users",
+			scrub:    Scrub{Users: true},
+			document: messageEvent,
+			want: `{"authorizations":[{"is_bot":true,"user_id":"` + Pseudonym("U0BOT") + `"}],"event":{"blocks":[{"type":"rich_text"}],` +
+				`"channel":"C123","text":"hey <@` + Pseudonym("U0ABC") + `>, have a look","ts":"1760605200.000100",` +
+				`"type":"message","user":"` + Pseudonym("U012AB3CD") + `"},` +
+				`"event_id":"Ev123","event_time":1760605200,"type":"event_callback"}`,
+		},
+		{
+			name:     "users_object",
+			scrub:    Scrub{Text: true, Users: true},
+			document: teamJoinEvent,
+			want: `{"event":{"type":"team_join","user":{"id":"` + Pseudonym("W012AB3CD") + `","is_bot":false}},` +
+				`"event_id":"Ev124","type":"event_callback"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.scrub.Apply([]byte(tt.document))
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+
+			var gotV, wantV interface{}
+
+			if err := json.Unmarshal(got, &gotV); err != nil {
+				t.Fatalf("Apply() = %s, which isn't JSON: %v", got, err)
+			}
+
+			if err := json.Unmarshal([]byte(tt.want), &wantV); err != nil {
+				t.Fatalf("bad test case: %v", err)
+			}
+
+			if diff := cmp.Diff(wantV, gotV); diff != "" {
+				t.Errorf("Apply() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPseudonym(t *testing.T) {
+	p := Pseudonym("U012AB3CD")
+
+	if p != Pseudonym("U012AB3CD") {
+		t.Error("Pseudonym() isn't stable")
+	}
+
+	if len(p) != 13 || p[0] != 'X' {
+		t.Errorf("Pseudonym() = %q, want X and 12 hex digits", p)
+	}
+}
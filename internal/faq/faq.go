@@ -0,0 +1,360 @@
+// Package faq recognizes the questions asked so often in the Gophers Slack that
+// they have a canonical answer, like why assigning to a map panics, so the bot
+// can link to it. Questions are matched by the cosine similarity of their words
+// to the ways each one is usually asked, tolerating typos.
+package faq
+
+import (
+	"html"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/gobridge/gopherbot/internal/fuzzy"
+)
+
+// MinScore is the similarity a message needs to one of an entry's phrasings
+// to be matched. It's high, as answering a question that wasn't asked is
+// worse than not answering.
+const MinScore = 0.6
+
+// maxWords is the most words a message can have to be matched. Longer ones
+// usually have the context that makes them more than the common question.
+const maxWords = 40
+
+// minFuzzyWordLen is the shortest word matched to a known one with a typo,
+// and minFuzzySimilarity how similar they have to be.
+const (
+	minFuzzyWordLen    = 5
+	minFuzzySimilarity = 0.9
+)
+
+// Entry is a frequently asked question, and where it's answered.
+type Entry struct {
+	// ID is a short name for the entry, like nil-map.
+	ID string
+
+	// Question is the canonical phrasing of the question.
+	Question string
+
+	// URL is the canonical answer.
+	URL string
+
+	// Phrasings are the ways the question is usually asked, which messages
+	// are compared to.
+	Phrasings []string
+}
+
+// Builtin are the entries that ship with the bot.
+var Builtin = []Entry{
+	{
+		ID:       "nil-map",
+		Question: "Why does assigning to my map panic?",
+		URL:      "https://go.dev/blog/maps#working-with-maps",
+		Phrasings: []string{
+			"assignment to entry in nil map",
+			"why does writing to my map panic",
+			"my map panics when I assign to it",
+			"panic when adding a key to a map in a struct",
+			"how do I initialize a map before assigning to it",
+		},
+	},
+	{
+		ID:       "append-aliasing",
+		Question: "Why does appending to one slice change another?",
+		URL:      "https://go.dev/blog/slices-intro",
+		Phrasings: []string{
+			"append to a slice changes the original slice",
+			"why did append modify my other slice",
+			"two slices share the same underlying array after append",
+			"append overwrites elements of another slice",
+		},
+	},
+	{
+		ID:       "gopath",
+		Question: "Do I still need GOPATH?",
+		URL:      "https://go.dev/doc/code",
+		Phrasings: []string{
+			"do I need to set GOPATH",
+			"does my project have to be in GOPATH",
+			"how do I set up GOPATH",
+			"go modules or GOPATH",
+		},
+	},
+	{
+		ID:       "nil-error",
+		Question: "Why is my nil error value not equal to nil?",
+		URL:      "https://go.dev/doc/faq#nil_error",
+		Phrasings: []string{
+			"why is my nil error not equal to nil",
+			"err != nil is true but the error is nil",
+			"returning a nil pointer as an error interface is not nil",
+		},
+	},
+	{
+		ID:       "slice-of-interface",
+		Question: "Why can't I convert []T to []interface{}?",
+		URL:      "https://go.dev/doc/faq#convert_slice_of_interface",
+		Phrasings: []string{
+			"cannot use slice of string as slice of interface",
+			"how do I convert a slice to a slice of interface",
+			"pass []string to a function taking []interface{}",
+			"convert []string to []interface{}",
+		},
+	},
+	{
+		ID:       "pointer-receiver",
+		Question: "Should I define methods on values or pointers?",
+		URL:      "https://go.dev/doc/faq#methods_on_values_or_pointers",
+		Phrasings: []string{
+			"should I use a pointer receiver or a value receiver",
+			"when to use pointer receivers for methods",
+			"difference between value and pointer receivers",
+		},
+	},
+	{
+		ID:       "pass-by-reference",
+		Question: "Does Go pass by value or by reference?",
+		URL:      "https://go.dev/doc/faq#pass_by_value",
+		Phrasings: []string{
+			"does go pass by value or by reference",
+			"are slices and maps passed by reference",
+			"is go pass by reference",
+		},
+	},
+	{
+		ID:       "binary-size",
+		Question: "Why is my trivial program such a large binary?",
+		URL:      "https://go.dev/doc/faq#Why_is_my_trivial_program_such_a_large_binary",
+		Phrasings: []string{
+			"why is my hello world binary so large",
+			"how do I make my go binary smaller",
+			"why are go executables so big",
+			"why is my binary so big",
+		},
+	},
+	{
+		ID:       "exceptions",
+		Question: "Why doesn't Go have exceptions?",
+		URL:      "https://go.dev/doc/faq#exceptions",
+		Phrasings: []string{
+			"why does go not have exceptions",
+			"is there try catch in go",
+			"how do I catch exceptions in go",
+		},
+	},
+}
+
+// stopWords are left out when comparing, as they're in most messages.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "can": true, "do": true, "does": true,
+	"for": true, "from": true, "go": true, "golang": true, "has": true,
+	"have": true, "hi": true, "how": true, "i": true, "if": true, "in": true,
+	"is": true, "it": true, "its": true, "me": true, "my": true, "of": true,
+	"on": true, "or": true, "so": true, "some": true, "that": true,
+	"the": true, "this": true, "to": true, "what": true, "when": true,
+	"why": true, "with": true, "you": true,
+}
+
+// words returns the lower-cased words of the text, without stop words, and
+// with a plural s trimmed so "maps" and "map" are the same word.
+func words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	ws := fields[:0]
+
+	for _, w := range fields {
+		if stopWords[w] {
+			continue
+		}
+
+		if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+			w = w[:len(w)-1]
+		}
+
+		ws = append(ws, w)
+	}
+
+	return ws
+}
+
+// questionWords start messages that are questions, even without a question
+// mark.
+var questionWords = []string{"how", "why", "what", "is", "are", "can", "do", "does", "should", "anyone", "any"}
+
+// isQuestion returns whether the text looks like a question.
+func isQuestion(text string) bool {
+	if strings.Contains(text, "?") {
+		return true
+	}
+
+	first := strings.ToLower(strings.SplitN(strings.TrimSpace(text), " ", 2)[0])
+
+	for _, w := range questionWords {
+		if first == w {
+			return true
+		}
+	}
+
+	return false
+}
+
+type vector map[string]float64
+
+func (v vector) norm() float64 {
+	var sum float64
+
+	for _, x := range v {
+		sum += x * x
+	}
+
+	return math.Sqrt(sum)
+}
+
+// cosine returns the cosine similarity of the vectors.
+func cosine(a, b vector) float64 {
+	na, nb := a.norm(), b.norm()
+	if na == 0 || nb == 0 {
+		return 0
+	}
+
+	var dot float64
+
+	for w, x := range a {
+		dot += x * b[w]
+	}
+
+	return dot / (na * nb)
+}
+
+// Index matches messages to the entries.
+type Index struct {
+	entries []Entry
+
+	// idf is the inverse document frequency of each word in the
+	// phrasings, with each entry a document, so words common to many
+	// questions count for less.
+	idf map[string]float64
+
+	// unknownIDF is the weight of words no phrasing has, which count
+	// against the similarity like the rarest known word.
+	unknownIDF float64
+
+	// phrasings are the vectors of each entry's phrasings.
+	phrasings [][]vector
+}
+
+// NewIndex returns an *Index of the entries.
+func NewIndex(entries []Entry) *Index {
+	df := make(map[string]int)
+
+	for _, e := range entries {
+		seen := make(map[string]bool)
+
+		for _, p := range e.Phrasings {
+			for _, w := range words(p) {
+				if !seen[w] {
+					seen[w] = true
+					df[w]++
+				}
+			}
+		}
+	}
+
+	n := float64(len(entries))
+
+	ix := &Index{
+		entries:    entries,
+		idf:        make(map[string]float64, len(df)),
+		unknownIDF: math.Log(1 + n),
+		phrasings:  make([][]vector, len(entries)),
+	}
+
+	for w, d := range df {
+		ix.idf[w] = math.Log(1 + n/float64(d))
+	}
+
+	for i, e := range entries {
+		for _, p := range e.Phrasings {
+			ix.phrasings[i] = append(ix.phrasings[i], ix.vector(words(p)))
+		}
+	}
+
+	return ix
+}
+
+// known returns the word the index knows that w is, allowing for typos in
+// longer words, or false if there isn't one.
+func (ix *Index) known(w string) (string, bool) {
+	if _, ok := ix.idf[w]; ok {
+		return w, true
+	}
+
+	if len(w) < minFuzzyWordLen {
+		return "", false
+	}
+
+	best, bestScore := "", minFuzzySimilarity
+
+	for k := range ix.idf {
+		if s := fuzzy.JaroWinkler(w, k); s >= bestScore && (s > bestScore || k < best) {
+			best, bestScore = k, s
+		}
+	}
+
+	return best, len(best) > 0
+}
+
+// vector returns the tf-idf vector of the words.
+func (ix *Index) vector(ws []string) vector {
+	v := make(vector, len(ws))
+
+	for _, w := range ws {
+		if k, ok := ix.known(w); ok {
+			v[k] += ix.idf[k]
+			continue
+		}
+
+		// prefixed so it can't collide with a known word
+		v["?"+w] += ix.unknownIDF
+	}
+
+	return v
+}
+
+// Match returns the entry the text asks, and how similar it is to the closest
+// phrasing, if it looks like a question and is at least MinScore similar.
+// The text is unescaped first, as Slack escapes some characters.
+func (ix *Index) Match(text string) (e Entry, score float64, ok bool) {
+	text = html.UnescapeString(text)
+
+	if !isQuestion(text) {
+		return Entry{}, 0, false
+	}
+
+	ws := words(text)
+	if len(ws) == 0 || len(ws) > maxWords {
+		return Entry{}, 0, false
+	}
+
+	v := ix.vector(ws)
+
+	best := -1
+
+	for i, ps := range ix.phrasings {
+		for _, p := range ps {
+			if s := cosine(v, p); s > score {
+				best, score = i, s
+			}
+		}
+	}
+
+	if best < 0 || score < MinScore {
+		return Entry{}, score, false
+	}
+
+	return ix.entries[best], score, true
+}
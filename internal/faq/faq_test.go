@@ -0,0 +1,80 @@
+package faq
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIndex_Match(t *testing.T) {
+	ix := NewIndex(Builtin)
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "nil_map",
+			text: "why do I get assignment to entry in nil map?",
+			want: "nil-map",
+		},
+		{
+			name: "rephrased",
+			text: "hey, why does my map panic when I assign to it?",
+			want: "nil-map",
+		},
+		{
+			name: "append",
+			text: "why did append change my original slice?",
+			want: "append-aliasing",
+		},
+		{
+			name: "gopath_no_question_mark",
+			text: "do I need to set GOPATH when using modules",
+			want: "gopath",
+		},
+		{
+			name: "typo",
+			text: "is go pass by refrence?",
+			want: "pass-by-reference",
+		},
+		{
+			name: "escaped",
+			text: "how do I convert []string to []interface{}?",
+			want: "slice-of-interface",
+		},
+		{
+			name: "not_a_question",
+			text: "I assigned to a nil map and it panicked, fixed it with make",
+		},
+		{
+			name: "other_question",
+			text: "how do I read a file line by line?",
+		},
+		{
+			name: "too_specific",
+			text: "does anyone know how to use GOPATH with docker for my private repo and CI setup?",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			e, score, ok := ix.Match(tt.text)
+
+			if ok != (len(tt.want) > 0) || e.ID != tt.want {
+				t.Fatalf("Match() = %q (%.2f), %t, want %q", e.ID, score, ok, tt.want)
+			}
+		})
+	}
+}
+
+func Test_words(t *testing.T) {
+	got := words("Why do my Maps panic? (assignment to entry in nil map)")
+	want := []string{"map", "panic", "assignment", "entry", "nil", "map"}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("words() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,339 @@
+package faq
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	redisChannelsKey     = "faq:channels"     // set of the channels it's enabled in
+	redisAnswerKeyFormat = "faq:answer:%s:%s" // channel, answer ts; the entry ID answered
+	redisVotersKeyFormat = "faq:voters:%s:%s" // channel, answer ts; set of users who gave feedback
+	redisStatsKeyFormat  = "faq:stats:%s"     // entry ID; hash of answered, helpful, and wrong counts
+	redisStatsIndexKey   = "faq:stats"        // set of the entry IDs with stats
+	redisTestKey         = "faq:test_key"
+
+	// answerTTL is how long feedback is taken on an answer.
+	answerTTL = 7 * 24 * time.Hour
+)
+
+// refreshInterval is how long the channels are cached for, so other
+// processes pick up changes within this long.
+const refreshInterval = 15 * time.Second
+
+// refreshTimeout is how long refreshing them can take.
+const refreshTimeout = 500 * time.Millisecond
+
+// Verdict is someone's feedback on an answer.
+type Verdict string
+
+const (
+	// Helpful is for an answer to the question asked.
+	Helpful Verdict = "helpful"
+
+	// Wrong is for a false positive, answering a question that wasn't
+	// asked.
+	Wrong Verdict = "wrong"
+)
+
+const (
+	// HelpfulReaction is the emoji added to an answer to mark it Helpful.
+	HelpfulReaction = "+1"
+
+	// WrongReaction is the emoji added to an answer to mark it Wrong.
+	WrongReaction = "-1"
+)
+
+// Stat is how an entry's answers have been received.
+type Stat struct {
+	ID       string
+	Answered int64
+	Helpful  int64
+	Wrong    int64
+}
+
+// Store stores the channels questions are answered in, which answers were
+// given, and the feedback on them. The channels are cached, so matching
+// messages doesn't usually hit Redis.
+type Store struct {
+	r      *redis.Client
+	logger zerolog.Logger
+
+	mu       sync.Mutex
+	channels map[string]struct{}
+	fetched  time.Time
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client, logger zerolog.Logger) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc, logger: logger}, nil
+}
+
+// Enabled returns whether questions are answered in the channel. If the
+// channels can't be refreshed, the last ones fetched are used.
+func (s *Store) Enabled(channelID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.fetched) > refreshInterval {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+
+		if err := s.refresh(ctx); err != nil {
+			s.logger.Error().
+				Err(err).
+				Msg("failed to refresh FAQ channels")
+		}
+	}
+
+	_, ok := s.channels[channelID]
+
+	return ok
+}
+
+// refresh reloads the channels. It must be called with s.mu held.
+func (s *Store) refresh(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	channels, err := s.r.SMembers(ctx, redisChannelsKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to SMEMBERS redis key: %w", err)
+	}
+
+	s.channels = make(map[string]struct{}, len(channels))
+
+	for _, cid := range channels {
+		s.channels[cid] = struct{}{}
+	}
+
+	s.fetched = time.Now()
+
+	return nil
+}
+
+// SetEnabled sets whether questions are answered in the channel.
+func (s *Store) SetEnabled(ctx context.Context, channelID string, enabled bool) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	var err error
+
+	if enabled {
+		err = s.r.SAdd(ctx, redisChannelsKey, channelID).Err()
+	} else {
+		err = s.r.SRem(ctx, redisChannelsKey, channelID).Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update FAQ channel %s: %w", channelID, err)
+	}
+
+	s.mu.Lock()
+	s.fetched = time.Time{}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RecordAnswer records that the message ts in the channel answered with the
+// entry, so feedback on it can be recorded for 7 days.
+func (s *Store) RecordAnswer(ctx context.Context, channelID, ts, entryID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	_, err := s.r.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, fmt.Sprintf(redisAnswerKeyFormat, channelID, ts), entryID, answerTTL)
+		p.HIncrBy(ctx, fmt.Sprintf(redisStatsKeyFormat, entryID), "answered", 1)
+		p.SAdd(ctx, redisStatsIndexKey, entryID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record answer %s: %w", ts, err)
+	}
+
+	return nil
+}
+
+// Feedback records the user's verdict on the answer ts in the channel,
+// returning the entry it answered with. found is false if it isn't an answer,
+// or is too old. Each user's first verdict on an answer is the one counted.
+func (s *Store) Feedback(ctx context.Context, channelID, ts, userID string, v Verdict) (entryID string, found bool, err error) {
+	select {
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	default:
+		// noop
+	}
+
+	entryID, err = s.r.Get(ctx, fmt.Sprintf(redisAnswerKeyFormat, channelID, ts)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", false, nil
+		}
+
+		return "", false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	votersKey := fmt.Sprintf(redisVotersKeyFormat, channelID, ts)
+
+	added, err := s.r.SAdd(ctx, votersKey, userID).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to SADD redis key: %w", err)
+	}
+
+	if added == 0 {
+		return entryID, true, nil
+	}
+
+	_, err = s.r.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Expire(ctx, votersKey, answerTTL)
+		p.HIncrBy(ctx, fmt.Sprintf(redisStatsKeyFormat, entryID), string(v), 1)
+		return nil
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to record feedback on answer %s: %w", ts, err)
+	}
+
+	return entryID, true, nil
+}
+
+// PurgeUser removes the user from the sets of who gave feedback on each
+// answer, returning how many they were in. If dryRun is true, they're counted
+// but not removed. The counts their feedback added to aren't changed. It
+// satisfies purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	var cursor uint64
+	var found []string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+			// noop
+		}
+
+		keys, next, err := s.r.Scan(ctx, cursor, fmt.Sprintf(redisVotersKeyFormat, "*", "*"), 100).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to SCAN redis keys: %w", err)
+		}
+
+		if len(keys) > 0 {
+			cmds := make([]*redis.BoolCmd, len(keys))
+
+			_, err = s.r.Pipelined(ctx, func(p redis.Pipeliner) error {
+				for i, key := range keys {
+					cmds[i] = p.SIsMember(ctx, key, userID)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return 0, fmt.Errorf("failed to SISMEMBER redis keys: %w", err)
+			}
+
+			for i, key := range keys {
+				if cmds[i].Val() {
+					found = append(found, key)
+				}
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	if len(found) == 0 || dryRun {
+		return len(found), nil
+	}
+
+	_, err := s.r.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		for _, key := range found {
+			p.SRem(ctx, key, userID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge feedback of %s: %w", userID, err)
+	}
+
+	return len(found), nil
+}
+
+// Stats returns how each entry's answers have been received, ordered by ID.
+func (s *Store) Stats(ctx context.Context) ([]Stat, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	ids, err := s.r.SMembers(ctx, redisStatsIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to SMEMBERS redis key: %w", err)
+	}
+
+	sort.Strings(ids)
+
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+
+	_, err = s.r.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = p.HGetAll(ctx, fmt.Sprintf(redisStatsKeyFormat, id))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to HGETALL redis keys: %w", err)
+	}
+
+	stats := make([]Stat, len(ids))
+
+	for i, id := range ids {
+		m := cmds[i].Val()
+
+		stats[i] = Stat{
+			ID:       id,
+			Answered: parseCount(m["answered"]),
+			Helpful:  parseCount(m[string(Helpful)]),
+			Wrong:    parseCount(m[string(Wrong)]),
+		}
+	}
+
+	return stats, nil
+}
+
+func parseCount(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
@@ -0,0 +1,258 @@
+// Package flags stores runtime overrides of shadow mode for individual
+// features, so they can be switched between acting and only logging what they
+// would do, without a redeploy.
+package flags
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	redisKey     = "flags:shadow"
+	redisTestKey = "flags:test_key"
+)
+
+// The features that can be toggled.
+const (
+	// Responses are the message responses and reactions.
+	Responses = "responses"
+
+	// Playground is the playground uploader for long code messages.
+	Playground = "playground"
+
+	// Welcomes are the messages to users joining the workspace or a channel.
+	Welcomes = "welcomes"
+
+	// Pollers are the announcements from the bgtasks pollers.
+	Pollers = "pollers"
+)
+
+// Features is the list of features that can be toggled.
+var Features = []string{Responses, Playground, Welcomes, Pollers}
+
+// Valid returns whether the feature is one that can be toggled.
+func Valid(feature string) bool {
+	for _, f := range Features {
+		if f == feature {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refreshInterval is how long the overrides are cached for, so other
+// processes pick up changes within this long.
+const refreshInterval = 15 * time.Second
+
+// refreshTimeout is how long refreshing the overrides can take.
+const refreshTimeout = 500 * time.Millisecond
+
+// Store is the Redis-backed feature flag store. The overrides are cached, so
+// checking a flag doesn't usually hit Redis.
+type Store struct {
+	r             *redis.Client
+	defaultShadow bool
+	logger        zerolog.Logger
+
+	mu        sync.Mutex
+	overrides map[string]bool
+	fetched   time.Time
+}
+
+// NewStore returns a new *Store. Features without an override are in shadow
+// mode if defaultShadow is true, which generally comes from the environment.
+func NewStore(rc *redis.Client, defaultShadow bool, logger zerolog.Logger) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{
+		r:             rc,
+		defaultShadow: defaultShadow,
+		logger:        logger,
+	}, nil
+}
+
+// DefaultShadow returns whether features are in shadow mode by default.
+func (s *Store) DefaultShadow() bool { return s.defaultShadow }
+
+// Shadow returns whether the feature is in shadow mode. If the overrides
+// can't be refreshed, the last ones fetched are used.
+func (s *Store) Shadow(feature string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.fetched) > refreshInterval {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+
+		overrides, err := s.Overrides(ctx)
+
+		cancel()
+
+		if err != nil {
+			s.logger.Error().
+				Err(err).
+				Msg("failed to refresh feature flag overrides")
+		} else {
+			s.overrides = overrides
+			s.fetched = time.Now()
+		}
+	}
+
+	return s.resolve(s.overrides, feature)
+}
+
+// Overrides returns the features whose shadow mode was overridden, mapped to
+// whether they're in shadow mode.
+func (s *Store) Overrides(ctx context.Context) (map[string]bool, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.HGetAll(ctx, redisKey)
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to HGETALL redis key: %w", err)
+	}
+
+	overrides := make(map[string]bool, len(res.Val()))
+
+	for f, v := range res.Val() {
+		shadow, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse override for %s: %w", f, err)
+		}
+
+		overrides[f] = shadow
+	}
+
+	return overrides, nil
+}
+
+// Set overrides whether the feature is in shadow mode.
+func (s *Store) Set(ctx context.Context, feature string, shadow bool) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.HSet(ctx, redisKey, feature, strconv.FormatBool(shadow)).Err(); err != nil {
+		return fmt.Errorf("failed to HSET redis key: %w", err)
+	}
+
+	s.invalidate()
+
+	return nil
+}
+
+// Clear removes the feature's override, returning it to the default.
+func (s *Store) Clear(ctx context.Context, feature string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.HDel(ctx, redisKey, feature).Err(); err != nil {
+		return fmt.Errorf("failed to HDEL redis key: %w", err)
+	}
+
+	s.invalidate()
+
+	return nil
+}
+
+// Refresh fetches the overrides now, instead of waiting for the cached ones to
+// expire, and describes each feature whose state changed as a result.
+func (s *Store) Refresh(ctx context.Context) ([]string, error) {
+	overrides, err := s.Overrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	old := s.overrides
+	s.overrides = overrides
+	s.fetched = time.Now()
+	s.mu.Unlock()
+
+	var changes []string
+
+	for _, f := range Features {
+		before, after := s.resolve(old, f), s.resolve(overrides, f)
+
+		if before != after {
+			changes = append(changes, fmt.Sprintf("`%s` is now %s, was %s", f, stateName(after), stateName(before)))
+		}
+	}
+
+	return changes, nil
+}
+
+// resolve returns whether the feature is in shadow mode given the overrides.
+func (s *Store) resolve(overrides map[string]bool, feature string) bool {
+	if shadow, ok := overrides[feature]; ok {
+		return shadow
+	}
+
+	return s.defaultShadow
+}
+
+func stateName(shadow bool) string {
+	if shadow {
+		return "shadow"
+	}
+
+	return "live"
+}
+
+// invalidate makes the next Shadow call refresh the overrides.
+func (s *Store) invalidate() {
+	s.mu.Lock()
+	s.fetched = time.Time{}
+	s.mu.Unlock()
+}
+
+// State is a feature's current shadow mode.
+type State struct {
+	Feature    string
+	Shadow     bool
+	Overridden bool
+}
+
+// States returns the current state of each of the Features, sorted by name.
+func (s *Store) States(ctx context.Context) ([]State, error) {
+	overrides, err := s.Overrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]State, 0, len(Features))
+
+	for _, f := range Features {
+		_, ok := overrides[f]
+		states = append(states, State{Feature: f, Shadow: s.resolve(overrides, f), Overridden: ok})
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Feature < states[j].Feature
+	})
+
+	return states, nil
+}
@@ -0,0 +1,228 @@
+// Package fuzzy finds the known terms closest to a misspelled one, for
+// suggesting what someone meant when they use a command that doesn't exist.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+)
+
+// Levenshtein returns the number of single-rune insertions, deletions, or
+// substitutions needed to turn a into b.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	if len(ra) == 0 {
+		return len(rb)
+	}
+
+	// only the previous row of the matrix is needed for the next
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func min(n int, ns ...int) int {
+	for _, v := range ns {
+		if v < n {
+			n = v
+		}
+	}
+
+	return n
+}
+
+// JaroWinkler returns the Jaro-Winkler similarity of a and b, from 0 for
+// nothing in common to 1 for identical. It favors strings with a common prefix,
+// which suits typos as they tend to be later in the word.
+func JaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	// runes match if they're the same and not too far apart
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+
+	var matches int
+
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb)-1, i+window)
+
+		for j := lo; j <= hi; j++ {
+			if matchedB[j] || ra[i] != rb[j] {
+				continue
+			}
+
+			matchedA[i], matchedB[j] = true, true
+			matches++
+
+			break
+		}
+	}
+
+	if matches == 0 {
+		return 0
+	}
+
+	// count the matched runes that are out of order
+	var transpositions, j int
+
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+
+		for !matchedB[j] {
+			j++
+		}
+
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions/2))/m) / 3
+
+	var prefix int
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+const (
+	// minSimilarity is the Jaro-Winkler similarity above which a term is
+	// close enough to suggest.
+	minSimilarity = 0.9
+
+	// maxDistanceRatio is how much of a term can be wrong, by Levenshtein
+	// distance, for it to still be suggested.
+	maxDistanceRatio = 0.34
+)
+
+// Index holds the terms to suggest. It's safe for concurrent use.
+type Index struct {
+	terms []string
+}
+
+// NewIndex returns a new *Index of terms. Terms are compared without regard to
+// case, and duplicates are ignored.
+func NewIndex(terms ...string) *Index {
+	seen := make(map[string]struct{}, len(terms))
+
+	idx := &Index{terms: make([]string, 0, len(terms))}
+
+	for _, t := range terms {
+		t = strings.ToLower(strings.TrimSpace(t))
+
+		if _, ok := seen[t]; ok || len(t) == 0 {
+			continue
+		}
+
+		seen[t] = struct{}{}
+		idx.terms = append(idx.terms, t)
+	}
+
+	return idx
+}
+
+// Closest returns up to n terms close enough to s to be worth suggesting, the
+// closest first. A term is close enough if it has a high Jaro-Winkler
+// similarity to s, or only about a third of it would need to change.
+func (idx *Index) Closest(s string, n int) []string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) == 0 || n < 1 {
+		return nil
+	}
+
+	type scored struct {
+		term  string
+		score float64
+	}
+
+	var candidates []scored
+
+	for _, t := range idx.terms {
+		if t == s {
+			continue
+		}
+
+		score := JaroWinkler(s, t)
+
+		maxDist := int(float64(len([]rune(t))) * maxDistanceRatio)
+
+		if score < minSimilarity && Levenshtein(s, t) > maxDist {
+			continue
+		}
+
+		candidates = append(candidates, scored{term: t, score: score})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score == candidates[j].score {
+			return candidates[i].term < candidates[j].term
+		}
+
+		return candidates[i].score > candidates[j].score
+	})
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+
+	terms := make([]string, len(candidates))
+
+	for i, c := range candidates {
+		terms[i] = c.term
+	}
+
+	return terms
+}
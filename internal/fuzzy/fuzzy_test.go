@@ -0,0 +1,117 @@
+package fuzzy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"gopher", "gopher", 0},
+		{"hepl", "help", 2},
+		{"ünïcode", "unicode", 2},
+	}
+
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"abc", "", 0},
+		{"abc", "xyz", 0},
+		{"martha", "marhta", 0.961},
+		{"dwayne", "duane", 0.84},
+		{"dixon", "dicksonx", 0.813},
+	}
+
+	for _, tt := range tests {
+		if got := JaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("JaroWinkler(%q, %q) = %.3f, want %.3f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIndex_Closest(t *testing.T) {
+	idx := NewIndex("help", "commands", "Channels", "channels", "gotime", "code of conduct", "newbie resources", "poll")
+
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want []string
+	}{
+		{
+			name: "transposition",
+			s:    "hepl",
+			n:    3,
+			want: []string{"help"},
+		},
+		{
+			name: "case",
+			s:    "CHANELS",
+			n:    3,
+			want: []string{"channels"},
+		},
+		{
+			name: "phrase",
+			s:    "code of condcut",
+			n:    3,
+			want: []string{"code of conduct"},
+		},
+		{
+			name: "limit",
+			s:    "comands",
+			n:    1,
+			want: []string{"commands"},
+		},
+		{
+			name: "exact_is_not_suggested",
+			s:    "help",
+			n:    3,
+		},
+		{
+			name: "unrelated",
+			s:    "does anyone know why my goroutines leak?",
+			n:    3,
+		},
+		{
+			name: "short",
+			s:    "pol",
+			n:    3,
+			want: []string{"poll"},
+		},
+		{
+			name: "empty",
+			s:    " ",
+			n:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := idx.Closest(tt.s, tt.n)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Closest() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
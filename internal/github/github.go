@@ -0,0 +1,179 @@
+// Package github is a small client for the parts of the GitHub REST API the
+// bot uses, shared so that every component authenticates and identifies itself
+// the same way.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const apiURL = "https://api.github.com"
+
+// Issue is an issue (or pull request) on GitHub.
+type Issue struct {
+	Number    int64     `json:"number"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"`
+	URL       string    `json:"html_url"`
+	Labels    []string  `json:"labels"`
+	Milestone string    `json:"milestone"`
+	Comments  int       `json:"comments"`
+	IsPR      bool      `json:"is_pr"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// apiIssue is the shape of an issue in the API, which we flatten into Issue.
+type apiIssue struct {
+	Number    int64     `json:"number"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"`
+	URL       string    `json:"html_url"`
+	Comments  int       `json:"comments"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+func (ai apiIssue) issue() Issue {
+	i := Issue{
+		Number:    ai.Number,
+		Title:     ai.Title,
+		State:     ai.State,
+		URL:       ai.URL,
+		Comments:  ai.Comments,
+		IsPR:      ai.PullRequest != nil,
+		UpdatedAt: ai.UpdatedAt,
+	}
+
+	for _, l := range ai.Labels {
+		i.Labels = append(i.Labels, l.Name)
+	}
+
+	if ai.Milestone != nil {
+		i.Milestone = ai.Milestone.Title
+	}
+
+	return i
+}
+
+// NotFoundError is returned when the requested resource doesn't exist.
+type NotFoundError struct {
+	Path string
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found", e.Path)
+}
+
+// Client is a GitHub API client.
+type Client struct {
+	http  *http.Client
+	token string
+}
+
+// New returns a *Client. If token is empty requests are unauthenticated, and
+// subject to much lower rate limits.
+func New(c *http.Client, token string) *Client {
+	return &Client{
+		http:  c,
+		token: token,
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	u := apiURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("User-Agent", "Gophers Slack bot")
+	req.Header.Add("Accept", "application/vnd.github.v3+json")
+
+	if len(c.token) > 0 {
+		req.Header.Add("Authorization", "token "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get data from GitHub: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// noop
+	case http.StatusNotFound, http.StatusGone:
+		return NotFoundError{Path: path}
+	default:
+		return fmt.Errorf("got non-200 code: %d from GitHub api", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if err = json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON body: %w", err)
+	}
+
+	return nil
+}
+
+// Issue gets a single issue from the repository. If it doesn't exist, the
+// error is a NotFoundError.
+func (c *Client) Issue(ctx context.Context, owner, repo string, number int64) (Issue, error) {
+	var ai apiIssue
+
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d", owner, repo, number)
+
+	if err := c.get(ctx, path, nil, &ai); err != nil {
+		return Issue{}, err
+	}
+
+	return ai.issue(), nil
+}
+
+// SearchIssues searches issues, returning the most recently updated first.
+func (c *Client) SearchIssues(ctx context.Context, query string, perPage int) ([]Issue, error) {
+	var sr struct {
+		Items []apiIssue `json:"items"`
+	}
+
+	q := url.Values{
+		"q":        {query},
+		"sort":     {"updated"},
+		"order":    {"desc"},
+		"per_page": {strconv.Itoa(perPage)},
+	}
+
+	if err := c.get(ctx, "/search/issues", q, &sr); err != nil {
+		return nil, err
+	}
+
+	issues := make([]Issue, 0, len(sr.Items))
+
+	for _, ai := range sr.Items {
+		issues = append(issues, ai.issue())
+	}
+
+	return issues, nil
+}
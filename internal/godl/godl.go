@@ -0,0 +1,181 @@
+// Package godl gets the supported Go releases from go.dev, with the dates they
+// were released, caching them so that everything asking about releases, like
+// the version command, shares the one source.
+package godl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const (
+	defaultDLURL      = "https://go.dev/dl/?mode=json"
+	defaultHistoryURL = "https://go.dev/doc/devel/release"
+)
+
+// Release is a Go release.
+type Release struct {
+	// Version is the release's version, like go1.22.1.
+	Version string `json:"version"`
+
+	// Stable is false for betas and release candidates.
+	Stable bool `json:"stable"`
+
+	// Date is when it was released, or the zero time if it's not known.
+	Date time.Time `json:"date"`
+}
+
+// Client gets the releases from go.dev, caching them in the Store.
+type Client struct {
+	httpc      *http.Client
+	cache      *Store
+	dlURL      string
+	historyURL string
+}
+
+// New returns a *Client.
+func New(httpc *http.Client, cache *Store) *Client {
+	return &Client{
+		httpc:      httpc,
+		cache:      cache,
+		dlURL:      defaultDLURL,
+		historyURL: defaultHistoryURL,
+	}
+}
+
+// Releases returns the releases listed for download, which are the latest of
+// each supported major version, and any prerelease of the next one, newest
+// first.
+func (c *Client) Releases(ctx context.Context) ([]Release, error) {
+	rs, found, err := c.cache.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		return rs, nil
+	}
+
+	rs, err = c.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.cache.Set(ctx, rs); err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+// Supported returns the current stable release, and the one before it, which
+// are the two that get security fixes. Either is empty if there isn't one.
+func Supported(rs []Release) (current, previous Release) {
+	var stable []Release
+
+	for _, r := range rs {
+		if r.Stable {
+			stable = append(stable, r)
+		}
+	}
+
+	if len(stable) > 0 {
+		current = stable[0]
+	}
+
+	if len(stable) > 1 {
+		previous = stable[1]
+	}
+
+	return current, previous
+}
+
+// fetch gets the releases, and their dates from the release history. The dates
+// are a nicety, so if the history can't be fetched they're left unset.
+func (c *Client) fetch(ctx context.Context) ([]Release, error) {
+	body, err := c.get(ctx, c.dlURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var rs []Release
+
+	if err = json.Unmarshal(body, &rs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON body: %w", err)
+	}
+
+	history, err := c.get(ctx, c.historyURL)
+	if err != nil {
+		return rs, nil
+	}
+
+	dates := releaseDates(history)
+
+	for i := range rs {
+		rs[i].Date = dates[rs[i].Version]
+	}
+
+	return rs, nil
+}
+
+func (c *Client) get(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", "Gophers Slack bot")
+
+	resp, err := c.httpc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", u, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got non-200 code: %d from %s", resp.StatusCode, u)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	return body, nil
+}
+
+// releaseRegexp matches the releases in the release history, which are like
+// "Go 1.22.0 (released 2024-02-06)" for major versions and "go1.22.1 (released
+// 2024-03-05)" for minor ones, wrapped across lines.
+var releaseRegexp = regexp.MustCompile(`(?i)\bgo ?(1(?:\.\d+){1,2})\s*\(released\s+(\d{4}-\d{2}-\d{2})\)`)
+
+// releaseDates returns when each release in the release history was released,
+// by version, like go1.22.1. The major versions are listed as 1.22.0 in the
+// history, but are go1.22 for download, so both are included.
+func releaseDates(history []byte) map[string]time.Time {
+	dates := make(map[string]time.Time)
+
+	for _, m := range releaseRegexp.FindAllSubmatch(history, -1) {
+		d, err := time.Parse("2006-01-02", string(m[2]))
+		if err != nil {
+			continue
+		}
+
+		v := "go" + string(m[1])
+		dates[v] = d
+
+		if major := majorVersionRegexp.FindStringSubmatch(v); major != nil {
+			dates[major[1]] = d
+		}
+	}
+
+	return dates
+}
+
+var majorVersionRegexp = regexp.MustCompile(`^(go1\.\d+)\.0$`)
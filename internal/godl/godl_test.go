@@ -0,0 +1,145 @@
+package godl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testDL = `[
+	{"version": "go1.23rc1", "stable": false, "files": []},
+	{"version": "go1.22.1", "stable": true, "files": [{"filename": "go1.22.1.src.tar.gz", "kind": "source"}]},
+	{"version": "go1.21.8", "stable": true, "files": []}
+]`
+
+const testHistory = `<h2 id="go1.22.0">Go 1.22.0 (released 2024-02-06)</h2>
+<p>
+go1.22.1
+(released 2024-03-05) includes security fixes to the <code>crypto/x509</code> package.
+</p>
+<h2 id="go1.21.0">Go 1.21.0 (released 2023-08-08)</h2>
+<p>
+go1.21.8 (released 2024-03-05) includes security fixes.
+</p>`
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func Test_releaseDates(t *testing.T) {
+	want := map[string]time.Time{
+		"go1.22.0": date("2024-02-06"),
+		"go1.22":   date("2024-02-06"),
+		"go1.22.1": date("2024-03-05"),
+		"go1.21.0": date("2023-08-08"),
+		"go1.21":   date("2023-08-08"),
+		"go1.21.8": date("2024-03-05"),
+	}
+
+	if diff := cmp.Diff(want, releaseDates([]byte(testHistory))); diff != "" {
+		t.Fatalf("releaseDates() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		name         string
+		releases     []Release
+		wantCurrent  string
+		wantPrevious string
+	}{
+		{
+			name: "prerelease",
+			releases: []Release{
+				{Version: "go1.23rc1"},
+				{Version: "go1.22.1", Stable: true},
+				{Version: "go1.21.8", Stable: true},
+			},
+			wantCurrent:  "go1.22.1",
+			wantPrevious: "go1.21.8",
+		},
+		{
+			name:        "one",
+			releases:    []Release{{Version: "go1.22.1", Stable: true}},
+			wantCurrent: "go1.22.1",
+		},
+		{
+			name: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			current, previous := Supported(tt.releases)
+
+			if current.Version != tt.wantCurrent || previous.Version != tt.wantPrevious {
+				t.Fatalf("Supported() = %q, %q, want %q, %q", current.Version, previous.Version, tt.wantCurrent, tt.wantPrevious)
+			}
+		})
+	}
+}
+
+func TestClient_fetch(t *testing.T) {
+	historyStatus := http.StatusOK
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/dl/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testDL))
+	})
+
+	mux.HandleFunc("/doc/devel/release", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(historyStatus)
+		_, _ = w.Write([]byte(testHistory))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := &Client{
+		httpc:      srv.Client(),
+		dlURL:      srv.URL + "/dl/?mode=json",
+		historyURL: srv.URL + "/doc/devel/release",
+	}
+
+	got, err := c.fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch() unexpected error: %v", err)
+	}
+
+	want := []Release{
+		{Version: "go1.23rc1"},
+		{Version: "go1.22.1", Stable: true, Date: date("2024-03-05")},
+		{Version: "go1.21.8", Stable: true, Date: date("2024-03-05")},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("fetch() mismatch (-want +got):\n%s", diff)
+	}
+
+	// without the history, there are no dates
+	historyStatus = http.StatusInternalServerError
+
+	got, err = c.fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch() unexpected error: %v", err)
+	}
+
+	for i := range want {
+		want[i].Date = time.Time{}
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("fetch() without history mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,83 @@
+package godl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKey     = "godl:releases"
+	redisTestKey = "godl:test_key"
+
+	// cacheTTL is how long the releases are cached. Releases are announced
+	// well ahead, so being this late to notice one is fine.
+	cacheTTL = time.Hour
+)
+
+// Store caches the releases in Redis.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Get returns the cached releases, if found.
+func (s *Store) Get(ctx context.Context) ([]Release, bool, error) {
+	select {
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, redisKey)
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	var rs []Release
+
+	if err := json.Unmarshal([]byte(res.Val()), &rs); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal releases: %w", err)
+	}
+
+	return rs, true, nil
+}
+
+// Set caches the releases.
+func (s *Store) Set(ctx context.Context, rs []Release) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	j, err := json.Marshal(rs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal releases: %w", err)
+	}
+
+	if err = s.r.Set(ctx, redisKey, string(j), cacheTTL).Err(); err != nil {
+		return fmt.Errorf("failed to cache releases: %w", err)
+	}
+
+	return nil
+}
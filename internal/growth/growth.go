@@ -0,0 +1,365 @@
+// Package growth counts members joining the workspace and its channels, and
+// which members post, per day, so the organizers can see how the community is
+// growing and whether new members stick around.
+package growth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisJoinsKeyFormat    = "growth:joins:%s"    // date
+	redisChannelsKeyFormat = "growth:channels:%s" // date
+	redisActiveKeyFormat   = "growth:active:%s"   // date
+	redisTestKey           = "growth:test_key"
+
+	dateFormat = "2006-01-02"
+)
+
+// Retention is how long the daily records are kept.
+const Retention = 100 * 24 * time.Hour
+
+// CohortAge is how long before a report's period the members in its cohort
+// joined. The cohort is those who joined in a period as long as the report's,
+// starting CohortAge before it.
+const CohortAge = 28 * 24 * time.Hour
+
+// Store is the Redis-backed store of the daily records.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+func dayKey(format string, t time.Time) string {
+	return fmt.Sprintf(format, t.UTC().Format(dateFormat))
+}
+
+// days returns the start of each day from from up to, but not including, to.
+func days(from, to time.Time) []time.Time {
+	var ds []time.Time
+
+	for d := from.UTC().Truncate(24 * time.Hour); d.Before(to); d = d.Add(24 * time.Hour) {
+		ds = append(ds, d)
+	}
+
+	return ds
+}
+
+// RecordJoin records that the user joined the workspace at t.
+func (s *Store) RecordJoin(ctx context.Context, userID string, t time.Time) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	key := dayKey(redisJoinsKeyFormat, t)
+
+	pipe := s.r.TxPipeline()
+	pipe.SAdd(ctx, key, userID)
+	pipe.Expire(ctx, key, Retention)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record join of %s: %w", userID, err)
+	}
+
+	return nil
+}
+
+// RecordChannelJoin counts a member joining the channel at t.
+func (s *Store) RecordChannelJoin(ctx context.Context, channelID string, t time.Time) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	key := dayKey(redisChannelsKeyFormat, t)
+
+	pipe := s.r.TxPipeline()
+	pipe.HIncrBy(ctx, key, channelID, 1)
+	pipe.Expire(ctx, key, Retention)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to count join of %s: %w", channelID, err)
+	}
+
+	return nil
+}
+
+// RecordActive records that the user posted a public message at t.
+func (s *Store) RecordActive(ctx context.Context, userID string, t time.Time) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	key := dayKey(redisActiveKeyFormat, t)
+
+	pipe := s.r.TxPipeline()
+	pipe.SAdd(ctx, key, userID)
+	pipe.Expire(ctx, key, Retention)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record activity of %s: %w", userID, err)
+	}
+
+	return nil
+}
+
+// PurgeUser removes the user from the daily records of who joined and who
+// posted, returning how many days they were in. If dryRun is true, they're
+// counted but not removed. It satisfies purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	now := time.Now()
+
+	var keys []string
+
+	for _, d := range days(now.Add(-Retention), now.Add(24*time.Hour)) {
+		keys = append(keys, dayKey(redisJoinsKeyFormat, d), dayKey(redisActiveKeyFormat, d))
+	}
+
+	pipe := s.r.Pipeline()
+
+	cmds := make([]*redis.BoolCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.SIsMember(ctx, key, userID)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to look up %s: %w", userID, err)
+	}
+
+	var found []string
+
+	for i, c := range cmds {
+		if c.Val() {
+			found = append(found, keys[i])
+		}
+	}
+
+	if len(found) == 0 || dryRun {
+		return len(found), nil
+	}
+
+	tx := s.r.TxPipeline()
+
+	for _, key := range found {
+		tx.SRem(ctx, key, userID)
+	}
+
+	if _, err := tx.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to purge %s: %w", userID, err)
+	}
+
+	return len(found), nil
+}
+
+// ChannelCount is how many members joined a channel.
+type ChannelCount struct {
+	ChannelID string
+	Joins     int64
+}
+
+// Report is how the workspace grew over a period.
+type Report struct {
+	From, To time.Time
+
+	// NewMembers is how many members joined the workspace.
+	NewMembers int
+
+	// NewPosted is how many of the new members posted a public message.
+	NewPosted int
+
+	// Active is how many members posted a public message.
+	Active int
+
+	// Channels is how many members joined each channel, the most joined
+	// first.
+	Channels []ChannelCount
+
+	// CohortJoined is how many members joined in the period CohortAge
+	// before this one, and CohortActive how many of them posted in this one.
+	// It's a rough measure of how many new members stick around.
+	CohortJoined, CohortActive int
+}
+
+// Report returns how the workspace grew on the days from from up to, but not
+// including, to.
+func (s *Store) Report(ctx context.Context, from, to time.Time) (Report, error) {
+	select {
+	case <-ctx.Done():
+		return Report{}, ctx.Err()
+	default:
+		// noop
+	}
+
+	pipe := s.r.Pipeline()
+
+	var joins, active, cohort []*redis.StringSliceCmd
+	var channels []*redis.MapStringStringCmd
+
+	for _, d := range days(from, to) {
+		joins = append(joins, pipe.SMembers(ctx, dayKey(redisJoinsKeyFormat, d)))
+		active = append(active, pipe.SMembers(ctx, dayKey(redisActiveKeyFormat, d)))
+		channels = append(channels, pipe.HGetAll(ctx, dayKey(redisChannelsKeyFormat, d)))
+	}
+
+	for _, d := range days(from.Add(-CohortAge), to.Add(-CohortAge)) {
+		cohort = append(cohort, pipe.SMembers(ctx, dayKey(redisJoinsKeyFormat, d)))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return Report{}, fmt.Errorf("failed to get growth records: %w", err)
+	}
+
+	vals := func(cmds []*redis.StringSliceCmd) [][]string {
+		v := make([][]string, len(cmds))
+		for i, c := range cmds {
+			v[i] = c.Val()
+		}
+
+		return v
+	}
+
+	cs := make([]map[string]string, len(channels))
+	for i, c := range channels {
+		cs[i] = c.Val()
+	}
+
+	r := summarize(vals(joins), vals(active), cs, vals(cohort))
+	r.From, r.To = from, to
+
+	return r, nil
+}
+
+func union(sets [][]string) map[string]struct{} {
+	u := make(map[string]struct{})
+
+	for _, s := range sets {
+		for _, v := range s {
+			u[v] = struct{}{}
+		}
+	}
+
+	return u
+}
+
+func intersection(a, b map[string]struct{}) int {
+	var n int
+
+	for v := range a {
+		if _, ok := b[v]; ok {
+			n++
+		}
+	}
+
+	return n
+}
+
+// summarize builds a Report from the daily records.
+func summarize(joins, active [][]string, channels []map[string]string, cohort [][]string) Report {
+	j, a, c := union(joins), union(active), union(cohort)
+
+	r := Report{
+		NewMembers:   len(j),
+		NewPosted:    intersection(j, a),
+		Active:       len(a),
+		CohortJoined: len(c),
+		CohortActive: intersection(c, a),
+	}
+
+	byChannel := make(map[string]int64)
+
+	for _, day := range channels {
+		for id, v := range day {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			byChannel[id] += n
+		}
+	}
+
+	for id, n := range byChannel {
+		r.Channels = append(r.Channels, ChannelCount{ChannelID: id, Joins: n})
+	}
+
+	sort.Slice(r.Channels, func(i, j int) bool {
+		if r.Channels[i].Joins == r.Channels[j].Joins {
+			return r.Channels[i].ChannelID < r.Channels[j].ChannelID
+		}
+
+		return r.Channels[i].Joins > r.Channels[j].Joins
+	})
+
+	return r
+}
+
+// reportTop is how many of the most joined channels are in a report.
+const reportTop = 10
+
+func percent(n, of int) string {
+	if of == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d%%", n*100/of)
+}
+
+// FormatReport formats the report as a Slack message.
+func FormatReport(title string, r Report) string {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "*%s*\n", title)
+	fmt.Fprintf(b, "New members: %d, of whom %d (%s) posted in a public channel\n", r.NewMembers, r.NewPosted, percent(r.NewPosted, r.NewMembers))
+	fmt.Fprintf(b, "Members posting in public channels: %d\n", r.Active)
+	fmt.Fprintf(b, "Of the %d members who joined four weeks earlier, %d (%s) posted in this period\n", r.CohortJoined, r.CohortActive, percent(r.CohortActive, r.CohortJoined))
+
+	if len(r.Channels) == 0 {
+		b.WriteString("\nNobody joined a channel.\n")
+		return b.String()
+	}
+
+	b.WriteString("\nMost joined channels:\n")
+
+	for i, c := range r.Channels {
+		if i == reportTop {
+			fmt.Fprintf(b, "…and %d more\n", len(r.Channels)-reportTop)
+			break
+		}
+
+		fmt.Fprintf(b, "- <#%s>: %d\n", c.ChannelID, c.Joins)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package growth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_days(t *testing.T) {
+	from := time.Date(2026, 9, 28, 9, 30, 0, 0, time.UTC)
+
+	want := []time.Time{
+		time.Date(2026, 9, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 9, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	if diff := cmp.Diff(want, days(from, from.Add(48*time.Hour))); diff != "" {
+		t.Fatalf("days() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_summarize(t *testing.T) {
+	joins := [][]string{{"U1", "U2"}, nil, {"U3", "U1"}}
+	active := [][]string{{"U1", "U9"}, {"U8", "U9", "U4"}, nil}
+	channels := []map[string]string{
+		{"C1": "3", "C2": "1", "C3": "x"},
+		nil,
+		{"C2": "2", "C4": "3"},
+	}
+	cohort := [][]string{{"U4", "U5"}, {"U6"}}
+
+	want := Report{
+		NewMembers: 3,
+		NewPosted:  1,
+		Active:     4,
+		Channels: []ChannelCount{
+			{ChannelID: "C1", Joins: 3},
+			{ChannelID: "C2", Joins: 3},
+			{ChannelID: "C4", Joins: 3},
+		},
+		CohortJoined: 3,
+		CohortActive: 1,
+	}
+
+	if diff := cmp.Diff(want, summarize(joins, active, channels, cohort)); diff != "" {
+		t.Fatalf("summarize() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormatReport(t *testing.T) {
+	r := Report{
+		NewMembers: 4,
+		NewPosted:  1,
+		Active:     30,
+		Channels: []ChannelCount{
+			{ChannelID: "C1", Joins: 5},
+			{ChannelID: "C2", Joins: 2},
+		},
+	}
+
+	got := FormatReport("Growth for the week of September 28", r)
+
+	want := "*Growth for the week of September 28*\n" +
+		"New members: 4, of whom 1 (25%) posted in a public channel\n" +
+		"Members posting in public channels: 30\n" +
+		"Of the 0 members who joined four weeks earlier, 0 (-) posted in this period\n" +
+		"\nMost joined channels:\n" +
+		"- <#C1>: 5\n" +
+		"- <#C2>: 2\n"
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("FormatReport() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -1,22 +1,27 @@
 // Package heartbeat provides a mechanism for heartbeating against Redis to
-// ensure it's still healthy.
+// ensure it's still healthy. Each beat also records which component is beating
+// and its commit, so other processes can see which of their peers are alive.
 package heartbeat
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/go-redis/redis"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 )
 
 const redisKeyFormat = "heartbeat:%s:%s"
 
 type redisClient interface {
-	Get(key string) *redis.StringCmd
-	Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
 }
 
 type Config struct {
@@ -24,6 +29,8 @@ type Config struct {
 	Logger      zerolog.Logger
 	AppName     string
 	UID         string
+	Component   string
+	Commit      string
 	Warn        time.Duration
 	Fail        time.Duration
 	ShutdownFn  func(zerolog.Logger)
@@ -48,13 +55,16 @@ type Heart struct {
 	warn       time.Duration
 	fail       time.Duration
 	key        string
+	component  string
+	commit     string
 	shutdownFn func(zerolog.Logger)
 }
 
 // New is a function with an argument list that's roughly the size of Texas. The
 // appName and UID are used as part of the key written to Redis. The warn and
 // fail durations control when we log a warning, and when we start to exit
-// (respectively). Finally, there's a shutdownFn if you want to clean up some
+// (respectively). The component and commit are recorded with each beat, for
+// Peers to report. Finally, there's a shutdownFn if you want to clean up some
 // things before the program exits. That function has 10 seconds to complete,
 // otherwise the program is forcibly exited.
 //
@@ -80,6 +90,8 @@ func New(ctx context.Context, cfg Config) (*Heart, error) {
 		warn:       cfg.Warn,
 		fail:       cfg.Fail,
 		key:        fmt.Sprintf(redisKeyFormat, cfg.AppName, cfg.UID),
+		component:  cfg.Component,
+		commit:     cfg.Commit,
 		shutdownFn: cfg.ShutdownFn,
 	}
 
@@ -93,6 +105,20 @@ func New(ctx context.Context, cfg Config) (*Heart, error) {
 	return h, nil
 }
 
+// LastBeat returns when Redis last confirmed a heartbeat.
+func (h *Heart) LastBeat() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.last
+}
+
+// Warn returns how old the last heartbeat can be before it's going stale.
+func (h *Heart) Warn() time.Duration { return h.warn }
+
+// Fail returns how old the last heartbeat can be before we shut down.
+func (h *Heart) Fail() time.Duration { return h.fail }
+
 func (h *Heart) monitor() {
 	t := time.NewTicker(time.Second)
 
@@ -187,26 +213,36 @@ func (h *Heart) pulse() {
 func (h *Heart) beat() error {
 	tn := time.Now().UnixNano() / int64(time.Millisecond)
 
-	status := h.r.Set(h.key, tn, h.fail+time.Minute)
+	v, err := json.Marshal(beat{
+		Component: h.component,
+		Commit:    h.commit,
+		At:        tn,
+		FailMS:    int64(h.fail / time.Millisecond),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal beat: %w", err)
+	}
+
+	status := h.r.Set(h.ctx, h.key, v, h.fail+time.Minute)
 	if err := status.Err(); err != nil {
 		return fmt.Errorf("failed to beat: %w", err)
 	}
 
-	res := h.r.Get(h.key)
+	res := h.r.Get(h.ctx, h.key)
 	if err := res.Err(); err != nil {
 		return fmt.Errorf("failed to read beat: %w", err)
 	}
 
-	ts, err := res.Int64()
+	b, err := parseBeat(res.Val())
 	if err != nil {
 		return fmt.Errorf("failed to read timestamp from redis: %w", err)
 	}
 
-	if ts != tn {
-		return fmt.Errorf("ts = %d, want %d", ts, tn)
+	if b.At != tn {
+		return fmt.Errorf("ts = %d, want %d", b.At, tn)
 	}
 
-	t := time.Unix(unix(ts))
+	t := time.Unix(unix(b.At))
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -216,6 +252,148 @@ func (h *Heart) beat() error {
 	return nil
 }
 
+// beat is the value written to Redis by each heartbeat.
+type beat struct {
+	Component string `json:"component"`
+	Commit    string `json:"commit"`
+
+	// At is when it was written, in Unix milliseconds
+	At int64 `json:"at"`
+
+	// FailMS is how old it can be, in milliseconds, before the process that
+	// wrote it gives up and exits
+	FailMS int64 `json:"fail_ms"`
+}
+
+// parseBeat parses a heartbeat written to Redis, including the bare
+// millisecond timestamps written before beats recorded anything else.
+func parseBeat(v string) (beat, error) {
+	if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return beat{At: ts}, nil
+	}
+
+	var b beat
+
+	if err := json.Unmarshal([]byte(v), &b); err != nil {
+		return beat{}, fmt.Errorf("failed to unmarshal beat: %w", err)
+	}
+
+	return b, nil
+}
+
+// DefaultFail is how old a peer's last beat can be before it's assumed to be
+// gone, if it didn't record its own limit.
+const DefaultFail = 8 * time.Second
+
+// Peer is a process heartbeating against the same Redis.
+type Peer struct {
+	// UID is the process's unique ID, its HEROKU_DYNO_ID.
+	UID string
+
+	// Component is the program, like "consumer", if it recorded one.
+	Component string
+
+	// Commit is the commit it was built from, if it recorded one.
+	Commit string
+
+	// LastBeat is when it last beat.
+	LastBeat time.Time
+
+	// Fail is how old its last beat can be before it gives up and exits.
+	Fail time.Duration
+}
+
+// Alive returns whether the peer has beat recently enough to still be running.
+func (p Peer) Alive(now time.Time) bool {
+	fail := p.Fail
+	if fail <= 0 {
+		fail = DefaultFail
+	}
+
+	return now.Sub(p.LastBeat) < fail
+}
+
+type peersClient interface {
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+	MGet(ctx context.Context, keys ...string) *redis.SliceCmd
+}
+
+// Peers returns the processes of the app that have beat recently, sorted by
+// component and UID. A process's beat is kept for a minute after it would have
+// given up, so those that stopped recently are included; use Peer.Alive to
+// tell them apart.
+func Peers(ctx context.Context, rc peersClient, appName string) ([]Peer, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	prefix := fmt.Sprintf(redisKeyFormat, appName, "")
+
+	var keys []string
+
+	seen := make(map[string]struct{})
+
+	// SCAN can return a key more than once
+	iter := rc.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		if _, ok := seen[iter.Val()]; ok {
+			continue
+		}
+
+		seen[iter.Val()] = struct{}{}
+		keys = append(keys, iter.Val())
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to SCAN redis keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	vals, err := rc.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to MGET redis keys: %w", err)
+	}
+
+	peers := make([]Peer, 0, len(keys))
+
+	for i, v := range vals {
+		// the key expired since it was scanned
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		b, err := parseBeat(s)
+		if err != nil {
+			continue
+		}
+
+		peers = append(peers, Peer{
+			UID:       strings.TrimPrefix(keys[i], prefix),
+			Component: b.Component,
+			Commit:    b.Commit,
+			LastBeat:  time.Unix(unix(b.At)),
+			Fail:      time.Duration(b.FailMS) * time.Millisecond,
+		})
+	}
+
+	sort.Slice(peers, func(a, b int) bool {
+		if peers[a].Component != peers[b].Component {
+			return peers[a].Component < peers[b].Component
+		}
+
+		return peers[a].UID < peers[b].UID
+	})
+
+	return peers, nil
+}
+
 func unix(i int64) (int64, int64) {
 	// convert milliseconds to whole seconds
 	// convert millisecond remainder from above conversion to nanoseconds
@@ -0,0 +1,67 @@
+package heartbeat
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseBeat(t *testing.T) {
+	tests := []struct {
+		name string
+		v    string
+		want beat
+		err  bool
+	}{
+		{
+			name: "json",
+			v:    `{"component":"consumer","commit":"abc","at":1588334400000,"fail_ms":8000}`,
+			want: beat{Component: "consumer", Commit: "abc", At: 1588334400000, FailMS: 8000},
+		},
+		{
+			name: "bare_timestamp",
+			v:    "1588334400000",
+			want: beat{At: 1588334400000},
+		},
+		{
+			name: "invalid",
+			v:    "{",
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBeat(tt.v)
+			if (err != nil) != tt.err {
+				t.Fatalf("parseBeat() error = %v, want error %t", err, tt.err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("parseBeat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeer_Alive(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		p    Peer
+		want bool
+	}{
+		{name: "recent", p: Peer{LastBeat: now.Add(-time.Second), Fail: 8 * time.Second}, want: true},
+		{name: "stale", p: Peer{LastBeat: now.Add(-10 * time.Second), Fail: 8 * time.Second}},
+		{name: "own_limit", p: Peer{LastBeat: now.Add(-10 * time.Second), Fail: 20 * time.Second}, want: true},
+		{name: "default_limit", p: Peer{LastBeat: now.Add(-9 * time.Second)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.Alive(now); got != tt.want {
+				t.Fatalf("Alive() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Package i18n holds the bot's canned responses in each language they've been
+// translated to, and which language each user has chosen to see them in.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// English is the default language, which every message has.
+const English = "en"
+
+// Catalog is the messages, by language and key. It's built on startup and is
+// then safe for concurrent reads.
+type Catalog struct {
+	msgs map[string]map[string]string
+}
+
+// NewCatalog returns a new, empty, *Catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{msgs: make(map[string]map[string]string)}
+}
+
+// Add adds the message in the language, from the lines of content. It panics
+// if the message was already added in the language, or it's translated into
+// another language before being added in English.
+func (c *Catalog) Add(lang, key string, content ...string) {
+	if len(lang) == 0 || len(key) == 0 {
+		panic("lang and key cannot be empty string")
+	}
+
+	if len(content) == 0 {
+		panic("content variadic cannot be empty")
+	}
+
+	if _, ok := c.msgs[English][key]; !ok && lang != English {
+		panic(fmt.Sprintf("message %q must be added in English first", key))
+	}
+
+	msgs, ok := c.msgs[lang]
+	if !ok {
+		msgs = make(map[string]string)
+		c.msgs[lang] = msgs
+	}
+
+	if _, ok := msgs[key]; ok {
+		panic(fmt.Sprintf("message %q already exists in %q", key, lang))
+	}
+
+	msgs[key] = strings.Join(content, "\n")
+}
+
+// Message returns the message in the language, or in English if it hasn't
+// been translated. The bool is false if the message doesn't exist at all.
+func (c *Catalog) Message(lang, key string) (string, bool) {
+	if msg, ok := c.msgs[lang][key]; ok {
+		return msg, true
+	}
+
+	msg, ok := c.msgs[English][key]
+
+	return msg, ok
+}
+
+// Has returns whether the key is a message.
+func (c *Catalog) Has(key string) bool {
+	_, ok := c.msgs[English][key]
+	return ok
+}
+
+// Supports returns whether any messages have been translated to the language.
+func (c *Catalog) Supports(lang string) bool {
+	_, ok := c.msgs[lang]
+	return ok
+}
+
+// Languages returns the languages with messages, sorted.
+func (c *Catalog) Languages() []string {
+	langs := make([]string, 0, len(c.msgs))
+
+	for l := range c.msgs {
+		langs = append(langs, l)
+	}
+
+	sort.Strings(langs)
+
+	return langs
+}
+
+const (
+	redisKeyFormat = "language:%s" // user ID
+	redisTestKey   = "language:test_key"
+)
+
+// Store is the Redis-backed store of the language each user has chosen.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Language returns the user's chosen language, or English if they haven't
+// chosen one.
+func (s *Store) Language(ctx context.Context, userID string) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+		// noop
+	}
+
+	lang, err := s.r.Get(ctx, fmt.Sprintf(redisKeyFormat, userID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return English, nil
+		}
+
+		return "", fmt.Errorf("failed to get language: %w", err)
+	}
+
+	return lang, nil
+}
+
+// SetLanguage sets the user's chosen language. Choosing English removes their
+// choice, as it's the default.
+func (s *Store) SetLanguage(ctx context.Context, userID, lang string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	key := fmt.Sprintf(redisKeyFormat, userID)
+
+	var err error
+
+	if lang == English {
+		err = s.r.Del(ctx, key).Err()
+	} else {
+		err = s.r.Set(ctx, key, lang, 0).Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to set language: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeUser deletes the user's chosen language, returning 1 if they had chosen
+// one. If dryRun is true, it's not deleted. It satisfies purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	key := fmt.Sprintf(redisKeyFormat, userID)
+
+	if dryRun {
+		n, err := s.r.Exists(ctx, key).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to EXISTS redis key: %w", err)
+		}
+
+		return int(n), nil
+	}
+
+	n, err := s.r.Del(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to DEL redis key: %w", err)
+	}
+
+	return int(n), nil
+}
+
+// LanguageStore is the storage of users' chosen languages, generally satisfied
+// by a *Store.
+type LanguageStore interface {
+	Language(ctx context.Context, userID string) (string, error)
+}
+
+// Localizer returns messages in the language each user has chosen.
+type Localizer struct {
+	c *Catalog
+	s LanguageStore
+}
+
+// NewLocalizer returns a new *Localizer.
+func NewLocalizer(c *Catalog, s LanguageStore) *Localizer {
+	return &Localizer{c: c, s: s}
+}
+
+// Has returns whether the key is a message.
+func (l *Localizer) Has(key string) bool {
+	return l.c.Has(key)
+}
+
+// Localize returns the message in the user's chosen language. If their choice
+// can't be looked up, the message is returned in English along with the error,
+// so it can still be sent.
+func (l *Localizer) Localize(ctx context.Context, userID, key string) (string, error) {
+	lang, err := l.s.Language(ctx, userID)
+	if err != nil {
+		lang = English
+	}
+
+	msg, ok := l.c.Message(lang, key)
+	if !ok {
+		return "", fmt.Errorf("unknown message %q", key)
+	}
+
+	return msg, err
+}
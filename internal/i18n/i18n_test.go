@@ -0,0 +1,165 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testCatalog() *Catalog {
+	c := NewCatalog()
+
+	c.Add(English, "ask", "Don't ask to ask.", "Just ask.")
+	c.Add(English, "tour", "Take the tour.")
+	c.Add("es", "ask", "No preguntes si puedes preguntar.")
+
+	return c
+}
+
+func TestCatalog_Message(t *testing.T) {
+	tests := []struct {
+		name   string
+		lang   string
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "english",
+			lang:   English,
+			key:    "ask",
+			want:   "Don't ask to ask.\nJust ask.",
+			wantOK: true,
+		},
+		{
+			name:   "translated",
+			lang:   "es",
+			key:    "ask",
+			want:   "No preguntes si puedes preguntar.",
+			wantOK: true,
+		},
+		{
+			name:   "untranslated",
+			lang:   "es",
+			key:    "tour",
+			want:   "Take the tour.",
+			wantOK: true,
+		},
+		{
+			name:   "unknown_language",
+			lang:   "fr",
+			key:    "tour",
+			want:   "Take the tour.",
+			wantOK: true,
+		},
+		{
+			name: "unknown_key",
+			lang: "es",
+			key:  "nope",
+		},
+	}
+
+	c := testCatalog()
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.Message(tt.lang, tt.key)
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("Message() = %q, %t, want %q, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCatalog_Languages(t *testing.T) {
+	c := testCatalog()
+
+	if diff := cmp.Diff([]string{"en", "es"}, c.Languages()); diff != "" {
+		t.Fatalf("Languages() mismatch (-want +got):\n%s", diff)
+	}
+
+	if !c.Supports("es") || c.Supports("fr") {
+		t.Fatalf("Supports() = %t, %t for es, fr, want true, false", c.Supports("es"), c.Supports("fr"))
+	}
+}
+
+func TestCatalog_Add_untranslatable(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Add() should panic for a message not added in English")
+		}
+	}()
+
+	testCatalog().Add("es", "nope", "No.")
+}
+
+type fakeLanguages map[string]string
+
+func (f fakeLanguages) Language(_ context.Context, userID string) (string, error) {
+	if userID == "UBROKEN" {
+		return "", errors.New("redis is down")
+	}
+
+	if l, ok := f[userID]; ok {
+		return l, nil
+	}
+
+	return English, nil
+}
+
+func TestLocalizer_Localize(t *testing.T) {
+	l := NewLocalizer(testCatalog(), fakeLanguages{"USPANISH": "es"})
+
+	tests := []struct {
+		name    string
+		userID  string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "default",
+			userID: "UOTHER",
+			key:    "ask",
+			want:   "Don't ask to ask.\nJust ask.",
+		},
+		{
+			name:   "chosen",
+			userID: "USPANISH",
+			key:    "ask",
+			want:   "No preguntes si puedes preguntar.",
+		},
+		{
+			name:    "store_error",
+			userID:  "UBROKEN",
+			key:     "ask",
+			want:    "Don't ask to ask.\nJust ask.",
+			wantErr: true,
+		},
+		{
+			name:    "unknown_key",
+			userID:  "USPANISH",
+			key:     "nope",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.Localize(context.Background(), tt.userID, tt.key)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Localize() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Fatalf("Localize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
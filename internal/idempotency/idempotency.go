@@ -0,0 +1,68 @@
+// Package idempotency records which one-off actions, like posting an
+// announcement, were already taken, so that retrying after a partial failure
+// doesn't take them twice.
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyFormat = "idempotency:%s"
+	redisTestKey   = "idempotency:test_key"
+)
+
+// Store is the Redis-backed idempotency key store.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Claim records the key before taking the action it's for, returning false if
+// it was already claimed within ttl, in which case the action should be
+// skipped. If the action then fails, Release the key so it can be retried.
+func (s *Store) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.SetNX(ctx, fmt.Sprintf(redisKeyFormat, key), time.Now().Unix(), ttl)
+	if err := res.Err(); err != nil {
+		return false, fmt.Errorf("failed to SETNX redis key: %w", err)
+	}
+
+	return res.Val(), nil
+}
+
+// Release removes a claimed key, after the action it was for failed.
+func (s *Store) Release(ctx context.Context, key string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.Del(ctx, fmt.Sprintf(redisKeyFormat, key)).Err(); err != nil {
+		return fmt.Errorf("failed to DEL redis key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package joins records when users joined the workspace, so that new accounts
+// can be held to stricter rules than established members, and which users were
+// welcomed, so they're only welcomed once.
+package joins
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyFormat         = "joins:user:%s"
+	redisWelcomedKeyFormat = "welcomed:%s"
+	redisTestKey           = "joins:test_key"
+
+	// retention is how long join times are kept; long enough for any window
+	// a new account should be treated differently in.
+	retention = 7 * 24 * time.Hour
+)
+
+// Store stores the time each user joined the workspace.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Record records that the user joined at t.
+func (s *Store) Record(ctx context.Context, userID string, t time.Time) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Set(ctx, fmt.Sprintf(redisKeyFormat, userID), strconv.FormatInt(t.Unix(), 10), retention)
+	if err := res.Err(); err != nil {
+		return fmt.Errorf("failed to record join time for %s: %w", userID, err)
+	}
+
+	return nil
+}
+
+// JoinedAt returns when the user joined. If found is false, the user joined
+// before we started recording, or longer ago than we keep records for.
+func (s *Store) JoinedAt(ctx context.Context, userID string) (t time.Time, found bool, err error) {
+	select {
+	case <-ctx.Done():
+		return time.Time{}, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, fmt.Sprintf(redisKeyFormat, userID))
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return time.Time{}, false, nil
+		}
+
+		return time.Time{}, false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	u, err := strconv.ParseInt(res.Val(), 10, 64)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to parse join time for %s: %w", userID, err)
+	}
+
+	return time.Unix(u, 0), true, nil
+}
+
+// ClaimWelcome records that the user is being welcomed, before the welcome is
+// sent, returning false if they already were, in which case it shouldn't be
+// sent again. This holds across retries and restarts, so a welcome that timed
+// out after being sent isn't sent twice. If sending definitely failed, use
+// ReleaseWelcome so it's retried.
+func (s *Store) ClaimWelcome(ctx context.Context, userID string) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.SetNX(ctx, fmt.Sprintf(redisWelcomedKeyFormat, userID), strconv.FormatInt(time.Now().Unix(), 10), 0)
+	if err := res.Err(); err != nil {
+		return false, fmt.Errorf("failed to SETNX redis key: %w", err)
+	}
+
+	return res.Val(), nil
+}
+
+// ReleaseWelcome removes the record that the user was welcomed, after sending
+// the welcome failed.
+func (s *Store) ReleaseWelcome(ctx context.Context, userID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.Del(ctx, fmt.Sprintf(redisWelcomedKeyFormat, userID)).Err(); err != nil {
+		return fmt.Errorf("failed to DEL redis key: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeUser deletes when the user joined and whether they were welcomed,
+// returning how many of those were recorded. If dryRun is true, they're
+// counted but not deleted. It satisfies purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	keys := []string{
+		fmt.Sprintf(redisKeyFormat, userID),
+		fmt.Sprintf(redisWelcomedKeyFormat, userID),
+	}
+
+	if dryRun {
+		n, err := s.r.Exists(ctx, keys...).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to EXISTS redis keys: %w", err)
+		}
+
+		return int(n), nil
+	}
+
+	n, err := s.r.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to DEL redis keys: %w", err)
+	}
+
+	return int(n), nil
+}
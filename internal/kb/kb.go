@@ -0,0 +1,142 @@
+// Package kb is a knowledge base of common Go error messages, with an
+// explanation of each, so the bot can explain the errors people paste. The
+// built-in entries can be added to by the moderators at runtime.
+package kb
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// MaxMatches is the most entries explained for a single message.
+const MaxMatches = 2
+
+// Entry is an error message, and what it means.
+type Entry struct {
+	// ID is a short name for the entry, like unused-import.
+	ID string
+
+	// Pattern matches the error message.
+	Pattern *regexp.Regexp
+
+	// Explanation is what the error means, and how to fix it, in Slack's
+	// markdown.
+	Explanation string
+
+	// Author is the user who contributed the entry, or empty for built-in
+	// ones.
+	Author string
+}
+
+var idRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,39}$`)
+
+// New returns an Entry, after validating the ID and compiling the pattern,
+// which is matched case-insensitively.
+func New(id, pattern, explanation string) (Entry, error) {
+	if !idRegexp.MatchString(id) {
+		return Entry{}, fmt.Errorf("`%s` should be lower case letters, numbers, and dashes, like `unused-import`", id)
+	}
+
+	if len(strings.TrimSpace(pattern)) == 0 {
+		return Entry{}, fmt.Errorf("the pattern can't be empty")
+	}
+
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return Entry{}, fmt.Errorf("the pattern isn't a valid regular expression: %w", err)
+	}
+
+	if re.MatchString("") {
+		return Entry{}, fmt.Errorf("the pattern matches every message")
+	}
+
+	if len(strings.TrimSpace(explanation)) == 0 {
+		return Entry{}, fmt.Errorf("the explanation can't be empty")
+	}
+
+	return Entry{ID: id, Pattern: re, Explanation: explanation}, nil
+}
+
+func mustNew(id, pattern, explanation string) Entry {
+	e, err := New(id, pattern, explanation)
+	if err != nil {
+		panic(fmt.Sprintf("built-in entry %s: %v", id, err))
+	}
+
+	return e
+}
+
+// Builtin are the entries that ship with the bot.
+var Builtin = []Entry{
+	mustNew("unused-import", `imported and not used`,
+		"Go doesn't allow unused imports. Remove the import, or if you need it for its side effects, like registering a database driver, import it as `_`. `goimports` can add and remove imports for you."),
+	mustNew("unused-variable", `declared (?:and|but) not used`,
+		"Go doesn't allow unused local variables. Remove the variable, use it, or assign to `_` if you only need the other results of a call."),
+	mustNew("nil-pointer", `invalid memory address or nil pointer dereference`,
+		"Something that's `nil` was used as if it pointed to a value, like calling a method or reading a field through a nil pointer, or using a nil interface. The stack trace after the panic shows the line; check where that value is set, and whether an error returned with it was ignored."),
+	mustNew("cannot-use", `cannot use .+ \((?:(?:variable|value|constant) of )?type [^)]+\) as `,
+		"The value's type doesn't match the type needed there. Go doesn't convert between types implicitly, even between a named type and its underlying type, so convert it explicitly, like `float64(x)`, or check that it implements the interface needed."),
+	mustNew("nil-map", `assignment to entry in nil map`,
+		"Maps have to be made before they're written to: `m := make(map[string]int)`, or `m := map[string]int{}`. Reading from a nil map is fine, and returns the zero value."),
+	mustNew("index-out-of-range", `index out of range(?: \[\d+\] with length \d+)?`,
+		"A slice, array, or string was indexed past its length. Check the length with `len` first, and remember indexes start at 0, so the last element is at `len(s)-1`."),
+	mustNew("deadlock", `all goroutines are asleep - deadlock!`,
+		"Every goroutine is blocked, so the program can never continue. This is usually a send on a channel nobody receives from, a receive on a channel nobody sends to or closes, or a `sync.WaitGroup` waiting for more `Done` calls than happen."),
+	mustNew("concurrent-map", `concurrent map (?:writes|read and map write|iteration and map write)`,
+		"Maps aren't safe to use from multiple goroutines at once if any of them writes. Guard the map with a `sync.Mutex` or `sync.RWMutex`, or use `sync.Map` for the cases it's designed for. Running with `-race` helps find these."),
+	mustNew("missing-return", `missing return`,
+		"A function with results has to end with a `return` (or a `panic`) on every path. Go doesn't look at whether your `if` or `switch` covers every case, so add a final `return`."),
+	mustNew("import-cycle", `import cycle not allowed`,
+		"Two or more packages import each other, which Go doesn't allow. Move the shared code into a package that both can import, or have one define an interface the other implements."),
+	mustNew("no-required-module", `no required module provides package`,
+		"The package isn't in any module your `go.mod` requires. Run `go get` with the package's path to add it, or `go mod tidy` to add every missing requirement."),
+	mustNew("pointer-receiver", `does not implement .+ \(.+ method has pointer receiver\)`,
+		"The method is defined on the pointer type, like `func (t *T) M()`, so only `*T` has it, not `T`. Use a pointer, like `&t`, where the interface is needed."),
+}
+
+// Match returns the entries matching the text, up to MaxMatches, in the order
+// they're listed. The text is unescaped first, as Slack escapes some of the
+// characters in error messages.
+func Match(entries []Entry, text string) []Entry {
+	text = html.UnescapeString(text)
+
+	var matches []Entry
+
+	for _, e := range entries {
+		if !e.Pattern.MatchString(text) {
+			continue
+		}
+
+		matches = append(matches, e)
+
+		if len(matches) == MaxMatches {
+			break
+		}
+	}
+
+	return matches
+}
+
+// merge returns the built-in entries followed by the contributed ones, which
+// replace a built-in entry with the same ID.
+func merge(builtin, contributed []Entry) []Entry {
+	byID := make(map[string]int, len(contributed))
+
+	for i, e := range contributed {
+		byID[e.ID] = i
+	}
+
+	entries := make([]Entry, 0, len(builtin)+len(contributed))
+
+	for _, e := range builtin {
+		if _, ok := byID[e.ID]; ok {
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	return append(entries, contributed...)
+}
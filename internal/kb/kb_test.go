@@ -0,0 +1,123 @@
+package kb
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "unused_import",
+			text: "```./prog.go:4:2: \"os\" imported and not used```",
+			want: []string{"unused-import"},
+		},
+		{
+			name: "unused_variable_old",
+			text: "./main.go:8:2: x declared but not used",
+			want: []string{"unused-variable"},
+		},
+		{
+			name: "unused_variable_new",
+			text: "./main.go:8:2: declared and not used: x",
+			want: []string{"unused-variable"},
+		},
+		{
+			name: "nil_pointer",
+			text: "panic: runtime error: invalid memory address or nil pointer dereference\n[signal SIGSEGV: segmentation violation]",
+			want: []string{"nil-pointer"},
+		},
+		{
+			name: "cannot_use_old",
+			text: "cannot use x (type int) as type float64 in argument to math.Sqrt",
+			want: []string{"cannot-use"},
+		},
+		{
+			name: "cannot_use_new",
+			text: "cannot use x (variable of type int) as float64 value in argument to math.Sqrt",
+			want: []string{"cannot-use"},
+		},
+		{
+			name: "escaped",
+			text: "cannot use &amp;t (value of type *T) as I value",
+			want: []string{"cannot-use"},
+		},
+		{
+			name: "max_matches",
+			text: "imported and not used\nx declared and not used\nmissing return",
+			want: []string{"unused-import", "unused-variable"},
+		},
+		{
+			name: "case",
+			text: "FATAL ERROR: ALL GOROUTINES ARE ASLEEP - DEADLOCK!",
+			want: []string{"deadlock"},
+		},
+		{
+			name: "nothing",
+			text: "how do I declare a variable?",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+
+			for _, e := range Match(Builtin, tt.text) {
+				got = append(got, e.ID)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Match() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          string
+		pattern     string
+		explanation string
+		wantErr     bool
+	}{
+		{name: "valid", id: "loop-var", pattern: `loop variable \w+ captured`, explanation: "Go 1.22 fixed this."},
+		{name: "bad_id", id: "Loop Var", pattern: "x", explanation: "y", wantErr: true},
+		{name: "empty_pattern", id: "x", pattern: " ", explanation: "y", wantErr: true},
+		{name: "bad_pattern", id: "x", pattern: "(", explanation: "y", wantErr: true},
+		{name: "matches_everything", id: "x", pattern: ".*", explanation: "y", wantErr: true},
+		{name: "empty_explanation", id: "x", pattern: "x", explanation: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.id, tt.pattern, tt.explanation); (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_merge(t *testing.T) {
+	builtin := []Entry{mustNew("a", "a", "a"), mustNew("b", "b", "b")}
+	contributed := []Entry{mustNew("b", "bb", "bb"), mustNew("c", "c", "c")}
+
+	var got []string
+
+	for _, e := range merge(builtin, contributed) {
+		got = append(got, e.ID+":"+e.Explanation)
+	}
+
+	want := []string{"a:a", "b:bb", "c:c"}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("merge() mismatch (-want +got):\n%s", diff)
+	}
+}
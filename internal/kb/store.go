@@ -0,0 +1,316 @@
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	redisEntriesKey  = "kb:entries"  // hash of ID to contribution
+	redisChannelsKey = "kb:channels" // set of the channels it's enabled in
+	redisTestKey     = "kb:test_key"
+)
+
+// refreshInterval is how long the entries and channels are cached for, so
+// other processes pick up changes within this long.
+const refreshInterval = 15 * time.Second
+
+// refreshTimeout is how long refreshing them can take.
+const refreshTimeout = 500 * time.Millisecond
+
+// contribution is how a contributed entry is stored.
+type contribution struct {
+	Pattern     string `json:"pattern"`
+	Explanation string `json:"explanation"`
+	Author      string `json:"author"`
+}
+
+// Store stores the contributed entries, and the channels the knowledge base is
+// enabled in. Both are cached, so matching messages doesn't usually hit Redis.
+type Store struct {
+	r      *redis.Client
+	logger zerolog.Logger
+
+	mu       sync.Mutex
+	entries  []Entry
+	channels map[string]struct{}
+	fetched  time.Time
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client, logger zerolog.Logger) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{
+		r:       rc,
+		logger:  logger,
+		entries: Builtin,
+	}, nil
+}
+
+// refresh reloads the cache if it's expired. If it can't be, the last entries
+// and channels fetched are used. It must be called with s.mu held.
+func (s *Store) refresh() {
+	if time.Since(s.fetched) <= refreshInterval {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+
+	contributed, err := s.Contributed(ctx)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("failed to refresh knowledge base entries")
+
+		return
+	}
+
+	channels, err := s.r.SMembers(ctx, redisChannelsKey).Result()
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("failed to refresh knowledge base channels")
+
+		return
+	}
+
+	s.entries = merge(Builtin, contributed)
+	s.channels = make(map[string]struct{}, len(channels))
+
+	for _, cid := range channels {
+		s.channels[cid] = struct{}{}
+	}
+
+	s.fetched = time.Now()
+}
+
+// Entries returns the built-in and contributed entries.
+func (s *Store) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refresh()
+
+	return s.entries
+}
+
+// Enabled returns whether the knowledge base is enabled in the channel.
+func (s *Store) Enabled(channelID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refresh()
+
+	_, ok := s.channels[channelID]
+
+	return ok
+}
+
+// SetEnabled sets whether the knowledge base is enabled in the channel.
+func (s *Store) SetEnabled(ctx context.Context, channelID string, enabled bool) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	var err error
+
+	if enabled {
+		err = s.r.SAdd(ctx, redisChannelsKey, channelID).Err()
+	} else {
+		err = s.r.SRem(ctx, redisChannelsKey, channelID).Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update knowledge base channel %s: %w", channelID, err)
+	}
+
+	s.invalidate()
+
+	return nil
+}
+
+// Contributed returns the contributed entries, ordered by ID. Any that no
+// longer compile are skipped.
+func (s *Store) Contributed(ctx context.Context) ([]Entry, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	m, err := s.r.HGetAll(ctx, redisEntriesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to HGETALL redis key: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(m))
+
+	for id, v := range m {
+		var c contribution
+
+		if err := json.Unmarshal([]byte(v), &c); err != nil {
+			s.logger.Warn().
+				Err(err).
+				Str("kb_id", id).
+				Msg("failed to unmarshal knowledge base entry")
+
+			continue
+		}
+
+		e, err := New(id, c.Pattern, c.Explanation)
+		if err != nil {
+			s.logger.Warn().
+				Err(err).
+				Str("kb_id", id).
+				Msg("invalid knowledge base entry")
+
+			continue
+		}
+
+		e.Author = c.Author
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	return entries, nil
+}
+
+// Add adds the entry, replacing any with the same ID. The pattern is stored as
+// given to New.
+func (s *Store) Add(ctx context.Context, id, pattern, explanation, author string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if _, err := New(id, pattern, explanation); err != nil {
+		return err
+	}
+
+	j, err := json.Marshal(contribution{Pattern: pattern, Explanation: explanation, Author: author})
+	if err != nil {
+		return fmt.Errorf("failed to marshal knowledge base entry %s: %w", id, err)
+	}
+
+	if err := s.r.HSet(ctx, redisEntriesKey, id, string(j)).Err(); err != nil {
+		return fmt.Errorf("failed to HSET redis key: %w", err)
+	}
+
+	s.invalidate()
+
+	return nil
+}
+
+// Remove removes the contributed entry, returning whether there was one.
+func (s *Store) Remove(ctx context.Context, id string) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// noop
+	}
+
+	n, err := s.r.HDel(ctx, redisEntriesKey, id).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to HDEL redis key: %w", err)
+	}
+
+	s.invalidate()
+
+	return n > 0, nil
+}
+
+// PurgeUser removes the user as the author of the entries they contributed,
+// returning how many there were. The entries themselves are kept. If dryRun is
+// true, they're counted but not changed. It satisfies purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	var n int
+
+	// don't overwrite entries changed while we're updating them
+	err := s.r.Watch(ctx, func(tx *redis.Tx) error {
+		m, err := tx.HGetAll(ctx, redisEntriesKey).Result()
+		if err != nil {
+			return fmt.Errorf("failed to HGETALL redis key: %w", err)
+		}
+
+		updates := make(map[string]interface{})
+
+		for id, v := range m {
+			var c contribution
+
+			if err := json.Unmarshal([]byte(v), &c); err != nil {
+				return fmt.Errorf("failed to unmarshal knowledge base entry %s: %w", id, err)
+			}
+
+			if c.Author != userID {
+				continue
+			}
+
+			c.Author = ""
+
+			j, err := json.Marshal(c)
+			if err != nil {
+				return fmt.Errorf("failed to marshal knowledge base entry %s: %w", id, err)
+			}
+
+			updates[id] = string(j)
+		}
+
+		n = len(updates)
+
+		if n == 0 || dryRun {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, redisEntriesKey, updates)
+			return nil
+		})
+
+		return err
+	}, redisEntriesKey)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge author %s from knowledge base: %w", userID, err)
+	}
+
+	if n > 0 && !dryRun {
+		s.invalidate()
+	}
+
+	return n, nil
+}
+
+// invalidate makes the next lookup refresh the cache.
+func (s *Store) invalidate() {
+	s.mu.Lock()
+	s.fetched = time.Time{}
+	s.mu.Unlock()
+}
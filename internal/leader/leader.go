@@ -0,0 +1,213 @@
+// Package leader provides leader election using a lock in Redis, so that only
+// one of several processes does work that can't be duplicated, with another
+// taking over if the leader's lock expires.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const redisKeyFormat = "leader:%s"
+
+// releaseTimeout is how long releasing the lock can take while stopping.
+const releaseTimeout = 5 * time.Second
+
+// renewScript extends the lock, but only if we still hold it.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lock, but only if we still hold it.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Elector campaigns to be the leader for a name.
+type Elector struct {
+	r      *redis.Client
+	key    string
+	id     string
+	ttl    time.Duration
+	logger zerolog.Logger
+
+	mu      sync.Mutex
+	leading bool
+	since   time.Time
+}
+
+// New returns a new *Elector, which campaigns as id. The ttl is how long the
+// lock is held without being renewed, and so how long it takes another
+// process to take over if the leader dies.
+func New(rc *redis.Client, name, id string, ttl time.Duration, logger zerolog.Logger) (*Elector, error) {
+	if len(id) == 0 {
+		return nil, fmt.Errorf("must provide an id to New()")
+	}
+
+	if ttl < time.Second {
+		return nil, fmt.Errorf("ttl must be at least 1s, got %s", ttl)
+	}
+
+	return &Elector{
+		r:      rc,
+		key:    fmt.Sprintf(redisKeyFormat, name),
+		id:     id,
+		ttl:    ttl,
+		logger: logger,
+	}, nil
+}
+
+// Leading returns whether we're the leader, and since when.
+func (e *Elector) Leading() (bool, time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.leading, e.since
+}
+
+// Leader returns the ID of the current leader, or an empty string if there
+// isn't one.
+func (e *Elector) Leader(ctx context.Context) (string, error) {
+	id, err := e.r.Get(ctx, e.key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	return id, nil
+}
+
+func (e *Elector) setLeading(leading bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.leading = leading
+	e.since = time.Now()
+}
+
+// Run campaigns until the context is canceled. Each time we become the
+// leader, fn is called with a context that's canceled when we stop being the
+// leader, and we keep campaigning after it returns. If fn returns an error,
+// Run gives up the lock and returns it.
+func (e *Elector) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	interval := e.ttl / 3
+
+	for {
+		acquired, err := e.r.SetNX(ctx, e.key, e.id, e.ttl).Result()
+		if err != nil {
+			e.logger.Error().
+				Err(err).
+				Msg("failed to campaign for leader")
+		}
+
+		if acquired {
+			if err := e.lead(ctx, fn); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// lead runs fn while renewing the lock, until either fn returns or the lock is
+// lost.
+func (e *Elector) lead(ctx context.Context, fn func(ctx context.Context) error) error {
+	e.setLeading(true)
+	defer e.setLeading(false)
+
+	e.logger.Info().Msg("became leader")
+
+	lctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() { done <- fn(lctx) }()
+
+	t := time.NewTicker(e.ttl / 3)
+	defer t.Stop()
+
+	renewed := time.Now()
+
+	for {
+		select {
+		case err := <-done:
+			e.release()
+			return err
+
+		case <-t.C:
+			ok, err := e.renew(ctx)
+
+			switch {
+			case err != nil:
+				e.logger.Error().
+					Err(err).
+					Msg("failed to renew leader lock")
+
+				// stop before the lock can expire, and someone else take
+				// over, if Redis stays unreachable
+				if time.Since(renewed) < e.ttl*2/3 {
+					continue
+				}
+
+			case ok:
+				renewed = time.Now()
+				continue
+			}
+
+			e.logger.Warn().Msg("lost leader lock: stopping")
+
+			cancel()
+
+			err = <-done
+
+			return err
+		}
+	}
+}
+
+func (e *Elector) renew(ctx context.Context) (bool, error) {
+	n, err := renewScript.Run(ctx, e.r, []string{e.key}, e.id, e.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// release gives up the lock, so another process can take over without waiting
+// for it to expire. It's done even if we're stopping because the context was
+// canceled.
+func (e *Elector) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	if err := releaseScript.Run(ctx, e.r, []string{e.key}, e.id).Err(); err != nil {
+		e.logger.Error().
+			Err(err).
+			Msg("failed to release leader lock")
+
+		return
+	}
+
+	e.logger.Info().Msg("released leader lock")
+}
@@ -0,0 +1,138 @@
+// Package mentionsonly stores the channels Workspace Admins have asked the bot
+// to stay quiet in unless it's mentioned, so it doesn't react to, or respond
+// on its own to, the messages there.
+package mentionsonly
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	redisKey     = "mentionsonly:channels" // set of channel IDs
+	redisTestKey = "mentionsonly:test_key"
+)
+
+// refreshInterval is how long the channels are cached for, so other processes
+// pick up changes within this long.
+const refreshInterval = 15 * time.Second
+
+// refreshTimeout is how long refreshing the channels can take.
+const refreshTimeout = 500 * time.Millisecond
+
+// Store is the Redis-backed set of channels. The channels are cached, so
+// checking one doesn't usually hit Redis.
+type Store struct {
+	r      *redis.Client
+	logger zerolog.Logger
+
+	mu       sync.Mutex
+	channels map[string]struct{}
+	fetched  time.Time
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client, logger zerolog.Logger) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{
+		r:      rc,
+		logger: logger,
+	}, nil
+}
+
+// MentionsOnly returns whether the channel is mentions-only. If the channels
+// can't be refreshed, the last ones fetched are used. It satisfies
+// handler.MentionsOnly.
+func (s *Store) MentionsOnly(channelID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.fetched) > refreshInterval {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+
+		channels, err := s.Channels(ctx)
+
+		cancel()
+
+		if err != nil {
+			s.logger.Error().
+				Err(err).
+				Msg("failed to refresh mentions-only channels")
+		} else {
+			s.channels = make(map[string]struct{}, len(channels))
+
+			for _, cid := range channels {
+				s.channels[cid] = struct{}{}
+			}
+
+			s.fetched = time.Now()
+		}
+	}
+
+	_, ok := s.channels[channelID]
+
+	return ok
+}
+
+// Channels returns the mentions-only channels, sorted by ID.
+func (s *Store) Channels(ctx context.Context) ([]string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	channels, err := s.r.SMembers(ctx, redisKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to SMEMBERS redis key: %w", err)
+	}
+
+	sort.Strings(channels)
+
+	return channels, nil
+}
+
+// Set sets whether the channel is mentions-only.
+func (s *Store) Set(ctx context.Context, channelID string, mentionsOnly bool) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	var err error
+
+	if mentionsOnly {
+		err = s.r.SAdd(ctx, redisKey, channelID).Err()
+	} else {
+		err = s.r.SRem(ctx, redisKey, channelID).Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update mentions-only channel %s: %w", channelID, err)
+	}
+
+	s.invalidate()
+
+	return nil
+}
+
+// invalidate makes the next MentionsOnly call refresh the channels.
+func (s *Store) invalidate() {
+	s.mu.Lock()
+	s.fetched = time.Time{}
+	s.mu.Unlock()
+}
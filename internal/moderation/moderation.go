@@ -0,0 +1,260 @@
+// Package moderation provides the shared pieces for automated moderation
+// detectors: recording their decisions, only enforcing them when the detector
+// is configured to, alerting moderators, and collecting moderator feedback so
+// that thresholds can be tuned before enforcement is enabled.
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/notify"
+	"github.com/gobridge/gopherbot/mparser"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// Mode is how a detector's decisions are handled.
+type Mode string
+
+const (
+	// DryRun only records and reports what the detector would have done.
+	DryRun Mode = "dry_run"
+
+	// Enforce records and reports decisions, and takes action on them.
+	Enforce Mode = "enforce"
+)
+
+// ParseMode parses the string representation of a Mode.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(strings.ToLower(s)) {
+	case DryRun:
+		return DryRun, nil
+	case Enforce:
+		return Enforce, nil
+	default:
+		return "", fmt.Errorf("unknown moderation mode %q", s)
+	}
+}
+
+// Modes converts the detector modes from the configuration, see
+// config.M.Modes.
+func Modes(m map[string]string) (map[string]Mode, error) {
+	modes := make(map[string]Mode, len(m))
+
+	for name, s := range m {
+		mode, err := ParseMode(s)
+		if err != nil {
+			return nil, fmt.Errorf("detector %s: %w", name, err)
+		}
+
+		modes[name] = mode
+	}
+
+	return modes, nil
+}
+
+// Verdict is moderator feedback on whether a decision was right.
+type Verdict string
+
+const (
+	// Correct is for when the detector flagged something it should have.
+	Correct Verdict = "correct"
+
+	// Incorrect is for false positives.
+	Incorrect Verdict = "incorrect"
+)
+
+const (
+	// CorrectReaction is the emoji moderators add to an alert to mark it as
+	// Correct.
+	CorrectReaction = "white_check_mark"
+
+	// IncorrectReaction is the emoji moderators add to an alert to mark it as
+	// Incorrect.
+	IncorrectReaction = "x"
+)
+
+// Decision is a detector's decision to flag a message.
+type Decision struct {
+	// Detector is the name of the detector, like "spam".
+	Detector string `json:"detector"`
+
+	// ChannelID is the channel the message was sent in.
+	ChannelID string `json:"channel_id"`
+
+	// UserID is the user who sent the message.
+	UserID string `json:"user_id"`
+
+	// MessageTS is the message ID.
+	MessageTS string `json:"message_ts"`
+
+	// Score is the detector's score for the message.
+	Score float64 `json:"score"`
+
+	// Threshold is the score at which the detector flags messages.
+	Threshold float64 `json:"threshold"`
+
+	// Reason is a human-readable explanation.
+	Reason string `json:"reason"`
+
+	// Mode is the mode the detector was in when it made the decision.
+	Mode Mode `json:"mode"`
+
+	// Time is when the decision was made.
+	Time time.Time `json:"time"`
+}
+
+// ID is the unique identifier for this decision.
+func (d Decision) ID() string {
+	return fmt.Sprintf("%s:%s:%s", d.Detector, d.ChannelID, d.MessageTS)
+}
+
+// Store represents the shape of the storage system.
+type Store interface {
+	Record(ctx context.Context, d Decision) error
+	SetAlert(ctx context.Context, channelID, alertTS, decisionID string) error
+	AlertDecision(ctx context.Context, channelID, alertTS string) (decisionID string, notFound bool, err error)
+	SetFeedback(ctx context.Context, decisionID string, v Verdict) error
+}
+
+// EnforceFunc takes action on a decision, like deleting the message.
+type EnforceFunc func(ctx context.Context) error
+
+// Notifier sends notifications, generally satisfied by a *notify.Router.
+type Notifier interface {
+	Notify(ctx context.Context, n notify.Notification) ([]notify.Delivery, error)
+}
+
+// Moderator handles the decisions of all detectors.
+type Moderator struct {
+	store    Store
+	notifier Notifier
+	modes    map[string]Mode
+	logger   zerolog.Logger
+}
+
+// New returns a *Moderator, which alerts moderators using the notifier. The
+// modes map detector names to their Mode, with any detector not present
+// defaulting to DryRun.
+func New(store Store, notifier Notifier, modes map[string]Mode, logger zerolog.Logger) *Moderator {
+	return &Moderator{
+		store:    store,
+		notifier: notifier,
+		modes:    modes,
+		logger:   logger,
+	}
+}
+
+// Mode returns the configured Mode of the detector.
+func (m *Moderator) Mode(detector string) Mode {
+	if mode, ok := m.modes[detector]; ok {
+		return mode
+	}
+
+	return DryRun
+}
+
+// Flag records the decision, calls enforce if the detector is in Enforce mode,
+// and then alerts the moderators. The enforce function may be nil if the
+// detector only ever alerts.
+func (m *Moderator) Flag(ctx context.Context, d Decision, enforce EnforceFunc) error {
+	d.Mode = m.Mode(d.Detector)
+
+	if d.Time.IsZero() {
+		d.Time = time.Now()
+	}
+
+	if err := m.store.Record(ctx, d); err != nil {
+		return fmt.Errorf("failed to record decision: %w", err)
+	}
+
+	var enforceErr error
+
+	if d.Mode == Enforce && enforce != nil {
+		enforceErr = enforce(ctx)
+	}
+
+	m.logger.Info().
+		Str("detector", d.Detector).
+		Str("mode", string(d.Mode)).
+		Str("channel_id", d.ChannelID).
+		Str("user_id", d.UserID).
+		Float64("score", d.Score).
+		AnErr("enforce_error", enforceErr).
+		Msg("message flagged")
+
+	// enforced decisions need a moderator to double-check them
+	severity := notify.Info
+	if d.Mode == Enforce {
+		severity = notify.Important
+	}
+
+	deliveries, err := m.notifier.Notify(ctx, notify.Notification{
+		Source:   notify.Moderation,
+		Severity: severity,
+		Summary:  fmt.Sprintf("%s flagged message %s", d.Detector, d.ID()),
+		Options: []slack.MsgOption{
+			slack.MsgOptionText(alertText(d, enforceErr), false),
+			slack.MsgOptionDisableLinkUnfurl(),
+		},
+	})
+
+	// record the alerts that did go out, even if some failed
+	for _, dv := range deliveries {
+		if serr := m.store.SetAlert(ctx, dv.ChannelID, dv.TS, d.ID()); serr != nil {
+			return fmt.Errorf("failed to record alert: %w", serr)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to alert moderators: %w", err)
+	}
+
+	if enforceErr != nil {
+		return fmt.Errorf("failed to enforce decision: %w", enforceErr)
+	}
+
+	return nil
+}
+
+func alertText(d Decision, enforceErr error) string {
+	user := mparser.Mention{Type: mparser.TypeUser, ID: d.UserID}
+	channel := mparser.Mention{Type: mparser.TypeChannelRef, ID: d.ChannelID}
+
+	action := "flagged"
+	if d.Mode == DryRun {
+		action = "would have flagged (dry run)"
+	}
+
+	msg := fmt.Sprintf("[%s] %s a message from %s in %s (score %.2f, threshold %.2f): %s",
+		d.Detector, action, user.String(), channel.String(), d.Score, d.Threshold, d.Reason,
+	)
+
+	if enforceErr != nil {
+		msg += fmt.Sprintf("\n:warning: failed to take action: %s", enforceErr)
+	}
+
+	return msg + fmt.Sprintf("\nReact with :%s: if this was right, or :%s: if it was a false positive.", CorrectReaction, IncorrectReaction)
+}
+
+// Feedback records moderator feedback for the decision the alert in channelID
+// with the alertTS was about. If found is false, the message wasn't an alert.
+func (m *Moderator) Feedback(ctx context.Context, channelID, alertTS string, v Verdict) (found bool, err error) {
+	id, notFound, err := m.store.AlertDecision(ctx, channelID, alertTS)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up alert: %w", err)
+	}
+
+	if notFound {
+		return false, nil
+	}
+
+	if err = m.store.SetFeedback(ctx, id, v); err != nil {
+		return false, fmt.Errorf("failed to record feedback: %w", err)
+	}
+
+	return true, nil
+}
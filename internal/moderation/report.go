@@ -0,0 +1,100 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Report is the accuracy of a single detector over a week.
+type Report struct {
+	Detector  string
+	Week      string
+	Flagged   int
+	Correct   int
+	Incorrect int
+}
+
+// Precision is the ratio of correct decisions to those that have had feedback.
+// If ok is false, no decisions have had feedback.
+func (r Report) Precision() (precision float64, ok bool) {
+	reviewed := r.Correct + r.Incorrect
+	if reviewed == 0 {
+		return 0, false
+	}
+
+	return float64(r.Correct) / float64(reviewed), true
+}
+
+func (r Report) String() string {
+	p := "n/a"
+	if v, ok := r.Precision(); ok {
+		p = fmt.Sprintf("%.0f%%", v*100)
+	}
+
+	return fmt.Sprintf("- `%s`: %d flagged, %d correct, %d false positives, %d unreviewed (precision: %s)",
+		r.Detector, r.Flagged, r.Correct, r.Incorrect, r.Flagged-r.Correct-r.Incorrect, p,
+	)
+}
+
+// ReportStore is the storage needed to build reports, generally satisfied by
+// a *DefaultStore.
+type ReportStore interface {
+	Detectors(ctx context.Context) ([]string, error)
+	WeekFeedback(ctx context.Context, detector, week string) (map[string]Verdict, error)
+}
+
+// BuildReports builds a Report for every detector for the week.
+func BuildReports(ctx context.Context, s ReportStore, week string) ([]Report, error) {
+	detectors, err := s.Detectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(detectors)
+
+	reports := make([]Report, 0, len(detectors))
+
+	for _, d := range detectors {
+		fb, err := s.WeekFeedback(ctx, d, week)
+		if err != nil {
+			return nil, err
+		}
+
+		r := Report{
+			Detector: d,
+			Week:     week,
+			Flagged:  len(fb),
+		}
+
+		for _, v := range fb {
+			switch v {
+			case Correct:
+				r.Correct++
+			case Incorrect:
+				r.Incorrect++
+			}
+		}
+
+		reports = append(reports, r)
+	}
+
+	return reports, nil
+}
+
+// FormatReports renders the reports as a Slack message.
+func FormatReports(week string, reports []Report) string {
+	if len(reports) == 0 {
+		return fmt.Sprintf("Moderation accuracy report for %s: no detectors have flagged anything.", week)
+	}
+
+	lines := make([]string, 0, len(reports)+1)
+	lines = append(lines, fmt.Sprintf("Moderation accuracy report for %s:", week))
+
+	for _, r := range reports {
+		lines = append(lines, r.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
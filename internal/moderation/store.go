@@ -0,0 +1,246 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisDetectorsKey       = "moderation:detectors"
+	redisDecisionKeyFormat  = "moderation:decision:%s"
+	redisWeekKeyFormat      = "moderation:week:%s:%s"
+	redisAlertKeyFormat     = "moderation:alert:%s:%s"
+	redisFeedbackKeyFormat  = "moderation:feedback:%s"
+	redisTestKey            = "moderation:test_key"
+	decisionRetentionPeriod = 60 * 24 * time.Hour
+)
+
+// Week returns the ISO 8601 week of t, like 2020-W07.
+func Week(t time.Time) string {
+	y, w := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", y, w)
+}
+
+// DefaultStore is a default implementation of the Store interface.
+type DefaultStore struct {
+	r *redis.Client
+}
+
+var _ Store = (*DefaultStore)(nil)
+
+// NewStore returns a new DefaultStore.
+func NewStore(rc *redis.Client) (*DefaultStore, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &DefaultStore{r: rc}, nil
+}
+
+// Record satisfies Store.
+func (s *DefaultStore) Record(ctx context.Context, d Decision) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	j, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("failed to marshal decision: %w", err)
+	}
+
+	id := d.ID()
+	wk := fmt.Sprintf(redisWeekKeyFormat, d.Detector, Week(d.Time))
+
+	pipe := s.r.TxPipeline()
+	pipe.SAdd(ctx, redisDetectorsKey, d.Detector)
+	pipe.Set(ctx, fmt.Sprintf(redisDecisionKeyFormat, id), string(j), decisionRetentionPeriod)
+	pipe.SAdd(ctx, wk, id)
+	pipe.Expire(ctx, wk, decisionRetentionPeriod)
+
+	if _, err = pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record decision %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// SetAlert satisfies Store.
+func (s *DefaultStore) SetAlert(ctx context.Context, channelID, alertTS, decisionID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Set(ctx, fmt.Sprintf(redisAlertKeyFormat, channelID, alertTS), decisionID, decisionRetentionPeriod)
+	if err := res.Err(); err != nil {
+		return fmt.Errorf("failed to set alert: %w", err)
+	}
+
+	return nil
+}
+
+// AlertDecision satisfies Store.
+func (s *DefaultStore) AlertDecision(ctx context.Context, channelID, alertTS string) (string, bool, error) {
+	select {
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, fmt.Sprintf(redisAlertKeyFormat, channelID, alertTS))
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return "", true, nil
+		}
+
+		return "", false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	return res.Val(), false, nil
+}
+
+// SetFeedback satisfies Store.
+func (s *DefaultStore) SetFeedback(ctx context.Context, decisionID string, v Verdict) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Set(ctx, fmt.Sprintf(redisFeedbackKeyFormat, decisionID), string(v), decisionRetentionPeriod)
+	if err := res.Err(); err != nil {
+		return fmt.Errorf("failed to set feedback: %w", err)
+	}
+
+	return nil
+}
+
+// Detectors returns the names of all detectors that have made decisions.
+func (s *DefaultStore) Detectors(ctx context.Context) ([]string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.SMembers(ctx, redisDetectorsKey)
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get detectors: %w", err)
+	}
+
+	return res.Val(), nil
+}
+
+// WeekFeedback returns the feedback for each decision the detector made in the
+// week. Decisions without feedback have an empty Verdict.
+func (s *DefaultStore) WeekFeedback(ctx context.Context, detector, week string) (map[string]Verdict, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.SMembers(ctx, fmt.Sprintf(redisWeekKeyFormat, detector, week))
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get decisions: %w", err)
+	}
+
+	ids := res.Val()
+	fb := make(map[string]Verdict, len(ids))
+
+	for _, id := range ids {
+		fres := s.r.Get(ctx, fmt.Sprintf(redisFeedbackKeyFormat, id))
+		if err := fres.Err(); err != nil {
+			if err == redis.Nil {
+				fb[id] = ""
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to get feedback for %s: %w", id, err)
+		}
+
+		fb[id] = Verdict(fres.Val())
+	}
+
+	return fb, nil
+}
+
+// PurgeUser deletes the decisions made about the user's messages, and the
+// moderators' feedback on them, returning how many decisions there were. If
+// dryRun is true, they're counted but not deleted. It satisfies purge.Store.
+func (s *DefaultStore) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	var cursor uint64
+	var found []Decision
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+			// noop
+		}
+
+		keys, next, err := s.r.Scan(ctx, cursor, fmt.Sprintf(redisDecisionKeyFormat, "*"), 100).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to SCAN redis keys: %w", err)
+		}
+
+		for _, key := range keys {
+			v, err := s.r.Get(ctx, key).Result()
+			if err != nil {
+				if err == redis.Nil {
+					continue
+				}
+
+				return 0, fmt.Errorf("failed to GET redis key: %w", err)
+			}
+
+			var d Decision
+
+			if err := json.Unmarshal([]byte(v), &d); err != nil {
+				return 0, fmt.Errorf("failed to unmarshal decision %s: %w", key, err)
+			}
+
+			if d.UserID == userID {
+				found = append(found, d)
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	if len(found) == 0 || dryRun {
+		return len(found), nil
+	}
+
+	pipe := s.r.TxPipeline()
+
+	for _, d := range found {
+		id := d.ID()
+		pipe.Del(ctx, fmt.Sprintf(redisDecisionKeyFormat, id), fmt.Sprintf(redisFeedbackKeyFormat, id))
+		pipe.SRem(ctx, fmt.Sprintf(redisWeekKeyFormat, d.Detector, Week(d.Time)), id)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to purge decisions about %s: %w", userID, err)
+	}
+
+	return len(found), nil
+}
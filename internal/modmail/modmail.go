@@ -0,0 +1,190 @@
+// Package modmail keeps track of the private conversations members start with
+// the moderators by DMing the bot, so replies on either side can be relayed to
+// the other. Each conversation is a thread in the member's DM with the bot, and
+// a thread in the moderators' channel.
+package modmail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/outbox"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisDMKeyFormat    = "modmail:dm:%s:%s"    // channel, thread ts
+	redisAdminKeyFormat = "modmail:admin:%s:%s" // channel, thread ts
+	redisTestKey        = "modmail:test_key"
+
+	// retention is how long after the last message a conversation is kept;
+	// replies after that aren't relayed.
+	retention = 30 * 24 * time.Hour
+)
+
+// Thread is a thread in a channel.
+type Thread struct {
+	ChannelID string `json:"channel_id"`
+	TS        string `json:"ts"`
+}
+
+// Conversation is a member's conversation with the moderators.
+type Conversation struct {
+	// UserID is the member who started it.
+	UserID string `json:"user_id"`
+
+	// DM is the thread in the member's DM with the bot.
+	DM Thread `json:"dm"`
+
+	// Admin is the thread in each of the moderators' channels it was sent
+	// to.
+	Admin []Thread `json:"admin"`
+}
+
+// Store stores the conversations.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Save records the conversation under each of its threads, or extends how long
+// it's kept if it already was. The messages are queued for delivery in the same
+// transaction.
+func (s *Store) Save(ctx context.Context, c Conversation, msgs ...outbox.Message) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation: %w", err)
+	}
+
+	pipe := s.r.TxPipeline()
+
+	pipe.Set(ctx, fmt.Sprintf(redisDMKeyFormat, c.DM.ChannelID, c.DM.TS), b, retention)
+
+	for _, t := range c.Admin {
+		pipe.Set(ctx, fmt.Sprintf(redisAdminKeyFormat, t.ChannelID, t.TS), b, retention)
+	}
+
+	if err := outbox.Enqueue(ctx, pipe, msgs...); err != nil {
+		return err
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save conversation for %s: %w", c.UserID, err)
+	}
+
+	return nil
+}
+
+// ByDM returns the conversation in the thread in a member's DM with the bot.
+// If found is false, there's no conversation in that thread.
+func (s *Store) ByDM(ctx context.Context, t Thread) (c Conversation, found bool, err error) {
+	return s.get(ctx, fmt.Sprintf(redisDMKeyFormat, t.ChannelID, t.TS))
+}
+
+// ByAdmin returns the conversation in the thread in a moderators' channel. If
+// found is false, there's no conversation in that thread.
+func (s *Store) ByAdmin(ctx context.Context, t Thread) (c Conversation, found bool, err error) {
+	return s.get(ctx, fmt.Sprintf(redisAdminKeyFormat, t.ChannelID, t.TS))
+}
+
+func (s *Store) get(ctx context.Context, key string) (Conversation, bool, error) {
+	select {
+	case <-ctx.Done():
+		return Conversation{}, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, key)
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return Conversation{}, false, nil
+		}
+
+		return Conversation{}, false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	var c Conversation
+
+	if err := json.Unmarshal([]byte(res.Val()), &c); err != nil {
+		return Conversation{}, false, fmt.Errorf("failed to unmarshal conversation: %w", err)
+	}
+
+	return c, true, nil
+}
+
+// PurgeUser deletes the conversations the user started, returning how many
+// there were. If dryRun is true, they're counted but not deleted. The messages
+// themselves are in Slack, and aren't deleted. It satisfies purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	var cursor uint64
+	var found []Conversation
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+			// noop
+		}
+
+		keys, next, err := s.r.Scan(ctx, cursor, fmt.Sprintf(redisDMKeyFormat, "*", "*"), 100).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to SCAN redis keys: %w", err)
+		}
+
+		for _, key := range keys {
+			c, ok, err := s.get(ctx, key)
+			if err != nil {
+				return 0, err
+			}
+
+			if ok && c.UserID == userID {
+				found = append(found, c)
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	if len(found) == 0 || dryRun {
+		return len(found), nil
+	}
+
+	pipe := s.r.TxPipeline()
+
+	for _, c := range found {
+		pipe.Del(ctx, fmt.Sprintf(redisDMKeyFormat, c.DM.ChannelID, c.DM.TS))
+
+		for _, t := range c.Admin {
+			pipe.Del(ctx, fmt.Sprintf(redisAdminKeyFormat, t.ChannelID, t.TS))
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to purge conversations of %s: %w", userID, err)
+	}
+
+	return len(found), nil
+}
@@ -0,0 +1,501 @@
+// Package notify routes notifications from the pollers and moderation
+// detectors to the Slack channels that should receive them, based on where
+// each notification came from, how important it is, and how verbose each
+// channel wants to be.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/digest"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+// Source is the component that sent a notification.
+type Source string
+
+const (
+	// Gerrit is for merged CLs.
+	Gerrit Source = "gerrit"
+
+	// GerritReviewed is for CLs approved for submission. It has no default
+	// route, see Router.AddRoute.
+	GerritReviewed Source = "gerrit_reviewed"
+
+	// GerritBackports is for CLs backporting fixes to release branches. It
+	// has no default route, see Router.AddRoute.
+	GerritBackports Source = "gerrit_backports"
+
+	// GoTime is for the GoTimeFM live show.
+	GoTime Source = "gotime"
+
+	// GoTimeStatus is for posts from the GoTimeFM Mastodon account. It's the
+	// same as Mastodon("gotime@changelog.social").
+	GoTimeStatus Source = "mastodon:gotime@changelog.social"
+
+	// Reddit is for r/golang posts upvoted past the score threshold. It has
+	// no default route, see Router.AddRoute.
+	Reddit Source = "reddit"
+
+	// Moderation is for moderation alerts and reports.
+	Moderation Source = "moderation"
+
+	// Events is for reminders about upcoming conferences and meetups. It has
+	// no default route, see Router.NotifyChannel.
+	Events Source = "events"
+
+	// Workshops is for upcoming GoBridge and GDI workshops. It has no
+	// default route, see Router.NotifyChannel.
+	Workshops Source = "workshops"
+
+	// Digest is for the weekly summary of announcements. It has no default
+	// route, see Router.NotifyChannel.
+	Digest Source = "digest"
+
+	// Usage is for the monthly report of how often each command is used.
+	Usage Source = "usage"
+
+	// Growth is for the weekly report of members joining and posting.
+	Growth Source = "growth"
+
+	// Scheduled is for the recurring posts moderators schedule. It has no
+	// default route, see Router.NotifyChannel.
+	Scheduled Source = "scheduled"
+
+	// Errors is for reports of bugs in the bot, like handler panics. It has
+	// no default route, see Router.NotifyChannel.
+	Errors Source = "errors"
+
+	// Deploys is for announcing new versions of the bot going live. It has
+	// no default route, see Router.NotifyChannel.
+	Deploys Source = "deploys"
+)
+
+// Mastodon returns the Source for posts from the Mastodon account, written as
+// username@server. Only GoTimeStatus has a default route, see
+// Router.AddRoute.
+func Mastodon(account string) Source {
+	return Source("mastodon:" + account)
+}
+
+// Severity is how important a notification is.
+type Severity int
+
+const (
+	// Debug is for notifications only useful when troubleshooting.
+	Debug Severity = iota
+
+	// Info is for routine notifications.
+	Info
+
+	// Important is for notifications that need someone's attention.
+	Important
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Debug:
+		return "debug"
+	case Info:
+		return "info"
+	case Important:
+		return "important"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Verbosity is how many notifications a channel wants to receive.
+type Verbosity int
+
+const (
+	// Normal channels receive Info and Important notifications.
+	Normal Verbosity = iota
+
+	// Quiet channels only receive Important notifications.
+	Quiet
+
+	// Verbose channels receive every notification.
+	Verbose
+)
+
+// ParseVerbosity parses the string representation of a Verbosity.
+func ParseVerbosity(s string) (Verbosity, error) {
+	switch strings.ToLower(s) {
+	case "normal":
+		return Normal, nil
+	case "quiet":
+		return Quiet, nil
+	case "verbose":
+		return Verbose, nil
+	default:
+		return 0, fmt.Errorf("unknown verbosity %q", s)
+	}
+}
+
+// Overrides converts the channel verbosity overrides from the configuration,
+// see config.N.Verbosity.
+func Overrides(m map[string]string) (map[string]Verbosity, error) {
+	overrides := make(map[string]Verbosity, len(m))
+
+	for id, s := range m {
+		v, err := ParseVerbosity(s)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", id, err)
+		}
+
+		overrides[id] = v
+	}
+
+	return overrides, nil
+}
+
+func (v Verbosity) String() string {
+	switch v {
+	case Normal:
+		return "normal"
+	case Quiet:
+		return "quiet"
+	case Verbose:
+		return "verbose"
+	default:
+		return fmt.Sprintf("Verbosity(%d)", int(v))
+	}
+}
+
+// allows returns whether a channel with this Verbosity wants a notification
+// with the Severity s.
+func (v Verbosity) allows(s Severity) bool {
+	switch v {
+	case Quiet:
+		return s >= Important
+	case Verbose:
+		return true
+	default:
+		return s >= Info
+	}
+}
+
+// Notification is a message to send to any channels routed for its Source.
+type Notification struct {
+	Source   Source
+	Severity Severity
+
+	// Summary describes the notification in logs.
+	Summary string
+
+	// Options are the message options used to build the Slack message.
+	Options []slack.MsgOption
+
+	// Digest, if set, is recorded in the announcement journal once the
+	// notification is sent, so that it's included in the weekly digest.
+	Digest *digest.Entry
+
+	// Key, if set, identifies what the notification is about, like a CL. It's
+	// claimed for each channel before sending, so retrying a notification
+	// that only reached some channels doesn't post it twice.
+	Key string
+}
+
+// Idempotency claims keys before actions are taken, generally implemented by
+// an *idempotency.Store.
+type Idempotency interface {
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, key string) error
+}
+
+// sentKeyTTL is how long a notification's Key is remembered for each channel.
+const sentKeyTTL = 30 * 24 * time.Hour
+
+// Journal records announcements for the weekly digest, generally implemented
+// by a *digest.Journal. Recording the same entry again doesn't add another.
+type Journal interface {
+	Record(ctx context.Context, e digest.Entry) error
+}
+
+// Delivery is a notification that was sent to a channel.
+type Delivery struct {
+	ChannelID string
+	TS        string
+}
+
+// Router sends notifications to the channels routed for their Source.
+type Router struct {
+	sc        *slack.Client
+	shadow    bool
+	routes    map[Source][]string
+	verbosity map[string]Verbosity
+	journal   Journal
+	sent      Idempotency
+	flags     Flags
+	feature   string
+	logger    zerolog.Logger
+}
+
+// Flags reports whether features are in shadow mode at runtime. It's generally
+// implemented by a *flags.Store.
+type Flags interface {
+	Shadow(feature string) bool
+}
+
+// New returns a *Router using the default routes. The verbosity overrides map
+// channel IDs to the Verbosity they should use instead of their default. In
+// shadow mode notifications are only logged, except moderation alerts, which
+// are sent to #gopherdev marked as shadowed.
+func New(sc *slack.Client, shadowMode bool, overrides map[string]Verbosity, logger zerolog.Logger) *Router {
+	r := &Router{
+		sc:        sc,
+		shadow:    shadowMode,
+		routes:    make(map[Source][]string),
+		verbosity: make(map[string]Verbosity),
+		logger:    logger,
+	}
+
+	for _, rt := range defaultRoutes {
+		r.routes[rt.source] = append(r.routes[rt.source], rt.channelID)
+	}
+
+	for id, v := range defaultVerbosity {
+		r.verbosity[id] = v
+	}
+
+	for id, v := range overrides {
+		r.verbosity[id] = v
+	}
+
+	return r
+}
+
+// SetJournal sets the Journal that notifications with a Digest entry are
+// recorded in. It's not safe to call once notifications are being sent.
+func (r *Router) SetJournal(j Journal) {
+	r.journal = j
+}
+
+// SetIdempotency sets where the Keys of sent notifications are recorded, so
+// they're not sent to the same channel twice. It's not safe to call once
+// notifications are being sent.
+func (r *Router) SetIdempotency(i Idempotency) {
+	r.sent = i
+}
+
+// SetFlags makes the Router consult f on each notification for whether
+// feature is in shadow mode, instead of the shadowMode given to New. It's not
+// safe to call once notifications are being sent.
+func (r *Router) SetFlags(f Flags, feature string) {
+	r.flags = f
+	r.feature = feature
+}
+
+func (r *Router) isShadow() bool {
+	if r.flags == nil {
+		return r.shadow
+	}
+
+	return r.flags.Shadow(r.feature)
+}
+
+// AddRoute sends the notifications from the Source to the channel too. It's
+// not safe to call once notifications are being sent.
+func (r *Router) AddRoute(s Source, channelID string) {
+	for _, id := range r.routes[s] {
+		if id == channelID {
+			return
+		}
+	}
+
+	r.routes[s] = append(r.routes[s], channelID)
+}
+
+// Channels returns the channels the notifications from the Source go to.
+func (r *Router) Channels(s Source) []string {
+	return r.routes[s]
+}
+
+// Notify sends the notification to each channel routed for its Source whose
+// verbosity allows it. Delivery to every channel is attempted, even if
+// sending to one of them fails.
+func (r *Router) Notify(ctx context.Context, n Notification) ([]Delivery, error) {
+	channels := r.routes[n.Source]
+	shadow := r.isShadow()
+
+	if id, ok := shadowRoutes[n.Source]; ok && shadow && len(channels) > 0 {
+		return r.notifyShadowed(ctx, id, channels, n)
+	}
+
+	if len(channels) == 0 {
+		r.logger.Warn().
+			Str("source", string(n.Source)).
+			Str("summary", n.Summary).
+			Msg("no routes for notification source")
+
+		return nil, nil
+	}
+
+	var deliveries []Delivery
+	var errs []string
+
+	for _, id := range channels {
+		d, sent, err := r.deliver(ctx, id, n, shadow)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", id, err))
+			continue
+		}
+
+		if sent {
+			deliveries = append(deliveries, d)
+		}
+	}
+
+	if len(deliveries) > 0 {
+		r.record(ctx, n)
+	}
+
+	if len(errs) > 0 {
+		return deliveries, fmt.Errorf("failed to send notification to %s", strings.Join(errs, "; "))
+	}
+
+	return deliveries, nil
+}
+
+// NotifyChannel sends the notification to the channel with this ID, instead
+// of the channels routed for its Source. This is for notifications going to
+// channels we only know by name, which are looked up at runtime. The
+// channel's verbosity and shadow mode are still respected.
+func (r *Router) NotifyChannel(ctx context.Context, channelID string, n Notification) ([]Delivery, error) {
+	d, sent, err := r.deliver(ctx, channelID, n, r.isShadow())
+	if err != nil {
+		return nil, fmt.Errorf("failed to send notification to %s: %w", channelID, err)
+	}
+
+	if !sent {
+		return nil, nil
+	}
+
+	r.record(ctx, n)
+
+	return []Delivery{d}, nil
+}
+
+// notifyShadowed sends the notification to the channel with this ID in shadow
+// mode, instead of the channels it's routed to, with an attachment marking it
+// as shadowed. It isn't recorded in the journal.
+func (r *Router) notifyShadowed(ctx context.Context, channelID string, routed []string, n Notification) ([]Delivery, error) {
+	mentions := make([]string, len(routed))
+	for i, id := range routed {
+		mentions[i] = "<#" + id + ">"
+	}
+
+	n.Options = append(n.Options[:len(n.Options):len(n.Options)], slack.MsgOptionAttachments(slack.Attachment{
+		Text: ":ghost: Shadow mode: this would have gone to " + strings.Join(mentions, ", ") + ".",
+	}))
+
+	d, sent, err := r.deliver(ctx, channelID, n, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send shadowed notification to %s: %w", channelID, err)
+	}
+
+	if !sent {
+		return nil, nil
+	}
+
+	return []Delivery{d}, nil
+}
+
+// record adds the notification's Digest entry to the journal, once it's sent
+// to a channel. Sending it to more channels records the same entry, which the
+// journal only keeps once. Failures are only logged, as the notification
+// itself was sent.
+func (r *Router) record(ctx context.Context, n Notification) {
+	if r.journal == nil || n.Digest == nil {
+		return
+	}
+
+	if err := r.journal.Record(ctx, *n.Digest); err != nil {
+		r.logger.Error().
+			Err(err).
+			Str("source", string(n.Source)).
+			Str("summary", n.Summary).
+			Msg("failed to record notification in journal")
+	}
+}
+
+// deliver sends the notification to a single channel, if its verbosity allows
+// it and shadow is false. sent is false if it was skipped.
+func (r *Router) deliver(ctx context.Context, id string, n Notification, shadow bool) (d Delivery, sent bool, err error) {
+	v := r.verbosity[id]
+
+	if !v.allows(n.Severity) {
+		r.logger.Debug().
+			Str("source", string(n.Source)).
+			Str("severity", n.Severity.String()).
+			Str("channel_id", id).
+			Str("verbosity", v.String()).
+			Msg("notification filtered by channel verbosity")
+
+		return Delivery{}, false, nil
+	}
+
+	if shadow {
+		r.logger.Info().
+			Bool("shadow_mode", true).
+			Str("source", string(n.Source)).
+			Str("severity", n.Severity.String()).
+			Str("channel_id", id).
+			Str("summary", n.Summary).
+			Msg("would send notification")
+
+		return Delivery{}, false, nil
+	}
+
+	var key string
+
+	if r.sent != nil && len(n.Key) > 0 {
+		key = fmt.Sprintf("notify:%s:%s", id, n.Key)
+
+		claimed, err := r.sent.Claim(ctx, key, sentKeyTTL)
+		if err != nil {
+			return Delivery{}, false, fmt.Errorf("failed to claim notification key: %w", err)
+		}
+
+		if !claimed {
+			r.logger.Info().
+				Str("source", string(n.Source)).
+				Str("channel_id", id).
+				Str("key", n.Key).
+				Msg("notification already sent to channel")
+
+			return Delivery{}, false, nil
+		}
+	}
+
+	_, ts, _, err := r.sc.SendMessageContext(ctx, id, n.Options...)
+	if err != nil {
+		if len(key) > 0 {
+			r.release(key)
+		}
+
+		return Delivery{}, false, err
+	}
+
+	return Delivery{ChannelID: id, TS: ts}, true, nil
+}
+
+// release frees a claimed key after sending failed, so the notification can
+// be retried. It doesn't use the notification's context, as that may be what
+// failed.
+func (r *Router) release(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := r.sent.Release(ctx, key); err != nil {
+		r.logger.Error().
+			Err(err).
+			Str("key", key).
+			Msg("failed to release notification key; it won't be retried")
+	}
+}
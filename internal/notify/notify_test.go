@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gobridge/gopherbot/internal/digest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+func TestVerbosity_allows(t *testing.T) {
+	tests := []struct {
+		v    Verbosity
+		s    Severity
+		want bool
+	}{
+		{Quiet, Debug, false},
+		{Quiet, Info, false},
+		{Quiet, Important, true},
+		{Normal, Debug, false},
+		{Normal, Info, true},
+		{Normal, Important, true},
+		{Verbose, Debug, true},
+		{Verbose, Info, true},
+		{Verbose, Important, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.v.allows(tt.s); got != tt.want {
+			t.Errorf("%s.allows(%s) = %t, want %t", tt.v, tt.s, got, tt.want)
+		}
+	}
+}
+
+// entrySet is a Journal keeping each entry once, like a *digest.Journal.
+type entrySet map[string]digest.Entry
+
+func (s entrySet) Record(_ context.Context, e digest.Entry) error {
+	if _, ok := s[e.Key()]; !ok {
+		s[e.Key()] = e
+	}
+
+	return nil
+}
+
+func TestRouter_NotifyChannel_digest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Form.Get("channel") == "CBROKEN" {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ok":true,"channel":"` + r.Form.Get("channel") + `","ts":"1588334400.000100"}`))
+	}))
+	defer srv.Close()
+
+	journal := make(entrySet)
+
+	r := New(slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/")), false, nil, zerolog.Nop())
+	r.SetJournal(journal)
+
+	n := Notification{
+		Severity: Important,
+		Options:  []slack.MsgOption{slack.MsgOptionText("GopherCon is next week", false)},
+		Digest:   &digest.Entry{Section: "Events", Title: "GopherCon", URL: "https://gophercon.com"},
+	}
+
+	if _, err := r.NotifyChannel(context.Background(), "CBROKEN", n); err == nil {
+		t.Fatal("NotifyChannel() error = <nil>")
+	}
+
+	if len(journal) != 0 {
+		t.Fatalf("journal has %d entries after a failed send, want 0", len(journal))
+	}
+
+	for _, id := range []string{"C1", "C2"} {
+		if _, err := r.NotifyChannel(context.Background(), id, n); err != nil {
+			t.Fatalf("NotifyChannel(%s) unexpected error: %v", id, err)
+		}
+	}
+
+	if len(journal) != 1 {
+		t.Fatalf("journal has %d entries, want 1", len(journal))
+	}
+}
+
+func TestRouter_Notify_shadow(t *testing.T) {
+	var posts []url.Values
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		posts = append(posts, r.Form)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"channel":"` + r.Form.Get("channel") + `","ts":"1588334400.000100"}`))
+	}))
+	defer srv.Close()
+
+	r := New(slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/")), true, nil, zerolog.Nop())
+
+	// only logged
+	ds, err := r.Notify(context.Background(), Notification{
+		Source:   Gerrit,
+		Severity: Info,
+		Options:  []slack.MsgOption{slack.MsgOptionText("CL 1234 merged", false)},
+	})
+	if err != nil {
+		t.Fatalf("Notify(Gerrit) unexpected error: %v", err)
+	}
+
+	if len(ds) != 0 || len(posts) != 0 {
+		t.Fatalf("Notify(Gerrit) sent %d messages, want 0", len(posts))
+	}
+
+	// sent to #gopherdev instead, marked as shadowed
+	ds, err = r.Notify(context.Background(), Notification{
+		Source:   Moderation,
+		Severity: Important,
+		Options:  []slack.MsgOption{slack.MsgOptionText("spam flagged a message", false)},
+	})
+	if err != nil {
+		t.Fatalf("Notify(Moderation) unexpected error: %v", err)
+	}
+
+	want := []Delivery{{ChannelID: gopherdevChannelID, TS: "1588334400.000100"}}
+	if diff := cmp.Diff(want, ds); diff != "" {
+		t.Fatalf("Notify(Moderation) deliveries mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(posts) != 1 {
+		t.Fatalf("Notify(Moderation) sent %d messages, want 1", len(posts))
+	}
+
+	if got := posts[0].Get("text"); got != "spam flagged a message" {
+		t.Errorf("text = %q, want %q", got, "spam flagged a message")
+	}
+
+	if got := posts[0].Get("attachments"); !strings.Contains(got, "Shadow mode") || !strings.Contains(got, moderatorsChannelID) {
+		t.Errorf("attachments = %s, want a shadow mode marker naming %s", got, moderatorsChannelID)
+	}
+}
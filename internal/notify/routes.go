@@ -0,0 +1,37 @@
+package notify
+
+// The channels notifications are routed to.
+const (
+	golangCLsChannelID  = "C2VU4UTFZ"   // #golang-cls
+	goTimeChannelID     = "C0F1752BB"   // #gotimefm
+	moderatorsChannelID = "G1L7RN06B"   // admin private channel
+	gopherdevChannelID  = "C013XC5SU21" // #gopherdev
+)
+
+type route struct {
+	source    Source
+	channelID string
+}
+
+// defaultRoutes maps each Source to the channels it notifies. A Source may be
+// routed to more than one channel.
+var defaultRoutes = []route{
+	{source: Gerrit, channelID: golangCLsChannelID},
+	{source: GoTime, channelID: goTimeChannelID},
+	{source: GoTimeStatus, channelID: goTimeChannelID},
+	{source: Moderation, channelID: moderatorsChannelID},
+	{source: Usage, channelID: moderatorsChannelID},
+	{source: Growth, channelID: moderatorsChannelID},
+}
+
+// shadowRoutes maps the Sources still sent in shadow mode, so what they'd say
+// can be checked, to the channel they're sent to instead of their routes.
+var shadowRoutes = map[Source]string{
+	Moderation: gopherdevChannelID,
+}
+
+// defaultVerbosity is the Verbosity of each channel. Channels not listed are
+// Normal.
+var defaultVerbosity = map[string]Verbosity{
+	moderatorsChannelID: Verbose,
+}
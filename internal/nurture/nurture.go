@@ -0,0 +1,300 @@
+// Package nurture keeps track of new members who haven't posted in a public
+// channel yet, so that those who still haven't a week after joining can be sent
+// one gentle follow-up with resources for getting started, and schedules the
+// follow-ups to the short welcome DM every new member gets. Members can opt out
+// of them, before or after they're sent.
+package nurture
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisPendingKey = "nurture:pending" // sorted set of user IDs, by join time
+	redisOptOutKey  = "nurture:opt_out"
+	redisTestKey    = "nurture:test_key"
+)
+
+const (
+	// FollowUpAfter is how long after joining a member who hasn't posted is
+	// followed up with.
+	FollowUpAfter = 7 * 24 * time.Hour
+
+	// FollowUpWithin is how long after joining a member can still be
+	// followed up with, if the follow-up was delayed, like by bgtasks being
+	// down. After that it's dropped, as it'd be more odd than helpful.
+	FollowUpWithin = 10 * 24 * time.Hour
+)
+
+// Action is what to do about a pending member.
+type Action int
+
+const (
+	// Wait means it's too early to follow up.
+	Wait Action = iota
+
+	// Send means the follow-up should be sent.
+	Send
+
+	// Drop means it's too late to follow up, and they should stop being
+	// tracked.
+	Drop
+)
+
+// Decide returns what to do about a member who joined at joined, and hasn't
+// posted since.
+func Decide(joined, now time.Time) Action {
+	age := now.Sub(joined)
+
+	switch {
+	case age < FollowUpAfter:
+		return Wait
+	case age > FollowUpWithin:
+		return Drop
+	default:
+		return Send
+	}
+}
+
+// Pending is a member who hasn't posted yet.
+type Pending struct {
+	UserID string
+	Joined time.Time
+}
+
+// Store stores the members who haven't posted yet, and those who opted out.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Track starts tracking whether the user, who joined at t, posts.
+func (s *Store) Track(ctx context.Context, userID string, t time.Time) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	z := redis.Z{Score: float64(t.Unix()), Member: userID}
+
+	if err := s.r.ZAddNX(ctx, redisPendingKey, z).Err(); err != nil {
+		return fmt.Errorf("failed to track %s: %w", userID, err)
+	}
+
+	return nil
+}
+
+// Seen records that the user posted, so they aren't followed up with. It's
+// called for every public message, and does nothing for members who aren't
+// being tracked.
+func (s *Store) Seen(ctx context.Context, userID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.ZRem(ctx, redisPendingKey, userID).Err(); err != nil {
+		return fmt.Errorf("failed to ZREM %s: %w", userID, err)
+	}
+
+	return nil
+}
+
+// Due returns up to n of the members who joined before before and haven't
+// posted, the earliest first.
+func (s *Store) Due(ctx context.Context, before time.Time, n int64) ([]Pending, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	zs, err := s.r.ZRangeByScoreWithScores(ctx, redisPendingKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(before.Unix(), 10),
+		Count: n,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending members: %w", err)
+	}
+
+	ps := make([]Pending, 0, len(zs))
+
+	for _, z := range zs {
+		id, ok := z.Member.(string)
+		if !ok {
+			continue
+		}
+
+		ps = append(ps, Pending{UserID: id, Joined: time.Unix(int64(z.Score), 0)})
+	}
+
+	return ps, nil
+}
+
+// Claim stops tracking the user, before they're followed up with, returning
+// false if they already weren't being tracked, in which case they shouldn't be
+// followed up with. This way a follow-up is never sent twice, even if sending
+// it times out.
+func (s *Store) Claim(ctx context.Context, userID string) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// noop
+	}
+
+	n, err := s.r.ZRem(ctx, redisPendingKey, userID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to ZREM %s: %w", userID, err)
+	}
+
+	return n > 0, nil
+}
+
+// OptedOut returns whether the user has opted out of follow-ups.
+func (s *Store) OptedOut(ctx context.Context, userID string) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// noop
+	}
+
+	ok, err := s.r.SIsMember(ctx, redisOptOutKey, userID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to SISMEMBER redis key: %w", err)
+	}
+
+	return ok, nil
+}
+
+// SetOptedOut sets whether the user has opted out of follow-ups. Opting out
+// also stops them being tracked, and unschedules their welcome follow-ups.
+func (s *Store) SetOptedOut(ctx context.Context, userID string, optedOut bool) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	var err error
+
+	if optedOut {
+		pipe := s.r.TxPipeline()
+		pipe.SAdd(ctx, redisOptOutKey, userID)
+		pipe.ZRem(ctx, redisPendingKey, userID)
+
+		for _, ws := range WelcomeSteps {
+			pipe.ZRem(ctx, redisWelcomeDueKey, Delivery{UserID: userID, Step: ws.Step}.member())
+		}
+
+		_, err = pipe.Exec(ctx)
+	} else {
+		err = s.r.SRem(ctx, redisOptOutKey, userID).Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update follow-up opt-out: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeUser stops tracking the user, unschedules their welcome follow-ups, and
+// deletes which were sent and whether they opted out, returning how many of
+// those were recorded. If dryRun is true, they're counted but not deleted. It
+// satisfies purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	pipe := s.r.Pipeline()
+
+	pending := pipe.ZScore(ctx, redisPendingKey, userID)
+	optedOut := pipe.SIsMember(ctx, redisOptOutKey, userID)
+	state := pipe.Exists(ctx, welcomeUserKey(userID))
+
+	due := make([]*redis.FloatCmd, 0, len(WelcomeSteps))
+	for _, ws := range WelcomeSteps {
+		due = append(due, pipe.ZScore(ctx, redisWelcomeDueKey, Delivery{UserID: userID, Step: ws.Step}.member()))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("failed to look up %s: %w", userID, err)
+	}
+
+	n := int(state.Val())
+
+	if pending.Err() == nil {
+		n++
+	}
+
+	if optedOut.Val() {
+		n++
+	}
+
+	for _, d := range due {
+		if d.Err() == nil {
+			n++
+		}
+	}
+
+	if n == 0 || dryRun {
+		return n, nil
+	}
+
+	tx := s.r.TxPipeline()
+	tx.ZRem(ctx, redisPendingKey, userID)
+	tx.SRem(ctx, redisOptOutKey, userID)
+	tx.Del(ctx, welcomeUserKey(userID))
+
+	for _, ws := range WelcomeSteps {
+		tx.ZRem(ctx, redisWelcomeDueKey, Delivery{UserID: userID, Step: ws.Step}.member())
+	}
+
+	if _, err := tx.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to purge %s: %w", userID, err)
+	}
+
+	return n, nil
+}
+
+// FollowUpMessage is the message new members who haven't posted are sent.
+const FollowUpMessage = `Hi there! :wave: It's been about a week since you joined the Gophers Slack, and I wanted to check in.
+
+If you're not sure where to start, <#C02A8LZKT> is the place for anyone new to Go, or programming in general, to ask questions and learn together. No question is too small. :simple_smile:
+
+Here are some resources for getting started:
+- A Tour of Go: <https://go.dev/tour/>
+- Go by Example: <https://gobyexample.com/>
+- Effective Go: <https://go.dev/doc/effective_go>
+
+You can also send me ` + "`newbie resources`" + ` for more, or ` + "`recommended channels`" + ` to find channels you might like.
+
+This is the last follow-up I'll send. If you'd rather I never message you like this, send me ` + "`followups off`" + `.`
@@ -0,0 +1,31 @@
+package nurture
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecide(t *testing.T) {
+	now := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		age  time.Duration
+		want Action
+	}{
+		{name: "just_joined", age: time.Hour, want: Wait},
+		{name: "almost_a_week", age: FollowUpAfter - time.Minute, want: Wait},
+		{name: "a_week", age: FollowUpAfter, want: Send},
+		{name: "delayed", age: FollowUpWithin - time.Minute, want: Send},
+		{name: "too_late", age: FollowUpWithin + time.Minute, want: Drop},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Decide(now.Add(-tt.age), now); got != tt.want {
+				t.Fatalf("Decide() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
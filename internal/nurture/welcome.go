@@ -0,0 +1,236 @@
+package nurture
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisWelcomeDueKey  = "nurture:welcome:due" // sorted set of user_id:step, by when each is due
+	redisWelcomeUserFmt = "nurture:welcome:%s"  // user ID; hash of each step's message and when it was sent
+
+	// welcomeStateTTL is how long after joining a member's welcome
+	// follow-ups, and which were sent, are kept.
+	welcomeStateTTL = 30 * 24 * time.Hour
+
+	// WelcomeLate is how late a welcome follow-up can still be sent, like if
+	// bgtasks was down. After that it's dropped.
+	WelcomeLate = 3 * 24 * time.Hour
+)
+
+// Step is one of the follow-ups to the short welcome DM, which between them
+// carry what used to be in one long welcome.
+type Step string
+
+const (
+	// ChannelsStep suggests channels to join.
+	ChannelsStep Step = "channels"
+
+	// AskingStep explains how to ask questions. Members who haven't posted
+	// by then are sent FollowUpMessage instead.
+	AskingStep Step = "asking"
+)
+
+// WelcomeSteps are the follow-ups to the welcome DM, in the order they're
+// sent, and how long after joining each is sent.
+var WelcomeSteps = []struct {
+	Step  Step
+	After time.Duration
+}{
+	{Step: ChannelsStep, After: 2 * 24 * time.Hour},
+	{Step: AskingStep, After: FollowUpAfter},
+}
+
+// Delivery is a welcome follow-up due to be sent to a member.
+type Delivery struct {
+	UserID string
+	Step   Step
+	Due    time.Time
+}
+
+func (d Delivery) member() string {
+	return d.UserID + ":" + string(d.Step)
+}
+
+func welcomeUserKey(userID string) string {
+	return fmt.Sprintf(redisWelcomeUserFmt, userID)
+}
+
+// ScheduleWelcome schedules the welcome follow-ups for the user, who joined at
+// joined, with the messages for each step. Steps without a message aren't
+// scheduled, and steps already scheduled aren't changed.
+func (s *Store) ScheduleWelcome(ctx context.Context, userID string, joined time.Time, messages map[Step]string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	key := welcomeUserKey(userID)
+
+	pipe := s.r.TxPipeline()
+
+	for _, ws := range WelcomeSteps {
+		msg, ok := messages[ws.Step]
+		if !ok {
+			continue
+		}
+
+		d := Delivery{UserID: userID, Step: ws.Step, Due: joined.Add(ws.After)}
+
+		pipe.HSetNX(ctx, key, string(ws.Step)+":message", msg)
+		pipe.ZAddNX(ctx, redisWelcomeDueKey, redis.Z{Score: float64(d.Due.Unix()), Member: d.member()})
+	}
+
+	pipe.ExpireAt(ctx, key, joined.Add(welcomeStateTTL))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to schedule welcome follow-ups for %s: %w", userID, err)
+	}
+
+	return nil
+}
+
+// DueWelcomes returns up to n of the welcome follow-ups due by now, the
+// earliest first.
+func (s *Store) DueWelcomes(ctx context.Context, now time.Time, n int64) ([]Delivery, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	zs, err := s.r.ZRangeByScoreWithScores(ctx, redisWelcomeDueKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.Unix(), 10),
+		Count: n,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due welcome follow-ups: %w", err)
+	}
+
+	ds := make([]Delivery, 0, len(zs))
+
+	for _, z := range zs {
+		m, ok := z.Member.(string)
+		if !ok {
+			continue
+		}
+
+		i := strings.LastIndexByte(m, ':')
+		if i < 0 {
+			continue
+		}
+
+		ds = append(ds, Delivery{UserID: m[:i], Step: Step(m[i+1:]), Due: time.Unix(int64(z.Score), 0)})
+	}
+
+	return ds, nil
+}
+
+// ClaimWelcome unschedules the follow-up before it's sent, returning its
+// message, and false if it was already unscheduled, in which case it shouldn't
+// be sent. This way a follow-up is never sent twice, even if sending it times
+// out.
+func (s *Store) ClaimWelcome(ctx context.Context, d Delivery) (string, bool, error) {
+	select {
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	default:
+		// noop
+	}
+
+	n, err := s.r.ZRem(ctx, redisWelcomeDueKey, d.member()).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to ZREM %s: %w", d.member(), err)
+	}
+
+	if n == 0 {
+		return "", false, nil
+	}
+
+	msg, err := s.r.HGet(ctx, welcomeUserKey(d.UserID), string(d.Step)+":message").Result()
+	if err != nil && err != redis.Nil {
+		return "", false, fmt.Errorf("failed to get welcome follow-up message: %w", err)
+	}
+
+	return msg, true, nil
+}
+
+// MarkWelcomeSent records that the follow-up was sent at the time.
+func (s *Store) MarkWelcomeSent(ctx context.Context, d Delivery, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.HSet(ctx, welcomeUserKey(d.UserID), string(d.Step)+":sent", at.Unix()).Err(); err != nil {
+		return fmt.Errorf("failed to mark welcome follow-up sent: %w", err)
+	}
+
+	return nil
+}
+
+// WelcomeSent returns when each of the user's welcome follow-ups was sent, by
+// step. Steps that weren't sent, or were too long ago, are missing.
+func (s *Store) WelcomeSent(ctx context.Context, userID string) (map[Step]time.Time, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	vals, err := s.r.HGetAll(ctx, welcomeUserKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get welcome follow-ups: %w", err)
+	}
+
+	sent := make(map[Step]time.Time)
+
+	for f, v := range vals {
+		if !strings.HasSuffix(f, ":sent") {
+			continue
+		}
+
+		ts, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		sent[Step(strings.TrimSuffix(f, ":sent"))] = time.Unix(ts, 0)
+	}
+
+	return sent, nil
+}
+
+// IsPending returns whether the user is being tracked because they haven't
+// posted yet.
+func (s *Store) IsPending(ctx context.Context, userID string) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// noop
+	}
+
+	err := s.r.ZScore(ctx, redisPendingKey, userID).Err()
+	if err == redis.Nil {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to ZSCORE %s: %w", userID, err)
+	}
+
+	return true, nil
+}
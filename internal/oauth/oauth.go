@@ -0,0 +1,252 @@
+// Package oauth stores the bot tokens granted when a workspace installs the
+// app through the Slack OAuth v2 flow, along with the state values used to
+// protect that flow from cross-site request forgery. The tokens are encrypted
+// at rest.
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/secrets"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisTeamKeyFormat  = "oauth:team:%s"
+	redisTeamKeyPattern = "oauth:team:*"
+	redisStateKeyFormat = "oauth:state:%s"
+	redisTestKey        = "oauth:test_key"
+
+	// stateTTL is how long a user has to approve the installation in Slack
+	// before they need to start over.
+	stateTTL = 10 * time.Minute
+)
+
+// Installation is the result of a workspace installing the app.
+type Installation struct {
+	TeamID       string    `json:"team_id"`
+	TeamName     string    `json:"team_name"`
+	EnterpriseID string    `json:"enterprise_id,omitempty"`
+	AppID        string    `json:"app_id"`
+	BotUserID    string    `json:"bot_user_id"`
+	BotToken     string    `json:"bot_token"`
+	Scope        string    `json:"scope"`
+	InstalledBy  string    `json:"installed_by"`
+	InstalledAt  time.Time `json:"installed_at"`
+}
+
+// Store is the Redis-backed installation store.
+type Store struct {
+	r *redis.Client
+	k *secrets.Keyring
+}
+
+// NewStore returns a new *Store, which encrypts bot tokens with the keyring.
+func NewStore(rc *redis.Client, k *secrets.Keyring) (*Store, error) {
+	if k == nil {
+		return nil, fmt.Errorf("keyring cannot be nil")
+	}
+
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc, k: k}, nil
+}
+
+// Put saves the installation, replacing any earlier one for the same team.
+func (s *Store) Put(ctx context.Context, in Installation) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if len(in.TeamID) == 0 {
+		return fmt.Errorf("installation missing team ID")
+	}
+
+	return s.put(ctx, in)
+}
+
+func (s *Store) put(ctx context.Context, in Installation) error {
+	token, err := s.k.Encrypt(in.BotToken)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt bot token: %w", err)
+	}
+
+	in.BotToken = token
+
+	j, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal installation: %w", err)
+	}
+
+	if err := s.r.Set(ctx, fmt.Sprintf(redisTeamKeyFormat, in.TeamID), j, 0).Err(); err != nil {
+		return fmt.Errorf("failed to SET redis key: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the team's installation.
+func (s *Store) Get(ctx context.Context, teamID string) (in Installation, notFound bool, err error) {
+	select {
+	case <-ctx.Done():
+		return Installation{}, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	in, _, notFound, err = s.get(ctx, fmt.Sprintf(redisTeamKeyFormat, teamID))
+
+	return in, notFound, err
+}
+
+// get returns the installation at the key, with its bot token decrypted, and
+// whether the token needs to be re-encrypted with the primary key.
+func (s *Store) get(ctx context.Context, key string) (in Installation, rotate, notFound bool, err error) {
+	res := s.r.Get(ctx, key)
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return Installation{}, false, true, nil
+		}
+
+		return Installation{}, false, false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(res.Val()), &in); err != nil {
+		return Installation{}, false, false, fmt.Errorf("failed to unmarshal installation at %s: %w", key, err)
+	}
+
+	// tokens saved before encryption was added are plain text
+	if !secrets.IsEncrypted(in.BotToken) {
+		return in, true, false, nil
+	}
+
+	rotate = s.k.NeedsRotation(in.BotToken)
+
+	token, err := s.k.Decrypt(in.BotToken)
+	if err != nil {
+		return Installation{}, false, false, fmt.Errorf("failed to decrypt bot token at %s: %w", key, err)
+	}
+
+	in.BotToken = token
+
+	return in, rotate, false, nil
+}
+
+// Reencrypt re-encrypts any bot tokens that aren't encrypted with the primary
+// key, returning how many were. Run it after adding a new primary key, before
+// removing the old one.
+func (s *Store) Reencrypt(ctx context.Context) (int, error) {
+	var cursor uint64
+	var count int
+
+	for {
+		select {
+		case <-ctx.Done():
+			return count, ctx.Err()
+		default:
+			// noop
+		}
+
+		keys, next, err := s.r.Scan(ctx, cursor, redisTeamKeyPattern, 100).Result()
+		if err != nil {
+			return count, fmt.Errorf("failed to SCAN redis keys: %w", err)
+		}
+
+		for _, key := range keys {
+			in, rotate, notFound, err := s.get(ctx, key)
+			if err != nil {
+				return count, err
+			}
+
+			if notFound || !rotate {
+				continue
+			}
+
+			if err := s.put(ctx, in); err != nil {
+				return count, err
+			}
+
+			count++
+		}
+
+		if cursor = next; cursor == 0 {
+			return count, nil
+		}
+	}
+}
+
+// Delete removes the team's installation, such as after the app is
+// uninstalled.
+func (s *Store) Delete(ctx context.Context, teamID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.Del(ctx, fmt.Sprintf(redisTeamKeyFormat, teamID)).Err(); err != nil {
+		return fmt.Errorf("failed to DEL redis key: %w", err)
+	}
+
+	return nil
+}
+
+// NewState returns a random state value to send with an authorization request.
+// It's valid for a single use, within stateTTL.
+func (s *Store) NewState(ctx context.Context) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+		// noop
+	}
+
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+
+	state := hex.EncodeToString(b)
+
+	if err := s.r.Set(ctx, fmt.Sprintf(redisStateKeyFormat, state), "1", stateTTL).Err(); err != nil {
+		return "", fmt.Errorf("failed to SET redis key: %w", err)
+	}
+
+	return state, nil
+}
+
+// ConsumeState returns whether the state was issued by NewState and not yet
+// used, and makes sure it can't be used again.
+func (s *Store) ConsumeState(ctx context.Context, state string) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// noop
+	}
+
+	if len(state) == 0 {
+		return false, nil
+	}
+
+	res := s.r.Del(ctx, fmt.Sprintf(redisStateKeyFormat, state))
+	if err := res.Err(); err != nil {
+		return false, fmt.Errorf("failed to DEL redis key: %w", err)
+	}
+
+	return res.Val() == 1, nil
+}
@@ -0,0 +1,347 @@
+// Package outbox delivers Slack messages on behalf of handlers that need them
+// sent together with a change to their state. The messages are queued in Redis
+// in the same transaction as the state change, and a Sender posts them
+// afterwards, retrying until they're delivered. A handler that fails partway
+// through a response therefore either changed nothing, or has every message
+// it meant to send queued.
+//
+// Delivery is at least once: each message has a dedupe key, and a message
+// whose key was delivered recently is dropped rather than posted again, but a
+// Sender that stops between posting a message and recording that it did will
+// post it again once its lease expires.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
+)
+
+const (
+	redisQueueKey       = "outbox:queue"    // ZSET of dedupe keys, scored by when they're due
+	redisMessageFormat  = "outbox:msg:%s"   // dedupe key
+	redisSentFormat     = "outbox:sent:%s"  // dedupe key
+	redisLeaseKeyFormat = "outbox:lease:%s" // dedupe key
+	redisTestKey        = "outbox:test_key"
+
+	// retention is how long an undelivered message is kept.
+	retention = 7 * 24 * time.Hour
+
+	// dedupeWindow is how long a delivered message's key is remembered, and
+	// messages enqueued again with it are dropped.
+	dedupeWindow = 24 * time.Hour
+
+	// lease is how long a Sender has to post a message before another one
+	// may try it.
+	lease = time.Minute
+
+	// MaxAttempts is how many times posting a message is tried before it's
+	// dropped.
+	MaxAttempts = 8
+
+	// pollInterval is how often the Sender looks for due messages.
+	pollInterval = time.Second
+
+	// batchSize is how many due messages are read each poll.
+	batchSize = 20
+)
+
+// Message is a Slack message to deliver.
+type Message struct {
+	// Key is the dedupe key, unique to what the message is for, like the
+	// event and destination it's in response to.
+	Key string `json:"key"`
+
+	ChannelID string `json:"channel_id"`
+
+	// ThreadTS is the thread to post in, if any.
+	ThreadTS string `json:"thread_ts,omitempty"`
+
+	Text string `json:"text"`
+
+	// Unfurl is whether links in the message are unfurled.
+	Unfurl bool `json:"unfurl,omitempty"`
+
+	// Attempts is how many times posting it failed.
+	Attempts int `json:"attempts,omitempty"`
+}
+
+func (m Message) options() []slack.MsgOption {
+	opts := []slack.MsgOption{slack.MsgOptionText(m.Text, false)}
+
+	if len(m.ThreadTS) > 0 {
+		opts = append(opts, slack.MsgOptionTS(m.ThreadTS))
+	}
+
+	if !m.Unfurl {
+		opts = append(opts, slack.MsgOptionDisableLinkUnfurl())
+	}
+
+	return opts
+}
+
+// Enqueue adds the commands queueing the messages to pipe, which should be a
+// transaction (from TxPipeline) with the caller's state changes. The messages
+// are due immediately. If a message with the same key is already queued, the
+// first one is kept.
+func Enqueue(ctx context.Context, pipe redis.Pipeliner, msgs ...Message) error {
+	now := float64(time.Now().UnixNano() / int64(time.Millisecond))
+
+	for _, m := range msgs {
+		if len(m.Key) == 0 {
+			return fmt.Errorf("message to %s has no dedupe key", m.ChannelID)
+		}
+
+		b, err := json.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message %s: %w", m.Key, err)
+		}
+
+		pipe.SetNX(ctx, fmt.Sprintf(redisMessageFormat, m.Key), b, retention)
+		pipe.ZAddNX(ctx, redisQueueKey, redis.Z{Score: now, Member: m.Key})
+	}
+
+	return nil
+}
+
+// Backoff returns how long to wait before trying to post a message again,
+// after it failed the given number of times: doubling from 5 seconds, up to
+// 10 minutes.
+func Backoff(attempts int) time.Duration {
+	const (
+		initial = 5 * time.Second
+		ceiling = 10 * time.Minute
+	)
+
+	d := initial
+
+	for i := 1; i < attempts; i++ {
+		d *= 2
+
+		if d >= ceiling {
+			return ceiling
+		}
+	}
+
+	return d
+}
+
+// permanentErrors are the Slack errors that retrying won't fix.
+var permanentErrors = map[string]struct{}{
+	"channel_not_found":      {},
+	"not_in_channel":         {},
+	"is_archived":            {},
+	"msg_too_long":           {},
+	"no_text":                {},
+	"restricted_action":      {},
+	"cannot_dm_bot":          {},
+	"user_disabled":          {},
+	"invalid_auth":           {},
+	"account_inactive":       {},
+	"token_revoked":          {},
+	"missing_scope":          {},
+	"not_allowed_token_type": {},
+}
+
+// Permanent returns whether posting failed in a way retrying won't fix. The
+// Slack client returns API errors as just their error code.
+func Permanent(err error) bool {
+	_, ok := permanentErrors[err.Error()]
+	return ok
+}
+
+// Poster is the part of *slack.Client used to deliver messages.
+type Poster interface {
+	PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+}
+
+// Sender delivers the queued messages.
+type Sender struct {
+	r      *redis.Client
+	s      Poster
+	logger zerolog.Logger
+}
+
+// NewSender returns a new *Sender.
+func NewSender(rc *redis.Client, s Poster, logger zerolog.Logger) (*Sender, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Sender{r: rc, s: s, logger: logger}, nil
+}
+
+// Run delivers messages as they come due, until the context is canceled. Any
+// number of Senders can run at once.
+func (s *Sender) Run(ctx context.Context) {
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		now := time.Now()
+
+		keys, err := s.r.ZRangeByScore(ctx, redisQueueKey, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   fmt.Sprintf("%d", now.UnixNano()/int64(time.Millisecond)),
+			Count: batchSize,
+		}).Result()
+		if err != nil {
+			s.logger.Error().
+				Err(err).
+				Msg("failed to read due messages")
+
+			continue
+		}
+
+		for _, key := range keys {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err := s.deliver(ctx, key); err != nil {
+				s.logger.Error().
+					Err(err).
+					Str("key", key).
+					Msg("failed to deliver message")
+			}
+		}
+	}
+}
+
+// deliver posts the message with the key, if no other Sender is.
+func (s *Sender) deliver(ctx context.Context, key string) error {
+	leaseKey := fmt.Sprintf(redisLeaseKeyFormat, key)
+
+	ok, err := s.r.SetNX(ctx, leaseKey, time.Now().Unix(), lease).Result()
+	if err != nil {
+		return fmt.Errorf("failed to take lease: %w", err)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	// give up the lease even if the context was canceled while delivering
+	defer func() { _ = s.r.Del(context.Background(), leaseKey).Err() }()
+
+	msgKey := fmt.Sprintf(redisMessageFormat, key)
+
+	sent, err := s.r.Exists(ctx, fmt.Sprintf(redisSentFormat, key)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to check if delivered: %w", err)
+	}
+
+	if sent > 0 {
+		return s.remove(ctx, key, msgKey)
+	}
+
+	raw, err := s.r.Get(ctx, msgKey).Result()
+	if err != nil {
+		if err == redis.Nil {
+			// it expired undelivered
+			return s.remove(ctx, key, msgKey)
+		}
+
+		return fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	var m Message
+
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		_ = s.remove(ctx, key, msgKey)
+		return fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	pctx, cancel := context.WithTimeout(ctx, lease/2)
+	_, _, err = s.s.PostMessageContext(pctx, m.ChannelID, m.options()...)
+	cancel()
+
+	if err == nil {
+		pipe := s.r.TxPipeline()
+		pipe.Set(ctx, fmt.Sprintf(redisSentFormat, key), time.Now().Unix(), dedupeWindow)
+		pipe.Del(ctx, msgKey)
+		pipe.ZRem(ctx, redisQueueKey, key)
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to record delivery: %w", err)
+		}
+
+		return nil
+	}
+
+	var rle *slack.RateLimitedError
+
+	if errors.As(err, &rle) {
+		// not the message's fault, so not an attempt
+		return s.reschedule(ctx, key, time.Now().Add(rle.RetryAfter))
+	}
+
+	m.Attempts++
+
+	if Permanent(err) || m.Attempts >= MaxAttempts {
+		s.logger.Error().
+			Err(err).
+			Str("key", key).
+			Str("channel_id", m.ChannelID).
+			Int("attempts", m.Attempts).
+			Msg("dropping undeliverable message")
+
+		return s.remove(ctx, key, msgKey)
+	}
+
+	s.logger.Warn().
+		Err(err).
+		Str("key", key).
+		Str("channel_id", m.ChannelID).
+		Int("attempts", m.Attempts).
+		Msg("failed to post message; will retry")
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	if err := s.r.Set(ctx, msgKey, b, retention).Err(); err != nil {
+		return fmt.Errorf("failed to record attempt: %w", err)
+	}
+
+	return s.reschedule(ctx, key, time.Now().Add(Backoff(m.Attempts)))
+}
+
+func (s *Sender) reschedule(ctx context.Context, key string, at time.Time) error {
+	err := s.r.ZAddXX(ctx, redisQueueKey, redis.Z{
+		Score:  float64(at.UnixNano() / int64(time.Millisecond)),
+		Member: key,
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to reschedule message: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Sender) remove(ctx context.Context, key, msgKey string) error {
+	pipe := s.r.TxPipeline()
+	pipe.Del(ctx, msgKey)
+	pipe.ZRem(ctx, redisQueueKey, key)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to remove message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 5 * time.Second},
+		{attempts: 2, want: 10 * time.Second},
+		{attempts: 4, want: 40 * time.Second},
+		{attempts: 7, want: 320 * time.Second},
+		{attempts: 8, want: 10 * time.Minute},
+		{attempts: 100, want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	tests := []struct {
+		err  string
+		want bool
+	}{
+		{err: "channel_not_found", want: true},
+		{err: "is_archived", want: true},
+		{err: "internal_error"},
+		{err: "context deadline exceeded"},
+	}
+
+	for _, tt := range tests {
+		if got := Permanent(errors.New(tt.err)); got != tt.want {
+			t.Errorf("Permanent(%q) = %t, want %t", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package docs indexes the sections of the Go language specification and
+// Effective Go, so that other components can link to the right anchor.
+package docs
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// Spec is the name of the Go language specification document.
+	Spec = "spec"
+
+	// EffectiveGo is the name of the Effective Go document.
+	EffectiveGo = "effective_go"
+)
+
+// documents are the documents we index, keyed by name.
+var documents = map[string]string{
+	Spec:        "https://go.dev/ref/spec",
+	EffectiveGo: "https://go.dev/doc/effective_go",
+}
+
+// Section is a single linkable section of a document.
+type Section struct {
+	Doc   string `json:"doc"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// Store represents the shape of the storage system.
+type Store interface {
+	// Put replaces all the stored sections with these.
+	Put(ctx context.Context, sections []Section) error
+}
+
+// Poller fetches and indexes the documents into the Store.
+type Poller struct {
+	store  Store
+	http   *http.Client
+	logger zerolog.Logger
+}
+
+// New returns a *Poller.
+func New(s Store, http *http.Client, logger zerolog.Logger) (*Poller, error) {
+	if s == nil {
+		return nil, fmt.Errorf("store cannot be nil")
+	}
+
+	return &Poller{
+		store:  s,
+		http:   http,
+		logger: logger,
+	}, nil
+}
+
+// Poll fetches each document, parses out its sections, and persists them. The
+// index is only updated if every document was fetched, so a partial outage
+// doesn't drop sections from the index.
+func (p *Poller) Poll(ctx context.Context) error {
+	var sections []Section
+
+	for name, u := range documents {
+		body, err := p.fetch(ctx, u)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", name, err)
+		}
+
+		s := parseSections(name, u, body)
+		if len(s) == 0 {
+			return fmt.Errorf("found no sections in %s", name)
+		}
+
+		sections = append(sections, s...)
+	}
+
+	p.logger.Debug().
+		Int("section_count", len(sections)).
+		Msg("persisting document sections")
+
+	if err := p.store.Put(ctx, sections); err != nil {
+		return fmt.Errorf("failed to persist sections: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Poller) fetch(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", "Gophers Slack bot")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got non-200 code: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	return body, nil
+}
+
+var (
+	headingRegex = regexp.MustCompile(`(?is)<h([2-4])[^>]*\sid="([^"]+)"[^>]*>(.*?)</h[2-4]>`)
+	tagRegex     = regexp.MustCompile(`<[^>]*>`)
+)
+
+// parseSections finds all of the h2-h4 headings with an id attribute in the
+// HTML body, and returns them as sections linking to u.
+func parseSections(doc, u string, body []byte) []Section {
+	matches := headingRegex.FindAllSubmatch(body, -1)
+
+	sections := make([]Section, 0, len(matches))
+	seen := make(map[string]struct{}, len(matches))
+
+	for _, m := range matches {
+		id := string(m[2])
+
+		title := tagRegex.ReplaceAllString(string(m[3]), "")
+		title = strings.Join(strings.Fields(html.UnescapeString(title)), " ")
+
+		if len(title) == 0 {
+			continue
+		}
+
+		if _, ok := seen[id]; ok {
+			continue
+		}
+
+		seen[id] = struct{}{}
+
+		sections = append(sections, Section{
+			Doc:   doc,
+			Title: title,
+			URL:   u + "#" + id,
+		})
+	}
+
+	return sections
+}
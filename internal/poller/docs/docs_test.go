@@ -0,0 +1,93 @@
+package docs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseSections(t *testing.T) {
+	body := []byte(`<html><body>
+<h2 id="Introduction">Introduction</h2>
+<p>text</p>
+<h3 id="Method_sets">Method <a href="#x">sets</a></h3>
+<h4 id="Go_statements"
+    class="x">Go&nbsp;statements</h4>
+<h3 id="Method_sets">Method sets (again)</h3>
+<h2 id="empty"></h2>
+<h5 id="too_deep">Too deep</h5>
+<h2>No ID</h2>
+</body></html>`)
+
+	want := []Section{
+		{Doc: Spec, Title: "Introduction", URL: "https://go.dev/ref/spec#Introduction"},
+		{Doc: Spec, Title: "Method sets", URL: "https://go.dev/ref/spec#Method_sets"},
+		{Doc: Spec, Title: "Go statements", URL: "https://go.dev/ref/spec#Go_statements"},
+	}
+
+	got := parseSections(Spec, "https://go.dev/ref/spec", body)
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("parseSections() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRank(t *testing.T) {
+	sections := []Section{
+		{Doc: EffectiveGo, Title: "Interfaces", URL: "eg#interfaces"},
+		{Doc: Spec, Title: "Interface types", URL: "spec#Interface_types"},
+		{Doc: EffectiveGo, Title: "Interfaces and other types", URL: "eg#interfaces_and_types"},
+		{Doc: Spec, Title: "Method sets", URL: "spec#Method_sets"},
+		{Doc: Spec, Title: "Go statements", URL: "spec#Go_statements"},
+		{Doc: EffectiveGo, Title: "Goroutines", URL: "eg#goroutines"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "exact",
+			query: "interfaces",
+			want:  []string{"eg#interfaces", "eg#interfaces_and_types"},
+		},
+		{
+			name:  "prefix_prefers_spec",
+			query: "interface",
+			want:  []string{"spec#Interface_types", "eg#interfaces", "eg#interfaces_and_types"},
+		},
+		{
+			name:  "multiple_words",
+			query: "method sets",
+			want:  []string{"spec#Method_sets"},
+		},
+		{
+			name:  "all_words_required",
+			query: "method types",
+		},
+		{
+			name:  "whole_word_beats_prefix",
+			query: "go",
+			want:  []string{"spec#Go_statements", "eg#goroutines"},
+		},
+		{
+			name:  "empty",
+			query: "  ",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range Rank(sections, tt.query) {
+				got = append(got, s.URL)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Rank(%q) mismatch (-want +got):\n%s", tt.query, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package docs
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// score is how well the section title matches the query words. Whole word
+// matches count more than prefix matches, an exact title match trumps
+// everything, and a title that doesn't match every query word scores zero.
+func score(title string, query []string) int {
+	tw := words(title)
+
+	if strings.Join(tw, " ") == strings.Join(query, " ") {
+		return 100
+	}
+
+	var total int
+
+	for _, q := range query {
+		best := 0
+
+		for _, w := range tw {
+			if w == q {
+				best = 2
+				break
+			}
+
+			if strings.HasPrefix(w, q) {
+				best = 1
+			}
+		}
+
+		if best == 0 {
+			return 0
+		}
+
+		total += best
+	}
+
+	return total
+}
+
+// Rank returns the sections matching query, best match first. Ties are broken
+// by preferring the spec, and then the shorter title.
+func Rank(sections []Section, query string) []Section {
+	qw := words(query)
+	if len(qw) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		Section
+		score int
+	}
+
+	var matches []scored
+
+	for _, s := range sections {
+		if n := score(s.Title, qw); n > 0 {
+			matches = append(matches, scored{Section: s, score: n})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+
+		if a.score != b.score {
+			return a.score > b.score
+		}
+
+		if a.Doc != b.Doc {
+			return a.Doc == Spec
+		}
+
+		if len(a.Title) != len(b.Title) {
+			return len(a.Title) < len(b.Title)
+		}
+
+		return a.URL < b.URL
+	})
+
+	ranked := make([]Section, len(matches))
+	for i, m := range matches {
+		ranked[i] = m.Section
+	}
+
+	return ranked
+}
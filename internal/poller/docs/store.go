@@ -0,0 +1,98 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKey     = "poller:docs:sections"
+	redisTestKey = "poller:docs:test_key"
+)
+
+// DefaultStore is a default implementation of the Store interface.
+type DefaultStore struct {
+	r *redis.Client
+}
+
+var _ Store = (*DefaultStore)(nil)
+
+// NewStore returns a new DefaultStore.
+func NewStore(rc *redis.Client) (*DefaultStore, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &DefaultStore{r: rc}, nil
+}
+
+// Put satisfies Store.
+func (s *DefaultStore) Put(ctx context.Context, sections []Section) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if len(sections) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(sections))
+
+	for _, sec := range sections {
+		j, err := json.Marshal(sec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal section %s: %w", sec.URL, err)
+		}
+
+		fields[sec.URL] = string(j)
+	}
+
+	pipe := s.r.TxPipeline()
+	pipe.Del(ctx, redisKey)
+	pipe.HMSet(ctx, redisKey, fields)
+	pipe.Expire(ctx, redisKey, 30*24*time.Hour)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set sections: %w", err)
+	}
+
+	return nil
+}
+
+// Search returns the stored sections matching query, best match first.
+func (s *DefaultStore) Search(ctx context.Context, query string) ([]Section, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.HGetAll(ctx, redisKey)
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get sections: %w", err)
+	}
+
+	sections := make([]Section, 0, len(res.Val()))
+
+	for k, v := range res.Val() {
+		var sec Section
+
+		if err := json.Unmarshal([]byte(v), &sec); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal section %s: %w", k, err)
+		}
+
+		sections = append(sections, sec)
+	}
+
+	return Rank(sections, query), nil
+}
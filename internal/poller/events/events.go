@@ -0,0 +1,205 @@
+// Package events polls a calendar feed of Go conferences and GoBridge events,
+// and sends reminders one week and one day before each of them starts.
+package events
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Event is a single conference, meetup, or workshop from the feed.
+type Event struct {
+	UID      string    `json:"uid"`
+	Title    string    `json:"title"`
+	URL      string    `json:"url"`
+	Location string    `json:"location"`
+	Start    time.Time `json:"start"`
+}
+
+// Reminder is which reminder is being sent for an event.
+type Reminder string
+
+const (
+	// WeekBefore is sent when the event starts within the next week.
+	WeekBefore Reminder = "week"
+
+	// DayBefore is sent when the event starts within the next day.
+	DayBefore Reminder = "day"
+)
+
+// dueReminder returns the reminder that should have been sent for an event
+// starting at start, or an empty string if none are due yet or the event has
+// already started.
+func dueReminder(start, now time.Time) Reminder {
+	until := start.Sub(now)
+
+	switch {
+	case until <= 0:
+		return ""
+	case until <= 24*time.Hour:
+		return DayBefore
+	case until <= 7*24*time.Hour:
+		return WeekBefore
+	default:
+		return ""
+	}
+}
+
+// NotifyFunc is called to send the reminder for an event.
+type NotifyFunc func(ctx context.Context, e Event, r Reminder) error
+
+// Store represents the shape of the storage system.
+type Store interface {
+	// Put replaces all the stored upcoming events with these.
+	Put(ctx context.Context, events []Event) error
+
+	// MarkReminded records that the reminder for the event was sent,
+	// returning false if it already had been. The record expires after ttl.
+	MarkReminded(ctx context.Context, uid string, r Reminder, ttl time.Duration) (bool, error)
+
+	// ClearReminded forgets that the reminder for the event was sent, so it's
+	// tried again on the next poll.
+	ClearReminded(ctx context.Context, uid string, r Reminder) error
+}
+
+// Poller fetches the feed, persists the upcoming events into the Store, and
+// sends the reminders that are due.
+type Poller struct {
+	store  Store
+	http   *http.Client
+	feed   string
+	logger zerolog.Logger
+	notify NotifyFunc
+}
+
+// New returns a *Poller for the feed at feedURL, which may be either an iCal
+// (.ics) or JSON feed.
+func New(s Store, http *http.Client, feedURL string, logger zerolog.Logger, notify NotifyFunc) (*Poller, error) {
+	if s == nil {
+		return nil, fmt.Errorf("store cannot be nil")
+	}
+
+	if len(feedURL) == 0 {
+		return nil, fmt.Errorf("feed URL cannot be empty")
+	}
+
+	if notify == nil {
+		return nil, fmt.Errorf("notify cannot be nil")
+	}
+
+	return &Poller{
+		store:  s,
+		http:   http,
+		feed:   feedURL,
+		logger: logger,
+		notify: notify,
+	}, nil
+}
+
+// Poll fetches the feed, persists the events that haven't started yet, and
+// sends any reminders that are due and weren't sent already.
+func (p *Poller) Poll(ctx context.Context) error {
+	body, err := p.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch events feed: %w", err)
+	}
+
+	all, err := Parse(body)
+	if err != nil {
+		return fmt.Errorf("failed to parse events feed: %w", err)
+	}
+
+	now := time.Now()
+
+	upcoming := make([]Event, 0, len(all))
+
+	for _, e := range all {
+		if e.Start.After(now) {
+			upcoming = append(upcoming, e)
+		}
+	}
+
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].Start.Before(upcoming[j].Start)
+	})
+
+	p.logger.Debug().
+		Int("event_count", len(all)).
+		Int("upcoming_count", len(upcoming)).
+		Msg("persisting upcoming events")
+
+	if err = p.store.Put(ctx, upcoming); err != nil {
+		return fmt.Errorf("failed to persist events: %w", err)
+	}
+
+	for _, e := range upcoming {
+		r := dueReminder(e.Start, now)
+		if len(r) == 0 {
+			continue
+		}
+
+		// keep the record around a little while after the event starts, so
+		// a feed with a slightly wrong start time can't cause a repeat
+		first, err := p.store.MarkReminded(ctx, e.UID, r, e.Start.Sub(now)+24*time.Hour)
+		if err != nil {
+			return fmt.Errorf("failed to mark event %s reminded: %w", e.UID, err)
+		}
+
+		if !first {
+			continue
+		}
+
+		p.logger.Info().
+			Str("event_uid", e.UID).
+			Str("event_title", e.Title).
+			Str("reminder", string(r)).
+			Msg("sending event reminder")
+
+		if err = p.notify(ctx, e, r); err != nil {
+			if cerr := p.store.ClearReminded(ctx, e.UID, r); cerr != nil {
+				p.logger.Error().
+					Err(cerr).
+					Str("event_uid", e.UID).
+					Msg("failed to clear reminder state; reminder will not be retried")
+			}
+
+			return fmt.Errorf("failed to send reminder for event %s: %w", e.UID, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Poller) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.feed, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", "Gophers Slack bot")
+	req.Header.Add("Accept", "text/calendar, application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got non-200 code: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	return body, nil
+}
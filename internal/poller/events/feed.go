@@ -0,0 +1,186 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Parse parses the events in an iCal or JSON feed. The JSON feed is an array
+// of objects in the shape of Event, with start in RFC 3339 format. Events
+// without a title or start time are skipped, and events without a UID are
+// given one based on their title and start time.
+func Parse(body []byte) ([]Event, error) {
+	body = bytes.TrimSpace(body)
+
+	var events []Event
+	var err error
+
+	if bytes.HasPrefix(body, []byte("BEGIN:VCALENDAR")) {
+		events, err = parseICal(body)
+	} else {
+		err = json.Unmarshal(body, &events)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	valid := events[:0]
+
+	for _, e := range events {
+		if len(e.Title) == 0 || e.Start.IsZero() {
+			continue
+		}
+
+		if len(e.UID) == 0 {
+			e.UID = e.Title + "@" + e.Start.UTC().Format(time.RFC3339)
+		}
+
+		valid = append(valid, e)
+	}
+
+	return valid, nil
+}
+
+// parseICal parses the VEVENT components of an iCal (RFC 5545) document. Only
+// the properties we need are parsed, and everything else is ignored.
+func parseICal(body []byte) ([]Event, error) {
+	var events []Event
+	var cur *Event
+
+	for _, line := range unfoldICal(body) {
+		name, params, value := splitICalLine(line)
+
+		switch name {
+		case "BEGIN":
+			if value == "VEVENT" {
+				cur = &Event{}
+			}
+
+		case "END":
+			if value == "VEVENT" && cur != nil {
+				events = append(events, *cur)
+				cur = nil
+			}
+
+		case "UID":
+			if cur != nil {
+				cur.UID = value
+			}
+
+		case "SUMMARY":
+			if cur != nil {
+				cur.Title = unescapeICal(value)
+			}
+
+		case "URL":
+			if cur != nil {
+				cur.URL = value
+			}
+
+		case "LOCATION":
+			if cur != nil {
+				cur.Location = unescapeICal(value)
+			}
+
+		case "DTSTART":
+			if cur == nil {
+				continue
+			}
+
+			t, err := parseICalTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse DTSTART of %q: %w", cur.UID, err)
+			}
+
+			cur.Start = t
+		}
+	}
+
+	return events, nil
+}
+
+// unfoldICal splits the document into lines, joining any that were folded
+// across multiple lines.
+func unfoldICal(body []byte) []string {
+	var lines []string
+
+	s := bufio.NewScanner(bytes.NewReader(body))
+	s.Buffer(make([]byte, 0, 4096), 1<<20)
+
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// splitICalLine splits a content line into its upper-cased name, its
+// parameters, and its value.
+func splitICalLine(line string) (name string, params map[string]string, value string) {
+	i := strings.IndexByte(line, ':')
+	if i == -1 {
+		return "", nil, ""
+	}
+
+	parts := strings.Split(line[:i], ";")
+	name = strings.ToUpper(parts[0])
+	value = line[i+1:]
+
+	for _, p := range parts[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		if params == nil {
+			params = make(map[string]string)
+		}
+
+		params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+
+	return name, params, value
+}
+
+var icalUnescaper = strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescapeICal(s string) string {
+	return icalUnescaper.Replace(s)
+}
+
+// parseICalTime parses a DATE or DATE-TIME value. Times without a TZID are
+// treated as UTC, as are all-day events.
+func parseICalTime(params map[string]string, value string) (time.Time, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		return time.Parse("20060102", value)
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+
+	loc := time.UTC
+
+	if tz, ok := params["TZID"]; ok {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q: %w", tz, err)
+		}
+
+		loc = l
+	}
+
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
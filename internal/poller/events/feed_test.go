@@ -0,0 +1,149 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want []Event
+		err  string
+	}{
+		{
+			name: "ical",
+			body: "BEGIN:VCALENDAR\r\n" +
+				"VERSION:2.0\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"UID:gophercon-2026@example.org\r\n" +
+				"SUMMARY:GopherCon\\, the \r\n" +
+				" big one\r\n" +
+				"LOCATION:Seattle\\; WA\r\n" +
+				"URL:https://gophercon.com\r\n" +
+				"DTSTART:20260901T160000Z\r\n" +
+				"END:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"UID:workshop@example.org\r\n" +
+				"SUMMARY:GoBridge workshop\r\n" +
+				"DTSTART;TZID=America/New_York:20261003T100000\r\n" +
+				"END:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"SUMMARY:All day\r\n" +
+				"DTSTART;VALUE=DATE:20261105\r\n" +
+				"END:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"UID:no-start@example.org\r\n" +
+				"SUMMARY:No start\r\n" +
+				"END:VEVENT\r\n" +
+				"END:VCALENDAR\r\n",
+			want: []Event{
+				{
+					UID:      "gophercon-2026@example.org",
+					Title:    "GopherCon, the big one",
+					URL:      "https://gophercon.com",
+					Location: "Seattle; WA",
+					Start:    time.Date(2026, 9, 1, 16, 0, 0, 0, time.UTC),
+				},
+				{
+					UID:   "workshop@example.org",
+					Title: "GoBridge workshop",
+					Start: time.Date(2026, 10, 3, 10, 0, 0, 0, ny),
+				},
+				{
+					UID:   "All day@2026-11-05T00:00:00Z",
+					Title: "All day",
+					Start: time.Date(2026, 11, 5, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name: "json",
+			body: `[
+				{"uid": "a", "title": "GopherCon EU", "url": "https://gophercon.eu", "location": "Berlin", "start": "2026-06-15T09:00:00+02:00"},
+				{"uid": "b", "title": "", "start": "2026-06-15T09:00:00Z"}
+			]`,
+			want: []Event{
+				{
+					UID:      "a",
+					Title:    "GopherCon EU",
+					URL:      "https://gophercon.eu",
+					Location: "Berlin",
+					Start:    time.Date(2026, 6, 15, 7, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name: "bad_ical_time_zone",
+			body: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;TZID=Nowhere/Special:20261003T100000\nEND:VEVENT\nEND:VCALENDAR\n",
+			err:  "unknown time zone",
+		},
+		{
+			name: "bad_json",
+			body: `{"events": []}`,
+			err:  "cannot unmarshal",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.body))
+			if len(tt.err) > 0 {
+				if err == nil {
+					t.Fatalf("Parse() error = <nil>, should contain %q", tt.err)
+				}
+
+				if !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Parse() error = %q, should contain %q", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+				t.Fatalf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_dueReminder(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		want  Reminder
+	}{
+		{name: "started", start: now.Add(-time.Minute)},
+		{name: "now", start: now},
+		{name: "hours", start: now.Add(3 * time.Hour), want: DayBefore},
+		{name: "one_day", start: now.Add(24 * time.Hour), want: DayBefore},
+		{name: "days", start: now.Add(3 * 24 * time.Hour), want: WeekBefore},
+		{name: "one_week", start: now.Add(7 * 24 * time.Hour), want: WeekBefore},
+		{name: "far_away", start: now.Add(30 * 24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dueReminder(tt.start, now); got != tt.want {
+				t.Fatalf("dueReminder() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
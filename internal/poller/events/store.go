@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKey            = "poller:events:upcoming"
+	redisRemindedPrefix = "poller:events:reminded:"
+	redisTestKey        = "poller:events:test_key"
+)
+
+// DefaultStore is a default implementation of the Store interface.
+type DefaultStore struct {
+	r *redis.Client
+}
+
+var _ Store = (*DefaultStore)(nil)
+
+// NewStore returns a new DefaultStore.
+func NewStore(rc *redis.Client) (*DefaultStore, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &DefaultStore{r: rc}, nil
+}
+
+// Put satisfies Store.
+func (s *DefaultStore) Put(ctx context.Context, events []Event) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	j, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal events: %w", err)
+	}
+
+	if err = s.r.Set(ctx, redisKey, j, 7*24*time.Hour).Err(); err != nil {
+		return fmt.Errorf("failed to set events: %w", err)
+	}
+
+	return nil
+}
+
+// Upcoming returns the stored events that haven't started yet, soonest first.
+func (s *DefaultStore) Upcoming(ctx context.Context) ([]Event, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	b, err := s.r.Get(ctx, redisKey).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	var events []Event
+	if err = json.Unmarshal(b, &events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal events: %w", err)
+	}
+
+	now := time.Now()
+	upcoming := events[:0]
+
+	for _, e := range events {
+		if e.Start.After(now) {
+			upcoming = append(upcoming, e)
+		}
+	}
+
+	return upcoming, nil
+}
+
+func remindedKey(uid string, r Reminder) string {
+	return redisRemindedPrefix + uid + ":" + string(r)
+}
+
+// MarkReminded satisfies Store.
+func (s *DefaultStore) MarkReminded(ctx context.Context, uid string, r Reminder, ttl time.Duration) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// noop
+	}
+
+	ok, err := s.r.SetNX(ctx, remindedKey(uid, r), time.Now().Unix(), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to SETNX redis key: %w", err)
+	}
+
+	return ok, nil
+}
+
+// ClearReminded satisfies Store.
+func (s *DefaultStore) ClearReminded(ctx context.Context, uid string, r Reminder) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.Del(ctx, remindedKey(uid, r)).Err(); err != nil {
+		return fmt.Errorf("failed to DEL redis key: %w", err)
+	}
+
+	return nil
+}
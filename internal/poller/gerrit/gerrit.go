@@ -8,14 +8,35 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"time"
+	"net/url"
 
 	"github.com/rs/zerolog"
 )
 
-const gerritURL = "https://go-review.googlesource.com/changes/?q=status:merged&O=12&n=100"
+const gerritURL = "https://go-review.googlesource.com/changes/?O=12&n=100&q="
 
-// CL represents a merged CL that we send to the subscriber of the poller.
+// Query is a Gerrit search whose new results the poller notifies about.
+type Query struct {
+	// Name identifies the query in the logs and Redis.
+	Name string
+
+	// Q is the search, in Gerrit's query syntax.
+	Q string
+}
+
+var (
+	// Merged is the CLs merged into any Go repository.
+	Merged = Query{Name: "merged", Q: "status:merged"}
+
+	// Reviewed is the open CLs approved for submission.
+	Reviewed = Query{Name: "reviewed", Q: "status:open label:Code-Review+2"}
+
+	// Backports is the open CLs backporting fixes to release branches.
+	Backports = Query{Name: "backports", Q: "status:open hashtag:backport"}
+)
+
+// CL represents a CL matching the query that we send to the subscriber of the
+// poller.
 type CL struct {
 	Project         string `json:"project"`
 	ChangeID        string `json:"change_id"`
@@ -53,54 +74,49 @@ func (cl *CL) Message() string {
 // future.
 type NotifyFunc func(context.Context, CL) error
 
-// Store represents the shape of the storage system.
+// Store represents the shape of the storage system, which keeps the CLs a
+// query has notified about.
 type Store interface {
-	Get(ctx context.Context) (id int64, notFound bool, err error)
-	Put(ctx context.Context, lastID int64) error
+	// Seen returns whether the query has notified about each of the CLs.
+	// If notFound is true, it has never notified about any.
+	Seen(ctx context.Context, numbers []int64) (seen []bool, notFound bool, err error)
+
+	// Put records that the query has notified about the CLs.
+	Put(ctx context.Context, numbers ...int64) error
 }
 
-// Gerrit tracks merged CLs.
+// Gerrit tracks the CLs matching a Query.
 type Gerrit struct {
 	store  Store
 	http   *http.Client
 	logger zerolog.Logger
+	query  Query
 	notify NotifyFunc
-
-	lastID int64
 }
 
 // ErrNotFound should be returned by Store implementations when CL number
 // doesn't exist.
 var ErrNotFound = errors.New("CL not found")
 
-// New creates an initializes an instance of Gerrit.
-func New(s Store, http *http.Client, logger zerolog.Logger, notify NotifyFunc) (*Gerrit, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-
-	lastID, notFound, err := s.Get(ctx)
-
-	cancel()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get latest ID: %w", err)
-	}
-
-	if notFound {
-		lastID = -1
+// New creates an initializes an instance of Gerrit, polling for the CLs
+// matching q.
+func New(s Store, http *http.Client, logger zerolog.Logger, q Query, notify NotifyFunc) (*Gerrit, error) {
+	if len(q.Name) == 0 || len(q.Q) == 0 {
+		return nil, errors.New("query must have a name and a search")
 	}
 
 	return &Gerrit{
 		store:  s,
 		http:   http,
-		logger: logger,
+		logger: logger.With().Str("query", q.Name).Logger(),
+		query:  q,
 		notify: notify,
-		lastID: lastID,
 	}, nil
 }
 
-// Poll checks for new merged CLs and calls notify for each CL.
+// Poll checks for CLs newly matching the query and calls notify for each CL.
 func (g *Gerrit) Poll(ctx context.Context) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", gerritURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", gerritURL+url.QueryEscape(g.query.Q), nil)
 	if err != nil {
 		return err
 	}
@@ -133,32 +149,47 @@ func (g *Gerrit) Poll(ctx context.Context) error {
 		return fmt.Errorf("failed to unmarshal JSON body: %w", err)
 	}
 
-	// if g.lastID is -1, we have zero state
-	// so grab only the latest CL and use that one
-	if g.lastID == -1 && len(cls) > 0 {
-		cls = cls[:1]
+	numbers := make([]int64, len(cls))
+	for i, cl := range cls {
+		numbers[i] = cl.Number
+	}
+
+	seen, notFound, err := g.store.Seen(ctx, numbers)
+	if err != nil {
+		return fmt.Errorf("failed to get the CLs already notified: %w", err)
+	}
+
+	// with zero state, record the CLs matching now without notifying, so
+	// only the ones after are
+	if notFound {
+		if len(numbers) == 0 {
+			return nil
+		}
 
 		g.logger.Info().
-			Int64("last_id", cls[0].Number).
-			Msg("initializing last CL ID to latest")
-
-	} else {
-		// The change output is sorted by the last update time, most recently updated to oldest updated.
-		// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#list-changes
-		for i, cl := range cls {
-			if cl.Number == g.lastID {
-				cls = cls[:i]
-				break
-			}
+			Int("cl_count", len(numbers)).
+			Msg("initializing CLs seen to the current matches")
+
+		if err = g.store.Put(ctx, numbers...); err != nil {
+			return fmt.Errorf("failed to persist CLs: %w", err)
 		}
+
+		return nil
 	}
 
+	// The change output is sorted by the last update time, most recently
+	// updated to oldest updated, so notify oldest first.
+	// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#list-changes
 	for i := len(cls) - 1; i >= 0; i-- {
+		if seen[i] {
+			continue
+		}
+
 		cl := cls[i]
 
 		g.logger.Trace().
 			Int64("cl_num", cl.Number).
-			Msg("sending notification of CL merged")
+			Msg("sending notification of CL")
 
 		if err = g.notify(ctx, cl); err != nil {
 			return fmt.Errorf("notification failed: %w", err)
@@ -167,8 +198,6 @@ func (g *Gerrit) Poll(ctx context.Context) error {
 		if err = g.store.Put(ctx, cl.Number); err != nil {
 			return fmt.Errorf("failed to persist CL %d: %w", cl.Number, err)
 		}
-
-		g.lastID = cl.Number
 	}
 
 	return nil
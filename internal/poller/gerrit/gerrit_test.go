@@ -0,0 +1,133 @@
+package gerrit
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rs/zerolog"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+type memStore struct {
+	seen map[int64]bool
+}
+
+func (s *memStore) Seen(_ context.Context, numbers []int64) ([]bool, bool, error) {
+	if s.seen == nil {
+		return nil, true, nil
+	}
+
+	seen := make([]bool, len(numbers))
+	for i, n := range numbers {
+		seen[i] = s.seen[n]
+	}
+
+	return seen, false, nil
+}
+
+func (s *memStore) Put(_ context.Context, numbers ...int64) error {
+	if s.seen == nil {
+		s.seen = make(map[int64]bool)
+	}
+
+	for _, n := range numbers {
+		s.seen[n] = true
+	}
+
+	return nil
+}
+
+func TestGerrit_Poll(t *testing.T) {
+	var body, query string
+
+	c := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		query = r.URL.Query().Get("q")
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(")]}'\n" + body)),
+		}, nil
+	})}
+
+	var notified []int64
+
+	store := &memStore{}
+
+	g, err := New(store, c, zerolog.Nop(), Backports, func(_ context.Context, cl CL) error {
+		notified = append(notified, cl.Number)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first poll only records what matches
+	body = `[{"_number": 2}, {"_number": 1}]`
+
+	if err := g.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() unexpected error: %v", err)
+	}
+
+	if query != "status:open hashtag:backport" {
+		t.Errorf("query = %q, want the Backports query", query)
+	}
+
+	if len(notified) != 0 {
+		t.Fatalf("first Poll() notified %v, want none", notified)
+	}
+
+	// CL 1 was updated, and two more match; CL 2 no longer does
+	body = `[{"_number": 4}, {"_number": 1}, {"_number": 3}]`
+
+	if err := g.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int64{3, 4}, notified); diff != "" {
+		t.Fatalf("Poll() notified differs (-want +got):\n%s", diff)
+	}
+}
+
+func Test_seenBefore(t *testing.T) {
+	tests := []struct {
+		name    string
+		numbers []int64
+		lastID  int64
+		want    []bool
+	}{
+		{
+			name:    "new_merges",
+			numbers: []int64{5, 4, 3, 2},
+			lastID:  3,
+			want:    []bool{false, false, true, true},
+		},
+		{
+			name:    "none_new",
+			numbers: []int64{3, 2},
+			lastID:  3,
+			want:    []bool{true, true},
+		},
+		{
+			name:    "too_old",
+			numbers: []int64{5, 4},
+			lastID:  1,
+			want:    []bool{true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, seenBefore(tt.numbers, tt.lastID)); diff != "" {
+				t.Fatalf("seenBefore() differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -3,69 +3,134 @@ package gerrit
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
-	"github.com/go-redis/redis"
+	"github.com/redis/go-redis/v9"
 )
 
 const (
-	redisKey     = "poller:gerrit:last_id"
-	redisTestKey = "poller:gerrit:test_key"
+	redisSeenKeyFormat = "poller:gerrit:%s:seen" // query name; sorted set of CL numbers by when they were notified
+	redisLegacyKey     = "poller:gerrit:last_id" // the last merged CL, from before queries kept the CLs seen
+	redisTestKey       = "poller:gerrit:test_key"
+
+	// seenTTL is how long a CL is remembered after it's notified about.
+	seenTTL = 31 * 24 * time.Hour
 )
 
 // DefaultStore is a default implementation of the Store interface.
 type DefaultStore struct {
-	r *redis.Client
+	r      *redis.Client
+	key    string
+	legacy bool
 }
 
 var _ Store = (*DefaultStore)(nil)
 
-// NewStore returns a new DefaultStore.
-func NewStore(rc *redis.Client) (*DefaultStore, error) {
-	res := rc.Set(redisTestKey, "foobar", 1*time.Second)
+// NewStore returns a new DefaultStore, for the CLs the query notifies about.
+func NewStore(rc *redis.Client, q Query) (*DefaultStore, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
 
 	if err := res.Err(); err != nil {
 		return nil, fmt.Errorf("failed to write to redis: %w", err)
 	}
 
-	return &DefaultStore{r: rc}, nil
+	return &DefaultStore{
+		r:      rc,
+		key:    fmt.Sprintf(redisSeenKeyFormat, q.Name),
+		legacy: q == Merged,
+	}, nil
 }
 
-// Get satisfies Store.
-func (s *DefaultStore) Get(ctx context.Context) (int64, bool, error) {
+// Seen satisfies Store.
+func (s *DefaultStore) Seen(ctx context.Context, numbers []int64) ([]bool, bool, error) {
 	select {
 	case <-ctx.Done():
-		return 0, false, ctx.Err()
+		return nil, false, ctx.Err()
 	default:
 		// noop
 	}
 
-	res := s.r.Get(redisKey)
-	if err := res.Err(); err != nil {
-		if err == redis.Nil {
-			return 0, true, nil
+	var exists *redis.IntCmd
+
+	scores := make([]*redis.FloatCmd, len(numbers))
+
+	_, err := s.r.Pipelined(ctx, func(p redis.Pipeliner) error {
+		exists = p.Exists(ctx, s.key)
+
+		for i, n := range numbers {
+			scores[i] = p.ZScore(ctx, s.key, strconv.FormatInt(n, 10))
 		}
 
-		return 0, false, fmt.Errorf("failed to GET redis key: %w", err)
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, false, fmt.Errorf("failed to ZSCORE redis key: %w", err)
 	}
 
-	select {
-	case <-ctx.Done():
-		return 0, false, ctx.Err()
-	default:
-		// noop
+	if exists.Val() == 0 {
+		if s.legacy {
+			return s.seenLegacy(ctx, numbers)
+		}
+
+		return nil, true, nil
 	}
 
-	i64, err := res.Int64()
+	seen := make([]bool, len(numbers))
+
+	for i, c := range scores {
+		switch err := c.Err(); err {
+		case nil:
+			seen[i] = true
+		case redis.Nil:
+			// not seen
+		default:
+			return nil, false, fmt.Errorf("failed to ZSCORE redis key: %w", err)
+		}
+	}
+
+	return seen, false, nil
+}
+
+// seenLegacy returns the merged CLs seen according to the last one notified
+// about, kept before the CLs seen were.
+func (s *DefaultStore) seenLegacy(ctx context.Context, numbers []int64) ([]bool, bool, error) {
+	lastID, err := s.r.Get(ctx, redisLegacyKey).Int64()
 	if err != nil {
-		return 0, false, fmt.Errorf("key found, but was not int64: %w", err)
+		if err == redis.Nil {
+			return nil, true, nil
+		}
+
+		return nil, false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	return seenBefore(numbers, lastID), false, nil
+}
+
+// seenBefore returns which of the CLs, most recently merged first, are lastID
+// or were merged before it. If lastID isn't one of them, it's too old to tell
+// which are new, so they all were.
+func seenBefore(numbers []int64, lastID int64) []bool {
+	seen := make([]bool, len(numbers))
+
+	found := false
+
+	for i, n := range numbers {
+		found = found || n == lastID
+		seen[i] = found
 	}
 
-	return i64, false, nil
+	if !found {
+		for i := range seen {
+			seen[i] = true
+		}
+	}
+
+	return seen
 }
 
-// Put satisfies Store.
-func (s *DefaultStore) Put(ctx context.Context, id int64) error {
+// Put satisfies Store. CLs are forgotten 31 days after they're put.
+func (s *DefaultStore) Put(ctx context.Context, numbers ...int64) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -73,11 +138,25 @@ func (s *DefaultStore) Put(ctx context.Context, id int64) error {
 		// noop
 	}
 
-	// set for 31 days
-	res := s.r.Set(redisKey, id, 31*24*time.Hour)
+	if len(numbers) == 0 {
+		return nil
+	}
 
-	if err := res.Err(); err != nil {
-		return fmt.Errorf("failed to set last ID %d: %w", id, err)
+	now := time.Now()
+
+	members := make([]redis.Z, len(numbers))
+	for i, n := range numbers {
+		members[i] = redis.Z{Score: float64(now.Unix()), Member: strconv.FormatInt(n, 10)}
+	}
+
+	_, err := s.r.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZAdd(ctx, s.key, members...)
+		p.ZRemRangeByScore(ctx, s.key, "-inf", strconv.FormatInt(now.Add(-seenTTL).Unix(), 10))
+		p.Expire(ctx, s.key, seenTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record CLs %v: %w", numbers, err)
 	}
 
 	return nil
@@ -2,31 +2,30 @@ package gotime
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"time"
 
+	"github.com/gobridge/gopherbot/internal/changelog"
 	"github.com/rs/zerolog"
 )
 
-// Store represents the shape of the storage system.
+// Store represents the shape of the storage system: when the show last went
+// live.
 type Store interface {
 	Get(ctx context.Context) (id int64, notFound bool, err error)
 	Put(ctx context.Context, lastID int64) error
 }
 
-// NotifyFunc represents the function signature the poller notifies on a new
-// item. If error is not nil, the item will be retried at some point in the
-// future.
-type NotifyFunc func(context.Context) error
+// NotifyFunc represents the function signature the poller notifies on the
+// show going live, with the episode being recorded. If error is not nil, the
+// item will be retried at some point in the future.
+type NotifyFunc func(context.Context, changelog.Episode) error
 
 // GoTime tracks when it's Go Time!
 type GoTime struct {
 	logger            zerolog.Logger
 	store             Store
-	http              *http.Client
+	changelog         *changelog.Client
 	notify            NotifyFunc
 	startTimeVariance time.Duration
 
@@ -40,8 +39,8 @@ type GoTime struct {
 // because the current changelog APIs return whether any show is streaming
 // rather thahn GoTime specifically.
 //
-// notify is called when streaming starts. notify should return true when a successful.
-func New(s Store, c *http.Client, logger zerolog.Logger, startTimeVariance time.Duration, notify NotifyFunc) (*GoTime, error) {
+// notify is called when streaming starts.
+func New(s Store, c *changelog.Client, logger zerolog.Logger, startTimeVariance time.Duration, notify NotifyFunc) (*GoTime, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -64,7 +63,7 @@ func New(s Store, c *http.Client, logger zerolog.Logger, startTimeVariance time.
 	return &GoTime{
 		logger:            logger,
 		store:             s,
-		http:              c,
+		changelog:         c,
 		notify:            notify,
 		startTimeVariance: startTimeVariance,
 		lastNotified:      t,
@@ -88,35 +87,29 @@ func (gt *GoTime) Poll(ctx context.Context) error {
 		return nil
 	}
 
-	var status struct {
-		Streaming bool
-	}
-	err := gt.get(ctx, "https://changelog.com/live/status", &status)
+	streaming, err := gt.changelog.Streaming(ctx)
 	if err != nil {
 		return err
 	}
 
-	if !status.Streaming {
+	if !streaming {
 		return nil
 	}
 
-	var countdown struct {
-		Data time.Time
-	}
-	err = gt.get(ctx, "https://changelog.com/slack/countdown/gotime", &countdown)
+	next, err := gt.changelog.NextEpisode(ctx, changelog.GoTime)
 	if err != nil {
 		return err
 	}
 
-	nextScheduled := countdown.Data
-	if now.Before(nextScheduled.Add(-gt.startTimeVariance)) || now.After(nextScheduled.Add(gt.startTimeVariance)) {
+	if now.Before(next.At.Add(-gt.startTimeVariance)) || now.After(next.At.Add(gt.startTimeVariance)) {
 		return nil
 	}
 
 	gt.logger.Trace().
+		Str("title", next.Title).
 		Msg("sending notification that it's Go Time")
 
-	if err := gt.notify(ctx); err != nil {
+	if err := gt.notify(ctx, next); err != nil {
 		return fmt.Errorf("Go Time notification failed: %w", err)
 	}
 
@@ -128,34 +121,3 @@ func (gt *GoTime) Poll(ctx context.Context) error {
 
 	return nil
 }
-
-// get makes an HTTP request to url and unmarshals the JSON response into i.
-func (gt *GoTime) get(ctx context.Context, url string, i interface{}) error {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		panic(err)
-	}
-	req = req.WithContext(ctx)
-
-	resp, err := gt.http.Do(req)
-	if err != nil {
-		return fmt.Errorf("making http request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("non-200 status code: %d - %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading response body: %v", err)
-	}
-
-	err = json.Unmarshal(body, i)
-	if err != nil {
-		return fmt.Errorf("unmarshaling response: %s", err)
-	}
-
-	return nil
-}
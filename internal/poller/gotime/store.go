@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/go-redis/redis"
+	"github.com/redis/go-redis/v9"
 )
 
 const (
@@ -22,7 +22,7 @@ var _ Store = (*DefaultStore)(nil)
 
 // NewStore returns a new DefaultStore.
 func NewStore(rc *redis.Client) (*DefaultStore, error) {
-	res := rc.Set(redisTestKey, "foobar", 1*time.Second)
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
 
 	if err := res.Err(); err != nil {
 		return nil, fmt.Errorf("failed to write to redis: %w", err)
@@ -40,7 +40,7 @@ func (s *DefaultStore) Get(ctx context.Context) (int64, bool, error) {
 		// noop
 	}
 
-	res := s.r.Get(redisKey)
+	res := s.r.Get(ctx, redisKey)
 	if err := res.Err(); err != nil {
 		if err == redis.Nil {
 			return 0, true, nil
@@ -74,7 +74,7 @@ func (s *DefaultStore) Put(ctx context.Context, id int64) error {
 	}
 
 	// set for 31 days
-	res := s.r.Set(redisKey, id, 31*24*time.Hour)
+	res := s.r.Set(ctx, redisKey, id, 31*24*time.Hour)
 
 	if err := res.Err(); err != nil {
 		return fmt.Errorf("failed to set last ID %d: %w", id, err)
@@ -0,0 +1,323 @@
+// Package mastodon polls Mastodon accounts, like @gotime@changelog.social or
+// @golang@hachyderm.io, for their new posts.
+package mastodon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Account is a Mastodon account, like gotime@changelog.social.
+type Account struct {
+	Username string
+	Server   string
+}
+
+// GoTime is the GoTimeFM account.
+var GoTime = Account{Username: "gotime", Server: "changelog.social"}
+
+// ParseAccount parses an account written as username@server, optionally with
+// a leading @.
+func ParseAccount(s string) (Account, error) {
+	username, server := strings.TrimPrefix(s, "@"), ""
+
+	if i := strings.IndexByte(username, '@'); i >= 0 {
+		username, server = username[:i], username[i+1:]
+	}
+
+	if len(username) == 0 || len(server) == 0 || strings.ContainsAny(server, "@/") {
+		return Account{}, fmt.Errorf("account %q is not of the form username@server", s)
+	}
+
+	return Account{Username: username, Server: server}, nil
+}
+
+func (a Account) String() string {
+	return a.Username + "@" + a.Server
+}
+
+// Profile is what the account's server says about it.
+type Profile struct {
+	ID          string
+	DisplayName string
+	AvatarURL   string
+}
+
+// Name returns the account's display name, or its username if it hasn't set
+// one.
+func (p Profile) Name(a Account) string {
+	if len(p.DisplayName) > 0 {
+		return p.DisplayName
+	}
+
+	return a.Username
+}
+
+// Status is a post from an account.
+type Status struct {
+	ID        string
+	URL       string
+	CreatedAt time.Time
+}
+
+// Store represents the shape of the storage system: the last status seen from
+// each account.
+type Store interface {
+	Get(ctx context.Context, a Account) (lastID string, notFound bool, err error)
+	Put(ctx context.Context, a Account, lastID string) error
+}
+
+// NotifyFunc represents the function signature the poller notifies on a new
+// status from the account. If error is not nil, the item will be retried at
+// some point in the future.
+type NotifyFunc func(ctx context.Context, a Account, p Profile, statusURL string) error
+
+// Mastodon polls the accounts for new statuses.
+type Mastodon struct {
+	logger       zerolog.Logger
+	store        Store
+	http         *http.Client
+	accounts     []Account
+	notify       NotifyFunc
+	statusMaxAge time.Duration
+
+	// nowFunc is not and should not be exposed as part of the API
+	// this is just to facilitate testing with a static time
+	nowFunc func() time.Time
+
+	// profiles and lastStatus are filled in as each account is first
+	// polled, so an unreachable server doesn't stop the others.
+	profiles   map[Account]Profile
+	lastStatus map[Account]string
+}
+
+// New constructs a *Mastodon polling the accounts.
+//
+// statusMaxAge sets the max age of a status to notify on.
+func New(s Store, c *http.Client, logger zerolog.Logger, statusMaxAge time.Duration, accounts []Account, notify NotifyFunc) (*Mastodon, error) {
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no accounts to poll")
+	}
+
+	return &Mastodon{
+		logger:       logger,
+		store:        s,
+		http:         c,
+		accounts:     accounts,
+		notify:       notify,
+		statusMaxAge: statusMaxAge,
+		profiles:     make(map[Account]Profile),
+		lastStatus:   make(map[Account]string),
+	}, nil
+}
+
+// Poll polls each account, returning the first error after trying them all.
+func (m *Mastodon) Poll(ctx context.Context) error {
+	var firstErr error
+
+	failed := 0
+
+	for _, a := range m.accounts {
+		if err := m.pollAccount(ctx, a); err != nil {
+			m.logger.Error().
+				Err(err).
+				Str("account", a.String()).
+				Msg("failed to poll mastodon account")
+
+			failed++
+
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to poll %s: %w", a, err)
+			}
+		}
+	}
+
+	if failed > 1 {
+		return fmt.Errorf("%d of %d accounts failed, first: %w", failed, len(m.accounts), firstErr)
+	}
+
+	return firstErr
+}
+
+// pollAccount conditionally calls notify if there is a status update from the
+// account.
+//
+// For a status to be posted, it needs to be younger than the maxStatusAge
+// this prevents very old statuses from being notified and acts as a safeguard
+// if the last status ID could not be persisted to state storage, and prevents reposts if we lose the last status ID.
+func (m *Mastodon) pollAccount(ctx context.Context, a Account) error {
+	p, ok := m.profiles[a]
+	if !ok {
+		var err error
+		if p, err = m.lookup(ctx, a); err != nil {
+			return err
+		}
+
+		lastStatus, _, err := m.store.Get(ctx, a)
+		if err != nil {
+			return fmt.Errorf("failed to get last notified status: %w", err)
+		}
+
+		m.profiles[a] = p
+		m.lastStatus[a] = lastStatus
+	}
+
+	lastStatus := m.lastStatus[a]
+
+	logger := m.logger.With().Str("account", a.String()).Logger()
+
+	now := m.now()
+	if lastStatus == "" {
+		logger.Trace().Msg("getting latest status")
+	} else {
+		logger.Trace().Msgf("getting statuses since %s", lastStatus)
+	}
+	statuses, err := m.statuses(ctx, a, p.ID, lastStatus)
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		// No new statuses
+		logger.Trace().Msg("no statuses found")
+		return nil
+	}
+	// Sorts the status in descending order (Latest First)
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].CreatedAt.After(statuses[j].CreatedAt)
+	})
+	if lastStatus == "" {
+		// no last status, only notify on the latest status
+		// which should be the first element in the list
+		statuses = statuses[0:1]
+	}
+	for i := range statuses {
+		// iterate in reverse order to post statuses in status in correct chronological order
+		status := statuses[len(statuses)-i-1]
+		m.lastStatus[a] = status.ID
+		age := now.Sub(status.CreatedAt)
+		if age > m.statusMaxAge { // too old
+			logger.Trace().Msgf("status %s skipped. too old: %s", status.ID, age)
+			continue
+		}
+		logger.Trace().Msgf("notify mastodon status: %s", status.URL)
+		if err := m.notify(ctx, a, p, status.URL); err != nil {
+			return fmt.Errorf("failed to notify social status %s: %w", status.URL, err)
+		}
+	}
+	if err := m.store.Put(ctx, a, m.lastStatus[a]); err != nil {
+		return fmt.Errorf("failed to persist status ID to redis: %w", err)
+	}
+	return nil
+}
+
+func (m *Mastodon) now() time.Time {
+	if m.nowFunc == nil {
+		return time.Now()
+	}
+	return m.nowFunc()
+}
+
+// lookup returns the account's profile from its server.
+func (m *Mastodon) lookup(ctx context.Context, a Account) (Profile, error) {
+	var account struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+		Avatar      string `json:"avatar"`
+	}
+
+	u := "https://" + a.Server + "/api/v1/accounts/lookup?acct=" + url.QueryEscape(a.Username)
+
+	if err := m.get(ctx, u, &account); err != nil {
+		return Profile{}, fmt.Errorf("failed to look up account: %w", err)
+	}
+
+	if len(account.ID) == 0 {
+		return Profile{}, fmt.Errorf("failed to look up account: no ID returned")
+	}
+
+	return Profile{ID: account.ID, DisplayName: account.DisplayName, AvatarURL: account.Avatar}, nil
+}
+
+type mastodonStatus struct {
+	ID        string     `json:"id"`
+	URL       string     `json:"url"`
+	CreatedAt createTime `json:"created_at"`
+}
+
+type createTime struct {
+	time.Time
+}
+
+func (ct *createTime) UnmarshalJSON(bs []byte) error {
+	var str string
+	if err := json.Unmarshal(bs, &str); err != nil {
+		return err
+	}
+	t, err := time.Parse("2006-01-02T15:04:05.999Z", str)
+	if err != nil {
+		return err
+	}
+	ct.Time = t
+	return nil
+}
+
+// statuses returns the account's latest statuses, or only those after sinceID
+// if it's not empty, in the order the server returns them.
+func (m *Mastodon) statuses(ctx context.Context, a Account, accountID, sinceID string) ([]Status, error) {
+	u := "https://" + a.Server + "/api/v1/accounts/" + url.PathEscape(accountID) + "/statuses"
+	if len(sinceID) > 0 {
+		u += "?since_id=" + url.QueryEscape(sinceID)
+	}
+
+	var ms []mastodonStatus
+
+	if err := m.get(ctx, u, &ms); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(ms))
+	for i, s := range ms {
+		statuses[i] = Status{ID: s.ID, URL: s.URL, CreatedAt: s.CreatedAt.Time}
+	}
+
+	return statuses, nil
+}
+
+// get makes an HTTP request to url and unmarshals the JSON response into i.
+func (m *Mastodon) get(ctx context.Context, url string, i interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("making http request: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-200 status code: %d - %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response body: %v", err)
+	}
+
+	err = json.Unmarshal(body, i)
+	if err != nil {
+		return fmt.Errorf("unmarshaling response: %s", err)
+	}
+
+	return nil
+}
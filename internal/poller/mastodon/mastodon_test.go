@@ -0,0 +1,197 @@
+package mastodon
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// staticTestPollTime is used to override the nowFunc so that the filtering logic can be tested with the static responses in testdata
+	// If those files are updated, this time should be modified to a new value relative to the new statuses created_at
+	staticTestPollTime = "2022-11-24T15:20:00Z"
+)
+
+func TestMastodon_Poll(t *testing.T) {
+	zl := zerolog.New(ioutil.Discard)
+	s := make(mockStore)
+	c := &http.Client{
+		Transport: &mockResponseTransport{
+			lookup:   testData(t, "lookup.json"),
+			response: testData(t, "statuses.json"),
+		},
+	}
+	const (
+		expectedURL      = "https://changelog.social/@gotime/109399448077436200"
+		expectedStatusID = "109399448077436200"
+	)
+	var notifyURL, notifyName string
+	m, err := New(s, c, zl, 5*time.Minute, []Account{GoTime}, func(ctx context.Context, a Account, p Profile, statusURL string) error {
+		notifyURL = statusURL
+		notifyName = p.Name(a)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error creating Mastodon: %v", err)
+	}
+	staticTime, err := time.Parse(time.RFC3339, staticTestPollTime)
+	if err != nil {
+		t.Fatalf("error parsing static time %s: %v", staticTestPollTime, err)
+	}
+	m.nowFunc = func() time.Time {
+		return staticTime
+	}
+	if err := m.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected poll error: %v", err)
+	}
+	if notifyURL != expectedURL {
+		t.Fatalf("status URL: expected %s, got %s", expectedURL, notifyURL)
+	}
+	if notifyName != "Go Time ⏰" {
+		t.Fatalf("profile name: expected %s, got %s", "Go Time ⏰", notifyName)
+	}
+	if m.lastStatus[GoTime] != expectedStatusID {
+		t.Fatalf("lastStatus: expected %s, got %s", expectedStatusID, m.lastStatus[GoTime])
+	}
+	if v, ok := s[GoTime]; !ok || v != expectedStatusID {
+		t.Fatalf("store: expected (%s,true), got (%s,%t)", expectedStatusID, v, ok)
+	}
+}
+
+func TestMastodon_Poll_lastID(t *testing.T) {
+	zl := zerolog.New(ioutil.Discard)
+	s := mockStore{GoTime: "109378535144130594"} // Set last status to test skipping old messages
+	c := &http.Client{
+		Transport: &mockResponseTransport{
+			lookup:   testData(t, "lookup.json"),
+			response: testData(t, "statuses_since.json"),
+		},
+	}
+	const (
+		expectedURL      = "https://changelog.social/@gotime/109399448077436200"
+		expectedStatusID = "109399448077436200"
+	)
+	var notifyURL string
+	m, err := New(s, c, zl, 5*time.Minute, []Account{GoTime}, func(ctx context.Context, a Account, p Profile, statusURL string) error {
+		notifyURL = statusURL
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error creating Mastodon: %v", err)
+	}
+	staticTime, err := time.Parse(time.RFC3339, staticTestPollTime)
+	if err != nil {
+		t.Fatalf("error parsing static time %s: %v", staticTestPollTime, err)
+	}
+	m.nowFunc = func() time.Time {
+		return staticTime
+	}
+	if err := m.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected poll error: %v", err)
+	}
+	if notifyURL != expectedURL {
+		t.Fatalf("status URL: expected %s, got %s", expectedURL, notifyURL)
+	}
+	if m.lastStatus[GoTime] != expectedStatusID {
+		t.Fatalf("lastStatus: expected %s, got %s", expectedStatusID, m.lastStatus[GoTime])
+	}
+	if v, ok := s[GoTime]; !ok || v != expectedStatusID {
+		t.Fatalf("store: expected (%s,true), got (%s,%t)", expectedStatusID, v, ok)
+	}
+}
+
+func TestParseAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Account
+		wantErr bool
+	}{
+		{
+			name: "plain",
+			s:    "golang@hachyderm.io",
+			want: Account{Username: "golang", Server: "hachyderm.io"},
+		},
+		{
+			name: "leading_at",
+			s:    "@gotime@changelog.social",
+			want: GoTime,
+		},
+		{
+			name:    "no_server",
+			s:       "@gotime",
+			wantErr: true,
+		},
+		{
+			name:    "url",
+			s:       "gotime@changelog.social/about",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAccount(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAccount() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Fatalf("ParseAccount() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type mockResponseTransport struct {
+	lookup   []byte
+	response []byte
+}
+
+type mockStore map[Account]string
+
+func (m mockStore) Get(ctx context.Context, a Account) (id string, notFound bool, err error) {
+	v, ok := m[a]
+	if !ok {
+		return "", true, nil
+	}
+	return v, false, nil
+}
+
+func (m mockStore) Put(ctx context.Context, a Account, lastID string) error {
+	m[a] = lastID
+	return nil
+}
+
+var _ Store = mockStore{}
+
+func (m *mockResponseTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	rr := httptest.NewRecorder()
+	if strings.HasSuffix(request.URL.Path, "/lookup") {
+		rr.Body = bytes.NewBuffer(m.lookup)
+	} else {
+		rr.Body = bytes.NewBuffer(m.response)
+	}
+	rr.Code = http.StatusOK
+	return rr.Result(), nil
+}
+
+var _ http.RoundTripper = &mockResponseTransport{}
+
+func testData(t *testing.T, name string) []byte {
+	fp := filepath.Join("testdata", name)
+	data, err := ioutil.ReadFile(fp)
+	if err != nil {
+		t.Fatalf("could not read %s: %v", fp, err)
+	}
+	return data
+}
@@ -0,0 +1,91 @@
+package mastodon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyFormat = "poller:mastodon:%s:last_id" // account, as username@server
+	redisTestKey   = "poller:mastodon:test_key"
+)
+
+// legacyKeys are where the last status of accounts polled before there could
+// be more than one was kept.
+var legacyKeys = map[Account]string{
+	GoTime: "poller:gotimestatus:last_status",
+}
+
+// DefaultStore is a default implementation of the Store interface.
+type DefaultStore struct {
+	r *redis.Client
+}
+
+var _ Store = (*DefaultStore)(nil)
+
+// NewStore returns a new DefaultStore.
+func NewStore(rc *redis.Client) (*DefaultStore, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &DefaultStore{r: rc}, nil
+}
+
+// Get satisfies Store.
+func (s *DefaultStore) Get(ctx context.Context, a Account) (string, bool, error) {
+	select {
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	default:
+		// noop
+	}
+
+	id, notFound, err := s.get(ctx, fmt.Sprintf(redisKeyFormat, a))
+	if err != nil || !notFound {
+		return id, notFound, err
+	}
+
+	if key, ok := legacyKeys[a]; ok {
+		return s.get(ctx, key)
+	}
+
+	return "", true, nil
+}
+
+func (s *DefaultStore) get(ctx context.Context, key string) (string, bool, error) {
+	v, err := s.r.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", true, nil
+		}
+
+		return "", false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	return v, false, nil
+}
+
+// Put satisfies Store.
+func (s *DefaultStore) Put(ctx context.Context, a Account, id string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	// set for 31 days
+	res := s.r.Set(ctx, fmt.Sprintf(redisKeyFormat, a), id, 31*24*time.Hour)
+
+	if err := res.Err(); err != nil {
+		return fmt.Errorf("failed to set last status ID %s for %s: %w", id, a, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+// Package proposals polls the golang/go issue tracker for accepted proposals,
+// so that other components can answer questions about their status.
+package proposals
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/github"
+	"github.com/rs/zerolog"
+)
+
+const acceptedQuery = "repo:golang/go is:issue label:Proposal-Accepted"
+
+// Proposal is an accepted proposal in the golang/go issue tracker.
+type Proposal struct {
+	Number    int64     `json:"number"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"`
+	URL       string    `json:"html_url"`
+	Milestone string    `json:"milestone"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store represents the shape of the storage system.
+type Store interface {
+	// Put replaces all the stored proposals with these.
+	Put(ctx context.Context, proposals []Proposal) error
+}
+
+// Poller mirrors accepted proposals into the Store.
+type Poller struct {
+	store  Store
+	gh     *github.Client
+	logger zerolog.Logger
+}
+
+// New returns a *Poller.
+func New(s Store, gh *github.Client, logger zerolog.Logger) (*Poller, error) {
+	if s == nil {
+		return nil, fmt.Errorf("store cannot be nil")
+	}
+
+	if gh == nil {
+		return nil, fmt.Errorf("github client cannot be nil")
+	}
+
+	return &Poller{
+		store:  s,
+		gh:     gh,
+		logger: logger,
+	}, nil
+}
+
+// Poll fetches the most recently updated accepted proposals and persists them.
+func (p *Poller) Poll(ctx context.Context) error {
+	issues, err := p.gh.SearchIssues(ctx, acceptedQuery, 100)
+	if err != nil {
+		return fmt.Errorf("failed to search accepted proposals: %w", err)
+	}
+
+	ps := make([]Proposal, 0, len(issues))
+
+	for _, i := range issues {
+		ps = append(ps, Proposal{
+			Number:    i.Number,
+			Title:     i.Title,
+			State:     i.State,
+			URL:       i.URL,
+			Milestone: i.Milestone,
+			UpdatedAt: i.UpdatedAt,
+		})
+	}
+
+	p.logger.Debug().
+		Int("proposal_count", len(ps)).
+		Msg("persisting accepted proposals")
+
+	if err = p.store.Put(ctx, ps); err != nil {
+		return fmt.Errorf("failed to persist proposals: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package proposals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKey     = "poller:proposals:accepted"
+	redisTestKey = "poller:proposals:test_key"
+)
+
+// DefaultStore is a default implementation of the Store interface.
+type DefaultStore struct {
+	r *redis.Client
+}
+
+var _ Store = (*DefaultStore)(nil)
+
+// NewStore returns a new DefaultStore.
+func NewStore(rc *redis.Client) (*DefaultStore, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &DefaultStore{r: rc}, nil
+}
+
+// Put satisfies Store.
+func (s *DefaultStore) Put(ctx context.Context, proposals []Proposal) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if len(proposals) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(proposals))
+
+	for _, p := range proposals {
+		j, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to marshal proposal %d: %w", p.Number, err)
+		}
+
+		fields[strconv.FormatInt(p.Number, 10)] = string(j)
+	}
+
+	pipe := s.r.TxPipeline()
+	pipe.Del(ctx, redisKey)
+	pipe.HMSet(ctx, redisKey, fields)
+	pipe.Expire(ctx, redisKey, 7*24*time.Hour)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set proposals: %w", err)
+	}
+
+	return nil
+}
+
+// Search returns the stored proposals whose title contains all of the words in
+// query, case-insensitively, most recently updated first.
+func (s *DefaultStore) Search(ctx context.Context, query string) ([]Proposal, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.HGetAll(ctx, redisKey)
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to HGETALL redis key: %w", err)
+	}
+
+	words := strings.Fields(strings.ToLower(query))
+
+	var ps []Proposal
+
+	for _, v := range res.Val() {
+		var p Proposal
+		if err := json.Unmarshal([]byte(v), &p); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal proposal: %w", err)
+		}
+
+		if matchesAll(strings.ToLower(p.Title), words) {
+			ps = append(ps, p)
+		}
+	}
+
+	sort.Slice(ps, func(i, j int) bool {
+		return ps[i].UpdatedAt.After(ps[j].UpdatedAt)
+	})
+
+	return ps, nil
+}
+
+func matchesAll(s string, words []string) bool {
+	for _, w := range words {
+		if !strings.Contains(s, w) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,266 @@
+// Package reddit polls the hot posts of r/golang for those upvoted past a
+// score threshold.
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	hotURL = "https://www.reddit.com/r/golang/hot.json?limit=50&raw_json=1"
+
+	// userAgent identifies the bot, as Reddit's API rules ask. Generic ones
+	// are rate limited much more aggressively.
+	userAgent = "server:gopherbot:v1 (by /u/gobridge; https://github.com/gobridge/gopherbot)"
+
+	// defaultBackoff is how long to stop fetching for after being rate
+	// limited, if Reddit doesn't say.
+	defaultBackoff = 10 * time.Minute
+)
+
+// Post is a post on r/golang.
+type Post struct {
+	ID          string
+	Title       string
+	Author      string
+	Permalink   string
+	URL         string
+	Score       int
+	NumComments int
+	CreatedAt   time.Time
+}
+
+// Store represents the shape of the storage system: the posts already
+// notified about.
+type Store interface {
+	// Seen returns which of the posts were notified about. notFound is true
+	// if nothing has been stored yet.
+	Seen(ctx context.Context, ids []string) (seen []bool, notFound bool, err error)
+
+	// Put records the posts were notified about.
+	Put(ctx context.Context, ids ...string) error
+}
+
+// NotifyFunc represents the function signature the poller notifies on a post
+// reaching the score threshold. If error is not nil, the item will be retried
+// at some point in the future.
+type NotifyFunc func(context.Context, Post) error
+
+// Reddit polls r/golang's hot posts.
+type Reddit struct {
+	store    Store
+	http     *http.Client
+	logger   zerolog.Logger
+	minScore int
+	notify   NotifyFunc
+
+	// backoffUntil is when Reddit said we can fetch again, after
+	// running out of requests.
+	backoffUntil time.Time
+}
+
+// New returns a *Reddit notifying about posts with a score of at least
+// minScore.
+func New(s Store, http *http.Client, logger zerolog.Logger, minScore int, notify NotifyFunc) (*Reddit, error) {
+	if s == nil {
+		return nil, fmt.Errorf("store cannot be nil")
+	}
+
+	if minScore < 1 {
+		return nil, fmt.Errorf("minimum score %d is not positive", minScore)
+	}
+
+	if notify == nil {
+		return nil, fmt.Errorf("notify cannot be nil")
+	}
+
+	return &Reddit{
+		store:    s,
+		http:     http,
+		logger:   logger,
+		minScore: minScore,
+		notify:   notify,
+	}, nil
+}
+
+// Poll notifies about the hot posts at or above the score threshold that
+// haven't been notified about, oldest first. Stickied posts are skipped, and
+// the first poll only records the posts already past the threshold, so
+// enabling it doesn't announce a day's worth at once.
+func (r *Reddit) Poll(ctx context.Context) error {
+	now := time.Now()
+
+	if now.Before(r.backoffUntil) {
+		r.logger.Debug().
+			Time("backoff_until", r.backoffUntil).
+			Msg("skipping reddit poll while rate limited")
+
+		return nil
+	}
+
+	posts, err := r.hot(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var hot []Post
+
+	for _, p := range posts {
+		if p.Score >= r.minScore {
+			hot = append(hot, p)
+		}
+	}
+
+	if len(hot) == 0 {
+		return nil
+	}
+
+	sort.Slice(hot, func(i, j int) bool {
+		return hot[i].CreatedAt.Before(hot[j].CreatedAt)
+	})
+
+	ids := make([]string, len(hot))
+	for i, p := range hot {
+		ids[i] = p.ID
+	}
+
+	seen, notFound, err := r.store.Seen(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get posts seen: %w", err)
+	}
+
+	if notFound {
+		r.logger.Info().
+			Int("post_count", len(ids)).
+			Msg("recording hot posts without notifying on first poll")
+
+		return r.store.Put(ctx, ids...)
+	}
+
+	for i, p := range hot {
+		if seen[i] {
+			continue
+		}
+
+		r.logger.Debug().
+			Str("post_id", p.ID).
+			Int("score", p.Score).
+			Msg("sending notification for hot post")
+
+		if err := r.notify(ctx, p); err != nil {
+			return fmt.Errorf("failed to notify for post %s: %w", p.ID, err)
+		}
+
+		if err := r.store.Put(ctx, p.ID); err != nil {
+			return fmt.Errorf("failed to record post %s: %w", p.ID, err)
+		}
+	}
+
+	return nil
+}
+
+type listing struct {
+	Data struct {
+		Children []struct {
+			Data struct {
+				ID          string  `json:"id"`
+				Title       string  `json:"title"`
+				Author      string  `json:"author"`
+				Permalink   string  `json:"permalink"`
+				URL         string  `json:"url"`
+				Score       int     `json:"score"`
+				NumComments int     `json:"num_comments"`
+				CreatedUTC  float64 `json:"created_utc"`
+				Stickied    bool    `json:"stickied"`
+			} `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+// hot fetches the subreddit's hot posts, leaving out the stickied ones. It
+// backs off until Reddit's rate limit resets if it's been used up.
+func (r *Reddit) hot(ctx context.Context, now time.Time) ([]Post, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hotURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		r.backoffUntil = now.Add(backoff(resp.Header, defaultBackoff))
+
+		return nil, fmt.Errorf("rate limited by reddit until %s", r.backoffUntil.Format(time.RFC3339))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got non-200 code: %d", resp.StatusCode)
+	}
+
+	if remaining, err := strconv.ParseFloat(resp.Header.Get("X-Ratelimit-Remaining"), 64); err == nil && remaining < 1 {
+		r.backoffUntil = now.Add(backoff(resp.Header, defaultBackoff))
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var l listing
+
+	if err := json.Unmarshal(body, &l); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal listing: %w", err)
+	}
+
+	posts := make([]Post, 0, len(l.Data.Children))
+
+	for _, c := range l.Data.Children {
+		d := c.Data
+
+		if d.Stickied {
+			continue
+		}
+
+		posts = append(posts, Post{
+			ID:          d.ID,
+			Title:       d.Title,
+			Author:      d.Author,
+			Permalink:   "https://www.reddit.com" + d.Permalink,
+			URL:         d.URL,
+			Score:       d.Score,
+			NumComments: d.NumComments,
+			CreatedAt:   time.Unix(int64(d.CreatedUTC), 0).UTC(),
+		})
+	}
+
+	return posts, nil
+}
+
+// backoff returns how long Reddit asked us to wait, from either the
+// Retry-After or X-Ratelimit-Reset header, or def if neither says.
+func backoff(h http.Header, def time.Duration) time.Duration {
+	for _, name := range []string{"Retry-After", "X-Ratelimit-Reset"} {
+		if s, err := strconv.ParseFloat(h.Get(name), 64); err == nil && s > 0 {
+			return time.Duration(s * float64(time.Second))
+		}
+	}
+
+	return def
+}
@@ -0,0 +1,161 @@
+package reddit
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rs/zerolog"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+type memStore struct {
+	seen map[string]bool
+}
+
+func (s *memStore) Seen(_ context.Context, ids []string) ([]bool, bool, error) {
+	if s.seen == nil {
+		return nil, true, nil
+	}
+
+	seen := make([]bool, len(ids))
+	for i, id := range ids {
+		seen[i] = s.seen[id]
+	}
+
+	return seen, false, nil
+}
+
+func (s *memStore) Put(_ context.Context, ids ...string) error {
+	if s.seen == nil {
+		s.seen = make(map[string]bool)
+	}
+
+	for _, id := range ids {
+		s.seen[id] = true
+	}
+
+	return nil
+}
+
+func TestReddit_Poll(t *testing.T) {
+	var (
+		body     string
+		header   http.Header
+		code     int
+		requests int
+	)
+
+	c := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+
+		if ua := r.Header.Get("User-Agent"); ua != userAgent {
+			t.Errorf("User-Agent = %q, want %q", ua, userAgent)
+		}
+
+		return &http.Response{
+			StatusCode: code,
+			Header:     header,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		}, nil
+	})}
+
+	var notified []string
+
+	r, err := New(&memStore{}, c, zerolog.Nop(), 100, func(_ context.Context, p Post) error {
+		notified = append(notified, p.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, header = http.StatusOK, http.Header{}
+
+	// the first poll only records what's hot
+	body = `{"data": {"children": [
+		{"data": {"id": "a", "score": 150, "created_utc": 1000}},
+		{"data": {"id": "b", "score": 20, "created_utc": 1100}}
+	]}}`
+
+	if err := r.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() unexpected error: %v", err)
+	}
+
+	if len(notified) != 0 {
+		t.Fatalf("first Poll() notified %v, want none", notified)
+	}
+
+	// b passes the threshold, c is new and hot, and the stickied post is
+	// left out however hot it is
+	body = `{"data": {"children": [
+		{"data": {"id": "pinned", "score": 900, "created_utc": 500, "stickied": true}},
+		{"data": {"id": "c", "score": 300, "created_utc": 1200}},
+		{"data": {"id": "a", "score": 180, "created_utc": 1000}},
+		{"data": {"id": "b", "score": 100, "created_utc": 1100}},
+		{"data": {"id": "d", "score": 99, "created_utc": 1300}}
+	]}}`
+
+	if err := r.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"b", "c"}, notified); diff != "" {
+		t.Fatalf("Poll() notified differs (-want +got):\n%s", diff)
+	}
+
+	// being rate limited backs off until Reddit says
+	code, header = http.StatusTooManyRequests, http.Header{"Retry-After": []string{"60"}}
+
+	if err := r.Poll(context.Background()); err == nil {
+		t.Fatal("Poll() expected error when rate limited")
+	}
+
+	if err := r.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() unexpected error while backing off: %v", err)
+	}
+
+	if requests != 3 {
+		t.Fatalf("made %d requests, want 3", requests)
+	}
+}
+
+func Test_backoff(t *testing.T) {
+	tests := []struct {
+		name string
+		h    http.Header
+		want time.Duration
+	}{
+		{
+			name: "retry_after",
+			h:    http.Header{"Retry-After": []string{"30"}},
+			want: 30 * time.Second,
+		},
+		{
+			name: "ratelimit_reset",
+			h:    http.Header{"X-Ratelimit-Reset": []string{"412.5"}},
+			want: 412500 * time.Millisecond,
+		},
+		{
+			name: "neither",
+			h:    http.Header{},
+			want: defaultBackoff,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backoff(tt.h, defaultBackoff); got != tt.want {
+				t.Fatalf("backoff() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
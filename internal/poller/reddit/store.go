@@ -0,0 +1,116 @@
+package reddit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisSeenKey = "poller:reddit:seen" // sorted set of post IDs by when they were notified
+	redisTestKey = "poller:reddit:test_key"
+
+	// seenTTL is how long a post is remembered after it's notified about,
+	// well past how long posts stay hot.
+	seenTTL = 7 * 24 * time.Hour
+)
+
+// DefaultStore is a default implementation of the Store interface.
+type DefaultStore struct {
+	r *redis.Client
+}
+
+var _ Store = (*DefaultStore)(nil)
+
+// NewStore returns a new DefaultStore.
+func NewStore(rc *redis.Client) (*DefaultStore, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &DefaultStore{r: rc}, nil
+}
+
+// Seen satisfies Store.
+func (s *DefaultStore) Seen(ctx context.Context, ids []string) ([]bool, bool, error) {
+	select {
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	var exists *redis.IntCmd
+
+	scores := make([]*redis.FloatCmd, len(ids))
+
+	_, err := s.r.Pipelined(ctx, func(p redis.Pipeliner) error {
+		exists = p.Exists(ctx, redisSeenKey)
+
+		for i, id := range ids {
+			scores[i] = p.ZScore(ctx, redisSeenKey, id)
+		}
+
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, false, fmt.Errorf("failed to ZSCORE redis key: %w", err)
+	}
+
+	if exists.Val() == 0 {
+		return nil, true, nil
+	}
+
+	seen := make([]bool, len(ids))
+
+	for i, c := range scores {
+		switch err := c.Err(); err {
+		case nil:
+			seen[i] = true
+		case redis.Nil:
+			// not seen
+		default:
+			return nil, false, fmt.Errorf("failed to ZSCORE redis key: %w", err)
+		}
+	}
+
+	return seen, false, nil
+}
+
+// Put satisfies Store. Posts are forgotten 7 days after they're put.
+func (s *DefaultStore) Put(ctx context.Context, ids ...string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	members := make([]redis.Z, len(ids))
+	for i, id := range ids {
+		members[i] = redis.Z{Score: float64(now.Unix()), Member: id}
+	}
+
+	_, err := s.r.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZAdd(ctx, redisSeenKey, members...)
+		p.ZRemRangeByScore(ctx, redisSeenKey, "-inf", strconv.FormatInt(now.Add(-seenTTL).Unix(), 10))
+		p.Expire(ctx, redisSeenKey, seenTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record posts %v: %w", ids, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,286 @@
+package workshops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisSubmissionsKey = "workshops:submissions" // hash of submissions by ID
+	redisNextIDKey      = "workshops:next_id"
+	redisAnnouncedFmt   = "workshops:announced:%s" // workshop ID
+	redisTestKey        = "workshops:test_key"
+
+	// pruneAfter is how long after a workshop starts its submission is
+	// kept.
+	pruneAfter = 24 * time.Hour
+)
+
+// Status is where a submission is in the review queue.
+type Status string
+
+const (
+	// Pending submissions are waiting to be reviewed.
+	Pending Status = "pending"
+
+	// Approved submissions are announced when they're due.
+	Approved Status = "approved"
+
+	// Rejected submissions are never announced.
+	Rejected Status = "rejected"
+)
+
+// Submission is a workshop submitted by an organizer.
+type Submission struct {
+	ID          int64     `json:"id"`
+	Workshop    Workshop  `json:"workshop"`
+	Status      Status    `json:"status"`
+	SubmittedBy string    `json:"submitted_by"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	ReviewedBy  string    `json:"reviewed_by,omitempty"`
+}
+
+// ErrNotFound is returned when a submission doesn't exist, like if it was
+// pruned.
+var ErrNotFound = errors.New("workshop submission not found")
+
+// Store is the Redis-backed store of submissions, and of which workshops were
+// announced.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Submit adds the workshop to the review queue, returning its submission. Its
+// ID is set from the submission's.
+func (s *Store) Submit(ctx context.Context, w Workshop, by string) (Submission, error) {
+	select {
+	case <-ctx.Done():
+		return Submission{}, ctx.Err()
+	default:
+		// noop
+	}
+
+	id, err := s.r.Incr(ctx, redisNextIDKey).Result()
+	if err != nil {
+		return Submission{}, fmt.Errorf("failed to get next ID: %w", err)
+	}
+
+	w.ID = fmt.Sprintf("s%d", id)
+
+	sub := Submission{
+		ID:          id,
+		Workshop:    w,
+		Status:      Pending,
+		SubmittedBy: by,
+		SubmittedAt: time.Now(),
+	}
+
+	j, err := json.Marshal(sub)
+	if err != nil {
+		return Submission{}, fmt.Errorf("failed to marshal submission: %w", err)
+	}
+
+	if err = s.r.HSet(ctx, redisSubmissionsKey, strconv.FormatInt(id, 10), j).Err(); err != nil {
+		return Submission{}, fmt.Errorf("failed to save submission: %w", err)
+	}
+
+	return sub, nil
+}
+
+// List returns the submissions with the status, or all of them if it's
+// empty, by ID.
+func (s *Store) List(ctx context.Context, status Status) ([]Submission, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	vals, err := s.r.HGetAll(ctx, redisSubmissionsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submissions: %w", err)
+	}
+
+	subs := make([]Submission, 0, len(vals))
+
+	for _, v := range vals {
+		var sub Submission
+
+		if err := json.Unmarshal([]byte(v), &sub); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal submission: %w", err)
+		}
+
+		if len(status) == 0 || sub.Status == status {
+			subs = append(subs, sub)
+		}
+	}
+
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+
+	return subs, nil
+}
+
+// Review sets the status of the submission, returning it, or ErrNotFound if
+// it doesn't exist.
+func (s *Store) Review(ctx context.Context, id int64, status Status, by string) (Submission, error) {
+	select {
+	case <-ctx.Done():
+		return Submission{}, ctx.Err()
+	default:
+		// noop
+	}
+
+	field := strconv.FormatInt(id, 10)
+
+	var sub Submission
+
+	// don't recreate a submission that's pruned while we're updating it
+	err := s.r.Watch(ctx, func(tx *redis.Tx) error {
+		v, err := tx.HGet(ctx, redisSubmissionsKey, field).Result()
+		if err != nil {
+			if err == redis.Nil {
+				return ErrNotFound
+			}
+
+			return fmt.Errorf("failed to get submission: %w", err)
+		}
+
+		if err = json.Unmarshal([]byte(v), &sub); err != nil {
+			return fmt.Errorf("failed to unmarshal submission: %w", err)
+		}
+
+		sub.Status = status
+		sub.ReviewedBy = by
+
+		j, err := json.Marshal(sub)
+		if err != nil {
+			return fmt.Errorf("failed to marshal submission: %w", err)
+		}
+
+		_, err = tx.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, redisSubmissionsKey, field, j)
+			return nil
+		})
+
+		return err
+	}, redisSubmissionsKey)
+
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Submission{}, err
+		}
+
+		return Submission{}, fmt.Errorf("failed to review submission: %w", err)
+	}
+
+	return sub, nil
+}
+
+// Prune removes the submissions for workshops that started over a day before
+// now, whatever their status.
+func (s *Store) Prune(ctx context.Context, now time.Time) error {
+	subs, err := s.List(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	var fields []string
+
+	for _, sub := range subs {
+		if now.Sub(sub.Workshop.Start) > pruneAfter {
+			fields = append(fields, strconv.FormatInt(sub.ID, 10))
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if err = s.r.HDel(ctx, redisSubmissionsKey, fields...).Err(); err != nil {
+		return fmt.Errorf("failed to remove submissions: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeUser deletes the submissions the user made, whatever their status,
+// returning how many there were. If dryRun is true, they're counted but not
+// deleted. It satisfies purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	subs, err := s.List(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	var fields []string
+
+	for _, sub := range subs {
+		if sub.SubmittedBy == userID {
+			fields = append(fields, strconv.FormatInt(sub.ID, 10))
+		}
+	}
+
+	if len(fields) == 0 || dryRun {
+		return len(fields), nil
+	}
+
+	if err = s.r.HDel(ctx, redisSubmissionsKey, fields...).Err(); err != nil {
+		return 0, fmt.Errorf("failed to remove submissions of %s: %w", userID, err)
+	}
+
+	return len(fields), nil
+}
+
+// MarkAnnounced records that the workshop was announced, returning false if
+// it already had been. The record expires after ttl.
+func (s *Store) MarkAnnounced(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// noop
+	}
+
+	ok, err := s.r.SetNX(ctx, fmt.Sprintf(redisAnnouncedFmt, id), time.Now().Unix(), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to SETNX redis key: %w", err)
+	}
+
+	return ok, nil
+}
+
+// ClearAnnounced forgets that the workshop was announced, so it's tried again
+// on the next poll.
+func (s *Store) ClearAnnounced(ctx context.Context, id string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.Del(ctx, fmt.Sprintf(redisAnnouncedFmt, id)).Err(); err != nil {
+		return fmt.Errorf("failed to DEL redis key: %w", err)
+	}
+
+	return nil
+}
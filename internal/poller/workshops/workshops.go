@@ -0,0 +1,216 @@
+// Package workshops announces upcoming GoBridge and GDI workshops, from a JSON
+// feed of them and from the ones organizers submit to the bot, once a
+// Workspace Admin approves them.
+package workshops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// announceWithin is how soon a workshop has to start to be announced, so
+// there's time to sign up without it being forgotten by the day.
+const announceWithin = 14 * 24 * time.Hour
+
+// Workshop is a single workshop.
+type Workshop struct {
+	// ID identifies the workshop. Submitted workshops have an ID like "s12",
+	// and those from the feed the ID in it, or one derived from the title and
+	// start time.
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	Organizer string    `json:"organizer,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	Start     time.Time `json:"start"`
+}
+
+// NotifyFunc is called to announce a workshop.
+type NotifyFunc func(ctx context.Context, w Workshop) error
+
+// Parse parses the workshops in a JSON feed, which is either an array of
+// objects in the shape of Workshop, with start in RFC 3339 format, or an
+// object with them in its workshops or events field, as the GoBridge events
+// API returns them. Workshops without a title or start time are skipped.
+func Parse(body []byte) ([]Workshop, error) {
+	body = bytes.TrimSpace(body)
+
+	var ws []Workshop
+
+	if bytes.HasPrefix(body, []byte("{")) {
+		var wrapped struct {
+			Workshops []Workshop `json:"workshops"`
+			Events    []Workshop `json:"events"`
+		}
+
+		if err := json.Unmarshal(body, &wrapped); err != nil {
+			return nil, err
+		}
+
+		ws = append(wrapped.Workshops, wrapped.Events...)
+	} else if err := json.Unmarshal(body, &ws); err != nil {
+		return nil, err
+	}
+
+	valid := ws[:0]
+
+	for _, w := range ws {
+		if len(w.Title) == 0 || w.Start.IsZero() {
+			continue
+		}
+
+		if len(w.ID) == 0 {
+			w.ID = w.Title + "@" + w.Start.UTC().Format(time.RFC3339)
+		}
+
+		valid = append(valid, w)
+	}
+
+	return valid, nil
+}
+
+// due returns the workshops starting within announceWithin of now, soonest
+// first.
+func due(ws []Workshop, now time.Time) []Workshop {
+	var d []Workshop
+
+	for _, w := range ws {
+		if until := w.Start.Sub(now); until > 0 && until <= announceWithin {
+			d = append(d, w)
+		}
+	}
+
+	sort.Slice(d, func(i, j int) bool { return d[i].Start.Before(d[j].Start) })
+
+	return d
+}
+
+// Poller fetches the feed, if there is one, and announces the workshops from
+// it and the approved submissions as they come due.
+type Poller struct {
+	store  *Store
+	http   *http.Client
+	feed   string
+	logger zerolog.Logger
+	notify NotifyFunc
+}
+
+// New returns a *Poller. The feedURL is optional, in which case only the
+// approved submissions are announced.
+func New(s *Store, http *http.Client, feedURL string, logger zerolog.Logger, notify NotifyFunc) (*Poller, error) {
+	if s == nil {
+		return nil, fmt.Errorf("store cannot be nil")
+	}
+
+	if notify == nil {
+		return nil, fmt.Errorf("notify cannot be nil")
+	}
+
+	return &Poller{
+		store:  s,
+		http:   http,
+		feed:   feedURL,
+		logger: logger,
+		notify: notify,
+	}, nil
+}
+
+// Poll announces the workshops that are due and haven't been announced. The
+// submissions that are over are pruned.
+func (p *Poller) Poll(ctx context.Context) error {
+	now := time.Now()
+
+	if err := p.store.Prune(ctx, now); err != nil {
+		return fmt.Errorf("failed to prune submissions: %w", err)
+	}
+
+	subs, err := p.store.List(ctx, Approved)
+	if err != nil {
+		return fmt.Errorf("failed to list approved submissions: %w", err)
+	}
+
+	ws := make([]Workshop, 0, len(subs))
+
+	for _, s := range subs {
+		ws = append(ws, s.Workshop)
+	}
+
+	if len(p.feed) > 0 {
+		body, err := p.fetch(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch workshops feed: %w", err)
+		}
+
+		fws, err := Parse(body)
+		if err != nil {
+			return fmt.Errorf("failed to parse workshops feed: %w", err)
+		}
+
+		ws = append(ws, fws...)
+	}
+
+	for _, w := range due(ws, now) {
+		first, err := p.store.MarkAnnounced(ctx, w.ID, w.Start.Sub(now)+24*time.Hour)
+		if err != nil {
+			return fmt.Errorf("failed to mark workshop %s announced: %w", w.ID, err)
+		}
+
+		if !first {
+			continue
+		}
+
+		p.logger.Info().
+			Str("workshop_id", w.ID).
+			Str("workshop_title", w.Title).
+			Msg("announcing workshop")
+
+		if err = p.notify(ctx, w); err != nil {
+			if cerr := p.store.ClearAnnounced(ctx, w.ID); cerr != nil {
+				p.logger.Error().
+					Err(cerr).
+					Str("workshop_id", w.ID).
+					Msg("failed to clear announced state; announcement will not be retried")
+			}
+
+			return fmt.Errorf("failed to announce workshop %s: %w", w.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Poller) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.feed, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", "Gophers Slack bot")
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got non-200 code: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,73 @@
+package workshops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	start := time.Date(2026, 11, 7, 17, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		body string
+		want []Workshop
+	}{
+		{
+			name: "array",
+			body: `[
+				{"id": "gb-1", "title": "Intro to Go", "url": "https://gobridge.org/w/1", "organizer": "GoBridge", "start": "2026-11-07T17:00:00Z"},
+				{"title": "No start"},
+				{"title": "GDI Go", "start": "2026-11-07T17:00:00Z"}
+			]`,
+			want: []Workshop{
+				{ID: "gb-1", Title: "Intro to Go", URL: "https://gobridge.org/w/1", Organizer: "GoBridge", Start: start},
+				{ID: "GDI Go@2026-11-07T17:00:00Z", Title: "GDI Go", Start: start},
+			},
+		},
+		{
+			name: "wrapped",
+			body: `{"events": [{"id": "gb-2", "title": "Web services in Go", "location": "Online", "start": "2026-11-07T17:00:00Z"}]}`,
+			want: []Workshop{
+				{ID: "gb-2", Title: "Web services in Go", Location: "Online", Start: start},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_due(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	ws := []Workshop{
+		{ID: "later", Start: now.Add(10 * 24 * time.Hour)},
+		{ID: "past", Start: now.Add(-time.Hour)},
+		{ID: "soon", Start: now.Add(2 * 24 * time.Hour)},
+		{ID: "too_far", Start: now.Add(30 * 24 * time.Hour)},
+	}
+
+	var got []string
+
+	for _, w := range due(ws, now) {
+		got = append(got, w.ID)
+	}
+
+	if diff := cmp.Diff([]string{"soon", "later"}, got); diff != "" {
+		t.Fatalf("due() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,122 @@
+// Package pollerhealth shares how the bgtasks pollers are doing, so that other
+// components, like the consumer answering `bgtasks status`, can report it.
+package pollerhealth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisPollersKey = "pollerhealth:pollers" // hash of poller name to Health
+	redisTestKey    = "pollerhealth:test_key"
+)
+
+// Health is how a poller, or cron job, has been doing.
+type Health struct {
+	Name string `json:"name"`
+
+	// Schedule is the cron expression, or the interval, it runs on.
+	Schedule string `json:"schedule,omitempty"`
+
+	// Next is when it's due to run next, if known.
+	Next time.Time `json:"next,omitempty"`
+
+	LastRun     time.Time `json:"last_run,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitempty"`
+
+	// LastError is why the last run failed, or empty if it succeeded.
+	LastError string `json:"last_error,omitempty"`
+
+	// ReportedAt is when bgtasks last saved it.
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+// Failing returns whether the last run failed.
+func (h Health) Failing() bool {
+	return len(h.LastError) > 0 || h.LastRun.After(h.LastSuccess)
+}
+
+// Store saves and loads the pollers' Health.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Save records the Health of each of the pollers, replacing what was saved
+// for them before.
+func (s *Store) Save(ctx context.Context, hs []Health) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if len(hs) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(hs))
+
+	for _, h := range hs {
+		j, err := json.Marshal(h)
+		if err != nil {
+			return fmt.Errorf("failed to marshal health of %s: %w", h.Name, err)
+		}
+
+		fields[h.Name] = string(j)
+	}
+
+	if err := s.r.HMSet(ctx, redisPollersKey, fields).Err(); err != nil {
+		return fmt.Errorf("failed to HMSET redis key: %w", err)
+	}
+
+	return nil
+}
+
+// All returns the Health of each poller saved, ordered by name.
+func (s *Store) All(ctx context.Context) ([]Health, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	raw, err := s.r.HGetAll(ctx, redisPollersKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to HGETALL redis key: %w", err)
+	}
+
+	hs := make([]Health, 0, len(raw))
+
+	for name, v := range raw {
+		var h Health
+
+		if err := json.Unmarshal([]byte(v), &h); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal health of %s: %w", name, err)
+		}
+
+		hs = append(hs, h)
+	}
+
+	sort.Slice(hs, func(i, j int) bool { return hs[i].Name < hs[j].Name })
+
+	return hs, nil
+}
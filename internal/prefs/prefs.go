@@ -0,0 +1,294 @@
+// Package prefs stores each user's preferences, like whether their code is
+// uploaded to the playground and which time zone they're in, for the handlers
+// to consult.
+package prefs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyFormat = "prefs:%s" // hash of preference to value, per user
+	redisTestKey   = "prefs:test_key"
+)
+
+// The preferences users can set.
+const (
+	// Playground is whether the user's long code messages are uploaded to
+	// the playground.
+	Playground = "playground"
+
+	// DMWelcome is whether the user is sent the welcome DM when joining.
+	DMWelcome = "dm_welcome"
+
+	// TZ is the user's time zone, by its IANA name.
+	TZ = "tz"
+)
+
+// Pref describes a preference.
+type Pref struct {
+	Name        string
+	Description string
+
+	// Default is the value of the preference for users who haven't set it.
+	Default string
+
+	// normalize validates a value, returning it in the form it's stored in.
+	normalize func(v string) (string, error)
+}
+
+// Prefs are the preferences users can set, in the order they're listed.
+var Prefs = []Pref{
+	{
+		Name:        Playground,
+		Description: "upload your long code messages to the playground (`on` or `off`)",
+		Default:     "on",
+		normalize:   normalizeSwitch,
+	},
+	{
+		Name:        DMWelcome,
+		Description: "send you the welcome DM if you join again (`on` or `off`)",
+		Default:     "on",
+		normalize:   normalizeSwitch,
+	},
+	{
+		Name:        TZ,
+		Description: "your time zone, like `Europe/Berlin`",
+		Default:     "UTC",
+		normalize:   normalizeTZ,
+	},
+}
+
+// Lookup returns the preference with the name.
+func Lookup(name string) (Pref, bool) {
+	for _, p := range Prefs {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+
+	return Pref{}, false
+}
+
+// Normalize validates the value, returning it in the form it's stored in.
+func (p Pref) Normalize(v string) (string, error) {
+	return p.normalize(strings.TrimSpace(v))
+}
+
+func normalizeSwitch(v string) (string, error) {
+	switch strings.ToLower(v) {
+	case "on", "true", "yes":
+		return "on", nil
+	case "off", "false", "no":
+		return "off", nil
+	default:
+		return "", fmt.Errorf("%q isn't `on` or `off`", v)
+	}
+}
+
+func normalizeTZ(v string) (string, error) {
+	// time.LoadLocation treats these as UTC and Local, neither of which we
+	// want stored
+	if len(v) == 0 || strings.EqualFold(v, "local") {
+		return "", fmt.Errorf("%q isn't a time zone", v)
+	}
+
+	loc, err := time.LoadLocation(v)
+	if err != nil {
+		return "", fmt.Errorf("%q isn't a time zone, like `Europe/Berlin`", v)
+	}
+
+	return loc.String(), nil
+}
+
+// ParseAssignment parses a preference being set, like playground=off, into the
+// preference and its normalized value.
+func ParseAssignment(s string) (Pref, string, error) {
+	i := strings.IndexByte(s, '=')
+	if i < 0 {
+		return Pref{}, "", fmt.Errorf("%q should be like `name=value`", s)
+	}
+
+	p, ok := Lookup(strings.TrimSpace(s[:i]))
+	if !ok {
+		return Pref{}, "", fmt.Errorf("there's no preference named `%s`", strings.TrimSpace(s[:i]))
+	}
+
+	v, err := p.Normalize(s[i+1:])
+	if err != nil {
+		return Pref{}, "", err
+	}
+
+	return p, v, nil
+}
+
+// Store stores the preferences.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Get returns the user's value for the preference, or its default if they
+// haven't set it.
+func (s *Store) Get(ctx context.Context, userID, name string) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+		// noop
+	}
+
+	p, ok := Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("unknown preference %q", name)
+	}
+
+	v, err := s.r.HGet(ctx, fmt.Sprintf(redisKeyFormat, userID), p.Name).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return p.Default, nil
+		}
+
+		return "", fmt.Errorf("failed to HGET redis key: %w", err)
+	}
+
+	return v, nil
+}
+
+// Enabled returns whether the user has the on-or-off preference on.
+func (s *Store) Enabled(ctx context.Context, userID, name string) (bool, error) {
+	v, err := s.Get(ctx, userID, name)
+	if err != nil {
+		return false, err
+	}
+
+	return v == "on", nil
+}
+
+// Location returns the user's time zone, or UTC if they haven't set one.
+func (s *Store) Location(ctx context.Context, userID string) (*time.Location, error) {
+	v, err := s.Get(ctx, userID, TZ)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time zone %q: %w", v, err)
+	}
+
+	return loc, nil
+}
+
+// Set sets the user's value for the preference, after validating it.
+func (s *Store) Set(ctx context.Context, userID, name, value string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	p, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown preference %q", name)
+	}
+
+	v, err := p.Normalize(value)
+	if err != nil {
+		return err
+	}
+
+	if err := s.r.HSet(ctx, fmt.Sprintf(redisKeyFormat, userID), p.Name, v).Err(); err != nil {
+		return fmt.Errorf("failed to HSET redis key: %w", err)
+	}
+
+	return nil
+}
+
+// Value is a user's value for a preference.
+type Value struct {
+	Pref
+
+	Value string
+
+	// Set is whether the user set it, rather than it being the default.
+	Set bool
+}
+
+// All returns the user's value for each preference, in the order of Prefs.
+func (s *Store) All(ctx context.Context, userID string) ([]Value, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	m, err := s.r.HGetAll(ctx, fmt.Sprintf(redisKeyFormat, userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to HGETALL redis key: %w", err)
+	}
+
+	return values(m), nil
+}
+
+// values returns the value of each preference, given those the user set.
+func values(set map[string]string) []Value {
+	vs := make([]Value, 0, len(Prefs))
+
+	for _, p := range Prefs {
+		v, ok := set[p.Name]
+		if !ok {
+			v = p.Default
+		}
+
+		vs = append(vs, Value{Pref: p, Value: v, Set: ok})
+	}
+
+	return vs
+}
+
+// PurgeUser deletes the user's preferences, returning how many they had set.
+// If dryRun is true, they're counted but not deleted. It satisfies
+// purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	key := fmt.Sprintf(redisKeyFormat, userID)
+
+	n, err := s.r.HLen(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to HLEN redis key: %w", err)
+	}
+
+	if n == 0 || dryRun {
+		return int(n), nil
+	}
+
+	if err := s.r.Del(ctx, key).Err(); err != nil {
+		return 0, fmt.Errorf("failed to DEL redis key: %w", err)
+	}
+
+	return int(n), nil
+}
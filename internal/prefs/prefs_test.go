@@ -0,0 +1,110 @@
+package prefs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseAssignment(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantPref  string
+		wantValue string
+		wantErr   bool
+	}{
+		{
+			name:      "switch",
+			input:     "playground=off",
+			wantPref:  Playground,
+			wantValue: "off",
+		},
+		{
+			name:      "switch_synonym",
+			input:     "DM_Welcome=Yes",
+			wantPref:  DMWelcome,
+			wantValue: "on",
+		},
+		{
+			name:      "tz",
+			input:     "tz=Europe/Berlin",
+			wantPref:  TZ,
+			wantValue: "Europe/Berlin",
+		},
+		{
+			name:      "spaces",
+			input:     "tz = UTC",
+			wantPref:  TZ,
+			wantValue: "UTC",
+		},
+		{
+			name:    "no_value",
+			input:   "playground",
+			wantErr: true,
+		},
+		{
+			name:    "unknown",
+			input:   "theme=dark",
+			wantErr: true,
+		},
+		{
+			name:    "bad_switch",
+			input:   "playground=maybe",
+			wantErr: true,
+		},
+		{
+			name:    "bad_tz",
+			input:   "tz=Mars/Olympus_Mons",
+			wantErr: true,
+		},
+		{
+			name:    "local_tz",
+			input:   "tz=Local",
+			wantErr: true,
+		},
+		{
+			name:    "empty_tz",
+			input:   "tz=",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p, v, err := ParseAssignment(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAssignment() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if p.Name != tt.wantPref || v != tt.wantValue {
+				t.Fatalf("ParseAssignment() = %q, %q, want %q, %q", p.Name, v, tt.wantPref, tt.wantValue)
+			}
+		})
+	}
+}
+
+func Test_values(t *testing.T) {
+	got := values(map[string]string{
+		Playground: "off",
+		"removed":  "whatever",
+	})
+
+	var gotStrs []string
+
+	for _, v := range got {
+		s := v.Name + "=" + v.Value
+		if v.Set {
+			s += " (set)"
+		}
+
+		gotStrs = append(gotStrs, s)
+	}
+
+	want := []string{"playground=off (set)", "dm_welcome=on", "tz=UTC"}
+
+	if diff := cmp.Diff(want, gotStrs); diff != "" {
+		t.Fatalf("values() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,121 @@
+// Package purge deletes everything the bot stores about a member, across the
+// stores that hold it, for deactivated members who ask to be removed. A dry run
+// reports what would be deleted, so it can be checked first.
+package purge
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// Store is a store holding data about members.
+type Store interface {
+	// PurgeUser deletes what's stored about the user, returning how many
+	// records there were. If dryRun is true, they're counted but not
+	// deleted.
+	PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error)
+}
+
+// userIDRegex matches Slack user IDs.
+var userIDRegex = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+
+// ValidUserID returns whether id looks like a Slack user ID.
+func ValidUserID(id string) bool {
+	return userIDRegex.MatchString(id)
+}
+
+// Result is what was found in one of the stores.
+type Result struct {
+	Name  string
+	Count int
+
+	// Err is why purging the store failed, if it did.
+	Err error
+}
+
+// Report is what was found about a user, and deleted unless it's a dry run.
+type Report struct {
+	UserID  string
+	DryRun  bool
+	Results []Result
+}
+
+// Total returns how many records were found across the stores.
+func (r Report) Total() int {
+	var n int
+
+	for _, res := range r.Results {
+		n += res.Count
+	}
+
+	return n
+}
+
+// Failed returns the results for the stores that couldn't be purged.
+func (r Report) Failed() []Result {
+	var failed []Result
+
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+
+	return failed
+}
+
+type namedStore struct {
+	name  string
+	store Store
+}
+
+// Purger purges users from each of the stores added to it, in the order they
+// were added.
+type Purger struct {
+	stores []namedStore
+}
+
+// New returns a new *Purger with no stores.
+func New() *Purger {
+	return &Purger{}
+}
+
+// Add adds the store, named for what it holds, like "preferences".
+func (p *Purger) Add(name string, s Store) {
+	p.stores = append(p.stores, namedStore{name: name, store: s})
+}
+
+// Purge purges the user from every store, or counts what would be if dryRun is
+// true. A store failing doesn't stop the others being purged, so it can be
+// retried: the error is in its Result, and an error is returned along with the
+// Report.
+func (p *Purger) Purge(ctx context.Context, userID string, dryRun bool) (Report, error) {
+	if !ValidUserID(userID) {
+		return Report{}, fmt.Errorf("%q isn't a user ID", userID)
+	}
+
+	r := Report{
+		UserID:  userID,
+		DryRun:  dryRun,
+		Results: make([]Result, 0, len(p.stores)),
+	}
+
+	for _, ns := range p.stores {
+		select {
+		case <-ctx.Done():
+			return r, ctx.Err()
+		default:
+			// noop
+		}
+
+		n, err := ns.store.PurgeUser(ctx, userID, dryRun)
+		r.Results = append(r.Results, Result{Name: ns.name, Count: n, Err: err})
+	}
+
+	if failed := r.Failed(); len(failed) > 0 {
+		return r, fmt.Errorf("failed to purge %s from %d stores, including %s: %w", userID, len(failed), failed[0].Name, failed[0].Err)
+	}
+
+	return r, nil
+}
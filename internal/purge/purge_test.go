@@ -0,0 +1,93 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeStore struct {
+	records map[string]int
+	err     error
+}
+
+func (f *fakeStore) PurgeUser(_ context.Context, userID string, dryRun bool) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	n := f.records[userID]
+
+	if !dryRun {
+		delete(f.records, userID)
+	}
+
+	return n, nil
+}
+
+func TestPurger_Purge(t *testing.T) {
+	prefs := &fakeStore{records: map[string]int{"U012AB3CD": 3, "U999": 1}}
+	joins := &fakeStore{records: map[string]int{"U012AB3CD": 2}}
+	broken := &fakeStore{err: errors.New("redis down")}
+
+	p := New()
+	p.Add("preferences", prefs)
+	p.Add("join tracking", joins)
+
+	ctx := context.Background()
+
+	r, err := p.Purge(ctx, "U012AB3CD", true)
+	if err != nil {
+		t.Fatalf("Purge() dry run error = %v", err)
+	}
+
+	want := Report{
+		UserID: "U012AB3CD",
+		DryRun: true,
+		Results: []Result{
+			{Name: "preferences", Count: 3},
+			{Name: "join tracking", Count: 2},
+		},
+	}
+
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Fatalf("Purge() dry run mismatch (-want +got):\n%s", diff)
+	}
+
+	if r.Total() != 5 {
+		t.Errorf("Total() = %d, want 5", r.Total())
+	}
+
+	if prefs.records["U012AB3CD"] != 3 {
+		t.Fatal("Purge() dry run deleted records")
+	}
+
+	p.Add("broken", broken)
+
+	r, err = p.Purge(ctx, "U012AB3CD", false)
+	if err == nil {
+		t.Fatal("Purge() error = nil, want an error from the broken store")
+	}
+
+	if r.Total() != 5 {
+		t.Errorf("Total() = %d, want 5", r.Total())
+	}
+
+	if failed := r.Failed(); len(failed) != 1 || failed[0].Name != "broken" || !errors.Is(failed[0].Err, broken.err) {
+		t.Errorf("Failed() = %v, want only the broken store", failed)
+	}
+
+	if _, ok := prefs.records["U012AB3CD"]; ok {
+		t.Error("Purge() didn't delete the preferences")
+	}
+
+	if prefs.records["U999"] != 1 {
+		t.Error("Purge() deleted another user's preferences")
+	}
+
+	if _, err := p.Purge(ctx, "general", true); err == nil {
+		t.Error("Purge() of a non-user ID error = nil, want an error")
+	}
+}
@@ -0,0 +1,74 @@
+// Package ratelimit provides a Redis-backed fixed-window rate limiter, for
+// limiting how often users can invoke expensive commands.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyFormat = "ratelimit:%s:%s"
+
+// Limiter allows up to a limit of events per window, per ID.
+type Limiter struct {
+	r      *redis.Client
+	name   string
+	limit  int64
+	window time.Duration
+}
+
+// New returns a new *Limiter. The name is used to namespace the Redis keys, so
+// that different commands can have different limits.
+func New(rc *redis.Client, name string, limit int64, window time.Duration) (*Limiter, error) {
+	if rc == nil {
+		return nil, fmt.Errorf("redis client cannot be nil")
+	}
+
+	if limit < 1 {
+		return nil, fmt.Errorf("limit must be greater than zero")
+	}
+
+	if window <= 0 {
+		return nil, fmt.Errorf("window must be greater than zero")
+	}
+
+	return &Limiter{
+		r:      rc,
+		name:   name,
+		limit:  limit,
+		window: window,
+	}, nil
+}
+
+// Allow records an event for the ID (e.g., a user ID), and returns whether it
+// is within the limit. If allowed is false, retryAfter is roughly how long
+// until the ID is allowed again.
+func (l *Limiter) Allow(ctx context.Context, id string) (allowed bool, retryAfter time.Duration, err error) {
+	select {
+	case <-ctx.Done():
+		return false, 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	key := fmt.Sprintf(redisKeyFormat, l.name, id)
+
+	// start the window if there isn't one, and count this event in it
+	pipe := l.r.TxPipeline()
+	pipe.SetNX(ctx, key, 0, l.window)
+	incr := pipe.Incr(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+
+	if _, err = pipe.Exec(ctx); err != nil {
+		return false, 0, fmt.Errorf("failed to increment rate limit counter: %w", err)
+	}
+
+	if incr.Val() > l.limit {
+		return false, ttl.Val(), nil
+	}
+
+	return true, 0, nil
+}
@@ -0,0 +1,243 @@
+// Package redact is the redaction layer for the logs. It rewrites each JSON
+// log line on its way out, hashing user IDs, truncating or removing message
+// text, and obfuscating secrets, depending on the Mode, so the logs can be
+// shipped and kept without holding members' messages or the bot's tokens.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fastjson"
+)
+
+// Mode is how much is redacted.
+type Mode string
+
+const (
+	// Strict hashes user IDs and emails, replaces message text with its
+	// length, and obfuscates secrets. It's the default in production.
+	Strict Mode = "strict"
+
+	// Partial truncates message text to TruncateLength characters and
+	// obfuscates secrets, leaving user IDs as they are. It's the default in
+	// staging and testing.
+	Partial Mode = "partial"
+
+	// Off only obfuscates secrets, for debugging incidents. It's the default
+	// in development.
+	Off Mode = "off"
+)
+
+// ParseMode parses the Mode, which is case-insensitive.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(s)); m {
+	case Strict, Partial, Off:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown redaction mode %q: must be strict, partial, or off", s)
+	}
+}
+
+// TruncateLength is how many characters of message text are kept in Partial
+// mode.
+const TruncateLength = 32
+
+// secretSuffixLength is how many characters at the end of a secret are kept,
+// which is enough to tell which one was used without being able to use it.
+const secretSuffixLength = 4
+
+// userFields are the fields holding user IDs or emails, which are hashed in
+// Strict mode, along with any field ending in _user_id.
+var userFields = map[string]struct{}{
+	"user_id":      {},
+	"installed_by": {},
+	"user_email":   {},
+}
+
+// textFields are the fields holding what members wrote.
+var textFields = map[string]struct{}{
+	"text": {},
+}
+
+// secretFields are the fields holding secrets, along with any field ending in
+// _token or _secret.
+var secretFields = map[string]struct{}{
+	"token":    {},
+	"password": {},
+	"dsn":      {},
+}
+
+type kind int
+
+const (
+	plain kind = iota
+	user
+	text
+	secret
+)
+
+func fieldKind(name string) kind {
+	if _, ok := secretFields[name]; ok || strings.HasSuffix(name, "_token") || strings.HasSuffix(name, "_secret") {
+		return secret
+	}
+
+	if _, ok := userFields[name]; ok || strings.HasSuffix(name, "_user_id") {
+		return user
+	}
+
+	if _, ok := textFields[name]; ok {
+		return text
+	}
+
+	return plain
+}
+
+// HashUserID returns the hash of a user ID or email that replaces it in Strict
+// mode, which is the same each time so a user's actions can still be followed
+// through the logs.
+func HashUserID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return "h:" + hex.EncodeToString(sum[:6])
+}
+
+// Secret returns the secret obfuscated, keeping only its last few characters,
+// or nothing of it if it's short.
+func Secret(s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+
+	if utf8.RuneCountInString(s) <= 4*secretSuffixLength {
+		return "[redacted]"
+	}
+
+	r := []rune(s)
+
+	return "[redacted]..." + string(r[len(r)-secretSuffixLength:])
+}
+
+// Text returns the message text redacted for the Mode.
+func Text(m Mode, s string) string {
+	switch m {
+	case Strict:
+		return fmt.Sprintf("[redacted: %d characters]", utf8.RuneCountInString(s))
+	case Partial:
+		if utf8.RuneCountInString(s) <= TruncateLength {
+			return s
+		}
+
+		return string([]rune(s)[:TruncateLength]) + "..."
+	default:
+		return s
+	}
+}
+
+func (m Mode) value(k kind, s string) (string, bool) {
+	switch {
+	case k == secret:
+		return Secret(s), true
+	case k == user && m == Strict:
+		return HashUserID(s), true
+	case k == text && m != Off:
+		return Text(m, s), true
+	default:
+		return s, false
+	}
+}
+
+// Writer redacts each JSON log line written to it before writing it to the
+// underlying writer. Lines that aren't JSON objects are written as they are.
+type Writer struct {
+	w    io.Writer
+	mode Mode
+
+	parsers fastjson.ParserPool
+	arenas  fastjson.ArenaPool
+}
+
+var _ zerolog.LevelWriter = (*Writer)(nil)
+
+// NewWriter returns a *Writer redacting for the Mode. If w is a
+// zerolog.LevelWriter, like one from zerolog.MultiLevelWriter, the level is
+// passed on to it.
+func NewWriter(w io.Writer, m Mode) *Writer {
+	return &Writer{w: w, mode: m}
+}
+
+// Redact returns the log line redacted.
+func (w *Writer) Redact(p []byte) []byte {
+	parser := w.parsers.Get()
+	defer w.parsers.Put(parser)
+
+	v, err := parser.ParseBytes(p)
+	if err != nil {
+		return p
+	}
+
+	obj, err := v.Object()
+	if err != nil {
+		return p
+	}
+
+	arena := w.arenas.Get()
+	defer w.arenas.Put(arena)
+
+	var changed bool
+
+	obj.Visit(func(key []byte, fv *fastjson.Value) {
+		if fv.Type() != fastjson.TypeString {
+			return
+		}
+
+		k := fieldKind(string(key))
+		if k == plain {
+			return
+		}
+
+		if s, ok := w.mode.value(k, string(fv.GetStringBytes())); ok {
+			obj.Set(string(key), arena.NewString(s))
+			changed = true
+		}
+	})
+
+	if !changed {
+		return p
+	}
+
+	out := obj.MarshalTo(make([]byte, 0, len(p)))
+
+	if len(p) > 0 && p[len(p)-1] == '\n' {
+		out = append(out, '\n')
+	}
+
+	return out
+}
+
+// Write satisfies io.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	if _, err := w.w.Write(w.Redact(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// WriteLevel satisfies zerolog.LevelWriter.
+func (w *Writer) WriteLevel(l zerolog.Level, p []byte) (int, error) {
+	lw, ok := w.w.(zerolog.LevelWriter)
+	if !ok {
+		return w.Write(p)
+	}
+
+	if _, err := lw.WriteLevel(l, w.Redact(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,165 @@
+// Package rng provides the random numbers behind the results people see, like
+// coin flips, dice rolls, and which reactions fire, so the handlers can be
+// given a seeded one to make them deterministic in tests.
+package rng
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RNG is a source of random numbers, safe for concurrent use.
+type RNG interface {
+	// Intn returns a number in [0,n). It panics if n <= 0.
+	Intn(n int) int
+
+	// Float64 returns a number in [0.0,1.0).
+	Float64() float64
+}
+
+// cryptoSource is a math/rand.Source64 reading from crypto/rand. It has no
+// state, so it's safe for concurrent use.
+type cryptoSource struct{}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+func (s cryptoSource) Int63() int64 {
+	return int64(s.Uint64() & (1<<63 - 1))
+}
+
+// Seed is a noop, as crypto/rand can't be seeded.
+func (cryptoSource) Seed(int64) {}
+
+// New returns an RNG backed by crypto/rand, so its results can't be predicted
+// from earlier ones.
+func New() RNG {
+	return rand.New(cryptoSource{})
+}
+
+// lockedRand makes a *rand.Rand safe for concurrent use.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.r.Intn(n)
+}
+
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.r.Float64()
+}
+
+// NewSeeded returns an RNG that always returns the same numbers for the same
+// seed, for tests.
+func NewSeeded(seed int64) RNG {
+	return &lockedRand{r: rand.New(rand.NewSource(seed))}
+}
+
+// Limits on the dice that can be rolled, so the results fit in a message.
+const (
+	MaxDice  = 100
+	MaxSides = 1000
+)
+
+// Dice are a number of dice with the same number of sides, like 2d6.
+type Dice struct {
+	Count int
+	Sides int
+}
+
+func (d Dice) String() string {
+	return fmt.Sprintf("%dd%d", d.Count, d.Sides)
+}
+
+// ParseDice parses dice in the usual notation, like 2d6, or d20 for one die.
+func ParseDice(s string) (Dice, error) {
+	i := strings.IndexAny(s, "dD")
+	if i < 0 {
+		return Dice{}, fmt.Errorf("`%s` should be like `2d6`", s)
+	}
+
+	d := Dice{Count: 1}
+
+	if i > 0 {
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return Dice{}, fmt.Errorf("`%s` should be like `2d6`", s)
+		}
+
+		d.Count = n
+	}
+
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return Dice{}, fmt.Errorf("`%s` should be like `2d6`", s)
+	}
+
+	d.Sides = n
+
+	if d.Count < 1 || d.Count > MaxDice {
+		return Dice{}, fmt.Errorf("I can roll between 1 and %d dice", MaxDice)
+	}
+
+	if d.Sides < 2 || d.Sides > MaxSides {
+		return Dice{}, fmt.Errorf("dice need between 2 and %d sides", MaxSides)
+	}
+
+	return d, nil
+}
+
+// Roll rolls the dice, returning each die's result.
+func (d Dice) Roll(r RNG) []int {
+	rolls := make([]int, d.Count)
+
+	for i := range rolls {
+		rolls[i] = r.Intn(d.Sides) + 1
+	}
+
+	return rolls
+}
+
+// ParseChoices splits the text into the choices to pick from, separated by
+// commas, or "or" if there aren't any commas: "tabs, spaces" and "tabs or
+// spaces" are both two choices.
+func ParseChoices(text string) []string {
+	var parts []string
+
+	if strings.Contains(text, ",") {
+		parts = strings.Split(text, ",")
+	} else {
+		parts = strings.Split(text, " or ")
+	}
+
+	choices := make([]string, 0, len(parts))
+
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		p = strings.TrimSpace(strings.TrimPrefix(p, "or "))
+
+		if len(p) > 0 {
+			choices = append(choices, p)
+		}
+	}
+
+	return choices
+}
@@ -0,0 +1,99 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDice(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Dice
+		wantErr bool
+	}{
+		{name: "two_d6", input: "2d6", want: Dice{Count: 2, Sides: 6}},
+		{name: "one_implied", input: "d20", want: Dice{Count: 1, Sides: 20}},
+		{name: "upper_case", input: "3D8", want: Dice{Count: 3, Sides: 8}},
+		{name: "no_d", input: "26", wantErr: true},
+		{name: "no_sides", input: "2d", wantErr: true},
+		{name: "not_a_number", input: "twod6", wantErr: true},
+		{name: "zero_dice", input: "0d6", wantErr: true},
+		{name: "too_many_dice", input: "101d6", wantErr: true},
+		{name: "one_side", input: "1d1", wantErr: true},
+		{name: "too_many_sides", input: "1d1001", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDice(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDice() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Fatalf("ParseDice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDice_Roll(t *testing.T) {
+	d := Dice{Count: 50, Sides: 6}
+
+	got := d.Roll(NewSeeded(42))
+
+	if len(got) != d.Count {
+		t.Fatalf("Roll() returned %d rolls, want %d", len(got), d.Count)
+	}
+
+	for _, n := range got {
+		if n < 1 || n > d.Sides {
+			t.Fatalf("Roll() = %d, want between 1 and %d", n, d.Sides)
+		}
+	}
+
+	if diff := cmp.Diff(got, d.Roll(NewSeeded(42))); diff != "" {
+		t.Fatalf("Roll() with the same seed mismatch (-first +second):\n%s", diff)
+	}
+}
+
+func TestParseChoices(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "commas", input: "tabs, spaces, both", want: []string{"tabs", "spaces", "both"}},
+		{name: "or", input: "tabs or spaces", want: []string{"tabs", "spaces"}},
+		{name: "commas_and_or", input: "vim, emacs, or nano", want: []string{"vim", "emacs", "nano"}},
+		{name: "empty_choices", input: "a,, b,", want: []string{"a", "b"}},
+		{name: "one", input: "pizza", want: []string{"pizza"}},
+		{name: "empty", input: "", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, ParseChoices(tt.input)); diff != "" {
+				t.Fatalf("ParseChoices() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	r := New()
+
+	for i := 0; i < 100; i++ {
+		if n := r.Intn(3); n < 0 || n >= 3 {
+			t.Fatalf("Intn(3) = %d", n)
+		}
+
+		if f := r.Float64(); f < 0 || f >= 1 {
+			t.Fatalf("Float64() = %f", f)
+		}
+	}
+}
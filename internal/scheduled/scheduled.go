@@ -0,0 +1,279 @@
+// Package scheduled stores the recurring posts moderators schedule, like a
+// weekly introductions thread or the job posting rules every Monday, which
+// bgtasks posts when their cron expression fires.
+package scheduled
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/cron"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKey       = "scheduled_posts"
+	redisNextIDKey = "scheduled_posts:next_id"
+	redisTestKey   = "scheduled_posts:test_key"
+)
+
+// Post is a recurring post.
+type Post struct {
+	ID        int64  `json:"id"`
+	ChannelID string `json:"channel_id"`
+
+	// Schedule is the cron expression for when it's posted.
+	Schedule string `json:"schedule"`
+
+	Text      string    `json:"text"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// LastRun is when it was last posted, if it has been.
+	LastRun time.Time `json:"last_run,omitempty"`
+}
+
+// Next returns when the post is next due, which may be in the past if it's
+// overdue.
+func (p Post) Next() (time.Time, error) {
+	s, err := cron.Parse(p.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	from := p.CreatedAt
+	if p.LastRun.After(from) {
+		from = p.LastRun
+	}
+
+	return s.Next(from), nil
+}
+
+// ErrNotFound is returned when a post doesn't exist, like if it was removed.
+var ErrNotFound = errors.New("scheduled post not found")
+
+// Store is the Redis-backed store of scheduled posts.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Add saves a new post, returning it with its ID. The schedule must be a valid
+// cron expression.
+func (s *Store) Add(ctx context.Context, p Post) (Post, error) {
+	select {
+	case <-ctx.Done():
+		return Post{}, ctx.Err()
+	default:
+		// noop
+	}
+
+	if _, err := cron.Parse(p.Schedule); err != nil {
+		return Post{}, fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	id, err := s.r.Incr(ctx, redisNextIDKey).Result()
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to get next ID: %w", err)
+	}
+
+	p.ID = id
+
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now()
+	}
+
+	j, err := json.Marshal(p)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to marshal post: %w", err)
+	}
+
+	if err = s.r.HSet(ctx, redisKey, strconv.FormatInt(id, 10), j).Err(); err != nil {
+		return Post{}, fmt.Errorf("failed to save post: %w", err)
+	}
+
+	return p, nil
+}
+
+// Remove removes the post, returning ErrNotFound if it doesn't exist.
+func (s *Store) Remove(ctx context.Context, id int64) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	n, err := s.r.HDel(ctx, redisKey, strconv.FormatInt(id, 10)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to remove post: %w", err)
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns all of the posts, by ID.
+func (s *Store) List(ctx context.Context) ([]Post, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	vals, err := s.r.HGetAll(ctx, redisKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	posts := make([]Post, 0, len(vals))
+
+	for _, v := range vals {
+		var p Post
+
+		if err := json.Unmarshal([]byte(v), &p); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal post: %w", err)
+		}
+
+		posts = append(posts, p)
+	}
+
+	sort.Slice(posts, func(i, j int) bool { return posts[i].ID < posts[j].ID })
+
+	return posts, nil
+}
+
+// MarkRun records that the post was posted at the time, returning ErrNotFound
+// if it was removed in the meantime.
+func (s *Store) MarkRun(ctx context.Context, id int64, at time.Time) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	field := strconv.FormatInt(id, 10)
+
+	// don't recreate a post that's removed while we're updating it
+	err := s.r.Watch(ctx, func(tx *redis.Tx) error {
+		v, err := tx.HGet(ctx, redisKey, field).Result()
+		if err != nil {
+			if err == redis.Nil {
+				return ErrNotFound
+			}
+
+			return fmt.Errorf("failed to get post: %w", err)
+		}
+
+		var p Post
+
+		if err = json.Unmarshal([]byte(v), &p); err != nil {
+			return fmt.Errorf("failed to unmarshal post: %w", err)
+		}
+
+		p.LastRun = at
+
+		j, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to marshal post: %w", err)
+		}
+
+		_, err = tx.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, redisKey, field, j)
+			return nil
+		})
+
+		return err
+	}, redisKey)
+
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to mark post run: %w", err)
+	}
+
+	return err
+}
+
+// PurgeUser removes the user as the creator of the posts they scheduled,
+// returning how many there were. The posts themselves are the channel's, and
+// are kept. If dryRun is true, they're counted but not changed. It satisfies
+// purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		// noop
+	}
+
+	var n int
+
+	// don't overwrite posts changed while we're updating them
+	err := s.r.Watch(ctx, func(tx *redis.Tx) error {
+		vals, err := tx.HGetAll(ctx, redisKey).Result()
+		if err != nil {
+			return fmt.Errorf("failed to get posts: %w", err)
+		}
+
+		updates := make(map[string]interface{})
+
+		for field, v := range vals {
+			var p Post
+
+			if err := json.Unmarshal([]byte(v), &p); err != nil {
+				return fmt.Errorf("failed to unmarshal post: %w", err)
+			}
+
+			if p.CreatedBy != userID {
+				continue
+			}
+
+			p.CreatedBy = ""
+
+			j, err := json.Marshal(p)
+			if err != nil {
+				return fmt.Errorf("failed to marshal post: %w", err)
+			}
+
+			updates[field] = j
+		}
+
+		n = len(updates)
+
+		if n == 0 || dryRun {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, redisKey, updates)
+			return nil
+		})
+
+		return err
+	}, redisKey)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge creator %s from posts: %w", userID, err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,48 @@
+package scheduled
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPost_Next(t *testing.T) {
+	created := time.Date(2026, time.October, 14, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		post    Post
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "never_run",
+			post: Post{Schedule: "0 9 * * MON", CreatedAt: created},
+			want: time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "after_last_run",
+			post: Post{Schedule: "0 9 * * MON", CreatedAt: created, LastRun: time.Date(2026, time.October, 19, 9, 0, 5, 0, time.UTC)},
+			want: time.Date(2026, time.October, 26, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid",
+			post:    Post{Schedule: "every monday", CreatedAt: created},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.post.Next()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Next() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Fatalf("Next() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Package secrets encrypts tokens and signing secrets at rest, using AES-GCM
+// with master keys provided through the environment.
+//
+// Several master keys can be configured at once, each with an ID that's saved
+// alongside the values it encrypts. New values are always encrypted with the
+// primary (first) key, while any of the keys can decrypt. To rotate, add a new
+// key in front of the old one, re-encrypt the stored values, and then remove
+// the old key.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// prefix marks an encrypted value, and is followed by the key ID and the
+// base64-encoded nonce and ciphertext, separated by colons.
+const prefix = "enc:v1:"
+
+// KeySize is the size of each master key, for AES-256.
+const KeySize = 32
+
+// Keyring holds the master keys, by ID.
+type Keyring struct {
+	primary string
+	ids     []string
+	aeads   map[string]cipher.AEAD
+}
+
+// ParseKeyring parses comma-separated id=key pairs, where each key is
+// KeySize bytes encoded as standard base64. The first key is the primary one.
+func ParseKeyring(s string) (*Keyring, error) {
+	k := &Keyring{aeads: make(map[string]cipher.AEAD)}
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("invalid key %q, want id=base64", pair)
+		}
+
+		id := kv[0]
+
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("key ID %q cannot contain a colon", id)
+		}
+
+		if _, ok := k.aeads[id]; ok {
+			return nil, fmt.Errorf("duplicate key ID %q", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key %q: %w", id, err)
+		}
+
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("key %q is %d bytes, want %d", id, len(key), KeySize)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up key %q: %w", id, err)
+		}
+
+		if len(k.ids) == 0 {
+			k.primary = id
+		}
+
+		k.ids = append(k.ids, id)
+		k.aeads[id] = aead
+	}
+
+	if len(k.ids) == 0 {
+		return nil, fmt.Errorf("no keys provided")
+	}
+
+	return k, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// GenerateKey returns a new random master key, encoded for ParseKeyring.
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Primary returns the ID of the key new values are encrypted with.
+func (k *Keyring) Primary() string { return k.primary }
+
+// IDs returns the IDs of the keys, starting with the primary one.
+func (k *Keyring) IDs() []string {
+	if k == nil {
+		return nil
+	}
+
+	return append([]string(nil), k.ids...)
+}
+
+// Encrypt encrypts the plaintext with the primary key.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	aead := k.aeads[k.primary]
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// the key ID is authenticated, so it can't be swapped for another
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.primary))
+
+	return prefix + k.primary + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt, with whichever key it was
+// encrypted with.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	id, data, err := split(value)
+	if err != nil {
+		return "", err
+	}
+
+	aead, ok := k.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("value encrypted with unknown key %q", id)
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted value: %w", err)
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("encrypted value too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value with key %q: %w", id, err)
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsRotation returns whether the value should be re-encrypted, because it
+// isn't encrypted with the primary key.
+func (k *Keyring) NeedsRotation(value string) bool {
+	id, _, err := split(value)
+	return err != nil || id != k.primary
+}
+
+// IsEncrypted returns whether the value looks like it was returned by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func split(value string) (id, data string, err error) {
+	if !IsEncrypted(value) {
+		return "", "", fmt.Errorf("value is not encrypted")
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(value, prefix), ":", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 {
+		return "", "", fmt.Errorf("malformed encrypted value")
+	}
+
+	return parts[0], parts[1], nil
+}
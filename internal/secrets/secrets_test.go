@@ -0,0 +1,139 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	testKeyA = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+	testKeyB = "ICEiIyQlJicoKSorLC0uLzAxMjM0NTY3ODk6Ozw9Pj8="
+)
+
+func TestParseKeyring(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "single",
+			input: "k1=" + testKeyA,
+			want:  []string{"k1"},
+		},
+		{
+			name:  "rotation",
+			input: " k2=" + testKeyB + ", k1=" + testKeyA + ",",
+			want:  []string{"k2", "k1"},
+		},
+		{
+			name:    "empty",
+			input:   " , ",
+			wantErr: "no keys provided",
+		},
+		{
+			name:    "missing_id",
+			input:   "=" + testKeyA,
+			wantErr: "want id=base64",
+		},
+		{
+			name:    "short_key",
+			input:   "k1=AAEC",
+			wantErr: "is 3 bytes, want 32",
+		},
+		{
+			name:    "duplicate",
+			input:   "k1=" + testKeyA + ",k1=" + testKeyB,
+			wantErr: `duplicate key ID "k1"`,
+		},
+		{
+			name:    "colon",
+			input:   "k:1=" + testKeyA,
+			wantErr: "cannot contain a colon",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := ParseKeyring(tt.input)
+
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseKeyring() error = %v, should contain %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseKeyring() unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, k.IDs()); diff != "" {
+				t.Fatalf("IDs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestKeyring_rotation(t *testing.T) {
+	oldRing, err := ParseKeyring("k1=" + testKeyA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRing, err := ParseKeyring("k2=" + testKeyB + ",k1=" + testKeyA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc, err := oldRing.Encrypt("xoxb-123")
+	if err != nil {
+		t.Fatalf("Encrypt() unexpected error: %v", err)
+	}
+
+	if !IsEncrypted(enc) || strings.Contains(enc, "xoxb-123") {
+		t.Fatalf("Encrypt() = %q, doesn't look encrypted", enc)
+	}
+
+	if oldRing.NeedsRotation(enc) {
+		t.Fatal("NeedsRotation() = true with the same primary key")
+	}
+
+	if !newRing.NeedsRotation(enc) {
+		t.Fatal("NeedsRotation() = false after adding a new primary key")
+	}
+
+	got, err := newRing.Decrypt(enc)
+	if err != nil {
+		t.Fatalf("Decrypt() unexpected error: %v", err)
+	}
+
+	if got != "xoxb-123" {
+		t.Fatalf("Decrypt() = %q, want %q", got, "xoxb-123")
+	}
+
+	reenc, err := newRing.Encrypt(got)
+	if err != nil {
+		t.Fatalf("Encrypt() unexpected error: %v", err)
+	}
+
+	if _, err := oldRing.Decrypt(reenc); err == nil || !strings.Contains(err.Error(), `unknown key "k2"`) {
+		t.Fatalf("Decrypt() with old keyring error = %v, should be for unknown key", err)
+	}
+
+	// swapping the key ID must not decrypt, even if the key were known
+	swapped := strings.Replace(reenc, ":k2:", ":k1:", 1)
+
+	if _, err := newRing.Decrypt(swapped); err == nil {
+		t.Fatal("Decrypt() of value with swapped key ID should fail")
+	}
+
+	if _, err := newRing.Decrypt("xoxb-123"); err == nil {
+		t.Fatal("Decrypt() of plaintext should fail")
+	}
+}
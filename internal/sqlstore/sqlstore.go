@@ -0,0 +1,172 @@
+// Package sqlstore is the optional relational storage for the state that
+// shouldn't be lost if Redis evicts or expires it. It's used instead of Redis
+// by the subsystems that support it when a database is configured, and the
+// schema is migrated forward when it's opened.
+//
+// No database/sql driver is linked in by this package: the binary has to
+// import one, registered under the configured driver name. Queries are written
+// with $N placeholders and ON CONFLICT upserts, which both Postgres and SQLite
+// understand.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Migration is a change to the schema. Migrations are applied in Version
+// order, each in its own transaction, and are never edited once released:
+// changes go in a new migration.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// migrations are all the schema changes, in order.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "topicwatch_approved",
+		SQL: `CREATE TABLE topicwatch_approved (
+	channel_id TEXT NOT NULL,
+	field      TEXT NOT NULL,
+	value      TEXT NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (channel_id, field)
+)`,
+	},
+}
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INTEGER PRIMARY KEY,
+	applied_at TIMESTAMP NOT NULL
+)`
+
+// pingTimeout is how long Open waits for the database to respond.
+const pingTimeout = 10 * time.Second
+
+// Open connects to the database using the named driver, and applies any
+// migrations it's missing.
+func Open(ctx context.Context, driver, url string) (*sql.DB, error) {
+	if !registered(driver) {
+		return nil, fmt.Errorf("database/sql driver %q isn't linked into this binary", driver)
+	}
+
+	db, err := sql.Open(driver, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	pctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if err := db.PingContext(pctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := Migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func registered(driver string) bool {
+	for _, d := range sql.Drivers() {
+		if d == driver {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Migrate applies the migrations the database is missing.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, createMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+
+	var applied []int
+
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+
+		applied = append(applied, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+
+	_ = rows.Close()
+
+	for _, m := range pending(migrations, applied) {
+		if err := apply(ctx, db, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pending returns the migrations whose versions aren't applied, in order.
+func pending(ms []Migration, applied []int) []Migration {
+	done := make(map[int]struct{}, len(applied))
+	for _, v := range applied {
+		done[v] = struct{}{}
+	}
+
+	var p []Migration
+
+	for _, m := range ms {
+		if _, ok := done[m.Version]; !ok {
+			p = append(p, m)
+		}
+	}
+
+	sort.Slice(p, func(i, j int) bool { return p[i].Version < p[j].Version })
+
+	return p
+}
+
+func apply(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start migration %d (%s): %w", m.Version, m.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Name, err)
+	}
+
+	// if another process applied it first, the primary key fails this
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)`, m.Version, time.Now().UTC())
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to record migration %d (%s): %w", m.Version, m.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d (%s): %w", m.Version, m.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package sqlstore
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	_ "github.com/lib/pq"
+)
+
+func Test_pending(t *testing.T) {
+	ms := []Migration{{Version: 3}, {Version: 1}, {Version: 2}}
+
+	tests := []struct {
+		name    string
+		applied []int
+		want    []int
+	}{
+		{name: "none_applied", want: []int{1, 2, 3}},
+		{name: "some_applied", applied: []int{1}, want: []int{2, 3}},
+		{name: "gap", applied: []int{1, 3}, want: []int{2}},
+		{name: "all_applied", applied: []int{3, 2, 1}},
+		{name: "unknown_applied", applied: []int{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, m := range pending(ms, tt.applied) {
+				got = append(got, m.Version)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("pending() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_migrations(t *testing.T) {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("migration %q has version %d, want %d", m.Name, m.Version, i+1)
+		}
+	}
+}
+
+func Test_registered(t *testing.T) {
+	if !registered("postgres") {
+		t.Fatal(`registered("postgres") = false, want true`)
+	}
+
+	if registered("nosuchdriver") {
+		t.Fatal(`registered("nosuchdriver") = true, want false`)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver string
+		url    string
+		err    string
+	}{
+		{
+			name:   "unlinked_driver",
+			driver: "nosuchdriver",
+			url:    "nosuchdriver://localhost",
+			err:    `database/sql driver "nosuchdriver" isn't linked into this binary`,
+		},
+		{
+			name:   "unreachable",
+			driver: "postgres",
+			url:    "postgres://gopher@127.0.0.1:1/gopher?sslmode=disable&connect_timeout=1",
+			err:    "failed to connect to database",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := Open(context.Background(), tt.driver, tt.url)
+			if err == nil {
+				_ = db.Close()
+				t.Fatal("Open() error = <nil>")
+			}
+
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("Open() error = %q, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestOpen_postgres migrates the database at GOPHER_TEST_DATABASE_URL, twice
+// to check reopening it is a no-op.
+func TestOpen_postgres(t *testing.T) {
+	url := os.Getenv("GOPHER_TEST_DATABASE_URL")
+	if len(url) == 0 {
+		t.Skip("GOPHER_TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		db, err := Open(ctx, "postgres", url)
+		if err != nil {
+			t.Fatalf("Open() unexpected error: %v", err)
+		}
+
+		var n int
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&n); err != nil {
+			_ = db.Close()
+			t.Fatalf("failed to count schema_migrations: %v", err)
+		}
+
+		if _, err := db.ExecContext(ctx, `SELECT channel_id, field, value, updated_at FROM topicwatch_approved`); err != nil {
+			_ = db.Close()
+			t.Fatalf("failed to query topicwatch_approved: %v", err)
+		}
+
+		_ = db.Close()
+
+		if n != len(migrations) {
+			t.Fatalf("schema_migrations has %d rows, want %d", n, len(migrations))
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// Package status is a small HTTP server reporting the health of each of a
+// process's subsystems as JSON, for uptime monitoring of the components that
+// don't otherwise serve HTTP.
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// State is how healthy a subsystem is.
+type State string
+
+const (
+	// OK is a healthy subsystem.
+	OK State = "ok"
+
+	// Degraded is a subsystem that's working, but not as it should be, like
+	// a poller whose last run failed.
+	Degraded State = "degraded"
+
+	// Down is a subsystem that's not working.
+	Down State = "down"
+)
+
+// severity orders the States, to find the worst.
+func (s State) severity() int {
+	switch s {
+	case OK:
+		return 0
+	case Degraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Report is a subsystem's health.
+type Report struct {
+	State State `json:"status"`
+
+	// Error is why the subsystem isn't OK, if it's known.
+	Error string `json:"error,omitempty"`
+
+	// Detail is any subsystem-specific information, like timestamps.
+	Detail interface{} `json:"detail,omitempty"`
+}
+
+// CheckFunc reports a subsystem's health.
+type CheckFunc func(ctx context.Context) Report
+
+// Freshness returns OK if last is within warn of now, Degraded if it's within
+// fail, and Down otherwise.
+func Freshness(last time.Time, warn, fail time.Duration) State {
+	switch age := time.Since(last); {
+	case age < warn:
+		return OK
+	case age < fail:
+		return Degraded
+	default:
+		return Down
+	}
+}
+
+// Beater is the part of *heartbeat.Heart the heartbeat check uses.
+type Beater interface {
+	LastBeat() time.Time
+	Warn() time.Duration
+	Fail() time.Duration
+}
+
+type heartbeatDetail struct {
+	LastBeat time.Time `json:"last_beat"`
+	Age      float64   `json:"age_seconds"`
+}
+
+// Heartbeat returns a CheckFunc for the freshness of the Redis heartbeat.
+func Heartbeat(b Beater) CheckFunc {
+	return func(context.Context) Report {
+		last := b.LastBeat()
+
+		r := Report{
+			State: Freshness(last, b.Warn(), b.Fail()),
+			Detail: heartbeatDetail{
+				LastBeat: last.UTC(),
+				Age:      time.Since(last).Seconds(),
+			},
+		}
+
+		if r.State != OK {
+			r.Error = "redis heartbeat is stale"
+		}
+
+		return r
+	}
+}
+
+// checkTimeout is how long all the checks have to finish.
+const checkTimeout = 5 * time.Second
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Server serves the status of the registered subsystems.
+type Server struct {
+	app     string
+	commit  string
+	started time.Time
+	logger  zerolog.Logger
+
+	mu     sync.Mutex
+	checks []check
+}
+
+// New returns a new *Server for the app running at commit.
+func New(app, commit string, logger zerolog.Logger) *Server {
+	return &Server{
+		app:     app,
+		commit:  commit,
+		started: time.Now(),
+		logger:  logger,
+	}
+}
+
+// Register adds a subsystem to the status.
+func (s *Server) Register(name string, fn CheckFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checks = append(s.checks, check{name: name, fn: fn})
+}
+
+type response struct {
+	State      State             `json:"status"`
+	App        string            `json:"app"`
+	Commit     string            `json:"commit"`
+	CheckedAt  time.Time         `json:"checked_at"`
+	Uptime     float64           `json:"uptime_seconds"`
+	Subsystems map[string]Report `json:"subsystems"`
+}
+
+// Check runs the checks concurrently, returning the worst State and each
+// subsystem's Report.
+func (s *Server) Check(ctx context.Context) (State, map[string]Report) {
+	s.mu.Lock()
+	checks := append([]check(nil), s.checks...)
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	reports := make([]Report, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, c := range checks {
+		wg.Add(1)
+
+		go func(i int, c check) {
+			defer wg.Done()
+			reports[i] = c.fn(ctx)
+		}(i, c)
+	}
+
+	wg.Wait()
+
+	state := OK
+	m := make(map[string]Report, len(checks))
+
+	for i, c := range checks {
+		if reports[i].State.severity() > state.severity() {
+			state = reports[i].State
+		}
+
+		m[c.name] = reports[i]
+	}
+
+	return state, m
+}
+
+// ServeHTTP serves the status as JSON from /_status, with a 503 status code if
+// any subsystem is down, a plain liveness check from /_ruok, and the process's
+// expvar metrics from /debug/vars.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/_ruok":
+		_, _ = io.WriteString(w, "imok")
+
+	case "/debug/vars":
+		expvar.Handler().ServeHTTP(w, r)
+
+	case "/_status":
+		state, reports := s.Check(r.Context())
+
+		resp := response{
+			State:      state,
+			App:        s.app,
+			Commit:     s.commit,
+			CheckedAt:  time.Now().UTC(),
+			Uptime:     time.Since(s.started).Seconds(),
+			Subsystems: reports,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+
+		if state == Down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			s.logger.Error().
+				Err(err).
+				Msg("failed to write status response")
+		}
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// Run serves the status on the TCP port until the context is canceled.
+func (s *Server) Run(ctx context.Context, port uint16) error {
+	addr := fmt.Sprintf("0.0.0.0:%d", port)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to open status HTTP socket: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler:      s,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: checkTimeout + 5*time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = srv.Shutdown(sctx)
+	}()
+
+	s.logger.Info().
+		Str("addr", addr).
+		Msg("serving status")
+
+	if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("failed to serve status: %w", err)
+	}
+
+	return nil
+}
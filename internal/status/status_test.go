@@ -0,0 +1,106 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rs/zerolog"
+)
+
+func TestFreshness(t *testing.T) {
+	tests := []struct {
+		name string
+		age  time.Duration
+		want State
+	}{
+		{name: "fresh", age: time.Second, want: OK},
+		{name: "stale", age: 5 * time.Second, want: Degraded},
+		{name: "gone", age: 10 * time.Second, want: Down},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Freshness(time.Now().Add(-tt.age), 4*time.Second, 8*time.Second); got != tt.want {
+				t.Fatalf("Freshness() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	report := func(s State) CheckFunc {
+		return func(context.Context) Report { return Report{State: s} }
+	}
+
+	tests := []struct {
+		name       string
+		checks     map[string]State
+		wantCode   int
+		wantStatus State
+	}{
+		{
+			name:       "no_checks",
+			wantCode:   http.StatusOK,
+			wantStatus: OK,
+		},
+		{
+			name:       "degraded",
+			checks:     map[string]State{"heartbeat": OK, "pollers": Degraded},
+			wantCode:   http.StatusOK,
+			wantStatus: Degraded,
+		},
+		{
+			name:       "down",
+			checks:     map[string]State{"heartbeat": Down, "pollers": Degraded},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: Down,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s := New("gopher-test", "deadbeef", zerolog.Nop())
+
+			for name, state := range tt.checks {
+				s.Register(name, report(state))
+			}
+
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/_status", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status code = %d, want %d", rec.Code, tt.wantCode)
+			}
+
+			var got response
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if got.State != tt.wantStatus {
+				t.Fatalf("status = %q, want %q", got.State, tt.wantStatus)
+			}
+
+			gotStates := make(map[string]State)
+			for name, r := range got.Subsystems {
+				gotStates[name] = r.State
+			}
+
+			want := tt.checks
+			if want == nil {
+				want = map[string]State{}
+			}
+
+			if diff := cmp.Diff(want, gotStates); diff != "" {
+				t.Fatalf("subsystems mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
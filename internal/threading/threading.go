@@ -0,0 +1,202 @@
+// Package threading stores the threading policies Workspace Admins set for
+// channels, overriding where the bot's responses go in them, and the threads
+// recent responses went in, so the responses to follow-ups join them.
+package threading
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKey            = "threading:channels"          // hash of channel ID to policy
+	redisFollowUpFormat = "threading:followup:%s:%s:%s" // channel, user, handler
+	redisTestKey        = "threading:test_key"
+
+	// FollowUpWindow is how long after responding to someone their
+	// follow-ups are responded to in the same thread.
+	FollowUpWindow = 10 * time.Minute
+)
+
+// Store stores the policies.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Threading returns the policy set for the channel, or an empty string if
+// there isn't one. It satisfies handler.ThreadingOverrides.
+func (s *Store) Threading(ctx context.Context, channelID string) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.HGet(ctx, redisKey, channelID)
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to HGET redis key: %w", err)
+	}
+
+	return res.Val(), nil
+}
+
+// Set sets the channel's policy.
+func (s *Store) Set(ctx context.Context, channelID, policy string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.HSet(ctx, redisKey, channelID, policy).Err(); err != nil {
+		return fmt.Errorf("failed to set threading policy of %s: %w", channelID, err)
+	}
+
+	return nil
+}
+
+// Clear removes the channel's policy.
+func (s *Store) Clear(ctx context.Context, channelID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.HDel(ctx, redisKey, channelID).Err(); err != nil {
+		return fmt.Errorf("failed to clear threading policy of %s: %w", channelID, err)
+	}
+
+	return nil
+}
+
+// Override is a channel's policy.
+type Override struct {
+	ChannelID string
+	Policy    string
+}
+
+// All returns every channel's policy, ordered by channel ID.
+func (s *Store) All(ctx context.Context) ([]Override, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	m, err := s.r.HGetAll(ctx, redisKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to HGETALL redis key: %w", err)
+	}
+
+	os := make([]Override, 0, len(m))
+	for id, p := range m {
+		os = append(os, Override{ChannelID: id, Policy: p})
+	}
+
+	sort.Slice(os, func(i, j int) bool { return os[i].ChannelID < os[j].ChannelID })
+
+	return os, nil
+}
+
+// FollowUpThread returns the thread the responses from the handler to the user
+// in the channel went in, within the FollowUpWindow, or an empty string if
+// there weren't any. It satisfies handler.FollowUps.
+func (s *Store) FollowUpThread(ctx context.Context, channelID, userID, key string) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, fmt.Sprintf(redisFollowUpFormat, channelID, userID, key))
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	return res.Val(), nil
+}
+
+// RememberFollowUp records the thread the responses from the handler to the
+// user in the channel went in, for the FollowUpWindow from now. It satisfies
+// handler.FollowUps.
+func (s *Store) RememberFollowUp(ctx context.Context, channelID, userID, key, threadTS string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.Set(ctx, fmt.Sprintf(redisFollowUpFormat, channelID, userID, key), threadTS, FollowUpWindow).Err(); err != nil {
+		return fmt.Errorf("failed to SET redis key: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeUser deletes the threads recent responses to the user went in,
+// returning how many there were. If dryRun is true, they're counted but not
+// deleted. It satisfies purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	var cursor uint64
+	var found []string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+			// noop
+		}
+
+		keys, next, err := s.r.Scan(ctx, cursor, fmt.Sprintf(redisFollowUpFormat, "*", userID, "*"), 100).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to SCAN redis keys: %w", err)
+		}
+
+		found = append(found, keys...)
+
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	if len(found) == 0 || dryRun {
+		return len(found), nil
+	}
+
+	if err := s.r.Del(ctx, found...).Err(); err != nil {
+		return 0, fmt.Errorf("failed to purge follow-up threads of %s: %w", userID, err)
+	}
+
+	return len(found), nil
+}
@@ -0,0 +1,222 @@
+// Package topicwatch decides what to do when someone changes the topic or
+// purpose of one of the workspace's key channels: every change is reported to
+// the moderators, and in channels where it's enabled, changes by anyone other
+// than a Workspace Admin are reverted to the last value an admin set.
+package topicwatch
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Mode is how changes in a watched channel are handled.
+type Mode string
+
+const (
+	// Log only reports changes to the moderators.
+	Log Mode = "log"
+
+	// Revert reports changes, and reverts those not made by an admin.
+	Revert Mode = "revert"
+)
+
+// ParseMode parses the string representation of a Mode.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(strings.ToLower(s)) {
+	case Log:
+		return Log, nil
+	case Revert:
+		return Revert, nil
+	default:
+		return "", fmt.Errorf("unknown topic watch mode %q", s)
+	}
+}
+
+// Field is which of a channel's descriptions changed.
+type Field string
+
+const (
+	// Topic is the channel's topic, shown in its header.
+	Topic Field = "topic"
+
+	// Purpose is the channel's purpose, or description.
+	Purpose Field = "purpose"
+)
+
+// Change is a change to a channel's topic or purpose.
+type Change struct {
+	ChannelID string
+	Field     Field
+	UserID    string
+	Value     string
+
+	// ByAdmin is whether the user is a Workspace Admin or Owner.
+	ByAdmin bool
+}
+
+// Decision is what to do about a Change.
+type Decision struct {
+	// Report is whether to tell the moderators about the change.
+	Report bool
+
+	// Revert is whether to change it back.
+	Revert bool
+
+	// Approve is whether the new value is the one to revert to from now on.
+	Approve bool
+}
+
+// Policy is how changes in each watched channel are handled.
+type Policy struct {
+	modes map[string]Mode
+}
+
+// NewPolicy returns a *Policy from the channel modes in the configuration, see
+// config.M.TopicWatch. Channels not listed aren't watched.
+func NewPolicy(m map[string]string) (*Policy, error) {
+	modes := make(map[string]Mode, len(m))
+
+	for id, s := range m {
+		mode, err := ParseMode(s)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", id, err)
+		}
+
+		modes[id] = mode
+	}
+
+	return &Policy{modes: modes}, nil
+}
+
+// Watched returns whether changes in the channel are handled at all.
+func (p *Policy) Watched(channelID string) bool {
+	_, ok := p.modes[channelID]
+	return ok
+}
+
+// Decide returns what to do about the change.
+func (p *Policy) Decide(c Change) Decision {
+	mode, ok := p.modes[c.ChannelID]
+	if !ok {
+		return Decision{}
+	}
+
+	if c.ByAdmin {
+		return Decision{Report: true, Approve: true}
+	}
+
+	return Decision{Report: true, Revert: mode == Revert}
+}
+
+const (
+	redisKeyFormat = "topicwatch:%s:%s" // channel, field
+	redisTestKey   = "topicwatch:test_key"
+)
+
+// Approvals stores the approved topic and purpose of each watched channel.
+type Approvals interface {
+	// Approve records the value as the one to revert the channel's field to.
+	Approve(ctx context.Context, channelID string, f Field, value string) error
+
+	// Approved returns the value to revert the channel's field to. If found
+	// is false, no value was approved yet.
+	Approved(ctx context.Context, channelID string, f Field) (value string, found bool, err error)
+}
+
+// Store stores the approved topic and purpose of each watched channel in
+// Redis.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Approve records the value as the one to revert the channel's field to.
+func (s *Store) Approve(ctx context.Context, channelID string, f Field, value string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.Set(ctx, fmt.Sprintf(redisKeyFormat, channelID, f), value, 0).Err(); err != nil {
+		return fmt.Errorf("failed to approve %s of %s: %w", f, channelID, err)
+	}
+
+	return nil
+}
+
+// Approved returns the value to revert the channel's field to. If found is
+// false, no value was approved yet.
+func (s *Store) Approved(ctx context.Context, channelID string, f Field) (value string, found bool, err error) {
+	select {
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, fmt.Sprintf(redisKeyFormat, channelID, f))
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return "", false, nil
+		}
+
+		return "", false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	return res.Val(), true, nil
+}
+
+// SQLStore stores the approved topic and purpose of each watched channel in
+// the database, in the topicwatch_approved table.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore returns a new *SQLStore. The database must already be migrated.
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// Approve satisfies Approvals.
+func (s *SQLStore) Approve(ctx context.Context, channelID string, f Field, value string) error {
+	const q = `INSERT INTO topicwatch_approved (channel_id, field, value, updated_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (channel_id, field) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`
+
+	if _, err := s.db.ExecContext(ctx, q, channelID, string(f), value, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to approve %s of %s: %w", f, channelID, err)
+	}
+
+	return nil
+}
+
+// Approved satisfies Approvals.
+func (s *SQLStore) Approved(ctx context.Context, channelID string, f Field) (value string, found bool, err error) {
+	const q = `SELECT value FROM topicwatch_approved WHERE channel_id = $1 AND field = $2`
+
+	if err := s.db.QueryRowContext(ctx, q, channelID, string(f)).Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, nil
+		}
+
+		return "", false, fmt.Errorf("failed to query approved %s of %s: %w", f, channelID, err)
+	}
+
+	return value, true, nil
+}
@@ -0,0 +1,63 @@
+package topicwatch
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPolicy_Decide(t *testing.T) {
+	p, err := NewPolicy(map[string]string{
+		"C1": "log",
+		"C2": "REVERT",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		c    Change
+		want Decision
+	}{
+		{
+			name: "unwatched",
+			c:    Change{ChannelID: "C3", Field: Topic, UserID: "U1"},
+			want: Decision{},
+		},
+		{
+			name: "log_member",
+			c:    Change{ChannelID: "C1", Field: Topic, UserID: "U1"},
+			want: Decision{Report: true},
+		},
+		{
+			name: "log_admin",
+			c:    Change{ChannelID: "C1", Field: Purpose, UserID: "U1", ByAdmin: true},
+			want: Decision{Report: true, Approve: true},
+		},
+		{
+			name: "revert_member",
+			c:    Change{ChannelID: "C2", Field: Topic, UserID: "U1"},
+			want: Decision{Report: true, Revert: true},
+		},
+		{
+			name: "revert_admin",
+			c:    Change{ChannelID: "C2", Field: Purpose, UserID: "U1", ByAdmin: true},
+			want: Decision{Report: true, Approve: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, p.Decide(tt.c)); diff != "" {
+				t.Fatalf("Decide() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewPolicy_badMode(t *testing.T) {
+	if _, err := NewPolicy(map[string]string{"C1": "delete"}); err == nil {
+		t.Fatal("NewPolicy() error = <nil>, want an error")
+	}
+}
@@ -0,0 +1,277 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// LogExporter exports spans by logging them at the debug level.
+type LogExporter struct {
+	l zerolog.Logger
+}
+
+var _ Exporter = LogExporter{}
+
+// NewLogExporter returns a LogExporter.
+func NewLogExporter(logger zerolog.Logger) LogExporter {
+	return LogExporter{l: logger}
+}
+
+// Export satisfies Exporter.
+func (e LogExporter) Export(s SpanData) {
+	ev := e.l.Debug()
+	if !ev.Enabled() {
+		return
+	}
+
+	ev = ev.
+		Str("span", s.Name).
+		Str("trace_id", s.Context.TraceID.String()).
+		Str("span_id", s.Context.SpanID.String()).
+		Dur("span_duration", s.End.Sub(s.Start))
+
+	if s.Parent.IsValid() {
+		ev = ev.Str("parent_span_id", s.Parent.String())
+	}
+
+	if len(s.Attributes) > 0 {
+		ev = ev.Interface("attributes", s.Attributes)
+	}
+
+	if len(s.Error) > 0 {
+		ev = ev.Str("error", s.Error)
+	}
+
+	ev.Msg("span ended")
+}
+
+const (
+	// otlpBatchSize is the most spans sent to the collector at once.
+	otlpBatchSize = 100
+
+	// otlpFlushInterval is how often spans are sent to the collector, if
+	// there aren't enough to fill a batch sooner.
+	otlpFlushInterval = 5 * time.Second
+
+	// otlpQueueSize is how many spans can be waiting to be sent before new
+	// ones are dropped, so a slow collector doesn't slow us down.
+	otlpQueueSize = 1000
+)
+
+// OTLPExporter exports spans to an OpenTelemetry collector, using OTLP's JSON
+// encoding over HTTP. Spans are batched and sent in the background.
+type OTLPExporter struct {
+	url     string
+	service string
+	http    *http.Client
+	l       zerolog.Logger
+	spans   chan SpanData
+}
+
+var _ Exporter = (*OTLPExporter)(nil)
+
+// NewOTLPExporter returns an *OTLPExporter sending to the collector at
+// endpoint, like http://localhost:4318, as service. It sends until the context
+// is canceled, at which point it sends what's left.
+func NewOTLPExporter(ctx context.Context, endpoint, service string, httpc *http.Client, logger zerolog.Logger) *OTLPExporter {
+	if httpc == nil {
+		httpc = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	e := &OTLPExporter{
+		url:     strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		service: service,
+		http:    httpc,
+		l:       logger,
+		spans:   make(chan SpanData, otlpQueueSize),
+	}
+
+	go e.run(ctx)
+
+	return e
+}
+
+// Export satisfies Exporter.
+func (e *OTLPExporter) Export(s SpanData) {
+	select {
+	case e.spans <- s:
+	default:
+		e.l.Warn().
+			Str("span", s.Name).
+			Msg("span queue full: dropping span")
+	}
+}
+
+func (e *OTLPExporter) run(ctx context.Context) {
+	t := time.NewTicker(otlpFlushInterval)
+	defer t.Stop()
+
+	batch := make([]SpanData, 0, otlpBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := e.send(batch); err != nil {
+			e.l.Error().
+				Err(err).
+				Int("spans", len(batch)).
+				Msg("failed to send spans to collector")
+		}
+
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case s := <-e.spans:
+					batch = append(batch, s)
+				default:
+					flush()
+					return
+				}
+			}
+
+		case s := <-e.spans:
+			batch = append(batch, s)
+
+			if len(batch) >= otlpBatchSize {
+				flush()
+			}
+
+		case <-t.C:
+			flush()
+		}
+	}
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// otlpStatusError is the OTLP status code for a failed span.
+const otlpStatusError = 2
+
+func attributes(m map[string]string) []otlpAttribute {
+	if len(m) == 0 {
+		return nil
+	}
+
+	attrs := make([]otlpAttribute, 0, len(m))
+
+	for k, v := range m {
+		attrs = append(attrs, otlpAttribute{Key: k, Value: otlpValue{StringValue: v}})
+	}
+
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+
+	return attrs
+}
+
+func (e *OTLPExporter) request(batch []SpanData) otlpRequest {
+	ss := otlpScopeSpans{Spans: make([]otlpSpan, 0, len(batch))}
+	ss.Scope.Name = "github.com/gobridge/gopherbot/internal/trace"
+
+	for _, s := range batch {
+		sp := otlpSpan{
+			TraceID:           s.Context.TraceID.String(),
+			SpanID:            s.Context.SpanID.String(),
+			Name:              s.Name,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        attributes(s.Attributes),
+		}
+
+		if s.Parent.IsValid() {
+			sp.ParentSpanID = s.Parent.String()
+		}
+
+		if len(s.Error) > 0 {
+			sp.Status = otlpStatus{Code: otlpStatusError, Message: s.Error}
+		}
+
+		ss.Spans = append(ss.Spans, sp)
+	}
+
+	rs := otlpResourceSpans{ScopeSpans: []otlpScopeSpans{ss}}
+	rs.Resource.Attributes = attributes(map[string]string{"service.name": e.service})
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{rs}}
+}
+
+func (e *OTLPExporter) send(batch []SpanData) error {
+	body, err := json.Marshal(e.request(batch))
+	if err != nil {
+		return fmt.Errorf("failed to marshal spans: %w", err)
+	}
+
+	resp, err := e.http.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send spans: %w", err)
+	}
+
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+// Package trace provides spans for following an event from the gateway,
+// through the workqueue, to the consumer's handlers, so slow or lost events
+// can be traced end to end.
+//
+// It implements the small part of OpenTelemetry tracing we need: spans are
+// propagated between processes using the W3C traceparent format, and can be
+// exported to an OpenTelemetry collector using OTLP, or to the logs.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// TraceID identifies a trace, which is made up of spans.
+type TraceID [16]byte
+
+// String returns the ID in hex.
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsValid returns whether the ID isn't all zeros.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the ID in hex.
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsValid returns whether the ID isn't all zeros.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext is what's propagated to identify the parent of a span.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// TraceParent returns the span context in the W3C traceparent format.
+func (sc SpanContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-01", sc.TraceID, sc.SpanID)
+}
+
+// ParseTraceParent parses a span context in the W3C traceparent format.
+func ParseTraceParent(s string) (SpanContext, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return SpanContext{}, fmt.Errorf("malformed traceparent %q", s)
+	}
+
+	var sc SpanContext
+
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, fmt.Errorf("malformed traceparent trace ID: %w", err)
+	}
+
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, fmt.Errorf("malformed traceparent span ID: %w", err)
+	}
+
+	if !sc.TraceID.IsValid() || !sc.SpanID.IsValid() {
+		return SpanContext{}, errors.New("traceparent IDs cannot be zero")
+	}
+
+	return sc, nil
+}
+
+// TraceIDFromRequestID returns the trace ID for a request ID, like Heroku's
+// X-Request-ID, so the trace can be found from the request's logs. A request
+// ID that's a UUID is used as-is, and anything else is hashed.
+func TraceIDFromRequestID(rid string) TraceID {
+	var t TraceID
+
+	if h := strings.ReplaceAll(rid, "-", ""); len(h) == 32 {
+		if _, err := hex.Decode(t[:], []byte(h)); err == nil && t.IsValid() {
+			return t
+		}
+	}
+
+	sum := sha256.Sum256([]byte(rid))
+	copy(t[:], sum[:])
+
+	return t
+}
+
+type ctxKey struct{}
+
+// ContextWithSpanContext returns a context with sc as the parent for spans
+// started from it, such as one propagated from another process.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, ctxKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of the current span, and
+// whether there is one.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(ctxKey{}).(SpanContext)
+	return sc, ok
+}
+
+// SpanData is a finished span, as given to an Exporter.
+type SpanData struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanID
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	Error      string
+}
+
+// Exporter sends finished spans somewhere, like a collector.
+type Exporter interface {
+	Export(s SpanData)
+}
+
+// Tracer starts spans. A nil *Tracer is valid, and its spans do nothing.
+type Tracer struct {
+	exp Exporter
+}
+
+// New returns a new *Tracer exporting to exp.
+func New(exp Exporter) *Tracer {
+	return &Tracer{exp: exp}
+}
+
+// Start starts a span, as a child of the span in the context if there is one
+// or as the root of a new trace if not. The returned context carries the new
+// span, for starting its children.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	return t.StartAt(ctx, name, time.Now())
+}
+
+// StartAt is like Start, but the span starts at a time in the past, like when
+// an event was enqueued.
+func (t *Tracer) StartAt(ctx context.Context, name string, start time.Time) (context.Context, *Span) {
+	if t == nil {
+		return ctx, &Span{}
+	}
+
+	s := &Span{
+		t: t,
+		d: SpanData{
+			Name:  name,
+			Start: start,
+		},
+	}
+
+	if parent, ok := SpanContextFromContext(ctx); ok && parent.TraceID.IsValid() {
+		s.d.Context.TraceID = parent.TraceID
+		s.d.Parent = parent.SpanID
+	} else {
+		randomBytes(s.d.Context.TraceID[:])
+	}
+
+	randomBytes(s.d.Context.SpanID[:])
+
+	return ContextWithSpanContext(ctx, s.d.Context), s
+}
+
+// randomBytes fills b from crypto/rand, which doesn't fail on the platforms we
+// run on.
+func randomBytes(b []byte) {
+	_, _ = io.ReadFull(rand.Reader, b)
+}
+
+// Span is an operation being traced. Its methods are safe to call on a span
+// from a nil *Tracer, but not concurrently.
+type Span struct {
+	t     *Tracer
+	d     SpanData
+	ended bool
+}
+
+// SpanContext returns the span's context, for propagating it to another
+// process.
+func (s *Span) SpanContext() SpanContext {
+	return s.d.Context
+}
+
+// SetAttribute records something about the operation.
+func (s *Span) SetAttribute(key, value string) {
+	if s.t == nil {
+		return
+	}
+
+	if s.d.Attributes == nil {
+		s.d.Attributes = make(map[string]string)
+	}
+
+	s.d.Attributes[key] = value
+}
+
+// RecordError marks the operation as failed.
+func (s *Span) RecordError(err error) {
+	if s.t == nil || err == nil {
+		return
+	}
+
+	s.d.Error = err.Error()
+}
+
+// End finishes the span and exports it. Calls after the first do nothing.
+func (s *Span) End() {
+	if s.t == nil || s.ended {
+		return
+	}
+
+	s.ended = true
+	s.d.End = time.Now()
+
+	s.t.exp.Export(s.d)
+}
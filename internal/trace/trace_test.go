@@ -0,0 +1,160 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			input: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		{
+			name:    "version",
+			input:   "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantErr: "malformed traceparent",
+		},
+		{
+			name:    "short",
+			input:   "00-4bf92f3577b34da6-00f067aa0ba902b7-01",
+			wantErr: "malformed traceparent",
+		},
+		{
+			name:    "not_hex",
+			input:   "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01",
+			wantErr: "malformed traceparent trace ID",
+		},
+		{
+			name:    "zero",
+			input:   "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			wantErr: "cannot be zero",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceParent(tt.input)
+
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseTraceParent() error = %v, should contain %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseTraceParent() unexpected error: %v", err)
+			}
+
+			if got := sc.TraceParent(); got != tt.want {
+				t.Fatalf("TraceParent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceIDFromRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		rid  string
+		want string
+	}{
+		{
+			name: "uuid",
+			rid:  "4bf92f35-77b3-4da6-a3ce-929d0e0e4736",
+			want: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name: "other",
+			rid:  "abc123",
+			want: "6ca13d52ca70c883e0f0bb101e425a89",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TraceIDFromRequestID(tt.rid).String(); got != tt.want {
+				t.Fatalf("TraceIDFromRequestID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type recorder struct {
+	spans []SpanData
+}
+
+func (r *recorder) Export(s SpanData) {
+	r.spans = append(r.spans, s)
+}
+
+func TestTracer_Start(t *testing.T) {
+	r := &recorder{}
+	tr := New(r)
+
+	remote := SpanContext{TraceID: TraceIDFromRequestID("4bf92f35-77b3-4da6-a3ce-929d0e0e4736")}
+
+	ctx, parent := tr.Start(ContextWithSpanContext(context.Background(), remote), "parent")
+
+	_, child := tr.Start(ctx, "child")
+	child.SetAttribute("handler", "message")
+	child.RecordError(errors.New("boom"))
+	child.End()
+	child.End()
+
+	parent.End()
+
+	if len(r.spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(r.spans))
+	}
+
+	c, p := r.spans[0], r.spans[1]
+
+	if p.Context.TraceID != remote.TraceID || c.Context.TraceID != remote.TraceID {
+		t.Errorf("spans should continue the remote trace %s, got %s and %s", remote.TraceID, p.Context.TraceID, c.Context.TraceID)
+	}
+
+	if p.Parent.IsValid() {
+		t.Errorf("parent span should have no parent span ID, got %s", p.Parent)
+	}
+
+	if c.Parent != p.Context.SpanID {
+		t.Errorf("child's parent = %s, want %s", c.Parent, p.Context.SpanID)
+	}
+
+	if diff := cmp.Diff(map[string]string{"handler": "message"}, c.Attributes); diff != "" {
+		t.Errorf("Attributes mismatch (-want +got):\n%s", diff)
+	}
+
+	if c.Error != "boom" {
+		t.Errorf("Error = %q, want %q", c.Error, "boom")
+	}
+}
+
+func TestTracer_Start_nil(t *testing.T) {
+	var tr *Tracer
+
+	ctx, s := tr.Start(context.Background(), "noop")
+	s.SetAttribute("k", "v")
+	s.RecordError(errors.New("boom"))
+	s.End()
+
+	if _, ok := SpanContextFromContext(ctx); ok {
+		t.Fatal("nil *Tracer should not add a span to the context")
+	}
+}
@@ -0,0 +1,201 @@
+// Package undo remembers who triggered the bot's recent responses, so they can
+// have them deleted for a short while after they're sent.
+package undo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisReplyFormat  = "undo:reply:%s:%s"     // channel, ts; value is the user who triggered it
+	redisThreadFormat = "undo:thread:%s:%s:%s" // channel, thread, user; value is the ts of the latest reply
+	redisTestKey      = "undo:test_key"
+
+	// Window is how long after a response is sent it can be deleted.
+	Window = 10 * time.Minute
+)
+
+// Store stores the recent responses.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// RememberReply records that the response with the timestamp ts, in the thread
+// threadTS if it's in one, was triggered by the user, for the Window from now.
+// It satisfies handler.Replies.
+func (s *Store) RememberReply(ctx context.Context, channelID, threadTS, ts, userID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	// a response that isn't in a thread can have replies in its own
+	if len(threadTS) == 0 {
+		threadTS = ts
+	}
+
+	_, err := s.r.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, fmt.Sprintf(redisReplyFormat, channelID, ts), userID, Window)
+		p.Set(ctx, fmt.Sprintf(redisThreadFormat, channelID, threadTS, userID), ts, Window)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remember reply %s in %s: %w", ts, channelID, err)
+	}
+
+	return nil
+}
+
+// TriggeredBy returns who triggered the response with the timestamp ts. If
+// found is false, it's not a response, or it's older than the Window.
+func (s *Store) TriggeredBy(ctx context.Context, channelID, ts string) (userID string, found bool, err error) {
+	select {
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	default:
+		// noop
+	}
+
+	userID, err = s.r.Get(ctx, fmt.Sprintf(redisReplyFormat, channelID, ts)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", false, nil
+		}
+
+		return "", false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	return userID, true, nil
+}
+
+// LatestReply returns the timestamp of the latest response to the user in the
+// thread, within the Window. If found is false, there wasn't one.
+func (s *Store) LatestReply(ctx context.Context, channelID, threadTS, userID string) (ts string, found bool, err error) {
+	select {
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	default:
+		// noop
+	}
+
+	ts, err = s.r.Get(ctx, fmt.Sprintf(redisThreadFormat, channelID, threadTS, userID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", false, nil
+		}
+
+		return "", false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	return ts, true, nil
+}
+
+// Forget removes the response, once it's deleted.
+func (s *Store) Forget(ctx context.Context, channelID, ts string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	if err := s.r.Del(ctx, fmt.Sprintf(redisReplyFormat, channelID, ts)).Err(); err != nil {
+		return fmt.Errorf("failed to DEL redis key: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeUser deletes the records of the responses the user triggered, returning
+// how many there were. If dryRun is true, they're counted but not deleted, and
+// once deleted the user can't have those responses deleted. It satisfies
+// purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	var found []string
+
+	threadKeys, err := s.scan(ctx, fmt.Sprintf(redisThreadFormat, "*", "*", userID))
+	if err != nil {
+		return 0, err
+	}
+
+	found = append(found, threadKeys...)
+
+	// the user is the value of the reply keys, not in their names
+	replyKeys, err := s.scan(ctx, fmt.Sprintf(redisReplyFormat, "*", "*"))
+	if err != nil {
+		return 0, err
+	}
+
+	for len(replyKeys) > 0 {
+		n := len(replyKeys)
+		if n > 100 {
+			n = 100
+		}
+
+		vals, err := s.r.MGet(ctx, replyKeys[:n]...).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to MGET redis keys: %w", err)
+		}
+
+		for i, v := range vals {
+			if id, ok := v.(string); ok && id == userID {
+				found = append(found, replyKeys[i])
+			}
+		}
+
+		replyKeys = replyKeys[n:]
+	}
+
+	if len(found) == 0 || dryRun {
+		return len(found), nil
+	}
+
+	if err := s.r.Del(ctx, found...).Err(); err != nil {
+		return 0, fmt.Errorf("failed to purge replies to %s: %w", userID, err)
+	}
+
+	return len(found), nil
+}
+
+// scan returns the keys matching the pattern.
+func (s *Store) scan(ctx context.Context, pattern string) ([]string, error) {
+	var cursor uint64
+	var keys []string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			// noop
+		}
+
+		page, next, err := s.r.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to SCAN redis keys: %w", err)
+		}
+
+		keys = append(keys, page...)
+
+		if cursor = next; cursor == 0 {
+			return keys, nil
+		}
+	}
+}
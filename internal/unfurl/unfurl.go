@@ -0,0 +1,379 @@
+// Package unfurl renders the previews the bot shows for links to Go's own
+// sites: the doc summary of packages on pkg.go.dev, the start of playground
+// snippets, and the status of CLs in Gerrit.
+package unfurl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	defaultPkgURL    = "https://pkg.go.dev"
+	defaultPlayURL   = "https://go.dev/play"
+	defaultGerritURL = "https://go-review.googlesource.com"
+)
+
+// maxPreviewLines is how many lines of a playground snippet are shown.
+const maxPreviewLines = 12
+
+// maxBodySize is the most read of any response.
+const maxBodySize = 1 << 20
+
+// Kind is the kind of page a link is to.
+type Kind int
+
+const (
+	// KindPackage is a package's documentation on pkg.go.dev.
+	KindPackage Kind = iota + 1
+
+	// KindPlayground is a shared playground snippet.
+	KindPlayground
+
+	// KindCL is a CL in the Go project's Gerrit.
+	KindCL
+)
+
+// Link is a link the bot can unfurl.
+type Link struct {
+	Kind Kind
+
+	// URL is the link as it was shared, which is how Slack identifies it
+	// when it's unfurled.
+	URL string
+
+	// Path is the package's import path, and Symbol the identifier its
+	// fragment links to, if any, for KindPackage.
+	Path   string
+	Symbol string
+
+	// ID is the snippet's ID, for KindPlayground.
+	ID string
+
+	// CL is the CL's number, for KindCL.
+	CL int
+}
+
+var (
+	playPathRegexp   = regexp.MustCompile(`^/p/([A-Za-z0-9_-]+)(?:\.go)?/?$`)
+	clPathRegexp     = regexp.MustCompile(`^/cl/(\d+)/?$`)
+	gerritPathRegexp = regexp.MustCompile(`^/c/[^+]+/\+/(\d+)(?:/.*)?$`)
+	symbolRegexp     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+)
+
+// notPackages are the pkg.go.dev pages that aren't a package's documentation.
+var notPackages = map[string]struct{}{
+	"about":          {},
+	"badge":          {},
+	"license-policy": {},
+	"search":         {},
+	"search-help":    {},
+	"static":         {},
+	"std":            {},
+}
+
+// Parse returns the Link for a URL, and whether it's one the bot unfurls.
+func Parse(rawURL string) (Link, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return Link{}, false
+	}
+
+	l := Link{URL: rawURL}
+
+	switch host := strings.ToLower(u.Hostname()); host {
+	case "pkg.go.dev":
+		p := strings.Trim(u.Path, "/")
+
+		// the site's own pages are all at the top level, like the
+		// standard library's top-level packages
+		if _, ok := notPackages[p]; ok || len(p) == 0 {
+			return Link{}, false
+		}
+
+		if i := strings.IndexByte(p, '@'); i != -1 {
+			rest := p[i:]
+			p = p[:i]
+
+			// keep any subpackage after the version
+			if j := strings.IndexByte(rest, '/'); j != -1 {
+				p += rest[j:]
+			}
+		}
+
+		l.Kind = KindPackage
+		l.Path = p
+
+		if symbolRegexp.MatchString(u.Fragment) {
+			l.Symbol = u.Fragment
+		}
+
+		return l, true
+
+	case "go.dev":
+		if strings.HasPrefix(u.Path, "/play/") {
+			return parsePlayground(l, strings.TrimPrefix(u.Path, "/play"))
+		}
+
+		return parseCL(l, u.Path, clPathRegexp)
+
+	case "play.golang.org":
+		return parsePlayground(l, u.Path)
+
+	case "golang.org":
+		return parseCL(l, u.Path, clPathRegexp)
+
+	case "go-review.googlesource.com":
+		return parseCL(l, u.Path, gerritPathRegexp)
+
+	default:
+		return Link{}, false
+	}
+}
+
+func parsePlayground(l Link, path string) (Link, bool) {
+	m := playPathRegexp.FindStringSubmatch(path)
+	if m == nil {
+		return Link{}, false
+	}
+
+	l.Kind = KindPlayground
+	l.ID = m[1]
+
+	return l, true
+}
+
+func parseCL(l Link, path string, re *regexp.Regexp) (Link, bool) {
+	m := re.FindStringSubmatch(path)
+	if m == nil {
+		return Link{}, false
+	}
+
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n <= 0 {
+		return Link{}, false
+	}
+
+	l.Kind = KindCL
+	l.CL = n
+
+	return l, true
+}
+
+// Renderer renders the unfurls, fetching what they show from the sites.
+type Renderer struct {
+	httpc     *http.Client
+	pkgURL    string
+	playURL   string
+	gerritURL string
+}
+
+// New returns a *Renderer.
+func New(httpc *http.Client) *Renderer {
+	return &Renderer{
+		httpc:     httpc,
+		pkgURL:    defaultPkgURL,
+		playURL:   defaultPlayURL,
+		gerritURL: defaultGerritURL,
+	}
+}
+
+// Render returns the unfurl for the link.
+func (r *Renderer) Render(ctx context.Context, l Link) (slack.Attachment, error) {
+	switch l.Kind {
+	case KindPackage:
+		return r.pkg(ctx, l)
+	case KindPlayground:
+		return r.snippet(ctx, l)
+	case KindCL:
+		return r.cl(ctx, l)
+	default:
+		return slack.Attachment{}, fmt.Errorf("unknown kind of link %d", l.Kind)
+	}
+}
+
+var descriptionRegexp = regexp.MustCompile(`(?i)<meta\s+name="description"\s+content="([^"]*)"`)
+
+// pkg renders a package's synopsis, from the description pkg.go.dev gives the
+// page.
+func (r *Renderer) pkg(ctx context.Context, l Link) (slack.Attachment, error) {
+	body, err := r.get(ctx, r.pkgURL+"/"+l.Path)
+	if err != nil {
+		return slack.Attachment{}, err
+	}
+
+	title := l.Path
+	if len(l.Symbol) > 0 {
+		title = fmt.Sprintf("%s.%s", l.Path[strings.LastIndexByte(l.Path, '/')+1:], l.Symbol)
+	}
+
+	a := slack.Attachment{
+		Title:     title,
+		TitleLink: l.URL,
+		Footer:    "pkg.go.dev",
+	}
+
+	if m := descriptionRegexp.FindSubmatch(body); m != nil {
+		a.Text = strings.TrimSpace(html.UnescapeString(string(m[1])))
+	}
+
+	if len(l.Symbol) > 0 {
+		a.Footer = "pkg.go.dev | " + l.Path
+	}
+
+	return a, nil
+}
+
+// snippet renders the first lines of a playground snippet.
+func (r *Renderer) snippet(ctx context.Context, l Link) (slack.Attachment, error) {
+	body, err := r.get(ctx, r.playURL+"/p/"+l.ID+".go")
+	if err != nil {
+		return slack.Attachment{}, err
+	}
+
+	return slack.Attachment{
+		Title:      "Go Playground snippet",
+		TitleLink:  l.URL,
+		Text:       preview(string(body)),
+		MarkdownIn: []string{"text"},
+		Footer:     "go.dev/play",
+	}, nil
+}
+
+// preview returns the first lines of the code as a code block, escaped for
+// Slack, noting how many more lines there are.
+func preview(code string) string {
+	lines := strings.Split(strings.Trim(code, "\n"), "\n")
+
+	var more int
+
+	if len(lines) > maxPreviewLines {
+		more = len(lines) - maxPreviewLines
+		lines = lines[:maxPreviewLines]
+	}
+
+	s := strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		"```", "` ` `",
+	).Replace(strings.Join(lines, "\n"))
+
+	s = "```" + s + "```"
+
+	switch {
+	case more == 1:
+		s += "\n... and 1 more line"
+	case more > 1:
+		s += fmt.Sprintf("\n... and %d more lines", more)
+	}
+
+	return s
+}
+
+// change is the part of a Gerrit ChangeInfo the unfurl shows.
+type change struct {
+	Project    string `json:"project"`
+	Branch     string `json:"branch"`
+	Subject    string `json:"subject"`
+	Status     string `json:"status"`
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+	Owner      struct {
+		Name string `json:"name"`
+	} `json:"owner"`
+}
+
+// statuses are how Gerrit's change statuses are shown.
+var statuses = map[string]string{
+	"NEW":       "Open",
+	"MERGED":    "Merged",
+	"ABANDONED": "Abandoned",
+}
+
+// cl renders a CL's subject and status.
+func (r *Renderer) cl(ctx context.Context, l Link) (slack.Attachment, error) {
+	body, err := r.get(ctx, fmt.Sprintf("%s/changes/%d?o=DETAILED_ACCOUNTS", r.gerritURL, l.CL))
+	if err != nil {
+		return slack.Attachment{}, err
+	}
+
+	// Gerrit prefixes responses with `)]}'`
+	// https://gerrit-review.googlesource.com/Documentation/rest-api.html#output
+	body = bytes.TrimPrefix(body, []byte(")]}'"))
+
+	var c change
+
+	if err = json.Unmarshal(body, &c); err != nil {
+		return slack.Attachment{}, fmt.Errorf("failed to unmarshal JSON body: %w", err)
+	}
+
+	subject := c.Subject
+	if c.Project != "go" {
+		subject = fmt.Sprintf("[%s] %s", c.Project, subject)
+	}
+
+	status, ok := statuses[c.Status]
+	if !ok {
+		status = c.Status
+	}
+
+	a := slack.Attachment{
+		Title:     fmt.Sprintf("CL %d: %s", l.CL, subject),
+		TitleLink: l.URL,
+		Fields: []slack.AttachmentField{
+			{Title: "Status", Value: status, Short: true},
+			{Title: "Changes", Value: fmt.Sprintf("+%d -%d", c.Insertions, c.Deletions), Short: true},
+		},
+		Footer: "go-review.googlesource.com",
+	}
+
+	if len(c.Owner.Name) > 0 {
+		a.Fields = append(a.Fields, slack.AttachmentField{Title: "Owner", Value: c.Owner.Name, Short: true})
+	}
+
+	if c.Branch != "master" && len(c.Branch) > 0 {
+		a.Fields = append(a.Fields, slack.AttachmentField{Title: "Branch", Value: c.Branch, Short: true})
+	}
+
+	return a, nil
+}
+
+func (r *Renderer) get(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", "Gophers Slack bot")
+
+	resp, err := r.httpc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", u, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP response status getting %s: %s", u, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,257 @@
+package unfurl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/slack-go/slack"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		want   Link
+		wantOK bool
+	}{
+		{
+			name:   "std",
+			url:    "https://pkg.go.dev/net/http",
+			want:   Link{Kind: KindPackage, Path: "net/http"},
+			wantOK: true,
+		},
+		{
+			name:   "top_level_std",
+			url:    "https://pkg.go.dev/fmt#Println",
+			want:   Link{Kind: KindPackage, Path: "fmt", Symbol: "Println"},
+			wantOK: true,
+		},
+		{
+			name:   "method",
+			url:    "https://pkg.go.dev/net/http#Client.Do",
+			want:   Link{Kind: KindPackage, Path: "net/http", Symbol: "Client.Do"},
+			wantOK: true,
+		},
+		{
+			name:   "versioned",
+			url:    "https://pkg.go.dev/github.com/rs/zerolog@v1.18.0/log?tab=doc#section-documentation",
+			want:   Link{Kind: KindPackage, Path: "github.com/rs/zerolog/log"},
+			wantOK: true,
+		},
+		{
+			name: "pkgsite_page",
+			url:  "https://pkg.go.dev/search?q=http",
+		},
+		{
+			name: "pkgsite_home",
+			url:  "https://pkg.go.dev/",
+		},
+		{
+			name:   "playground",
+			url:    "https://go.dev/play/p/HmnNoBf0p1z",
+			want:   Link{Kind: KindPlayground, ID: "HmnNoBf0p1z"},
+			wantOK: true,
+		},
+		{
+			name:   "old_playground",
+			url:    "https://play.golang.org/p/HmnNoBf0p1z.go",
+			want:   Link{Kind: KindPlayground, ID: "HmnNoBf0p1z"},
+			wantOK: true,
+		},
+		{
+			name: "playground_home",
+			url:  "https://go.dev/play/",
+		},
+		{
+			name:   "cl",
+			url:    "https://go.dev/cl/123456",
+			want:   Link{Kind: KindCL, CL: 123456},
+			wantOK: true,
+		},
+		{
+			name:   "old_cl",
+			url:    "https://golang.org/cl/123456/",
+			want:   Link{Kind: KindCL, CL: 123456},
+			wantOK: true,
+		},
+		{
+			name:   "gerrit",
+			url:    "https://go-review.googlesource.com/c/tools/+/123456/2",
+			want:   Link{Kind: KindCL, CL: 123456},
+			wantOK: true,
+		},
+		{
+			name: "other_go_dev",
+			url:  "https://go.dev/doc/effective_go",
+		},
+		{
+			name: "other_site",
+			url:  "https://example.com/play/p/HmnNoBf0p1z",
+		},
+		{
+			name: "not_http",
+			url:  "ftp://pkg.go.dev/fmt",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Parse(tt.url)
+			if ok != tt.wantOK {
+				t.Fatalf("Parse() ok = %t, want %t", ok, tt.wantOK)
+			}
+
+			if ok {
+				tt.want.URL = tt.url
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_preview(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{
+			name: "short",
+			code: "package main\n\nfunc main() {}\n",
+			want: "```package main\n\nfunc main() {}```",
+		},
+		{
+			name: "escaped",
+			code: "x := a<b && c>d // ```",
+			want: "```x := a&lt;b &amp;&amp; c&gt;d // ` ` ````",
+		},
+		{
+			name: "one_more",
+			code: strings.Repeat("x\n", maxPreviewLines+1),
+			want: "```" + strings.TrimSuffix(strings.Repeat("x\n", maxPreviewLines), "\n") + "```\n... and 1 more line",
+		},
+		{
+			name: "more",
+			code: strings.Repeat("x\n", maxPreviewLines+5),
+			want: "```" + strings.TrimSuffix(strings.Repeat("x\n", maxPreviewLines), "\n") + "```\n... and 5 more lines",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, preview(tt.code)); diff != "" {
+				t.Fatalf("preview() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRenderer_Render(t *testing.T) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/pkg/net/http", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><meta name="Description" content="Package http provides HTTP client and server implementations &amp; more."></head></html>`))
+	})
+
+	mux.HandleFunc("/play/p/abc.go", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("package main\n"))
+	})
+
+	mux.HandleFunc("/gerrit/changes/123", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(")]}'\n" + `{"project":"tools","branch":"master","subject":"gopls: fix a crash","status":"MERGED","insertions":10,"deletions":2,"owner":{"name":"Gopher"}}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	r := New(srv.Client())
+	r.pkgURL = srv.URL + "/pkg"
+	r.playURL = srv.URL + "/play"
+	r.gerritURL = srv.URL + "/gerrit"
+
+	tests := []struct {
+		name    string
+		url     string
+		want    slack.Attachment
+		wantErr bool
+	}{
+		{
+			name: "package",
+			url:  "https://pkg.go.dev/net/http",
+			want: slack.Attachment{
+				Title:     "net/http",
+				TitleLink: "https://pkg.go.dev/net/http",
+				Text:      "Package http provides HTTP client and server implementations & more.",
+				Footer:    "pkg.go.dev",
+			},
+		},
+		{
+			name: "symbol",
+			url:  "https://pkg.go.dev/net/http#Client",
+			want: slack.Attachment{
+				Title:     "http.Client",
+				TitleLink: "https://pkg.go.dev/net/http#Client",
+				Text:      "Package http provides HTTP client and server implementations & more.",
+				Footer:    "pkg.go.dev | net/http",
+			},
+		},
+		{
+			name:    "missing_package",
+			url:     "https://pkg.go.dev/example.com/nope",
+			wantErr: true,
+		},
+		{
+			name: "playground",
+			url:  "https://go.dev/play/p/abc",
+			want: slack.Attachment{
+				Title:      "Go Playground snippet",
+				TitleLink:  "https://go.dev/play/p/abc",
+				Text:       "```package main```",
+				MarkdownIn: []string{"text"},
+				Footer:     "go.dev/play",
+			},
+		},
+		{
+			name: "cl",
+			url:  "https://go.dev/cl/123",
+			want: slack.Attachment{
+				Title:     "CL 123: [tools] gopls: fix a crash",
+				TitleLink: "https://go.dev/cl/123",
+				Fields: []slack.AttachmentField{
+					{Title: "Status", Value: "Merged", Short: true},
+					{Title: "Changes", Value: "+10 -2", Short: true},
+					{Title: "Owner", Value: "Gopher", Short: true},
+				},
+				Footer: "go-review.googlesource.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			l, ok := Parse(tt.url)
+			if !ok {
+				t.Fatalf("Parse(%q) failed", tt.url)
+			}
+
+			got, err := r.Render(context.Background(), l)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("Render() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
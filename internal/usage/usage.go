@@ -0,0 +1,276 @@
+// Package usage counts how often each of the bot's triggers fires, per channel
+// and per day, so the maintainers can prune the responses nobody uses and see
+// which are popular.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyFormat  = "usage:%s" // date
+	redisTriggerKey = "usage:triggers"
+	redisTestKey    = "usage:test_key"
+
+	dateFormat = "2006-01-02"
+)
+
+// Retention is how long the daily counts are kept.
+const Retention = 100 * 24 * time.Hour
+
+// Store is the Redis-backed store of usage counts.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+func dayKey(t time.Time) string {
+	return fmt.Sprintf(redisKeyFormat, t.UTC().Format(dateFormat))
+}
+
+// field is the hash field for the trigger in the channel. Channel IDs don't
+// have colons, unlike some triggers, so it's split on the first.
+func field(trigger, channelID string) string {
+	return channelID + ":" + trigger
+}
+
+// Record counts the trigger firing in the channel today.
+func (s *Store) Record(ctx context.Context, trigger, channelID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	key := dayKey(time.Now())
+
+	pipe := s.r.TxPipeline()
+	pipe.HIncrBy(ctx, key, field(trigger, channelID), 1)
+	pipe.Expire(ctx, key, Retention)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to increment usage count: %w", err)
+	}
+
+	return nil
+}
+
+// SetTriggers replaces the list of registered triggers, so the ones that never
+// fire can be reported.
+func (s *Store) SetTriggers(ctx context.Context, triggers []string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	members := make([]interface{}, len(triggers))
+	for i, t := range triggers {
+		members[i] = t
+	}
+
+	pipe := s.r.TxPipeline()
+	pipe.Del(ctx, redisTriggerKey)
+
+	if len(members) > 0 {
+		pipe.SAdd(ctx, redisTriggerKey, members...)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set triggers: %w", err)
+	}
+
+	return nil
+}
+
+// Triggers returns the registered triggers, sorted.
+func (s *Store) Triggers(ctx context.Context) ([]string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	triggers, err := s.r.SMembers(ctx, redisTriggerKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get triggers: %w", err)
+	}
+
+	sort.Strings(triggers)
+
+	return triggers, nil
+}
+
+// Count is how often a trigger fired over a period.
+type Count struct {
+	Trigger string
+
+	// Total is the number of times it fired, in all channels.
+	Total int64
+
+	// Channels are the number of times it fired, by channel ID.
+	Channels map[string]int64
+}
+
+// TopChannel returns the channel the trigger fired in most.
+func (c Count) TopChannel() (channelID string, n int64) {
+	for id, v := range c.Channels {
+		if v > n || (v == n && id < channelID) {
+			channelID, n = id, v
+		}
+	}
+
+	return channelID, n
+}
+
+// Counts returns how often each trigger fired on the days from from up to, but
+// not including, to, the most used first.
+func (s *Store) Counts(ctx context.Context, from, to time.Time) ([]Count, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	pipe := s.r.Pipeline()
+
+	var cmds []*redis.MapStringStringCmd
+
+	for d := from.UTC().Truncate(24 * time.Hour); d.Before(to); d = d.Add(24 * time.Hour) {
+		cmds = append(cmds, pipe.HGetAll(ctx, dayKey(d)))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get usage counts: %w", err)
+	}
+
+	days := make([]map[string]string, len(cmds))
+	for i, c := range cmds {
+		days[i] = c.Val()
+	}
+
+	return aggregate(days), nil
+}
+
+// aggregate sums the daily hashes into a Count per trigger, the most used
+// first.
+func aggregate(days []map[string]string) []Count {
+	byTrigger := make(map[string]*Count)
+
+	for _, day := range days {
+		for f, v := range day {
+			i := strings.IndexByte(f, ':')
+			if i < 0 {
+				continue
+			}
+
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			channelID, trigger := f[:i], f[i+1:]
+
+			c, ok := byTrigger[trigger]
+			if !ok {
+				c = &Count{Trigger: trigger, Channels: make(map[string]int64)}
+				byTrigger[trigger] = c
+			}
+
+			c.Total += n
+			c.Channels[channelID] += n
+		}
+	}
+
+	counts := make([]Count, 0, len(byTrigger))
+	for _, c := range byTrigger {
+		counts = append(counts, *c)
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Total == counts[j].Total {
+			return counts[i].Trigger < counts[j].Trigger
+		}
+
+		return counts[i].Total > counts[j].Total
+	})
+
+	return counts
+}
+
+// Unused returns the triggers that don't have a count, sorted.
+func Unused(triggers []string, counts []Count) []string {
+	used := make(map[string]struct{}, len(counts))
+	for _, c := range counts {
+		used[c.Trigger] = struct{}{}
+	}
+
+	var unused []string
+
+	for _, t := range triggers {
+		if _, ok := used[t]; !ok {
+			unused = append(unused, t)
+		}
+	}
+
+	sort.Strings(unused)
+
+	return unused
+}
+
+// reportTop is how many of the most used triggers are in a report.
+const reportTop = 20
+
+// FormatReport formats the counts as a Slack message, with the most used
+// triggers and the registered triggers that weren't used at all.
+func FormatReport(title string, counts []Count, triggers []string) string {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "*%s*\n", title)
+
+	if len(counts) == 0 {
+		b.WriteString("Nothing was used.\n")
+	}
+
+	for i, c := range counts {
+		if i == reportTop {
+			fmt.Fprintf(b, "…and %d more\n", len(counts)-reportTop)
+			break
+		}
+
+		channelID, n := c.TopChannel()
+
+		fmt.Fprintf(b, "- `%s`: %d (%d in <#%s>)\n", c.Trigger, c.Total, n, channelID)
+	}
+
+	if unused := Unused(triggers, counts); len(unused) > 0 {
+		for i := range unused {
+			unused[i] = "`" + unused[i] + "`"
+		}
+
+		fmt.Fprintf(b, "\nNever used: %s\n", strings.Join(unused, ", "))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package usage
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_aggregate(t *testing.T) {
+	days := []map[string]string{
+		{
+			"C1:help":              "3",
+			"C2:help":              "1",
+			"C1:(?i)\\bgo:lang\\b": "2",
+			"malformed":            "9",
+			"C1:bad_count":         "x",
+		},
+		nil,
+		{
+			"C2:help":  "4",
+			"C1:issue": "2",
+		},
+	}
+
+	want := []Count{
+		{Trigger: "help", Total: 8, Channels: map[string]int64{"C1": 3, "C2": 5}},
+		{Trigger: "(?i)\\bgo:lang\\b", Total: 2, Channels: map[string]int64{"C1": 2}},
+		{Trigger: "issue", Total: 2, Channels: map[string]int64{"C1": 2}},
+	}
+
+	if diff := cmp.Diff(want, aggregate(days)); diff != "" {
+		t.Fatalf("aggregate() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCount_TopChannel(t *testing.T) {
+	c := Count{Channels: map[string]int64{"C3": 1, "C2": 5, "C1": 5}}
+
+	id, n := c.TopChannel()
+	if id != "C1" || n != 5 {
+		t.Fatalf("TopChannel() = %q, %d, want %q, %d", id, n, "C1", 5)
+	}
+}
+
+func TestFormatReport(t *testing.T) {
+	counts := []Count{
+		{Trigger: "help", Total: 8, Channels: map[string]int64{"C1": 3, "C2": 5}},
+		{Trigger: "issue", Total: 2, Channels: map[string]int64{"C1": 2}},
+	}
+
+	got := FormatReport("Usage for September 2026", counts, []string{"help", "xkcd", "issue", "bbq"})
+
+	want := "*Usage for September 2026*\n" +
+		"- `help`: 8 (5 in <#C2>)\n" +
+		"- `issue`: 2 (2 in <#C1>)\n" +
+		"\nNever used: `bbq`, `xkcd`\n"
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("FormatReport() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := FormatReport("Empty", nil, nil); got != "*Empty*\nNothing was used.\n" {
+		t.Fatalf("FormatReport() = %q", got)
+	}
+}
@@ -0,0 +1,225 @@
+// Package version keeps track of which commit each of the bot's processes is
+// running, to answer "which version is live?", and notices when a new one is
+// deployed.
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	redisProcessesKey = "version:processes" // hash of component:dyno to Info
+	redisCommitKey    = "version:commit"    // the commit last seen starting
+	redisTestKey      = "version:test_key"
+
+	// refreshInterval is how often each process records that it's running.
+	refreshInterval = time.Minute
+
+	// staleAfter is how long after a process last recorded that it's
+	// running it's assumed to have stopped.
+	staleAfter = 3 * refreshInterval
+)
+
+// Info is what a running process reports about itself.
+type Info struct {
+	// Component is the program, like "consumer".
+	Component string `json:"component"`
+
+	// DynoID is the process's HEROKU_DYNO_ID.
+	DynoID string `json:"dyno_id"`
+
+	// Commit is the HEROKU_SLUG_COMMIT it was built from.
+	Commit string `json:"commit"`
+
+	// ReleasedAt is when its slug was built and released, if known.
+	ReleasedAt time.Time `json:"released_at"`
+
+	// StartedAt is when the process started.
+	StartedAt time.Time `json:"started_at"`
+
+	// SeenAt is when the process last recorded that it's running.
+	SeenAt time.Time `json:"seen_at"`
+}
+
+// ShortCommit returns the abbreviated commit, or "unknown" if it isn't set.
+func (i Info) ShortCommit() string {
+	switch {
+	case len(i.Commit) == 0:
+		return "unknown"
+	case len(i.Commit) > 7:
+		return i.Commit[:7]
+	default:
+		return i.Commit
+	}
+}
+
+// Uptime returns how long the process had been running when it was last seen.
+func (i Info) Uptime() time.Duration {
+	return i.SeenAt.Sub(i.StartedAt)
+}
+
+func (i Info) field() string {
+	return i.Component + ":" + i.DynoID
+}
+
+// Registry records the running processes.
+type Registry struct {
+	r    *redis.Client
+	self Info
+	l    zerolog.Logger
+}
+
+// NewRegistry returns a new *Registry, for the process described by self.
+func NewRegistry(rc *redis.Client, self Info, logger zerolog.Logger) (*Registry, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	if self.StartedAt.IsZero() {
+		self.StartedAt = time.Now()
+	}
+
+	return &Registry{r: rc, self: self, l: logger}, nil
+}
+
+// Self returns the Info of the process the Registry is for.
+func (r *Registry) Self() Info {
+	i := r.self
+	i.SeenAt = time.Now()
+
+	return i
+}
+
+// Run records that the process is running until the context is canceled.
+func (r *Registry) Run(ctx context.Context) {
+	t := time.NewTicker(refreshInterval)
+	defer t.Stop()
+
+	for {
+		if err := r.Register(ctx); err != nil {
+			r.l.Error().
+				Err(err).
+				Msg("failed to record running version")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			// escape out to for loop
+		}
+	}
+}
+
+// Register records that the process is running now.
+func (r *Registry) Register(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	i := r.Self()
+
+	j, err := json.Marshal(i)
+	if err != nil {
+		return fmt.Errorf("failed to marshal version info: %w", err)
+	}
+
+	if err := r.r.HSet(ctx, redisProcessesKey, i.field(), string(j)).Err(); err != nil {
+		return fmt.Errorf("failed to HSET redis key: %w", err)
+	}
+
+	return nil
+}
+
+// All returns the processes that are running, sorted by component. The
+// processes that have stopped are forgotten.
+func (r *Registry) All(ctx context.Context) ([]Info, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		// noop
+	}
+
+	raw, err := r.r.HGetAll(ctx, redisProcessesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to HGETALL redis key: %w", err)
+	}
+
+	running, stopped := live(raw, time.Now())
+
+	if len(stopped) > 0 {
+		if err := r.r.HDel(ctx, redisProcessesKey, stopped...).Err(); err != nil {
+			return nil, fmt.Errorf("failed to HDEL redis key: %w", err)
+		}
+	}
+
+	return running, nil
+}
+
+// Deployed records the process's commit as the one running, and returns the
+// one it replaced if it's new. Only the first process to start after a deploy
+// sees it as new, so it's announced once.
+func (r *Registry) Deployed(ctx context.Context) (previous string, isNew bool, err error) {
+	select {
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	default:
+		// noop
+	}
+
+	if len(r.self.Commit) == 0 {
+		return "", false, nil
+	}
+
+	previous, err = r.r.GetSet(ctx, redisCommitKey, r.self.Commit).Result()
+	if err != nil {
+		if err == redis.Nil {
+			// the first deploy that's tracked; nothing to compare it to
+			return "", false, nil
+		}
+
+		return "", false, fmt.Errorf("failed to GETSET redis key: %w", err)
+	}
+
+	return previous, previous != r.self.Commit, nil
+}
+
+// live splits the recorded processes into those seen recently, sorted by
+// component and dyno, and the fields of those that weren't.
+func live(raw map[string]string, now time.Time) (running []Info, stopped []string) {
+	for field, v := range raw {
+		var i Info
+
+		if err := json.Unmarshal([]byte(v), &i); err != nil || now.Sub(i.SeenAt) > staleAfter {
+			stopped = append(stopped, field)
+			continue
+		}
+
+		running = append(running, i)
+	}
+
+	sort.Slice(running, func(a, b int) bool {
+		if running[a].Component != running[b].Component {
+			return running[a].Component < running[b].Component
+		}
+
+		return running[a].DynoID < running[b].DynoID
+	})
+
+	sort.Strings(stopped)
+
+	return running, stopped
+}
@@ -0,0 +1,58 @@
+package version
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInfo_ShortCommit(t *testing.T) {
+	tests := []struct {
+		name   string
+		commit string
+		want   string
+	}{
+		{name: "full", commit: "deadbeefcafe1234", want: "deadbee"},
+		{name: "short", commit: "abc", want: "abc"},
+		{name: "unset", want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Info{Commit: tt.commit}).ShortCommit(); got != tt.want {
+				t.Fatalf("ShortCommit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_live(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	consumer := Info{Component: "consumer", DynoID: "b", Commit: "c1", SeenAt: now.Add(-time.Minute)}
+	consumer2 := Info{Component: "consumer", DynoID: "a", Commit: "c1", SeenAt: now.Add(-time.Minute)}
+	bgtasks := Info{Component: "bgtasks", DynoID: "c", Commit: "c1", SeenAt: now}
+	gateway := Info{Component: "gateway", DynoID: "d", Commit: "c0", SeenAt: now.Add(-time.Hour)}
+
+	raw := map[string]string{"broken:e": "{"}
+
+	for _, i := range []Info{consumer, consumer2, bgtasks, gateway} {
+		j, err := json.Marshal(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		raw[i.field()] = string(j)
+	}
+
+	running, stopped := live(raw, now)
+
+	if want := []Info{bgtasks, consumer2, consumer}; !reflect.DeepEqual(running, want) {
+		t.Errorf("running = %+v, want %+v", running, want)
+	}
+
+	if want := []string{"broken:e", "gateway:d"}; !reflect.DeepEqual(stopped, want) {
+		t.Errorf("stopped = %v, want %v", stopped, want)
+	}
+}
@@ -0,0 +1,201 @@
+// Package issue summarizes golang/go issues, either when asked directly or
+// when someone references one like golang/go#12345 in a message.
+package issue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/github"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// Prefix is the prefix that's intended to be used by the handler.
+const Prefix = "issue "
+
+const (
+	owner = "golang"
+	repo  = "go"
+)
+
+// maxLinkedIssues is the maximum number of references in a single message
+// that we'll summarize.
+const maxLinkedIssues = 3
+
+var (
+	refRegex       = regexp.MustCompile(`(?:^|[^\w/.-])golang/go#(\d+)\b`)
+	codeBlockRegex = regexp.MustCompile("(?s)```.*?```|`[^`]*`")
+)
+
+// Fetcher is the interface to get issues, generally satisfied by a
+// *github.Client.
+type Fetcher interface {
+	Issue(ctx context.Context, owner, repo string, number int64) (github.Issue, error)
+}
+
+// Lookup summarizes issues.
+type Lookup struct {
+	gh     Fetcher
+	cache  *Store
+	prefix string
+}
+
+// New returns a new *Lookup, which caches issues in the Store.
+func New(prefix string, gh Fetcher, cache *Store) *Lookup {
+	return &Lookup{
+		gh:     gh,
+		cache:  cache,
+		prefix: prefix,
+	}
+}
+
+// refs returns the unique issue numbers referenced in the text, in order,
+// ignoring any in code.
+func refs(text string) []int64 {
+	text = codeBlockRegex.ReplaceAllString(text, "")
+
+	matches := refRegex.FindAllStringSubmatch(text, -1)
+
+	nums := make([]int64, 0, len(matches))
+	seen := make(map[int64]struct{}, len(matches))
+
+	for _, m := range matches {
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || n == 0 {
+			continue
+		}
+
+		if _, ok := seen[n]; ok {
+			continue
+		}
+
+		seen[n] = struct{}{}
+		nums = append(nums, n)
+	}
+
+	return nums
+}
+
+// escaper escapes the characters Slack uses for its control sequences, for
+// titles and labels that anyone can write, like <!channel>.
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func summary(i github.Issue) string {
+	kind := "issue"
+	if i.IsPR {
+		kind = "PR"
+	}
+
+	s := fmt.Sprintf("<%s|golang/go#%d> %s (%s %s", i.URL, i.Number, escaper.Replace(i.Title), i.State, kind)
+
+	if len(i.Milestone) > 0 {
+		s += ", " + escaper.Replace(i.Milestone)
+	}
+
+	s += ")"
+
+	if len(i.Labels) > 0 {
+		s += " labels: " + escaper.Replace(strings.Join(i.Labels, ", "))
+	}
+
+	return s
+}
+
+// get returns the issue from the cache, or fetches and caches it.
+func (l *Lookup) get(ctx context.Context, number int64) (github.Issue, error) {
+	i, found, err := l.cache.Get(ctx, number)
+	if err != nil {
+		return github.Issue{}, err
+	}
+
+	if found {
+		return i, nil
+	}
+
+	i, err = l.gh.Issue(ctx, owner, repo, number)
+	if err != nil {
+		return github.Issue{}, err
+	}
+
+	if err = l.cache.Set(ctx, i); err != nil {
+		return github.Issue{}, err
+	}
+
+	return i, nil
+}
+
+// Handler satisfies handler.MessageActionFn.
+func (l *Lookup) Handler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	if !m.BotMentioned() {
+		return nil
+	}
+
+	arg := strings.TrimPrefix(strings.TrimSpace(m.Text()[len(l.prefix):]), "#")
+
+	number, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || number <= 0 {
+		return r.RespondTo(ctx, "You need to specify an issue number, like `issue 12345`")
+	}
+
+	i, err := l.get(ctx, number)
+	if err != nil {
+		var nf github.NotFoundError
+		if errors.As(err, &nf) {
+			return r.RespondTo(ctx, fmt.Sprintf("I couldn't find golang/go#%d.", number))
+		}
+
+		return fmt.Errorf("failed to get issue %d: %w", number, err)
+	}
+
+	return r.RespondTo(ctx, summary(i))
+}
+
+// MessageMatchFn satisfies handler.MessageMatchFn.
+func (l *Lookup) MessageMatchFn(shadowMode bool, m handler.Messenger) bool {
+	if shadowMode {
+		return false
+	}
+
+	// the command handler takes care of these
+	if m.BotMentioned() && strings.HasPrefix(m.Text(), l.prefix) {
+		return false
+	}
+
+	return len(refs(m.Text())) > 0
+}
+
+// LinkHandler satisfies handler.MessageActionFn, for summarizing the issues
+// referenced in a message.
+func (l *Lookup) LinkHandler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	nums := refs(m.Text())
+	if len(nums) > maxLinkedIssues {
+		nums = nums[:maxLinkedIssues]
+	}
+
+	lines := make([]string, 0, len(nums))
+
+	for _, n := range nums {
+		i, err := l.get(ctx, n)
+		if err != nil {
+			var nf github.NotFoundError
+			if errors.As(err, &nf) {
+				continue
+			}
+
+			return fmt.Errorf("failed to get issue %d: %w", n, err)
+		}
+
+		lines = append(lines, summary(i))
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return r.Respond(ctx, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,67 @@
+package issue
+
+import (
+	"testing"
+
+	"github.com/gobridge/gopherbot/internal/github"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_refs(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []int64
+	}{
+		{name: "none", text: "no issues here", want: []int64{}},
+		{name: "single", text: "see golang/go#12345", want: []int64{12345}},
+		{name: "start", text: "golang/go#1 is old", want: []int64{1}},
+		{name: "multiple_deduped", text: "golang/go#1, golang/go#2 and golang/go#1", want: []int64{1, 2}},
+		{name: "other_repo", text: "see foo/golang/go#3 or github.com/golang/go#4", want: []int64{}},
+		{name: "inline_code", text: "ignore `golang/go#5` but not golang/go#6", want: []int64{6}},
+		{name: "code_block", text: "```\ngolang/go#7\n```", want: []int64{}},
+		{name: "zero", text: "golang/go#0", want: []int64{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, refs(tt.text)); diff != "" {
+				t.Fatalf("refs(%q) mismatch (-want +got):\n%s", tt.text, diff)
+			}
+		})
+	}
+}
+
+func Test_summary(t *testing.T) {
+	tests := []struct {
+		name string
+		i    github.Issue
+		want string
+	}{
+		{
+			name: "issue",
+			i:    github.Issue{Number: 1, Title: "spec: add generics", State: "closed", URL: "https://github.com/golang/go/issues/1", Milestone: "Go1.18", Labels: []string{"Proposal"}},
+			want: "<https://github.com/golang/go/issues/1|golang/go#1> spec: add generics (closed issue, Go1.18) labels: Proposal",
+		},
+		{
+			name: "pr",
+			i:    github.Issue{Number: 2, Title: "fix it", State: "open", URL: "https://github.com/golang/go/pull/2", IsPR: true},
+			want: "<https://github.com/golang/go/pull/2|golang/go#2> fix it (open PR)",
+		},
+		{
+			name: "escaped",
+			i:    github.Issue{Number: 3, Title: "<!channel> a&b", State: "open", URL: "https://github.com/golang/go/issues/3", Labels: []string{"<@U1>"}},
+			want: "<https://github.com/golang/go/issues/3|golang/go#3> &lt;!channel&gt; a&amp;b (open issue) labels: &lt;@U1&gt;",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summary(tt.i); got != tt.want {
+				t.Fatalf("summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
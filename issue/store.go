@@ -0,0 +1,84 @@
+package issue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/github"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyFormat = "issue:golang/go:%d"
+	redisTestKey   = "issue:test_key"
+
+	// cacheTTL is how long an issue is cached, to avoid hitting the GitHub
+	// API every time a popular issue is mentioned.
+	cacheTTL = 10 * time.Minute
+)
+
+// Store caches issues in Redis.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Get returns the cached issue, if found.
+func (s *Store) Get(ctx context.Context, number int64) (github.Issue, bool, error) {
+	select {
+	case <-ctx.Done():
+		return github.Issue{}, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, fmt.Sprintf(redisKeyFormat, number))
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return github.Issue{}, false, nil
+		}
+
+		return github.Issue{}, false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	var i github.Issue
+
+	if err := json.Unmarshal([]byte(res.Val()), &i); err != nil {
+		return github.Issue{}, false, fmt.Errorf("failed to unmarshal issue %d: %w", number, err)
+	}
+
+	return i, true, nil
+}
+
+// Set caches the issue.
+func (s *Store) Set(ctx context.Context, i github.Issue) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	j, err := json.Marshal(i)
+	if err != nil {
+		return fmt.Errorf("failed to marshal issue %d: %w", i.Number, err)
+	}
+
+	if err = s.r.Set(ctx, fmt.Sprintf(redisKeyFormat, i.Number), string(j), cacheTTL).Err(); err != nil {
+		return fmt.Errorf("failed to cache issue %d: %w", i.Number, err)
+	}
+
+	return nil
+}
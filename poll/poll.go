@@ -0,0 +1,193 @@
+// Package poll runs simple polls, where people vote by reacting to the poll
+// message with the emoji next to their choice.
+package poll
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+// Command is the name of the command that's intended to be used by the
+// handler.
+const Command = "poll"
+
+// Usage is how to use the command.
+const Usage = "To start a poll, use `poll \"Question\" \"Option A\" \"Option B\"`. For the results, use `poll results` in the poll's thread."
+
+const resultsCommand = "results"
+
+// optionEmoji are the emoji used to vote for each option, in order.
+var optionEmoji = []string{
+	"one", "two", "three", "four", "five",
+	"six", "seven", "eight", "nine", "keycap_ten",
+}
+
+const (
+	minOptions = 2
+	maxOptions = 10
+)
+
+// Poll is a single poll.
+type Poll struct {
+	Question string   `json:"question"`
+	Options  []string `json:"options"`
+	UserID   string   `json:"user_id"`
+}
+
+// parse parses the poll's question and options from the command arguments.
+func parse(args []string) (Poll, error) {
+	var parts []string
+
+	for _, a := range args {
+		if s := strings.TrimSpace(a); len(s) > 0 {
+			parts = append(parts, s)
+		}
+	}
+
+	if len(parts) < minOptions+1 {
+		return Poll{}, fmt.Errorf("a poll needs a question and at least %d options", minOptions)
+	}
+
+	if len(parts) > maxOptions+1 {
+		return Poll{}, fmt.Errorf("a poll can have at most %d options", maxOptions)
+	}
+
+	return Poll{Question: parts[0], Options: parts[1:]}, nil
+}
+
+func (p Poll) blocks() []slack.Block {
+	b := &strings.Builder{}
+
+	for i, o := range p.Options {
+		fmt.Fprintf(b, ":%s: %s\n", optionEmoji[i], o)
+	}
+
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s*", p.Question), false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, b.String(), false, false), nil, nil),
+		slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("Poll by <@%s>. React to vote, and mention me with `poll results` in the thread to tally the votes.", p.UserID), false, false),
+		),
+	}
+}
+
+// Poller runs polls.
+type Poller struct {
+	store *Store
+}
+
+// New returns a new *Poller.
+func New(s *Store) *Poller {
+	return &Poller{store: s}
+}
+
+// Handler satisfies handler.CommandFn.
+func (p *Poller) Handler(ctx workqueue.Context, m handler.Messenger, c handler.Command, r handler.Responder) error {
+	if !m.BotMentioned() {
+		return nil
+	}
+
+	if len(c.Args) == 1 && strings.EqualFold(c.Args[0], resultsCommand) {
+		return p.results(ctx, m, r)
+	}
+
+	// the poll's thread is where its results are asked for, and a reply in a
+	// thread can't have one of its own
+	if len(m.ThreadTS()) > 0 {
+		return r.RespondTo(ctx, "Please start the poll in the channel rather than in a thread, so it gets a thread of its own for the `poll results`.")
+	}
+
+	poll, err := parse(c.Args)
+	if err != nil {
+		return handler.Usagef("I couldn't start that poll: %s", err)
+	}
+
+	poll.UserID = m.UserID()
+
+	_, ts, _, err := ctx.Slack().SendMessageContext(ctx, m.ChannelID(),
+		slack.MsgOptionText(poll.Question, false),
+		slack.MsgOptionBlocks(poll.blocks()...),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to post poll: %w", err)
+	}
+
+	if err = p.store.Put(ctx, m.ChannelID(), ts, poll); err != nil {
+		return fmt.Errorf("failed to save poll: %w", err)
+	}
+
+	item := slack.NewRefToMessage(m.ChannelID(), ts)
+
+	for i := range poll.Options {
+		if err = ctx.Slack().AddReactionContext(ctx, optionEmoji[i], item); err != nil {
+			return fmt.Errorf("failed to add option reaction: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Poller) results(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	if len(m.ThreadTS()) == 0 {
+		return r.RespondTo(ctx, "Please ask for the `poll results` in the poll's thread.")
+	}
+
+	poll, found, err := p.store.Get(ctx, m.ChannelID(), m.ThreadTS())
+	if err != nil {
+		return fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	if !found {
+		return r.RespondTo(ctx, "I couldn't find a poll for this thread. Polls are only kept for 30 days.")
+	}
+
+	reactions, err := ctx.Slack().GetReactionsContext(ctx, slack.NewRefToMessage(m.ChannelID(), m.ThreadTS()), slack.NewGetReactionsParameters())
+	if err != nil {
+		return fmt.Errorf("failed to get reactions: %w", err)
+	}
+
+	return r.Respond(ctx, tally(ctx.Self().ID, poll, reactions))
+}
+
+// tally renders the results of the poll, not counting the bot's own votes.
+func tally(selfID string, p Poll, reactions []slack.ItemReaction) string {
+	counts := make(map[string]int, len(reactions))
+
+	for _, ir := range reactions {
+		n := ir.Count
+
+		for _, u := range ir.Users {
+			if u == selfID {
+				n--
+				break
+			}
+		}
+
+		counts[ir.Name] = n
+	}
+
+	var total int
+	for i := range p.Options {
+		total += counts[optionEmoji[i]]
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "Results for *%s* (%d votes):\n", p.Question, total)
+
+	for i, o := range p.Options {
+		n := counts[optionEmoji[i]]
+
+		var pct int
+		if total > 0 {
+			pct = n * 100 / total
+		}
+
+		fmt.Fprintf(b, ":%s: %s: %d (%d%%)\n", optionEmoji[i], o, n, pct)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package poll
+
+import (
+	"testing"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/handler/handlertest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/slack-go/slack"
+)
+
+func Test_parse(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want Poll
+		err  string
+	}{
+		{
+			name: "valid",
+			args: []string{"Tabs or spaces?", "Tabs", "Spaces"},
+			want: Poll{Question: "Tabs or spaces?", Options: []string{"Tabs", "Spaces"}},
+		},
+		{
+			name: "empty_options_skipped",
+			args: []string{"Q", "", "A", "  ", "B"},
+			want: Poll{Question: "Q", Options: []string{"A", "B"}},
+		},
+		{
+			name: "too_few",
+			args: []string{"Q", "A"},
+			err:  "a poll needs a question and at least 2 options",
+		},
+		{
+			name: "too_many",
+			args: []string{"Q", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"},
+			err:  "a poll can have at most 10 options",
+		},
+		{
+			name: "none",
+			err:  "a poll needs a question and at least 2 options",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(tt.args)
+			if len(tt.err) > 0 {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("parse() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parse() unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_tally(t *testing.T) {
+	p := Poll{Question: "Tabs or spaces?", Options: []string{"Tabs", "Spaces"}}
+
+	reactions := []slack.ItemReaction{
+		{Name: "one", Count: 4, Users: []string{"UBOT", "U1", "U2", "U3"}},
+		{Name: "two", Count: 2, Users: []string{"UBOT", "U4"}},
+		{Name: "tada", Count: 1, Users: []string{"U5"}},
+	}
+
+	want := "Results for *Tabs or spaces?* (4 votes):\n:one: Tabs: 3 (75%)\n:two: Spaces: 1 (25%)\n"
+
+	if got := tally("UBOT", p, reactions); got != want {
+		t.Fatalf("tally() = %q, want %q", got, want)
+	}
+}
+
+func TestPoller_Handler_inThread(t *testing.T) {
+	m := handlertest.NewMessage("poll \"Tabs or spaces?\" Tabs Spaces").
+		InChannel("C123").
+		InThread("1588334400.000100").
+		MentioningBot()
+
+	c := handler.Command{Name: Command, Args: []string{"Tabs or spaces?", "Tabs", "Spaces"}}
+
+	rec := &handlertest.Recorder{}
+
+	// the store and Slack client are nil, so this fails if it tries to post
+	if err := New(nil).Handler(handlertest.NewContext(), m, c, rec); err != nil {
+		t.Fatalf("Handler() unexpected error: %v", err)
+	}
+
+	want := "Please start the poll in the channel rather than in a thread, so it gets a thread of its own for the `poll results`.\n"
+
+	if got := rec.Text(); got != want {
+		t.Fatalf("responses = %q, want %q", got, want)
+	}
+}
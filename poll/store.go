@@ -0,0 +1,138 @@
+package poll
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyFormat = "poll:%s:%s"
+	redisTestKey   = "poll:test_key"
+
+	retention = 30 * 24 * time.Hour
+)
+
+// Store stores polls by the channel and message ID they were posted as.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+// Put stores the poll.
+func (s *Store) Put(ctx context.Context, channelID, ts string, p Poll) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	j, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal poll: %w", err)
+	}
+
+	if err = s.r.Set(ctx, fmt.Sprintf(redisKeyFormat, channelID, ts), string(j), retention).Err(); err != nil {
+		return fmt.Errorf("failed to set poll: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the poll, if found.
+func (s *Store) Get(ctx context.Context, channelID, ts string) (Poll, bool, error) {
+	select {
+	case <-ctx.Done():
+		return Poll{}, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	res := s.r.Get(ctx, fmt.Sprintf(redisKeyFormat, channelID, ts))
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return Poll{}, false, nil
+		}
+
+		return Poll{}, false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	var p Poll
+
+	if err := json.Unmarshal([]byte(res.Val()), &p); err != nil {
+		return Poll{}, false, fmt.Errorf("failed to unmarshal poll: %w", err)
+	}
+
+	return p, true, nil
+}
+
+// PurgeUser deletes the polls the user started, returning how many there
+// were. If dryRun is true, they're counted but not deleted. It satisfies
+// purge.Store.
+func (s *Store) PurgeUser(ctx context.Context, userID string, dryRun bool) (int, error) {
+	var cursor uint64
+	var found []string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+			// noop
+		}
+
+		keys, next, err := s.r.Scan(ctx, cursor, fmt.Sprintf(redisKeyFormat, "*", "*"), 100).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to SCAN redis keys: %w", err)
+		}
+
+		for _, key := range keys {
+			v, err := s.r.Get(ctx, key).Result()
+			if err != nil {
+				if err == redis.Nil {
+					continue
+				}
+
+				return 0, fmt.Errorf("failed to GET redis key: %w", err)
+			}
+
+			var p Poll
+
+			if err := json.Unmarshal([]byte(v), &p); err != nil {
+				return 0, fmt.Errorf("failed to unmarshal poll: %w", err)
+			}
+
+			if p.UserID == userID {
+				found = append(found, key)
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	if len(found) == 0 || dryRun {
+		return len(found), nil
+	}
+
+	if err := s.r.Del(ctx, found...).Err(); err != nil {
+		return 0, fmt.Errorf("failed to purge polls of %s: %w", userID, err)
+	}
+
+	return len(found), nil
+}
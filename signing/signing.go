@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -38,16 +39,16 @@ type Request struct {
 	Body []byte
 }
 
+// DefaultMaxSkew is the default for Validator.MaxSkew, which is what Slack
+// recommends.
+const DefaultMaxSkew = 5 * time.Minute
+
 func parseTimestamp(t string) (int64, error) {
 	ts, err := strconv.ParseInt(t, 10, 64)
 	if err != nil {
 		return -1, fmt.Errorf("failed to parse %s header: %w", SlackTimestampHeader, err)
 	}
 
-	if time.Now().Unix()-ts > 300 { // was this more than 5 minutes ago?
-		return -1, fmt.Errorf("request timestamp (%d) too old", ts)
-	}
-
 	return ts, nil
 }
 
@@ -62,11 +63,29 @@ func genHMAC(key, data string) string {
 	return fmt.Sprintf("v0=%x", m.Sum(nil))
 }
 
-// Validate takes the signature key, and the pieces of a request that allow us
-// to validate its signature. If this returned an error, the validation failed.
-// Returned errors are meant to be logged, not to be sent back to the entity
-// making the request.
-func Validate(key string, r Request) error {
+// Validator validates the signatures of requests from Slack.
+type Validator struct {
+	// Secrets are the signing secrets a request may be signed with. To rotate
+	// the secret without rejecting requests while Slack switches over, list
+	// the new one first and the previous one after it. Empty secrets are
+	// ignored.
+	Secrets []string
+
+	// MaxSkew is how far the request's timestamp can be from now, in either
+	// direction, before it's rejected to stop replays. If zero,
+	// DefaultMaxSkew is used.
+	MaxSkew time.Duration
+}
+
+// Validate takes the pieces of a request that allow us to validate its
+// signature. If this returned an error, the validation failed. Returned
+// errors are meant to be logged, not to be sent back to the entity making the
+// request.
+//
+// The signature is compared in constant time against every secret, and the
+// timestamp window is checked only after, so how long validation takes doesn't
+// reveal which check failed or which secret is in use.
+func (v Validator) Validate(r Request) error {
 	if len(r.Timestamp) == 0 {
 		return fmt.Errorf("%s header not present", SlackTimestampHeader)
 	}
@@ -80,13 +99,48 @@ func Validate(key string, r Request) error {
 		return err
 	}
 
-	mac := genHMAC(key, fmt.Sprintf("v0:%d:%s", ts, string(r.Body)))
+	maxSkew := v.MaxSkew
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxSkew
+	}
+
+	skew := time.Now().Unix() - ts
+	maxSecs := int64(maxSkew / time.Second)
+
+	tooOld, tooNew := skew > maxSecs, -skew > maxSecs
 
-	if hmac.Equal([]byte(r.Signature), []byte(mac)) {
+	base := fmt.Sprintf("v0:%d:%s", ts, string(r.Body))
+
+	var match, secrets int
+
+	for _, key := range v.Secrets {
+		if len(key) == 0 {
+			continue
+		}
+
+		secrets++
+		match |= subtle.ConstantTimeCompare([]byte(r.Signature), []byte(genHMAC(key, base)))
+	}
+
+	switch {
+	case secrets == 0:
+		return errors.New("no signing secrets configured")
+	case tooOld:
+		return fmt.Errorf("request timestamp (%d) too old", ts)
+	case tooNew:
+		return fmt.Errorf("request timestamp (%d) too far in the future", ts)
+	case match == 0:
+		return errors.New("signature does not match")
+	default:
 		return nil
 	}
+}
 
-	return errors.New("signature does not match")
+// Validate takes the signature key, and the pieces of a request that allow us
+// to validate its signature, using the DefaultMaxSkew. It's shorthand for a
+// Validator with only the one secret.
+func Validate(key string, r Request) error {
+	return Validator{Secrets: []string{key}}.Validate(r)
 }
 
 // Sign takes the signature key, and a request, and then signs the request using
@@ -132,6 +132,94 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidator_Validate(t *testing.T) {
+	const previousSecret = "previous"
+
+	body := "testBody"
+	now := time.Now().Unix()
+
+	sign := func(key string, ts int64) string {
+		m := hmac.New(sha256.New, []byte(key))
+		_, _ = fmt.Fprintf(m, "v0:%d:%s", ts, body)
+		return fmt.Sprintf("v0=%x", m.Sum(nil))
+	}
+
+	rotating := Validator{Secrets: []string{slackExampleSecret, previousSecret}}
+
+	tests := []struct {
+		name string
+		v    Validator
+		ts   int64
+		key  string
+		err  string
+	}{
+		{
+			name: "primary",
+			v:    rotating,
+			ts:   now,
+			key:  slackExampleSecret,
+		},
+		{
+			name: "previous",
+			v:    rotating,
+			ts:   now,
+			key:  previousSecret,
+		},
+		{
+			name: "unknown_secret",
+			v:    rotating,
+			ts:   now,
+			key:  "unknown",
+			err:  "signature does not match",
+		},
+		{
+			name: "empty_secrets_ignored",
+			v:    Validator{Secrets: []string{"", slackExampleSecret}},
+			ts:   now,
+			key:  slackExampleSecret,
+		},
+		{
+			name: "no_secrets",
+			v:    Validator{Secrets: []string{""}},
+			ts:   now,
+			key:  slackExampleSecret,
+			err:  "no signing secrets configured",
+		},
+		{
+			name: "future_timestamp",
+			v:    rotating,
+			ts:   now + 600,
+			key:  slackExampleSecret,
+			err:  fmt.Sprintf("request timestamp (%d) too far in the future", now+600),
+		},
+		{
+			name: "within_custom_skew",
+			v:    Validator{Secrets: []string{slackExampleSecret}, MaxSkew: 15 * time.Minute},
+			ts:   now - 600,
+			key:  slackExampleSecret,
+		},
+		{
+			name: "outside_custom_skew",
+			v:    Validator{Secrets: []string{slackExampleSecret}, MaxSkew: time.Minute},
+			ts:   now - 120,
+			key:  slackExampleSecret,
+			err:  fmt.Sprintf("request timestamp (%d) too old", now-120),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Request{
+				Timestamp: strconv.FormatInt(tt.ts, 10),
+				Signature: sign(tt.key, tt.ts),
+				Body:      []byte(body),
+			}
+
+			testErrCheck(t, "Validate()", tt.err, tt.v.Validate(r))
+		})
+	}
+}
+
 type garbageRC struct{}
 
 func (garbageRC) Read(_ []byte) (int, error) {
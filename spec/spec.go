@@ -0,0 +1,92 @@
+// Package spec links to the section of the Go language specification or
+// Effective Go that best matches a topic.
+package spec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/poller/docs"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// Prefix is the prefix that's intended to be used by the handler.
+const Prefix = "spec "
+
+// SectionSearcher is the interface to search the indexed documents, generally
+// satisfied by a *docs.DefaultStore.
+type SectionSearcher interface {
+	Search(ctx context.Context, query string) ([]docs.Section, error)
+}
+
+// Searcher is the spec section searcher.
+type Searcher struct {
+	sections SectionSearcher
+	prefix   string
+}
+
+// New returns a new *Searcher.
+func New(prefix string, ss SectionSearcher) *Searcher {
+	return &Searcher{
+		sections: ss,
+		prefix:   prefix,
+	}
+}
+
+// maxOtherResults is the maximum number of other matching sections to list
+// after the best match.
+const maxOtherResults = 3
+
+func docName(doc string) string {
+	switch doc {
+	case docs.Spec:
+		return "The Go Programming Language Specification"
+	case docs.EffectiveGo:
+		return "Effective Go"
+	default:
+		return doc
+	}
+}
+
+// Handler satisfies handler.MessageActionFn.
+func (s *Searcher) Handler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	if !m.BotMentioned() {
+		return nil
+	}
+
+	topic := strings.TrimSpace(m.Text()[len(s.prefix):])
+
+	if len(topic) == 0 {
+		return r.RespondTo(ctx, "You need to specify a topic, like `spec method sets`")
+	}
+
+	sections, err := s.sections.Search(ctx, topic)
+	if err != nil {
+		return fmt.Errorf("failed to search sections: %w", err)
+	}
+
+	if len(sections) == 0 {
+		return r.RespondTo(ctx, fmt.Sprintf("I'm sorry, I couldn't find a section about %q in the spec or Effective Go.", topic))
+	}
+
+	best := sections[0]
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s, *%s*: <%s>", docName(best.Doc), best.Title, best.URL)
+
+	if others := sections[1:]; len(others) > 0 {
+		b.WriteString("\nSee also:")
+
+		for i, sec := range others {
+			if i == maxOtherResults {
+				break
+			}
+
+			fmt.Fprintf(b, "\n- <%s|%s> (%s)", sec.URL, sec.Title, docName(sec.Doc))
+		}
+	}
+
+	return r.RespondTo(ctx, b.String())
+}
@@ -0,0 +1,138 @@
+package tip
+
+import (
+	"fmt"
+	"strings"
+)
+
+var features = []fOption{
+	// features represents all the features known by the tracker
+	//
+	// when adding items, please order alphabetically by the name
+
+	feature("clear builtin", []string{"clear"}, Released, "1.21", 56351,
+		"The `clear` builtin deletes all map entries, or zeroes all slice elements.",
+	),
+
+	feature("embed", []string{"go:embed", "embedding files"}, Released, "1.16", 41191,
+		"The `embed` package and `//go:embed` directive let you embed files into your binary.",
+	),
+
+	feature("encoding/json/v2", []string{"json v2", "jsonv2", "json/v2"}, Experiment, "1.25", 71497,
+		"A new major version of `encoding/json`, available with `GOEXPERIMENT=jsonv2`.",
+	),
+
+	feature("errors.Join", []string{"multi errors", "multierror", "wrapping multiple errors"}, Released, "1.20", 53435,
+		"Errors can wrap multiple errors, via `errors.Join` or multiple `%w` verbs.",
+	),
+
+	feature("fuzzing", []string{"native fuzzing", "fuzz"}, Released, "1.18", 44551,
+		"Native fuzz testing, via `go test -fuzz`.",
+	),
+
+	feature("generic type aliases", []string{"generic aliases"}, Released, "1.24", 46477,
+		"Type aliases may be parameterized, like defined types.",
+	),
+
+	feature("generics", []string{"type parameters", "generic"}, Released, "1.18", 43651,
+		"Type parameters for functions and types.",
+	),
+
+	feature("log/slog", []string{"slog", "structured logging"}, Released, "1.21", 56345,
+		"Structured logging in the standard library.",
+	),
+
+	feature("loop variable semantics", []string{"loopvar", "loop variable", "for loop variable"}, Released, "1.22", 60078,
+		"Each iteration of a `for` loop has its own variables.",
+	),
+
+	feature("math/rand/v2", []string{"rand v2", "math/rand v2"}, Released, "1.22", 61716,
+		"A new version of `math/rand` with better algorithms and API.",
+	),
+
+	feature("min and max builtins", []string{"min", "max", "min max"}, Released, "1.21", 59488,
+		"The `min` and `max` builtins return the smallest / largest of their arguments.",
+	),
+
+	feature("net/http routing patterns", []string{"http routing", "servemux patterns", "enhanced routing"}, Released, "1.22", 61410,
+		"`http.ServeMux` patterns support methods and wildcards.",
+	),
+
+	feature("os.Root", []string{"root", "directory-limited filesystem access"}, Released, "1.24", 67002,
+		"`os.Root` permits filesystem operations confined to a directory.",
+	),
+
+	feature("range over func", []string{"iterators", "range over function", "rangefunc", "iter"}, Released, "1.23", 61405,
+		"`for range` loops accept iterator functions, and the `iter` package defines them.",
+	),
+
+	feature("range over int", []string{"range int", "range over integer"}, Released, "1.22", 61405,
+		"`for i := range 10` loops from 0 to 9.",
+	),
+
+	feature("slices and maps packages", []string{"slices", "maps", "slices package", "maps package"}, Released, "1.21", 45955,
+		"Generic helpers for slices and maps in the standard library.",
+	),
+
+	feature("sum types", []string{"union types", "discriminated unions", "enums"}, Proposed, "", 57644,
+		"There are a number of proposals, but none have been accepted.",
+	),
+
+	feature("swiss table maps", []string{"swiss tables", "swisstable"}, Released, "1.24", 54766,
+		"The built-in map is implemented with Swiss Tables, for better performance.",
+	),
+
+	feature("testing/synctest", []string{"synctest"}, Released, "1.25", 67434,
+		"Support for testing concurrent code with a fake clock.",
+	),
+
+	feature("tool directives", []string{"go tool dependencies", "tools.go", "tool dependencies"}, Released, "1.24", 48429,
+		"`go.mod` can track executable dependencies with `tool` directives.",
+	),
+
+	feature("try", []string{"try builtin", "error handling", "check/handle"}, Declined, "", 32437,
+		"Changes to error handling syntax have been proposed many times, and declined.",
+	),
+
+	feature("unique", []string{"interning", "unique package"}, Released, "1.23", 62483,
+		"The `unique` package canonicalizes (interns) comparable values.",
+	),
+
+	feature("weak pointers", []string{"weak", "weak package"}, Released, "1.24", 67552,
+		"The `weak` package provides weak pointers.",
+	),
+
+	feature("workspaces", []string{"go.work", "workspace mode", "go work"}, Released, "1.18", 45713,
+		"Multi-module workspaces, via `go.work` files.",
+	),
+}
+
+type fOption func(t *Tracker)
+
+func feature(name string, aliases []string, status Status, release string, issue int64, summary string) fOption {
+	return func(t *Tracker) {
+		key := strings.ToLower(name)
+
+		if _, ok := t.entries[key]; ok {
+			panic(fmt.Sprintf("feature %s already defined", name))
+		}
+
+		for _, a := range aliases {
+			a = strings.ToLower(a)
+
+			if v, ok := t.aliases[a]; ok {
+				panic(fmt.Sprintf("alias %s already exists to %s", a, v))
+			}
+
+			t.aliases[a] = key
+		}
+
+		t.entries[key] = Feature{
+			Name:    name,
+			Status:  status,
+			Release: release,
+			Issue:   issue,
+			Summary: summary,
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package tip answers the perennial "when is X landing?" question, by mapping
+// Go features and accepted proposals to their status, target release, and
+// tracking issue.
+package tip
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/internal/poller/proposals"
+	"github.com/gobridge/gopherbot/workqueue"
+)
+
+// Prefix is the prefix that's intended to be used by the handler.
+const Prefix = "tip "
+
+// Status is the state of a feature.
+type Status string
+
+const (
+	// Released is for features that have shipped in a Go release.
+	Released Status = "released"
+
+	// Experiment is for features that have shipped behind a GOEXPERIMENT.
+	Experiment Status = "experiment"
+
+	// Accepted is for accepted proposals still being implemented.
+	Accepted Status = "accepted"
+
+	// Proposed is for features still under discussion.
+	Proposed Status = "proposed"
+
+	// Declined is for proposals that were declined.
+	Declined Status = "declined"
+)
+
+// Feature is a single entry in the dataset.
+type Feature struct {
+	Name    string
+	Status  Status
+	Release string
+	Issue   int64
+	Summary string
+}
+
+func (f Feature) String() string {
+	var state string
+
+	switch f.Status {
+	case Released:
+		state = fmt.Sprintf("was released in Go %s", f.Release)
+	case Experiment:
+		state = fmt.Sprintf("is available as an experiment in Go %s", f.Release)
+	case Accepted:
+		state = "has been accepted and is being worked on"
+		if len(f.Release) > 0 {
+			state += fmt.Sprintf(", targeting Go %s", f.Release)
+		}
+	case Proposed:
+		state = "is still a proposal under discussion"
+	case Declined:
+		state = "was proposed, but the proposal was declined"
+	default:
+		state = "has an unknown status"
+	}
+
+	return fmt.Sprintf("*%s* %s. %s\nTracking issue: <https://golang.org/issue/%d>", f.Name, state, f.Summary, f.Issue)
+}
+
+// ProposalSearcher is the interface to search accepted proposals, generally
+// satisfied by a *proposals.DefaultStore.
+type ProposalSearcher interface {
+	Search(ctx context.Context, query string) ([]proposals.Proposal, error)
+}
+
+// Tracker is the feature tracker.
+type Tracker struct {
+	entries   map[string]Feature
+	aliases   map[string]string
+	proposals ProposalSearcher
+	prefix    string
+}
+
+// New generates a new Tracker from the maintained dataset. The ProposalSearcher
+// is consulted for anything that's not in the dataset.
+func New(prefix string, ps ProposalSearcher) *Tracker {
+	t := &Tracker{
+		entries:   make(map[string]Feature),
+		aliases:   make(map[string]string),
+		proposals: ps,
+		prefix:    prefix,
+	}
+
+	for _, ffn := range features {
+		ffn(t)
+	}
+
+	return t
+}
+
+// maxProposalResults is the maximum number of proposals we'll list when
+// falling back to searching them.
+const maxProposalResults = 5
+
+// Handler satisfies handler.MessageActionFn.
+func (t *Tracker) Handler(ctx workqueue.Context, m handler.Messenger, r handler.Responder) error {
+	if !m.BotMentioned() {
+		return nil
+	}
+
+	name := strings.TrimSpace(m.Text()[len(t.prefix):])
+
+	if len(name) == 0 {
+		return r.RespondTo(ctx, "You need to specify a feature, like `tip generics`")
+	}
+
+	lname := strings.ToLower(name)
+
+	if v, ok := t.aliases[lname]; ok {
+		lname = v
+	}
+
+	if f, ok := t.entries[lname]; ok {
+		return r.RespondMentions(ctx, f.String())
+	}
+
+	ps, err := t.proposals.Search(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to search proposals: %w", err)
+	}
+
+	if len(ps) == 0 {
+		msg := "I'm sorry, I don't know anything about that feature.\n\nPlease consider adding it here and opening a PR: <https://github.com/gobridge/gopherbot/blob/master/tip/features.go>"
+		return r.RespondTo(ctx, msg)
+	}
+
+	b := &strings.Builder{}
+
+	for i, p := range ps {
+		if i == maxProposalResults {
+			fmt.Fprintf(b, "...and %d more\n", len(ps)-maxProposalResults)
+			break
+		}
+
+		milestone := p.Milestone
+		if len(milestone) == 0 {
+			milestone = "no milestone"
+		}
+
+		fmt.Fprintf(b, "- <%s|#%d> %s (%s, %s)\n", p.URL, p.Number, p.Title, p.State, milestone)
+	}
+
+	return r.RespondMentionsTextAttachment(ctx, "I found these accepted proposals that might be what you're looking for:", b.String())
+}
@@ -0,0 +1,384 @@
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Streams returns the names of all the streams the gateway publishes to.
+func Streams() []string {
+	return append([]string(nil), streams...)
+}
+
+// GroupInfo is a consumer group reading a stream.
+type GroupInfo struct {
+	Name      string
+	Consumers int64
+
+	// Pending is how many messages were delivered to a consumer, but not
+	// yet acknowledged.
+	Pending int64
+
+	// LastDeliveredID is the ID of the last message delivered to the group.
+	LastDeliveredID string
+}
+
+// StreamInfo is the contents of a stream.
+type StreamInfo struct {
+	Stream string
+	Length int64
+
+	// FirstID and LastID are the IDs of the oldest and newest messages, or
+	// empty if the stream is.
+	FirstID string
+	LastID  string
+
+	Groups []GroupInfo
+}
+
+// Inspect returns the contents of each stream. Streams that don't exist yet are
+// skipped.
+func Inspect(ctx context.Context, rc *redis.Client) ([]StreamInfo, error) {
+	infos := make([]StreamInfo, 0, len(streams))
+
+	for _, stream := range streams {
+		n, err := rc.Exists(ctx, stream).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s exists: %w", stream, err)
+		}
+
+		if n == 0 {
+			continue
+		}
+
+		info := StreamInfo{Stream: stream}
+
+		if info.Length, err = rc.XLen(ctx, stream).Result(); err != nil {
+			return nil, fmt.Errorf("failed to XLEN %s: %w", stream, err)
+		}
+
+		first, err := rc.XRangeN(ctx, stream, "-", "+", 1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to XRANGE %s: %w", stream, err)
+		}
+
+		last, err := rc.XRevRangeN(ctx, stream, "+", "-", 1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to XREVRANGE %s: %w", stream, err)
+		}
+
+		if len(first) > 0 && len(last) > 0 {
+			info.FirstID, info.LastID = first[0].ID, last[0].ID
+		}
+
+		if info.Groups, err = groups(ctx, rc, stream); err != nil {
+			return nil, err
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+func groups(ctx context.Context, rc *redis.Client, stream string) ([]GroupInfo, error) {
+	res, err := rc.Do(ctx, "XINFO", "GROUPS", stream).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to XINFO GROUPS %s: %w", stream, err)
+	}
+
+	return parseGroups(res), nil
+}
+
+// parseGroups parses an XINFO GROUPS reply, which is a list of flattened
+// field-value pairs for each group.
+func parseGroups(reply interface{}) []GroupInfo {
+	gs, _ := reply.([]interface{})
+
+	infos := make([]GroupInfo, 0, len(gs))
+
+	for _, g := range gs {
+		fields, _ := g.([]interface{})
+
+		var gi GroupInfo
+
+		for i := 0; i+1 < len(fields); i += 2 {
+			k, _ := fields[i].(string)
+
+			switch k {
+			case "name":
+				gi.Name, _ = fields[i+1].(string)
+			case "consumers":
+				gi.Consumers, _ = fields[i+1].(int64)
+			case "pending":
+				gi.Pending, _ = fields[i+1].(int64)
+			case "last-delivered-id":
+				gi.LastDeliveredID, _ = fields[i+1].(string)
+			}
+		}
+
+		infos = append(infos, gi)
+	}
+
+	return infos
+}
+
+// Entry is a message in a stream, as published by the gateway.
+type Entry struct {
+	ID        string
+	Published time.Time
+	EventID   string
+	RequestID string
+
+	// ReplayOf is the ID of the message this one re-published, if it's a
+	// replay.
+	ReplayOf string
+
+	// JSON is the Slack event.
+	JSON string
+}
+
+func entry(m redis.XMessage) Entry {
+	str := func(k string) string {
+		s, _ := m.Values[k].(string)
+		return s
+	}
+
+	return Entry{
+		ID:        m.ID,
+		Published: idTime(m.ID),
+		EventID:   str("event_id"),
+		RequestID: str("request_id"),
+		ReplayOf:  str("replay_of"),
+		JSON:      str("json"),
+	}
+}
+
+// Entries returns up to count messages in the stream, from start to end
+// inclusive. They're stream IDs, or "-" and "+" for the oldest and newest.
+func Entries(ctx context.Context, rc *redis.Client, stream, start, end string, count int64) ([]Entry, error) {
+	if !knownStream(stream) {
+		return nil, fmt.Errorf("unknown stream %q", stream)
+	}
+
+	msgs, err := rc.XRangeN(ctx, stream, start, end, count).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to XRANGE %s: %w", stream, err)
+	}
+
+	entries := make([]Entry, len(msgs))
+	for i, m := range msgs {
+		entries[i] = entry(m)
+	}
+
+	return entries, nil
+}
+
+// replayOf returns the ID of the message the message re-published, if it's a
+// replay.
+func replayOf(values map[string]interface{}) string {
+	s, _ := values["replay_of"].(string)
+	return s
+}
+
+// Replay re-publishes the messages in the stream from start to end inclusive,
+// so the consumer groups reading it handle them again, like after deploying a
+// fix for a handler that mishandled them. The copies have a new gateway time,
+// and the ID of the original in replay_of; replaying a replay points at the
+// original. If dryRun is true, the messages are only counted. It returns the
+// messages that were (or would be) re-published.
+func Replay(ctx context.Context, rc *redis.Client, stream, start, end string, dryRun bool) ([]Entry, error) {
+	if !knownStream(stream) {
+		return nil, fmt.Errorf("unknown stream %q", stream)
+	}
+
+	msgs, err := rc.XRange(ctx, stream, start, end).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to XRANGE %s: %w", stream, err)
+	}
+
+	entries := make([]Entry, 0, len(msgs))
+
+	for _, m := range msgs {
+		if !dryRun {
+			values := make(map[string]interface{}, len(m.Values)+1)
+			for k, v := range m.Values {
+				values[k] = v
+			}
+
+			if len(replayOf(values)) == 0 {
+				values["replay_of"] = m.ID
+			}
+
+			values["gateway_ts"] = strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+
+			err := rc.XAdd(ctx, &redis.XAddArgs{
+				Stream: stream,
+				MaxLen: streamMaxLength,
+				Approx: true,
+				Values: values,
+			}).Err()
+			if err != nil {
+				return entries, fmt.Errorf("failed to re-publish %s: %w", m.ID, err)
+			}
+		}
+
+		entries = append(entries, entry(m))
+	}
+
+	return entries, nil
+}
+
+// ErrUnsafeTrim is returned when trimming a stream would remove messages some
+// consumer group hasn't handled yet.
+var ErrUnsafeTrim = errors.New("trimming would remove messages not yet handled by every consumer group")
+
+// beforeTrim is called between Trim's safety check and the XTRIM, so tests
+// can publish in between.
+var beforeTrim = func() {}
+
+// Trim trims the stream to its newest maxLen messages. Unless force is true,
+// it refuses with ErrUnsafeTrim if that would remove messages that haven't
+// been delivered to, and acknowledged by, every group reading the stream. If
+// dryRun is true, nothing is trimmed. Messages published while Trim runs are
+// kept. It returns how many messages were (or would be) removed.
+func Trim(ctx context.Context, rc *redis.Client, stream string, maxLen int64, force, dryRun bool) (int64, error) {
+	if !knownStream(stream) {
+		return 0, fmt.Errorf("unknown stream %q", stream)
+	}
+
+	if maxLen < 0 {
+		return 0, errors.New("maxLen cannot be negative")
+	}
+
+	length, err := rc.XLen(ctx, stream).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to XLEN %s: %w", stream, err)
+	}
+
+	remove := length - maxLen
+	if remove <= 0 {
+		return 0, nil
+	}
+
+	// trim by the ID of the last message checked rather than by length, so
+	// messages published after the check aren't counted against maxLen
+	msgs, err := rc.XRangeN(ctx, stream, "-", "+", remove).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to XRANGE %s: %w", stream, err)
+	}
+
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+
+	last := msgs[len(msgs)-1].ID
+
+	if !force {
+		safe, err := safeTrimID(ctx, rc, stream)
+		if err != nil {
+			return 0, err
+		}
+
+		if compareIDs(last, safe) >= 0 {
+			return 0, ErrUnsafeTrim
+		}
+	}
+
+	if dryRun {
+		return int64(len(msgs)), nil
+	}
+
+	beforeTrim()
+
+	n, err := rc.XTrimMinID(ctx, stream, nextID(last)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to XTRIM %s: %w", stream, err)
+	}
+
+	return n, nil
+}
+
+// safeTrimID returns the ID of the oldest message that some group still needs:
+// the oldest pending message, or the message after the last delivered to a
+// group. Messages before it can be trimmed.
+func safeTrimID(ctx context.Context, rc *redis.Client, stream string) (string, error) {
+	gs, err := groups(ctx, rc, stream)
+	if err != nil {
+		return "", err
+	}
+
+	// with no groups, nothing needs the messages
+	safe := "+"
+
+	for _, g := range gs {
+		need := nextID(g.LastDeliveredID)
+
+		if g.Pending > 0 {
+			p, err := rc.XPending(ctx, stream, g.Name).Result()
+			if err != nil {
+				return "", fmt.Errorf("failed to XPENDING %s %s: %w", stream, g.Name, err)
+			}
+
+			if compareIDs(p.Lower, need) < 0 {
+				need = p.Lower
+			}
+		}
+
+		if safe == "+" || compareIDs(need, safe) < 0 {
+			safe = need
+		}
+	}
+
+	return safe, nil
+}
+
+// parseID splits a stream ID into its millisecond time and sequence number.
+func parseID(id string) (ms, seq uint64) {
+	parts := strings.SplitN(id, "-", 2)
+
+	ms, _ = strconv.ParseUint(parts[0], 10, 64)
+
+	if len(parts) == 2 {
+		seq, _ = strconv.ParseUint(parts[1], 10, 64)
+	}
+
+	return ms, seq
+}
+
+// compareIDs compares two stream IDs, returning -1, 0, or 1. "+" is after
+// every ID.
+func compareIDs(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "+":
+		return 1
+	case b == "+":
+		return -1
+	}
+
+	ams, aseq := parseID(a)
+	bms, bseq := parseID(b)
+
+	switch {
+	case ams < bms, ams == bms && aseq < bseq:
+		return -1
+	case ams > bms, ams == bms && aseq > bseq:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// nextID returns the smallest ID after id.
+func nextID(id string) string {
+	ms, seq := parseID(id)
+	return fmt.Sprintf("%d-%d", ms, seq+1)
+}
@@ -0,0 +1,85 @@
+package workqueue
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TestTrim_publishDuringTrim trims a stream in the Redis at
+// GOPHER_TEST_REDIS_URL while more messages are published between the safety
+// check and the XTRIM. Only the messages that were checked may be removed.
+func TestTrim_publishDuringTrim(t *testing.T) {
+	url := os.Getenv("GOPHER_TEST_REDIS_URL")
+	if len(url) == 0 {
+		t.Skip("GOPHER_TEST_REDIS_URL not set")
+	}
+
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("failed to parse GOPHER_TEST_REDIS_URL: %v", err)
+	}
+
+	rc := redis.NewClient(opts)
+	defer func() { _ = rc.Close() }()
+
+	ctx := context.Background()
+	stream := slackPublicMessage
+
+	if err := rc.Del(ctx, stream).Err(); err != nil {
+		t.Fatalf("failed to DEL %s: %v", stream, err)
+	}
+	defer func() { _ = rc.Del(ctx, stream).Err() }()
+
+	publish := func() string {
+		id, err := rc.XAdd(ctx, &redis.XAddArgs{
+			Stream: stream,
+			Values: map[string]interface{}{"event": "{}"},
+		}).Result()
+		if err != nil {
+			t.Fatalf("failed to XADD %s: %v", stream, err)
+		}
+
+		return id
+	}
+
+	var ids []string
+	for i := 0; i < 4; i++ {
+		ids = append(ids, publish())
+	}
+
+	defer func(fn func()) { beforeTrim = fn }(beforeTrim)
+	beforeTrim = func() {
+		publish()
+		publish()
+	}
+
+	n, err := Trim(ctx, rc, stream, 2, false, false)
+	if err != nil {
+		t.Fatalf("Trim() unexpected error: %v", err)
+	}
+
+	if n != 2 {
+		t.Fatalf("Trim() = %d, want 2", n)
+	}
+
+	length, err := rc.XLen(ctx, stream).Result()
+	if err != nil {
+		t.Fatalf("failed to XLEN %s: %v", stream, err)
+	}
+
+	if length != 4 {
+		t.Fatalf("stream has %d messages, want 4", length)
+	}
+
+	msgs, err := rc.XRangeN(ctx, stream, "-", "+", 1).Result()
+	if err != nil {
+		t.Fatalf("failed to XRANGE %s: %v", stream, err)
+	}
+
+	if msgs[0].ID != ids[2] {
+		t.Fatalf("oldest message is %s, want %s", msgs[0].ID, ids[2])
+	}
+}
@@ -13,6 +13,27 @@ type ChannelSvc interface {
 	Lookup(channelName string) (slack.Channel, bool, error)
 }
 
+// UserSvc is an interface providing the user service.
+type UserSvc interface {
+	// User finds a user by their ID. If the user is not found, err will be
+	// nil and notFound true.
+	User(id string) (user slack.User, notFound bool, err error)
+}
+
+// UsergroupSvc is an interface providing the usergroup (subteam) service.
+type UsergroupSvc interface {
+	// Usergroup finds a usergroup by its ID. If the usergroup is not found,
+	// err will be nil and notFound true.
+	Usergroup(id string) (group slack.UserGroup, notFound bool, err error)
+}
+
+// EmojiSvc is an interface providing the custom emoji service.
+type EmojiSvc interface {
+	// Emoji finds a custom emoji by its name. If the emoji is not found, err
+	// will be nil and notFound true.
+	Emoji(name string) (value string, notFound bool, err error)
+}
+
 // EventMetadata represents the metadata about the event
 type EventMetadata struct {
 	// ID represents the ID as given to us by Slack.
@@ -26,6 +47,28 @@ type EventMetadata struct {
 
 	// RedisEvent is the ID of the message sent through the Redis queue.
 	RedisEvent string
+
+	// RequestID is the ID of the gateway request the event came in on, which
+	// is in the logs of both the gateway and the handlers, and is the trace ID.
+	RequestID string
+
+	// ReplayOf is the ID of the Redis message this one re-published, if the
+	// event was replayed with wqadmin.
+	ReplayOf string
+
+	// RetryNum is which of Slack's retries the event was delivered on, from
+	// the X-Slack-Retry-Num header, or zero if it was the first delivery.
+	RetryNum int
+
+	// RetryReason is why Slack retried delivering the event, from the
+	// X-Slack-Retry-Reason header, like "http_timeout".
+	RetryReason string
+
+	// EventContext is the event_context Slack sent the event with, for
+	// listing all the installations it was delivered for with
+	// apps.event.authorizations.list. It's empty for interactions and slash
+	// commands.
+	EventContext string
 }
 
 // Context is a superset of context.Context, including methods needed by
@@ -50,16 +93,30 @@ type Context interface {
 	// ChannelSvc provides a way to work with the internal channel metadata
 	// cache.
 	ChannelSvc() ChannelSvc
+
+	// UserSvc provides a way to work with the internal user metadata cache,
+	// like display names and time zones.
+	UserSvc() UserSvc
+
+	// UsergroupSvc provides a way to work with the internal usergroup
+	// metadata cache, like handles and members.
+	UsergroupSvc() UsergroupSvc
+
+	// EmojiSvc provides a way to work with the internal custom emoji cache.
+	EmojiSvc() EmojiSvc
 }
 
 type ctxer struct {
 	context.Context
 
-	s *slack.Client
-	l *zerolog.Logger
-	u *slack.User
-	c ChannelSvc
-	e EventMetadata
+	s  *slack.Client
+	l  *zerolog.Logger
+	u  *slack.User
+	c  ChannelSvc
+	us UserSvc
+	gs UsergroupSvc
+	es EmojiSvc
+	e  EventMetadata
 }
 
 // Meta satisfies Context.
@@ -67,6 +124,25 @@ func (c ctxer) Meta() EventMetadata {
 	return c.e
 }
 
+type metaKey struct{}
+
+// Value satisfies context.Context, also answering for the event metadata, so
+// it can be found in contexts derived from the handler's.
+func (c ctxer) Value(key interface{}) interface{} {
+	if _, ok := key.(metaKey); ok {
+		return c.e
+	}
+
+	return c.Context.Value(key)
+}
+
+// MetaFromContext returns the metadata of the event being handled, if ctx is
+// the Context given to a handler or one derived from it.
+func MetaFromContext(ctx context.Context) (EventMetadata, bool) {
+	e, ok := ctx.Value(metaKey{}).(EventMetadata)
+	return e, ok
+}
+
 // Slack satisfies Context.
 func (c ctxer) Slack() *slack.Client {
 	return c.s
@@ -87,4 +163,19 @@ func (c ctxer) ChannelSvc() ChannelSvc {
 	return c.c
 }
 
+// UserSvc satisfies Context.
+func (c ctxer) UserSvc() UserSvc {
+	return c.us
+}
+
+// UsergroupSvc satisfies Context.
+func (c ctxer) UsergroupSvc() UsergroupSvc {
+	return c.gs
+}
+
+// EmojiSvc satisfies Context.
+func (c ctxer) EmojiSvc() EmojiSvc {
+	return c.es
+}
+
 var _ Context = ctxer{}
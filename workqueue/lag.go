@@ -0,0 +1,117 @@
+package workqueue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// lagScanLimit is how many undelivered messages are counted, per stream,
+// before giving up and reporting the limit.
+const lagScanLimit = 100
+
+// streams are all the streams the gateway publishes to.
+var streams = []string{
+	slackPublicMessage,
+	slackMentionMessage,
+	slackPrivateMessage,
+	slackTeamJoin,
+	slackChannelJoin,
+	slackReactionAdded,
+	slackInteraction,
+	slackChannelTopic,
+	slackChannelChange,
+	slackLinkShared,
+	slackSlashCommand,
+}
+
+// StreamLag is how far behind a consumer group is on one of the streams.
+type StreamLag struct {
+	Stream string
+
+	// Pending is how many messages were delivered to a consumer, but not
+	// yet acknowledged.
+	Pending int64
+
+	// Undelivered is how many messages haven't been delivered to any
+	// consumer yet, up to 100.
+	Undelivered int64
+
+	// Oldest is when the oldest undelivered message was published. It's the
+	// zero value if the group is caught up.
+	Oldest time.Time
+}
+
+// Lag returns how far behind the consumer group is on each stream. Streams
+// that don't exist yet, or that the group isn't reading, are skipped.
+func Lag(ctx context.Context, rc *redis.Client, group string) ([]StreamLag, error) {
+	lags := make([]StreamLag, 0, len(streams))
+
+	for _, stream := range streams {
+		res, err := rc.Do(ctx, "XINFO", "GROUPS", stream).Result()
+		if err != nil {
+			if strings.Contains(err.Error(), "no such key") {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to XINFO GROUPS %s: %w", stream, err)
+		}
+
+		pending, lastID, found := findGroup(res, group)
+		if !found {
+			continue
+		}
+
+		lag := StreamLag{Stream: stream, Pending: pending}
+
+		msgs, err := rc.XRangeN(ctx, stream, lastID, "+", lagScanLimit+1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to XRANGE %s: %w", stream, err)
+		}
+
+		// the range is inclusive, so drop the last delivered message
+		if len(msgs) > 0 && msgs[0].ID == lastID {
+			msgs = msgs[1:]
+		}
+
+		if len(msgs) > lagScanLimit {
+			msgs = msgs[:lagScanLimit]
+		}
+
+		lag.Undelivered = int64(len(msgs))
+
+		if len(msgs) > 0 {
+			lag.Oldest = idTime(msgs[0].ID)
+		}
+
+		lags = append(lags, lag)
+	}
+
+	return lags, nil
+}
+
+// findGroup finds the group in an XINFO GROUPS reply.
+func findGroup(reply interface{}, group string) (pending int64, lastID string, found bool) {
+	for _, g := range parseGroups(reply) {
+		if g.Name == group {
+			return g.Pending, g.LastDeliveredID, true
+		}
+	}
+
+	return 0, "", false
+}
+
+// idTime returns when a stream message was added, from the milliseconds
+// timestamp in its ID.
+func idTime(id string) time.Time {
+	ms, err := strconv.ParseInt(strings.SplitN(id, "-", 2)[0], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
@@ -0,0 +1,59 @@
+package workqueue
+
+// Priority is the tier a stream is consumed in. Each tier has its own Redis
+// consumers, so a flood of events in one, like a busy #general, doesn't delay
+// the events in another, like someone asking the bot for help in a DM.
+type Priority string
+
+const (
+	// PriorityHigh is for the events people are waiting on a reply to: DMs,
+	// messages mentioning the bot (which is how commands, including the admin
+	// ones, are given in channels), interactions with its messages, and its
+	// slash commands.
+	PriorityHigh Priority = "high"
+
+	// PriorityNormal is for the other messages, people joining, channel
+	// topic changes, and links to unfurl.
+	PriorityNormal Priority = "normal"
+
+	// PriorityLow is for reactions, and channels being created, renamed,
+	// archived, or unarchived.
+	PriorityLow Priority = "low"
+)
+
+// Priorities are the tiers, highest first.
+var Priorities = []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+// streamPriorities are the tier each stream is consumed in.
+var streamPriorities = map[string]Priority{
+	slackPrivateMessage: PriorityHigh,
+	slackMentionMessage: PriorityHigh,
+	slackInteraction:    PriorityHigh,
+	slackPublicMessage:  PriorityNormal,
+	slackTeamJoin:       PriorityNormal,
+	slackChannelJoin:    PriorityNormal,
+	slackChannelTopic:   PriorityNormal,
+	slackReactionAdded:  PriorityLow,
+	slackChannelChange:  PriorityLow,
+	slackLinkShared:     PriorityNormal,
+	slackSlashCommand:   PriorityHigh,
+}
+
+// StreamPriority returns the tier the stream is consumed in.
+func StreamPriority(stream string) Priority {
+	if p, ok := streamPriorities[stream]; ok {
+		return p
+	}
+
+	return PriorityNormal
+}
+
+func knownPriority(p Priority) bool {
+	for _, v := range Priorities {
+		if v == p {
+			return true
+		}
+	}
+
+	return false
+}
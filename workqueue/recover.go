@@ -0,0 +1,67 @@
+package workqueue
+
+import (
+	"expvar"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/rs/zerolog"
+)
+
+// panics counts the handler panics recovered, by handler.
+var panics = expvar.NewMap("workqueue_handler_panics")
+
+// Panic describes a handler panic that was recovered.
+type Panic struct {
+	// Handler is the kind of handler, like "message" or "team_join".
+	Handler string
+
+	// Event is the metadata of the event the handler panicked on.
+	Event EventMetadata
+
+	// Value is what the handler panicked with.
+	Value interface{}
+
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+// PanicHandler is called after a handler panic is recovered, for example to
+// report it to the maintainers. It's called synchronously, so it should hand
+// off anything slow.
+type PanicHandler func(p Panic)
+
+// safeCall calls the handler, recovering if it panics. A panic is logged,
+// counted, passed to ph if it's not nil, and then treated as a failure that
+// shouldn't be retried, so the message is acknowledged rather than wedging the
+// queue by being redelivered.
+func safeCall(logger zerolog.Logger, ph PanicHandler, handler string, meta EventMetadata, fn func() (bool, bool, error)) (shouldRetry, discarded bool, err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		p := Panic{
+			Handler: handler,
+			Event:   meta,
+			Value:   r,
+			Stack:   debug.Stack(),
+		}
+
+		panics.Add(handler, 1)
+
+		logger.Error().
+			Str("panic", fmt.Sprint(r)).
+			Str("stack", string(p.Stack)).
+			Msg("handler panicked")
+
+		if ph != nil {
+			ph(p)
+		}
+
+		shouldRetry, discarded, err = false, false, fmt.Errorf("handler panicked: %v", r)
+	}()
+
+	return fn()
+}
@@ -0,0 +1,476 @@
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// The stream consumer here started as a port of
+// github.com/robinjoseph08/redisqueue onto go-redis v9, so each call to Redis
+// can be canceled, with errors logged rather than sent on a channel.
+
+// message is a message read from, or added to, one of the Redis streams.
+type message struct {
+	ID     string
+	Stream string
+	Values map[string]interface{}
+}
+
+// consumerFunc handles a message. If it returns an error, the message isn't
+// acknowledged, and is reclaimed and handled again after the visibility
+// timeout.
+type consumerFunc func(*message) error
+
+// consumerOptions are the options for a consumer.
+type consumerOptions struct {
+	// Name is the name of the consumer within the group
+	Name string
+
+	// GroupName is the name of the consumer group
+	GroupName string
+
+	// VisibilityTimeout is how long a message can be pending before another
+	// consumer claims it. Messages aren't reclaimed if it's zero.
+	VisibilityTimeout time.Duration
+
+	// BlockingTimeout is how long reading from the streams blocks for
+	BlockingTimeout time.Duration
+
+	// ReclaimInterval is how often pending messages are checked for any to
+	// reclaim
+	ReclaimInterval time.Duration
+
+	// BufferSize is how many messages are fetched ahead of being handled
+	BufferSize int
+
+	// Concurrency is how many messages are handled at once
+	Concurrency int
+
+	RedisClient *redis.Client
+	Logger      *zerolog.Logger
+}
+
+type registeredConsumer struct {
+	fn consumerFunc
+	id string
+}
+
+// consumer reads messages from the streams registered with it, as part of a
+// consumer group, and hands them to workers to handle.
+type consumer struct {
+	options *consumerOptions
+	rc      *redis.Client
+	logger  *zerolog.Logger
+
+	// ctx is canceled by shutdown, which stops reading and reclaiming
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	consumers map[string]registeredConsumer
+	streams   []string
+	queue     chan *message
+}
+
+// newConsumer returns a new *consumer, checking Redis supports streams.
+func newConsumer(ctx context.Context, options *consumerOptions) (*consumer, error) {
+	if options.BlockingTimeout == 0 {
+		options.BlockingTimeout = 5 * time.Second
+	}
+
+	if options.ReclaimInterval == 0 {
+		options.ReclaimInterval = time.Second
+	}
+
+	if err := redisPreflightChecks(ctx, options.RedisClient); err != nil {
+		return nil, err
+	}
+
+	l := options.Logger
+	if l == nil {
+		nop := zerolog.Nop()
+		l = &nop
+	}
+
+	cctx, cancel := context.WithCancel(context.Background())
+
+	return &consumer{
+		options:   options,
+		rc:        options.RedisClient,
+		logger:    l,
+		ctx:       cctx,
+		cancel:    cancel,
+		consumers: make(map[string]registeredConsumer),
+		queue:     make(chan *message, options.BufferSize),
+	}, nil
+}
+
+// RegisterWithLastID registers the handler for the stream. The id is the
+// oldest message to read when the consumer group is first created, like "$"
+// for only new messages, and is ignored if the group already exists.
+func (c *consumer) RegisterWithLastID(stream string, id string, fn consumerFunc) {
+	if len(id) == 0 {
+		id = "0"
+	}
+
+	c.consumers[stream] = registeredConsumer{
+		fn: fn,
+		id: id,
+	}
+}
+
+// Run creates the consumer groups, and reads and handles messages until
+// Shutdown is called and the messages already read have been handled.
+func (c *consumer) Run() {
+	if len(c.consumers) == 0 {
+		c.logger.Error().Msg("no stream consumers registered")
+		return
+	}
+
+	c.streams = c.streams[:0]
+
+	for stream, rc := range c.consumers {
+		c.streams = append(c.streams, stream)
+
+		err := c.rc.XGroupCreateMkStream(c.ctx, stream, c.options.GroupName, rc.id).Err()
+
+		// ignoring the BUSYGROUP error makes this a noop
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			c.logger.Error().
+				Err(err).
+				Str("redis_stream", stream).
+				Msg("failed to create consumer group")
+
+			return
+		}
+	}
+
+	for range c.consumers {
+		c.streams = append(c.streams, ">")
+	}
+
+	// the queue is closed once nothing is reading into it, which stops the
+	// workers after they handle what's in it
+	var readers sync.WaitGroup
+
+	readers.Add(2)
+
+	go func() {
+		defer readers.Done()
+		c.reclaim()
+	}()
+
+	go func() {
+		defer readers.Done()
+		c.poll()
+	}()
+
+	go func() {
+		readers.Wait()
+		close(c.queue)
+	}()
+
+	var workers sync.WaitGroup
+
+	workers.Add(c.options.Concurrency)
+
+	for i := 0; i < c.options.Concurrency; i++ {
+		go func() {
+			defer workers.Done()
+			c.work()
+		}()
+	}
+
+	workers.Wait()
+}
+
+// Shutdown stops reading new messages, letting Run return once the messages
+// already read have been handled.
+func (c *consumer) Shutdown() {
+	c.cancel()
+}
+
+// reclaim claims the messages pending for longer than the visibility timeout,
+// such as those read by a consumer that was stopped before handling them,
+// every ReclaimInterval.
+func (c *consumer) reclaim() {
+	if c.options.VisibilityTimeout == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.options.ReclaimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for stream := range c.consumers {
+			c.reclaimStream(stream)
+		}
+	}
+}
+
+func (c *consumer) reclaimStream(stream string) {
+	start := "-"
+
+	for {
+		count := int64(c.options.BufferSize - len(c.queue))
+		if count < 1 {
+			return
+		}
+
+		res, err := c.rc.XPendingExt(c.ctx, &redis.XPendingExtArgs{
+			Stream: stream,
+			Group:  c.options.GroupName,
+			Start:  start,
+			End:    "+",
+			Count:  count,
+		}).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			if c.ctx.Err() == nil {
+				c.logger.Error().
+					Err(err).
+					Str("redis_stream", stream).
+					Msg("failed to list pending messages")
+			}
+
+			return
+		}
+
+		if len(res) == 0 {
+			return
+		}
+
+		for _, r := range res {
+			if r.Idle < c.options.VisibilityTimeout {
+				continue
+			}
+
+			claimed, err := c.rc.XClaim(c.ctx, &redis.XClaimArgs{
+				Stream:   stream,
+				Group:    c.options.GroupName,
+				Consumer: c.options.Name,
+				MinIdle:  c.options.VisibilityTimeout,
+				Messages: []string{r.ID},
+			}).Result()
+
+			switch {
+			case errors.Is(err, redis.Nil):
+				// the message was claimed, but deleted from the stream, such
+				// as by trimming it, so the only way to stop it being pending
+				// is to acknowledge it
+				if err := c.rc.XAck(c.ctx, stream, c.options.GroupName, r.ID).Err(); err != nil {
+					c.logger.Error().
+						Err(err).
+						Str("redis_stream", stream).
+						Str("redis_message", r.ID).
+						Msg("failed to acknowledge deleted message")
+				}
+
+			case err != nil:
+				if c.ctx.Err() == nil {
+					c.logger.Error().
+						Err(err).
+						Str("redis_stream", stream).
+						Str("redis_message", r.ID).
+						Msg("failed to claim message")
+				}
+
+			default:
+				if !c.enqueue(stream, claimed) {
+					return
+				}
+			}
+		}
+
+		next, err := incrementMessageID(res[len(res)-1].ID)
+		if err != nil {
+			c.logger.Error().
+				Err(err).
+				Str("redis_stream", stream).
+				Msg("failed to page pending messages")
+
+			return
+		}
+
+		start = next
+	}
+}
+
+// poll reads new messages from the streams until shutdown, blocking for up to
+// the BlockingTimeout each time.
+func (c *consumer) poll() {
+	for c.ctx.Err() == nil {
+		count := int64(c.options.BufferSize - len(c.queue))
+		if count < 1 {
+			count = 1
+		}
+
+		res, err := c.rc.XReadGroup(c.ctx, &redis.XReadGroupArgs{
+			Group:    c.options.GroupName,
+			Consumer: c.options.Name,
+			Streams:  c.streams,
+			Count:    count,
+			Block:    c.options.BlockingTimeout,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || c.ctx.Err() != nil {
+				continue
+			}
+
+			c.logger.Error().
+				Err(err).
+				Msg("failed to read from streams")
+
+			// don't spin while Redis is unavailable
+			select {
+			case <-c.ctx.Done():
+			case <-time.After(time.Second):
+			}
+
+			continue
+		}
+
+		for _, r := range res {
+			if !c.enqueue(r.Stream, r.Messages) {
+				return
+			}
+		}
+	}
+}
+
+// enqueue hands the messages to the workers, returning false if it was shut
+// down first. Those not handed over are left pending, to be reclaimed.
+func (c *consumer) enqueue(stream string, msgs []redis.XMessage) bool {
+	for _, m := range msgs {
+		msg := &message{
+			ID:     m.ID,
+			Stream: stream,
+			Values: m.Values,
+		}
+
+		select {
+		case c.queue <- msg:
+		case <-c.ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
+
+// work handles messages from the queue, acknowledging those handled without
+// an error, until the queue is closed.
+func (c *consumer) work() {
+	for msg := range c.queue {
+		if err := c.process(msg); err != nil {
+			// the handler has logged it, so it's left for reclaiming
+			continue
+		}
+
+		// acknowledge it even while shutting down, as it was handled
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := c.rc.XAck(ctx, msg.Stream, c.options.GroupName, msg.ID).Err()
+		cancel()
+
+		if err != nil {
+			c.logger.Error().
+				Err(err).
+				Str("redis_stream", msg.Stream).
+				Str("redis_message", msg.ID).
+				Msg("failed to acknowledge message")
+		}
+	}
+}
+
+func (c *consumer) process(msg *message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("consumer func panic: %v", r)
+
+			c.logger.Error().
+				Err(err).
+				Str("redis_stream", msg.Stream).
+				Str("redis_message", msg.ID).
+				Msg("failed to handle message")
+		}
+	}()
+
+	return c.consumers[msg.Stream].fn(msg)
+}
+
+// enqueueMessage adds the message to its stream, trimming the stream to about
+// maxLen messages, and sets its ID.
+func enqueueMessage(ctx context.Context, rc *redis.Client, maxLen int64, msg *message) error {
+	id, err := rc.XAdd(ctx, &redis.XAddArgs{
+		ID:     msg.ID,
+		Stream: msg.Stream,
+		Values: msg.Values,
+		MaxLen: maxLen,
+		Approx: true,
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	msg.ID = id
+
+	return nil
+}
+
+var redisVersionRE = regexp.MustCompile(`redis_version:(.+)`)
+
+// redisPreflightChecks checks Redis can be reached, and supports streams,
+// which need version 5 or later.
+func redisPreflightChecks(ctx context.Context, rc *redis.Client) error {
+	info, err := rc.Info(ctx, "server").Result()
+	if err != nil {
+		return err
+	}
+
+	match := redisVersionRE.FindStringSubmatch(info)
+	if len(match) < 2 {
+		return errors.New("could not extract redis version")
+	}
+
+	version := strings.TrimSpace(match[1])
+
+	major, err := strconv.Atoi(strings.Split(version, ".")[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse redis version %q: %w", version, err)
+	}
+
+	if major < 5 {
+		return fmt.Errorf("redis streams are not supported in version %q", version)
+	}
+
+	return nil
+}
+
+// incrementMessageID returns the message ID after the one given, like
+// 1564886140363-1 after 1564886140363-0, for paging through messages.
+func incrementMessageID(id string) (string, error) {
+	parts := strings.Split(id, "-")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid message ID %q", id)
+	}
+
+	n, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse message ID %q: %w", id, err)
+	}
+
+	return fmt.Sprintf("%s-%d", parts[0], n+1), nil
+}
@@ -0,0 +1,169 @@
+package workqueue
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+var (
+	// rateLimited counts the handlers that failed because Slack rate limited
+	// them, by stream.
+	rateLimited = expvar.NewMap("workqueue_rate_limited")
+
+	// inFlight is how many events are being handled, by stream.
+	inFlight = expvar.NewMap("workqueue_in_flight")
+)
+
+// defaultRateLimitPause is how long handling is paused after a 429 from Slack
+// without a Retry-After.
+const defaultRateLimitPause = time.Second
+
+// ThrottleStats is the state of the adaptive throttle.
+type ThrottleStats struct {
+	// Limit is how many events can be handled at once right now.
+	Limit int
+
+	// Max is how many events can be handled at once when Slack isn't rate
+	// limiting us, which is the total concurrency of the streams.
+	Max int
+
+	// InFlight is how many events are being handled.
+	InFlight int
+
+	// PausedUntil is when handling resumes, if it's paused after a 429.
+	PausedUntil time.Time
+}
+
+// throttle limits how many events are handled at once across all the streams,
+// backing off when Slack returns a 429. The limit is halved, and handling is
+// paused for as long as Slack asked, each time a handler is rate limited, and
+// then grows back by one for each handler that isn't.
+//
+// The workers wait for it before handling an event, so while it's backing off
+// their buffers fill and the consumers stop fetching more from Redis.
+type throttle struct {
+	mu       sync.Mutex
+	max      int
+	limit    int
+	inFlight int
+	until    time.Time
+
+	// wake is closed, and replaced, when a slot frees up
+	wake chan struct{}
+}
+
+func newThrottle(max int) *throttle {
+	if max < 1 {
+		max = 1
+	}
+
+	return &throttle{
+		max:   max,
+		limit: max,
+		wake:  make(chan struct{}),
+	}
+}
+
+// acquire waits for a slot to handle an event in.
+func (t *throttle) acquire(stream string) {
+	for {
+		t.mu.Lock()
+
+		pause := time.Until(t.until)
+
+		if pause <= 0 && t.inFlight < t.limit {
+			t.inFlight++
+			t.mu.Unlock()
+
+			inFlight.Add(stream, 1)
+
+			return
+		}
+
+		wake := t.wake
+		t.mu.Unlock()
+
+		if pause <= 0 {
+			<-wake
+			continue
+		}
+
+		timer := time.NewTimer(pause)
+
+		select {
+		case <-wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// release frees the slot, adjusting the limit based on whether the handler
+// was rate limited.
+func (t *throttle) release(stream string, err error) {
+	inFlight.Add(stream, -1)
+
+	retryAfter, limited := rateLimitedFor(err)
+	if limited {
+		rateLimited.Add(stream, 1)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.inFlight--
+
+	switch {
+	case limited:
+		if t.limit = t.limit / 2; t.limit < 1 {
+			t.limit = 1
+		}
+
+		if until := time.Now().Add(retryAfter); until.After(t.until) {
+			t.until = until
+		}
+
+	case t.limit < t.max:
+		t.limit++
+	}
+
+	close(t.wake)
+	t.wake = make(chan struct{})
+}
+
+func (t *throttle) stats() ThrottleStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := ThrottleStats{
+		Limit:    t.limit,
+		Max:      t.max,
+		InFlight: t.inFlight,
+	}
+
+	if time.Now().Before(t.until) {
+		s.PausedUntil = t.until
+	}
+
+	return s
+}
+
+// rateLimitedFor returns whether the error is from Slack rate limiting us, and
+// if so how long it asked us to wait.
+func rateLimitedFor(err error) (time.Duration, bool) {
+	var rle *slack.RateLimitedError
+
+	if !errors.As(err, &rle) {
+		return 0, false
+	}
+
+	if rle.RetryAfter <= 0 {
+		return defaultRateLimitPause, true
+	}
+
+	return rle.RetryAfter, true
+}
@@ -0,0 +1,158 @@
+package workqueue
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const testStream = "test:throttle"
+
+func TestThrottle_rateLimited(t *testing.T) {
+	th := newThrottle(8)
+
+	for i := 0; i < 4; i++ {
+		th.acquire(testStream)
+	}
+
+	th.release(testStream, &slack.RateLimitedError{RetryAfter: time.Hour})
+
+	s := th.stats()
+
+	if s.Limit != 4 {
+		t.Fatalf("Limit = %d, want 4", s.Limit)
+	}
+
+	if s.InFlight != 3 {
+		t.Fatalf("InFlight = %d, want 3", s.InFlight)
+	}
+
+	if until := time.Until(s.PausedUntil); until < 59*time.Minute {
+		t.Fatalf("paused for %s, want about an hour", until)
+	}
+
+	// a wrapped 429 halves the limit again, and a shorter Retry-After doesn't
+	// cut the pause short
+	th.release(testStream, fmt.Errorf("failed to post: %w", &slack.RateLimitedError{RetryAfter: time.Second}))
+
+	if s := th.stats(); s.Limit != 2 || time.Until(s.PausedUntil) < 59*time.Minute {
+		t.Fatalf("stats() = %+v, want Limit 2 and paused for about an hour", s)
+	}
+
+	// other errors count as successes
+	th.release(testStream, errors.New("channel_not_found"))
+
+	if s := th.stats(); s.Limit != 3 {
+		t.Fatalf("Limit = %d, want 3", s.Limit)
+	}
+}
+
+func TestThrottle_rateLimited_floor(t *testing.T) {
+	th := newThrottle(2)
+
+	for i := 0; i < 3; i++ {
+		th.acquire(testStream)
+		th.release(testStream, &slack.RateLimitedError{RetryAfter: time.Nanosecond})
+	}
+
+	if s := th.stats(); s.Limit != 1 {
+		t.Fatalf("Limit = %d, want 1", s.Limit)
+	}
+}
+
+func TestThrottle_pause(t *testing.T) {
+	const retryAfter = 50 * time.Millisecond
+
+	th := newThrottle(4)
+
+	th.acquire(testStream)
+
+	start := time.Now()
+	th.release(testStream, &slack.RateLimitedError{RetryAfter: retryAfter})
+
+	// acquire waits out the Retry-After, even with slots free
+	th.acquire(testStream)
+
+	if waited := time.Since(start); waited < retryAfter {
+		t.Fatalf("acquire() waited %s, want at least %s", waited, retryAfter)
+	}
+
+	th.release(testStream, nil)
+}
+
+func TestThrottle_pause_default(t *testing.T) {
+	th := newThrottle(4)
+
+	th.acquire(testStream)
+
+	start := time.Now()
+
+	// without a Retry-After, it pauses for defaultRateLimitPause
+	th.release(testStream, &slack.RateLimitedError{})
+
+	end := time.Now()
+
+	if until := th.stats().PausedUntil; until.Before(start.Add(defaultRateLimitPause)) || until.After(end.Add(defaultRateLimitPause)) {
+		t.Fatalf("paused for %s, want %s", until.Sub(start), defaultRateLimitPause)
+	}
+}
+
+func TestThrottle_growsBack(t *testing.T) {
+	th := newThrottle(4)
+
+	th.acquire(testStream)
+	th.release(testStream, &slack.RateLimitedError{RetryAfter: time.Nanosecond})
+
+	if s := th.stats(); s.Limit != 2 {
+		t.Fatalf("Limit = %d, want 2", s.Limit)
+	}
+
+	for want := 3; want <= 4; want++ {
+		th.acquire(testStream)
+		th.release(testStream, nil)
+
+		if s := th.stats(); s.Limit != want {
+			t.Fatalf("Limit = %d, want %d", s.Limit, want)
+		}
+	}
+
+	// it doesn't grow past the max
+	th.acquire(testStream)
+	th.release(testStream, nil)
+
+	if s := th.stats(); s.Limit != 4 || s.InFlight != 0 {
+		t.Fatalf("stats() = %+v, want Limit 4 and nothing in flight", s)
+	}
+}
+
+func TestThrottle_acquire_limit(t *testing.T) {
+	th := newThrottle(1)
+
+	th.acquire(testStream)
+
+	acquired := make(chan struct{})
+
+	go func() {
+		th.acquire(testStream)
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquire() didn't wait for a free slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	th.release(testStream, nil)
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("acquire() didn't return after a slot was released")
+	}
+
+	th.release(testStream, nil)
+}
@@ -0,0 +1,37 @@
+package workqueue
+
+import (
+	"context"
+	"time"
+
+	"github.com/gobridge/gopherbot/internal/trace"
+)
+
+// requestID returns the ID of the gateway request the message came from, or an
+// empty string for messages published without one.
+func requestID(m *message) string {
+	rid, _ := m.Values["request_id"].(string)
+	return rid
+}
+
+// traceMessage continues the trace propagated with the message, recording the
+// time it spent in the queue as a dequeue span, and starts the span for the
+// handler. The returned context carries the handler's span.
+func traceMessage(ctx context.Context, tr *trace.Tracer, m *message, handler string, enqueued time.Time) (context.Context, *trace.Span) {
+	if tp, ok := m.Values["traceparent"].(string); ok {
+		if sc, err := trace.ParseTraceParent(tp); err == nil {
+			ctx = trace.ContextWithSpanContext(ctx, sc)
+		}
+	}
+
+	_, dq := tr.StartAt(ctx, "workqueue.dequeue", enqueued)
+	dq.SetAttribute("redis_stream", m.Stream)
+	dq.SetAttribute("redis_message", m.ID)
+	dq.End()
+
+	ctx, span := tr.Start(ctx, "handler."+handler)
+	span.SetAttribute("handler", handler)
+	span.SetAttribute("redis_message", m.ID)
+
+	return ctx, span
+}
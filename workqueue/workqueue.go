@@ -6,29 +6,45 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
-	"github.com/go-redis/redis"
-	"github.com/robinjoseph08/redisqueue"
+	"github.com/gobridge/gopherbot/internal/trace"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 )
 
+// streamMaxLength is about how many messages are kept in each stream.
+const streamMaxLength = 1024
+
 // Event matches external event types to the Redis stream names we're using
 type Event string
 
 const (
 	slackPublicMessage  = "slack_message_public"
+	slackMentionMessage = "slack_message_mention"
 	slackPrivateMessage = "slack_message_private"
 	slackTeamJoin       = "slack_team_join"
 	slackChannelJoin    = "slack_channel_join"
+	slackReactionAdded  = "slack_reaction_added"
+	slackInteraction    = "slack_interaction"
+	slackChannelTopic   = "slack_channel_topic"
+	slackChannelChange  = "slack_channel_change"
+	slackLinkShared     = "slack_link_shared"
+	slackSlashCommand   = "slack_slash_command"
 )
 
 const (
 	// SlackMessageChannel is the Event for a message with a channel_type of "channel"
 	SlackMessageChannel Event = slackPublicMessage
 
+	// SlackMessageMention is the Event for a message with a channel_type of
+	// "channel" that mentions the bot, which is published to a separate
+	// stream so commands aren't stuck behind a busy channel.
+	SlackMessageMention Event = slackMentionMessage
+
 	// SlackMessageAppHome is the Event for a message with a channel_type of "app_home"
 	SlackMessageAppHome Event = slackPrivateMessage
 
@@ -49,6 +65,38 @@ const (
 
 	// SlackChannelJoin is the Event for a channel (public or private) join Slack event.
 	SlackChannelJoin Event = slackChannelJoin
+
+	// SlackReactionAdded is the Event for a reaction being added to an item.
+	SlackReactionAdded Event = slackReactionAdded
+
+	// SlackBlockActions is the Event for a user interacting with a block
+	// element, like a button or menu, in one of our messages.
+	SlackBlockActions Event = slackInteraction
+
+	// SlackMessageAction is the Event for a user choosing one of our message
+	// shortcuts from a message's menu.
+	SlackMessageAction Event = slackInteraction
+
+	// SlackViewSubmission is the Event for a user submitting one of our
+	// modals.
+	SlackViewSubmission Event = slackInteraction
+
+	// SlackChannelTopic is the Event for a message with a subtype of
+	// channel_topic, channel_purpose, group_topic, or group_purpose, sent
+	// when someone changes a channel's topic or purpose.
+	SlackChannelTopic Event = slackChannelTopic
+
+	// SlackChannelChange is the Event for a public channel being created,
+	// renamed, archived, or unarchived.
+	SlackChannelChange Event = slackChannelChange
+
+	// SlackLinkShared is the Event for a message containing links to one of
+	// the domains the App unfurls.
+	SlackLinkShared Event = slackLinkShared
+
+	// SlackSlashCommand is the Event for a member invoking one of the App's
+	// slash commands.
+	SlackSlashCommand Event = slackSlashCommand
 )
 
 // MessageHandler is the handler for public Slack messages. The handler signals
@@ -76,9 +124,162 @@ type TeamJoinHandler func(ctx Context, tj *slack.TeamJoinEvent) (shouldRetry, di
 // instead an informational message.
 type ChannelJoinHandler func(ctx Context, cj *slackevents.MemberJoinedChannelEvent) (shouldRetry, discarded bool, err error)
 
+// ReactionAddedHandler is the handler for reaction_added Slack events, used
+// when a member adds an emoji reaction to an item. For info on shouldRetry
+// please see the comment for the MessageHandler type.
+//
+// If discarded is true, the returend error isn't treated as an error but
+// instead an informational message.
+type ReactionAddedHandler func(ctx Context, ra *slackevents.ReactionAddedEvent) (shouldRetry, discarded bool, err error)
+
+// InteractionHandler is the handler for Slack interactivity payloads, used
+// when a member interacts with a block element in one of our messages, uses
+// one of our shortcuts, or submits one of our modals. For
+// info on shouldRetry please see the comment for the MessageHandler type.
+//
+// If discarded is true, the returend error isn't treated as an error but
+// instead an informational message.
+type InteractionHandler func(ctx Context, ic *slack.InteractionCallback) (shouldRetry, discarded bool, err error)
+
+// LinkSharedHandler is the handler for link_shared Slack events, used when a
+// member posts a message with links to one of the domains the App unfurls. For
+// info on shouldRetry please see the comment for the MessageHandler type.
+//
+// If discarded is true, the returend error isn't treated as an error but
+// instead an informational message.
+type LinkSharedHandler func(ctx Context, ls *slackevents.LinkSharedEvent) (shouldRetry, discarded bool, err error)
+
+// SlashCommandHandler is the handler for slash commands, used when a member
+// invokes one of the App's slash commands from anywhere in Slack. For info on
+// shouldRetry please see the comment for the MessageHandler type.
+//
+// If discarded is true, the returend error isn't treated as an error but
+// instead an informational message.
+type SlashCommandHandler func(ctx Context, sc *slack.SlashCommand) (shouldRetry, discarded bool, err error)
+
+// ChannelTopicEvent is the message Slack sends when someone changes a public or
+// private channel's topic or purpose.
+type ChannelTopicEvent struct {
+	Type        string `json:"type"`
+	SubType     string `json:"subtype"`
+	User        string `json:"user"`
+	Text        string `json:"text"`
+	TimeStamp   string `json:"ts"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type"`
+
+	// Topic is the new topic, for the channel_topic and group_topic
+	// subtypes.
+	Topic string `json:"topic,omitempty"`
+
+	// Purpose is the new purpose, for the channel_purpose and group_purpose
+	// subtypes.
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// IsPurpose returns whether it's the purpose that changed, rather than the
+// topic.
+func (e *ChannelTopicEvent) IsPurpose() bool {
+	return e.SubType == "channel_purpose" || e.SubType == "group_purpose"
+}
+
+// ChannelTopicHandler is the handler for changes to channels' topics and
+// purposes. For info on shouldRetry please see the comment for the
+// MessageHandler type.
+//
+// If discarded is true, the returend error isn't treated as an error but
+// instead an informational message.
+type ChannelTopicHandler func(ctx Context, ct *ChannelTopicEvent) (shouldRetry, discarded bool, err error)
+
+// ChannelChangeEvent is the event Slack sends when a public channel is created,
+// renamed, archived, or unarchived.
+type ChannelChangeEvent struct {
+	// Type is channel_created, channel_rename, channel_archive, or
+	// channel_unarchive.
+	Type      string
+	ChannelID string
+
+	// Name is the channel's new name, for the channel_created and
+	// channel_rename types.
+	Name string
+
+	// User is who archived or unarchived the channel, or created it.
+	User string
+}
+
+// UnmarshalJSON satisfies json.Unmarshaler. The channel is an object for the
+// channel_created and channel_rename types, and only its ID for the others.
+func (e *ChannelChangeEvent) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Type    string          `json:"type"`
+		Channel json.RawMessage `json:"channel"`
+		User    string          `json:"user"`
+	}
+
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*e = ChannelChangeEvent{Type: raw.Type, User: raw.User}
+
+	if len(raw.Channel) > 0 && raw.Channel[0] == '{' {
+		var ch struct {
+			ID      string `json:"id"`
+			Name    string `json:"name"`
+			Creator string `json:"creator"`
+		}
+
+		if err := json.Unmarshal(raw.Channel, &ch); err != nil {
+			return fmt.Errorf("failed to unmarshal channel: %w", err)
+		}
+
+		e.ChannelID, e.Name = ch.ID, ch.Name
+
+		if len(e.User) == 0 {
+			e.User = ch.Creator
+		}
+
+		return nil
+	}
+
+	if err := json.Unmarshal(raw.Channel, &e.ChannelID); err != nil {
+		return fmt.Errorf("failed to unmarshal channel ID: %w", err)
+	}
+
+	return nil
+}
+
+// ChannelChangeHandler is the handler for public channels being created,
+// renamed, archived, or unarchived. For info on shouldRetry please see the
+// comment for the MessageHandler type.
+//
+// If discarded is true, the returend error isn't treated as an error but
+// instead an informational message.
+type ChannelChangeHandler func(ctx Context, cc *ChannelChangeEvent) (shouldRetry, discarded bool, err error)
+
 // Publisher is the interface for the workqueue publish behavior.
 type Publisher interface {
-	Publish(e Event, eventTimestamp int64, eventID, requetID string, jsonData []byte) error
+	Publish(e Event, eventTimestamp int64, eventID, requetID string, jsonData []byte, opts ...PublishOption) error
+}
+
+// PublishOption sets optional values on a published message.
+type PublishOption func(values map[string]interface{})
+
+// WithRetry marks the message as being from Slack's nth retry of delivering
+// the event, for the reason Slack gave, like "http_timeout".
+func WithRetry(num int, reason string) PublishOption {
+	return func(values map[string]interface{}) {
+		values["retry_num"] = strconv.Itoa(num)
+		values["retry_reason"] = reason
+	}
+}
+
+// WithEventContext sets the event_context Slack sent the event with, which
+// identifies it when listing all the installations it was delivered for.
+func WithEventContext(ec string) PublishOption {
+	return func(values map[string]interface{}) {
+		values["event_context"] = ec
+	}
 }
 
 // Registerer is the interface for handler registrations within the workqueue.
@@ -87,6 +288,12 @@ type Registerer interface {
 	RegisterChannelJoinsHandler(timeout time.Duration, fn ChannelJoinHandler)
 	RegisterPublicMessagesHandler(timeout time.Duration, fn MessageHandler)
 	RegisterPrivateMessagesHandler(timeout time.Duration, fn MessageHandler)
+	RegisterReactionAddedHandler(timeout time.Duration, fn ReactionAddedHandler)
+	RegisterInteractionsHandler(timeout time.Duration, fn InteractionHandler)
+	RegisterChannelTopicsHandler(timeout time.Duration, fn ChannelTopicHandler)
+	RegisterChannelChangesHandler(timeout time.Duration, fn ChannelChangeHandler)
+	RegisterLinkSharedHandler(timeout time.Duration, fn LinkSharedHandler)
+	RegisterSlashCommandsHandler(timeout time.Duration, fn SlashCommandHandler)
 }
 
 // Q is an interface to describe the entirety of the workqueue.
@@ -95,6 +302,26 @@ type Q interface {
 	Registerer
 }
 
+const (
+	// DefaultConcurrency is how many events from a stream are handled at
+	// once, unless configured otherwise.
+	DefaultConcurrency = 2
+
+	// DefaultBufferSize is how many events from a stream are fetched ahead
+	// of being handled, unless configured otherwise.
+	DefaultBufferSize = 1
+)
+
+// StreamConfig is how events from a stream are consumed. Its zero fields are
+// defaulted.
+type StreamConfig struct {
+	// Concurrency is how many events are handled at once.
+	Concurrency int
+
+	// BufferSize is how many events are fetched ahead of being handled.
+	BufferSize int
+}
+
 // Config is the I configuration
 type Config struct {
 	// ConsumerName is this node's unique identifier. Leave blank to use
@@ -114,6 +341,19 @@ type Config struct {
 	// RedisClient is the *redis.Client to use for the workqueue.
 	RedisClient *redis.Client
 
+	// Default is how events are consumed in the priority tiers not in
+	// Priorities. It defaults to DefaultConcurrency and DefaultBufferSize.
+	Default StreamConfig
+
+	// Priorities is how events are consumed in each priority tier, from the
+	// streams in the tier not in Streams.
+	Priorities map[Priority]StreamConfig
+
+	// Streams is how events are consumed from specific streams, by stream
+	// name (like "slack_message_public"). Each of them gets its own Redis
+	// consumer, so a busy stream doesn't hold up the others.
+	Streams map[string]StreamConfig
+
 	// Logger is the logger
 	Logger *zerolog.Logger
 
@@ -126,18 +366,52 @@ type Config struct {
 	// ChannelCache is the cache the workqueue will present as the ChannelSvc.
 	// Generally this is implemented by a *cache.Channel.
 	ChannelCache ChannelSvc
+
+	// UserCache is the cache the workqueue will present as the UserSvc.
+	// Generally this is implemented by a *cache.User.
+	UserCache UserSvc
+
+	// UsergroupCache is the cache the workqueue will present as the
+	// UsergroupSvc. Generally this is implemented by a *cache.Usergroup.
+	UsergroupCache UsergroupSvc
+
+	// EmojiCache is the cache the workqueue will present as the EmojiSvc.
+	// Generally this is implemented by a *cache.Emoji.
+	EmojiCache EmojiSvc
+
+	// PanicHandler, if set, is called when a handler panics, after the panic
+	// is recovered and logged.
+	PanicHandler PanicHandler
+
+	// Tracer, if set, records spans for publishing messages and handling them,
+	// continuing the trace from the publisher.
+	Tracer *trace.Tracer
 }
 
 // I is the workqueue struct, which satisfies Q.
 type I struct {
-	p *redisqueue.Producer
-	c *redisqueue.Consumer
+	rc *redis.Client
+
+	// tiers consume the streams without their own consumer in streams
+	tiers   map[Priority]*consumer
+	streams map[string]*consumer
+
+	// registered are the consumers with handlers, which are the ones run
+	mu         sync.Mutex
+	registered []*consumer
+
+	th *throttle
 
 	l *zerolog.Logger
 
 	sc   *slack.Client
 	self *slack.User
 	cs   ChannelSvc
+	us   UserSvc
+	gs   UsergroupSvc
+	es   EmojiSvc
+	ph   PanicHandler
+	tr   *trace.Tracer
 }
 
 // compile time check: does *I satisfy Q?
@@ -147,63 +421,222 @@ var _ Q = (*I)(nil)
 // visibilityTimeout can be left at their zero value if you're only using I to
 // publish.
 func New(cfg Config) (*I, error) {
-	p, err := redisqueue.NewProducerWithOptions(&redisqueue.ProducerOptions{
-		ApproximateMaxLength: true,
-		StreamMaxLength:      1024,
-		RedisClient:          cfg.RedisClient,
-	})
-	if err != nil {
+	ctx := context.Background()
+
+	if err := redisPreflightChecks(ctx, cfg.RedisClient); err != nil {
 		return nil, fmt.Errorf("failed to make producer: %w", err)
 	}
 
-	c, err := redisqueue.NewConsumerWithOptions(&redisqueue.ConsumerOptions{
-		Name:              cfg.ConsumerName,
-		GroupName:         cfg.ConsumerGroup,
-		VisibilityTimeout: cfg.VisibilityTimeout,
-		BlockingTimeout:   10 * time.Second,
-		ReclaimInterval:   time.Second,
-		BufferSize:        1,
-		Concurrency:       2,
-		RedisClient:       cfg.RedisClient,
+	def := cfg.Default.withDefaults(StreamConfig{
+		Concurrency: DefaultConcurrency,
+		BufferSize:  DefaultBufferSize,
 	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to prepare consumer: %w", err)
+
+	makeConsumer := func(sc StreamConfig) (*consumer, error) {
+		return newConsumer(ctx, &consumerOptions{
+			Name:              cfg.ConsumerName,
+			GroupName:         cfg.ConsumerGroup,
+			VisibilityTimeout: cfg.VisibilityTimeout,
+			BlockingTimeout:   10 * time.Second,
+			ReclaimInterval:   time.Second,
+			BufferSize:        sc.BufferSize,
+			Concurrency:       sc.Concurrency,
+			RedisClient:       cfg.RedisClient,
+			Logger:            cfg.Logger,
+		})
+	}
+
+	if def.Concurrency < 1 || def.BufferSize < 0 {
+		return nil, errors.New("invalid default stream config: concurrency must be positive and buffer size not negative")
+	}
+
+	// the throttle's limit starts at the total concurrency, so it only
+	// holds the workers back after Slack rate limits us
+	var total int
+
+	var err error
+
+	for pri := range cfg.Priorities {
+		if !knownPriority(pri) {
+			return nil, fmt.Errorf("unknown priority %q", pri)
+		}
+	}
+
+	tiers := make(map[Priority]*consumer, len(Priorities))
+
+	for _, pri := range Priorities {
+		tc := cfg.Priorities[pri].withDefaults(def)
+
+		if tc.Concurrency < 1 || tc.BufferSize < 0 {
+			return nil, fmt.Errorf("invalid config for %s priority: concurrency must be positive and buffer size not negative", pri)
+		}
+
+		if tiers[pri], err = makeConsumer(tc); err != nil {
+			return nil, fmt.Errorf("failed to prepare %s priority consumer: %w", pri, err)
+		}
+
+		total += tc.Concurrency
+	}
+
+	scs := make(map[string]*consumer, len(cfg.Streams))
+
+	for stream, sc := range cfg.Streams {
+		if !knownStream(stream) {
+			return nil, fmt.Errorf("unknown stream %q", stream)
+		}
+
+		sc = sc.withDefaults(cfg.Priorities[StreamPriority(stream)].withDefaults(def))
+
+		if sc.Concurrency < 1 || sc.BufferSize < 0 {
+			return nil, fmt.Errorf("invalid config for stream %s: concurrency must be positive and buffer size not negative", stream)
+		}
+
+		if scs[stream], err = makeConsumer(sc); err != nil {
+			return nil, fmt.Errorf("failed to prepare %s consumer: %w", stream, err)
+		}
+
+		total += sc.Concurrency
 	}
 
 	i := &I{
-		p:    p,
-		c:    c,
-		l:    cfg.Logger,
-		sc:   cfg.SlackClient,
-		self: cfg.SlackUser,
-		cs:   cfg.ChannelCache,
+		rc:      cfg.RedisClient,
+		tiers:   tiers,
+		streams: scs,
+		th:      newThrottle(total),
+		l:       cfg.Logger,
+		sc:      cfg.SlackClient,
+		self:    cfg.SlackUser,
+		cs:      cfg.ChannelCache,
+		us:      cfg.UserCache,
+		gs:      cfg.UsergroupCache,
+		es:      cfg.EmojiCache,
+		ph:      cfg.PanicHandler,
+		tr:      cfg.Tracer,
 	}
 
 	return i, nil
 }
 
-// Run wraps the redisqueue.Consumer.Run method
+// withDefaults returns the config with its zero fields set from d.
+func (sc StreamConfig) withDefaults(d StreamConfig) StreamConfig {
+	if sc.Concurrency == 0 {
+		sc.Concurrency = d.Concurrency
+	}
+
+	if sc.BufferSize == 0 {
+		sc.BufferSize = d.BufferSize
+	}
+
+	return sc
+}
+
+func knownStream(stream string) bool {
+	for _, s := range streams {
+		if s == stream {
+			return true
+		}
+	}
+
+	return false
+}
+
+// register registers the handler with the stream's consumer.
+func (i *I) register(stream string, fn consumerFunc) {
+	c, ok := i.streams[stream]
+	if !ok {
+		c = i.tiers[StreamPriority(stream)]
+	}
+
+	c.RegisterWithLastID(stream, "$", fn)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, rc := range i.registered {
+		if rc == c {
+			return
+		}
+	}
+
+	i.registered = append(i.registered, c)
+}
+
+func (i *I) consumers() []*consumer {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return append([]*consumer(nil), i.registered...)
+}
+
+// Run runs the consumers with handlers registered, until they're
+// shut down.
 func (i *I) Run() {
-	i.c.Run()
+	var wg sync.WaitGroup
+
+	for _, c := range i.consumers() {
+		wg.Add(1)
+
+		go func(c *consumer) {
+			defer wg.Done()
+			c.Run()
+		}(c)
+	}
+
+	wg.Wait()
 }
 
-// Shutdown wraps the redisqueue.Consumer.Shutdown method
+// Shutdown shuts down the consumers, waiting for the events being
+// handled.
 func (i *I) Shutdown() {
-	i.c.Shutdown()
+	for _, c := range i.consumers() {
+		c.Shutdown()
+	}
+}
+
+// ThrottleStats returns the state of the throttle that backs off handling
+// events when Slack rate limits us.
+func (i *I) ThrottleStats() ThrottleStats {
+	return i.th.stats()
 }
 
 // Publish takes an Event, which roughly map to different Slack event types, the event timestamp (from the Slack side),
-func (i *I) Publish(e Event, eventTimestamp int64, eventID, requestID string, jsonData []byte) error {
-	return i.p.Enqueue(&redisqueue.Message{
+// the event ID, and the request ID, which is also used as the trace ID.
+func (i *I) Publish(e Event, eventTimestamp int64, eventID, requestID string, jsonData []byte, opts ...PublishOption) error {
+	ctx := context.Background()
+	if len(requestID) > 0 {
+		ctx = trace.ContextWithSpanContext(ctx, trace.SpanContext{TraceID: trace.TraceIDFromRequestID(requestID)})
+	}
+
+	_, span := i.tr.Start(ctx, "workqueue.publish")
+	span.SetAttribute("redis_stream", string(e))
+	span.SetAttribute("event_id", eventID)
+	span.SetAttribute("request_id", requestID)
+
+	values := map[string]interface{}{
+		"request_id": requestID,
+		"gateway_ts": strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10),
+		"event_ts":   strconv.FormatInt(eventTimestamp, 10),
+		"event_id":   eventID,
+		"json":       string(jsonData),
+	}
+
+	if i.tr != nil {
+		values["traceparent"] = span.SpanContext().TraceParent()
+	}
+
+	for _, o := range opts {
+		o(values)
+	}
+
+	err := enqueueMessage(ctx, i.rc, streamMaxLength, &message{
 		Stream: string(e),
-		Values: map[string]interface{}{
-			"request_id": requestID,
-			"gateway_ts": strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10),
-			"event_ts":   strconv.FormatInt(eventTimestamp, 10),
-			"event_id":   eventID,
-			"json":       string(jsonData),
-		},
+		Values: values,
 	})
+
+	span.RecordError(err)
+	span.End()
+
+	return err
 }
 
 // RegisterPublicMessagesHandler is the method to register a new handler for
@@ -223,25 +656,67 @@ func (i *I) RegisterPrivateMessagesHandler(timeout time.Duration, fn MessageHand
 }
 
 func (i *I) registerMessageHandler(stream string, timeout time.Duration, fn MessageHandler) {
-	i.c.RegisterWithLastID(stream, "$", messageHandlerFactory(i.l, i.sc, i.self, i.cs, timeout, fn))
+	// messages mentioning the bot are public messages too, just prioritized
+	if stream == slackPublicMessage {
+		i.register(slackMentionMessage, messageHandlerFactory(i.l, i.sc, i.self, i.cs, i.us, i.gs, i.es, i.ph, i.tr, i.th, timeout, fn))
+	}
+
+	i.register(stream, messageHandlerFactory(i.l, i.sc, i.self, i.cs, i.us, i.gs, i.es, i.ph, i.tr, i.th, timeout, fn))
 }
 
 // RegisterTeamJoinsHandler registers the handler for events related to people
 // joining the Slack workspace.
 func (i *I) RegisterTeamJoinsHandler(timeout time.Duration, fn TeamJoinHandler) {
-	i.c.RegisterWithLastID(slackTeamJoin, "$", teamJoinHandlerFactory(i.l, i.sc, i.self, i.cs, timeout, fn))
+	i.register(slackTeamJoin, teamJoinHandlerFactory(i.l, i.sc, i.self, i.cs, i.us, i.gs, i.es, i.ph, i.tr, i.th, timeout, fn))
 }
 
 // RegisterChannelJoinsHandler registers the handler for events related to
 // people joining channels in the Slack workspace.
 func (i *I) RegisterChannelJoinsHandler(timeout time.Duration, fn ChannelJoinHandler) {
-	i.c.RegisterWithLastID(slackChannelJoin, "$", channelJoinHandlerFactory(i.l, i.sc, i.self, i.cs, timeout, fn))
+	i.register(slackChannelJoin, channelJoinHandlerFactory(i.l, i.sc, i.self, i.cs, i.us, i.gs, i.es, i.ph, i.tr, i.th, timeout, fn))
+}
+
+// RegisterReactionAddedHandler registers the handler for events related to
+// people adding reactions to messages in the Slack workspace.
+func (i *I) RegisterReactionAddedHandler(timeout time.Duration, fn ReactionAddedHandler) {
+	i.register(slackReactionAdded, reactionAddedHandlerFactory(i.l, i.sc, i.self, i.cs, i.us, i.gs, i.es, i.ph, i.tr, i.th, timeout, fn))
+}
+
+// RegisterInteractionsHandler registers the handler for interactivity payloads,
+// sent when people interact with block elements in the bot's messages, use its
+// shortcuts, or submit its modals.
+func (i *I) RegisterInteractionsHandler(timeout time.Duration, fn InteractionHandler) {
+	i.register(slackInteraction, interactionHandlerFactory(i.l, i.sc, i.self, i.cs, i.us, i.gs, i.es, i.ph, i.tr, i.th, timeout, fn))
+}
+
+// RegisterChannelTopicsHandler registers the handler for changes to channels'
+// topics and purposes.
+func (i *I) RegisterChannelTopicsHandler(timeout time.Duration, fn ChannelTopicHandler) {
+	i.register(slackChannelTopic, channelTopicHandlerFactory(i.l, i.sc, i.self, i.cs, i.us, i.gs, i.es, i.ph, i.tr, i.th, timeout, fn))
+}
+
+// RegisterChannelChangesHandler registers the handler for public channels being
+// created, renamed, archived, or unarchived.
+func (i *I) RegisterChannelChangesHandler(timeout time.Duration, fn ChannelChangeHandler) {
+	i.register(slackChannelChange, channelChangeHandlerFactory(i.l, i.sc, i.self, i.cs, i.us, i.gs, i.es, i.ph, i.tr, i.th, timeout, fn))
+}
+
+// RegisterLinkSharedHandler registers the handler for links to the domains the
+// App unfurls being posted.
+func (i *I) RegisterLinkSharedHandler(timeout time.Duration, fn LinkSharedHandler) {
+	i.register(slackLinkShared, linkSharedHandlerFactory(i.l, i.sc, i.self, i.cs, i.us, i.gs, i.es, i.ph, i.tr, i.th, timeout, fn))
+}
+
+// RegisterSlashCommandsHandler registers the handler for members invoking the
+// App's slash commands.
+func (i *I) RegisterSlashCommandsHandler(timeout time.Duration, fn SlashCommandHandler) {
+	i.register(slackSlashCommand, slashCommandHandlerFactory(i.l, i.sc, i.self, i.cs, i.us, i.gs, i.es, i.ph, i.tr, i.th, timeout, fn))
 }
 
-func messageHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, timeout time.Duration, fn MessageHandler) redisqueue.ConsumerFunc {
+func messageHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, usvc UserSvc, gsvc UsergroupSvc, esvc EmojiSvc, ph PanicHandler, tr *trace.Tracer, th *throttle, timeout time.Duration, fn MessageHandler) consumerFunc {
 	flogger := baseLogger.With().Str("handler", "message").Logger()
 
-	return func(m *redisqueue.Message) error {
+	return func(m *message) error {
 		start := time.Now()
 
 		// build message-local logging context
@@ -260,10 +735,13 @@ func messageHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser
 			return nil
 		}
 
+		rid := requestID(m)
+
 		// log time fired on Slack side, and time it was enqueued
 		logger = logger.With().
 			Time("event_time", et).
 			Str("event_id", eid).
+			Str("request_id", rid).
 			Time("enqueued_time", gt).Logger()
 
 		var sm *slackevents.MessageEvent
@@ -278,27 +756,56 @@ func messageHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser
 			return nil
 		}
 
+		// wait for the throttle before starting the handler's timeout, so
+		// backing off from Slack doesn't eat into it
+		th.acquire(m.Stream)
+
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 
+		ctx, span := traceMessage(ctx, tr, m, "message", gt)
+
 		wqctx := ctxer{
 			Context: ctx,
 			s:       sc,
 			l:       &logger,
 			u:       botUser,
 			c:       csvc,
-			e:       EventMetadata{eid, et, gt, m.ID},
+			us:      usvc,
+			gs:      gsvc,
+			es:      esvc,
+			e: EventMetadata{
+				ID:           eid,
+				Time:         et,
+				IngestTime:   gt,
+				RedisEvent:   m.ID,
+				RequestID:    rid,
+				ReplayOf:     replayOf(m.Values),
+				RetryNum:     retryNum(m.Values),
+				RetryReason:  retryReason(m.Values),
+				EventContext: eventContext(m.Values),
+			},
 		}
 
 		// used to calculate handler duration
 		bht := time.Now()
 
-		shouldRetry, discarded, err := fn(wqctx, sm)
+		shouldRetry, discarded, err := safeCall(logger, ph, "message", wqctx.e, func() (bool, bool, error) {
+			return fn(wqctx, sm)
+		})
 
 		// handler runtime duration
 		hrd := time.Since(bht)
 
 		cancel()
 
+		th.release(m.Stream, err)
+
+		if err != nil && !discarded {
+			span.RecordError(err)
+		}
+
+		span.End()
+
 		logger = logger.With().Dur("handler_duration", hrd).Logger()
 
 		if err != nil {
@@ -331,10 +838,10 @@ func messageHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser
 	}
 }
 
-func teamJoinHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, timeout time.Duration, fn TeamJoinHandler) redisqueue.ConsumerFunc {
+func teamJoinHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, usvc UserSvc, gsvc UsergroupSvc, esvc EmojiSvc, ph PanicHandler, tr *trace.Tracer, th *throttle, timeout time.Duration, fn TeamJoinHandler) consumerFunc {
 	flogger := baseLogger.With().Str("handler", "team_join").Logger()
 
-	return func(m *redisqueue.Message) error {
+	return func(m *message) error {
 		start := time.Now()
 
 		// build message-local logging context
@@ -353,10 +860,13 @@ func teamJoinHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUse
 			return nil
 		}
 
+		rid := requestID(m)
+
 		// log time fired on Slack side, and time it was enqueued
 		logger = logger.With().
 			Time("event_time", et).
 			Str("event_id", eid).
+			Str("request_id", rid).
 			Time("enqueued_time", gt).Logger()
 
 		var stj *slack.TeamJoinEvent
@@ -371,27 +881,56 @@ func teamJoinHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUse
 			return nil
 		}
 
+		// wait for the throttle before starting the handler's timeout, so
+		// backing off from Slack doesn't eat into it
+		th.acquire(m.Stream)
+
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 
+		ctx, span := traceMessage(ctx, tr, m, "team_join", gt)
+
 		wqctx := ctxer{
 			Context: ctx,
 			s:       sc,
 			l:       &logger,
 			u:       botUser,
 			c:       csvc,
-			e:       EventMetadata{eid, et, gt, m.ID},
+			us:      usvc,
+			gs:      gsvc,
+			es:      esvc,
+			e: EventMetadata{
+				ID:           eid,
+				Time:         et,
+				IngestTime:   gt,
+				RedisEvent:   m.ID,
+				RequestID:    rid,
+				ReplayOf:     replayOf(m.Values),
+				RetryNum:     retryNum(m.Values),
+				RetryReason:  retryReason(m.Values),
+				EventContext: eventContext(m.Values),
+			},
 		}
 
 		// used to calculate handler duration
 		bht := time.Now()
 
-		shouldRetry, discarded, err := fn(wqctx, stj)
+		shouldRetry, discarded, err := safeCall(logger, ph, "team_join", wqctx.e, func() (bool, bool, error) {
+			return fn(wqctx, stj)
+		})
 
 		// handler runtime duration
 		hrd := time.Since(bht)
 
 		cancel()
 
+		th.release(m.Stream, err)
+
+		if err != nil && !discarded {
+			span.RecordError(err)
+		}
+
+		span.End()
+
 		logger = logger.With().Dur("handler_duration", hrd).Logger()
 
 		if err != nil {
@@ -424,10 +963,10 @@ func teamJoinHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUse
 	}
 }
 
-func channelJoinHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, timeout time.Duration, fn ChannelJoinHandler) redisqueue.ConsumerFunc {
+func channelJoinHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, usvc UserSvc, gsvc UsergroupSvc, esvc EmojiSvc, ph PanicHandler, tr *trace.Tracer, th *throttle, timeout time.Duration, fn ChannelJoinHandler) consumerFunc {
 	flogger := baseLogger.With().Str("handler", "channel_join").Logger()
 
-	return func(m *redisqueue.Message) error {
+	return func(m *message) error {
 		start := time.Now()
 
 		// build message-local logging context
@@ -446,10 +985,13 @@ func channelJoinHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, bot
 			return nil
 		}
 
+		rid := requestID(m)
+
 		// log time fired on Slack side, and time it was enqueued
 		logger = logger.With().
 			Time("event_time", et).
 			Str("event_id", eid).
+			Str("request_id", rid).
 			Time("enqueued_time", gt).Logger()
 
 		var mjce *slackevents.MemberJoinedChannelEvent
@@ -464,27 +1006,56 @@ func channelJoinHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, bot
 			return nil
 		}
 
+		// wait for the throttle before starting the handler's timeout, so
+		// backing off from Slack doesn't eat into it
+		th.acquire(m.Stream)
+
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 
+		ctx, span := traceMessage(ctx, tr, m, "channel_join", gt)
+
 		wqctx := ctxer{
 			Context: ctx,
 			s:       sc,
 			l:       &logger,
 			u:       botUser,
 			c:       csvc,
-			e:       EventMetadata{eid, et, gt, m.ID},
+			us:      usvc,
+			gs:      gsvc,
+			es:      esvc,
+			e: EventMetadata{
+				ID:           eid,
+				Time:         et,
+				IngestTime:   gt,
+				RedisEvent:   m.ID,
+				RequestID:    rid,
+				ReplayOf:     replayOf(m.Values),
+				RetryNum:     retryNum(m.Values),
+				RetryReason:  retryReason(m.Values),
+				EventContext: eventContext(m.Values),
+			},
 		}
 
 		// used to calculate handler duration
 		bht := time.Now()
 
-		shouldRetry, discarded, err := fn(wqctx, mjce)
+		shouldRetry, discarded, err := safeCall(logger, ph, "channel_join", wqctx.e, func() (bool, bool, error) {
+			return fn(wqctx, mjce)
+		})
 
 		// handler runtime duration
 		hrd := time.Since(bht)
 
 		cancel()
 
+		th.release(m.Stream, err)
+
+		if err != nil && !discarded {
+			span.RecordError(err)
+		}
+
+		span.End()
+
 		logger = logger.With().Dur("handler_duration", hrd).Logger()
 
 		if err != nil {
@@ -517,59 +1088,330 @@ func channelJoinHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, bot
 	}
 }
 
-func unix(i int64) (int64, int64) {
-	// convert milliseconds to whole seconds
-	// convert millisecond remainder from above conversion to nanoseconds
-	return i / 1000, (i % 1000) * int64(time.Millisecond)
-}
+func reactionAddedHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, usvc UserSvc, gsvc UsergroupSvc, esvc EmojiSvc, ph PanicHandler, tr *trace.Tracer, th *throttle, timeout time.Duration, fn ReactionAddedHandler) consumerFunc {
+	flogger := baseLogger.With().Str("handler", "reaction_added").Logger()
 
-func parseGatewayMessage(m *redisqueue.Message) (eventID string, eventTime, gatewayTime time.Time, data string, err error) {
-	eti, ok := m.Values["event_ts"]
-	if !ok {
-		return "", time.Time{}, time.Time{}, "", errors.New("redis stream malformed: event_ts not present")
-	}
+	return func(m *message) error {
+		start := time.Now()
 
-	gti, ok := m.Values["gateway_ts"]
-	if !ok {
-		return "", time.Time{}, time.Time{}, "", errors.New("redis stream malformed: gateway_ts not present")
-	}
+		// build message-local logging context
+		logger := flogger.With().
+			Str("redis_message", m.ID).
+			Str("redis_stream", m.Stream).
+			Logger()
 
-	eidi, ok := m.Values["event_id"]
-	if !ok {
-		return "", time.Time{}, time.Time{}, "", errors.New("redis stream malformed: event_id not present")
-	}
+		eid, et, gt, d, err := parseGatewayMessage(m)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message from gateway")
 
-	di, ok := m.Values["json"]
-	if !ok {
-		return "", time.Time{}, time.Time{}, "", errors.New("redis stream malformed: json data not present")
-	}
+			return nil
+		}
 
-	d, ok := di.(string)
-	if !ok {
-		return "", time.Time{}, time.Time{}, "", errors.New("json data is not a string")
-	}
+		rid := requestID(m)
 
-	eid, ok := eidi.(string)
-	if !ok {
-		return "", time.Time{}, time.Time{}, "", errors.New("event_id data is not a string")
-	}
+		// log time fired on Slack side, and time it was enqueued
+		logger = logger.With().
+			Time("event_time", et).
+			Str("event_id", eid).
+			Str("request_id", rid).
+			Time("enqueued_time", gt).Logger()
 
-	ets, ok := eti.(string)
-	if !ok {
-		return "", time.Time{}, time.Time{}, "", errors.New("event_ts is not a string")
-	}
+		var rae *slackevents.ReactionAddedEvent
 
-	gts, ok := gti.(string)
-	if !ok {
-		return "", time.Time{}, time.Time{}, "", errors.New("gateway_ts is not a string")
-	}
+		if err = json.Unmarshal([]byte(d), &rae); err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message JSON")
 
-	et, err := strconv.ParseInt(ets, 10, 64)
-	if err != nil {
-		return "", time.Time{}, time.Time{}, "", fmt.Errorf("failed to parse event_ts %q: %w", ets, err)
-	}
+			// we can't process it
+			return nil
+		}
 
-	gt, err := strconv.ParseInt(gts, 10, 64)
+		// wait for the throttle before starting the handler's timeout, so
+		// backing off from Slack doesn't eat into it
+		th.acquire(m.Stream)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+		ctx, span := traceMessage(ctx, tr, m, "reaction_added", gt)
+
+		wqctx := ctxer{
+			Context: ctx,
+			s:       sc,
+			l:       &logger,
+			u:       botUser,
+			c:       csvc,
+			us:      usvc,
+			gs:      gsvc,
+			es:      esvc,
+			e: EventMetadata{
+				ID:           eid,
+				Time:         et,
+				IngestTime:   gt,
+				RedisEvent:   m.ID,
+				RequestID:    rid,
+				ReplayOf:     replayOf(m.Values),
+				RetryNum:     retryNum(m.Values),
+				RetryReason:  retryReason(m.Values),
+				EventContext: eventContext(m.Values),
+			},
+		}
+
+		// used to calculate handler duration
+		bht := time.Now()
+
+		shouldRetry, discarded, err := safeCall(logger, ph, "reaction_added", wqctx.e, func() (bool, bool, error) {
+			return fn(wqctx, rae)
+		})
+
+		// handler runtime duration
+		hrd := time.Since(bht)
+
+		cancel()
+
+		th.release(m.Stream, err)
+
+		if err != nil && !discarded {
+			span.RecordError(err)
+		}
+
+		span.End()
+
+		logger = logger.With().Dur("handler_duration", hrd).Logger()
+
+		if err != nil {
+			if discarded {
+				logger.Warn().
+					Err(err).
+					TimeDiff("duration", time.Now(), start).
+					Msg("discarded event")
+
+				return nil
+			}
+
+			logger.Error().Err(err).
+				Bool("should_retry", shouldRetry).
+				TimeDiff("duration", time.Now(), start).
+				Msg("handler failed")
+
+			if shouldRetry {
+				return err
+			}
+
+			return nil
+		}
+
+		logger.Info().
+			TimeDiff("duration", time.Now(), start).
+			Msg("complete")
+
+		return nil
+	}
+}
+
+func interactionHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, usvc UserSvc, gsvc UsergroupSvc, esvc EmojiSvc, ph PanicHandler, tr *trace.Tracer, th *throttle, timeout time.Duration, fn InteractionHandler) consumerFunc {
+	flogger := baseLogger.With().Str("handler", "interaction").Logger()
+
+	return func(m *message) error {
+		start := time.Now()
+
+		// build message-local logging context
+		logger := flogger.With().
+			Str("redis_message", m.ID).
+			Str("redis_stream", m.Stream).
+			Logger()
+
+		eid, et, gt, d, err := parseGatewayMessage(m)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message from gateway")
+
+			return nil
+		}
+
+		rid := requestID(m)
+
+		// log time of the action on Slack side, and time it was enqueued
+		logger = logger.With().
+			Time("event_time", et).
+			Str("event_id", eid).
+			Str("request_id", rid).
+			Time("enqueued_time", gt).Logger()
+
+		var ic *slack.InteractionCallback
+
+		if err = json.Unmarshal([]byte(d), &ic); err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message JSON")
+
+			// we can't process it
+			return nil
+		}
+
+		// wait for the throttle before starting the handler's timeout, so
+		// backing off from Slack doesn't eat into it
+		th.acquire(m.Stream)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+		ctx, span := traceMessage(ctx, tr, m, "interaction", gt)
+
+		wqctx := ctxer{
+			Context: ctx,
+			s:       sc,
+			l:       &logger,
+			u:       botUser,
+			c:       csvc,
+			us:      usvc,
+			gs:      gsvc,
+			es:      esvc,
+			e: EventMetadata{
+				ID:           eid,
+				Time:         et,
+				IngestTime:   gt,
+				RedisEvent:   m.ID,
+				RequestID:    rid,
+				ReplayOf:     replayOf(m.Values),
+				RetryNum:     retryNum(m.Values),
+				RetryReason:  retryReason(m.Values),
+				EventContext: eventContext(m.Values),
+			},
+		}
+
+		// used to calculate handler duration
+		bht := time.Now()
+
+		shouldRetry, discarded, err := safeCall(logger, ph, "interaction", wqctx.e, func() (bool, bool, error) {
+			return fn(wqctx, ic)
+		})
+
+		// handler runtime duration
+		hrd := time.Since(bht)
+
+		cancel()
+
+		th.release(m.Stream, err)
+
+		if err != nil && !discarded {
+			span.RecordError(err)
+		}
+
+		span.End()
+
+		logger = logger.With().Dur("handler_duration", hrd).Logger()
+
+		if err != nil {
+			if discarded {
+				logger.Warn().
+					Err(err).
+					TimeDiff("duration", time.Now(), start).
+					Msg("discarded event")
+
+				return nil
+			}
+
+			logger.Error().Err(err).
+				Bool("should_retry", shouldRetry).
+				TimeDiff("duration", time.Now(), start).
+				Msg("handler failed")
+
+			if shouldRetry {
+				return err
+			}
+
+			return nil
+		}
+
+		logger.Info().
+			TimeDiff("duration", time.Now(), start).
+			Msg("complete")
+
+		return nil
+	}
+}
+
+func unix(i int64) (int64, int64) {
+	// convert milliseconds to whole seconds
+	// convert millisecond remainder from above conversion to nanoseconds
+	return i / 1000, (i % 1000) * int64(time.Millisecond)
+}
+
+// retryNum returns which of Slack's retries the message is from, or zero if
+// it's from the first delivery.
+func retryNum(values map[string]interface{}) int {
+	s, _ := values["retry_num"].(string)
+	n, _ := strconv.Atoi(s)
+
+	return n
+}
+
+// retryReason returns why Slack retried delivering the message, if it did.
+func retryReason(values map[string]interface{}) string {
+	s, _ := values["retry_reason"].(string)
+	return s
+}
+
+// eventContext returns the event_context Slack sent the event with, if it did.
+func eventContext(values map[string]interface{}) string {
+	s, _ := values["event_context"].(string)
+	return s
+}
+
+func parseGatewayMessage(m *message) (eventID string, eventTime, gatewayTime time.Time, data string, err error) {
+	eti, ok := m.Values["event_ts"]
+	if !ok {
+		return "", time.Time{}, time.Time{}, "", errors.New("redis stream malformed: event_ts not present")
+	}
+
+	gti, ok := m.Values["gateway_ts"]
+	if !ok {
+		return "", time.Time{}, time.Time{}, "", errors.New("redis stream malformed: gateway_ts not present")
+	}
+
+	eidi, ok := m.Values["event_id"]
+	if !ok {
+		return "", time.Time{}, time.Time{}, "", errors.New("redis stream malformed: event_id not present")
+	}
+
+	di, ok := m.Values["json"]
+	if !ok {
+		return "", time.Time{}, time.Time{}, "", errors.New("redis stream malformed: json data not present")
+	}
+
+	d, ok := di.(string)
+	if !ok {
+		return "", time.Time{}, time.Time{}, "", errors.New("json data is not a string")
+	}
+
+	eid, ok := eidi.(string)
+	if !ok {
+		return "", time.Time{}, time.Time{}, "", errors.New("event_id data is not a string")
+	}
+
+	ets, ok := eti.(string)
+	if !ok {
+		return "", time.Time{}, time.Time{}, "", errors.New("event_ts is not a string")
+	}
+
+	gts, ok := gti.(string)
+	if !ok {
+		return "", time.Time{}, time.Time{}, "", errors.New("gateway_ts is not a string")
+	}
+
+	et, err := strconv.ParseInt(ets, 10, 64)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, "", fmt.Errorf("failed to parse event_ts %q: %w", ets, err)
+	}
+
+	gt, err := strconv.ParseInt(gts, 10, 64)
 	if err != nil {
 		return "", time.Time{}, time.Time{}, "", fmt.Errorf("failed to parse gateway_ts %q: %w", gts, err)
 	}
@@ -581,3 +1423,503 @@ func parseGatewayMessage(m *redisqueue.Message) (eventID string, eventTime, gate
 
 	return eid, ett, gtt, d, nil
 }
+
+func channelTopicHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, usvc UserSvc, gsvc UsergroupSvc, esvc EmojiSvc, ph PanicHandler, tr *trace.Tracer, th *throttle, timeout time.Duration, fn ChannelTopicHandler) consumerFunc {
+	flogger := baseLogger.With().Str("handler", "channel_topic").Logger()
+
+	return func(m *message) error {
+		start := time.Now()
+
+		// build message-local logging context
+		logger := flogger.With().
+			Str("redis_message", m.ID).
+			Str("redis_stream", m.Stream).
+			Logger()
+
+		eid, et, gt, d, err := parseGatewayMessage(m)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message from gateway")
+
+			return nil
+		}
+
+		rid := requestID(m)
+
+		// log time fired on Slack side, and time it was enqueued
+		logger = logger.With().
+			Time("event_time", et).
+			Str("event_id", eid).
+			Str("request_id", rid).
+			Time("enqueued_time", gt).Logger()
+
+		var sct *ChannelTopicEvent
+
+		if err = json.Unmarshal([]byte(d), &sct); err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message JSON")
+
+			// we can't process it
+			return nil
+		}
+
+		// wait for the throttle before starting the handler's timeout, so
+		// backing off from Slack doesn't eat into it
+		th.acquire(m.Stream)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+		ctx, span := traceMessage(ctx, tr, m, "channel_topic", gt)
+
+		wqctx := ctxer{
+			Context: ctx,
+			s:       sc,
+			l:       &logger,
+			u:       botUser,
+			c:       csvc,
+			us:      usvc,
+			gs:      gsvc,
+			es:      esvc,
+			e: EventMetadata{
+				ID:           eid,
+				Time:         et,
+				IngestTime:   gt,
+				RedisEvent:   m.ID,
+				RequestID:    rid,
+				ReplayOf:     replayOf(m.Values),
+				RetryNum:     retryNum(m.Values),
+				RetryReason:  retryReason(m.Values),
+				EventContext: eventContext(m.Values),
+			},
+		}
+
+		// used to calculate handler duration
+		bht := time.Now()
+
+		shouldRetry, discarded, err := safeCall(logger, ph, "channel_topic", wqctx.e, func() (bool, bool, error) {
+			return fn(wqctx, sct)
+		})
+
+		// handler runtime duration
+		hrd := time.Since(bht)
+
+		cancel()
+
+		th.release(m.Stream, err)
+
+		if err != nil && !discarded {
+			span.RecordError(err)
+		}
+
+		span.End()
+
+		logger = logger.With().Dur("handler_duration", hrd).Logger()
+
+		if err != nil {
+			if discarded {
+				logger.Warn().
+					Err(err).
+					TimeDiff("duration", time.Now(), start).
+					Msg("discarded event")
+
+				return nil
+			}
+
+			logger.Error().Err(err).
+				Bool("should_retry", shouldRetry).
+				TimeDiff("duration", time.Now(), start).
+				Msg("handler failed")
+
+			if shouldRetry {
+				return err
+			}
+
+			return nil
+		}
+
+		logger.Info().
+			TimeDiff("duration", time.Now(), start).
+			Msg("complete")
+
+		return nil
+	}
+}
+
+func channelChangeHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, usvc UserSvc, gsvc UsergroupSvc, esvc EmojiSvc, ph PanicHandler, tr *trace.Tracer, th *throttle, timeout time.Duration, fn ChannelChangeHandler) consumerFunc {
+	flogger := baseLogger.With().Str("handler", "channel_change").Logger()
+
+	return func(m *message) error {
+		start := time.Now()
+
+		// build message-local logging context
+		logger := flogger.With().
+			Str("redis_message", m.ID).
+			Str("redis_stream", m.Stream).
+			Logger()
+
+		eid, et, gt, d, err := parseGatewayMessage(m)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message from gateway")
+
+			return nil
+		}
+
+		rid := requestID(m)
+
+		// log time fired on Slack side, and time it was enqueued
+		logger = logger.With().
+			Time("event_time", et).
+			Str("event_id", eid).
+			Str("request_id", rid).
+			Time("enqueued_time", gt).Logger()
+
+		var scc *ChannelChangeEvent
+
+		if err = json.Unmarshal([]byte(d), &scc); err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message JSON")
+
+			// we can't process it
+			return nil
+		}
+
+		// wait for the throttle before starting the handler's timeout, so
+		// backing off from Slack doesn't eat into it
+		th.acquire(m.Stream)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+		ctx, span := traceMessage(ctx, tr, m, "channel_change", gt)
+
+		wqctx := ctxer{
+			Context: ctx,
+			s:       sc,
+			l:       &logger,
+			u:       botUser,
+			c:       csvc,
+			us:      usvc,
+			gs:      gsvc,
+			es:      esvc,
+			e: EventMetadata{
+				ID:           eid,
+				Time:         et,
+				IngestTime:   gt,
+				RedisEvent:   m.ID,
+				RequestID:    rid,
+				ReplayOf:     replayOf(m.Values),
+				RetryNum:     retryNum(m.Values),
+				RetryReason:  retryReason(m.Values),
+				EventContext: eventContext(m.Values),
+			},
+		}
+
+		// used to calculate handler duration
+		bht := time.Now()
+
+		shouldRetry, discarded, err := safeCall(logger, ph, "channel_change", wqctx.e, func() (bool, bool, error) {
+			return fn(wqctx, scc)
+		})
+
+		// handler runtime duration
+		hrd := time.Since(bht)
+
+		cancel()
+
+		th.release(m.Stream, err)
+
+		if err != nil && !discarded {
+			span.RecordError(err)
+		}
+
+		span.End()
+
+		logger = logger.With().Dur("handler_duration", hrd).Logger()
+
+		if err != nil {
+			if discarded {
+				logger.Warn().
+					Err(err).
+					TimeDiff("duration", time.Now(), start).
+					Msg("discarded event")
+
+				return nil
+			}
+
+			logger.Error().Err(err).
+				Bool("should_retry", shouldRetry).
+				TimeDiff("duration", time.Now(), start).
+				Msg("handler failed")
+
+			if shouldRetry {
+				return err
+			}
+
+			return nil
+		}
+
+		logger.Info().
+			TimeDiff("duration", time.Now(), start).
+			Msg("complete")
+
+		return nil
+	}
+}
+
+func linkSharedHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, usvc UserSvc, gsvc UsergroupSvc, esvc EmojiSvc, ph PanicHandler, tr *trace.Tracer, th *throttle, timeout time.Duration, fn LinkSharedHandler) consumerFunc {
+	flogger := baseLogger.With().Str("handler", "link_shared").Logger()
+
+	return func(m *message) error {
+		start := time.Now()
+
+		// build message-local logging context
+		logger := flogger.With().
+			Str("redis_message", m.ID).
+			Str("redis_stream", m.Stream).
+			Logger()
+
+		eid, et, gt, d, err := parseGatewayMessage(m)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message from gateway")
+
+			return nil
+		}
+
+		rid := requestID(m)
+
+		// log time fired on Slack side, and time it was enqueued
+		logger = logger.With().
+			Time("event_time", et).
+			Str("event_id", eid).
+			Str("request_id", rid).
+			Time("enqueued_time", gt).Logger()
+
+		var sls *slackevents.LinkSharedEvent
+
+		if err = json.Unmarshal([]byte(d), &sls); err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message JSON")
+
+			// we can't process it
+			return nil
+		}
+
+		// wait for the throttle before starting the handler's timeout, so
+		// backing off from Slack doesn't eat into it
+		th.acquire(m.Stream)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+		ctx, span := traceMessage(ctx, tr, m, "link_shared", gt)
+
+		wqctx := ctxer{
+			Context: ctx,
+			s:       sc,
+			l:       &logger,
+			u:       botUser,
+			c:       csvc,
+			us:      usvc,
+			gs:      gsvc,
+			es:      esvc,
+			e: EventMetadata{
+				ID:           eid,
+				Time:         et,
+				IngestTime:   gt,
+				RedisEvent:   m.ID,
+				RequestID:    rid,
+				ReplayOf:     replayOf(m.Values),
+				RetryNum:     retryNum(m.Values),
+				RetryReason:  retryReason(m.Values),
+				EventContext: eventContext(m.Values),
+			},
+		}
+
+		// used to calculate handler duration
+		bht := time.Now()
+
+		shouldRetry, discarded, err := safeCall(logger, ph, "link_shared", wqctx.e, func() (bool, bool, error) {
+			return fn(wqctx, sls)
+		})
+
+		// handler runtime duration
+		hrd := time.Since(bht)
+
+		cancel()
+
+		th.release(m.Stream, err)
+
+		if err != nil && !discarded {
+			span.RecordError(err)
+		}
+
+		span.End()
+
+		logger = logger.With().Dur("handler_duration", hrd).Logger()
+
+		if err != nil {
+			if discarded {
+				logger.Warn().
+					Err(err).
+					TimeDiff("duration", time.Now(), start).
+					Msg("discarded event")
+
+				return nil
+			}
+
+			logger.Error().Err(err).
+				Bool("should_retry", shouldRetry).
+				TimeDiff("duration", time.Now(), start).
+				Msg("handler failed")
+
+			if shouldRetry {
+				return err
+			}
+
+			return nil
+		}
+
+		logger.Info().
+			TimeDiff("duration", time.Now(), start).
+			Msg("complete")
+
+		return nil
+	}
+}
+
+func slashCommandHandlerFactory(baseLogger *zerolog.Logger, sc *slack.Client, botUser *slack.User, csvc ChannelSvc, usvc UserSvc, gsvc UsergroupSvc, esvc EmojiSvc, ph PanicHandler, tr *trace.Tracer, th *throttle, timeout time.Duration, fn SlashCommandHandler) consumerFunc {
+	flogger := baseLogger.With().Str("handler", "slash_command").Logger()
+
+	return func(m *message) error {
+		start := time.Now()
+
+		// build message-local logging context
+		logger := flogger.With().
+			Str("redis_message", m.ID).
+			Str("redis_stream", m.Stream).
+			Logger()
+
+		eid, et, gt, d, err := parseGatewayMessage(m)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message from gateway")
+
+			return nil
+		}
+
+		rid := requestID(m)
+
+		// log time fired on Slack side, and time it was enqueued
+		logger = logger.With().
+			Time("event_time", et).
+			Str("event_id", eid).
+			Str("request_id", rid).
+			Time("enqueued_time", gt).Logger()
+
+		var ssc *slack.SlashCommand
+
+		if err = json.Unmarshal([]byte(d), &ssc); err != nil {
+			logger.Error().
+				Err(err).
+				TimeDiff("duration", time.Now(), start).
+				Msg("failed to parse message JSON")
+
+			// we can't process it
+			return nil
+		}
+
+		// wait for the throttle before starting the handler's timeout, so
+		// backing off from Slack doesn't eat into it
+		th.acquire(m.Stream)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+		ctx, span := traceMessage(ctx, tr, m, "slash_command", gt)
+
+		wqctx := ctxer{
+			Context: ctx,
+			s:       sc,
+			l:       &logger,
+			u:       botUser,
+			c:       csvc,
+			us:      usvc,
+			gs:      gsvc,
+			es:      esvc,
+			e: EventMetadata{
+				ID:           eid,
+				Time:         et,
+				IngestTime:   gt,
+				RedisEvent:   m.ID,
+				RequestID:    rid,
+				ReplayOf:     replayOf(m.Values),
+				RetryNum:     retryNum(m.Values),
+				RetryReason:  retryReason(m.Values),
+				EventContext: eventContext(m.Values),
+			},
+		}
+
+		// used to calculate handler duration
+		bht := time.Now()
+
+		shouldRetry, discarded, err := safeCall(logger, ph, "slash_command", wqctx.e, func() (bool, bool, error) {
+			return fn(wqctx, ssc)
+		})
+
+		// handler runtime duration
+		hrd := time.Since(bht)
+
+		cancel()
+
+		th.release(m.Stream, err)
+
+		if err != nil && !discarded {
+			span.RecordError(err)
+		}
+
+		span.End()
+
+		logger = logger.With().Dur("handler_duration", hrd).Logger()
+
+		if err != nil {
+			if discarded {
+				logger.Warn().
+					Err(err).
+					TimeDiff("duration", time.Now(), start).
+					Msg("discarded event")
+
+				return nil
+			}
+
+			logger.Error().Err(err).
+				Bool("should_retry", shouldRetry).
+				TimeDiff("duration", time.Now(), start).
+				Msg("handler failed")
+
+			if shouldRetry {
+				return err
+			}
+
+			return nil
+		}
+
+		logger.Info().
+			TimeDiff("duration", time.Now(), start).
+			Msg("complete")
+
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+package xkcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyFormat = "xkcd:comic:%d"
+	redisLatestKey = "xkcd:latest"
+	redisTestKey   = "xkcd:test_key"
+
+	// comicTTL is how long a comic is cached. They don't change once
+	// published, so this only bounds how much is kept.
+	comicTTL = 7 * 24 * time.Hour
+
+	// latestTTL is how long the latest comic is cached, which is well within
+	// how often new ones come out.
+	latestTTL = time.Hour
+)
+
+// Store caches comics in Redis.
+type Store struct {
+	r *redis.Client
+}
+
+// NewStore returns a new *Store.
+func NewStore(rc *redis.Client) (*Store, error) {
+	res := rc.Set(context.Background(), redisTestKey, "foobar", 1*time.Second)
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to write to redis: %w", err)
+	}
+
+	return &Store{r: rc}, nil
+}
+
+func cacheKey(num int) (string, time.Duration) {
+	if num == 0 {
+		return redisLatestKey, latestTTL
+	}
+
+	return fmt.Sprintf(redisKeyFormat, num), comicTTL
+}
+
+// Get returns the cached comic, if found, where num 0 is the latest comic.
+func (s *Store) Get(ctx context.Context, num int) (Comic, bool, error) {
+	select {
+	case <-ctx.Done():
+		return Comic{}, false, ctx.Err()
+	default:
+		// noop
+	}
+
+	key, _ := cacheKey(num)
+
+	res := s.r.Get(ctx, key)
+	if err := res.Err(); err != nil {
+		if err == redis.Nil {
+			return Comic{}, false, nil
+		}
+
+		return Comic{}, false, fmt.Errorf("failed to GET redis key: %w", err)
+	}
+
+	var c Comic
+
+	if err := json.Unmarshal([]byte(res.Val()), &c); err != nil {
+		return Comic{}, false, fmt.Errorf("failed to unmarshal comic %d: %w", num, err)
+	}
+
+	return c, true, nil
+}
+
+// Set caches the comic, where num 0 is the latest comic.
+func (s *Store) Set(ctx context.Context, num int, c Comic) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		// noop
+	}
+
+	j, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal comic %d: %w", c.Num, err)
+	}
+
+	key, ttl := cacheKey(num)
+
+	if err = s.r.Set(ctx, key, string(j), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache comic %d: %w", c.Num, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+// Package xkcd looks up xkcd comics, by number or the latest one, using the
+// xkcd JSON API.
+package xkcd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gobridge/gopherbot/handler"
+	"github.com/gobridge/gopherbot/workqueue"
+	"github.com/slack-go/slack"
+)
+
+// Usage is the usage of the xkcd command.
+const Usage = "Usage: `xkcd <number|latest>`, like `xkcd 303`, or with one of the names I know, like `xkcd compiling`."
+
+const defaultBaseURL = "https://xkcd.com"
+
+// Aliases are the names of the comics people ask for most.
+var Aliases = map[string]int{
+	"compiling":    303,
+	"ballmer":      323,
+	"standards":    927,
+	"optimization": 1691,
+}
+
+// Latest is the argument for the latest comic.
+const Latest = "latest"
+
+// Comic is an xkcd comic's metadata.
+type Comic struct {
+	Num   int    `json:"num"`
+	Title string `json:"safe_title"`
+	Alt   string `json:"alt"`
+	Img   string `json:"img"`
+	Year  string `json:"year"`
+	Month string `json:"month"`
+	Day   string `json:"day"`
+}
+
+// URL returns the comic's page.
+func (c Comic) URL() string {
+	return fmt.Sprintf("https://xkcd.com/%d/", c.Num)
+}
+
+// Published returns the date the comic was published, like 2006-01-02, or an
+// empty string if it's not known.
+func (c Comic) Published() string {
+	y, yerr := strconv.Atoi(c.Year)
+	m, merr := strconv.Atoi(c.Month)
+	d, derr := strconv.Atoi(c.Day)
+
+	if yerr != nil || merr != nil || derr != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%04d-%02d-%02d", y, m, d)
+}
+
+// ErrNotFound is returned when the comic doesn't exist.
+var ErrNotFound = errors.New("comic not found")
+
+// Client fetches comics from the xkcd JSON API, caching them in the Store.
+type Client struct {
+	httpc   *http.Client
+	cache   *Store
+	baseURL string
+}
+
+// New returns a *Client.
+func New(httpc *http.Client, cache *Store) *Client {
+	return &Client{
+		httpc:   httpc,
+		cache:   cache,
+		baseURL: defaultBaseURL,
+	}
+}
+
+// parseArg parses the command's argument into the comic's number, or 0 for
+// the latest comic.
+func parseArg(arg string) (int, error) {
+	arg = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(arg), "#"))
+
+	if arg == Latest {
+		return 0, nil
+	}
+
+	if n, ok := Aliases[arg]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("`%s` isn't a comic number", arg)
+	}
+
+	return n, nil
+}
+
+// Comic returns the comic with the number, or the latest comic if num is 0.
+// If the comic doesn't exist, the error is ErrNotFound.
+func (c *Client) Comic(ctx context.Context, num int) (Comic, error) {
+	cm, found, err := c.cache.Get(ctx, num)
+	if err != nil {
+		return Comic{}, err
+	}
+
+	if found {
+		return cm, nil
+	}
+
+	cm, err = c.fetch(ctx, num)
+	if err != nil {
+		return Comic{}, err
+	}
+
+	if err = c.cache.Set(ctx, num, cm); err != nil {
+		return Comic{}, err
+	}
+
+	return cm, nil
+}
+
+func (c *Client) fetch(ctx context.Context, num int) (Comic, error) {
+	u := c.baseURL + "/info.0.json"
+	if num > 0 {
+		u = fmt.Sprintf("%s/%d/info.0.json", c.baseURL, num)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return Comic{}, err
+	}
+
+	req.Header.Add("User-Agent", "Gophers Slack bot")
+
+	resp, err := c.httpc.Do(req)
+	if err != nil {
+		return Comic{}, fmt.Errorf("failed to get comic from xkcd: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// noop
+	case http.StatusNotFound:
+		return Comic{}, ErrNotFound
+	default:
+		return Comic{}, fmt.Errorf("got non-200 code: %d from xkcd", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Comic{}, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	var cm Comic
+
+	if err = json.Unmarshal(body, &cm); err != nil {
+		return Comic{}, fmt.Errorf("failed to unmarshal JSON body: %w", err)
+	}
+
+	return cm, nil
+}
+
+// attachment renders the comic for Slack.
+func attachment(c Comic) slack.Attachment {
+	return slack.Attachment{
+		Title:     fmt.Sprintf("xkcd #%d: %s", c.Num, c.Title),
+		TitleLink: c.URL(),
+		ImageURL:  c.Img,
+		Text:      c.Alt,
+		Footer:    c.Published(),
+	}
+}
+
+// Handler satisfies handler.CommandFn, for the `xkcd <number|latest>` command.
+func (c *Client) Handler(ctx workqueue.Context, m handler.Messenger, cmd handler.Command, r handler.Responder) error {
+	if !m.BotMentioned() && m.ChannelType() != handler.ChannelDM {
+		return nil
+	}
+
+	if len(cmd.Args) != 1 {
+		return &handler.UsageError{}
+	}
+
+	num, err := parseArg(cmd.Arg(0))
+	if err != nil {
+		return handler.Usagef("%s", err)
+	}
+
+	cm, err := c.Comic(ctx, num)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return r.RespondTo(ctx, fmt.Sprintf("I couldn't find xkcd #%d.", num))
+		}
+
+		return fmt.Errorf("failed to get xkcd comic %d: %w", num, err)
+	}
+
+	return r.Respond(ctx, fmt.Sprintf("<%s|xkcd #%d>", cm.URL(), cm.Num), attachment(cm))
+}
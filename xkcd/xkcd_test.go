@@ -0,0 +1,132 @@
+package xkcd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    int
+		wantErr bool
+	}{
+		{name: "number", arg: "303", want: 303},
+		{name: "hash", arg: "#927", want: 927},
+		{name: "latest", arg: "Latest", want: 0},
+		{name: "alias", arg: "compiling", want: 303},
+		{name: "zero", arg: "0", wantErr: true},
+		{name: "negative", arg: "-1", wantErr: true},
+		{name: "word", arg: "gophers", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseArg(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseArg() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Fatalf("parseArg() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComic_Published(t *testing.T) {
+	tests := []struct {
+		name  string
+		comic Comic
+		want  string
+	}{
+		{name: "padded", comic: Comic{Year: "2007", Month: "8", Day: "1"}, want: "2007-08-01"},
+		{name: "unknown", comic: Comic{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.comic.Published(); got != tt.want {
+				t.Fatalf("Published() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_fetch(t *testing.T) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/info.0.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"num": 2900, "safe_title": "Latest", "img": "https://imgs.xkcd.com/comics/latest.png", "year": "2024", "month": "3", "day": "4"}`))
+	})
+
+	mux.HandleFunc("/303/info.0.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"num": 303, "safe_title": "Compiling", "alt": "'Are you stealing those LCDs?' 'Yeah, but I'm doing it while my code compiles.'", "img": "https://imgs.xkcd.com/comics/compiling.png", "year": "2007", "month": "8", "day": "1"}`))
+	})
+
+	mux.HandleFunc("/500/info.0.json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := &Client{httpc: srv.Client(), baseURL: srv.URL}
+
+	tests := []struct {
+		name    string
+		num     int
+		want    Comic
+		wantErr error
+	}{
+		{
+			name: "latest",
+			num:  0,
+			want: Comic{Num: 2900, Title: "Latest", Img: "https://imgs.xkcd.com/comics/latest.png", Year: "2024", Month: "3", Day: "4"},
+		},
+		{
+			name: "number",
+			num:  303,
+			want: Comic{
+				Num:   303,
+				Title: "Compiling",
+				Alt:   "'Are you stealing those LCDs?' 'Yeah, but I'm doing it while my code compiles.'",
+				Img:   "https://imgs.xkcd.com/comics/compiling.png",
+				Year:  "2007",
+				Month: "8",
+				Day:   "1",
+			},
+		},
+		{
+			name:    "not_found",
+			num:     404,
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.fetch(context.Background(), tt.num)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("fetch() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("fetch() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := c.fetch(context.Background(), 500); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("fetch() error = %v, want a server error", err)
+	}
+}